@@ -49,4 +49,10 @@ const (
 
 	// Deploy is the string value for the deploy action when logging.
 	Deploy = "deploy"
+
+	// Threshold is the string value for threshold events when logging.
+	Threshold = "threshold"
+
+	// Connect is the string value for connections between services when logging.
+	Connect = "connect"
 )
@@ -34,6 +34,10 @@ type Logger interface {
 	// Set the message that we are writing to the audit log.
 	Message(ctx datastore.Context, message string) Logger
 
+	// Set the message that we are writing to the audit log for a user that
+	// is not acting through a datastore context, such as a host.
+	MessageAs(user string, message string) Logger
+
 	// Set the type of entity being modified.
 	Type(theType string) Logger
 
@@ -81,7 +85,11 @@ func (l *logger) Action(action string) Logger {
 }
 
 func (l *logger) Message(ctx datastore.Context, message string) Logger {
-	result := l.newLoggerWith("user", ctx.User())
+	return l.MessageAs(ctx.User(), message)
+}
+
+func (l *logger) MessageAs(user string, message string) Logger {
+	result := l.newLoggerWith("user", user)
 	result.message = message
 	return result
 }
@@ -45,6 +45,18 @@ func (_m *Logger) Message(ctx datastore.Context, message string) audit.Logger {
 
 	return r0
 }
+func (_m *Logger) MessageAs(user string, message string) audit.Logger {
+	ret := _m.Called(user, message)
+
+	var r0 audit.Logger
+	if rf, ok := ret.Get(0).(func() audit.Logger); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(audit.Logger)
+	}
+
+	return r0
+}
 func (_m *Logger) Type(theType string) audit.Logger {
 	ret := _m.Called(theType)
 
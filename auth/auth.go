@@ -61,12 +61,22 @@ var (
 	ErrRestTokenBadSig = errors.New("Rest token signature cannot be verified")
 	// ErrSSHFailed is thrown when we can't ssh to a remote host to register keys
 	ErrSSHFailed = errors.New("Unable to make an ssh connection to host")
-	// ErrAuth0TokenExpired is thrown when an auth0 token is expired
-	ErrAuth0TokenExpired = errors.New("auth0 token expired")
-	// ErrAuth0TokenBadIssuer is thrown when the issuer claim in an auth0 token does not match the value configured in the API
-	ErrAuth0TokenBadIssuer = errors.New("auth0 token issuer does not match value configured in API")
-	// ErrAuth0TokenBadAudience is thrown when the audience claim in an auth0 token does not match the value of the target API
-	ErrAuth0TokenBadAudience = errors.New("auth0 token audience does not match the target API")
+	// ErrOIDCTokenExpired is thrown when an OIDC token is expired
+	ErrOIDCTokenExpired = errors.New("OIDC token expired")
+	// ErrOIDCTokenBadIssuer is thrown when the issuer claim in an OIDC token does not match the configured issuer
+	ErrOIDCTokenBadIssuer = errors.New("OIDC token issuer does not match the configured issuer")
+	// ErrOIDCTokenBadAudience is thrown when the audience claim in an OIDC token does not match the client or API
+	ErrOIDCTokenBadAudience = errors.New("OIDC token audience does not match the client or API")
+	// ErrOIDCTokenBadNonce is thrown when an OIDC ID token was not issued for the current login
+	ErrOIDCTokenBadNonce = errors.New("OIDC token nonce does not match the login request")
+	// ErrOIDCUnknownKey is thrown when an OIDC token is signed with a key the provider does not publish
+	ErrOIDCUnknownKey = errors.New("OIDC token is signed with an unknown key")
+	// ErrOIDCDiscovery is thrown when the OIDC provider's configuration cannot be discovered
+	ErrOIDCDiscovery = errors.New("Unable to discover OIDC provider configuration")
+	// ErrInvalidRole is thrown when a role name is not recognized
+	ErrInvalidRole = errors.New("Invalid role")
+	// ErrInvalidGrant is thrown when a role grant or role mapping cannot be parsed
+	ErrInvalidGrant = errors.New("Invalid role grant")
 
 	log = logging.PackageLogger()
 )
@@ -90,5 +100,6 @@ type Identity interface {
 	PoolID() string
 	HasAdminAccess() bool
 	HasDFSAccess() bool
+	Role() Role
 	Verifier() (Verifier, error)
 }
@@ -49,6 +49,7 @@ type jwtIdentity struct {
 	IssuedAt    int64  `json:"iat,omitempty"`
 	AdminAccess bool   `json:"adm,omitempty"`
 	DFSAccess   bool   `json:"dfs,omitempty"`
+	RoleName    string `json:"rol,omitempty"`
 	PubKey      string `json:"key,omitempty"`
 }
 
@@ -85,16 +86,29 @@ func ParseJWTIdentity(token string) (Identity, error) {
 
 // CreateJWTIdentity returns a signed string
 func CreateJWTIdentity(hostID, poolID string, admin, dfs bool, pubKeyPEM []byte, expiration time.Duration) (string, int64, error) {
+	role := RoleNone
+	if admin {
+		role = RoleAdmin
+	}
+	return CreateJWTIdentityWithRole(hostID, poolID, role, dfs, pubKeyPEM, expiration)
+}
+
+// CreateJWTIdentityWithRole returns a signed string for an identity with the
+// given role
+func CreateJWTIdentityWithRole(hostID, poolID string, role Role, dfs bool, pubKeyPEM []byte, expiration time.Duration) (string, int64, error) {
 	now := jwt.TimeFunc().UTC()
 	claims := &jwtIdentity{
 		Host:        hostID,
 		Pool:        poolID,
 		ExpiresAt:   now.Add(expiration).Unix(),
 		IssuedAt:    now.Unix(),
-		AdminAccess: admin,
+		AdminAccess: role == RoleAdmin,
 		DFSAccess:   dfs,
 		PubKey:      string(pubKeyPEM),
 	}
+	if role != RoleNone {
+		claims.RoleName = role.String()
+	}
 	token := jwt.NewWithClaims(jwt.SigningMethodPS256, claims)
 	masterPrivKey, err := getMasterPrivateKey()
 	if err != nil {
@@ -142,6 +156,16 @@ func (id *jwtIdentity) HasDFSAccess() bool {
 	return id.DFSAccess
 }
 
+// Role returns the role of the identity.  Tokens issued before roles were
+// introduced only carry the admin flag.
+func (id *jwtIdentity) Role() Role {
+	if id.AdminAccess {
+		return RoleAdmin
+	}
+	role, _ := ParseRole(id.RoleName)
+	return role
+}
+
 func (id *jwtIdentity) Verifier() (Verifier, error) {
 	return RSAVerifierFromPEM([]byte(id.PubKey))
 }
@@ -21,34 +21,88 @@ import (
 
 /*
    When establishing a connection to the mux, in addition to the address of the receiver,
-   the sender sends an authentication token and signs the whole message. The token determines
-   if the sender is authorized to send data to the receiver or not
+   the sender sends an authentication token and the tenant it is connecting on behalf of,
+   and signs the whole message. The token and tenant determine if the sender is authorized
+   to send data to the receiver or not. The tenant is chosen by the sender, so the receiver
+   must check it against the identity in the token before trusting it. The address and
+   tenant are the payload of the authentication header (see header.go):
 
-   ---------------------------------------------------------------------------------------------------------
-   | Auth Token length (4 bytes)  |     Auth Token (N bytes)  | Address (6 bytes) |  Signature (256 bytes) |
-   ---------------------------------------------------------------------------------------------------------
+   -----------------------------------------------
+   | Address (6 bytes) |  Tenant ID (N bytes)    |
+   -----------------------------------------------
+
+   Once the receiver has checked the header and connected to the address, it
+   answers with a single result byte before any data is proxied, so the sender
+   can try another receiver if the connection was refused.
 */
 
 const (
 	ADDRESS_BYTES = 6
 )
 
+// Results the receiver sends back after reading a mux header
+const (
+	MuxOK          byte = 0 // the connection was established
+	MuxDenied      byte = 1 // the sender may not connect to the address
+	MuxUnreachable byte = 2 // the address could not be reached
+)
+
 var (
-	ErrBadMuxAddress = errors.New("Bad mux address")
+	ErrBadMuxAddress    = errors.New("Bad mux address")
+	ErrMuxDenied        = errors.New("Mux denied the connection")
+	ErrMuxUnreachable   = errors.New("Mux could not reach the address")
+	ErrUnknownMuxResult = errors.New("Unknown mux result")
 
 	endian = binary.BigEndian
 )
 
-func AddSignedMuxHeader(w io.Writer, address []byte, token string) error {
+// AddSignedMuxHeader writes a signed header requesting a connection to the
+// address on behalf of the tenant.
+func AddSignedMuxHeader(w io.Writer, address []byte, tenantID, token string) error {
 	if len(address) != ADDRESS_BYTES {
 		return ErrBadMuxAddress
 	}
-	header := NewAuthHeaderWriterTo([]byte(token), address, &delegateKeys)
+	payload := append(append([]byte{}, address...), tenantID...)
+	header := NewAuthHeaderWriterTo([]byte(token), payload, &delegateKeys)
 	_, err := header.WriteTo(w)
 	return err
 }
 
-func ReadMuxHeader(r io.Reader) ([]byte, Identity, error) {
-	sender, _, address, err := ReadAuthHeader(r)
-	return address, sender, err
+// ReadMuxHeader reads a signed mux header, returning the requested address,
+// the tenant of the sender and the sender's identity.  The tenant is empty if
+// the sender did not send one, and is not verified against the identity.
+func ReadMuxHeader(r io.Reader) ([]byte, string, Identity, error) {
+	sender, _, payload, err := ReadAuthHeader(r)
+	if err != nil {
+		return nil, "", sender, err
+	}
+	if len(payload) < ADDRESS_BYTES {
+		return nil, "", sender, ErrBadMuxAddress
+	}
+	return payload[:ADDRESS_BYTES], string(payload[ADDRESS_BYTES:]), sender, nil
+}
+
+// WriteMuxResult answers a mux header with the result of the connection.
+func WriteMuxResult(w io.Writer, result byte) error {
+	_, err := w.Write([]byte{result})
+	return err
+}
+
+// ReadMuxResult reads the receiver's answer to a mux header, returning an
+// error if the connection was not established.
+func ReadMuxResult(r io.Reader) error {
+	result := make([]byte, 1)
+	if _, err := io.ReadFull(r, result); err != nil {
+		return err
+	}
+	switch result[0] {
+	case MuxOK:
+		return nil
+	case MuxDenied:
+		return ErrMuxDenied
+	case MuxUnreachable:
+		return ErrMuxUnreachable
+	default:
+		return ErrUnknownMuxResult
+	}
 }
@@ -27,14 +27,14 @@ func (s *TestAuthSuite) TestBuildHeaderBadAddr(c *C) {
 	token, _, _ := auth.CreateJWTIdentity(s.hostId, s.poolId, s.admin, s.dfs, s.delegatePubPEM, time.Hour)
 	addr := "this is more than 6 bytes"
 	var b bytes.Buffer
-	err := auth.AddSignedMuxHeader(&b, []byte(addr), token)
+	err := auth.AddSignedMuxHeader(&b, []byte(addr), "tenant", token)
 	c.Assert(err, Equals, auth.ErrBadMuxAddress)
 }
 
 func (s *TestAuthSuite) TestExtractBadHeader(c *C) {
 	mockHeader := []byte{0, 0, 0, 19, 109, 121, 32, 115, 117, 112, 101, 114, 32, 102}
 	b := bytes.NewBuffer(mockHeader)
-	_, _, err := auth.ReadMuxHeader(b)
+	_, _, _, err := auth.ReadMuxHeader(b)
 	c.Assert(err, Not(IsNil))
 }
 
@@ -44,17 +44,46 @@ func (s *TestAuthSuite) TestBuildAndExtractHeader(c *C) {
 	var b bytes.Buffer
 
 	// build header
-	err := auth.AddSignedMuxHeader(&b, []byte(addr), token)
+	err := auth.AddSignedMuxHeader(&b, []byte(addr), "tenant", token)
 	c.Assert(err, Equals, nil)
 
 	// extract header
-	extractedAddr, ident, err := auth.ReadMuxHeader(&b)
-	// check the address is correctly decoded
+	extractedAddr, tenantID, ident, err := auth.ReadMuxHeader(&b)
+	// check the address and tenant are correctly decoded
 	c.Assert(err, IsNil)
 	c.Assert(string(extractedAddr), DeepEquals, addr)
+	c.Assert(tenantID, Equals, "tenant")
 	// check the identity has been correctly extracted
 	c.Assert(s.hostId, DeepEquals, ident.HostID())
 	c.Assert(s.poolId, DeepEquals, ident.PoolID())
 	c.Assert(s.admin, Equals, ident.HasAdminAccess())
 	c.Assert(s.dfs, Equals, ident.HasDFSAccess())
 }
+
+func (s *TestAuthSuite) TestBuildAndExtractHeaderNoTenant(c *C) {
+	token, _, _ := auth.CreateJWTIdentity(s.hostId, s.poolId, s.admin, s.dfs, s.delegatePubPEM, time.Hour)
+	var b bytes.Buffer
+	err := auth.AddSignedMuxHeader(&b, []byte("zenoss"), "", token)
+	c.Assert(err, IsNil)
+	extractedAddr, tenantID, _, err := auth.ReadMuxHeader(&b)
+	c.Assert(err, IsNil)
+	c.Assert(string(extractedAddr), Equals, "zenoss")
+	c.Assert(tenantID, Equals, "")
+}
+
+func (s *TestAuthSuite) TestMuxResult(c *C) {
+	for result, expected := range map[byte]error{
+		auth.MuxOK:          nil,
+		auth.MuxDenied:      auth.ErrMuxDenied,
+		auth.MuxUnreachable: auth.ErrMuxUnreachable,
+		42:                  auth.ErrUnknownMuxResult,
+	} {
+		var b bytes.Buffer
+		c.Assert(auth.WriteMuxResult(&b, result), IsNil)
+		c.Assert(auth.ReadMuxResult(&b), Equals, expected)
+	}
+
+	// the receiver closed the connection without answering
+	var b bytes.Buffer
+	c.Assert(auth.ReadMuxResult(&b), Not(IsNil))
+}
@@ -0,0 +1,617 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/control-center/serviced/config"
+	"github.com/control-center/serviced/utils"
+	"github.com/dgrijalva/jwt-go"
+)
+
+const (
+	// DefaultOIDCScope is requested when no scope is configured
+	DefaultOIDCScope = "openid profile email"
+	// DefaultOIDCUsernameClaim is the claim that holds the user's login name
+	DefaultOIDCUsernameClaim = "preferred_username"
+	// DefaultOIDCAdminClaim is the claim that holds the user's groups
+	DefaultOIDCAdminClaim = "groups"
+	// DefaultOIDCKeyRefreshInterval is the minimum time between fetches of
+	// the provider's signing keys, so that tokens with unknown key ids can't
+	// be used to flood the provider
+	DefaultOIDCKeyRefreshInterval = time.Minute
+)
+
+// OIDCConfig describes an OpenID Connect provider
+type OIDCConfig struct {
+	Issuer        string   // Issuer URL; the discovery document is served below it
+	ClientID      string   // Client ID registered with the provider
+	ClientSecret  string   // Client secret, if the client is confidential
+	Audience      string   // Additional audience accepted for access tokens
+	Scope         string   // Scopes requested at login
+	UsernameClaim string   // Claim holding the user name
+	AdminClaim    string   // Claim holding groups or roles; nested claims are separated by '.'
+	AdminValues   []string // Values of AdminClaim that grant admin access
+	RedirectURL   string   // URL of the login callback, if it can't be derived from the request
+	StripSubject  bool     // Only use the part of the subject after the last '|' as the user name
+
+	KeyRefreshInterval time.Duration // Minimum time between fetches of the signing keys
+}
+
+// OIDCDiscovery is the subset of the provider's discovery document that is used
+type OIDCDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+	EndSessionEndpoint    string `json:"end_session_endpoint,omitempty"`
+}
+
+// OIDCToken is a validated token issued by the OIDC provider
+type OIDCToken interface {
+	HasAdminAccess() bool
+	Grants() Grants
+	User() string
+	Expiration() int64
+}
+
+// OIDCProvider validates tokens issued by an OpenID Connect provider and
+// drives the authorization code flow.
+type OIDCProvider struct {
+	config OIDCConfig
+	client *http.Client
+
+	mu          sync.Mutex
+	discovery   *OIDCDiscovery
+	keys        map[string]interface{}
+	lastRefresh time.Time
+}
+
+var (
+	oidcProvider *OIDCProvider
+	oidcLock     sync.RWMutex
+)
+
+// NewOIDCProvider creates a provider.  Discovery is deferred until it is
+// first needed, so the provider may be unreachable at startup.
+func NewOIDCProvider(config OIDCConfig) *OIDCProvider {
+	config.Issuer = strings.TrimSuffix(config.Issuer, "/")
+	if config.Scope == "" {
+		config.Scope = DefaultOIDCScope
+	}
+	if config.UsernameClaim == "" {
+		config.UsernameClaim = DefaultOIDCUsernameClaim
+	}
+	if config.AdminClaim == "" {
+		config.AdminClaim = DefaultOIDCAdminClaim
+	}
+	if config.KeyRefreshInterval == 0 {
+		config.KeyRefreshInterval = DefaultOIDCKeyRefreshInterval
+	}
+	return &OIDCProvider{
+		config: config,
+		client: &http.Client{Timeout: 30 * time.Second},
+		keys:   make(map[string]interface{}),
+	}
+}
+
+// OIDCConfigFromOptions returns the OIDC provider configured by the options.
+// The legacy Auth0 options are used if no OIDC issuer is set.  Returns false
+// if neither is configured.
+func OIDCConfigFromOptions(opts config.Options) (OIDCConfig, bool) {
+	if opts.OIDCIssuer != "" {
+		return OIDCConfig{
+			Issuer:        opts.OIDCIssuer,
+			ClientID:      opts.OIDCClientID,
+			ClientSecret:  opts.OIDCClientSecret,
+			Audience:      opts.OIDCAudience,
+			Scope:         opts.OIDCScope,
+			UsernameClaim: opts.OIDCUsernameClaim,
+			AdminClaim:    opts.OIDCAdminClaim,
+			AdminValues:   opts.OIDCAdminValues,
+			RedirectURL:   opts.OIDCRedirectURL,
+		}, opts.OIDCClientID != ""
+	}
+	if opts.Auth0Domain == "" || opts.Auth0ClientID == "" || opts.Auth0Audience == "" ||
+		opts.Auth0Scope == "" || len(opts.Auth0Group) == 0 {
+		return OIDCConfig{}, false
+	}
+	// the authorization code flow needs an ID token
+	scope := opts.Auth0Scope
+	if !utils.StringInSlice("openid", strings.Fields(scope)) {
+		scope = "openid " + scope
+	}
+	return OIDCConfig{
+		Issuer:        fmt.Sprintf("https://%s/", opts.Auth0Domain),
+		ClientID:      opts.Auth0ClientID,
+		Audience:      opts.Auth0Audience,
+		Scope:         scope,
+		UsernameClaim: "sub",
+		AdminClaim:    "https://zenoss.com/groups",
+		AdminValues:   opts.Auth0Group,
+		RedirectURL:   opts.OIDCRedirectURL,
+		StripSubject:  true,
+	}, true
+}
+
+// SetOIDCProvider sets the provider used to log in to the web UI.  A nil
+// provider disables OIDC login.
+func SetOIDCProvider(p *OIDCProvider) {
+	oidcLock.Lock()
+	defer oidcLock.Unlock()
+	oidcProvider = p
+}
+
+// GetOIDCProvider returns the provider used to log in to the web UI, or nil
+// if OIDC login is not configured.
+func GetOIDCProvider() *OIDCProvider {
+	oidcLock.RLock()
+	defer oidcLock.RUnlock()
+	return oidcProvider
+}
+
+// OIDCIsConfigured returns true if web UI logins go through an OIDC provider
+func OIDCIsConfigured() bool {
+	return GetOIDCProvider() != nil
+}
+
+// Config returns the configuration of the provider
+func (p *OIDCProvider) Config() OIDCConfig {
+	return p.config
+}
+
+// Discover returns the provider's discovery document, fetching it on first
+// use.
+func (p *OIDCProvider) Discover() (*OIDCDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.discover()
+}
+
+func (p *OIDCProvider) discover() (*OIDCDiscovery, error) {
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+	var doc OIDCDiscovery
+	if err := p.getJSON(p.config.Issuer+"/.well-known/openid-configuration", &doc); err != nil {
+		log.WithError(err).WithField("issuer", p.config.Issuer).Warn("Unable to fetch OIDC discovery document")
+		return nil, ErrOIDCDiscovery
+	}
+	// the issuer in the document must match the issuer we trust (OIDC
+	// Discovery 1.0, section 4.3)
+	if strings.TrimSuffix(doc.Issuer, "/") != p.config.Issuer {
+		log.WithFields(logrus.Fields{
+			"issuer":     p.config.Issuer,
+			"discovered": doc.Issuer,
+		}).Warn("OIDC discovery document is for a different issuer")
+		return nil, ErrOIDCDiscovery
+	}
+	if doc.JWKSURI == "" || doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" {
+		log.WithField("issuer", p.config.Issuer).Warn("OIDC discovery document is missing required endpoints")
+		return nil, ErrOIDCDiscovery
+	}
+	p.discovery = &doc
+	return p.discovery, nil
+}
+
+func (p *OIDCProvider) getJSON(u string, v interface{}) error {
+	resp, err := p.client.Get(u)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned %s", u, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// jsonWebKey is a key in the provider's JWKS document
+type jsonWebKey struct {
+	Kty string   `json:"kty"`
+	Kid string   `json:"kid"`
+	Use string   `json:"use"`
+	N   string   `json:"n"`
+	E   string   `json:"e"`
+	Crv string   `json:"crv"`
+	X   string   `json:"x"`
+	Y   string   `json:"y"`
+	X5c []string `json:"x5c"`
+}
+
+// publicKey returns the RSA or ECDSA public key
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		if k.N != "" && k.E != "" {
+			n, err := base64.RawURLEncoding.DecodeString(k.N)
+			if err != nil {
+				return nil, err
+			}
+			e, err := base64.RawURLEncoding.DecodeString(k.E)
+			if err != nil {
+				return nil, err
+			}
+			return &rsa.PublicKey{
+				N: new(big.Int).SetBytes(n),
+				E: int(new(big.Int).SetBytes(e).Int64()),
+			}, nil
+		}
+	case "EC":
+		if k.X != "" && k.Y != "" {
+			var curve elliptic.Curve
+			switch k.Crv {
+			case "P-256":
+				curve = elliptic.P256()
+			case "P-384":
+				curve = elliptic.P384()
+			case "P-521":
+				curve = elliptic.P521()
+			default:
+				return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+			}
+			x, err := base64.RawURLEncoding.DecodeString(k.X)
+			if err != nil {
+				return nil, err
+			}
+			y, err := base64.RawURLEncoding.DecodeString(k.Y)
+			if err != nil {
+				return nil, err
+			}
+			return &ecdsa.PublicKey{
+				Curve: curve,
+				X:     new(big.Int).SetBytes(x),
+				Y:     new(big.Int).SetBytes(y),
+			}, nil
+		}
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+	// fall back to the certificate chain
+	if len(k.X5c) == 0 {
+		return nil, fmt.Errorf("key %q has no key material", k.Kid)
+	}
+	der, err := base64.StdEncoding.DecodeString(k.X5c[0])
+	if err != nil {
+		return nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return cert.PublicKey, nil
+}
+
+// refreshKeys fetches the provider's signing keys, replacing the keys that
+// were previously fetched so that retired keys are dropped.
+func (p *OIDCProvider) refreshKeys() error {
+	doc, err := p.discover()
+	if err != nil {
+		return err
+	}
+	p.lastRefresh = time.Now()
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := p.getJSON(doc.JWKSURI, &jwks); err != nil {
+		log.WithError(err).WithField("jwksuri", doc.JWKSURI).Warn("Unable to fetch OIDC signing keys")
+		return err
+	}
+	keys := make(map[string]interface{})
+	for _, k := range jwks.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			log.WithError(err).WithField("kid", k.Kid).Debug("Skipping OIDC signing key")
+			continue
+		}
+		keys[k.Kid] = key
+	}
+	p.keys = keys
+	log.WithField("keys", len(keys)).Debug("Refreshed OIDC signing keys")
+	return nil
+}
+
+// key returns the signing key with the given key id.  Keys are refetched
+// when an unknown key id is seen, which picks up rotated keys.
+func (p *OIDCProvider) key(kid string) (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key, ok := p.keys[kid]
+	if !ok && time.Since(p.lastRefresh) >= p.config.KeyRefreshInterval {
+		if err := p.refreshKeys(); err != nil {
+			return nil, err
+		}
+		key, ok = p.keys[kid]
+	}
+	if ok {
+		return key, nil
+	}
+	// tokens without a key id are accepted if the provider has a single key
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, nil
+		}
+	}
+	return nil, ErrOIDCUnknownKey
+}
+
+// oidcToken holds the claims of a validated token
+type oidcToken struct {
+	claims   jwt.MapClaims
+	provider *OIDCProvider
+}
+
+// ParseToken validates an ID or access token issued by the provider
+func (p *OIDCProvider) ParseToken(token string) (OIDCToken, error) {
+	parser := &jwt.Parser{
+		ValidMethods: []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"},
+	}
+	claims := jwt.MapClaims{}
+	_, err := parser.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.key(kid)
+	})
+	if err != nil {
+		if verr, ok := err.(*jwt.ValidationError); ok {
+			if verr.Errors&jwt.ValidationErrorExpired != 0 {
+				return nil, ErrOIDCTokenExpired
+			}
+			if verr.Inner != nil {
+				return nil, verr.Inner
+			}
+		}
+		return nil, err
+	}
+	if iss, _ := claims["iss"].(string); strings.TrimSuffix(iss, "/") != p.config.Issuer {
+		return nil, ErrOIDCTokenBadIssuer
+	}
+	if !p.validAudience(claims["aud"]) {
+		return nil, ErrOIDCTokenBadAudience
+	}
+	if _, ok := claims["exp"]; !ok {
+		return nil, ErrInvalidIdentityTokenClaims
+	}
+	return &oidcToken{claims: claims, provider: p}, nil
+}
+
+// validAudience checks that the token was issued for this client or for the
+// configured API audience.  The aud claim may be a string or an array.
+func (p *OIDCProvider) validAudience(aud interface{}) bool {
+	var values []string
+	switch v := aud.(type) {
+	case string:
+		values = []string{v}
+	case []interface{}:
+		values, _ = utils.InterfaceArrayToStringArray(v)
+	}
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		if value == p.config.ClientID || value == p.config.Audience {
+			return true
+		}
+	}
+	return false
+}
+
+// claim returns a claim by its path; nested claims are separated by '.'.  A
+// claim whose name contains '.' (such as a namespaced URL) is matched as a
+// whole first.
+func (t *oidcToken) claim(name string) interface{} {
+	if v, ok := t.claims[name]; ok {
+		return v
+	}
+	var value interface{} = map[string]interface{}(t.claims)
+	for _, part := range strings.Split(name, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		if value, ok = m[part]; !ok {
+			return nil
+		}
+	}
+	return value
+}
+
+// groups returns the values of the admin claim
+func (t *oidcToken) groups() []string {
+	switch v := t.claim(t.provider.config.AdminClaim).(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		values, _ := utils.InterfaceArrayToStringArray(v)
+		return values
+	}
+	return nil
+}
+
+// HasAdminAccess returns true if the admin claim holds one of the admin values
+func (t *oidcToken) HasAdminAccess() bool {
+	groups := t.groups()
+	for _, admin := range t.provider.config.AdminValues {
+		if utils.StringInSlice(strings.TrimSpace(admin), groups) {
+			return true
+		}
+	}
+	return false
+}
+
+// Grants returns the roles given to the user.  The admin values give an
+// unscoped admin role, and the values of the admin claim are looked up in the
+// role map.
+func (t *oidcToken) Grants() Grants {
+	var grants Grants
+	if t.HasAdminAccess() {
+		grants = append(grants, Grant{Role: RoleAdmin})
+	}
+	grants = append(grants, GetRoleMap().Grants(t.groups())...)
+	if len(grants) == 0 {
+		log.WithFields(logrus.Fields{
+			"user":  t.User(),
+			"claim": t.provider.config.AdminClaim,
+		}).Warn("OIDC user has no roles")
+	}
+	return grants
+}
+
+// User returns the name of the user
+func (t *oidcToken) User() string {
+	user, _ := t.claim(t.provider.config.UsernameClaim).(string)
+	if user == "" {
+		user, _ = t.claims["sub"].(string)
+	}
+	if t.provider.config.StripSubject {
+		// Auth0 returns the subject in the form <source>|<username>, and
+		// there may be more than one '|'.  Use the last field.
+		fields := strings.Split(user, "|")
+		user = fields[len(fields)-1]
+	}
+	return user
+}
+
+// Expiration returns the expiration time of the token in seconds since the
+// epoch
+func (t *oidcToken) Expiration() int64 {
+	switch exp := t.claims["exp"].(type) {
+	case float64:
+		return int64(exp)
+	case json.Number:
+		v, _ := exp.Int64()
+		return v
+	}
+	return 0
+}
+
+// OIDCLoginRequest holds the per-login secrets of the authorization code flow
+type OIDCLoginRequest struct {
+	State    string
+	Nonce    string
+	Verifier string
+}
+
+// AuthCodeURL returns the URL that starts a login at the provider.  The code
+// challenge is derived from the request's verifier (PKCE, RFC 7636).
+func (p *OIDCProvider) AuthCodeURL(req OIDCLoginRequest, redirectURL string) (string, error) {
+	doc, err := p.Discover()
+	if err != nil {
+		return "", err
+	}
+	challenge := sha256.Sum256([]byte(req.Verifier))
+	q := url.Values{}
+	q.Set("response_type", "code")
+	q.Set("client_id", p.config.ClientID)
+	q.Set("redirect_uri", redirectURL)
+	q.Set("scope", p.config.Scope)
+	q.Set("state", req.State)
+	q.Set("nonce", req.Nonce)
+	q.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	q.Set("code_challenge_method", "S256")
+	if p.config.Audience != "" {
+		// Auth0 only issues JWT access tokens for a requested audience
+		q.Set("audience", p.config.Audience)
+	}
+	return appendQuery(doc.AuthorizationEndpoint, q), nil
+}
+
+// Exchange trades an authorization code for the user's ID token, which is
+// validated before it is returned.
+func (p *OIDCProvider) Exchange(req OIDCLoginRequest, code, redirectURL string) (string, OIDCToken, error) {
+	doc, err := p.Discover()
+	if err != nil {
+		return "", nil, err
+	}
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", redirectURL)
+	form.Set("client_id", p.config.ClientID)
+	form.Set("code_verifier", req.Verifier)
+	hreq, err := http.NewRequest("POST", doc.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", nil, err
+	}
+	hreq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	hreq.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		hreq.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+	resp, err := p.client.Do(hreq)
+	if err != nil {
+		return "", nil, err
+	}
+	defer resp.Body.Close()
+	var result struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", nil, fmt.Errorf("could not decode token response: %s", err)
+	}
+	if resp.StatusCode != http.StatusOK || result.Error != "" {
+		return "", nil, fmt.Errorf("token request failed: %s %s", result.Error, result.ErrorDescription)
+	}
+	token, err := p.ParseToken(result.IDToken)
+	if err != nil {
+		return "", nil, err
+	}
+	// the nonce ties the ID token to this login
+	if nonce, _ := token.(*oidcToken).claims["nonce"].(string); nonce != req.Nonce {
+		return "", nil, ErrOIDCTokenBadNonce
+	}
+	return result.IDToken, token, nil
+}
+
+// LogoutURL returns the URL that ends the user's session at the provider, or
+// an empty string if the provider does not support RP-initiated logout.
+func (p *OIDCProvider) LogoutURL(idToken, redirectURL string) string {
+	doc, err := p.Discover()
+	if err != nil || doc.EndSessionEndpoint == "" {
+		return ""
+	}
+	q := url.Values{}
+	q.Set("client_id", p.config.ClientID)
+	q.Set("post_logout_redirect_uri", redirectURL)
+	if idToken != "" {
+		q.Set("id_token_hint", idToken)
+	}
+	return appendQuery(doc.EndSessionEndpoint, q)
+}
+
+func appendQuery(u string, q url.Values) string {
+	if strings.Contains(u, "?") {
+		return u + "&" + q.Encode()
+	}
+	return u + "?" + q.Encode()
+}
@@ -0,0 +1,365 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build unit
+
+package auth_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/control-center/serviced/auth"
+	"github.com/control-center/serviced/config"
+	"github.com/dgrijalva/jwt-go"
+	. "gopkg.in/check.v1"
+)
+
+// stubIssuer is a minimal OpenID Connect provider
+type stubIssuer struct {
+	sync.Mutex
+	server     *httptest.Server
+	keys       map[string]interface{} // kid -> private key
+	jwksFetch  int
+	code       string
+	challenge  string
+	nonce      string
+	idClaims   jwt.MapClaims
+	signingKid string
+}
+
+func newStubIssuer(c *C) *stubIssuer {
+	s := &stubIssuer{keys: make(map[string]interface{})}
+	s.server = httptest.NewServer(s)
+	s.addRSAKey(c, "key-1")
+	return s
+}
+
+func (s *stubIssuer) addRSAKey(c *C, kid string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	c.Assert(err, IsNil)
+	s.Lock()
+	s.keys[kid] = key
+	s.signingKid = kid
+	s.Unlock()
+}
+
+func (s *stubIssuer) addECKey(c *C, kid string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, IsNil)
+	s.Lock()
+	s.keys[kid] = key
+	s.signingKid = kid
+	s.Unlock()
+}
+
+func (s *stubIssuer) removeKey(kid string) {
+	s.Lock()
+	delete(s.keys, kid)
+	s.Unlock()
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func (s *stubIssuer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	switch r.URL.Path {
+	case "/.well-known/openid-configuration":
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 s.server.URL,
+			"authorization_endpoint": s.server.URL + "/authorize",
+			"token_endpoint":         s.server.URL + "/token",
+			"jwks_uri":               s.server.URL + "/keys",
+			"end_session_endpoint":   s.server.URL + "/logout",
+		})
+	case "/keys":
+		s.jwksFetch++
+		var keys []map[string]string
+		for kid, key := range s.keys {
+			switch k := key.(type) {
+			case *rsa.PrivateKey:
+				keys = append(keys, map[string]string{
+					"kty": "RSA", "kid": kid, "use": "sig",
+					"n": b64(k.N.Bytes()),
+					"e": b64(big.NewInt(int64(k.E)).Bytes()),
+				})
+			case *ecdsa.PrivateKey:
+				keys = append(keys, map[string]string{
+					"kty": "EC", "kid": kid, "crv": "P-256",
+					"x": b64(k.X.Bytes()),
+					"y": b64(k.Y.Bytes()),
+				})
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys})
+	case "/token":
+		r.ParseForm()
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if r.PostForm.Get("code") != s.code || b64(sum[:]) != s.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		claims := jwt.MapClaims{"nonce": s.nonce}
+		for k, v := range s.idClaims {
+			claims[k] = v
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": s.sign(claims)})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// sign returns a token signed by the current signing key.  The caller must
+// hold the lock.
+func (s *stubIssuer) sign(claims jwt.MapClaims) string {
+	var token *jwt.Token
+	switch s.keys[s.signingKid].(type) {
+	case *rsa.PrivateKey:
+		token = jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	case *ecdsa.PrivateKey:
+		token = jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	}
+	token.Header["kid"] = s.signingKid
+	signed, _ := token.SignedString(s.keys[s.signingKid])
+	return signed
+}
+
+func (s *stubIssuer) token(claims jwt.MapClaims) string {
+	s.Lock()
+	defer s.Unlock()
+	return s.sign(claims)
+}
+
+func (s *stubIssuer) claims(extra jwt.MapClaims) jwt.MapClaims {
+	claims := jwt.MapClaims{
+		"iss":                s.server.URL,
+		"aud":                "serviced",
+		"sub":                "1234",
+		"preferred_username": "alice",
+		"exp":                time.Now().Add(time.Hour).Unix(),
+		"groups":             []string{"users", "cc-admins"},
+	}
+	for k, v := range extra {
+		claims[k] = v
+	}
+	return claims
+}
+
+func (s *stubIssuer) provider() *auth.OIDCProvider {
+	return auth.NewOIDCProvider(auth.OIDCConfig{
+		Issuer:             s.server.URL + "/",
+		ClientID:           "serviced",
+		AdminValues:        []string{"cc-admins"},
+		KeyRefreshInterval: time.Nanosecond,
+	})
+}
+
+func (t *TestAuthSuite) TestOIDCParseToken(c *C) {
+	s := newStubIssuer(c)
+	defer s.server.Close()
+	p := s.provider()
+
+	token, err := p.ParseToken(s.token(s.claims(nil)))
+	c.Assert(err, IsNil)
+	c.Assert(token.User(), Equals, "alice")
+	c.Assert(token.HasAdminAccess(), Equals, true)
+
+	token, err = p.ParseToken(s.token(s.claims(jwt.MapClaims{"groups": "users"})))
+	c.Assert(err, IsNil)
+	c.Assert(token.HasAdminAccess(), Equals, false)
+
+	// access tokens may have several audiences
+	p = auth.NewOIDCProvider(auth.OIDCConfig{Issuer: s.server.URL, ClientID: "serviced", Audience: "https://api"})
+	_, err = p.ParseToken(s.token(s.claims(jwt.MapClaims{"aud": []string{"https://api", "userinfo"}})))
+	c.Assert(err, IsNil)
+
+	_, err = p.ParseToken(s.token(s.claims(jwt.MapClaims{"aud": "other"})))
+	c.Assert(err, Equals, auth.ErrOIDCTokenBadAudience)
+	_, err = p.ParseToken(s.token(s.claims(jwt.MapClaims{"iss": "https://elsewhere"})))
+	c.Assert(err, Equals, auth.ErrOIDCTokenBadIssuer)
+	_, err = p.ParseToken(s.token(s.claims(jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()})))
+	c.Assert(err, Equals, auth.ErrOIDCTokenExpired)
+	_, err = p.ParseToken("not.a.token")
+	c.Assert(err, NotNil)
+}
+
+func (t *TestAuthSuite) TestOIDCNestedClaims(c *C) {
+	s := newStubIssuer(c)
+	defer s.server.Close()
+	p := auth.NewOIDCProvider(auth.OIDCConfig{
+		Issuer:        s.server.URL,
+		ClientID:      "serviced",
+		UsernameClaim: "email",
+		AdminClaim:    "realm_access.roles",
+		AdminValues:   []string{"admin"},
+	})
+	token, err := p.ParseToken(s.token(s.claims(jwt.MapClaims{
+		"realm_access": map[string]interface{}{"roles": []string{"admin"}},
+	})))
+	c.Assert(err, IsNil)
+	c.Assert(token.HasAdminAccess(), Equals, true)
+	// falls back to the subject
+	c.Assert(token.User(), Equals, "1234")
+}
+
+func (t *TestAuthSuite) TestOIDCGrants(c *C) {
+	s := newStubIssuer(c)
+	defer s.server.Close()
+	p := s.provider()
+	auth.SetRoleMap(auth.RoleMap{
+		"noc":   {{Role: auth.RoleOperator}},
+		"users": {{Role: auth.RoleViewer, Pool: "default"}},
+	})
+	defer auth.SetRoleMap(nil)
+
+	token, err := p.ParseToken(s.token(s.claims(nil)))
+	c.Assert(err, IsNil)
+	c.Assert(token.Grants(), DeepEquals, auth.Grants{{Role: auth.RoleAdmin}, {Role: auth.RoleViewer, Pool: "default"}})
+
+	token, err = p.ParseToken(s.token(s.claims(jwt.MapClaims{"groups": []string{"noc"}})))
+	c.Assert(err, IsNil)
+	c.Assert(token.HasAdminAccess(), Equals, false)
+	c.Assert(token.Grants(), DeepEquals, auth.Grants{{Role: auth.RoleOperator}})
+
+	token, err = p.ParseToken(s.token(s.claims(jwt.MapClaims{"groups": "guests"})))
+	c.Assert(err, IsNil)
+	c.Assert(token.Grants(), HasLen, 0)
+}
+
+func (t *TestAuthSuite) TestOIDCKeyRotation(c *C) {
+	s := newStubIssuer(c)
+	defer s.server.Close()
+	p := s.provider()
+
+	_, err := p.ParseToken(s.token(s.claims(nil)))
+	c.Assert(err, IsNil)
+	_, err = p.ParseToken(s.token(s.claims(nil)))
+	c.Assert(err, IsNil)
+	c.Assert(s.jwksFetch, Equals, 1)
+
+	// the provider rotates to a new key and retires the old one
+	old := s.token(s.claims(nil))
+	s.addECKey(c, "key-2")
+	s.removeKey("key-1")
+	token, err := p.ParseToken(s.token(s.claims(nil)))
+	c.Assert(err, IsNil)
+	c.Assert(token.User(), Equals, "alice")
+	c.Assert(s.jwksFetch, Equals, 2)
+	_, err = p.ParseToken(old)
+	c.Assert(err, Equals, auth.ErrOIDCUnknownKey)
+
+	// refetches are rate limited
+	p = auth.NewOIDCProvider(auth.OIDCConfig{Issuer: s.server.URL, ClientID: "serviced", KeyRefreshInterval: time.Hour})
+	_, err = p.ParseToken(s.token(s.claims(nil)))
+	c.Assert(err, IsNil)
+	fetches := s.jwksFetch
+	s.addRSAKey(c, "key-3")
+	_, err = p.ParseToken(s.token(s.claims(nil)))
+	c.Assert(err, Equals, auth.ErrOIDCUnknownKey)
+	c.Assert(s.jwksFetch, Equals, fetches)
+}
+
+func (t *TestAuthSuite) TestOIDCDiscoveryIssuerMismatch(c *C) {
+	s := newStubIssuer(c)
+	defer s.server.Close()
+	p := auth.NewOIDCProvider(auth.OIDCConfig{Issuer: s.server.URL + "/realms/other", ClientID: "serviced"})
+	_, err := p.Discover()
+	c.Assert(err, Equals, auth.ErrOIDCDiscovery)
+}
+
+func (t *TestAuthSuite) TestOIDCAuthorizationCodeFlow(c *C) {
+	s := newStubIssuer(c)
+	defer s.server.Close()
+	p := s.provider()
+
+	req := auth.OIDCLoginRequest{State: "state", Nonce: "nonce", Verifier: "verifier-verifier-verifier-verifier-verifier"}
+	loginURL, err := p.AuthCodeURL(req, "https://cc/oidc/callback")
+	c.Assert(err, IsNil)
+	u, err := url.Parse(loginURL)
+	c.Assert(err, IsNil)
+	c.Assert(u.Path, Equals, "/authorize")
+	q := u.Query()
+	c.Assert(q.Get("client_id"), Equals, "serviced")
+	c.Assert(q.Get("response_type"), Equals, "code")
+	c.Assert(q.Get("scope"), Equals, auth.DefaultOIDCScope)
+	c.Assert(q.Get("code_challenge_method"), Equals, "S256")
+
+	// the provider authenticates the user and redirects back with a code
+	s.Lock()
+	s.code = "code-1"
+	s.challenge = q.Get("code_challenge")
+	s.nonce = q.Get("nonce")
+	s.idClaims = s.claims(nil)
+	s.Unlock()
+
+	idToken, token, err := p.Exchange(req, "code-1", "https://cc/oidc/callback")
+	c.Assert(err, IsNil)
+	c.Assert(idToken, Not(Equals), "")
+	c.Assert(token.User(), Equals, "alice")
+
+	_, _, err = p.Exchange(req, "wrong", "https://cc/oidc/callback")
+	c.Assert(err, ErrorMatches, ".*invalid_grant.*")
+
+	// an ID token issued for another login is rejected
+	other := req
+	other.Nonce = "other"
+	_, _, err = p.Exchange(other, "code-1", "https://cc/oidc/callback")
+	c.Assert(err, Equals, auth.ErrOIDCTokenBadNonce)
+
+	logout, err := url.Parse(p.LogoutURL(idToken, "https://cc/"))
+	c.Assert(err, IsNil)
+	c.Assert(logout.Path, Equals, "/logout")
+	c.Assert(logout.Query().Get("id_token_hint"), Equals, idToken)
+}
+
+func (t *TestAuthSuite) TestOIDCConfigFromOptions(c *C) {
+	_, ok := auth.OIDCConfigFromOptions(config.Options{})
+	c.Assert(ok, Equals, false)
+
+	cfg, ok := auth.OIDCConfigFromOptions(config.Options{
+		OIDCIssuer:      "https://keycloak/realms/cc",
+		OIDCClientID:    "serviced",
+		OIDCAdminValues: []string{"admin"},
+	})
+	c.Assert(ok, Equals, true)
+	c.Assert(cfg.Issuer, Equals, "https://keycloak/realms/cc")
+
+	// legacy Auth0 settings
+	cfg, ok = auth.OIDCConfigFromOptions(config.Options{
+		Auth0Domain:   "tenant.auth0.com",
+		Auth0ClientID: "client",
+		Auth0Audience: "https://api",
+		Auth0Scope:    "profile",
+		Auth0Group:    []string{"cc-admins"},
+	})
+	c.Assert(ok, Equals, true)
+	c.Assert(cfg.Issuer, Equals, "https://tenant.auth0.com/")
+	c.Assert(cfg.Scope, Equals, "openid profile")
+	c.Assert(cfg.AdminClaim, Equals, "https://zenoss.com/groups")
+	c.Assert(cfg.StripSubject, Equals, true)
+}
@@ -0,0 +1,196 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"strings"
+	"sync"
+)
+
+// Role is a level of access to control center.  Each role includes the access
+// of the roles below it.
+type Role int
+
+const (
+	// RoleNone has no access beyond what every authenticated host needs
+	RoleNone Role = iota
+	// RoleViewer can read everything but change nothing
+	RoleViewer
+	// RoleOperator can also start, stop and restart services
+	RoleOperator
+	// RoleAdmin has full access
+	RoleAdmin
+)
+
+var roleNames = map[Role]string{
+	RoleNone:     "none",
+	RoleViewer:   "viewer",
+	RoleOperator: "operator",
+	RoleAdmin:    "admin",
+}
+
+// ParseRole returns the role with the given name
+func ParseRole(name string) (Role, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for role, n := range roleNames {
+		if n == name {
+			return role, nil
+		}
+	}
+	return RoleNone, ErrInvalidRole
+}
+
+func (r Role) String() string {
+	if name, ok := roleNames[r]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// Includes returns true if the role has at least the access of other
+func (r Role) Includes(other Role) bool {
+	return r >= other
+}
+
+// Grant gives a role, optionally limited to the resources in one resource
+// pool or one tenant.
+type Grant struct {
+	Role   Role
+	Pool   string
+	Tenant string
+}
+
+// ParseGrant parses a grant of the form ROLE, ROLE:pool=POOLID or
+// ROLE:tenant=TENANTID.
+func ParseGrant(s string) (Grant, error) {
+	parts := strings.SplitN(strings.TrimSpace(s), ":", 2)
+	role, err := ParseRole(parts[0])
+	if err != nil {
+		return Grant{}, err
+	}
+	grant := Grant{Role: role}
+	if len(parts) == 1 {
+		return grant, nil
+	}
+	scope := strings.SplitN(parts[1], "=", 2)
+	if len(scope) != 2 || scope[1] == "" {
+		return Grant{}, ErrInvalidGrant
+	}
+	switch strings.ToLower(scope[0]) {
+	case "pool":
+		grant.Pool = scope[1]
+	case "tenant":
+		grant.Tenant = scope[1]
+	default:
+		return Grant{}, ErrInvalidGrant
+	}
+	return grant, nil
+}
+
+func (g Grant) String() string {
+	switch {
+	case g.Pool != "":
+		return g.Role.String() + ":pool=" + g.Pool
+	case g.Tenant != "":
+		return g.Role.String() + ":tenant=" + g.Tenant
+	}
+	return g.Role.String()
+}
+
+// Grants are all of the roles held by a user or token
+type Grants []Grant
+
+// Allows returns true if a grant gives at least the role on a resource in the
+// given pool and tenant.  Unscoped grants cover every resource, while scoped
+// grants only cover resources in their pool or tenant, so a resource that
+// belongs to no pool or tenant is only covered by unscoped grants.
+func (gs Grants) Allows(role Role, pool, tenant string) bool {
+	for _, g := range gs {
+		if !g.Role.Includes(role) {
+			continue
+		}
+		if g.Pool != "" && g.Pool != pool {
+			continue
+		}
+		if g.Tenant != "" && g.Tenant != tenant {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// Max returns the highest role held in any scope
+func (gs Grants) Max() Role {
+	max := RoleNone
+	for _, g := range gs {
+		if g.Role > max {
+			max = g.Role
+		}
+	}
+	return max
+}
+
+// RoleMap maps the names of user groups to the grants given to their members
+type RoleMap map[string]Grants
+
+// ParseRoleMap parses entries of the form GROUP=GRANT.  A group may appear in
+// more than one entry.
+func ParseRoleMap(entries []string) (RoleMap, error) {
+	roles := make(RoleMap)
+	for _, entry := range entries {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		group := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || group == "" {
+			return nil, ErrInvalidGrant
+		}
+		grant, err := ParseGrant(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		roles[group] = append(roles[group], grant)
+	}
+	return roles, nil
+}
+
+// Grants returns the grants given to a member of the groups
+func (m RoleMap) Grants(groups []string) Grants {
+	var grants Grants
+	for _, group := range groups {
+		grants = append(grants, m[group]...)
+	}
+	return grants
+}
+
+var (
+	roleMap     RoleMap
+	roleMapLock sync.RWMutex
+)
+
+// SetRoleMap sets the mapping of user groups to roles used for logins
+func SetRoleMap(roles RoleMap) {
+	roleMapLock.Lock()
+	defer roleMapLock.Unlock()
+	roleMap = roles
+}
+
+// GetRoleMap returns the mapping of user groups to roles used for logins
+func GetRoleMap() RoleMap {
+	roleMapLock.RLock()
+	defer roleMapLock.RUnlock()
+	return roleMap
+}
@@ -0,0 +1,98 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build unit
+
+package auth_test
+
+import (
+	"github.com/control-center/serviced/auth"
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestAuthSuite) TestParseRole(c *C) {
+	role, err := auth.ParseRole(" Operator ")
+	c.Assert(err, IsNil)
+	c.Assert(role, Equals, auth.RoleOperator)
+	c.Assert(role.String(), Equals, "operator")
+	c.Assert(role.Includes(auth.RoleViewer), Equals, true)
+	c.Assert(role.Includes(auth.RoleAdmin), Equals, false)
+
+	_, err = auth.ParseRole("superuser")
+	c.Assert(err, Equals, auth.ErrInvalidRole)
+}
+
+func (s *TestAuthSuite) TestParseGrant(c *C) {
+	grant, err := auth.ParseGrant("admin")
+	c.Assert(err, IsNil)
+	c.Assert(grant, Equals, auth.Grant{Role: auth.RoleAdmin})
+
+	grant, err = auth.ParseGrant("operator:pool=default")
+	c.Assert(err, IsNil)
+	c.Assert(grant, Equals, auth.Grant{Role: auth.RoleOperator, Pool: "default"})
+	c.Assert(grant.String(), Equals, "operator:pool=default")
+
+	grant, err = auth.ParseGrant("viewer:tenant=abc123")
+	c.Assert(err, IsNil)
+	c.Assert(grant, Equals, auth.Grant{Role: auth.RoleViewer, Tenant: "abc123"})
+
+	_, err = auth.ParseGrant("viewer:host=abc123")
+	c.Assert(err, Equals, auth.ErrInvalidGrant)
+	_, err = auth.ParseGrant("viewer:pool=")
+	c.Assert(err, Equals, auth.ErrInvalidGrant)
+	_, err = auth.ParseGrant("root")
+	c.Assert(err, Equals, auth.ErrInvalidRole)
+}
+
+func (s *TestAuthSuite) TestGrantsAllows(c *C) {
+	grants := auth.Grants{
+		{Role: auth.RoleViewer},
+		{Role: auth.RoleOperator, Tenant: "tenant1"},
+		{Role: auth.RoleAdmin, Pool: "pool2"},
+	}
+	c.Assert(grants.Max(), Equals, auth.RoleAdmin)
+
+	// unscoped viewer
+	c.Assert(grants.Allows(auth.RoleViewer, "", ""), Equals, true)
+	c.Assert(grants.Allows(auth.RoleViewer, "pool1", "tenant2"), Equals, true)
+	c.Assert(grants.Allows(auth.RoleOperator, "", ""), Equals, false)
+
+	// operator in one tenant
+	c.Assert(grants.Allows(auth.RoleOperator, "pool1", "tenant1"), Equals, true)
+	c.Assert(grants.Allows(auth.RoleOperator, "pool1", "tenant2"), Equals, false)
+	c.Assert(grants.Allows(auth.RoleAdmin, "pool1", "tenant1"), Equals, false)
+
+	// admin in one pool
+	c.Assert(grants.Allows(auth.RoleAdmin, "pool2", "tenant2"), Equals, true)
+	c.Assert(grants.Allows(auth.RoleAdmin, "pool2", ""), Equals, true)
+	c.Assert(grants.Allows(auth.RoleAdmin, "", "tenant2"), Equals, false)
+
+	c.Assert(auth.Grants(nil).Max(), Equals, auth.RoleNone)
+	c.Assert(auth.Grants(nil).Allows(auth.RoleViewer, "", ""), Equals, false)
+}
+
+func (s *TestAuthSuite) TestParseRoleMap(c *C) {
+	roles, err := auth.ParseRoleMap([]string{"noc=operator", "acme = operator:tenant=acme", "acme=viewer", ""})
+	c.Assert(err, IsNil)
+	c.Assert(roles, DeepEquals, auth.RoleMap{
+		"noc":  {{Role: auth.RoleOperator}},
+		"acme": {{Role: auth.RoleOperator, Tenant: "acme"}, {Role: auth.RoleViewer}},
+	})
+	c.Assert(roles.Grants([]string{"noc", "users"}), DeepEquals, auth.Grants{{Role: auth.RoleOperator}})
+	c.Assert(roles.Grants([]string{"users"}), IsNil)
+
+	_, err = auth.ParseRoleMap([]string{"operator"})
+	c.Assert(err, Equals, auth.ErrInvalidGrant)
+	_, err = auth.ParseRoleMap([]string{"noc=boss"})
+	c.Assert(err, Equals, auth.ErrInvalidRole)
+}
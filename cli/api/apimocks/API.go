@@ -3,17 +3,22 @@ package mocks
 import api "github.com/control-center/serviced/cli/api"
 import applicationendpoint "github.com/control-center/serviced/domain/applicationendpoint"
 import dao "github.com/control-center/serviced/dao"
+import events "github.com/control-center/serviced/events"
 import host "github.com/control-center/serviced/domain/host"
 import io "io"
 import isvcs "github.com/control-center/serviced/isvcs"
+import migration "github.com/control-center/serviced/datastore/migration"
 import metrics "github.com/control-center/serviced/metrics"
 import mock "github.com/stretchr/testify/mock"
 import pool "github.com/control-center/serviced/domain/pool"
+import secret "github.com/control-center/serviced/domain/secret"
 import script "github.com/control-center/serviced/script"
 import "github.com/control-center/serviced/utils"
 import service "github.com/control-center/serviced/domain/service"
 import servicedefinition "github.com/control-center/serviced/domain/servicedefinition"
 import servicetemplate "github.com/control-center/serviced/domain/servicetemplate"
+import snapshotpolicy "github.com/control-center/serviced/domain/snapshotpolicy"
+import time "time"
 import volume "github.com/control-center/serviced/volume"
 
 // API is an autogenerated mock type for the API type
@@ -319,20 +324,43 @@ func (_m *API) AuthenticateHost(_a0 string) (string, int64, error) {
 	return r0, r1, r2
 }
 
-// Backup provides a mock function with given fields: _a0, _a1
-func (_m *API) Backup(_a0 string, _a1 []string, _a2 bool) (string, error) {
-	ret := _m.Called(_a0, _a1, _a2)
+// Backup provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *API) Backup(_a0 string, _a1 []string, _a2 bool, _a3 string) (string, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
 
 	var r0 string
-	if rf, ok := ret.Get(0).(func(string, []string, bool) string); ok {
-		r0 = rf(_a0, _a1, _a2)
+	if rf, ok := ret.Get(0).(func(string, []string, bool, string) string); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string, []string, bool) error); ok {
-		r1 = rf(_a0, _a1, _a2)
+	if rf, ok := ret.Get(1).(func(string, []string, bool, string) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PruneBackups provides a mock function with given fields: _a0, _a1
+func (_m *API) PruneBackups(_a0 string, _a1 int) ([]string, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(string, int) []string); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, int) error); ok {
+		r1 = rf(_a0, _a1)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -1409,6 +1437,29 @@ func (_m *API) ScriptParse(fileName string, config *script.Config) error {
 	return r0
 }
 
+// ScriptPlan provides a mock function with given fields: fileName, config
+func (_m *API) ScriptPlan(fileName string, config *script.Config) (*script.Plan, error) {
+	ret := _m.Called(fileName, config)
+
+	var r0 *script.Plan
+	if rf, ok := ret.Get(0).(func(string, *script.Config) *script.Plan); ok {
+		r0 = rf(fileName, config)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*script.Plan)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, *script.Config) error); ok {
+		r1 = rf(fileName, config)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ScriptRun provides a mock function with given fields: fileName, config, stopChan
 func (_m *API) ScriptRun(fileName string, config *script.Config, stopChan chan struct{}) error {
 	ret := _m.Called(fileName, config, stopChan)
@@ -1474,6 +1525,20 @@ func (_m *API) SetHostMemory(_a0 api.HostUpdateConfig) error {
 	return r0
 }
 
+// SetHostLabels provides a mock function with given fields: _a0
+func (_m *API) SetHostLabels(_a0 api.HostLabelConfig) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(api.HostLabelConfig) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // StartServer provides a mock function with given fields:
 func (_m *API) StartServer() error {
 	ret := _m.Called()
@@ -1700,3 +1765,317 @@ func (_m *API) GetBackupEstimate(_a0 string, _a1 []string) (*dao.BackupEstimate,
 }
 
 var _ api.API = (*API)(nil)
+
+// GetMigrationStatus provides a mock function with given fields:
+func (_m *API) GetMigrationStatus() ([]migration.Status, error) {
+	ret := _m.Called()
+
+	var r0 []migration.Status
+	if rf, ok := ret.Get(0).(func() []migration.Status); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]migration.Status)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MigrateEntities provides a mock function with given fields: dryRun
+func (_m *API) MigrateEntities(dryRun bool) ([]migration.Status, error) {
+	ret := _m.Called(dryRun)
+
+	var r0 []migration.Status
+	if rf, ok := ret.Get(0).(func(bool) []migration.Status); ok {
+		r0 = rf(dryRun)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]migration.Status)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(bool) error); ok {
+		r1 = rf(dryRun)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetEvents provides a mock function with given fields: filter, since, timeout
+func (_m *API) GetEvents(filter events.Filter, since uint64, timeout time.Duration) ([]events.Event, error) {
+	ret := _m.Called(filter, since, timeout)
+
+	var r0 []events.Event
+	if rf, ok := ret.Get(0).(func(events.Filter, uint64, time.Duration) []events.Event); ok {
+		r0 = rf(filter, since, timeout)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]events.Event)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(events.Filter, uint64, time.Duration) error); ok {
+		r1 = rf(filter, since, timeout)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRolloutStatus provides a mock function with given fields: serviceID
+func (_m *API) GetRolloutStatus(serviceID string) (*service.RolloutStatus, error) {
+	ret := _m.Called(serviceID)
+
+	var r0 *service.RolloutStatus
+	if rf, ok := ret.Get(0).(func(string) *service.RolloutStatus); ok {
+		r0 = rf(serviceID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*service.RolloutStatus)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(serviceID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetServiceTree provides a mock function with given fields: serviceID
+func (_m *API) GetServiceTree(serviceID string) (*service.ServiceTree, error) {
+	ret := _m.Called(serviceID)
+
+	var r0 *service.ServiceTree
+	if rf, ok := ret.Get(0).(func(string) *service.ServiceTree); ok {
+		r0 = rf(serviceID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*service.ServiceTree)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(serviceID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ApplyServiceTree provides a mock function with given fields: reader, dryRun
+func (_m *API) ApplyServiceTree(reader io.Reader, dryRun bool) ([]service.ServiceChange, error) {
+	ret := _m.Called(reader, dryRun)
+
+	var r0 []service.ServiceChange
+	if rf, ok := ret.Get(0).(func(io.Reader, bool) []service.ServiceChange); ok {
+		r0 = rf(reader, dryRun)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]service.ServiceChange)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(io.Reader, bool) error); ok {
+		r1 = rf(reader, dryRun)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpgradeServiceTemplate provides a mock function with given fields: _a0
+func (_m *API) UpgradeServiceTemplate(_a0 servicetemplate.UpgradeRequest) (*servicetemplate.Upgrade, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *servicetemplate.Upgrade
+	if rf, ok := ret.Get(0).(func(servicetemplate.UpgradeRequest) *servicetemplate.Upgrade); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*servicetemplate.Upgrade)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(servicetemplate.UpgradeRequest) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AddSecret provides a mock function with given fields: name, description, value
+func (_m *API) AddSecret(name string, description string, value []byte) error {
+	ret := _m.Called(name, description, value)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, []byte) error); ok {
+		r0 = rf(name, description, value)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RotateSecret provides a mock function with given fields: name, value
+func (_m *API) RotateSecret(name string, value []byte) error {
+	ret := _m.Called(name, value)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, []byte) error); ok {
+		r0 = rf(name, value)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RemoveSecret provides a mock function with given fields: name
+func (_m *API) RemoveSecret(name string) error {
+	ret := _m.Called(name)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetSecrets provides a mock function with given fields:
+func (_m *API) GetSecrets() ([]secret.Secret, error) {
+	ret := _m.Called()
+
+	var r0 []secret.Secret
+	if rf, ok := ret.Get(0).(func() []secret.Secret); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]secret.Secret)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetSnapshotPolicy provides a mock function with given fields: policy
+func (_m *API) SetSnapshotPolicy(policy snapshotpolicy.SnapshotPolicy) error {
+	ret := _m.Called(policy)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(snapshotpolicy.SnapshotPolicy) error); ok {
+		r0 = rf(policy)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetSnapshotPolicies provides a mock function with given fields:
+func (_m *API) GetSnapshotPolicies() ([]snapshotpolicy.SnapshotPolicy, error) {
+	ret := _m.Called()
+
+	var r0 []snapshotpolicy.SnapshotPolicy
+	if rf, ok := ret.Get(0).(func() []snapshotpolicy.SnapshotPolicy); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]snapshotpolicy.SnapshotPolicy)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveSnapshotPolicy provides a mock function with given fields: tenantID
+func (_m *API) RemoveSnapshotPolicy(tenantID string) error {
+	ret := _m.Called(tenantID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(tenantID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DiffSnapshot provides a mock function with given fields: _a0, _a1
+func (_m *API) DiffSnapshot(_a0 string, _a1 string) ([]volume.FileChange, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []volume.FileChange
+	if rf, ok := ret.Get(0).(func(string, string) []volume.FileChange); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]volume.FileChange)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RestoreSnapshotPaths provides a mock function with given fields: _a0, _a1
+func (_m *API) RestoreSnapshotPaths(_a0 string, _a1 []string) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, []string) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -16,6 +16,7 @@ package api
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/control-center/serviced/config"
 	"github.com/control-center/serviced/dao"
@@ -25,7 +26,8 @@ import (
 // Dump all templates and services to a tgz file.
 // This includes a snapshot of all shared file systems
 // and exports all docker images the services depend on.
-func (a *api) Backup(dirpath string, excludes []string, force bool) (string, error) {
+// If incremental is set, only the changes since that backup are written.
+func (a *api) Backup(dirpath string, excludes []string, force bool, incremental string) (string, error) {
 	client, err := a.connectDAO()
 	if err != nil {
 		return "", err
@@ -36,6 +38,7 @@ func (a *api) Backup(dirpath string, excludes []string, force bool) (string, err
 		SnapshotSpacePercent: config.GetOptions().SnapshotSpacePercent,
 		Excludes:             excludes,
 		Force:                force,
+		Incremental:          incremental,
 	}
 
 	est := dao.BackupEstimate{}
@@ -57,6 +60,11 @@ func (a *api) Restore(path string) error {
 		return err
 	}
 
+	// backups in an object store are restored from their full location
+	if strings.HasPrefix(path, "s3://") {
+		return client.Restore(dao.RestoreRequest{Filename: path}, &unusedInt)
+	}
+
 	fp, err := filepath.Abs(path)
 	if err != nil {
 		return fmt.Errorf("could not convert '%s' to an absolute file path: %v", path, err)
@@ -65,6 +73,21 @@ func (a *api) Restore(path string) error {
 	return client.Restore(dao.RestoreRequest{Filename: filepath.Clean(fp)}, &unusedInt)
 }
 
+// PruneBackups removes all but the newest backups from dirpath, or from the
+// default backup location if dirpath is empty.
+func (a *api) PruneBackups(dirpath string, keep int) ([]string, error) {
+	client, err := a.connectDAO()
+	if err != nil {
+		return nil, err
+	}
+	var removed []string
+	req := dao.PruneBackupsRequest{Dirpath: dirpath, Keep: keep}
+	if err := client.PruneBackups(req, &removed); err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
+
 
 func (a *api) GetBackupEstimate(dirpath string, excludes []string) (*dao.BackupEstimate, error) {
 	client, err := a.connectDAO()
@@ -18,27 +18,34 @@ import (
 	"errors"
 
 	"github.com/Sirupsen/logrus"
+	"github.com/control-center/serviced/audit"
 	"github.com/control-center/serviced/auth"
 	commonsdocker "github.com/control-center/serviced/commons/docker"
 	"github.com/control-center/serviced/config"
 	coordclient "github.com/control-center/serviced/coordinator/client"
+	coordetcd "github.com/control-center/serviced/coordinator/client/etcd"
 	coordzk "github.com/control-center/serviced/coordinator/client/zookeeper"
 	"github.com/control-center/serviced/coordinator/storage"
 	"github.com/control-center/serviced/dao"
 	"github.com/control-center/serviced/dao/elasticsearch"
 	"github.com/control-center/serviced/datastore"
 	"github.com/control-center/serviced/datastore/elastic"
+	"github.com/control-center/serviced/datastore/embedded"
 	"github.com/control-center/serviced/dfs"
+	"github.com/control-center/serviced/dfs/archive"
 	"github.com/control-center/serviced/dfs/docker"
 	"github.com/control-center/serviced/dfs/nfs"
 	"github.com/control-center/serviced/dfs/registry"
+	"github.com/control-center/serviced/dfs/target"
 	"github.com/control-center/serviced/domain/addressassignment"
 	"github.com/control-center/serviced/domain/host"
 	"github.com/control-center/serviced/domain/pool"
 	"github.com/control-center/serviced/domain/properties"
+	"github.com/control-center/serviced/domain/secret"
 	"github.com/control-center/serviced/domain/service"
 	"github.com/control-center/serviced/domain/serviceconfigfile"
 	"github.com/control-center/serviced/domain/servicetemplate"
+	"github.com/control-center/serviced/domain/snapshotpolicy"
 	"github.com/control-center/serviced/domain/user"
 	"github.com/control-center/serviced/facade"
 	"github.com/control-center/serviced/health"
@@ -54,6 +61,7 @@ import (
 	"github.com/control-center/serviced/servicedversion"
 	"github.com/control-center/serviced/shell"
 	"github.com/control-center/serviced/stats"
+	"github.com/control-center/serviced/threshold"
 	"github.com/control-center/serviced/utils"
 	"github.com/control-center/serviced/utils/iostat"
 	"github.com/control-center/serviced/validation"
@@ -117,10 +125,13 @@ type daemon struct {
 	facade *facade.Facade
 	ssm    servicestatemanager.ServiceStateManager
 	hcache *health.HealthStatusCache
+	backup *archive.Config
 	docker docker.Docker
 	reg    *registry.RegistryListener
 	disk   volume.Driver
 	net    storage.StorageDriver
+
+	muxMetrics *proxy.ConnectionMetrics
 }
 
 func init() {
@@ -173,11 +184,13 @@ func (d *daemon) startISVCS() {
 	bigtable := options.BigTableMetrics
 	isvcs.Init(options.ESStartupTimeout, options.DockerLogDriver, convertStringSliceToMap(options.DockerLogConfigList), d.docker, startZK, bigtable)
 	isvcs.Mgr.SetVolumesDir(options.IsvcsPath)
-	servicedClusterName := d.getEsClusterName("elasticsearch-serviced")
-	if err := isvcs.Mgr.SetConfigurationOption("elasticsearch-serviced", "cluster", servicedClusterName); err != nil {
-		log.WithFields(logrus.Fields{
-			"clustername": servicedClusterName,
-		}).WithError(err).Fatal("Could not set Elastic configuration")
+	if options.DatastoreDriver != DatastoreEmbedded {
+		servicedClusterName := d.getEsClusterName("elasticsearch-serviced")
+		if err := isvcs.Mgr.SetConfigurationOption("elasticsearch-serviced", "cluster", servicedClusterName); err != nil {
+			log.WithFields(logrus.Fields{
+				"clustername": servicedClusterName,
+			}).WithError(err).Fatal("Could not set Elastic configuration")
+		}
 	}
 	logstashClusterName := d.getEsClusterName("elasticsearch-logstash")
 	if err := isvcs.Mgr.SetConfigurationOption("elasticsearch-logstash", "cluster", logstashClusterName); err != nil {
@@ -406,6 +419,18 @@ func (d *daemon) initContext() datastore.Context {
 
 func (d *daemon) initZK(zks []string) (*coordclient.Client, error) {
 	options := config.GetOptions()
+	if options.CoordinatorDriver == config.CoordinatorEtcd {
+		dsn := coordetcd.NewDSN(options.EtcdEndpoints,
+			time.Duration(options.ZKSessionTimeout)*time.Second,
+			time.Duration(options.ZKConnectTimeout)*time.Second,
+		).String()
+		log.WithFields(logrus.Fields{
+			"dsn":       dsn,
+			"endpoints": options.EtcdEndpoints,
+		}).Debug("Establishing connection to etcd")
+		return coordclient.New(config.CoordinatorEtcd, dsn, "/", nil)
+	}
+
 	coordzk.RegisterZKLogger()
 	dsn := coordzk.NewDSN(zks,
 		time.Duration(options.ZKSessionTimeout)*time.Second,
@@ -419,7 +444,7 @@ func (d *daemon) initZK(zks []string) (*coordclient.Client, error) {
 		"sessiontimeout": options.ZKSessionTimeout,
 		"ensemble":       zks,
 	}).Debug("Establishing connection to ZooKeeper")
-	return coordclient.New("zookeeper", dsn, "/", nil)
+	return coordclient.New(config.CoordinatorZookeeper, dsn, "/", nil)
 }
 
 func (d *daemon) startMaster() (err error) {
@@ -556,6 +581,7 @@ func (d *daemon) startMaster() (err error) {
 	d.addTemplates()
 	d.startScheduler()
 	d.startPoolListener()
+	d.startThresholdMonitor()
 
 	log.Info("Started serviced master")
 
@@ -718,7 +744,14 @@ func createMuxListener() net.Listener {
 func (d *daemon) startAgent() error {
 	options := config.GetOptions()
 	muxListener := createMuxListener()
-	mux, err := proxy.NewTCPMux(muxListener)
+	d.muxMetrics = proxy.NewConnectionMetrics("net.mux")
+	if options.DebugPort > 0 {
+		// scraped in the Prometheus text format from the debug server
+		http.Handle("/metrics", d.muxMetrics)
+	}
+	mux, err := proxy.NewTCPMux(muxListener, proxy.NewExportAuthorizer(func() (coordclient.Connection, error) {
+		return zzk.GetLocalConnection("/")
+	}, node.ControlPlanePorts(options.UIPort)), d.muxMetrics)
 	if err != nil {
 		log.WithError(err).Fatal("Could not start TCP multiplexer")
 	}
@@ -1008,6 +1041,8 @@ func (d *daemon) startAgent() error {
 			ZKPerHostConnectDelay: options.ZKPerHostConnectDelay,
 			ZKReconnectStartDelay: options.ZKReconnectStartDelay,
 			ZKReconnectMaxDelay:   options.ZKReconnectMaxDelay,
+			CoordinatorDriver:     options.CoordinatorDriver,
+			EtcdEndpoints:         options.EtcdEndpoints,
 			DelegateKeyFile:       delegateKeyFile,
 			TokenFile:             tokenFile,
 		}
@@ -1046,6 +1081,7 @@ func (d *daemon) startAgent() error {
 			if err != nil {
 				log.WithError(err).Error("Unable to start reporting stats")
 			} else {
+				servicedStatsReporter.AddSampleSource(d.muxMetrics.Samples)
 				go func() {
 					defer servicedStatsReporter.Close()
 					<-d.shutdown
@@ -1192,6 +1228,10 @@ func (d *daemon) registerMasterRPC() error {
 }
 
 func (d *daemon) initDriver() datastore.Driver {
+	options := config.GetOptions()
+	if options.DatastoreDriver == DatastoreEmbedded {
+		return initEmbeddedDriver(options)
+	}
 	log := log.WithFields(logrus.Fields{
 		"address": "localhost:9200",
 		"index":   "controlplane",
@@ -1205,6 +1245,8 @@ func (d *daemon) initDriver() datastore.Driver {
 	eDriver.AddMapping(addressassignment.MAPPING)
 	eDriver.AddMapping(serviceconfigfile.MAPPING)
 	eDriver.AddMapping(user.MAPPING)
+	eDriver.AddMapping(secret.MAPPING)
+	eDriver.AddMapping(snapshotpolicy.MAPPING)
 	err := eDriver.Initialize(10 * time.Second)
 	if err != nil {
 		log.WithError(err).Fatal("Unable to establish connection to Elastic database")
@@ -1212,6 +1254,21 @@ func (d *daemon) initDriver() datastore.Driver {
 	return eDriver
 }
 
+func initEmbeddedDriver(options config.Options) datastore.Driver {
+	path := options.DatastorePath
+	if path == "" {
+		path = filepath.Join(options.IsvcsPath, embedded.DefaultFilename)
+	}
+	log := log.WithField("path", path)
+	log.Debug("Opening embedded datastore")
+	driver, err := embedded.Open(path, 10*time.Second)
+	if err != nil {
+		log.WithError(err).Fatal("Unable to open embedded datastore")
+	}
+	log.Info("Opened embedded datastore")
+	return driver
+}
+
 func initMetricsClient() *metrics.Client {
 	addr := fmt.Sprintf("http://%s:8888", localhost)
 	log := log.WithFields(logrus.Fields{
@@ -1232,6 +1289,8 @@ func (d *daemon) initFacade() *facade.Facade {
 	index := registry.NewRegistryIndexClient(f)
 	dfs := dfs.NewDistributedFilesystem(d.docker, index, d.reg, d.disk, d.net, time.Duration(options.MaxDFSTimeout)*time.Second)
 	dfs.SetTmp(os.Getenv("TMP"))
+	d.backup = initBackupArchive(options)
+	dfs.SetArchiveConfig(d.backup)
 	f.SetDFS(dfs)
 	f.SetIsvcsPath(options.IsvcsPath)
 	d.hcache = health.New()
@@ -1239,6 +1298,9 @@ func (d *daemon) initFacade() *facade.Facade {
 	f.SetHealthCache(d.hcache)
 	client := initMetricsClient()
 	f.SetMetricsClient(client)
+	if _, err := f.MigrateEntities(d.dsContext, false); err != nil {
+		log.WithError(err).Fatal("Unable to migrate datastore entities")
+	}
 	if err := f.CreateSystemUser(d.dsContext); err != nil {
 		log.WithError(err).Fatal("Unable to create system user")
 	}
@@ -1246,6 +1308,8 @@ func (d *daemon) initFacade() *facade.Facade {
 		log.WithError(err).Fatal("Unable to update the service cache")
 	}
 	f.SetRollingRestartTimeout(time.Duration(options.ServiceRunLevelTimeout) * time.Second)
+	f.SetIncrementalBackups(options.BackupIncremental)
+	initSecretKey(f, options)
 	return f
 }
 
@@ -1268,6 +1332,28 @@ func (d *daemon) startLogstashPurger(initialStart, cycleTime time.Duration) {
 	}
 }
 
+// startThresholdMonitor evaluates the thresholds in the monitoring profiles of
+// services
+func (d *daemon) startThresholdMonitor() {
+	options := config.GetOptions()
+	if options.ThresholdInterval <= 0 {
+		log.Info("Threshold evaluation is disabled")
+		return
+	}
+	client := initMetricsClient()
+	if client == nil {
+		log.Warn("Unable to evaluate thresholds without a metrics server")
+		return
+	}
+	interval := time.Duration(options.ThresholdInterval) * time.Second
+	monitor := threshold.NewMonitor(d.facade, client, d.facade.ThresholdEvents(), audit.NewLogger(), interval)
+	d.waitGroup.Add(1)
+	go func() {
+		defer d.waitGroup.Done()
+		monitor.Run(d.dsContext, d.shutdown)
+	}()
+}
+
 func (d *daemon) startStorageMonitor() {
 	options := config.GetOptions()
 	defer log.Info("Stopped monitoring application storage availability")
@@ -1386,6 +1472,34 @@ func (d *daemon) startStorageMonitor() {
 	}
 }
 
+// initBackupArchive loads the compression and keys used for backups
+func initBackupArchive(options config.Options) *archive.Config {
+	cfg, err := archive.LoadConfig(options.BackupCompression, options.BackupEncryptionKeyFile, options.BackupRecipients, options.BackupIdentities)
+	if err != nil {
+		log.WithError(err).Fatal("Unable to load backup archive settings")
+	}
+	log.WithFields(logrus.Fields{
+		"compression": cfg.Compression,
+		"encrypted":   cfg.Encrypted(),
+	}).Debug("Loaded backup archive settings")
+	return cfg
+}
+
+// initSecretKey loads the key that encrypts secrets
+func initSecretKey(f *facade.Facade, options config.Options) {
+	if options.SecretKeyFile == "" {
+		log.Debug("No secret key is configured; secrets are disabled")
+		return
+	}
+	key, err := archive.ReadKeyFile(options.SecretKeyFile)
+	if err != nil {
+		log.WithField("keyfile", options.SecretKeyFile).WithError(err).Fatal("Unable to load secret key")
+	}
+	if err := f.SetSecretKey(key); err != nil {
+		log.WithField("keyfile", options.SecretKeyFile).WithError(err).Fatal("Unable to load secret key")
+	}
+}
+
 // FIXME: The dao package is deprecated and should be removed.
 func (d *daemon) initDAO() dao.ControlPlane {
 	options := config.GetOptions()
@@ -1394,12 +1508,21 @@ func (d *daemon) initDAO() dao.ControlPlane {
 	if err != nil {
 		log.WithField("rpcPort", options.RPCPort).WithError(err).Fatal("RPC Port invalid")
 	}
-	if err := os.MkdirAll(options.BackupsPath, 0777); err != nil && !os.IsExist(err) {
+	if strings.HasPrefix(options.BackupsPath, "s3://") {
+		log.WithField("backupspath", options.BackupsPath).Info("Storing backups in an S3-compatible object store")
+	} else if err := os.MkdirAll(options.BackupsPath, 0777); err != nil && !os.IsExist(err) {
 		log.WithFields(logrus.Fields{
 			"backupspath": options.BackupsPath,
 		}).WithError(err).Fatal("Unable to create backup path")
 	}
-	cp, err := elasticsearch.NewControlSvc("localhost", 9200, d.facade, options.BackupsPath, rpcPortInt)
+	s3config := target.S3Config{
+		Endpoint:        options.BackupS3Endpoint,
+		Region:          options.BackupS3Region,
+		AccessKeyID:     options.BackupS3AccessKeyID,
+		SecretAccessKey: options.BackupS3SecretAccessKey,
+		PartSize:        int64(options.BackupS3PartSize) * 1024 * 1024,
+	}
+	cp, err := elasticsearch.NewControlSvc("localhost", 9200, d.facade, options.BackupsPath, s3config, d.backup, rpcPortInt)
 	if err != nil {
 		log.WithError(err).Fatal("Unable to initialize DAO layer")
 	}
@@ -1415,6 +1538,15 @@ func (d *daemon) initWeb() {
 		"master": options.Endpoint,
 	})
 	log.Debug("Starting Control Center UI server")
+	roles, err := auth.ParseRoleMap(options.AuthRoles)
+	if err != nil {
+		log.WithError(err).WithField("roles", options.AuthRoles).Fatal("Unable to parse the user roles")
+	}
+	auth.SetRoleMap(roles)
+	if oidcConfig, ok := auth.OIDCConfigFromOptions(options); ok {
+		log.WithField("issuer", oidcConfig.Issuer).Info("Logging in to the UI through an OIDC provider")
+		auth.SetOIDCProvider(auth.NewOIDCProvider(oidcConfig))
+	}
 	muxDisableTLS, _ := strconv.ParseBool(options.MuxDisableTLS)
 	cpserver := web.NewServiceConfig(
 		options.UIPort,
@@ -1527,7 +1659,7 @@ func (d *daemon) runScheduler() {
 	options := config.GetOptions()
 	// Run the first time after 10 minutes
 	for {
-		sched, err := scheduler.NewScheduler(d.masterPoolID, d.hostID, d.storageHandler, d.cpDao, d.facade, d.reg, options.SnapshotTTL)
+		sched, err := scheduler.NewScheduler(d.masterPoolID, d.hostID, d.storageHandler, d.cpDao, d.facade, d.reg, options.SnapshotTTL, options.SnapshotSpacePercent)
 		if err != nil {
 			log.WithError(err).Fatal("Unable to start service scheduler")
 			return
@@ -22,4 +22,6 @@ import (
 	_ "github.com/control-center/serviced/volume/devicemapper"
 // Need to do nfs driver initializations
 	_ "github.com/control-center/serviced/volume/nfs"
+// Need to do zfs driver initializations
+	_ "github.com/control-center/serviced/volume/zfs"
 )
@@ -39,6 +39,13 @@ type HostUpdateConfig struct {
 	Memory string
 }
 
+// HostLabelConfig sets or removes labels on a host.  Labels with an empty
+// value are removed.
+type HostLabelConfig struct {
+	HostID string
+	Labels map[string]string
+}
+
 type AuthHost struct {
 	host.Host
 	Authenticated bool
@@ -246,6 +253,29 @@ func (a *api) SetHostMemory(config HostUpdateConfig) error {
 	return client.UpdateHost(*h)
 }
 
+// Sets or removes the labels of an existing host
+func (a *api) SetHostLabels(config HostLabelConfig) error {
+	client, err := a.connectMaster()
+	if err != nil {
+		return err
+	}
+	h, err := client.GetHost(config.HostID)
+	if err != nil {
+		return err
+	}
+	if h.Labels == nil {
+		h.Labels = make(map[string]string)
+	}
+	for key, value := range config.Labels {
+		if value == "" {
+			delete(h.Labels, key)
+		} else {
+			h.Labels[key] = value
+		}
+	}
+	return client.UpdateHost(*h)
+}
+
 func (a *api) AuthenticateHost(hostID string) (string, int64, error) {
 	client, err := a.connectMaster()
 	if err != nil {
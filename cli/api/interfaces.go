@@ -15,14 +15,19 @@ package api
 
 import (
 	"io"
+	"time"
 
 	"github.com/control-center/serviced/dao"
+	"github.com/control-center/serviced/datastore/migration"
 	"github.com/control-center/serviced/domain/applicationendpoint"
 	"github.com/control-center/serviced/domain/host"
 	"github.com/control-center/serviced/domain/pool"
+	"github.com/control-center/serviced/domain/secret"
 	"github.com/control-center/serviced/domain/service"
 	"github.com/control-center/serviced/domain/servicedefinition"
 	template "github.com/control-center/serviced/domain/servicetemplate"
+	"github.com/control-center/serviced/domain/snapshotpolicy"
+	"github.com/control-center/serviced/events"
 	"github.com/control-center/serviced/isvcs"
 	"github.com/control-center/serviced/metrics"
 	"github.com/control-center/serviced/script"
@@ -46,6 +51,7 @@ type API interface {
 	RemoveHost(string) error
 	GetHostMemory(string) (*metrics.MemoryUsageStats, error)
 	SetHostMemory(HostUpdateConfig) error
+	SetHostLabels(HostLabelConfig) error
 	GetHostPublicKey(string) ([]byte, error)
 	RegisterHost([]byte) error
 	RegisterRemoteHost(*host.Host, utils.URL, []byte, bool) error
@@ -83,6 +89,9 @@ type API interface {
 	GetEndpoints(serviceID string, reportImports, reportExports, validate bool) ([]applicationendpoint.EndpointReport, error)
 	ResolveServicePath(path string) ([]service.ServiceDetails, error)
 	ClearEmergency(serviceID string) (int, error)
+	GetRolloutStatus(serviceID string) (*service.RolloutStatus, error)
+	GetServiceTree(serviceID string) (*service.ServiceTree, error)
+	ApplyServiceTree(reader io.Reader, dryRun bool) ([]service.ServiceChange, error)
 	RemoveIP(args []string) error
 	SetIP(IPConfig) error
 
@@ -99,6 +108,8 @@ type API interface {
 	Rollback(string, bool) error
 	TagSnapshot(string, string) error
 	RemoveSnapshotTag(string, string) (string, error)
+	DiffSnapshot(string, string) ([]volume.FileChange, error)
+	RestoreSnapshotPaths(string, []string) error
 
 	// Templates
 	GetServiceTemplates() ([]template.ServiceTemplate, error)
@@ -107,10 +118,12 @@ type API interface {
 	RemoveServiceTemplate(string) error
 	CompileServiceTemplate(CompileTemplateConfig) (*template.ServiceTemplate, error)
 	DeployServiceTemplate(DeployTemplateConfig) ([]service.ServiceDetails, error)
+	UpgradeServiceTemplate(template.UpgradeRequest) (*template.Upgrade, error)
 
 	// Backup & Restore
 	GetBackupEstimate(string, []string) (*dao.BackupEstimate, error)
-	Backup(string, []string, bool) (string, error)
+	Backup(string, []string, bool, string) (string, error)
+	PruneBackups(string, int) ([]string, error)
 	Restore(string) error
 
 	// Docker
@@ -128,6 +141,7 @@ type API interface {
 	// Scripts
 	ScriptRun(fileName string, config *script.Config, stopChan chan struct{}) error
 	ScriptParse(fileName string, config *script.Config) error
+	ScriptPlan(fileName string, config *script.Config) (*script.Plan, error)
 
 	// Volumes
 	GetVolumeStatus() (*volume.Statuses, error)
@@ -151,4 +165,22 @@ type API interface {
 	// Debug Management
 	DebugEnableMetrics() (string, error)
 	DebugDisableMetrics() (string, error)
+
+	// Migrations
+	GetMigrationStatus() ([]migration.Status, error)
+	MigrateEntities(dryRun bool) ([]migration.Status, error)
+
+	// Events
+	GetEvents(filter events.Filter, since uint64, timeout time.Duration) ([]events.Event, error)
+
+	// Secrets
+	AddSecret(name, description string, value []byte) error
+	RotateSecret(name string, value []byte) error
+	RemoveSecret(name string) error
+	GetSecrets() ([]secret.Secret, error)
+
+	// Snapshot policies
+	SetSnapshotPolicy(policy snapshotpolicy.SnapshotPolicy) error
+	GetSnapshotPolicies() ([]snapshotpolicy.SnapshotPolicy, error)
+	RemoveSnapshotPolicy(tenantID string) error
 }
@@ -35,6 +35,11 @@ const (
 	DefaultRPCPort       = 4979
 	outboundIPRetryDelay = 1
 	outboundIPMaxWait    = 90
+
+	// DatastoreElastic keeps the control plane entities in Elasticsearch
+	DatastoreElastic = config.DatastoreElastic
+	// DatastoreEmbedded keeps the control plane entities in a database file
+	DatastoreEmbedded = config.DatastoreEmbedded
 )
 
 // Validate options which are common to all CLI commands
@@ -81,10 +86,22 @@ func ValidateServerOptions(options *config.Options) error {
 		}
 	}
 
+	switch options.CoordinatorDriver {
+	case config.CoordinatorZookeeper, config.CoordinatorEtcd:
+	default:
+		return fmt.Errorf("Unknown coordinator driver %q; use %s or %s", options.CoordinatorDriver, config.CoordinatorZookeeper, config.CoordinatorEtcd)
+	}
+
 	if options.Master {
 		log.WithFields(logrus.Fields{
 			"poolid": options.MasterPoolID,
 		}).Debug("Using configured default pool ID")
+
+		switch options.DatastoreDriver {
+		case DatastoreElastic, DatastoreEmbedded:
+		default:
+			return fmt.Errorf("Unknown datastore driver %q; use %s or %s", options.DatastoreDriver, DatastoreElastic, DatastoreEmbedded)
+		}
 	}
 	return nil
 }
@@ -189,20 +206,48 @@ func GetDefaultOptions(cfg utils.ConfigReader) config.Options {
 		ZKPerHostConnectDelay:      cfg.IntVal("ZK_PER_HOST_CONNECT_DELAY", 0),
 		ZKReconnectStartDelay:      cfg.IntVal("ZK_RECONNECT_START_DELAY", 1),
 		ZKReconnectMaxDelay:        cfg.IntVal("ZK_RECONNECT_MAX_DELAY", 1),
+		CoordinatorDriver:          cfg.StringVal("COORDINATOR_DRIVER", config.CoordinatorZookeeper),
+		EtcdEndpoints:              cfg.StringSlice("ETCD_ENDPOINTS", []string{}),
 		TokenExpiration:            cfg.IntVal("AUTH_TOKEN_EXPIRATION", 60*60),
 		ServiceRunLevelTimeout:     cfg.IntVal("RUN_LEVEL_TIMEOUT", 60*10),
 		StorageReportInterval:      cfg.IntVal("STORAGE_REPORT_INTERVAL", 30),
 		StorageMetricMonitorWindow: cfg.IntVal("STORAGE_METRIC_MONITOR_WINDOW", 300),
 		StorageLookaheadPeriod:     cfg.IntVal("STORAGE_LOOKAHEAD_PERIOD", 360),
 		StorageMinimumFreeSpace:    cfg.StringVal("STORAGE_MIN_FREE", "3G"),
+		ThresholdInterval:          cfg.IntVal("THRESHOLD_INTERVAL", 60),
+		DatastoreDriver:            cfg.StringVal("DATASTORE_DRIVER", DatastoreElastic),
+		DatastorePath:              cfg.StringVal("DATASTORE_PATH", ""),
 		BackupEstimatedCompression: cfg.Float64Val("BACKUP_ESTIMATED_COMPRESSION", 1.0),
 		BackupMinOverhead:          cfg.StringVal("BACKUP_MIN_OVERHEAD", "0G"),
+		BackupS3Endpoint:           cfg.StringVal("BACKUP_S3_ENDPOINT", "https://s3.amazonaws.com"),
+		BackupS3Region:             cfg.StringVal("BACKUP_S3_REGION", "us-east-1"),
+		BackupS3AccessKeyID:        cfg.StringVal("BACKUP_S3_ACCESS_KEY_ID", ""),
+		BackupS3SecretAccessKey:    cfg.StringVal("BACKUP_S3_SECRET_ACCESS_KEY", ""),
+		BackupS3PartSize:           cfg.IntVal("BACKUP_S3_PART_SIZE", 16),
+		BackupCompression:          cfg.StringVal("BACKUP_COMPRESSION", "gzip"),
+		BackupIncremental:          cfg.BoolVal("BACKUP_INCREMENTAL", false),
+		BackupEncryptionKeyFile:    cfg.StringVal("BACKUP_ENCRYPTION_KEY_FILE", ""),
+		BackupRecipients:           cfg.StringSlice("BACKUP_RECIPIENTS", []string{}),
+		BackupIdentities:           cfg.StringSlice("BACKUP_IDENTITIES", []string{}),
+		SecretKeyFile:              cfg.StringVal("SECRET_KEY_FILE", ""),
 		// Auth0 configuration parameters. Default to empty strings - must edit in serviced.conf to configure for auth0.
 		Auth0Domain:   cfg.StringVal("AUTH0_DOMAIN", ""),
 		Auth0Audience: cfg.StringVal("AUTH0_AUDIENCE", ""),
 		Auth0Group:    cfg.StringSlice("AUTH0_GROUP", []string{}),
 		Auth0ClientID: cfg.StringVal("AUTH0_CLIENT_ID", ""),
 		Auth0Scope:    cfg.StringVal("AUTH0_SCOPE", ""),
+		// OpenID Connect login. Takes precedence over the Auth0 parameters.
+		OIDCIssuer:        cfg.StringVal("OIDC_ISSUER", ""),
+		OIDCClientID:      cfg.StringVal("OIDC_CLIENT_ID", ""),
+		OIDCClientSecret:  cfg.StringVal("OIDC_CLIENT_SECRET", ""),
+		OIDCAudience:      cfg.StringVal("OIDC_AUDIENCE", ""),
+		OIDCScope:         cfg.StringVal("OIDC_SCOPE", "openid profile email"),
+		OIDCUsernameClaim: cfg.StringVal("OIDC_USERNAME_CLAIM", "preferred_username"),
+		OIDCAdminClaim:    cfg.StringVal("OIDC_ADMIN_CLAIM", "groups"),
+		OIDCAdminValues:   cfg.StringSlice("OIDC_ADMIN_VALUES", []string{}),
+		OIDCRedirectURL:   cfg.StringVal("OIDC_REDIRECT_URL", ""),
+		// Roles for UI and REST users who are not admins
+		AuthRoles: cfg.StringSlice("AUTH_ROLES", []string{}),
 		// Parameters for api-key-proxy isvc configuration
 		KeyProxyJsonServer: cfg.StringVal("KEYPROXY_JSON_SERVER", ""),
 		KeyProxyListenPort: cfg.StringVal("KEYPROXY_LISTEN_PORT", ":6443"),
@@ -36,6 +36,17 @@ func (a *api) ScriptRun(fileName string, config *script.Config, stopChan chan st
 	return r.Run(stopChan)
 }
 
+// ScriptPlan reports what each step of a script would do without running it
+func (a *api) ScriptPlan(fileName string, config *script.Config) (*script.Plan, error) {
+	initConfig(config, a)
+	r, err := script.NewRunnerFromFile(fileName, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Plan()
+}
+
 func (a *api) ScriptParse(fileName string, config *script.Config) error {
 	_, err := script.NewRunnerFromFile(fileName, config)
 	return err
@@ -56,6 +67,48 @@ func initConfig(config *script.Config, a *api) {
 		return a.AddSnapshot(SnapshotConfig{DockerID: containerID})
 	}
 	config.SvcUse = cliServiceUse(a)
+	config.SvcState = cliServiceState(a)
+	config.TenantServices = cliTenantServices(a)
+	config.SnapshotSize = cliSnapshotSize(a)
+}
+
+func cliTenantServices(a *api) script.TenantServiceList {
+	return func(tenantID string) ([]service.ServiceDetails, error) {
+		client, err := a.connectMaster()
+		if err != nil {
+			return nil, err
+		}
+		return client.GetServiceDetailsByTenantID(tenantID)
+	}
+}
+
+// cliSnapshotSize estimates the size of a snapshot as the space used by the
+// tenant volume, which is the most that a snapshot can hold on to.
+func cliSnapshotSize(a *api) script.SnapshotEstimate {
+	return func(tenantID string) (uint64, error) {
+		statuses, err := a.GetVolumeStatus()
+		if err != nil {
+			return 0, err
+		}
+		for _, status := range statuses.DeviceMapperStatusMap {
+			for _, tenant := range status.Tenants {
+				if tenant.TenantID == tenantID {
+					return tenant.FilesystemUsed, nil
+				}
+			}
+		}
+		return 0, fmt.Errorf("no usage data for tenant %s", tenantID)
+	}
+}
+
+func cliServiceState(a *api) script.ServiceStateLookup {
+	return func(serviceID string) (script.ServiceState, error) {
+		svc, err := a.GetServiceDetails(serviceID)
+		if err != nil {
+			return "", err
+		}
+		return script.DesiredStateToScriptState(service.DesiredState(svc.DesiredState))
+	}
 }
 
 func cliServiceUse(a *api) script.ServiceUse {
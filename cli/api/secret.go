@@ -0,0 +1,55 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"github.com/control-center/serviced/domain/secret"
+	"github.com/control-center/serviced/rpc/master"
+)
+
+// AddSecret encrypts and stores a new secret
+func (a *api) AddSecret(name, description string, value []byte) error {
+	client, err := a.connectMaster()
+	if err != nil {
+		return err
+	}
+	return client.AddSecret(master.SecretRequest{Name: name, Description: description, Value: value})
+}
+
+// RotateSecret replaces the value of a secret
+func (a *api) RotateSecret(name string, value []byte) error {
+	client, err := a.connectMaster()
+	if err != nil {
+		return err
+	}
+	return client.RotateSecret(master.SecretRequest{Name: name, Value: value})
+}
+
+// RemoveSecret deletes a secret
+func (a *api) RemoveSecret(name string) error {
+	client, err := a.connectMaster()
+	if err != nil {
+		return err
+	}
+	return client.RemoveSecret(name)
+}
+
+// GetSecrets returns all secrets without their values
+func (a *api) GetSecrets() ([]secret.Secret, error) {
+	client, err := a.connectMaster()
+	if err != nil {
+		return nil, err
+	}
+	return client.GetSecrets()
+}
@@ -479,3 +479,41 @@ func (a *api) ClearEmergency(serviceID string) (int, error) {
 
 	return client.ClearEmergency(serviceID)
 }
+
+// GetServiceTree returns a service and its descendants in the form that
+// ApplyServiceTree reads
+func (a *api) GetServiceTree(serviceID string) (*service.ServiceTree, error) {
+	client, err := a.connectMaster()
+	if err != nil {
+		return nil, err
+	}
+
+	return client.GetServiceTree(serviceID)
+}
+
+// ApplyServiceTree changes a service and its descendants to match the JSON
+// service tree in the reader, and returns the changes
+func (a *api) ApplyServiceTree(reader io.Reader, dryRun bool) ([]service.ServiceChange, error) {
+	// Unmarshal JSON from the reader
+	var tree service.ServiceTree
+	if err := json.NewDecoder(reader).Decode(&tree); err != nil {
+		return nil, fmt.Errorf("could not unmarshal json: %s", err)
+	}
+
+	client, err := a.connectMaster()
+	if err != nil {
+		return nil, err
+	}
+
+	return client.ApplyServiceTree(tree, dryRun)
+}
+
+// GetRolloutStatus returns the progress of the most recent rolling restart of a service
+func (a *api) GetRolloutStatus(serviceID string) (*service.RolloutStatus, error) {
+	client, err := a.connectMaster()
+	if err != nil {
+		return nil, err
+	}
+
+	return client.GetRolloutStatus(serviceID)
+}
@@ -156,7 +156,7 @@ func (a *api) RunShell(config ShellConfig, stopChan chan struct{}) (int, error)
 		return s, err
 	}
 
-	if err := svc.EvaluateRunsTemplate(getSvc, findChild); err != nil {
+	if err := svc.EvaluateRunsTemplate(getSvc, findChild, nil); err != nil {
 		return 1, fmt.Errorf("error evaluating service:%s Runs:%+v  error:%s", svc.ID, svc.Runs, err)
 	}
 	run, ok := svc.Commands[config.Command]
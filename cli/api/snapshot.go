@@ -18,6 +18,7 @@ import (
 
 	"github.com/control-center/serviced/config"
 	"github.com/control-center/serviced/dao"
+	"github.com/control-center/serviced/volume"
 )
 
 type SnapshotConfig struct {
@@ -161,3 +162,22 @@ func (a *api) RemoveSnapshotTag(serviceID string, tagName string) (string, error
 
 	return snapshotID, nil
 }
+
+// DiffSnapshot lists the files that differ between a snapshot and the live
+// volume, or between two snapshots if otherID is set
+func (a *api) DiffSnapshot(snapshotID, otherID string) ([]volume.FileChange, error) {
+	client, err := a.connectMaster()
+	if err != nil {
+		return nil, err
+	}
+	return client.DiffSnapshot(snapshotID, otherID)
+}
+
+// RestoreSnapshotPaths restores the given paths in place from a snapshot
+func (a *api) RestoreSnapshotPaths(snapshotID string, paths []string) error {
+	client, err := a.connectMaster()
+	if err != nil {
+		return err
+	}
+	return client.RestoreSnapshotPaths(snapshotID, paths)
+}
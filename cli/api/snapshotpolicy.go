@@ -0,0 +1,45 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"github.com/control-center/serviced/domain/snapshotpolicy"
+)
+
+// SetSnapshotPolicy adds or replaces the snapshot policy of a tenant
+func (a *api) SetSnapshotPolicy(policy snapshotpolicy.SnapshotPolicy) error {
+	client, err := a.connectMaster()
+	if err != nil {
+		return err
+	}
+	return client.SetSnapshotPolicy(policy)
+}
+
+// GetSnapshotPolicies returns the snapshot policies of all tenants
+func (a *api) GetSnapshotPolicies() ([]snapshotpolicy.SnapshotPolicy, error) {
+	client, err := a.connectMaster()
+	if err != nil {
+		return nil, err
+	}
+	return client.GetSnapshotPolicies()
+}
+
+// RemoveSnapshotPolicy removes the snapshot policy of a tenant
+func (a *api) RemoveSnapshotPolicy(tenantID string) error {
+	client, err := a.connectMaster()
+	if err != nil {
+		return err
+	}
+	return client.RemoveSnapshotPolicy(tenantID)
+}
@@ -32,6 +32,7 @@ func getDefaultStorageOptions(driverType volume.DriverType, config utils.ConfigR
 	switch driverType {
 	case volume.DriverTypeRsync:
 	case volume.DriverTypeBtrFS:
+	case volume.DriverTypeZFS:
 	case volume.DriverTypeDeviceMapper:
 		addStorageOption(config, "DM_THINPOOLDEV", "", func(v string) {
 			options = append(options, fmt.Sprintf("dm.thinpooldev=%s", v))
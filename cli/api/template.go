@@ -168,3 +168,13 @@ func (a *api) DeployServiceTemplate(config DeployTemplateConfig) ([]service.Serv
 
 	return svcs, nil
 }
+
+// UpgradeServiceTemplate merges a new template into a deployed tenant
+func (a *api) UpgradeServiceTemplate(request template.UpgradeRequest) (*template.Upgrade, error) {
+	client, err := a.connectMaster()
+	if err != nil {
+		return nil, err
+	}
+
+	return client.UpgradeTemplate(request)
+}
@@ -43,6 +43,11 @@ func (c *ServicedCli) initBackup() {
 					Name: "force",
 					Usage: "attempt backup even if space check fails",
 				},
+				cli.StringFlag{
+					Name:  "incremental",
+					Value: "",
+					Usage: "only back up changes since the given parent backup",
+				},
 			},
 		},
 		cli.Command{
@@ -51,6 +56,19 @@ func (c *ServicedCli) initBackup() {
 			Description: "serviced restore FILEPATH",
 			Action:      c.cmdRestore,
 		},
+		cli.Command{
+			Name:        "prune-backups",
+			Usage:       "Remove all but the newest backups",
+			Description: "serviced prune-backups --keep N [DIRPATH]",
+			Action:      c.cmdPruneBackups,
+			Flags: []cli.Flag{
+				cli.IntFlag{
+					Name:  "keep",
+					Value: 0,
+					Usage: "number of backups to keep",
+				},
+			},
+		},
 	)
 }
 
@@ -81,7 +99,7 @@ func (c *ServicedCli) cmdBackup(ctx *cli.Context)  {
 		return
 	}
 	// do backup
-	if path, err := c.driver.Backup(args[0], ctx.StringSlice("exclude"), ctx.Bool("force")); err != nil {
+	if path, err := c.driver.Backup(args[0], ctx.StringSlice("exclude"), ctx.Bool("force"), ctx.String("incremental")); err != nil {
 		fmt.Fprintln(os.Stdout, err)
 		c.exit(1)
 		return
@@ -108,3 +126,28 @@ func (c *ServicedCli) cmdRestore(ctx *cli.Context) {
 		fmt.Fprintln(os.Stderr, err)
 	}
 }
+
+// serviced prune-backups --keep N [DIRPATH]
+func (c *ServicedCli) cmdPruneBackups(ctx *cli.Context) {
+	args := ctx.Args()
+	keep := ctx.Int("keep")
+	if keep < 1 || len(args) > 1 {
+		fmt.Printf("Incorrect Usage.\n\n")
+		cli.ShowCommandHelp(ctx, "prune-backups")
+		c.exit(1)
+		return
+	}
+	dirpath := ""
+	if len(args) > 0 {
+		dirpath = args[0]
+	}
+	removed, err := c.driver.PruneBackups(dirpath, keep)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		c.exit(1)
+		return
+	}
+	for _, name := range removed {
+		fmt.Println(name)
+	}
+}
@@ -54,7 +54,7 @@ func InitBackupAPITestNoExit(args ...string) {
 	c.Run(args)
 }
 
-func (t BackupAPITest) Backup(dirpath string, excludes []string, force bool) (string, error) {
+func (t BackupAPITest) Backup(dirpath string, excludes []string, force bool, incremental string) (string, error) {
 	switch dirpath {
 	case PathNotFound:
 		return "", ErrBackupFailed
@@ -71,6 +71,15 @@ func (t BackupAPITest) Backup(dirpath string, excludes []string, force bool) (st
 	}
 }
 
+func (t BackupAPITest) PruneBackups(dirpath string, keep int) ([]string, error) {
+	switch dirpath {
+	case PathNotFound:
+		return nil, ErrBackupFailed
+	default:
+		return []string{"backup-1.tgz", "backup-2.tgz"}, nil
+	}
+}
+
 func (t BackupAPITest) Restore(path string) error {
 	switch path {
 	case PathNotFound:
@@ -148,6 +157,7 @@ func ExampleServicedCLI_CmdBackup_usage() {
 	//    --exclude '--exclude option --exclude option'	Subdirectory of the tenant volume to exclude from backup
 	//    --check						check space, but do not do backup
 	//    --force						attempt backup even if space check fails
+	//    --incremental 					only back up changes since the given parent backup
 }
 
 func ExampleServicedCLI_CmdBackup_noforce() {
@@ -202,3 +212,17 @@ func ExampleServicedCLI_CmdRestore_usage() {
 	// OPTIONS:
 }
 
+func ExampleServicedCLI_CmdPruneBackups() {
+	InitBackupAPITest("serviced", "prune-backups", "--keep", "3")
+
+	// Output:
+	// backup-1.tgz
+	// backup-2.tgz
+}
+
+func ExampleServicedCLI_CmdPruneBackups_fail() {
+	pipeStderr(func() { InitBackupAPITestNoExit("serviced", "prune-backups", "--keep", "3", PathNotFound) })
+
+	// Output:
+	// backup failed
+}
@@ -132,6 +132,8 @@ func New(driver api.API, config utils.ConfigReader, logControl logging.LogContro
 		cli.IntFlag{"zk-per-host-connect-delay", defaultOps.ZKPerHostConnectDelay, "zookeeper per-host delay in seconds"},
 		cli.IntFlag{"zk-reconnect-start-delay", defaultOps.ZKReconnectStartDelay, "zookeeper initial reconnect delay in seconds"},
 		cli.IntFlag{"zk-reconnect-max-delay", defaultOps.ZKReconnectMaxDelay, "zookeeper max recoonect delay in seconds"},
+		cli.StringFlag{"coordinator-driver", defaultOps.CoordinatorDriver, "backend of the cluster coordination, either zookeeper or etcd"},
+		cli.StringSliceFlag{"etcd-endpoint", convertToStringSlice(defaultOps.EtcdEndpoints), "client URL of an etcd member when the coordinator driver is etcd (e.g. --etcd-endpoint http://localhost:2379)"},
 		cli.IntFlag{"auth-token-expiry", defaultOps.TokenExpiration, "authentication token expiration in seconds"},
 		cli.StringFlag{"conntrack-flush", defaultOps.ConntrackFlush, "whether to flush the conntrack table when a service with an assigned IP is started"},
 		cli.IntFlag{"service-run-level-timeout", defaultOps.ServiceRunLevelTimeout, "max time in seconds to wait for services to start/stop before moving on to services at the next run level"},
@@ -147,6 +149,9 @@ func New(driver api.API, config utils.ConfigReader, logControl logging.LogContro
 		cli.IntFlag{"storage-metric-monitor-window", defaultOps.StorageMetricMonitorWindow, "the amount of time in seconds for which serviced will consider storage availability metrics in order to predict future availability"},
 		cli.IntFlag{"storage-lookahead-period", defaultOps.StorageLookaheadPeriod, "the amount of time in the future in seconds serviced should predict storage availability for the purposes of emergency shutdown"},
 		cli.StringFlag{"storage-min-free", string(defaultOps.StorageMinimumFreeSpace), "the amount of space the emergency shutdown algorithm should reserve when deciding to shut down"},
+		cli.IntFlag{"threshold-interval", defaultOps.ThresholdInterval, "frequency in seconds to evaluate the thresholds of services; 0 disables threshold evaluation"},
+		cli.StringFlag{"datastore-driver", defaultOps.DatastoreDriver, "backend of the control plane entities, either elastic or embedded"},
+		cli.StringFlag{"datastore-path", defaultOps.DatastorePath, "database file of the embedded datastore (defaults to controlplane.db in the isvcs path)"},
 
 		cli.IntFlag{"logstash-cycle-time", defaultOps.LogstashCycleTime, "logstash purging cycle time in hours"},
 		cli.IntFlag{"v", defaultOps.Verbosity, "log level for V logs"},
@@ -157,11 +162,31 @@ func New(driver api.API, config utils.ConfigReader, logControl logging.LogContro
 		cli.StringFlag{"allow-loop-back", defaultOps.AllowLoopBack, "allow loop-back device with devicemapper"},
 		cli.StringFlag{"backup-min-overhead", defaultOps.BackupMinOverhead, "Minimum free space to allow when calculating backup estimates"},
 		cli.Float64Flag{"backup-estimated-compression", defaultOps.BackupEstimatedCompression, "Estimate of compression rate to use when calculating backup estimates"},
+		cli.StringFlag{"backup-s3-endpoint", defaultOps.BackupS3Endpoint, "Endpoint of the S3-compatible object store for s3:// backup locations"},
+		cli.StringFlag{"backup-s3-region", defaultOps.BackupS3Region, "Region of the S3-compatible object store for s3:// backup locations"},
+		cli.StringFlag{"backup-s3-access-key-id", defaultOps.BackupS3AccessKeyID, "Access key for the S3-compatible object store for s3:// backup locations"},
+		cli.StringFlag{"backup-s3-secret-access-key", defaultOps.BackupS3SecretAccessKey, "Secret key for the S3-compatible object store for s3:// backup locations"},
+		cli.IntFlag{"backup-s3-part-size", defaultOps.BackupS3PartSize, "Size in MiB of each part uploaded to the S3-compatible object store; backups are limited to 10,000 parts"},
+		cli.StringFlag{"backup-compression", defaultOps.BackupCompression, "Compression used for backups (gzip or zstd)"},
+		cli.StringFlag{"backup-encryption-key-file", defaultOps.BackupEncryptionKeyFile, "File containing the 32-byte key used to encrypt and decrypt backups"},
+		cli.StringSliceFlag{"backup-recipient", convertToStringSlice(defaultOps.BackupRecipients), "PEM file of a P-256 public key to encrypt backups to"},
+		cli.StringSliceFlag{"backup-identity", convertToStringSlice(defaultOps.BackupIdentities), "PEM file of a P-256 private key used to decrypt backups"},
+		cli.StringFlag{"secret-key-file", defaultOps.SecretKeyFile, "File containing the 32-byte key used to encrypt and decrypt secrets"},
 		cli.StringFlag{"auth0-domain", defaultOps.Auth0Domain, "Domain configured for tenant in Auth0. Ref: https://auth0.com/docs/getting-started/the-basics#domain"},
 		cli.StringFlag{"auth0-audience", defaultOps.Auth0Audience, "Audience configured for application (?) in Auth0."},
 		cli.StringSliceFlag{"auth0-group", convertToStringSlice(defaultOps.Auth0Group), "Group(s) configured for application in Auth0. A comma-separated list."},
 		cli.StringFlag{"auth0-client-id", defaultOps.Auth0ClientID, "Client ID of Auth0 application"},
 		cli.StringFlag{"auth0-scope", defaultOps.Auth0Scope, "Scope to request in Auth0"},
+		cli.StringFlag{"oidc-issuer", defaultOps.OIDCIssuer, "Issuer URL of the OpenID Connect provider used to log in to the UI"},
+		cli.StringFlag{"oidc-client-id", defaultOps.OIDCClientID, "Client ID registered with the OIDC provider"},
+		cli.StringFlag{"oidc-client-secret", defaultOps.OIDCClientSecret, "Client secret registered with the OIDC provider"},
+		cli.StringFlag{"oidc-audience", defaultOps.OIDCAudience, "Additional audience accepted for OIDC access tokens"},
+		cli.StringFlag{"oidc-scope", defaultOps.OIDCScope, "Scopes requested from the OIDC provider"},
+		cli.StringFlag{"oidc-username-claim", defaultOps.OIDCUsernameClaim, "Claim holding the user name"},
+		cli.StringFlag{"oidc-admin-claim", defaultOps.OIDCAdminClaim, "Claim holding the user's groups or roles"},
+		cli.StringSliceFlag{"oidc-admin-values", convertToStringSlice(defaultOps.OIDCAdminValues), "Values of the admin claim that grant access. A comma-separated list."},
+		cli.StringFlag{"oidc-redirect-url", defaultOps.OIDCRedirectURL, "External URL of /oidc/callback"},
+		cli.StringSliceFlag{"auth-role", convertToStringSlice(defaultOps.AuthRoles), "Role given to members of a user group, as GROUP=ROLE[:pool=POOLID|:tenant=TENANTID]. ROLE is viewer, operator or admin"},
 		cli.StringFlag{"keyproxy-json-server", defaultOps.KeyProxyJsonServer, "URL for API key server (cc auth token endpoint)"},
 		cli.StringFlag{"keyproxy-listen-port", defaultOps.KeyProxyListenPort, "Port for API key proxy to listen on"},
 	}
@@ -181,6 +206,9 @@ func New(driver api.API, config utils.ConfigReader, logControl logging.LogContro
 	c.initScript()
 	c.initServer()
 	c.initVolume()
+	c.initMigrate()
+	c.initEvents()
+	c.initSecret()
 	c.initKey()
 	c.initDebug()
 
@@ -233,6 +261,7 @@ func getRuntimeOptions(cfg utils.ConfigReader, ctx *cli.Context) config.Options
 		StartZK:                    cfg.BoolVal("START_ZK", true),
 		StartAPIKeyProxy:           cfg.BoolVal("START_API_KEY_PROXY", false),
 		BigTableMetrics:            cfg.BoolVal("BIGTABLE_METRICS", false),
+		BackupIncremental:          cfg.BoolVal("BACKUP_INCREMENTAL", false),
 		DockerRegistry:             ctx.GlobalString("docker-registry"),
 		NFSClient:                  ctx.GlobalString("nfs-client"),
 		Endpoint:                   ctx.GlobalString("endpoint"),
@@ -306,19 +335,44 @@ func getRuntimeOptions(cfg utils.ConfigReader, ctx *cli.Context) config.Options
 		ZKPerHostConnectDelay:      ctx.GlobalInt("zk-per-host-connect-delay"),
 		ZKReconnectStartDelay:      ctx.GlobalInt("zk-reconnect-start-delay"),
 		ZKReconnectMaxDelay:        ctx.GlobalInt("zk-reconnect-max-delay"),
+		CoordinatorDriver:          ctx.GlobalString("coordinator-driver"),
+		EtcdEndpoints:              ctx.GlobalStringSlice("etcd-endpoint"),
 		TokenExpiration:            ctx.GlobalInt("auth-token-expiry"),
 		ConntrackFlush:             ctx.GlobalString("conntrack-flush"),
 		ServiceRunLevelTimeout:     ctx.GlobalInt("service-run-level-timeout"),
 		StorageMetricMonitorWindow: ctx.GlobalInt("storage-metric-monitor-window"),
 		StorageLookaheadPeriod:     ctx.GlobalInt("storage-lookahead-period"),
 		StorageMinimumFreeSpace:    ctx.GlobalString("storage-min-free"),
+		ThresholdInterval:          ctx.GlobalInt("threshold-interval"),
+		DatastoreDriver:            ctx.GlobalString("datastore-driver"),
+		DatastorePath:              ctx.GlobalString("datastore-path"),
 		BackupEstimatedCompression: ctx.Float64("backup-estimated-compression"),
 		BackupMinOverhead:          ctx.String("backup-min-overhead"),
+		BackupS3Endpoint:           ctx.GlobalString("backup-s3-endpoint"),
+		BackupS3Region:             ctx.GlobalString("backup-s3-region"),
+		BackupS3AccessKeyID:        ctx.GlobalString("backup-s3-access-key-id"),
+		BackupS3SecretAccessKey:    ctx.GlobalString("backup-s3-secret-access-key"),
+		BackupS3PartSize:           ctx.GlobalInt("backup-s3-part-size"),
+		BackupCompression:          ctx.GlobalString("backup-compression"),
+		BackupEncryptionKeyFile:    ctx.GlobalString("backup-encryption-key-file"),
+		BackupRecipients:           ctx.GlobalStringSlice("backup-recipient"),
+		BackupIdentities:           ctx.GlobalStringSlice("backup-identity"),
+		SecretKeyFile:              ctx.GlobalString("secret-key-file"),
 		Auth0Domain:                ctx.String("auth0-domain"),
 		Auth0Audience:              ctx.String("auth0-audience"),
 		Auth0Group:                 ctx.GlobalStringSlice("auth0-group"),
 		Auth0ClientID:              ctx.String("auth0-client-id"),
 		Auth0Scope:                 ctx.String("auth0-scope"),
+		OIDCIssuer:                 ctx.GlobalString("oidc-issuer"),
+		OIDCClientID:               ctx.GlobalString("oidc-client-id"),
+		OIDCClientSecret:           ctx.GlobalString("oidc-client-secret"),
+		OIDCAudience:               ctx.GlobalString("oidc-audience"),
+		OIDCScope:                  ctx.GlobalString("oidc-scope"),
+		OIDCUsernameClaim:          ctx.GlobalString("oidc-username-claim"),
+		OIDCAdminClaim:             ctx.GlobalString("oidc-admin-claim"),
+		OIDCAdminValues:            ctx.GlobalStringSlice("oidc-admin-values"),
+		OIDCRedirectURL:            ctx.GlobalString("oidc-redirect-url"),
+		AuthRoles:                  ctx.GlobalStringSlice("auth-role"),
 		KeyProxyJsonServer:         ctx.String("keyproxy-json-server"),
 		KeyProxyListenPort:         ctx.String("keyproxy-listen-port"),
 	}
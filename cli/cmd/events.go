@@ -0,0 +1,151 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/codegangsta/cli"
+	"github.com/control-center/serviced/events"
+)
+
+// eventPollTimeout is how long each request of serviced events --follow waits
+// for new events
+const eventPollTimeout = 30 * time.Second
+
+// Initializer for serviced events
+func (c *ServicedCli) initEvents() {
+	c.app.Commands = append(c.app.Commands, cli.Command{
+		Name:        "events",
+		Usage:       "Shows state changes of services, instances, hosts and backups",
+		Description: "serviced events [--follow] [--type TYPE,...] [--service SERVICE] [--host HOSTID] [--pool POOLID] [--since EVENTID]",
+		Action:      c.cmdEvents,
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "follow, f",
+				Usage: "Keep printing events as they happen",
+			},
+			cli.StringFlag{
+				Name:  "type",
+				Value: "",
+				Usage: "Comma separated event types or type prefixes, such as service,instance.health",
+			},
+			cli.StringFlag{
+				Name:  "service",
+				Value: "",
+				Usage: "Only show events of the service",
+			},
+			cli.StringFlag{
+				Name:  "host",
+				Value: "",
+				Usage: "Only show events of the host",
+			},
+			cli.StringFlag{
+				Name:  "pool",
+				Value: "",
+				Usage: "Only show events in the resource pool",
+			},
+			cli.IntFlag{
+				Name:  "since",
+				Value: 0,
+				Usage: "Only show events after the event with this id",
+			},
+			cli.BoolFlag{
+				Name:  "verbose, v",
+				Usage: "Show JSON format",
+			},
+		},
+	})
+}
+
+// serviced events [--follow] [--type TYPE,...] [--service SERVICE] [--host HOSTID] [--pool POOLID] [--since EVENTID]
+func (c *ServicedCli) cmdEvents(ctx *cli.Context) {
+	filter := events.Filter{
+		Types:  events.ParseTypes(ctx.String("type")),
+		HostID: ctx.String("host"),
+		PoolID: ctx.String("pool"),
+	}
+	if name := ctx.String("service"); name != "" {
+		svc, _, err := c.searchForService(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return
+		}
+		filter.ServiceID = svc.ID
+	}
+	since := ctx.Int("since")
+	if since < 0 {
+		fmt.Fprintln(os.Stderr, "since must be an event id")
+		return
+	}
+
+	last := uint64(since)
+	var timeout time.Duration
+	for {
+		evts, err := c.driver.GetEvents(filter, last, timeout)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return
+		}
+		for _, event := range evts {
+			printEvent(event, ctx.Bool("verbose"))
+			last = event.ID
+		}
+		if !ctx.Bool("follow") {
+			return
+		}
+		timeout = eventPollTimeout
+	}
+}
+
+func printEvent(event events.Event, verbose bool) {
+	if verbose {
+		if jsonEvent, err := json.Marshal(event); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to marshal event: %s", err)
+		} else {
+			fmt.Println(string(jsonEvent))
+		}
+		return
+	}
+
+	subject := event.Name
+	if subject == "" {
+		subject = event.ServiceID
+	}
+	if subject == "" {
+		subject = event.HostID
+	}
+	if strings.HasPrefix(string(event.Type), "instance.") {
+		subject = fmt.Sprintf("%s/%d", subject, event.InstanceID)
+		if event.Type == events.InstanceHealth {
+			subject = fmt.Sprintf("%s/%d %s", event.ServiceID, event.InstanceID, event.Name)
+		}
+	}
+	state := event.State
+	if state == "" {
+		state = "removed"
+	}
+	if event.Previous != "" {
+		state = fmt.Sprintf("%s -> %s", event.Previous, state)
+	}
+	line := fmt.Sprintf("%d  %s  %-22s %s: %s", event.ID, event.Timestamp.Local().Format(time.RFC3339), event.Type, subject, state)
+	if event.Message != "" {
+		line += " (" + event.Message + ")"
+	}
+	fmt.Println(line)
+}
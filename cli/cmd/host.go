@@ -115,6 +115,12 @@ func (c *ServicedCli) initHost() {
 				Description:  "serviced host set-memory HOSTID ALLOCATION",
 				BashComplete: c.printHostsAll,
 				Action:       c.cmdHostSetMemory,
+			}, {
+				Name:         "set-labels",
+				Usage:        "Set or remove the labels on a specific host",
+				Description:  "serviced host set-labels HOSTID LABEL=VALUE ... (an empty VALUE removes the label)",
+				BashComplete: c.printHostsAll,
+				Action:       c.cmdHostSetLabels,
 			},
 		},
 	})
@@ -419,6 +425,30 @@ func (c *ServicedCli) cmdHostSetMemory(ctx *cli.Context) {
 	}
 }
 
+// serviced host set-labels HOSTID LABEL=VALUE ...
+func (c *ServicedCli) cmdHostSetLabels(ctx *cli.Context) {
+	args := ctx.Args()
+	if len(args) < 2 {
+		fmt.Printf("Incorrect Usage.\n\n")
+		cli.ShowCommandHelp(ctx, "set-labels")
+		return
+	}
+
+	labels := make(map[string]string)
+	for _, arg := range args[1:] {
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			fmt.Fprintf(os.Stderr, "invalid label %s; expected LABEL=VALUE\n", arg)
+			return
+		}
+		labels[parts[0]] = parts[1]
+	}
+
+	if err := c.driver.SetHostLabels(api.HostLabelConfig{HostID: args[0], Labels: labels}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
 // serviced host register (KEYSFILE | -)
 func (c *ServicedCli) cmdHostRegister(ctx *cli.Context) {
 	args := ctx.Args()
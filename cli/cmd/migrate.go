@@ -0,0 +1,112 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/codegangsta/cli"
+	"github.com/control-center/serviced/datastore/migration"
+)
+
+// Initializer for serviced migrate subcommands
+func (c *ServicedCli) initMigrate() {
+	c.app.Commands = append(c.app.Commands, cli.Command{
+		Name:        "migrate",
+		Usage:       "Administers schema migrations of stored entities",
+		Description: "",
+		Subcommands: []cli.Command{
+			{
+				Name:        "status",
+				Usage:       "Lists the schema migrations and whether they have been applied",
+				Description: "serviced migrate status",
+				Action:      c.cmdMigrateStatus,
+				Flags: []cli.Flag{
+					cli.BoolFlag{
+						Name:  "verbose, v",
+						Usage: "Show JSON format",
+					},
+				},
+			}, {
+				Name:        "up",
+				Usage:       "Applies the schema migrations that have not been applied",
+				Description: "serviced migrate up",
+				Action:      c.cmdMigrateUp,
+				Flags: []cli.Flag{
+					cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "Count the entities each migration would change without changing them",
+					},
+					cli.BoolFlag{
+						Name:  "verbose, v",
+						Usage: "Show JSON format",
+					},
+				},
+			},
+		},
+	})
+}
+
+// serviced migrate status [--verbose, -v]
+func (c *ServicedCli) cmdMigrateStatus(ctx *cli.Context) {
+	statuses, err := c.driver.GetMigrationStatus()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+	printMigrations(statuses, ctx.Bool("verbose"))
+}
+
+// serviced migrate up [--dry-run] [--verbose, -v]
+func (c *ServicedCli) cmdMigrateUp(ctx *cli.Context) {
+	statuses, err := c.driver.MigrateEntities(ctx.Bool("dry-run"))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	} else if len(statuses) == 0 {
+		fmt.Fprintln(os.Stderr, "no pending migrations")
+		return
+	}
+	printMigrations(statuses, ctx.Bool("verbose"))
+}
+
+func printMigrations(statuses []migration.Status, verbose bool) {
+	if verbose {
+		if jsonStatuses, err := json.MarshalIndent(statuses, " ", "  "); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to marshal migration list: %s", err)
+		} else {
+			fmt.Println(string(jsonStatuses))
+		}
+		return
+	}
+	t := NewTable("ID,Kind,Applied,Changed,Description")
+	t.Padding = 2
+	for _, s := range statuses {
+		applied := "--"
+		if s.Applied {
+			applied = s.AppliedAt.Local().Format(time.RFC3339)
+		}
+		t.AddRow(map[string]interface{}{
+			"ID":          s.ID,
+			"Kind":        s.Kind,
+			"Applied":     applied,
+			"Changed":     s.Changed,
+			"Description": s.Description,
+		})
+	}
+	t.Print()
+}
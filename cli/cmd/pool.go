@@ -65,6 +65,14 @@ func (c *ServicedCli) initPool() {
 						Name:  "admin",
 						Usage: "Allow pool to use administrative functions",
 					},
+					cli.BoolFlag{
+						Name:  "operator",
+						Usage: "Allow pool to start, stop and restart services",
+					},
+					cli.BoolFlag{
+						Name:  "viewer",
+						Usage: "Allow pool to read services, hosts and pools",
+					},
 				},
 			}, {
 				Name:         "remove",
@@ -123,6 +131,14 @@ func (c *ServicedCli) initPool() {
 						Name:  "admin",
 						Usage: "Control permission to use administrative functions",
 					},
+					cli.BoolFlag{
+						Name:  "operator",
+						Usage: "Control permission to start, stop and restart services",
+					},
+					cli.BoolFlag{
+						Name:  "viewer",
+						Usage: "Control permission to read services, hosts and pools",
+					},
 				},
 			},
 		},
@@ -212,6 +228,12 @@ func (c *ServicedCli) cmdPoolList(ctx *cli.Context) {
 			if p.HasAdminAccess() {
 				perms = append(perms, "Admin")
 			}
+			if p.HasOperatorAccess() {
+				perms = append(perms, "Operator")
+			}
+			if p.HasViewerAccess() {
+				perms = append(perms, "Viewer")
+			}
 			t.AddRow(map[string]interface{}{
 				"ID":          p.ID,
 				"Permissions": perms,
@@ -260,6 +282,8 @@ func (c *ServicedCli) cmdPoolAdd(ctx *cli.Context) {
 	}
 	updatePerms("dfs", pool.DFSAccess)
 	updatePerms("admin", pool.AdminAccess)
+	updatePerms("operator", pool.OperatorAccess)
+	updatePerms("viewer", pool.ViewerAccess)
 
 	if pool, err := c.driver.AddResourcePool(cfg); err != nil {
 		fmt.Fprintln(os.Stderr, err)
@@ -433,6 +457,8 @@ func (c *ServicedCli) cmdSetPermission(ctx *cli.Context) {
 	}
 	updatePerms("dfs", pool.DFSAccess)
 	updatePerms("admin", pool.AdminAccess)
+	updatePerms("operator", pool.OperatorAccess)
+	updatePerms("viewer", pool.ViewerAccess)
 
 	// Fold the accumulated permissions into the current permissions
 	p.Permissions &^= perm_mask
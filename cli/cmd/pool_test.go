@@ -303,6 +303,10 @@ func TestServicedCLI_CmdPoolAdd_perm(t *testing.T) {
 	poolID = "pool_Both"
 	RunCmd(test, "serviced", "pool", "add", "--dfs", "--admin", poolID)
 	assertPerm(poolID, pool.DFSAccess|pool.AdminAccess)
+
+	poolID = "pool_Operator"
+	RunCmd(test, "serviced", "pool", "add", "--dfs", "--operator", poolID)
+	assertPerm(poolID, pool.DFSAccess|pool.OperatorAccess)
 }
 
 func ExampleServicedCLI_CmdPoolRemove() {
@@ -446,4 +450,6 @@ func TestServicedCLI_CmdPoolSetPermission(t *testing.T) {
 	assertPerm(poolID, pool.DFSAccess)
 	RunCmd(test, "serviced", "pool", "set-permission", "--admin", "--dfs=false", poolID)
 	assertPerm(poolID, pool.AdminAccess)
+	RunCmd(test, "serviced", "pool", "set-permission", "--admin=false", "--viewer", poolID)
+	assertPerm(poolID, pool.ViewerAccess)
 }
@@ -14,6 +14,7 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/signal"
@@ -46,7 +47,7 @@ func (c *ServicedCli) initScript() {
 			{
 				Name:        "run",
 				Usage:       "Run a script",
-				Description: "serviced script run FILE [--service SERVICEID] [-n]",
+				Description: "serviced script run FILE [--service SERVICEID] [-n] [--plan [-v]]",
 				Action:      c.cmdScriptRun,
 				Flags: []cli.Flag{
 					cli.StringFlag{
@@ -58,6 +59,14 @@ func (c *ServicedCli) initScript() {
 						Name:  "no-op, n",
 						Usage: "Run through script without modifying system",
 					},
+					cli.BoolFlag{
+						Name:  "plan",
+						Usage: "Report what each line of the script would change without modifying system",
+					},
+					cli.BoolFlag{
+						Name:  "verbose, v",
+						Usage: "Show the plan in JSON format",
+					},
 				},
 			},
 		},
@@ -99,6 +108,11 @@ func (c *ServicedCli) cmdScriptRun(ctx *cli.Context) {
 		config.ServiceID = svc.ID
 	}
 
+	if ctx.Bool("plan") {
+		planScript(c, ctx, fileName, config)
+		return
+	}
+
 	// exec unix script command to log output
 	if isWithin := os.Getenv("IS_WITHIN_UNIX_SCRIPT"); isWithin != "TRUE" {
 		os.Setenv("IS_WITHIN_UNIX_SCRIPT", "TRUE") // prevent inception problem
@@ -145,6 +159,79 @@ func (c *ServicedCli) cmdScriptParse(ctx *cli.Context) {
 	return
 }
 
+func planScript(c *ServicedCli, ctx *cli.Context, fileName string, config *script.Config) {
+	plan, err := c.driver.ScriptPlan(fileName, config)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		c.exit(1)
+		return
+	}
+
+	if ctx.Bool("verbose") {
+		if jsonPlan, err := json.MarshalIndent(plan, " ", "  "); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to marshal plan: %s\n", err)
+			c.exit(1)
+			return
+		} else {
+			fmt.Println(string(jsonPlan))
+		}
+	} else {
+		printPlan(plan)
+	}
+
+	if plan.HasErrors() {
+		c.exit(1)
+	}
+}
+
+// printPlan writes a plan as text, one entry per line of the script
+func printPlan(plan *script.Plan) {
+	for _, step := range plan.Steps {
+		if step.Command == script.DESCRIPTION || step.Command == script.VERSION || step.Command == script.DEPENDENCY {
+			continue
+		}
+		indent := strings.Repeat("  ", step.Depth)
+		var flags []string
+		if step.OnError {
+			flags = append(flags, "on error")
+		}
+		if step.Skipped {
+			flags = append(flags, "skipped")
+		}
+		header := fmt.Sprintf("line %d: %s%s", step.LineNum, indent, step.Line)
+		if len(flags) > 0 {
+			header = fmt.Sprintf("%s (%s)", header, strings.Join(flags, ", "))
+		}
+		fmt.Println(header)
+		if step.Skipped {
+			continue
+		}
+		detail := indent + "    "
+		if step.Summary != "" {
+			fmt.Printf("%s%s\n", detail, step.Summary)
+		}
+		if len(step.Images) > 0 {
+			for _, img := range step.Images {
+				if img.ServicePath == "" {
+					fmt.Printf("%s  %s -> %s\n", detail, img.From, img.To)
+				} else {
+					fmt.Printf("%s  %s: %s -> %s\n", detail, img.ServicePath, img.From, img.To)
+				}
+			}
+		} else {
+			for _, svcPath := range step.Services {
+				fmt.Printf("%s  %s\n", detail, svcPath)
+			}
+		}
+		for _, warning := range step.Warnings {
+			fmt.Printf("%sWARNING: %s\n", detail, warning)
+		}
+		if step.Error != "" {
+			fmt.Printf("%sERROR: %s\n", detail, step.Error)
+		}
+	}
+}
+
 func runScript(c *ServicedCli, ctx *cli.Context, fileName string, config *script.Config) {
 	stopChan := make(chan struct{})
 	signalHandlerChan := make(chan os.Signal)
@@ -0,0 +1,205 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/codegangsta/cli"
+	"github.com/control-center/serviced/domain/secret"
+)
+
+// Initializer for serviced secret subcommands
+func (c *ServicedCli) initSecret() {
+	c.app.Commands = append(c.app.Commands, cli.Command{
+		Name:        "secret",
+		Usage:       "Administers secrets that are delivered to services",
+		Description: "",
+		Subcommands: []cli.Command{
+			{
+				Name:        "list",
+				Usage:       "Lists all secrets without their values",
+				Description: "serviced secret list",
+				Action:      c.cmdSecretList,
+				Flags: []cli.Flag{
+					cli.BoolFlag{
+						Name:  "verbose, v",
+						Usage: "Show JSON format",
+					},
+				},
+			}, {
+				Name:        "add",
+				Usage:       "Adds a secret, reading its value from a file or standard input",
+				Description: "serviced secret add NAME",
+				Action:      c.cmdSecretAdd,
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "description",
+						Usage: "Description of the secret",
+					},
+					cli.StringFlag{
+						Name:  "file",
+						Usage: "File containing the value of the secret (default: standard input)",
+					},
+				},
+			}, {
+				Name:        "rotate",
+				Usage:       "Replaces the value of a secret; running containers keep the previous value until they restart",
+				Description: "serviced secret rotate NAME",
+				Action:      c.cmdSecretRotate,
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "file",
+						Usage: "File containing the new value of the secret (default: standard input)",
+					},
+				},
+			}, {
+				Name:         "remove",
+				ShortName:    "rm",
+				Usage:        "Removes a secret",
+				Description:  "serviced secret remove NAME",
+				BashComplete: c.printSecretsAll,
+				Action:       c.cmdSecretRemove,
+			},
+		},
+	})
+}
+
+// Bash-completion command that prints the names of all secrets
+func (c *ServicedCli) printSecretsAll(ctx *cli.Context) {
+	if len(ctx.Args()) > 0 {
+		return
+	}
+	secrets, err := c.driver.GetSecrets()
+	if err != nil {
+		return
+	}
+	for _, s := range secrets {
+		fmt.Println(s.Name)
+	}
+}
+
+// readSecretValue reads the value of a secret from a file or standard input,
+// so that it never appears in the process list or the shell history.  A
+// single trailing newline is removed.
+func readSecretValue(filename string) ([]byte, error) {
+	var value []byte
+	var err error
+	if filename == "" || filename == "-" {
+		value, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		value, err = ioutil.ReadFile(filename)
+	}
+	if err != nil {
+		return nil, err
+	}
+	value = bytes.TrimSuffix(value, []byte("\n"))
+	value = bytes.TrimSuffix(value, []byte("\r"))
+	if len(value) == 0 {
+		return nil, fmt.Errorf("the value of the secret is empty")
+	}
+	return value, nil
+}
+
+// serviced secret list [--verbose, -v]
+func (c *ServicedCli) cmdSecretList(ctx *cli.Context) {
+	secrets, err := c.driver.GetSecrets()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	} else if len(secrets) == 0 {
+		fmt.Fprintln(os.Stderr, "no secrets found")
+		return
+	}
+	if ctx.Bool("verbose") {
+		if jsonSecrets, err := json.MarshalIndent(secrets, " ", "  "); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to marshal secret list: %s", err)
+		} else {
+			fmt.Println(string(jsonSecrets))
+		}
+		return
+	}
+	t := NewTable("Name,Value,Version,Updated,Description")
+	t.Padding = 2
+	for _, s := range secrets {
+		t.AddRow(map[string]interface{}{
+			"Name":        s.Name,
+			"Value":       secret.Redacted,
+			"Version":     s.Version,
+			"Updated":     s.UpdatedAt.Local().Format(time.RFC3339),
+			"Description": s.Description,
+		})
+	}
+	t.Print()
+}
+
+// serviced secret add [--description DESCRIPTION] [--file FILE] NAME
+func (c *ServicedCli) cmdSecretAdd(ctx *cli.Context) {
+	args := ctx.Args()
+	if len(args) != 1 {
+		fmt.Printf("Incorrect Usage.\n\n")
+		cli.ShowCommandHelp(ctx, "add")
+		return
+	}
+	value, err := readSecretValue(ctx.String("file"))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+	if err := c.driver.AddSecret(args[0], ctx.String("description"), value); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+	fmt.Println(args[0])
+}
+
+// serviced secret rotate [--file FILE] NAME
+func (c *ServicedCli) cmdSecretRotate(ctx *cli.Context) {
+	args := ctx.Args()
+	if len(args) != 1 {
+		fmt.Printf("Incorrect Usage.\n\n")
+		cli.ShowCommandHelp(ctx, "rotate")
+		return
+	}
+	value, err := readSecretValue(ctx.String("file"))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+	if err := c.driver.RotateSecret(args[0], value); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+	fmt.Println(args[0])
+}
+
+// serviced secret remove NAME
+func (c *ServicedCli) cmdSecretRemove(ctx *cli.Context) {
+	args := ctx.Args()
+	if len(args) != 1 {
+		fmt.Printf("Incorrect Usage.\n\n")
+		cli.ShowCommandHelp(ctx, "remove")
+		return
+	}
+	if err := c.driver.RemoveSecret(args[0]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+	fmt.Println(args[0])
+}
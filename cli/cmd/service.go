@@ -483,6 +483,41 @@ func (c *ServicedCli) initService() {
 				BashComplete: c.printServicesFirst,
 				Action:       c.cmdServiceClearEmergency,
 			},
+			{
+				Name:         "rollout-status",
+				Usage:        "Shows the progress of the most recent rolling restart of a service",
+				Description:  "serviced service rollout-status { SERVICEID | SERVICENAME | DEPLOYMENTID/...PARENTNAME.../SERVICENAME }",
+				BashComplete: c.printServicesFirst,
+				Action:       c.cmdServiceRolloutStatus,
+				Flags: []cli.Flag{
+					cli.BoolFlag{
+						Name:  "verbose, v",
+						Usage: "Show JSON format",
+					},
+				},
+			}, {
+				Name:         "tree",
+				Usage:        "Prints a service and its descendants as a service tree",
+				Description:  "serviced service tree { SERVICEID | SERVICENAME | DEPLOYMENTID/...PARENTNAME.../SERVICENAME }",
+				BashComplete: c.printServicesFirst,
+				Action:       c.cmdServiceTree,
+			}, {
+				Name:        "apply",
+				Usage:       "Changes a service and its descendants to match a service tree",
+				Description: "serviced service apply [--dry-run] -f FILE",
+				Action:      c.cmdServiceApply,
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "file, f",
+						Value: "",
+						Usage: "Service tree to apply (use - for stdin)",
+					},
+					cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "Show the changes without applying them",
+					},
+				},
+			},
 			{
 				Name:         "remove-ip",
 				Usage:        "Remove the IP assignment of a service's endpoints",
@@ -1818,3 +1853,116 @@ func (c *ServicedCli) cmdServiceClearEmergency(ctx *cli.Context) {
 
 	fmt.Printf("Cleared emergency status for %d services\n", count)
 }
+
+// serviced service rollout-status [--verbose, -v] { SERVICEID | SERVICENAME | DEPLOYMENTID/...PARENTNAME.../SERVICENAME }
+func (c *ServicedCli) cmdServiceRolloutStatus(ctx *cli.Context) {
+	// verify args
+	args := ctx.Args()
+	if len(args) < 1 {
+		fmt.Printf("Incorrect Usage.\n\n")
+		cli.ShowCommandHelp(ctx, "rollout-status")
+		return
+	}
+
+	svc, _, err := c.searchForService(ctx.Args().First())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	status, err := c.driver.GetRolloutStatus(svc.ID)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	if ctx.Bool("verbose") {
+		if jsonStatus, err := json.MarshalIndent(status, " ", "  "); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to marshal rollout status: %s", err)
+		} else {
+			fmt.Println(string(jsonStatus))
+		}
+		return
+	}
+
+	fmt.Printf("%s: %s, batch %d of %d, %d of %d instances restarted\n", svc.Name, status.State, status.Batch, status.Batches, status.Restarted, status.Instances)
+	if status.PreviousImage != "" {
+		fmt.Printf("Image: %s (previous %s)\n", status.ImageID, status.PreviousImage)
+	}
+	if status.Error != "" {
+		fmt.Printf("Error: %s\n", status.Error)
+	}
+}
+
+// serviced service tree { SERVICEID | SERVICENAME | DEPLOYMENTID/...PARENTNAME.../SERVICENAME }
+func (c *ServicedCli) cmdServiceTree(ctx *cli.Context) {
+	// verify args
+	args := ctx.Args()
+	if len(args) < 1 {
+		fmt.Printf("Incorrect Usage.\n\n")
+		cli.ShowCommandHelp(ctx, "tree")
+		return
+	}
+
+	svc, _, err := c.searchForService(ctx.Args().First())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	tree, err := c.driver.GetServiceTree(svc.ID)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	if jsonTree, err := json.MarshalIndent(tree, " ", "  "); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to marshal service tree: %s\n", err)
+	} else {
+		fmt.Println(string(jsonTree))
+	}
+}
+
+// serviced service apply [--dry-run] -f FILE
+func (c *ServicedCli) cmdServiceApply(ctx *cli.Context) {
+	filepath := ctx.String("file")
+	if filepath == "" {
+		fmt.Printf("Incorrect Usage.\n\n")
+		cli.ShowCommandHelp(ctx, "apply")
+		return
+	}
+
+	var input *os.File
+	if filepath == "-" {
+		input = os.Stdin
+	} else {
+		var err error
+		if input, err = os.Open(filepath); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return
+		}
+		defer input.Close()
+	}
+
+	dryRun := ctx.Bool("dry-run")
+	changes, err := c.driver.ApplyServiceTree(input, dryRun)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	if len(changes) == 0 {
+		fmt.Println("No changes")
+		return
+	}
+
+	for _, change := range changes {
+		fmt.Printf("%s %s (%s)\n", change.Action, change.Path, change.ServiceID)
+		for _, field := range change.Fields {
+			fmt.Printf("    %s: %s -> %s\n", field.Field, field.Old, field.New)
+		}
+	}
+	if dryRun {
+		fmt.Println("Dry run: no changes were applied")
+	}
+}
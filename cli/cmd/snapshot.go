@@ -14,13 +14,16 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/codegangsta/cli"
 	"github.com/control-center/serviced/cli/api"
 	"github.com/control-center/serviced/dao"
+	"github.com/control-center/serviced/domain/snapshotpolicy"
 )
 
 // initSnapshot is the initializer for serviced snapshot
@@ -90,6 +93,24 @@ func (c *ServicedCli) initSnapshot() {
 				},
 				BashComplete: c.printSnapshotsFirst,
 				Action:       c.cmdSnapshotRollback,
+			}, {
+				Name:         "diff",
+				Usage:        "Lists the files that differ between a snapshot and the live volume or another snapshot",
+				Description:  "serviced snapshot diff SNAPSHOTID [SNAPSHOTID]",
+				BashComplete: c.printSnapshotsAll,
+				Action:       c.cmdSnapshotDiff,
+				Flags: []cli.Flag{
+					cli.BoolFlag{
+						Name:  "verbose, v",
+						Usage: "Show JSON format",
+					},
+				},
+			}, {
+				Name:         "restore-path",
+				Usage:        "Restores files and directories in place from a snapshot",
+				Description:  "serviced snapshot restore-path SNAPSHOTID PATH ...",
+				BashComplete: c.printSnapshotsFirst,
+				Action:       c.cmdSnapshotRestorePath,
 			}, {
 				Name:         "tag",
 				Usage:        "Tags an existing snapshot with TAG-NAME",
@@ -102,6 +123,68 @@ func (c *ServicedCli) initSnapshot() {
 				Description:  "serviced snapshot untag SERVICEID TAG-NAME",
 				BashComplete: c.printServicesFirst,
 				Action:       c.cmdSnapshotRemoveTag,
+			}, {
+				Name:        "policy",
+				Usage:       "Administers scheduled snapshot policies",
+				Description: "serviced snapshot policy",
+				Subcommands: []cli.Command{
+					{
+						Name:        "list",
+						Usage:       "Lists the snapshot policies of all tenants",
+						Description: "serviced snapshot policy list",
+						Action:      c.cmdSnapshotPolicyList,
+						Flags: []cli.Flag{
+							cli.BoolFlag{
+								Name:  "verbose, v",
+								Usage: "Show JSON format",
+							},
+						},
+					}, {
+						Name:         "set",
+						Usage:        "Sets the snapshot policy of the tenant of a service",
+						Description:  "serviced snapshot policy set SERVICEID --schedule SCHEDULE",
+						BashComplete: c.printServicesFirst,
+						Action:       c.cmdSnapshotPolicySet,
+						Flags: []cli.Flag{
+							cli.StringFlag{
+								Name:  "schedule, s",
+								Value: "@daily",
+								Usage: "cron expression (minute hour day-of-month month day-of-week) or @hourly, @daily, @weekly",
+							},
+							cli.BoolFlag{
+								Name:  "quiesce, q",
+								Usage: "pause the services of the tenant while the snapshot is taken",
+							},
+							cli.IntFlag{
+								Name:  "keep-hourly",
+								Value: 0,
+								Usage: "number of hours to keep the newest snapshot of",
+							},
+							cli.IntFlag{
+								Name:  "keep-daily",
+								Value: 7,
+								Usage: "number of days to keep the newest snapshot of",
+							},
+							cli.IntFlag{
+								Name:  "keep-weekly",
+								Value: 0,
+								Usage: "number of weeks to keep the newest snapshot of",
+							},
+							cli.IntFlag{
+								Name:  "max-space-percent",
+								Value: 0,
+								Usage: "percent of the tenant volume size needed to take a snapshot (0 uses the daemon setting)",
+							},
+						},
+					}, {
+						Name:         "remove",
+						ShortName:    "rm",
+						Usage:        "Removes the snapshot policy of a tenant",
+						Description:  "serviced snapshot policy remove TENANTID",
+						BashComplete: c.printServicesFirst,
+						Action:       c.cmdSnapshotPolicyRemove,
+					},
+				},
 			},
 		},
 	})
@@ -349,6 +432,72 @@ func (c *ServicedCli) cmdSnapshotRollback(ctx *cli.Context) {
 	}
 }
 
+// serviced snapshot diff SNAPSHOTID [SNAPSHOTID] [--verbose, -v]
+func (c *ServicedCli) cmdSnapshotDiff(ctx *cli.Context) {
+	args := ctx.Args()
+	if len(args) < 1 || len(args) > 2 {
+		fmt.Printf("Incorrect Usage.\n\n")
+		cli.ShowCommandHelp(ctx, "diff")
+		return
+	}
+
+	otherID := ""
+	if len(args) == 2 {
+		otherID = args[1]
+	}
+	changes, err := c.driver.DiffSnapshot(args[0], otherID)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		c.exit(1)
+		return
+	} else if len(changes) == 0 {
+		fmt.Fprintln(os.Stderr, "no changes found")
+		return
+	}
+	if ctx.Bool("verbose") {
+		if jsonChanges, err := json.MarshalIndent(changes, " ", "  "); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to marshal snapshot diff: %s", err)
+		} else {
+			fmt.Println(string(jsonChanges))
+		}
+		return
+	}
+	t := NewTable("Change,Size,Path")
+	t.Padding = 2
+	for _, change := range changes {
+		size, path := fmt.Sprintf("%d", change.Size), change.Path
+		if change.IsDir {
+			size, path = "-", path+"/"
+		}
+		t.AddRow(map[string]interface{}{
+			"Change": change.Change,
+			"Size":   size,
+			"Path":   path,
+		})
+	}
+	t.Print()
+}
+
+// serviced snapshot restore-path SNAPSHOTID PATH ...
+func (c *ServicedCli) cmdSnapshotRestorePath(ctx *cli.Context) {
+	args := ctx.Args()
+	if len(args) < 2 {
+		fmt.Printf("Incorrect Usage.\n\n")
+		cli.ShowCommandHelp(ctx, "restore-path")
+		return
+	}
+
+	paths := args[1:]
+	if err := c.driver.RestoreSnapshotPaths(args[0], paths); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		c.exit(1)
+		return
+	}
+	for _, path := range paths {
+		fmt.Println(path)
+	}
+}
+
 // serviced snapshot tag SNAPSHOTID TAG-NAME
 func (c *ServicedCli) cmdSnapshotTag(ctx *cli.Context) {
 	args := ctx.Args()
@@ -391,3 +540,89 @@ func (c *ServicedCli) cmdSnapshotRemoveTag(ctx *cli.Context) {
 	}
 	fmt.Printf("%s\n", snapshotID)
 }
+
+// serviced snapshot policy list [--verbose, -v]
+func (c *ServicedCli) cmdSnapshotPolicyList(ctx *cli.Context) {
+	policies, err := c.driver.GetSnapshotPolicies()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	} else if len(policies) == 0 {
+		fmt.Fprintln(os.Stderr, "no snapshot policies found")
+		return
+	}
+	if ctx.Bool("verbose") {
+		if jsonPolicies, err := json.MarshalIndent(policies, " ", "  "); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to marshal snapshot policy list: %s", err)
+		} else {
+			fmt.Println(string(jsonPolicies))
+		}
+		return
+	}
+	t := NewTable("TenantID,Schedule,Quiesce,Hourly,Daily,Weekly,MaxSpace,Next")
+	t.Padding = 2
+	for _, p := range policies {
+		next := ""
+		if n, err := p.Next(); err == nil && !n.IsZero() {
+			next = n.Local().Format(time.RFC3339)
+		}
+		t.AddRow(map[string]interface{}{
+			"TenantID": p.TenantID,
+			"Schedule": p.Schedule,
+			"Quiesce":  p.Quiesce,
+			"Hourly":   p.KeepHourly,
+			"Daily":    p.KeepDaily,
+			"Weekly":   p.KeepWeekly,
+			"MaxSpace": p.MaxSpacePercent,
+			"Next":     next,
+		})
+	}
+	t.Print()
+}
+
+// serviced snapshot policy set SERVICEID [--schedule SCHEDULE] [--quiesce]
+// [--keep-hourly N] [--keep-daily N] [--keep-weekly N] [--max-space-percent N]
+func (c *ServicedCli) cmdSnapshotPolicySet(ctx *cli.Context) {
+	args := ctx.Args()
+	if len(args) != 1 {
+		fmt.Printf("Incorrect Usage.\n\n")
+		cli.ShowCommandHelp(ctx, "set")
+		return
+	}
+	policy := snapshotpolicy.SnapshotPolicy{
+		TenantID:        args[0],
+		Schedule:        ctx.String("schedule"),
+		Quiesce:         ctx.Bool("quiesce"),
+		KeepHourly:      ctx.Int("keep-hourly"),
+		KeepDaily:       ctx.Int("keep-daily"),
+		KeepWeekly:      ctx.Int("keep-weekly"),
+		MaxSpacePercent: ctx.Int("max-space-percent"),
+	}
+	if _, err := snapshotpolicy.ParseSchedule(policy.Schedule); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		c.exit(1)
+		return
+	}
+	if err := c.driver.SetSnapshotPolicy(policy); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		c.exit(1)
+		return
+	}
+	fmt.Println(args[0])
+}
+
+// serviced snapshot policy remove TENANTID
+func (c *ServicedCli) cmdSnapshotPolicyRemove(ctx *cli.Context) {
+	args := ctx.Args()
+	if len(args) != 1 {
+		fmt.Printf("Incorrect Usage.\n\n")
+		cli.ShowCommandHelp(ctx, "remove")
+		return
+	}
+	if err := c.driver.RemoveSnapshotPolicy(args[0]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		c.exit(1)
+		return
+	}
+	fmt.Println(args[0])
+}
@@ -20,10 +20,13 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/control-center/serviced/cli/api"
 	"github.com/control-center/serviced/dao"
+	"github.com/control-center/serviced/domain/snapshotpolicy"
 	"github.com/control-center/serviced/utils"
+	"github.com/control-center/serviced/volume"
 	"github.com/control-center/serviced/volume/btrfs"
 )
 
@@ -53,6 +56,8 @@ type SnapshotAPITest struct {
 	btrfsFail    bool
 	getByTagFail bool
 	snapshots    []dao.SnapshotInfo
+	policies     *[]snapshotpolicy.SnapshotPolicy
+	restored     *[]string
 }
 
 func InitSnapshotAPITest(args ...string) {
@@ -180,6 +185,55 @@ func (t SnapshotAPITest) RemoveSnapshotTag(serviceID string, tagName string) (st
 	return "", ErrNoSnapshotFound
 }
 
+func (t SnapshotAPITest) GetSnapshotPolicies() ([]snapshotpolicy.SnapshotPolicy, error) {
+	if t.fail {
+		return nil, ErrInvalidSnapshot
+	} else if t.policies == nil {
+		return nil, nil
+	}
+	return *t.policies, nil
+}
+
+func (t SnapshotAPITest) SetSnapshotPolicy(policy snapshotpolicy.SnapshotPolicy) error {
+	if t.fail {
+		return ErrInvalidSnapshot
+	}
+	*t.policies = append(*t.policies, policy)
+	return nil
+}
+
+func (t SnapshotAPITest) DiffSnapshot(snapshotID, otherID string) ([]volume.FileChange, error) {
+	for _, id := range []string{snapshotID, otherID} {
+		if id == "" {
+			continue
+		} else if ok, err := t.hasSnapshot(id); err != nil {
+			return nil, err
+		} else if !ok {
+			return nil, ErrNoSnapshotFound
+		}
+	}
+	if otherID != "" {
+		return []volume.FileChange{}, nil
+	}
+	return []volume.FileChange{
+		{Path: "etc/app.conf", Change: volume.FileModified, Size: 120},
+		{Path: "var/cache", Change: volume.FileAdded, IsDir: true},
+		{Path: "var/data/index.db", Change: volume.FileDeleted, Size: 4096},
+	}, nil
+}
+
+func (t SnapshotAPITest) RestoreSnapshotPaths(snapshotID string, paths []string) error {
+	if ok, err := t.hasSnapshot(snapshotID); err != nil {
+		return err
+	} else if !ok {
+		return ErrNoSnapshotFound
+	}
+	if t.restored != nil {
+		*t.restored = append(*t.restored, paths...)
+	}
+	return nil
+}
+
 func ExampleServicedCLI_CmdSnapshotList() {
 	InitSnapshotAPITest("serviced", "snapshot", "list")
 
@@ -619,3 +673,149 @@ func ExampleServicedCLI_CmdSnapshotUntag_btrfsFail() {
 	// Output:
 	// operation not supported on btrfs driver
 }
+
+func TestServicedCLI_CmdSnapshotPolicySet(t *testing.T) {
+	policies := []snapshotpolicy.SnapshotPolicy{}
+	test := SnapshotAPITest{policies: &policies}
+	output := captureStdout(func() {
+		New(test, utils.TestConfigReader(make(map[string]string)), MockLogControl{}).Run([]string{
+			"serviced", "snapshot", "policy", "set", "test-service-1",
+			"--schedule", "0 */4 * * *", "--quiesce", "--keep-hourly", "6",
+		})
+	})
+	if strings.TrimSpace(string(output)) != "test-service-1" {
+		t.Fatalf("got output %q", output)
+	}
+	expected := []snapshotpolicy.SnapshotPolicy{{
+		TenantID:   "test-service-1",
+		Schedule:   "0 */4 * * *",
+		Quiesce:    true,
+		KeepHourly: 6,
+		KeepDaily:  7,
+	}}
+	if len(policies) != 1 || policies[0] != expected[0] {
+		t.Fatalf("\ngot:\n%+v\nwant:\n%+v", policies, expected)
+	}
+}
+
+func TestServicedCLI_CmdSnapshotPolicySet_InvalidSchedule(t *testing.T) {
+	policies := []snapshotpolicy.SnapshotPolicy{}
+	test := SnapshotAPITest{policies: &policies}
+	c := New(test, utils.TestConfigReader(make(map[string]string)), MockLogControl{})
+	c.exitDisabled = true
+	output := captureStderr(func() {
+		c.Run([]string{"serviced", "snapshot", "policy", "set", "test-service-1", "--schedule", "sometimes"})
+	})
+	if !strings.Contains(string(output), "invalid schedule") {
+		t.Fatalf("got error output %q", output)
+	}
+	if len(policies) != 0 {
+		t.Fatalf("set a policy with an invalid schedule: %+v", policies)
+	}
+}
+
+func TestServicedCLI_CmdSnapshotPolicyList(t *testing.T) {
+	lastRun := time.Date(2018, time.March, 14, 0, 0, 0, 0, time.UTC)
+	policies := []snapshotpolicy.SnapshotPolicy{
+		{TenantID: "test-service-1", Schedule: "@daily", KeepDaily: 7, LastRun: lastRun},
+		{TenantID: "test-service-2", Schedule: "0 */4 * * *", Quiesce: true, KeepHourly: 6, KeepWeekly: 4, MaxSpacePercent: 20, LastRun: lastRun},
+	}
+	test := SnapshotAPITest{policies: &policies}
+	output := captureStdout(func() {
+		New(test, utils.TestConfigReader(make(map[string]string)), MockLogControl{}).Run([]string{"serviced", "snapshot", "policy", "list"})
+	})
+	next := func(hours int) string {
+		return lastRun.Add(time.Duration(hours) * time.Hour).Local().Format(time.RFC3339)
+	}
+	expected :=
+		"TenantID        Schedule     Quiesce  Hourly  Daily  Weekly  MaxSpace  Next" +
+			"\ntest-service-1  @daily       false    0       7      0       0         " + next(24) +
+			"\ntest-service-2  0 */4 * * *  true     6       0      4       20        " + next(4)
+
+	outStr := TrimLines(fmt.Sprintf("%s", output))
+	expected = TrimLines(expected)
+
+	if expected != outStr {
+		t.Fatalf("\ngot:\n%s\nwant:\n%s", outStr, expected)
+	}
+}
+
+func TestServicedCLI_CmdSnapshotDiff(t *testing.T) {
+	output := captureStdout(func() {
+		InitSnapshotAPITest("serviced", "snapshot", "diff", "test-service-1-snapshot-1")
+	})
+	expected :=
+		"Change    Size  Path" +
+			"\nmodified  120   etc/app.conf" +
+			"\nadded     -     var/cache/" +
+			"\ndeleted   4096  var/data/index.db"
+
+	outStr := TrimLines(fmt.Sprintf("%s", output))
+	expected = TrimLines(expected)
+
+	if expected != outStr {
+		t.Fatalf("\ngot:\n%s\nwant:\n%s", outStr, expected)
+	}
+}
+
+func ExampleServicedCLI_CmdSnapshotDiff_noChanges() {
+	pipeStderr(func() {
+		InitSnapshotAPITest("serviced", "snapshot", "diff", "test-service-1-snapshot-1", "test-service-1-snapshot-2")
+	})
+
+	// Output:
+	// no changes found
+}
+
+func ExampleServicedCLI_CmdSnapshotDiff_usage() {
+	InitSnapshotAPITest("serviced", "snapshot", "diff")
+
+	// Output:
+	// Incorrect Usage.
+	//
+	// NAME:
+	//    diff - Lists the files that differ between a snapshot and the live volume or another snapshot
+	//
+	// USAGE:
+	//    command diff [command options] [arguments...]
+	//
+	// DESCRIPTION:
+	//    serviced snapshot diff SNAPSHOTID [SNAPSHOTID]
+	//
+	// OPTIONS:
+	//    --verbose, -v	Show JSON format
+}
+
+func TestServicedCLI_CmdSnapshotRestorePath(t *testing.T) {
+	restored := []string{}
+	test := SnapshotAPITest{snapshots: DefaultTestSnapshots, restored: &restored}
+	output := captureStdout(func() {
+		New(test, utils.TestConfigReader(make(map[string]string)), MockLogControl{}).Run([]string{
+			"serviced", "snapshot", "restore-path", "test-service-1-snapshot-1", "etc/app.conf", "var/data",
+		})
+	})
+	if strings.TrimSpace(string(output)) != "etc/app.conf\nvar/data" {
+		t.Fatalf("got output %q", output)
+	}
+	if len(restored) != 2 || restored[0] != "etc/app.conf" || restored[1] != "var/data" {
+		t.Fatalf("restored the wrong paths: %v", restored)
+	}
+}
+
+func ExampleServicedCLI_CmdSnapshotRestorePath_usage() {
+	InitSnapshotAPITest("serviced", "snapshot", "restore-path", "test-service-1-snapshot-1")
+
+	// Output:
+	// Incorrect Usage.
+	//
+	// NAME:
+	//    restore-path - Restores files and directories in place from a snapshot
+	//
+	// USAGE:
+	//    command restore-path [command options] [arguments...]
+	//
+	// DESCRIPTION:
+	//    serviced snapshot restore-path SNAPSHOTID PATH ...
+	//
+	// OPTIONS:
+}
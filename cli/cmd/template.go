@@ -74,6 +74,27 @@ func (c *ServicedCli) initTemplate() {
 						Usage: "Manually assign IP addresses",
 					},
 				},
+			}, {
+				Name:         "upgrade",
+				Usage:        "Merges a new template into a deployed application",
+				Description:  "serviced template upgrade TENANT TEMPLATEID",
+				BashComplete: c.printServicesFirst,
+				Action:       c.cmdTemplateUpgrade,
+				Flags: []cli.Flag{
+					cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "Show the changes and conflicts without applying them",
+					},
+					cli.BoolFlag{
+						Name:  "keep-local",
+						Usage: "Keep locally edited fields that conflict with the new template",
+					},
+					cli.StringFlag{
+						Name:  "base",
+						Value: "",
+						Usage: "ID of the template the application was deployed from, if it was not recorded",
+					},
+				},
 			}, {
 				Name:        "compile",
 				Usage:       "Convert a directory of service definitions into a template",
@@ -265,6 +286,56 @@ func (c *ServicedCli) cmdTemplateDeploy(ctx *cli.Context) {
 	}
 }
 
+// serviced template upgrade [--dry-run] [--keep-local] [--base TEMPLATEID] TENANT TEMPLATEID
+func (c *ServicedCli) cmdTemplateUpgrade(ctx *cli.Context) {
+	args := ctx.Args()
+	if len(args) < 2 {
+		fmt.Printf("Incorrect Usage.\n\n")
+		cli.ShowCommandHelp(ctx, "upgrade")
+		return
+	}
+
+	tenant, _, err := c.searchForService(args[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	req := template.UpgradeRequest{
+		TenantID:       tenant.ID,
+		TemplateID:     args[1],
+		BaseTemplateID: ctx.String("base"),
+		DryRun:         ctx.Bool("dry-run"),
+		KeepLocal:      ctx.Bool("keep-local"),
+	}
+	upgrade, err := c.driver.UpgradeServiceTemplate(req)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	if len(upgrade.Changes) == 0 {
+		fmt.Println("No changes")
+	}
+	for _, change := range upgrade.Changes {
+		fmt.Printf("%s %s\n", change.Action, change.Path)
+		for _, field := range change.Fields {
+			fmt.Printf("    %s: %s -> %s\n", field.Field, field.Old, field.New)
+		}
+	}
+	for _, conflict := range upgrade.Conflicts {
+		fmt.Printf("conflict %s\n", conflict.Path)
+		for _, field := range conflict.Fields {
+			fmt.Printf("    %s: deployed %s, template %s, local %s\n", field.Field, field.Base, field.Template, field.Live)
+		}
+	}
+	if req.DryRun {
+		fmt.Println("Dry run: no changes were applied")
+	} else if len(upgrade.Conflicts) > 0 {
+		fmt.Println("Conflicting fields kept their local values")
+	}
+}
+
 type metaTemplate struct {
 	template.ServiceTemplate
 	ServicedVersion servicedversion.ServicedVersion
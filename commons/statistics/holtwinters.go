@@ -0,0 +1,79 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statistics
+
+import (
+	"errors"
+	"math"
+)
+
+var (
+	ErrInvalidSeason = errors.New("season length must be positive")
+)
+
+// HoltWinters uses additive triple exponential smoothing to forecast each
+// value of the series from the values before it.  The first two seasons of
+// the series are used to initialize the model, so the series must contain
+// at least 2*season values.  Alpha, beta and gamma are the smoothing factors
+// for the level, trend and seasonal components, from 0 to 1.
+func HoltWinters(series []float64, season int, alpha, beta, gamma float64) ([]float64, error) {
+	if season < 1 {
+		return nil, ErrInvalidSeason
+	}
+	if len(series) < 2*season {
+		return nil, ErrInsufficientData
+	}
+
+	// Initialize the level from the mean of the first season, the trend
+	// from the average change between the first two seasons, and the
+	// seasonal components from each value's offset from the first level.
+	level := Mean(series[:season])
+	var trend float64
+	for i := 0; i < season; i++ {
+		trend += (series[season+i] - series[i]) / float64(season)
+	}
+	trend /= float64(season)
+	seasonals := make([]float64, season)
+	for i := 0; i < season; i++ {
+		seasonals[i] = series[i] - level
+	}
+
+	forecast := make([]float64, len(series))
+	for i, value := range series {
+		s := seasonals[i%season]
+		forecast[i] = level + trend + s
+
+		lastLevel := level
+		level = alpha*(value-s) + (1-alpha)*(level+trend)
+		trend = beta*(level-lastLevel) + (1-beta)*trend
+		seasonals[i%season] = gamma*(value-level) + (1-gamma)*s
+	}
+	return forecast, nil
+}
+
+// MeanAbsoluteDeviation returns the mean of the absolute differences
+// between the values and their forecasts.
+func MeanAbsoluteDeviation(values, forecast []float64) (float64, error) {
+	if len(values) != len(forecast) {
+		return 0, ErrUnequalArrays
+	}
+	if len(values) == 0 {
+		return 0, ErrInsufficientData
+	}
+	var sum float64
+	for i := range values {
+		sum += math.Abs(values[i] - forecast[i])
+	}
+	return sum / float64(len(values)), nil
+}
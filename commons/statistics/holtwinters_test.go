@@ -0,0 +1,42 @@
+package statistics_test
+
+import (
+	"math"
+
+	. "github.com/control-center/serviced/commons/statistics"
+	. "gopkg.in/check.v1"
+)
+
+func (s *StatisticsSuite) TestHoltWinters(c *C) {
+	// Insufficient data
+	_, err := HoltWinters([]float64{1, 2, 3}, 2, 0.5, 0.5, 0.5)
+	c.Assert(err, Equals, ErrInsufficientData)
+	_, err = HoltWinters([]float64{1, 2, 3}, 0, 0.5, 0.5, 0.5)
+	c.Assert(err, Equals, ErrInvalidSeason)
+
+	// A repeating pattern is forecast exactly once the model is initialized
+	series := []float64{1, 5, 3, 1, 5, 3, 1, 5, 3, 1, 5, 3}
+	forecast, err := HoltWinters(series, 3, 0.5, 0.5, 0.5)
+	c.Assert(err, IsNil)
+	c.Assert(forecast, HasLen, len(series))
+	for i := range series {
+		c.Check(forecast[i], RoughlyEquals, series[i])
+	}
+
+	// A trend is followed
+	series = []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+	forecast, err = HoltWinters(series, 2, 0.5, 0.5, 0.5)
+	c.Assert(err, IsNil)
+	c.Check(math.Abs(forecast[11]-12) < 0.1, Equals, true)
+}
+
+func (s *StatisticsSuite) TestMeanAbsoluteDeviation(c *C) {
+	mad, err := MeanAbsoluteDeviation([]float64{1, 2, 3}, []float64{2, 2, 1})
+	c.Assert(err, IsNil)
+	c.Assert(mad, RoughlyEquals, float64(1))
+
+	_, err = MeanAbsoluteDeviation([]float64{1}, []float64{})
+	c.Assert(err, Equals, ErrUnequalArrays)
+	_, err = MeanAbsoluteDeviation([]float64{}, []float64{})
+	c.Assert(err, Equals, ErrInsufficientData)
+}
@@ -29,6 +29,16 @@ import (
 
 const (
 	minTimeout = 30
+
+	// DatastoreElastic keeps the control plane entities in Elasticsearch
+	DatastoreElastic = "elastic"
+	// DatastoreEmbedded keeps the control plane entities in a database file
+	DatastoreEmbedded = "embedded"
+
+	// CoordinatorZookeeper coordinates the cluster through the zookeeper isvc
+	CoordinatorZookeeper = "zookeeper"
+	// CoordinatorEtcd coordinates the cluster through an etcd v3 cluster
+	CoordinatorEtcd = "etcd"
 )
 
 var (
@@ -119,6 +129,8 @@ type Options struct {
 	ZKPerHostConnectDelay      int               // The delay, in seconds, between connection attempts to other zookeeper servers.
 	ZKReconnectStartDelay      int               // The initial delay, in seconds, before attempting to reconnect after none of the zookeepers are reachable
 	ZKReconnectMaxDelay        int               // The maximum delay, in seconds, before attempting to reconnect after none of the zookeepers are reachable
+	CoordinatorDriver          string            // Backend of the cluster coordination, either zookeeper or etcd
+	EtcdEndpoints              []string          // Client URLs of the etcd cluster when CoordinatorDriver is etcd
 	TokenExpiration            int               // The time in seconds before an authentication token expires
 	ConntrackFlush             string            // Whether to flush the conntrack table when a service with an assigned IP is started
 	LogConfigFilename          string            // Path to the logri configuration
@@ -127,8 +139,22 @@ type Options struct {
 	StorageMetricMonitorWindow int               // The amount of time in seconds for which serviced will consider storage availability metrics in order to predict future availability
 	StorageLookaheadPeriod     int               // The amount of time in the future in seconds serviced should predict storage availability for the purposes of emergency shutdown
 	StorageMinimumFreeSpace    string            // The amount of space the emergency shutdown algorithm should reserve when deciding to shut down
+	ThresholdInterval          int               // frequency in seconds to evaluate the thresholds of services; 0 disables threshold evaluation
+	DatastoreDriver            string            // Backend of the control plane entities, either elastic or embedded
+	DatastorePath              string            // Database file of the embedded datastore; defaults to controlplane.db in IsvcsPath
 	BackupEstimatedCompression float64           // Best guess for tgz compression ratio (uncompressed size / compressed size) used to determine whether sufficient disk space is available for taking a backup
 	BackupMinOverhead          string            // Warn user if estimated backup size would leave less than this amount of space free
+	BackupS3Endpoint           string            // Endpoint of the S3-compatible object store used when BackupsPath is an s3:// location
+	BackupS3Region             string            // Region of the S3-compatible object store
+	BackupS3AccessKeyID        string            // Access key for the S3-compatible object store
+	BackupS3SecretAccessKey    string            // Secret key for the S3-compatible object store
+	BackupS3PartSize           int               // Size in MiB of each part uploaded to the S3-compatible object store; limits backups to 10,000 parts
+	BackupCompression          string            // Compression used for backups, either gzip or zstd
+	BackupIncremental          bool              // Keep the snapshot of each backup so that the next backup can be incremental
+	BackupEncryptionKeyFile    string            // File containing the 32-byte key used to encrypt and decrypt backups
+	BackupRecipients           []string          // PEM files of the P-256 public keys that backups are encrypted to
+	BackupIdentities           []string          // PEM files of the P-256 private keys used to decrypt backups
+	SecretKeyFile              string            // File containing the 32-byte key used to encrypt and decrypt secrets
 	StartZK                    bool              // Should ZooKeeper ISVC be started
 	StartAPIKeyProxy           bool              // Should API Key Proxy ISVC be started
 	BigTableMetrics            bool              // Should serviced metrics be stored in gcp bigtable
@@ -137,6 +163,16 @@ type Options struct {
 	Auth0Group                 []string          // Group membership(s) required in Auth0 token for login, comma separated list
 	Auth0ClientID              string            // ClientID of Auth0 Application
 	Auth0Scope                 string            // Auth0 Scope for request.
+	OIDCIssuer                 string            // Issuer URL of the OpenID Connect provider used to log in to the UI
+	OIDCClientID               string            // Client ID registered with the OIDC provider
+	OIDCClientSecret           string            // Client secret registered with the OIDC provider, if the client is confidential
+	OIDCAudience               string            // Additional audience accepted for OIDC access tokens
+	OIDCScope                  string            // Scopes requested from the OIDC provider
+	OIDCUsernameClaim          string            // Claim holding the user name
+	OIDCAdminClaim             string            // Claim holding the user's groups or roles; nested claims are separated by '.'
+	OIDCAdminValues            []string          // Values of the admin claim that grant access, comma separated list
+	OIDCRedirectURL            string            // External URL of /oidc/callback, if it differs from the URL the UI is accessed with
+	AuthRoles                  []string          // Roles given to members of user groups, as GROUP=ROLE[:pool=POOLID|:tenant=TENANTID]
 	KeyProxyJsonServer         string            // Address of api-key-server endpoint for getting CC Access tokens
 	KeyProxyListenPort         string            // Port where api-key-proxy will listen
 
@@ -0,0 +1,212 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/control-center/serviced/health"
+)
+
+const (
+	// proxyRetries is the number of other backends a connection is tried on
+	// when the first backend cannot be reached
+	proxyRetries = 2
+
+	// minEjection is how long a backend is ejected after its first failure.
+	// Each consecutive failure doubles the time, up to maxEjection.
+	minEjection = 5 * time.Second
+	maxEjection = 2 * time.Minute
+)
+
+// backend is a remote instance of an endpoint and the state used to balance
+// connections across it.
+type backend struct {
+	address      addressTuple
+	active       int       // number of open connections
+	failures     int       // number of consecutive failed connections
+	ejectedUntil time.Time // the backend is skipped until this time
+}
+
+// backendPool picks backends for new connections.  Healthy backends with the
+// fewest open connections are preferred, and backends that fail are ejected
+// for a while.
+type backendPool struct {
+	mu       *sync.Mutex
+	backends []*backend
+	now      func() time.Time
+}
+
+func newBackendPool() *backendPool {
+	return &backendPool{
+		mu:  &sync.Mutex{},
+		now: time.Now,
+	}
+}
+
+// SetAddresses replaces the backends in the pool, keeping the connection
+// count and ejection state of addresses that are still present.
+func (bp *backendPool) SetAddresses(addresses []addressTuple) {
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+
+	current := make(map[string]*backend)
+	for _, b := range bp.backends {
+		current[b.address.key()] = b
+	}
+
+	backends := make([]*backend, len(addresses))
+	for i, address := range addresses {
+		if b, ok := current[address.key()]; ok {
+			b.address = address
+			backends[i] = b
+		} else {
+			backends[i] = &backend{address: address}
+		}
+	}
+	bp.backends = backends
+}
+
+// Addresses returns the addresses of the backends in the pool
+func (bp *backendPool) Addresses() []addressTuple {
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+	addresses := make([]addressTuple, len(bp.backends))
+	for i, b := range bp.backends {
+		addresses[i] = b.address
+	}
+	return addresses
+}
+
+// Len returns the number of backends in the pool
+func (bp *backendPool) Len() int {
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+	return len(bp.backends)
+}
+
+// Next picks a backend that has not been tried for a new connection and
+// counts the connection against it.  Backends that are ejected or have no
+// passing health checks are only used when no other backend is left.
+// Returns nil if every backend has been tried.
+func (bp *backendPool) Next(tried map[*backend]bool) *backend {
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+
+	now := bp.now()
+	var healthy, available, all []*backend
+	for _, b := range bp.backends {
+		if tried[b] {
+			continue
+		}
+		all = append(all, b)
+		if now.Before(b.ejectedUntil) {
+			continue
+		}
+		available = append(available, b)
+		if healthWeight(b.address.healthStatus) > 0 {
+			healthy = append(healthy, b)
+		}
+	}
+
+	var b *backend
+	switch {
+	case len(healthy) > 0:
+		b = leastLoaded(healthy)
+	case len(available) > 0:
+		b = leastLoaded(available)
+	case len(all) > 0:
+		b = leastLoaded(all)
+	default:
+		return nil
+	}
+	b.active++
+	return b
+}
+
+// Cancel releases a connection from a backend that was never attempted
+func (bp *backendPool) Cancel(b *backend) {
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+	b.active--
+}
+
+// Done releases a connection from a backend.  A failed connection ejects the
+// backend, and a successful one brings it back.
+func (bp *backendPool) Done(b *backend, ok bool) {
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+
+	b.active--
+	if ok {
+		b.failures = 0
+		b.ejectedUntil = time.Time{}
+		return
+	}
+
+	ejection := maxEjection
+	if b.failures < 5 {
+		ejection = minEjection << uint(b.failures)
+		if ejection > maxEjection {
+			ejection = maxEjection
+		}
+	}
+	b.failures++
+	b.ejectedUntil = bp.now().Add(ejection)
+}
+
+// leastLoaded returns the backend with the fewest open connections for its
+// health, breaking ties at random so that short-lived connections are still
+// spread out.
+func leastLoaded(backends []*backend) *backend {
+	var best []*backend
+	var bestScore float64
+	for _, b := range backends {
+		weight := healthWeight(b.address.healthStatus)
+		if weight == 0 {
+			// there is nothing better to choose from, so fall back to
+			// least connections
+			weight = 1
+		}
+		score := float64(b.active+1) / weight
+		switch {
+		case len(best) == 0 || score < bestScore:
+			best, bestScore = []*backend{b}, score
+		case score == bestScore:
+			best = append(best, b)
+		}
+	}
+	return best[rand.Intn(len(best))]
+}
+
+// healthWeight returns the share of traffic, between 0 and 1, that a backend
+// should get based on its health checks.  Backends without health checks are
+// assumed to be healthy.
+func healthWeight(status map[string]health.Status) float64 {
+	if len(status) == 0 {
+		return 1
+	}
+	var total float64
+	for _, s := range status {
+		switch s {
+		case health.OK:
+			total += 1
+		case health.Unknown:
+			total += 0.5
+		}
+	}
+	return total / float64(len(status))
+}
@@ -0,0 +1,259 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build unit
+
+package container
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/control-center/serviced/auth"
+	"github.com/control-center/serviced/health"
+	svcproxy "github.com/control-center/serviced/proxy"
+)
+
+func testBackendPool(addrs ...string) (*backendPool, *time.Time) {
+	now := time.Unix(1000, 0)
+	bp := newBackendPool()
+	bp.now = func() time.Time { return now }
+	addresses := make([]addressTuple, len(addrs))
+	for i, addr := range addrs {
+		addresses[i] = addressTuple{host: "10.0.0.1", containerAddr: addr}
+	}
+	bp.SetAddresses(addresses)
+	return bp, &now
+}
+
+func TestBackendPoolLeastConnections(t *testing.T) {
+	bp, _ := testBackendPool("a:1", "b:1", "c:1")
+
+	// every backend gets a connection before any gets a second
+	seen := make(map[string]int)
+	for i := 0; i < 3; i++ {
+		seen[bp.Next(nil).address.containerAddr]++
+	}
+	if len(seen) != 3 {
+		t.Fatalf("expected connections on every backend, got %v", seen)
+	}
+
+	// release a connection and it goes to the same backend
+	b := bp.backends[1]
+	bp.Done(b, true)
+	if next := bp.Next(nil); next != b {
+		t.Errorf("expected %s, got %s", b.address.containerAddr, next.address.containerAddr)
+	}
+}
+
+func TestBackendPoolHealthWeighted(t *testing.T) {
+	bp, _ := testBackendPool()
+	bp.SetAddresses([]addressTuple{
+		{host: "10.0.0.1", containerAddr: "a:1", healthStatus: map[string]health.Status{"running": health.OK, "ready": health.OK}},
+		{host: "10.0.0.1", containerAddr: "b:1", healthStatus: map[string]health.Status{"running": health.OK, "ready": health.Failed}},
+		{host: "10.0.0.1", containerAddr: "c:1", healthStatus: map[string]health.Status{"running": health.Failed}},
+	})
+
+	// a gets twice the connections of b, and c gets none
+	seen := make(map[string]int)
+	for i := 0; i < 30; i++ {
+		seen[bp.Next(nil).address.containerAddr]++
+	}
+	if seen["a:1"] != 20 || seen["b:1"] != 10 || seen["c:1"] != 0 {
+		t.Errorf("unexpected distribution of connections: %v", seen)
+	}
+
+	// c is still used once the others have been tried
+	tried := map[*backend]bool{bp.backends[0]: true, bp.backends[1]: true}
+	if b := bp.Next(tried); b == nil || b.address.containerAddr != "c:1" {
+		t.Errorf("expected the failing backend as a last resort, got %v", b)
+	}
+}
+
+func TestBackendPoolEjection(t *testing.T) {
+	bp, now := testBackendPool("a:1", "b:1")
+	a, b := bp.backends[0], bp.backends[1]
+
+	// a failure ejects the backend
+	bp.Next(nil)
+	bp.Next(nil)
+	bp.Done(a, false)
+	bp.Done(b, true)
+	for i := 0; i < 3; i++ {
+		if next := bp.Next(nil); next != b {
+			t.Fatalf("expected the ejected backend to be skipped")
+		}
+	}
+
+	// the backend returns once its ejection expires
+	*now = now.Add(minEjection)
+	if next := bp.Next(nil); next != a {
+		t.Fatalf("expected the backend to return after %s", minEjection)
+	}
+
+	// consecutive failures eject the backend for longer, up to the limit
+	bp.Done(a, false)
+	if d := a.ejectedUntil.Sub(*now); d != 2*minEjection {
+		t.Errorf("expected ejection of %s, got %s", 2*minEjection, d)
+	}
+	for i := 0; i < 10; i++ {
+		bp.Next(map[*backend]bool{b: true})
+		bp.Done(a, false)
+	}
+	if d := a.ejectedUntil.Sub(*now); d != maxEjection {
+		t.Errorf("expected ejection of %s, got %s", maxEjection, d)
+	}
+
+	// a success brings it back
+	bp.Next(map[*backend]bool{b: true})
+	bp.Done(a, true)
+	if a.failures != 0 || !a.ejectedUntil.IsZero() {
+		t.Errorf("expected the backend to be restored")
+	}
+}
+
+func TestBackendPoolAllEjected(t *testing.T) {
+	bp, _ := testBackendPool("a:1")
+	a := bp.backends[0]
+	bp.Next(nil)
+	bp.Done(a, false)
+
+	// an ejected backend is better than none
+	if next := bp.Next(nil); next != a {
+		t.Errorf("expected the ejected backend when no other is available")
+	}
+	if next := bp.Next(map[*backend]bool{a: true}); next != nil {
+		t.Errorf("expected no backend once all have been tried")
+	}
+}
+
+func TestBackendPoolSetAddresses(t *testing.T) {
+	bp, _ := testBackendPool("a:1", "b:1")
+	a := bp.backends[0]
+	bp.Next(map[*backend]bool{bp.backends[1]: true})
+	bp.Done(a, false)
+
+	// the state of a backend survives an update
+	status := map[string]health.Status{"running": health.OK}
+	bp.SetAddresses([]addressTuple{
+		{host: "10.0.0.1", containerAddr: "a:1", healthStatus: status},
+		{host: "10.0.0.1", containerAddr: "c:1"},
+	})
+	if bp.Len() != 2 {
+		t.Fatalf("expected 2 backends, got %d", bp.Len())
+	}
+	if bp.backends[0] != a || a.failures != 1 {
+		t.Errorf("expected the state of the backend to be kept")
+	}
+	if a.address.healthStatus["running"] != health.OK {
+		t.Errorf("expected the health of the backend to be updated")
+	}
+}
+
+func TestHealthWeight(t *testing.T) {
+	for _, tc := range []struct {
+		status map[string]health.Status
+		weight float64
+	}{
+		{nil, 1},
+		{map[string]health.Status{"a": health.OK}, 1},
+		{map[string]health.Status{"a": health.OK, "b": health.Unknown}, 0.75},
+		{map[string]health.Status{"a": health.OK, "b": health.Timeout}, 0.5},
+		{map[string]health.Status{"a": health.Failed, "b": health.NotRunning}, 0},
+	} {
+		if w := healthWeight(tc.status); w != tc.weight {
+			t.Errorf("expected weight %v for %v, got %v", tc.weight, tc.status, w)
+		}
+	}
+}
+
+func TestProxyRetriesDeniedMux(t *testing.T) {
+	pub, priv, _ := auth.GenerateRSAKeyPairPEM(nil)
+	auth.LoadMasterKeysFromPEM(pub, priv)
+	dpub, dpriv, _ := auth.GenerateRSAKeyPairPEM(nil)
+	auth.LoadDelegateKeysFromPEM(dpub, dpriv)
+	auth.RefreshToken(func() (string, int64, error) {
+		return auth.CreateJWTIdentity("host", "pool", false, false, dpub, time.Hour)
+	}, "")
+
+	// the mux denies the first connection and echoes the rest
+	listener, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("could not listen: %s", err)
+	}
+	defer listener.Close()
+	go func() {
+		for i := 0; ; i++ {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			if _, _, _, err := auth.ReadMuxHeader(conn); err != nil {
+				conn.Close()
+				continue
+			}
+			if i == 0 {
+				auth.WriteMuxResult(conn, auth.MuxDenied)
+				conn.Close()
+				continue
+			}
+			auth.WriteMuxResult(conn, auth.MuxOK)
+			go func(c net.Conn) {
+				io.Copy(c, c)
+				c.Close()
+			}(conn)
+		}
+	}()
+
+	bp := newBackendPool()
+	bp.SetAddresses([]addressTuple{
+		{host: "127.0.0.1", containerAddr: "10.0.0.1:8080"},
+		{host: "127.0.0.1", containerAddr: "10.0.0.2:8080"},
+	})
+	p := &proxy{
+		name:       "app",
+		tenantID:   "tenant",
+		backends:   bp,
+		tcpMuxPort: uint16(listener.Addr().(*net.TCPAddr).Port),
+		metrics:    svcproxy.NewConnectionMetrics("net.proxy").Endpoint(nil),
+	}
+
+	local, client := net.Pipe()
+	defer client.Close()
+	go p.prxy(local)
+
+	msg := []byte("hello")
+	if _, err := client.Write(msg); err != nil {
+		t.Fatalf("could not write to the proxy: %s", err)
+	}
+	client.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buffer := make([]byte, len(msg))
+	if _, err := io.ReadFull(client, buffer); err != nil {
+		t.Fatalf("expected the connection to be retried on the other backend: %s", err)
+	}
+	if string(buffer) != string(msg) {
+		t.Errorf("got back %q, expected %q", buffer, msg)
+	}
+
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+	ejected := 0
+	for _, b := range bp.backends {
+		ejected += b.failures
+	}
+	if ejected != 1 {
+		t.Errorf("expected the denied backend to be ejected, got %d failures", ejected)
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/control-center/serviced/domain/servicedefinition"
 	"github.com/control-center/serviced/health"
 	"github.com/control-center/serviced/node"
+	svcproxy "github.com/control-center/serviced/proxy"
 	"github.com/control-center/serviced/rpc/master"
 	"github.com/control-center/serviced/utils"
 	"github.com/control-center/serviced/zzk"
@@ -117,6 +118,7 @@ type Controller struct {
 	tenantID           string
 	dockerID           string
 	metricForwarder    *MetricForwarder
+	proxyMetrics       *svcproxy.ConnectionMetrics
 	logforwarder       *subprocess.Instance
 	logforwarderExited chan error
 	closing            chan chan error
@@ -128,6 +130,7 @@ type Controller struct {
 	endpoints          *ContainerEndpoints
 	healthChecks       map[string]health.HealthCheck
 	ccApiProxy         *servicedApiProxy
+	secretEnv          []string
 }
 
 // Close shuts down the controller
@@ -154,10 +157,29 @@ func getService(lbClientPort string, serviceID string, instanceID int) (*service
 		return nil, "", "", err
 	}
 
-	glog.V(2).Infof("getService: serviceID=%s, tenantID=%s, serviceNamePath=%s: %+v", serviceID, evaluatedServiceResponse.TenantID, evaluatedServiceResponse.ServiceNamePath, evaluatedServiceResponse.Service)
+	// the templates of the evaluated service may contain the values of
+	// secrets, so only log what identifies it
+	glog.V(2).Infof("getService: serviceID=%s, tenantID=%s, serviceNamePath=%s", serviceID, evaluatedServiceResponse.TenantID, evaluatedServiceResponse.ServiceNamePath)
 	return &evaluatedServiceResponse.Service, evaluatedServiceResponse.TenantID, evaluatedServiceResponse.ServiceNamePath, nil
 }
 
+// getServiceSecrets retrieves the values of the secrets of a service instance
+func getServiceSecrets(lbClientPort string, serviceID string, instanceID int) (map[string]string, error) {
+	client, err := node.NewLBClient(lbClientPort)
+	if err != nil {
+		glog.Errorf("Could not create a client to endpoint: %s, %s", lbClientPort, err)
+		return nil, err
+	}
+	defer client.Close()
+
+	values := make(map[string]string)
+	if err := client.GetServiceSecrets(master.ServiceSecretsRequest{ServiceID: serviceID, InstanceID: instanceID}, &values); err != nil {
+		glog.Errorf("Error getting secrets of service %s error: %s", serviceID, err)
+		return nil, err
+	}
+	return values, nil
+}
+
 // getAgentHostID retrieves the agent's host id
 func getAgentHostID(lbClientPort string) (string, error) {
 	client, err := node.NewLBClient(lbClientPort)
@@ -337,6 +359,18 @@ func NewController(options ControllerOptions) (*Controller, error) {
 		return c, fmt.Errorf("container: invalid ConfigFiles error:%s", err)
 	}
 
+	// deliver secrets
+	secrets := make(map[string]string)
+	if len(service.Secrets) > 0 {
+		if secrets, err = getServiceSecrets(options.ServicedEndpoint, options.Service.ID, instanceID); err != nil {
+			return c, err
+		}
+	}
+	if c.secretEnv, err = setupSecrets(service, secrets); err != nil {
+		glog.Errorf("Could not setup secrets error:%s", err)
+		return c, fmt.Errorf("container: invalid Secrets error:%s", err)
+	}
+
 	// get host id
 	c.hostID, err = getAgentHostID(options.ServicedEndpoint)
 	if err != nil {
@@ -385,8 +419,11 @@ func NewController(options ControllerOptions) (*Controller, error) {
 		metricRedirect += "&controlplane_host_id=" + c.hostID
 		metricRedirect += "&controlplane_instance_id=" + options.Service.InstanceID
 
+		// connection statistics of the endpoint proxies
+		c.proxyMetrics = svcproxy.NewConnectionMetrics("net.proxy")
+
 		//build and serve the container metric forwarder
-		forwarder, err := NewMetricForwarder(options.Metric.Address, metricRedirect)
+		forwarder, err := NewMetricForwarder(options.Metric.Address, metricRedirect, c.proxyMetrics)
 		if err != nil {
 			return c, err
 		}
@@ -395,7 +432,7 @@ func NewController(options ControllerOptions) (*Controller, error) {
 		// setup network stats
 		destination := fmt.Sprintf("http://localhost%s/api/metrics/store", options.Metric.Address)
 		glog.Infof("pushing network stats to: %s", destination)
-		go statReporter(destination, time.Second*15)
+		go statReporter(destination, time.Second*15, c.proxyMetrics)
 	}
 
 	// Keep a copy of the service prerequisites in the Controller object.
@@ -411,7 +448,11 @@ func NewController(options ControllerOptions) (*Controller, error) {
 
 	// endpoints are created at the root level (not pool aware)
 	rootBasePath := ""
-	zClient, err := coordclient.New("zookeeper", c.zkInfo.ZkDSN, rootBasePath, nil)
+	driver := c.zkInfo.Driver
+	if driver == "" {
+		driver = "zookeeper"
+	}
+	zClient, err := coordclient.New(driver, c.zkInfo.ZkDSN, rootBasePath, nil)
 	if err != nil {
 		glog.Errorf("failed create a new coordclient: %v", err)
 		return c, err
@@ -428,6 +469,7 @@ func NewController(options ControllerOptions) (*Controller, error) {
 		TCPMuxPort:           uint16(options.Mux.Port),
 		UseTLS:               !options.Mux.DisableTLS,
 		VirtualAddressSubnet: options.VirtualAddressSubnet,
+		ProxyMetrics:         c.proxyMetrics,
 	}
 	c.endpoints, err = NewContainerEndpoints(service, opts)
 	if err != nil {
@@ -608,8 +650,15 @@ func (c *Controller) Run() (err error) {
 	env = append(env, fmt.Sprintf("CONTROLPLANE_SERVICED_ID=%s", c.options.Service.ID))
 
 	if err := writeEnvFile(env); err != nil {
-		return err
+		if os.Geteuid() == 0 {
+			return err
+		}
+		// a service whose security context runs it as another user may not
+		// be able to write the file; shells in the container will not have
+		// the service's environment
+		glog.Warningf("Could not write the container environment file as user %d: %s", os.Geteuid(), err)
 	}
+	env = append(env, c.secretEnv...)
 
 	args := []string{"-c", "exec " + strings.Join(c.options.Service.Command, " ")}
 
@@ -814,7 +863,7 @@ func (c *Controller) kickOffHealthChecks(healthExit chan struct{}) {
 	}
 	for name, hc := range c.healthChecks {
 		glog.Infof("Kicking off health check %s.", name)
-		glog.Infof("Setting up health check: %s", hc.Script)
+		glog.Infof("Setting up %s health check: %s", hc.GetType(), hc.Target())
 		key := health.HealthStatusKey{
 			ServiceID:       c.options.Service.ID,
 			InstanceID:      instanceID,
@@ -844,6 +893,9 @@ func (c *Controller) doHealthCheck(cancel <-chan struct{}, key health.HealthStat
 		}
 		defer client.Close()
 		client.ReportHealthStatus(req, nil)
+		if c.endpoints != nil {
+			c.endpoints.SetHealthStatus(key.HealthCheckName, stat.Status)
+		}
 		if stat.KillFlag {
 			logger.WithField("kill_count_limit", hc.KillCountLimit).Infof("KillFlag has been set. Shutting down the controller.")
 			c.shutdown()
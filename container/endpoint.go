@@ -24,6 +24,8 @@ import (
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/control-center/serviced/domain/service"
+	"github.com/control-center/serviced/health"
+	svcproxy "github.com/control-center/serviced/proxy"
 	"github.com/control-center/serviced/zzk"
 	"github.com/control-center/serviced/zzk/registry"
 	zkservice "github.com/control-center/serviced/zzk/service"
@@ -38,6 +40,7 @@ type ContainerEndpointsOptions struct {
 	TCPMuxPort           uint16
 	UseTLS               bool
 	VirtualAddressSubnet string
+	ProxyMetrics         *svcproxy.ConnectionMetrics
 }
 
 // ContainerEndpoints manages import and export bindings for the instance.
@@ -47,6 +50,10 @@ type ContainerEndpoints struct {
 	cache *proxyCache
 	ports map[uint16]struct{}
 	vifs  *VIFRegistry
+
+	mu           *sync.Mutex
+	healthStatus map[string]health.Status
+	healthSubs   []chan map[string]health.Status
 }
 
 // NewContainerEndpoints loads the service state and manages port bindings
@@ -57,6 +64,9 @@ func NewContainerEndpoints(svc *service.Service, opts ContainerEndpointsOptions)
 		opts:  opts,
 		ports: make(map[uint16]struct{}),
 		vifs:  NewVIFRegistry(),
+
+		mu:           &sync.Mutex{},
+		healthStatus: make(map[string]health.Status),
 	}
 
 	// load the state object
@@ -66,7 +76,7 @@ func NewContainerEndpoints(svc *service.Service, opts ContainerEndpointsOptions)
 	}
 
 	// set up the proxy cache
-	ce.cache = newProxyCache(opts.TenantID, opts.TCPMuxPort, opts.UseTLS, allowDirect)
+	ce.cache = newProxyCache(opts.TenantID, opts.TCPMuxPort, opts.UseTLS, allowDirect, opts.ProxyMetrics)
 
 	// set up virtual interface registry
 	if err := ce.vifs.SetSubnet(opts.VirtualAddressSubnet); err != nil {
@@ -197,6 +207,54 @@ func (ce *ContainerEndpoints) Run(cancel <-chan struct{}) {
 	go ce.RunImportListener(cancel, ce.opts.TenantID, ce.state.Imports...)
 }
 
+// SetHealthStatus updates the result of one of the instance's health checks,
+// which is published with its exports.
+func (ce *ContainerEndpoints) SetHealthStatus(name string, status health.Status) {
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+
+	if current, ok := ce.healthStatus[name]; ok && current == status {
+		return
+	}
+	ce.healthStatus[name] = status
+
+	// only the latest status matters, so replace any update that has not yet
+	// been published
+	for _, sub := range ce.healthSubs {
+		select {
+		case <-sub:
+		default:
+		}
+		sub <- ce.copyHealthStatus()
+	}
+}
+
+// getHealthStatus returns the current health status of the instance
+func (ce *ContainerEndpoints) getHealthStatus() map[string]health.Status {
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+	return ce.copyHealthStatus()
+}
+
+// subscribeHealthStatus returns a channel that receives the health status of
+// the instance whenever it changes.
+func (ce *ContainerEndpoints) subscribeHealthStatus() <-chan map[string]health.Status {
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+	sub := make(chan map[string]health.Status, 1)
+	ce.healthSubs = append(ce.healthSubs, sub)
+	return sub
+}
+
+// copyHealthStatus is non thread-safe
+func (ce *ContainerEndpoints) copyHealthStatus() map[string]health.Status {
+	status := make(map[string]health.Status)
+	for name, s := range ce.healthStatus {
+		status[name] = s
+	}
+	return status
+}
+
 // AddExport ensures that an export is registered for other services to bind
 func (ce *ContainerEndpoints) AddExport(cancel <-chan struct{}, bind zkservice.ExportBinding) {
 	logger := plog.WithFields(log.Fields{
@@ -212,6 +270,7 @@ func (ce *ContainerEndpoints) AddExport(cancel <-chan struct{}, bind zkservice.E
 		MuxPort:       ce.opts.TCPMuxPort,
 		InstanceID:    ce.state.InstanceID,
 	}
+	healthStatus := ce.subscribeHealthStatus()
 
 	logger.Debug("Registering export")
 	defer logger.Debug("Unregistered export")
@@ -222,7 +281,8 @@ func (ce *ContainerEndpoints) AddExport(cancel <-chan struct{}, bind zkservice.E
 			if conn != nil {
 
 				logger.Debug("Received coordinator connection")
-				registry.RegisterExport(cancel, conn, ce.opts.TenantID, exp)
+				exp.HealthStatus = ce.getHealthStatus()
+				registry.RegisterExport(cancel, conn, ce.opts.TenantID, exp, healthStatus)
 				select {
 				case <-cancel:
 					return
@@ -476,9 +536,10 @@ type proxyCache struct {
 	tcpMuxPort  uint16
 	useTLS      bool
 	allowDirect bool
+	metrics     *svcproxy.ConnectionMetrics
 }
 
-func newProxyCache(tenantID string, tcpMuxPort uint16, useTLS, allowDirect bool) *proxyCache {
+func newProxyCache(tenantID string, tcpMuxPort uint16, useTLS, allowDirect bool, metrics *svcproxy.ConnectionMetrics) *proxyCache {
 	return &proxyCache{
 		mu:          &sync.Mutex{},
 		cache:       make(map[proxyKey]*proxy),
@@ -486,6 +547,7 @@ func newProxyCache(tenantID string, tcpMuxPort uint16, useTLS, allowDirect bool)
 		tcpMuxPort:  tcpMuxPort,
 		useTLS:      useTLS,
 		allowDirect: allowDirect,
+		metrics:     metrics,
 	}
 }
 
@@ -522,11 +584,16 @@ func (c *proxyCache) Set(application string, portNumber uint16, exports ...regis
 		// create the proxy
 		prxy, err = newProxy(
 			fmt.Sprintf("%s-%d", application, portNumber),
+			c.tenantID,
 			fmt.Sprintf("%s-%s-%d", c.tenantID, application, portNumber),
 			c.tcpMuxPort,
 			c.useTLS,
 			listener,
 			c.allowDirect,
+			c.metrics.Endpoint(map[string]string{
+				"application": application,
+				"port":        fmt.Sprintf("%d", portNumber),
+			}),
 		)
 		if err != nil {
 			logger.WithError(err).Debug("Could not start proxy")
@@ -544,6 +611,7 @@ func (c *proxyCache) Set(application string, portNumber uint16, exports ...regis
 		addresses[i] = addressTuple{
 			host:          export.HostIP,
 			containerAddr: fmt.Sprintf("%s:%d", export.PrivateIP, export.PortNumber),
+			healthStatus:  export.HealthStatus,
 		}
 	}
 	prxy.SetNewAddresses(addresses)
@@ -14,6 +14,7 @@
 package container
 
 import (
+	svcproxy "github.com/control-center/serviced/proxy"
 	"github.com/zenoss/glog"
 	rest "github.com/zenoss/go-json-rest"
 
@@ -29,19 +30,22 @@ import (
 type MetricForwarder struct {
 	port               string
 	metricsRedirectURL string
+	proxyMetrics       *svcproxy.ConnectionMetrics
 	listener           *net.Listener
 }
 
 var client = &http.Client{Timeout:time.Duration(5 * time.Second)}
 
-// NewMetricForwarder creates a new metric forwarder at port, all metrics are forwarded to metricsRedirectURL
-func NewMetricForwarder(port, metricsRedirectURL string) (config *MetricForwarder, err error) {
+// NewMetricForwarder creates a new metric forwarder at port, all metrics are forwarded to metricsRedirectURL.
+// The connection statistics of the endpoint proxies are served at /metrics in the Prometheus text format.
+func NewMetricForwarder(port, metricsRedirectURL string, proxyMetrics *svcproxy.ConnectionMetrics) (config *MetricForwarder, err error) {
 	if len(port) < 4 {
 		return nil, fmt.Errorf("invalid port specification: '%s'", port)
 	}
 	config = &MetricForwarder{
 		port:               port,
 		metricsRedirectURL: metricsRedirectURL,
+		proxyMetrics:       proxyMetrics,
 	}
 	listener, err := net.Listen("tcp", port)
 	if err != nil {
@@ -61,6 +65,11 @@ func (forwarder *MetricForwarder) loop() {
 			PathExp:    "/api/metrics/store",
 			Func:       postAPIMetricsStore(forwarder.metricsRedirectURL),
 		},
+		rest.Route{
+			HttpMethod: "GET",
+			PathExp:    "/metrics",
+			Func:       getProxyMetrics(forwarder.proxyMetrics),
+		},
 	}
 
 	handler := rest.ResourceHandler{}
@@ -97,3 +106,15 @@ func postAPIMetricsStore(redirectURL string) func(*rest.ResponseWriter, *rest.Re
 		}
 	}
 }
+
+// getProxyMetrics serves the connection statistics of the endpoint proxies in
+// the Prometheus text format
+func getProxyMetrics(proxyMetrics *svcproxy.ConnectionMetrics) func(*rest.ResponseWriter, *rest.Request) {
+	return func(w *rest.ResponseWriter, request *rest.Request) {
+		if proxyMetrics == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		proxyMetrics.ServeHTTP(w, request.Request)
+	}
+}
@@ -37,7 +37,7 @@ var server sync.Once
 // start a metric forwarder
 func startForwarder() (*MetricForwarder, error) {
 	metricRedirect := fmt.Sprintf("http://%s/api/metrics/store", address)
-	return NewMetricForwarder(":22350", metricRedirect)
+	return NewMetricForwarder(":22350", metricRedirect, nil)
 }
 
 //echo the Request body into the response
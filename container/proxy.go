@@ -15,6 +15,7 @@ package container
 
 import (
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"math/rand"
@@ -23,11 +24,21 @@ import (
 	"strings"
 	"time"
 
+	log "github.com/Sirupsen/logrus"
 	"github.com/control-center/serviced/auth"
+	"github.com/control-center/serviced/health"
+	svcproxy "github.com/control-center/serviced/proxy"
 	"github.com/control-center/serviced/utils"
 	"github.com/zenoss/glog"
 )
 
+// errNoAuthToken is returned when the token needed to connect to a remote mux
+// cannot be loaded.  This is not the fault of the backend.
+var errNoAuthToken = errors.New("unable to retrieve authentication token")
+
+// muxResultTimeout is how long to wait for a remote mux to answer a mux header
+const muxResultTimeout = 30 * time.Second
+
 func init() {
 	rand.Seed(time.Now().UnixNano())
 }
@@ -72,37 +83,46 @@ The netcat (nc) command is particularly useful for this:
 */
 
 type addressTuple struct {
-	host          string // IP of the host on which the container is running
-	containerAddr string // Container IP:port of the remote service
+	host          string                   // IP of the host on which the container is running
+	containerAddr string                   // Container IP:port of the remote service
+	healthStatus  map[string]health.Status // Health check results of the remote service
+}
+
+// key identifies the remote service regardless of its health
+func (a addressTuple) key() string {
+	return a.host + "/" + a.containerAddr
 }
 
 type proxy struct {
-	name             string              // Name of the remote service
-	tenantEndpointID string              // Tenant endpoint ID
-	addresses        []addressTuple      // Public/container IP:Port of the remote service
-	tcpMuxPort       uint16              // the port to use for TCP Muxing, 0 is disabled
-	useTLS           bool                // use encryption over mux port
-	closing          chan chan error     // internal shutdown signal
-	newAddresses     chan []addressTuple // a stream of updates to the addresses
-	listener         net.Listener        // handle on the listening socket
-	allowDirectConn  bool                // allow container to container connections
+	name             string                    // Name of the remote service
+	tenantID         string                    // Tenant of the local service
+	tenantEndpointID string                    // Tenant endpoint ID
+	backends         *backendPool              // Public/container IP:Port of the remote service
+	tcpMuxPort       uint16                    // the port to use for TCP Muxing, 0 is disabled
+	useTLS           bool                      // use encryption over mux port
+	closing          chan chan error           // internal shutdown signal
+	listener         net.Listener              // handle on the listening socket
+	allowDirectConn  bool                      // allow container to container connections
+	metrics          *svcproxy.EndpointMetrics // connection statistics
 }
 
 // Newproxy create a new proxy object. It starts listening on the prxy port asynchronously.
-func newProxy(name, tenantEndpointID string, tcpMuxPort uint16, useTLS bool, listener net.Listener, allowDirectConn bool) (p *proxy, err error) {
+func newProxy(name, tenantID, tenantEndpointID string, tcpMuxPort uint16, useTLS bool, listener net.Listener, allowDirectConn bool, metrics *svcproxy.EndpointMetrics) (p *proxy, err error) {
 	if len(name) == 0 {
 		return nil, fmt.Errorf("prxy: name can not be empty")
 	}
 	p = &proxy{
 		name:             name,
+		tenantID:         tenantID,
 		tenantEndpointID: tenantEndpointID,
-		addresses:        make([]addressTuple, 0),
+		backends:         newBackendPool(),
 		tcpMuxPort:       tcpMuxPort,
 		useTLS:           useTLS,
+		closing:          make(chan chan error),
 		listener:         listener,
 		allowDirectConn:  allowDirectConn,
+		metrics:          metrics,
 	}
-	p.newAddresses = make(chan []addressTuple, 2)
 	go p.listenAndproxy()
 	return p, nil
 }
@@ -114,7 +134,7 @@ func (p *proxy) Name() string {
 
 // String() pretty prints the proxy struct.
 func (p *proxy) String() string {
-	return fmt.Sprintf("proxy[%s; %s]=>%v", p.name, p.listener, p.addresses)
+	return fmt.Sprintf("proxy[%s; %s]=>%v", p.name, p.listener, p.backends.Addresses())
 }
 
 // TCPMuxPort() returns the tcp port use for muxing, 0 if not used.
@@ -129,13 +149,7 @@ func (p *proxy) UseTLS() bool {
 
 // Set a new Destination Address set for the prxy
 func (p *proxy) SetNewAddresses(addresses []addressTuple) {
-	// Randomize the addresses so not all instances get them in the same order
-	dest := make([]addressTuple, len(addresses))
-	perm := rand.Perm(len(addresses))
-	for i, v := range perm {
-		dest[v] = addresses[i]
-	}
-	p.newAddresses <- dest
+	p.backends.SetAddresses(addresses)
 }
 
 // Close() terminates the prxy; it can not be restarted.
@@ -161,20 +175,15 @@ func (p *proxy) listenAndproxy() {
 		}
 	}(p.listener, connections)
 
-	i := 0
 	for {
 		select {
 		case conn := <-connections:
-			if len(p.addresses) == 0 {
+			if p.backends.Len() == 0 {
 				glog.Warningf("No remote services available for prxying %v", p)
 				conn.Close()
 				continue
 			}
-			i++
-			// round robin connections to list of addresses
-			glog.V(1).Infof("choosing address from %v", p.addresses)
-			go p.prxy(conn, p.addresses[i%len(p.addresses)])
-		case p.addresses = <-p.newAddresses:
+			go p.prxy(conn)
 		case errc := <-p.closing:
 			p.listener.Close()
 			errc <- nil
@@ -192,15 +201,61 @@ func getPort(addr string) (int, error) {
 	return strconv.Atoi(port)
 }
 
-// prxy takes an established local connection, Dials the remote address specified
-// by the proxy structure and then copies data to and from the resulting pair
-// of endpoints.
-func (p *proxy) prxy(local net.Conn, address addressTuple) {
+// prxy takes an established local connection, picks a backend, Dials it and
+// then copies data to and from the resulting pair of endpoints.  If the
+// backend cannot be reached it is ejected and the next backend is tried, up to
+// proxyRetries times, before the local connection is closed.
+func (p *proxy) prxy(local net.Conn) {
+	var token string
+	getToken := func() (string, error) {
+		if token != "" {
+			return token, nil
+		}
+		select {
+		case token = <-auth.AuthToken(nil):
+			return token, nil
+		case <-time.After(30 * time.Second):
+			return "", errNoAuthToken
+		}
+	}
+
+	tried := make(map[*backend]bool)
+	for attempt := 0; attempt <= proxyRetries; attempt++ {
+		b := p.backends.Next(tried)
+		if b == nil {
+			break
+		}
+		tried[b] = true
 
-	var (
-		remote net.Conn
-		err    error
-	)
+		start := time.Now()
+		remote, isMux, err := p.dial(b.address, getToken)
+		if err == errNoAuthToken {
+			p.backends.Cancel(b)
+			glog.Error("Unable to retrieve authentication token with 30 seconds")
+			break
+		} else if err != nil {
+			p.backends.Done(b, false)
+			p.metrics.Failed()
+			glog.Warningf("Could not connect to %s for %s; ejecting it (attempt %d): %s", b.address.containerAddr, p.name, attempt+1, err)
+			continue
+		}
+		remote = svcproxy.MeterConn(remote, p.metrics, start, plog.WithFields(log.Fields{
+			"endpoint":      p.name,
+			"containeraddr": b.address.containerAddr,
+			"mux":           isMux,
+		}))
+
+		p.pipe(local, remote, b)
+		return
+	}
+
+	glog.Errorf("No remote services could be reached for prxying %v", p)
+	local.Close()
+}
+
+// dial connects to the backend at the address, which will be either a local
+// container address or a mux port on a remote host.
+func (p *proxy) dial(address addressTuple, getToken func() (string, error)) (remote net.Conn, isMux bool, err error) {
 	glog.V(2).Infof("Setting up proxy for %#v", address)
 	isLocalContainer := false
 	localAddr := address.containerAddr
@@ -241,40 +296,35 @@ func (p *proxy) prxy(local net.Conn, address addressTuple) {
 	var (
 		muxAddrPacked []byte
 		token         string
-		tokenTimeout  = 30 * time.Second
 	)
 
 	if !isLocalContainer {
 		muxAddrPacked, err = utils.PackTCPAddressString(address.containerAddr)
 		if err != nil {
 			glog.Errorf("Container address is invalid. Can't create proxy: %s", address.containerAddr)
-			return
+			return nil, false, err
 		}
-		select {
-		case token = <-auth.AuthToken(nil):
-		case <-time.After(tokenTimeout):
-			glog.Error("Unable to retrieve authentication token with 30 seconds")
-			return
+		if token, err = getToken(); err != nil {
+			return nil, false, err
 		}
 	}
 
-	// Dial the target connection, which will be either a local container
-	// address or a mux port on a remote host.
 	switch {
 	case isLocalContainer:
 		glog.V(2).Infof("dialing local addr=> %s", localAddr)
 		remote, err = net.Dial("tcp4", localAddr)
 		if err != nil {
 			glog.Errorf("Error Local (net.Dial): %s", err)
-			return
+			return nil, false, err
 		}
+		return remote, false, nil
 	case p.useTLS:
 		glog.V(2).Infof("dialing remote tls => %s", muxAddr)
 		config := tls.Config{InsecureSkipVerify: true}
 		tlsConn, err := tls.Dial("tcp4", muxAddr, &config)
 		if err != nil {
 			glog.Errorf("Error TLS (net.Dial): %s", err)
-			return
+			return nil, false, err
 		}
 		remote = tlsConn // cast it to the net.Conn interface
 		cipher := tlsConn.ConnectionState().CipherSuite
@@ -284,21 +334,41 @@ func (p *proxy) prxy(local net.Conn, address addressTuple) {
 		remote, err = net.Dial("tcp4", muxAddr)
 		if err != nil {
 			glog.Errorf("Error Remote (net.Dial): %s", err)
-			return
+			return nil, false, err
 		}
 	}
 
-	// If this is not a local container, write the mux header
-	if token != "" && len(muxAddrPacked) > 0 {
-		auth.AddSignedMuxHeader(remote, muxAddrPacked, token)
+	// This is not a local container, so write the mux header
+	if err := auth.AddSignedMuxHeader(remote, muxAddrPacked, p.tenantID, token); err != nil {
+		glog.Errorf("Error writing mux header: %s", err)
+		remote.Close()
+		return nil, false, err
 	}
 
+	// Wait for the mux to authorize the connection and reach the container, so
+	// a refused connection can be retried on another backend.
+	remote.SetReadDeadline(time.Now().Add(muxResultTimeout))
+	if err := auth.ReadMuxResult(remote); err != nil {
+		glog.Errorf("Mux at %s did not connect to %s: %s", muxAddr, address.containerAddr, err)
+		remote.Close()
+		return nil, false, err
+	}
+	remote.SetReadDeadline(time.Time{})
+	return remote, true, nil
+}
+
+// pipe copies data between the local connection and the backend until either
+// side closes.  The backend has already accepted the connection, so it is
+// not ejected however the connection ends.
+func (p *proxy) pipe(local, remote net.Conn, b *backend) {
+	address := b.address
 	glog.V(2).Infof("Using hostAgent:%v to prxy %v<->%v<->%v<->%v",
 		remote.RemoteAddr(), local.LocalAddr(), local.RemoteAddr(), remote.LocalAddr(), address)
 	go func(address string) {
 		defer local.Close()
 		defer remote.Close()
 		io.Copy(local, remote)
+		p.backends.Done(b, true)
 		glog.V(2).Infof("Closing hostAgent:%v to prxy %v<->%v<->%v<->%v",
 			remote.RemoteAddr(), local.LocalAddr(), local.RemoteAddr(), remote.LocalAddr(), address)
 	}(address.containerAddr)
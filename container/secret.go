@@ -0,0 +1,84 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package container
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/control-center/serviced/domain/service"
+	"github.com/control-center/serviced/domain/servicedefinition"
+	"github.com/zenoss/glog"
+)
+
+// secretsDir is where secret files are written; it is on the tmpfs that
+// docker mounts at /dev/shm
+var secretsDir = servicedefinition.SecretsPath
+
+// setupSecrets writes the secrets of the service that are delivered as files
+// and returns the environment variables of the secrets that are delivered to
+// the service's process, using the values of the secrets by name.  The
+// environment variables are not written to the container environment file.
+// Secret files are owned by the user of the service's security context, if it
+// sets one.
+func setupSecrets(svc *service.Service, values map[string]string) ([]string, error) {
+	var env []string
+	owner := svc.SecurityContext.User()
+	for _, ref := range svc.Secrets {
+		value := values[ref.Name]
+		if value == "" {
+			return nil, fmt.Errorf("secret %s has no value", ref.Name)
+		}
+		if ref.File != "" {
+			if err := writeSecretFile(filepath.Join(secretsDir, ref.File), value, owner); err != nil {
+				glog.Errorf("Could not write secret %s: %s", ref.Name, err)
+				return nil, err
+			}
+		}
+		if ref.Env != "" {
+			env = append(env, fmt.Sprintf("%s=%s", ref.Env, value))
+		}
+	}
+	return env, nil
+}
+
+// writeSecretFile writes a secret that only the user running the service can
+// read, replacing the file if it exists.  If owner is set, the file and its
+// directory are given to that user.
+func writeSecretFile(filename, value, owner string) error {
+	dir := filepath.Dir(filename)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := ioutil.WriteFile(filename, []byte(value), 0400); err != nil {
+		return err
+	}
+	if owner == "" {
+		return nil
+	}
+	// chown resolves the user and group names from the container's image
+	for _, p := range []string{dir, filename} {
+		if output, err := exec.Command("chown", owner, p).CombinedOutput(); err != nil {
+			return fmt.Errorf("could not change the owner of %s to %s: %s (%s)", p, owner, err, strings.TrimSpace(string(output)))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build unit
+
+package container
+
+import (
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"syscall"
+	"testing"
+
+	"github.com/control-center/serviced/domain/service"
+	"github.com/control-center/serviced/domain/servicedefinition"
+)
+
+func TestSetupSecrets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	if err != nil {
+		t.Fatalf("Could not create temporary directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(d string) { secretsDir = d }(secretsDir)
+	secretsDir = dir
+
+	svc := &service.Service{
+		Secrets: []servicedefinition.SecretRef{
+			{Name: "db-password", File: "db", Env: "DB_PASSWORD"},
+			{Name: "api-token", Env: "API_TOKEN"},
+		},
+	}
+	values := map[string]string{"db-password": "hunter2", "api-token": "abc123"}
+	// secrets are written twice, as when the controller starts again in the
+	// same container
+	for i := 0; i < 2; i++ {
+		env, err := setupSecrets(svc, values)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if expected := []string{"DB_PASSWORD=hunter2", "API_TOKEN=abc123"}; !reflect.DeepEqual(env, expected) {
+			t.Errorf("Expected env %v, got %v", expected, env)
+		}
+	}
+
+	filename := filepath.Join(dir, "db")
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Could not read secret file: %s", err)
+	} else if string(content) != "hunter2" {
+		t.Errorf("Unexpected secret file content %q", content)
+	}
+	if fi, err := os.Stat(filename); err != nil {
+		t.Fatalf("Could not stat secret file: %s", err)
+	} else if mode := fi.Mode().Perm(); mode != 0400 {
+		t.Errorf("Expected mode 0400, got %o", mode)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "api-token")); !os.IsNotExist(err) {
+		t.Errorf("Secret delivered as environment variable was written to a file")
+	}
+}
+
+func TestSetupSecrets_SecurityContext(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("Changing the owner of a secret file requires root")
+	}
+	nobody, err := user.Lookup("nobody")
+	if err != nil {
+		t.Skipf("Could not look up user nobody: %s", err)
+	}
+	dir, err := ioutil.TempDir("", "secrets")
+	if err != nil {
+		t.Fatalf("Could not create temporary directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(d string) { secretsDir = d }(secretsDir)
+	secretsDir = filepath.Join(dir, "secrets")
+
+	svc := &service.Service{
+		SecurityContext: &servicedefinition.SecurityContext{RunAsUser: "nobody"},
+		Secrets: []servicedefinition.SecretRef{
+			{Name: "db-password", File: "db"},
+		},
+	}
+	values := map[string]string{"db-password": "hunter2"}
+	if _, err := setupSecrets(svc, values); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	// the service, not the controller, must be able to read the secret
+	for _, p := range []string{secretsDir, filepath.Join(secretsDir, "db")} {
+		fi, err := os.Stat(p)
+		if err != nil {
+			t.Fatalf("Could not stat %s: %s", p, err)
+		}
+		if uid := strconv.Itoa(int(fi.Sys().(*syscall.Stat_t).Uid)); uid != nobody.Uid {
+			t.Errorf("Expected %s to be owned by uid %s, got %s", p, nobody.Uid, uid)
+		}
+	}
+	if fi, err := os.Stat(secretsDir); err != nil {
+		t.Fatalf("Could not stat secrets directory: %s", err)
+	} else if mode := fi.Mode().Perm(); mode != 0700 {
+		t.Errorf("Expected mode 0700, got %o", mode)
+	}
+
+	svc.SecurityContext.RunAsUser = "no-such-user"
+	if _, err := setupSecrets(svc, values); err == nil {
+		t.Errorf("Expected an error for an unknown user")
+	}
+}
+
+func TestSetupSecrets_NoValue(t *testing.T) {
+	svc := &service.Service{
+		Secrets: []servicedefinition.SecretRef{{Name: "db-password", Env: "DB_PASSWORD"}},
+	}
+	if _, err := setupSecrets(svc, map[string]string{"api-token": "abc123"}); err == nil {
+		t.Errorf("Expected an error for a secret without a value")
+	}
+}
@@ -14,6 +14,7 @@
 package container
 
 import (
+	svcproxy "github.com/control-center/serviced/proxy"
 	"github.com/control-center/serviced/stats"
 	"github.com/zenoss/glog"
 
@@ -28,13 +29,13 @@ import (
 
 // statReporter perically collects statistics at the given
 // interval until the closing channel closes
-func statReporter(statsUrl string, interval time.Duration) {
+func statReporter(statsUrl string, interval time.Duration, proxyMetrics *svcproxy.ConnectionMetrics) {
 
 	tick := time.Tick(interval)
 	for {
 		select {
 		case t := <-tick:
-			collect(t, statsUrl)
+			collect(t, statsUrl, proxyMetrics)
 		}
 	}
 }
@@ -46,7 +47,7 @@ var procNetFiles = map[string]string{
 	"raw": "/proc/net/raw",
 }
 
-func collect(ts time.Time, statsUrl string) {
+func collect(ts time.Time, statsUrl string, proxyMetrics *svcproxy.ConnectionMetrics) {
 	// TODO: At some point we can look at refactoring this to use the
 	// 'serviced metric' code
 
@@ -89,6 +90,8 @@ func collect(ts time.Time, statsUrl string) {
 		samples = append(samples, sample)
 	}
 
+	// collect endpoint proxy connection statistics
+	samples = append(samples, proxyMetrics.Samples(ts)...)
 
 	glog.V(4).Infof("posting samples: %+v", samples)
 	if err := stats.Post(statsUrl, samples); err != nil {
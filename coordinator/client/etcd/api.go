@@ -0,0 +1,308 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/control-center/serviced/coordinator/client"
+)
+
+// The etcd v3 API is reached through the JSON gateway that every etcd server
+// serves next to gRPC, so that the driver does not need the gRPC client.  Keys
+// and values are base64 encoded by encoding/json, and the gateway encodes
+// 64-bit integers as strings.
+
+// keyValue is a key in etcd
+type keyValue struct {
+	Key            []byte `json:"key,omitempty"`
+	Value          []byte `json:"value,omitempty"`
+	CreateRevision int64  `json:"create_revision,string,omitempty"`
+	ModRevision    int64  `json:"mod_revision,string,omitempty"`
+	Version        int64  `json:"version,string,omitempty"`
+	Lease          int64  `json:"lease,string,omitempty"`
+}
+
+// responseHeader is the header of every response
+type responseHeader struct {
+	Revision int64 `json:"revision,string,omitempty"`
+}
+
+type rangeRequest struct {
+	Key       []byte `json:"key"`
+	RangeEnd  []byte `json:"range_end,omitempty"`
+	KeysOnly  bool   `json:"keys_only,omitempty"`
+	CountOnly bool   `json:"count_only,omitempty"`
+}
+
+type rangeResponse struct {
+	Header responseHeader `json:"header"`
+	Kvs    []keyValue     `json:"kvs"`
+	Count  int64          `json:"count,string,omitempty"`
+}
+
+type putRequest struct {
+	Key         []byte `json:"key"`
+	Value       []byte `json:"value,omitempty"`
+	Lease       int64  `json:"lease,string,omitempty"`
+	IgnoreLease bool   `json:"ignore_lease,omitempty"`
+}
+
+type deleteRangeRequest struct {
+	Key      []byte `json:"key"`
+	RangeEnd []byte `json:"range_end,omitempty"`
+}
+
+// compare is a condition of a transaction.  Only the field of the target may
+// be set, so the values are kept as strings.
+type compare struct {
+	Result         string `json:"result"`
+	Target         string `json:"target"`
+	Key            []byte `json:"key"`
+	Version        string `json:"version,omitempty"`
+	CreateRevision string `json:"create_revision,omitempty"`
+	Lease          string `json:"lease,omitempty"`
+}
+
+// versionIs compares the number of times a key has been written since it was
+// created, which is 0 if the key does not exist.
+func versionIs(key []byte, version int64) compare {
+	return compare{Result: "EQUAL", Target: "VERSION", Key: key, Version: strconv.FormatInt(version, 10)}
+}
+
+// keyExists compares the create revision of a key, which is 0 if the key does
+// not exist.
+func keyExists(key []byte) compare {
+	return compare{Result: "GREATER", Target: "CREATE", Key: key, CreateRevision: "0"}
+}
+
+// leaseIs compares the lease of a key
+func leaseIs(key []byte, lease int64) compare {
+	return compare{Result: "EQUAL", Target: "LEASE", Key: key, Lease: strconv.FormatInt(lease, 10)}
+}
+
+type requestOp struct {
+	RequestRange       *rangeRequest       `json:"request_range,omitempty"`
+	RequestPut         *putRequest         `json:"request_put,omitempty"`
+	RequestDeleteRange *deleteRangeRequest `json:"request_delete_range,omitempty"`
+}
+
+type txnRequest struct {
+	Compare []compare   `json:"compare,omitempty"`
+	Success []requestOp `json:"success,omitempty"`
+	Failure []requestOp `json:"failure,omitempty"`
+}
+
+type responseOp struct {
+	ResponseRange *rangeResponse `json:"response_range"`
+}
+
+type txnResponse struct {
+	Header    responseHeader `json:"header"`
+	Succeeded bool           `json:"succeeded"`
+	Responses []responseOp   `json:"responses"`
+}
+
+type leaseGrantRequest struct {
+	TTL int64 `json:"TTL,string"`
+}
+
+type leaseRequest struct {
+	ID int64 `json:"ID,string"`
+}
+
+type leaseResponse struct {
+	ID  int64 `json:"ID,string,omitempty"`
+	TTL int64 `json:"TTL,string,omitempty"`
+}
+
+// streamResponse is one message of a streaming response from the gateway
+type streamResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *gatewayError   `json:"error"`
+}
+
+// gatewayError is an error returned by the gateway
+type gatewayError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Err     string `json:"error"`
+}
+
+func (e *gatewayError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = e.Err
+	}
+	return fmt.Sprintf("etcd: %s (code %d)", msg, e.Code)
+}
+
+// api calls the JSON gateway of an etcd cluster
+type api struct {
+	mu        *sync.Mutex
+	endpoints []string
+	current   int
+	client    *http.Client // for requests
+	streams   *http.Client // for watches, which have no timeout
+}
+
+func newAPI(endpoints []string, timeout time.Duration) *api {
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		Dial: (&net.Dialer{
+			Timeout:   timeout,
+			KeepAlive: 30 * time.Second,
+		}).Dial,
+		MaxIdleConnsPerHost: 8,
+	}
+	return &api{
+		mu:        &sync.Mutex{},
+		endpoints: endpoints,
+		client:    &http.Client{Transport: transport, Timeout: timeout},
+		streams:   &http.Client{Transport: transport},
+	}
+}
+
+// post sends a request to the gateway, trying each endpoint in turn until one
+// of them answers.  The body of the response is returned to the caller to
+// close.
+func (a *api) post(c *http.Client, method string, request interface{}, cancel <-chan struct{}) (io.ReadCloser, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, client.ErrSerialization
+	}
+
+	a.mu.Lock()
+	start := a.current
+	a.mu.Unlock()
+
+	var lastErr error
+	for i := 0; i < len(a.endpoints); i++ {
+		n := (start + i) % len(a.endpoints)
+		endpoint := a.endpoints[n]
+		req, err := http.NewRequest("POST", strings.TrimRight(endpoint, "/")+method, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Cancel = cancel
+		resp, err := c.Do(req)
+		if err != nil {
+			select {
+			case <-cancel:
+				return nil, err
+			default:
+			}
+			plog.WithError(err).WithField("endpoint", endpoint).Debug("Could not reach etcd endpoint")
+			lastErr = err
+			continue
+		}
+		a.mu.Lock()
+		a.current = n
+		a.mu.Unlock()
+
+		if resp.StatusCode != http.StatusOK {
+			defer resp.Body.Close()
+			gerr := &gatewayError{Code: resp.StatusCode}
+			data, _ := ioutil.ReadAll(resp.Body)
+			if err := json.Unmarshal(data, gerr); err != nil || (gerr.Message == "" && gerr.Err == "") {
+				gerr.Message = strings.TrimSpace(string(data))
+			}
+			return nil, gerr
+		}
+		return resp.Body, nil
+	}
+	plog.WithError(lastErr).WithField("endpoints", a.endpoints).Warn("Could not reach any etcd endpoint")
+	return nil, client.ErrNoServer
+}
+
+// call sends a request and decodes the response
+func (a *api) call(method string, request, response interface{}) error {
+	body, err := a.post(a.client, method, request, nil)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	if err := json.NewDecoder(body).Decode(response); err != nil {
+		return client.ErrSerialization
+	}
+	return nil
+}
+
+func (a *api) Range(req *rangeRequest) (*rangeResponse, error) {
+	resp := &rangeResponse{}
+	return resp, a.call("/v3/kv/range", req, resp)
+}
+
+func (a *api) Txn(req *txnRequest) (*txnResponse, error) {
+	resp := &txnResponse{}
+	return resp, a.call("/v3/kv/txn", req, resp)
+}
+
+func (a *api) LeaseGrant(ttl time.Duration) (int64, error) {
+	resp := &leaseResponse{}
+	if err := a.call("/v3/lease/grant", &leaseGrantRequest{TTL: int64(ttl / time.Second)}, resp); err != nil {
+		return 0, err
+	}
+	return resp.ID, nil
+}
+
+// LeaseKeepAlive renews a lease and returns its remaining time to live, which
+// is 0 if the lease has expired.
+func (a *api) LeaseKeepAlive(id int64) (time.Duration, error) {
+	body, err := a.post(a.client, "/v3/lease/keepalive", &leaseRequest{ID: id}, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+	msg := &streamResponse{}
+	if err := json.NewDecoder(body).Decode(msg); err != nil {
+		return 0, client.ErrSerialization
+	} else if msg.Error != nil {
+		return 0, msg.Error
+	}
+	resp := &leaseResponse{}
+	if err := json.Unmarshal(msg.Result, resp); err != nil {
+		return 0, client.ErrSerialization
+	}
+	return time.Duration(resp.TTL) * time.Second, nil
+}
+
+func (a *api) LeaseRevoke(id int64) error {
+	return a.call("/v3/lease/revoke", &leaseRequest{ID: id}, &struct{}{})
+}
+
+// prefixEnd returns the end of the range of keys that start with prefix
+func prefixEnd(prefix []byte) []byte {
+	end := make([]byte, len(prefix))
+	copy(end, prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	// the prefix is all 0xff, so range to the end of the keyspace
+	return []byte{0}
+}
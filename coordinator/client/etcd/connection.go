@@ -0,0 +1,688 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcd
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/control-center/serviced/coordinator/client"
+)
+
+// Stat is the version of a node
+type Stat struct {
+	Version        int64 // number of writes since the node was created, starting at 1
+	CreateRevision int64 // revision of the cluster when the node was created
+	ModRevision    int64 // revision of the cluster when the node was last written
+}
+
+// Connection is an etcd based implementation of client.Connection.  Paths
+// are joined to the base path and stored as keys under the prefix.
+type Connection struct {
+	sync.RWMutex
+	api      *api
+	prefix   string
+	basePath string
+	ttl      time.Duration
+	lease    int64
+	closing  chan struct{}
+	onClose  func(int)
+	id       int
+}
+
+// Assert that Connection implements client.Connection.
+var _ client.Connection = &Connection{}
+
+// IsClosed returns connection closed error if true, otherwise returns nil.
+func (c *Connection) isClosed() error {
+	if c.api == nil {
+		return client.ErrConnectionClosed
+	}
+	return nil
+}
+
+// Close closes the client connection to etcd and removes its ephemeral
+// nodes. Calling close twice will result in a no-op.
+func (c *Connection) Close() {
+	c.Lock()
+	defer c.Unlock()
+	if c.api != nil {
+		close(c.closing)
+		if err := c.api.LeaseRevoke(c.lease); err != nil {
+			plog.WithError(err).WithField("lease", c.lease).Debug("Could not revoke etcd session")
+		}
+		c.api = nil
+		if c.onClose != nil {
+			c.onClose(c.id)
+			c.onClose = nil
+		}
+	}
+}
+
+// SetID sets the connection ID
+func (c *Connection) SetID(i int) {
+	c.Lock()
+	defer c.Unlock()
+	c.id = i
+}
+
+// ID gets the connection ID
+func (c *Connection) ID() int {
+	c.RLock()
+	defer c.RUnlock()
+	return c.id
+}
+
+// SetOnClose performs cleanup when a connection is closed
+func (c *Connection) SetOnClose(onClose func(int)) {
+	c.Lock()
+	defer c.Unlock()
+	if err := c.isClosed(); err == nil {
+		c.onClose = onClose
+	}
+}
+
+// keepAlive renews the session of the connection until it is closed.  If the
+// session expires, its ephemeral nodes are gone and a new session is created.
+func (c *Connection) keepAlive() {
+	ticker := time.NewTicker(c.ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-c.closing:
+			return
+		}
+
+		c.RLock()
+		api, lease := c.api, c.lease
+		c.RUnlock()
+		if api == nil {
+			return
+		}
+		logger := plog.WithField("lease", lease)
+
+		ttl, err := api.LeaseKeepAlive(lease)
+		if err != nil {
+			logger.WithError(err).Warn("Could not renew etcd session")
+			continue
+		} else if ttl > 0 {
+			continue
+		}
+
+		logger.Warn("etcd session expired; ephemeral nodes were removed")
+		newLease, err := api.LeaseGrant(c.ttl)
+		if err != nil {
+			logger.WithError(err).Warn("Could not create etcd session")
+			continue
+		}
+		c.Lock()
+		if c.api != nil {
+			c.lease = newLease
+		}
+		c.Unlock()
+		plog.WithField("lease", newLease).Debug("etcd connection has new session")
+	}
+}
+
+// NewTransaction creates a new transaction object
+func (c *Connection) NewTransaction() client.Transaction {
+	return &Transaction{
+		conn: c,
+		ops:  []multiReq{},
+	}
+}
+
+// NewLock creates a new lock object
+func (c *Connection) NewLock(p string) (client.Lock, error) {
+	c.RLock()
+	defer c.RUnlock()
+	if err := c.isClosed(); err != nil {
+		return nil, err
+	}
+	return &Lock{conn: c, path: c.abs(p)}, nil
+}
+
+// NewLeader returns a managed leader object at the given path bound to the
+// current connection.
+func (c *Connection) NewLeader(p string) (client.Leader, error) {
+	c.RLock()
+	defer c.RUnlock()
+	if err := c.isClosed(); err != nil {
+		return nil, err
+	}
+	return &Leader{conn: c, path: c.abs(p)}, nil
+}
+
+// abs returns the path of a node from the root
+func (c *Connection) abs(p string) string {
+	return path.Join("/", c.basePath, p)
+}
+
+// key returns the key of the node at the absolute path
+func (c *Connection) key(p string) []byte {
+	return []byte(c.prefix + "/nodes" + p)
+}
+
+// childPrefix returns the prefix of the keys of the children of the node at
+// the absolute path
+func (c *Connection) childPrefix(p string) []byte {
+	if p == "/" {
+		return c.key(p)
+	}
+	return c.key(p + "/")
+}
+
+// seqKey returns the key of the counter of the sequential nodes created under
+// the node at the absolute path
+func (c *Connection) seqKey(p string) []byte {
+	return []byte(c.prefix + "/seq" + p)
+}
+
+// childName returns the name of the child of the node at the absolute path if
+// key is the key of one of its direct children.
+func (c *Connection) childName(p string, key []byte) (string, bool) {
+	prefix := c.childPrefix(p)
+	if len(key) <= len(prefix) || string(key[:len(prefix)]) != string(prefix) {
+		return "", false
+	}
+	name := string(key[len(prefix):])
+	if strings.Contains(name, "/") {
+		return "", false
+	}
+	return name, true
+}
+
+// parentCompares returns the conditions for creating a child under the node
+// at the absolute path: the parent must exist and may not be ephemeral.
+func (c *Connection) parentCompares(p string) []compare {
+	if p == "/" {
+		return nil
+	}
+	key := c.key(p)
+	return []compare{keyExists(key), leaseIs(key, 0)}
+}
+
+// Create adds a node at the specified path
+func (c *Connection) Create(path string, node client.Node) error {
+	c.RLock()
+	defer c.RUnlock()
+	if err := c.isClosed(); err != nil {
+		return err
+	}
+	p := c.abs(path)
+	if err := c.ensurePath(p); err != nil {
+		return err
+	}
+	return c.create(p, node)
+}
+
+// CreateIfExists adds a node at the specified path if the dirpath already
+// exists.
+func (c *Connection) CreateIfExists(path string, node client.Node) error {
+	c.RLock()
+	defer c.RUnlock()
+	if err := c.isClosed(); err != nil {
+		return err
+	}
+	return c.create(c.abs(path), node)
+}
+
+func (c *Connection) create(p string, node client.Node) error {
+	bytes, err := json.Marshal(node)
+	if err != nil {
+		return client.ErrSerialization
+	}
+	rev, err := c.put(p, bytes, 0)
+	if err != nil {
+		return err
+	}
+	node.SetVersion(&Stat{Version: 1, CreateRevision: rev, ModRevision: rev})
+	return nil
+}
+
+// put creates the key of a node, with the lease if it is ephemeral, and
+// returns the revision at which it was created.
+func (c *Connection) put(p string, value []byte, lease int64) (int64, error) {
+	if p == "/" {
+		return 0, client.ErrNodeExists
+	}
+	key := c.key(p)
+	resp, err := c.api.Txn(&txnRequest{
+		Compare: append(c.parentCompares(path.Dir(p)), versionIs(key, 0)),
+		Success: []requestOp{{RequestPut: &putRequest{Key: key, Value: value, Lease: lease}}},
+	})
+	if err != nil {
+		return 0, err
+	} else if !resp.Succeeded {
+		return 0, c.createError(p)
+	}
+	return resp.Header.Revision, nil
+}
+
+// createError explains why a node could not be created at the absolute path
+func (c *Connection) createError(p string) error {
+	if kv, err := c.getKV(p); err != nil {
+		return err
+	} else if kv != nil {
+		return client.ErrNodeExists
+	}
+	parent, err := c.getKV(path.Dir(p))
+	if err != nil {
+		return err
+	} else if parent == nil && path.Dir(p) != "/" {
+		return client.ErrNoNode
+	} else if parent != nil && parent.Lease != 0 {
+		return client.ErrNoChildrenForEphemerals
+	}
+	return client.ErrBadVersion
+}
+
+// CreateDir adds a dir at the specified path
+func (c *Connection) CreateDir(path string) error {
+	c.RLock()
+	defer c.RUnlock()
+	if err := c.isClosed(); err != nil {
+		return err
+	}
+	p := c.abs(path)
+	if err := c.ensurePath(p); err != nil {
+		return err
+	}
+	return c.createDir(p)
+}
+
+func (c *Connection) createDir(p string) error {
+	_, err := c.put(p, nil, 0)
+	return err
+}
+
+// ensurePath creates the parents of the node at the absolute path
+func (c *Connection) ensurePath(p string) error {
+	dp := path.Dir(p)
+	if p == "/" || dp == "/" {
+		return nil
+	}
+	if exists, err := c.exists(dp); err != nil {
+		return err
+	} else if exists {
+		return nil
+	}
+	if err := c.ensurePath(dp); err != nil {
+		return err
+	} else if err := c.createDir(dp); err != nil && err != client.ErrNodeExists {
+		return err
+	}
+	return nil
+}
+
+// CreateEphemeral creates a node whose existance depends on the persistence of
+// the connection.
+func (c *Connection) CreateEphemeral(path string, node client.Node) (string, error) {
+	c.RLock()
+	defer c.RUnlock()
+	if err := c.isClosed(); err != nil {
+		return "", err
+	}
+	p := c.abs(path)
+	if err := c.ensurePath(p); err != nil {
+		return "", err
+	}
+	return c.createEphemeral(p, node)
+}
+
+// CreateEphemeralIfExists creates an ephemeral node at the given path if it
+// exists.
+func (c *Connection) CreateEphemeralIfExists(path string, node client.Node) (string, error) {
+	c.RLock()
+	defer c.RUnlock()
+	if err := c.isClosed(); err != nil {
+		return "", err
+	}
+	return c.createEphemeral(c.abs(path), node)
+}
+
+// createEphemeral creates a sequential node that is removed when the session
+// of the connection ends, and returns its absolute path.  The sequence number
+// is the version of a counter for the parent, which is incremented in the
+// same transaction.
+func (c *Connection) createEphemeral(p string, node client.Node) (string, error) {
+	bytes, err := json.Marshal(node)
+	if err != nil {
+		return "", client.ErrSerialization
+	}
+	dp := path.Dir(p)
+	seqKey := c.seqKey(dp)
+	for {
+		resp, err := c.api.Range(&rangeRequest{Key: seqKey})
+		if err != nil {
+			return "", err
+		}
+		var seq int64
+		if len(resp.Kvs) > 0 {
+			seq = resp.Kvs[0].Version
+		}
+		epth := fmt.Sprintf("%s%010d", p, seq+1)
+		key := c.key(epth)
+
+		txn, err := c.api.Txn(&txnRequest{
+			Compare: append(c.parentCompares(dp), versionIs(seqKey, seq), versionIs(key, 0)),
+			Success: []requestOp{
+				{RequestPut: &putRequest{Key: seqKey}},
+				{RequestPut: &putRequest{Key: key, Value: bytes, Lease: c.lease}},
+			},
+		})
+		if err != nil {
+			return "", err
+		} else if txn.Succeeded {
+			node.SetVersion(&Stat{Version: 1, CreateRevision: txn.Header.Revision, ModRevision: txn.Header.Revision})
+			return epth, nil
+		}
+
+		// try the next number if another node took this one
+		if err := c.createError(epth); err != client.ErrBadVersion {
+			return "", err
+		}
+	}
+}
+
+// Set assigns a value to an existing node at a given path
+func (c *Connection) Set(path string, node client.Node) error {
+	c.RLock()
+	defer c.RUnlock()
+	if err := c.isClosed(); err != nil {
+		return err
+	}
+	return c.set(c.abs(path), node)
+}
+
+// setOp returns the conditions and request to update a node.  A node without
+// a version may only be updated if it has not been written since it was
+// created.
+func (c *Connection) setOp(p string, node client.Node) (compare, requestOp, error) {
+	bytes, err := json.Marshal(node)
+	if err != nil {
+		return compare{}, requestOp{}, client.ErrSerialization
+	}
+	stat := &Stat{Version: 1}
+	if version := node.Version(); version != nil {
+		var ok bool
+		if stat, ok = version.(*Stat); !ok {
+			return compare{}, requestOp{}, client.ErrInvalidVersionObj
+		}
+	}
+	key := c.key(p)
+	return versionIs(key, stat.Version), requestOp{RequestPut: &putRequest{Key: key, Value: bytes, IgnoreLease: true}}, nil
+}
+
+func (c *Connection) set(p string, node client.Node) error {
+	cmp, op, err := c.setOp(p, node)
+	if err != nil {
+		return err
+	}
+	resp, err := c.api.Txn(&txnRequest{
+		Compare: []compare{cmp},
+		Success: []requestOp{op},
+	})
+	if err != nil {
+		return err
+	} else if !resp.Succeeded {
+		return c.setError(p)
+	}
+	return nil
+}
+
+// setError explains why the node at the absolute path could not be updated
+func (c *Connection) setError(p string) error {
+	if kv, err := c.getKV(p); err != nil {
+		return err
+	} else if kv == nil {
+		return client.ErrNoNode
+	}
+	return client.ErrBadVersion
+}
+
+// Delete recursively removes a path and its children
+func (c *Connection) Delete(path string) error {
+	c.RLock()
+	defer c.RUnlock()
+	if err := c.isClosed(); err != nil {
+		return err
+	}
+	return c.delete(c.abs(path))
+}
+
+// deleteOps returns the requests that remove a node and its children
+func (c *Connection) deleteOps(p string) []requestOp {
+	prefix := c.childPrefix(p)
+	ops := []requestOp{
+		{RequestDeleteRange: &deleteRangeRequest{Key: prefix, RangeEnd: prefixEnd(prefix)}},
+	}
+	if p != "/" {
+		ops = append(ops, requestOp{RequestDeleteRange: &deleteRangeRequest{Key: c.key(p)}})
+	}
+	return ops
+}
+
+func (c *Connection) delete(p string) error {
+	req := &txnRequest{Success: c.deleteOps(p)}
+	if p != "/" {
+		req.Compare = []compare{keyExists(c.key(p))}
+	}
+	resp, err := c.api.Txn(req)
+	if err != nil {
+		return err
+	} else if !resp.Succeeded {
+		return client.ErrNoNode
+	}
+	return nil
+}
+
+// Exists returns true if the path exists
+func (c *Connection) Exists(path string) (bool, error) {
+	c.RLock()
+	defer c.RUnlock()
+	if err := c.isClosed(); err != nil {
+		return false, err
+	}
+	return c.exists(c.abs(path))
+}
+
+func (c *Connection) exists(p string) (bool, error) {
+	if p == "/" {
+		return true, nil
+	}
+	kv, err := c.getKV(p)
+	return kv != nil, err
+}
+
+// getKV returns the key of the node at the absolute path, or nil if the node
+// does not exist.
+func (c *Connection) getKV(p string) (*keyValue, error) {
+	kv, _, err := c.getKVRev(p)
+	return kv, err
+}
+
+// getKVRev returns the key of the node at the absolute path and the revision
+// at which it was read.
+func (c *Connection) getKVRev(p string) (*keyValue, int64, error) {
+	resp, err := c.api.Range(&rangeRequest{Key: c.key(p)})
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, resp.Header.Revision, nil
+	}
+	return &resp.Kvs[0], resp.Header.Revision, nil
+}
+
+// ExistsW sets a watch on a node and alerts whenever it is added or removed.
+func (c *Connection) ExistsW(path string, cancel <-chan struct{}) (bool, <-chan client.Event, error) {
+	c.RLock()
+	defer c.RUnlock()
+	if err := c.isClosed(); err != nil {
+		return false, nil, err
+	}
+	return c.existsW(c.abs(path), cancel)
+}
+
+func (c *Connection) existsW(p string, cancel <-chan struct{}) (bool, <-chan client.Event, error) {
+	kv, rev, err := c.getKVRev(p)
+	if err != nil {
+		return false, nil, err
+	}
+	key := c.key(p)
+	ch := c.watch(key, nil, rev+1, cancel, func(ev *watchEvent) (client.EventType, bool) {
+		return ev.nodeEvent(), true
+	})
+	return kv != nil || p == "/", ch, nil
+}
+
+// Get returns the node at the given path.
+func (c *Connection) Get(path string, node client.Node) error {
+	c.RLock()
+	defer c.RUnlock()
+	if err := c.isClosed(); err != nil {
+		return err
+	}
+	_, err := c.get(c.abs(path), node)
+	return err
+}
+
+// get reads the node at the absolute path and returns the revision at which
+// it was read.
+func (c *Connection) get(p string, node client.Node) (int64, error) {
+	kv, rev, err := c.getKVRev(p)
+	if err != nil {
+		return 0, err
+	} else if kv == nil {
+		return 0, client.ErrNoNode
+	}
+	if len(kv.Value) > 0 {
+		if err := json.Unmarshal(kv.Value, node); err != nil {
+			return 0, client.ErrSerialization
+		}
+	} else {
+		err = client.ErrEmptyNode
+	}
+	node.SetVersion(&Stat{
+		Version:        kv.Version,
+		CreateRevision: kv.CreateRevision,
+		ModRevision:    kv.ModRevision,
+	})
+	return rev, err
+}
+
+// GetW returns the node at the given path as well as a channel to watch for
+// events on that node.
+func (c *Connection) GetW(path string, node client.Node, cancel <-chan struct{}) (<-chan client.Event, error) {
+	c.RLock()
+	defer c.RUnlock()
+	if err := c.isClosed(); err != nil {
+		return nil, err
+	}
+	return c.getW(c.abs(path), node, cancel)
+}
+
+func (c *Connection) getW(p string, node client.Node, cancel <-chan struct{}) (<-chan client.Event, error) {
+	rev, err := c.get(p, node)
+	if err != nil {
+		return nil, err
+	}
+	ch := c.watch(c.key(p), nil, rev+1, cancel, func(ev *watchEvent) (client.EventType, bool) {
+		return ev.nodeEvent(), true
+	})
+	return ch, nil
+}
+
+// Children returns the children of the node at the given path.
+func (c *Connection) Children(path string) ([]string, error) {
+	c.RLock()
+	defer c.RUnlock()
+	if err := c.isClosed(); err != nil {
+		return []string{}, err
+	}
+	children, _, err := c.children(c.abs(path))
+	return children, err
+}
+
+// children returns the children of the node at the absolute path and the
+// revision at which they were read.
+func (c *Connection) children(p string) ([]string, int64, error) {
+	prefix := c.childPrefix(p)
+	ops := []requestOp{
+		{RequestRange: &rangeRequest{Key: prefix, RangeEnd: prefixEnd(prefix), KeysOnly: true}},
+	}
+	if p != "/" {
+		ops = append(ops, requestOp{RequestRange: &rangeRequest{Key: c.key(p), CountOnly: true}})
+	}
+	resp, err := c.api.Txn(&txnRequest{Success: ops})
+	if err != nil {
+		return []string{}, 0, err
+	} else if len(resp.Responses) != len(ops) {
+		return []string{}, 0, client.ErrAPIError
+	}
+	if p != "/" {
+		if node := resp.Responses[1].ResponseRange; node == nil || node.Count == 0 {
+			return []string{}, 0, client.ErrNoNode
+		}
+	}
+
+	children := []string{}
+	if r := resp.Responses[0].ResponseRange; r != nil {
+		for _, kv := range r.Kvs {
+			if name, ok := c.childName(p, kv.Key); ok {
+				children = append(children, name)
+			}
+		}
+	}
+	return children, resp.Header.Revision, nil
+}
+
+// ChildrenW returns the children of the node at the given path as well as a
+// channel to watch for events on that node.
+func (c *Connection) ChildrenW(path string, cancel <-chan struct{}) ([]string, <-chan client.Event, error) {
+	c.RLock()
+	defer c.RUnlock()
+	if err := c.isClosed(); err != nil {
+		return []string{}, nil, err
+	}
+	return c.childrenW(c.abs(path), cancel)
+}
+
+func (c *Connection) childrenW(p string, cancel <-chan struct{}) ([]string, <-chan client.Event, error) {
+	children, rev, err := c.children(p)
+	if err != nil {
+		return []string{}, nil, err
+	}
+
+	// watch the node and everything that sorts between it and the end of its
+	// children, and only report when a child is added or removed, or the node
+	// itself is deleted.
+	key, prefix := c.key(p), c.childPrefix(p)
+	ch := c.watch(key, prefixEnd(prefix), rev+1, cancel, func(ev *watchEvent) (client.EventType, bool) {
+		if string(ev.Kv.Key) == string(key) && p != "/" {
+			return client.EventNodeDeleted, ev.Type == eventDelete
+		}
+		if _, ok := c.childName(p, ev.Kv.Key); ok {
+			return client.EventNodeChildrenChanged, ev.Type == eventDelete || ev.Kv.Version == 1
+		}
+		return 0, false
+	})
+	return children, ch, nil
+}
@@ -0,0 +1,566 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build integration
+
+package etcd
+
+import (
+	"fmt"
+	"path"
+	"testing"
+	"time"
+
+	coordclient "github.com/control-center/serviced/coordinator/client"
+)
+
+type testNodeT struct {
+	Name    string
+	version interface{}
+}
+
+func (n *testNodeT) SetVersion(version interface{}) {
+	fmt.Printf("setting version to: %v", version)
+	n.version = version
+}
+func (n *testNodeT) Version() interface{} { return n.version }
+
+func TestEtcdDriver(t *testing.T) {
+	dsn, stop := startEtcd(t)
+	defer stop()
+
+	drv := Driver{}
+	basePath := "/basePath"
+	conn, err := drv.GetConnection(dsn, basePath)
+	if err != nil {
+		t.Fatal("unexpected error getting connection")
+	}
+	exists, err := conn.Exists("/foo")
+	if err != nil {
+		t.Fatalf("err calling exists: %s", err)
+	}
+	if exists {
+		t.Fatal("foo should not exist")
+	}
+
+	err = conn.Delete("/foo")
+	if err == nil {
+		t.Fatalf("delete on non-existent object should fail")
+	}
+
+	err = conn.CreateDir("/foo")
+	if err != nil {
+		t.Fatalf("creating /foo should work: %s", err)
+	}
+
+	testNode := &testNodeT{
+		Name: "test",
+	}
+	err = conn.Create("/foo/bar", testNode)
+	if err != nil {
+		t.Fatalf("creating /foo/bar should work: %s", err)
+	}
+	t.Logf("testNode version: %v", testNode.Version())
+
+	exists, err = conn.Exists("/foo/bar")
+	if err != nil {
+		t.Fatalf("could not call exists: %s", err)
+	}
+
+	if !exists {
+		t.Fatal("/foo/bar should  exist")
+	}
+
+	testNode2 := &testNodeT{
+		Name: "baz",
+	}
+	err = conn.Get("/foo/bar", testNode2)
+	if err != nil {
+		t.Fatalf("could not get /foo/bar node: %s", err)
+	}
+
+	if testNode.Name != testNode2.Name {
+		t.Fatalf("expected testNodes to match %s  --- %s", testNode.Name, testNode2.Name)
+	}
+
+	err = conn.Get("/foo/bar", testNode2)
+	testNode2.Name = "abc"
+	if err := conn.Set("/foo/bar", testNode2); err != nil {
+		t.Fatalf("Could not update testNode: %s", err)
+	}
+
+	err = conn.Delete("/foo")
+	if err != nil {
+		t.Fatalf("delete of /foo should work: %s", err)
+	}
+
+	err = conn.CreateDir("/fum/bar/baz/echo/p/q")
+	if err != nil {
+		t.Fatalf("creating /fum/bar/baz/echo/p/q should work")
+	}
+	exists, err = conn.Exists("/fum/bar/baz/echo/p/q")
+	if err != nil {
+		t.Fatalf("could not call exists: %s", err)
+	}
+	if !exists {
+		t.Fatal("/fum/bar/baz/echo/p/q should exist")
+	}
+	err = conn.Delete("/fum")
+	if err != nil {
+		t.Fatalf("delete of /fum should work: %s", err)
+	}
+
+	conn.Close()
+}
+
+func TestEtcdDriver_Multi(t *testing.T) {
+	dsn, stop := startEtcd(t)
+	defer stop()
+
+	drv := Driver{}
+
+	basePath := "/basePath"
+	conn, err := drv.GetConnection(dsn, basePath)
+	defer conn.Close()
+	if err != nil {
+		t.Fatal("unexpected error getting connection")
+	}
+
+	conn.CreateDir("/basePath")
+
+	//
+	// Test creating a new node and setting a non-existent node. Should not commit.
+	//
+	testNode0 := &testNodeT{
+		Name: "test0",
+	}
+	testNode1 := &testNodeT{
+		Name: "test1",
+	}
+
+	multi := conn.NewTransaction()
+	multi.Create("/test0", testNode0)
+	multi.Set("/test1", testNode1)
+	if err = multi.Commit(); err == nil {
+		t.Fatalf("creating /test0 and setting /test1 should have failed")
+	}
+
+	exists, err := conn.Exists("/test0")
+	if err != nil {
+		t.Fatalf("Error testing for existence of /test0: %s", err)
+	}
+	if exists {
+		t.Fatalf("/test0 should not have been created")
+	}
+
+	//
+	// Test creating two new nodes. Should commit.
+	//
+	multi = conn.NewTransaction()
+	multi.Create("/test0", testNode0)
+	multi.Create("/test1", testNode1)
+	if err = multi.Commit(); err != nil {
+		t.Fatalf("creating /test0 and /test1 should work: %s", err)
+	}
+
+	out := &testNodeT{
+		Name: "luffydmonkey",
+	}
+
+	err = conn.Get("/test0", out)
+	if err != nil {
+		t.Fatalf("getting /test0 should work: %s", err)
+	}
+
+	if out.Name != "test0" {
+		t.Fatalf("expected test0, got %s", out.Name)
+	}
+
+	err = conn.Get("/test1", out)
+	if err != nil {
+		t.Fatalf("getting /test1 should work: %s", err)
+	}
+
+	if out.Name != "test1" {
+		t.Fatalf("expected test1, got %s", out.Name)
+	}
+
+	//
+	// Test setting the newly created nodes. Should commit.
+	//
+	testNode0.Name = "test0b"
+	testNode1.Name = "test1b"
+
+	multi = conn.NewTransaction()
+	multi.Set("/test0", testNode0)
+	multi.Set("/test1", testNode1)
+	if err = multi.Commit(); err != nil {
+		t.Fatalf("setting test0 and test1 should work: %s", err)
+	}
+
+	out.Name = "luffydmonkey"
+
+	err = conn.Get("/test0", out)
+	if err != nil {
+		t.Fatalf("getting /test0 should work: %s", err)
+	}
+
+	if out.Name != "test0b" {
+		t.Fatalf("expected test0b, got %s", out.Name)
+	}
+
+	out.Name = "luffydmonkey"
+
+	err = conn.Get("/test1", out)
+	if err != nil {
+		t.Fatalf("getting /test1 should work: %s", err)
+	}
+
+	if out.Name != "test1b" {
+		t.Fatalf("expected test1b, got %s", out.Name)
+	}
+
+	//
+	// Attempt to delete the same node twice in the same transaction. Should not commit.
+	//
+	multi = conn.NewTransaction()
+	multi.Delete("/test0")
+	multi.Delete("/test0")
+	if err = multi.Commit(); err == nil {
+		t.Fatalf("expected error trying to delete the same node twice")
+	}
+
+	exists, err = conn.Exists("/test0")
+	if err != nil {
+		t.Fatalf("Error testing for existence of /test0: %s", err)
+	}
+	if !exists {
+		t.Fatalf("/test0 should not have been deleted")
+	}
+
+	//
+	// Attempt to delete two nodes in a transaction. Should commit.
+	//
+	multi = conn.NewTransaction()
+	multi.Delete("/test0")
+	multi.Delete("/test1")
+	if err = multi.Commit(); err != nil {
+		t.Fatalf("deleting /test0 and /test1 should work: %s", err)
+	}
+
+	exists, err = conn.Exists("/test0")
+	if err != nil {
+		t.Fatalf("Error testing for existence of /test0: %s", err)
+	}
+	if exists {
+		t.Fatalf("/test0 should have been deleted")
+	}
+
+	exists, err = conn.Exists("/test1")
+	if err != nil {
+		t.Fatalf("Error testing for existence of /test1: %s", err)
+	}
+	if exists {
+		t.Fatalf("/test1 should have been deleted")
+	}
+
+	//
+	// Attempt to create the same node twice in the same transaction. Should not commit.
+	//
+	multi = conn.NewTransaction()
+	multi.Create("/test0", testNode0)
+	multi.Create("/test0", testNode1)
+	if err = multi.Commit(); err == nil {
+		t.Fatalf("expected error trying to create an existing node")
+	}
+
+}
+
+func TestEtcdDriver_Ephemeral(t *testing.T) {
+	dsn, stop := startEtcd(t)
+	defer stop()
+
+	drv := Driver{}
+
+	basePath := "/basePath"
+	conn, err := drv.GetConnection(dsn, basePath)
+	defer conn.Close()
+	if err != nil {
+		t.Fatal("unexpected error getting connection")
+	}
+
+	node := &testNodeT{Name: "ephemeral"}
+	epath, err := conn.CreateEphemeral("/ephemeral", node)
+	if err != nil {
+		t.Fatalf("creating /ephemeral should work: %s", err)
+	}
+	// The returned is from the root, so it has to be trimmed down to the
+	// relative location
+	ename := "/" + path.Base(epath)
+
+	if ok, err := conn.Exists(ename); err != nil {
+		t.Fatalf("could not find path to ephemeral %s: %s", ename, err)
+	} else if !ok {
+		t.Fatalf("ephemeral %s not created", ename)
+	}
+
+	// Close connection and verify the node was deleted
+	conn.Close()
+	conn, err = drv.GetConnection(dsn, basePath)
+
+	if err != nil {
+		t.Fatal("unexpected error getting connection")
+	}
+
+	if ok, err := conn.Exists(ename); err != nil && err != coordclient.ErrNoNode {
+		t.Fatalf("should be able to check path %s: %s", ename, err)
+	} else if ok {
+		t.Errorf("ephemeral %s should have been deleted", ename)
+	}
+
+	// Adding and deleting
+	node = &testNodeT{Name: "ephemeral"}
+	epath, err = conn.CreateEphemeral("/ephemeral", node)
+	if err != nil {
+		t.Fatalf("creating /ephemeral should work: %s", err)
+	}
+	ename = "/" + path.Base(epath)
+
+	if ok, err := conn.Exists(ename); err != nil {
+		t.Fatalf("could not find path to ephemeral %s: %s", ename, err)
+	} else if !ok {
+		t.Fatalf("ephemeral %s not created", ename)
+	}
+	if err := conn.Delete(ename); err != nil {
+		t.Fatalf("could not delete path %s to ephemeral: %s", ename, err)
+	}
+
+	if ok, err := conn.Exists(ename); err != nil && err != coordclient.ErrNoNode {
+		t.Fatalf("should be able to check path %s: %s", ename, err)
+	} else if ok {
+		t.Errorf("ephemeral %s should have been deleted", ename)
+	}
+}
+
+func TestEtcdDriver_Watch(t *testing.T) {
+	dsn, stop := startEtcd(t)
+	defer stop()
+
+	drv := Driver{}
+	basePath := "/basePath"
+	conn, err := drv.GetConnection(dsn, basePath)
+	if err != nil {
+		t.Fatal("unexpected error getting connection")
+	}
+
+	err = conn.CreateDir("/foo")
+	if err != nil {
+		t.Fatalf("creating /foo should work: %s", err)
+	}
+	err = conn.Get("/foo", &testNodeT{})
+	if err != coordclient.ErrEmptyNode {
+		t.Fatalf("expected empty node, got %s", err)
+	}
+
+	childWDone1 := make(chan struct{})
+	defer close(childWDone1)
+	_, w1, err := conn.ChildrenW("/foo", childWDone1)
+	if err != nil {
+		t.Fatalf("should be able to acquire watch for /foo: %s", err)
+	}
+
+	childWDone2 := make(chan struct{})
+	defer close(childWDone2)
+	_, w2, err := conn.ChildrenW("/foo", childWDone2)
+	if err != nil {
+		t.Fatalf("should be able to acquire watch for /foo: %s", err)
+	}
+
+	go func() {
+		for w1 != nil || w2 != nil {
+			select {
+			case e := <-w1:
+				if e.Type != coordclient.EventNodeChildrenChanged {
+					t.Errorf("expected %v; actual: %v (w1)", coordclient.EventNodeChildrenChanged, e.Type)
+				}
+				w1 = nil
+			case e := <-w2:
+				if e.Type != coordclient.EventNodeChildrenChanged {
+					t.Errorf("expected %v; actual: %v (w2)", coordclient.EventNodeChildrenChanged, e.Type)
+				}
+				w2 = nil
+			}
+		}
+	}()
+
+	<-time.After(time.Second)
+	testNode := &testNodeT{
+		Name: "test",
+	}
+	err = conn.Create("/foo/bar", testNode)
+	if err != nil {
+		t.Fatalf("creating /foo/bar should work: %s", err)
+	}
+	t.Logf("testNode version: %v", testNode.Version())
+}
+
+func TestEtcdDriver_Version(t *testing.T) {
+	dsn, stop := startEtcd(t)
+	defer stop()
+
+	drv := Driver{}
+	conn, err := drv.GetConnection(dsn, "/basePath")
+	if err != nil {
+		t.Fatal("unexpected error getting connection")
+	}
+	defer conn.Close()
+
+	if err := conn.CreateIfExists("/missing/node", &testNodeT{Name: "a"}); err != coordclient.ErrNoNode {
+		t.Fatalf("expected %s, got %v", coordclient.ErrNoNode, err)
+	}
+	if err := conn.Create("/foo", &testNodeT{Name: "a"}); err != nil {
+		t.Fatalf("creating /foo should work: %s", err)
+	}
+	if err := conn.Create("/foo", &testNodeT{Name: "a"}); err != coordclient.ErrNodeExists {
+		t.Fatalf("expected %s, got %v", coordclient.ErrNodeExists, err)
+	}
+
+	// a node updated by another writer is a conflict
+	node1, node2 := &testNodeT{}, &testNodeT{}
+	if err := conn.Get("/foo", node1); err != nil {
+		t.Fatalf("getting /foo should work: %s", err)
+	}
+	if err := conn.Get("/foo", node2); err != nil {
+		t.Fatalf("getting /foo should work: %s", err)
+	}
+	node1.Name = "b"
+	if err := conn.Set("/foo", node1); err != nil {
+		t.Fatalf("setting /foo should work: %s", err)
+	}
+	node2.Name = "c"
+	if err := conn.Set("/foo", node2); err != coordclient.ErrBadVersion {
+		t.Fatalf("expected %s, got %v", coordclient.ErrBadVersion, err)
+	}
+	if err := conn.Set("/bar", &testNodeT{}); err != coordclient.ErrNoNode {
+		t.Fatalf("expected %s, got %v", coordclient.ErrNoNode, err)
+	}
+	if err := conn.Set("/foo", &testNodeT{Name: "d"}); err != coordclient.ErrBadVersion {
+		t.Fatalf("expected %s for a node without a version, got %v", coordclient.ErrBadVersion, err)
+	}
+
+	// ephemeral nodes may not have children
+	epath, err := conn.CreateEphemeral("/foo/eph", &testNodeT{Name: "e"})
+	if err != nil {
+		t.Fatalf("creating an ephemeral should work: %s", err)
+	}
+	if path.Base(epath) != "eph0000000001" {
+		t.Errorf("expected a sequential ephemeral, got %s", epath)
+	}
+	if err := conn.CreateDir("/foo/" + path.Base(epath) + "/child"); err != coordclient.ErrNoChildrenForEphemerals {
+		t.Fatalf("expected %s, got %v", coordclient.ErrNoChildrenForEphemerals, err)
+	}
+	children, err := conn.Children("/foo")
+	if err != nil || len(children) != 1 || children[0] != path.Base(epath) {
+		t.Fatalf("expected 1 child of /foo, got %v (%v)", children, err)
+	}
+	if _, err := conn.Children("/bar"); err != coordclient.ErrNoNode {
+		t.Fatalf("expected %s, got %v", coordclient.ErrNoNode, err)
+	}
+}
+
+func TestEtcdDriver_WatchEvents(t *testing.T) {
+	dsn, stop := startEtcd(t)
+	defer stop()
+
+	drv := Driver{}
+	conn, err := drv.GetConnection(dsn, "/basePath")
+	if err != nil {
+		t.Fatal("unexpected error getting connection")
+	}
+
+	expect := func(ch <-chan coordclient.Event, eventType coordclient.EventType) {
+		select {
+		case ev := <-ch:
+			if ev.Type != eventType {
+				t.Errorf("expected event %v, got %v", eventType, ev.Type)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for event %v", eventType)
+		}
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+
+	// node created
+	exists, ev, err := conn.ExistsW("/foo", done)
+	if err != nil || exists {
+		t.Fatalf("expected a watch on a missing node, got %v (%v)", exists, err)
+	}
+	node := &testNodeT{Name: "a"}
+	if err := conn.Create("/foo", node); err != nil {
+		t.Fatalf("creating /foo should work: %s", err)
+	}
+	expect(ev, coordclient.EventNodeCreated)
+
+	// node changed
+	if ev, err = conn.GetW("/foo", node, done); err != nil {
+		t.Fatalf("watching /foo should work: %s", err)
+	}
+	_, childEv, err := conn.ChildrenW("/foo", done)
+	if err != nil {
+		t.Fatalf("watching the children of /foo should work: %s", err)
+	}
+	node.Name = "b"
+	if err := conn.Set("/foo", node); err != nil {
+		t.Fatalf("setting /foo should work: %s", err)
+	}
+	expect(ev, coordclient.EventNodeDataChanged)
+
+	// children changed, but not for changes to the node or its grandchildren
+	if err := conn.CreateDir("/foobar"); err != nil {
+		t.Fatalf("creating /foobar should work: %s", err)
+	}
+	if err := conn.CreateDir("/foo/bar"); err != nil {
+		t.Fatalf("creating /foo/bar should work: %s", err)
+	}
+	expect(childEv, coordclient.EventNodeChildrenChanged)
+	if _, childEv, err = conn.ChildrenW("/foo", done); err != nil {
+		t.Fatalf("watching the children of /foo should work: %s", err)
+	}
+	if err := conn.CreateDir("/foo/bar/baz"); err != nil {
+		t.Fatalf("creating /foo/bar/baz should work: %s", err)
+	}
+	select {
+	case ev := <-childEv:
+		t.Fatalf("unexpected event %v", ev.Type)
+	case <-time.After(time.Second):
+	}
+
+	// node deleted
+	if ev, err = conn.GetW("/foo", node, done); err != nil {
+		t.Fatalf("watching /foo should work: %s", err)
+	}
+	if err := conn.Delete("/foo"); err != nil {
+		t.Fatalf("deleting /foo should work: %s", err)
+	}
+	expect(ev, coordclient.EventNodeDeleted)
+	expect(childEv, coordclient.EventNodeChildrenChanged)
+
+	// connection closed
+	if _, ev, err = conn.ExistsW("/foo", done); err != nil {
+		t.Fatalf("watching /foo should work: %s", err)
+	}
+	conn.Close()
+	expect(ev, coordclient.EventNotWatching)
+}
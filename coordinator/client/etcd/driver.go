@@ -0,0 +1,122 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package etcd implements the coordinator client on an etcd v3 cluster.
+// Nodes are keys named by their path, ephemeral nodes are attached to a lease
+// that is kept alive for as long as the connection is open, and the version of
+// a node is the version of its key.
+package etcd
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/control-center/serviced/coordinator/client"
+	"github.com/control-center/serviced/logging"
+)
+
+var (
+	plog = logging.PackageLogger() // the standard package logger
+)
+
+const (
+	// DefaultPrefix is the key prefix of the nodes of the coordinator
+	DefaultPrefix = "/serviced"
+	// minSessionTimeout is the shortest time to live that etcd grants a lease
+	minSessionTimeout = 5 * time.Second
+)
+
+// Driver implements an etcd based client.Driver interface
+type Driver struct{}
+
+// Assert that the etcd driver meets the Driver interface
+var _ client.Driver = &Driver{}
+
+func init() {
+	client.RegisterDriver("etcd", &Driver{})
+}
+
+// DSN is an etcd specific struct used for connections. It can be serialized.
+type DSN struct {
+	Endpoints      []string      // client URLs of the etcd members
+	SessionTimeout time.Duration // time to live of the ephemeral nodes of a connection
+	RequestTimeout time.Duration // time limit of each request to etcd
+	Prefix         string        // prefix of every key written by the coordinator
+}
+
+// NewDSN returns a new DSN object from endpoints and timeouts.
+func NewDSN(endpoints []string, sessionTimeout, requestTimeout time.Duration) DSN {
+	dsn := DSN{
+		Endpoints:      endpoints,
+		SessionTimeout: sessionTimeout,
+		RequestTimeout: requestTimeout,
+		Prefix:         DefaultPrefix,
+	}
+	if len(dsn.Endpoints) == 0 {
+		dsn.Endpoints = []string{"http://127.0.0.1:2379"}
+	}
+	return dsn
+}
+
+// String creates a parsable (JSON) string represenation of this DSN.
+func (dsn DSN) String() string {
+	bytes, err := json.Marshal(dsn)
+	if err != nil {
+		panic(err)
+	}
+	return string(bytes)
+}
+
+// ParseDSN decodes a string (JSON) represnation of a DSN object.
+func ParseDSN(dsn string) (val DSN, err error) {
+	err = json.Unmarshal([]byte(dsn), &val)
+	return val, err
+}
+
+// GetConnection returns an etcd connection given the dsn. The caller is
+// responsible for closing the returned connection.
+func (driver *Driver) GetConnection(dsn, basePath string) (client.Connection, error) {
+	dsnVal, err := ParseDSN(dsn)
+	if err != nil {
+		return nil, client.ErrInvalidDSN
+	}
+	if len(dsnVal.Endpoints) == 0 {
+		return nil, client.ErrInvalidDSN
+	}
+	if dsnVal.SessionTimeout < minSessionTimeout {
+		dsnVal.SessionTimeout = minSessionTimeout
+	}
+	if dsnVal.RequestTimeout <= 0 {
+		dsnVal.RequestTimeout = 10 * time.Second
+	}
+	if dsnVal.Prefix == "" {
+		dsnVal.Prefix = DefaultPrefix
+	}
+
+	conn := &Connection{
+		api:      newAPI(dsnVal.Endpoints, dsnVal.RequestTimeout),
+		prefix:   dsnVal.Prefix,
+		basePath: basePath,
+		ttl:      dsnVal.SessionTimeout,
+		closing:  make(chan struct{}),
+	}
+
+	// the session of the connection is a lease that holds its ephemerals
+	if conn.lease, err = conn.api.LeaseGrant(conn.ttl); err != nil {
+		plog.WithError(err).WithField("endpoints", dsnVal.Endpoints).Debug("Could not create etcd session")
+		return nil, err
+	}
+	plog.WithField("lease", conn.lease).Debug("etcd connection has session")
+	go conn.keepAlive()
+	return conn, nil
+}
@@ -0,0 +1,192 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcd
+
+import (
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/control-center/serviced/coordinator/client"
+)
+
+// Leader is an election among the connections that take the lead on a path.
+// Each candidate is an ephemeral sequential node under the path, and the
+// candidate with the lowest sequence number leads, as in the etcd election
+// recipe.
+type Leader struct {
+	conn     *Connection
+	path     string
+	lockPath string
+}
+
+// Current returns the currect elected leader and deserializes it in to node.
+// It will return ErrNoLeaderFound if no leader has been elected.
+func (l *Leader) Current(node client.Node) error {
+	l.conn.RLock()
+	defer l.conn.RUnlock()
+	if err := l.conn.isClosed(); err != nil {
+		return err
+	}
+	candidates, err := l.conn.candidates(l.path)
+	if err != nil {
+		return err
+	} else if len(candidates) == 0 {
+		return client.ErrNoLeaderFound
+	}
+	_, err = l.conn.get(path.Join(l.path, candidates[0].name), node)
+	return err
+}
+
+// TakeLead attempts to aquire the leader role. When aquired it returns a
+// channel on the leader node so the caller can react to changes in etcd
+func (l *Leader) TakeLead(node client.Node, cancel <-chan struct{}) (<-chan client.Event, error) {
+	if l.lockPath != "" {
+		return nil, client.ErrDeadlock
+	}
+	var err error
+	if l.lockPath, err = l.conn.enqueue(path.Join(l.path, "leader-"), node); err != nil {
+		return nil, err
+	}
+	for {
+		ok, ch, err := l.conn.waitTurn(l.path, l.lockPath, cancel)
+		if err != nil {
+			return nil, err
+		} else if ok {
+			return ch, nil
+		}
+		if ev := <-ch; ev.Err != nil {
+			return nil, ev.Err
+		}
+	}
+}
+
+// ReleaseLead release the current leader role. It will return ErrNotLocked if
+// the current object is not locked.
+func (l *Leader) ReleaseLead() error {
+	if l.lockPath == "" {
+		return client.ErrNotLocked
+	}
+	if err := l.conn.dequeue(l.lockPath); err != nil {
+		return err
+	}
+	l.lockPath = ""
+	return nil
+}
+
+// candidate is a sequential node in an election or lock queue
+type candidate struct {
+	name string
+	seq  uint64
+}
+
+type bySeq []candidate
+
+func (s bySeq) Len() int           { return len(s) }
+func (s bySeq) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s bySeq) Less(i, j int) bool { return s[i].seq < s[j].seq }
+
+// candidates returns the sequential nodes under the absolute path, sorted by
+// their sequence numbers.
+func (c *Connection) candidates(p string) ([]candidate, error) {
+	children, _, err := c.children(p)
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]candidate, 0, len(children))
+	for _, name := range children {
+		if seq, err := parseSeq(name); err == nil {
+			candidates = append(candidates, candidate{name, seq})
+		}
+	}
+	sort.Sort(bySeq(candidates))
+	return candidates, nil
+}
+
+// enqueue adds a candidate at the absolute path prefix and returns the path
+// of its node.
+func (c *Connection) enqueue(prefix string, node client.Node) (string, error) {
+	c.RLock()
+	defer c.RUnlock()
+	if err := c.isClosed(); err != nil {
+		return "", err
+	}
+	if err := c.ensurePath(prefix); err != nil {
+		return "", err
+	}
+	return c.createEphemeral(prefix, node)
+}
+
+// dequeue removes a candidate
+func (c *Connection) dequeue(lockPath string) error {
+	c.RLock()
+	defer c.RUnlock()
+	if err := c.isClosed(); err != nil {
+		return err
+	}
+	return c.delete(lockPath)
+}
+
+// waitTurn returns true and a watch on the candidate's node if it is first in
+// the queue at the absolute path.  Otherwise, it returns a watch on the
+// candidate ahead of it, which ends when that candidate changes or the
+// connection is closed.
+func (c *Connection) waitTurn(p, lockPath string, cancel <-chan struct{}) (bool, <-chan client.Event, error) {
+	c.RLock()
+	defer c.RUnlock()
+	if err := c.isClosed(); err != nil {
+		return false, nil, err
+	}
+	candidates, err := c.candidates(p)
+	if err != nil {
+		return false, nil, err
+	}
+
+	// find the candidate just ahead of this one
+	name := path.Base(lockPath)
+	found, ahead := false, ""
+	for _, cand := range candidates {
+		if cand.name == name {
+			found = true
+			break
+		}
+		ahead = cand.name
+	}
+	if !found {
+		// the candidate's node is gone with its session
+		return false, nil, client.ErrNoNode
+	} else if ahead == "" {
+		_, ch, err := c.existsW(lockPath, cancel)
+		return err == nil, ch, err
+	}
+
+	exists, ch, err := c.existsW(path.Join(p, ahead), nil)
+	if err != nil {
+		return false, nil, err
+	} else if !exists {
+		// it left before the watch was set, so look again
+		done := make(chan client.Event, 1)
+		done <- client.Event{Type: client.EventNodeDeleted}
+		return false, done, nil
+	}
+	return false, ch, nil
+}
+
+// parseSeq returns the sequence number at the end of the name of a node
+func parseSeq(p string) (uint64, error) {
+	name := path.Base(p)
+	i := strings.LastIndexFunc(name, func(r rune) bool { return r < '0' || r > '9' })
+	return strconv.ParseUint(name[i+1:], 10, 64)
+}
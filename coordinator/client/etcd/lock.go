@@ -0,0 +1,68 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcd
+
+import (
+	"path"
+
+	"github.com/control-center/serviced/coordinator/client"
+)
+
+// Lock creates a object to facilitate create a locking pattern in etcd.  Each
+// waiter is an ephemeral sequential node under the path, and the lock is held
+// by the waiter with the lowest sequence number.
+type Lock struct {
+	conn     *Connection
+	path     string
+	lockPath string
+}
+
+// Lock attempts to acquire the lock.
+func (l *Lock) Lock() error {
+	if l.lockPath != "" {
+		return client.ErrDeadlock
+	}
+	lockPath, err := l.conn.enqueue(path.Join(l.path, "lock-"), &client.Dir{})
+	if err != nil {
+		return err
+	}
+	cancel := make(chan struct{})
+	defer close(cancel)
+	for {
+		ok, ch, err := l.conn.waitTurn(l.path, lockPath, cancel)
+		if err != nil {
+			l.conn.dequeue(lockPath)
+			return err
+		} else if ok {
+			l.lockPath = lockPath
+			return nil
+		}
+		if ev := <-ch; ev.Err != nil {
+			l.conn.dequeue(lockPath)
+			return ev.Err
+		}
+	}
+}
+
+// Unlock attempts to release the lock.
+func (l *Lock) Unlock() error {
+	if l.lockPath == "" {
+		return client.ErrNotLocked
+	}
+	if err := l.conn.dequeue(l.lockPath); err != nil {
+		return err
+	}
+	l.lockPath = ""
+	return nil
+}
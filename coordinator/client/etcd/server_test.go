@@ -0,0 +1,93 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build integration
+
+package etcd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+// freePort returns a port that nothing is listening on
+func freePort(t *testing.T) int {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Could not find a free port: %s", err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+// startEtcd starts a single member etcd cluster from the etcd binary in the
+// PATH, or at $ETCD_BIN, with its data in a temporary directory.  It returns
+// the DSN of the cluster and a function that stops it.
+func startEtcd(t *testing.T) (string, func()) {
+	bin := os.Getenv("ETCD_BIN")
+	if bin == "" {
+		var err error
+		if bin, err = exec.LookPath("etcd"); err != nil {
+			t.Skip("etcd is not installed")
+		}
+	}
+	dir, err := ioutil.TempDir("", "etcd-test-")
+	if err != nil {
+		t.Fatalf("Could not create data directory: %s", err)
+	}
+
+	clientURL := fmt.Sprintf("http://127.0.0.1:%d", freePort(t))
+	peerURL := fmt.Sprintf("http://127.0.0.1:%d", freePort(t))
+	cmd := exec.Command(bin,
+		"--name", "test",
+		"--data-dir", dir,
+		"--listen-client-urls", clientURL,
+		"--advertise-client-urls", clientURL,
+		"--listen-peer-urls", peerURL,
+		"--initial-advertise-peer-urls", peerURL,
+		"--initial-cluster", "test="+peerURL,
+	)
+	if err := cmd.Start(); err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("Could not start etcd: %s", err)
+	}
+	stop := func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+		os.RemoveAll(dir)
+	}
+
+	// wait for the cluster to elect itself
+	timeout := time.After(15 * time.Second)
+	for {
+		if resp, err := http.Get(clientURL + "/health"); err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				break
+			}
+		}
+		select {
+		case <-timeout:
+			stop()
+			t.Fatalf("etcd did not start")
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	return NewDSN([]string{clientURL}, 5*time.Second, 5*time.Second).String(), stop
+}
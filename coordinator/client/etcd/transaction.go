@@ -0,0 +1,152 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcd
+
+import (
+	"encoding/json"
+	"path"
+
+	"github.com/control-center/serviced/coordinator/client"
+)
+
+const (
+	multiCreate int = iota
+	multiSet
+	multiDelete
+)
+
+type multiReq struct {
+	Type int
+	Path string
+	Node client.Node
+}
+
+// Transaction commits a set of operations in a single etcd transaction
+type Transaction struct {
+	conn *Connection
+	ops  []multiReq
+}
+
+func (t *Transaction) Create(path string, node client.Node) client.Transaction {
+	t.ops = append(t.ops, multiReq{multiCreate, path, node})
+	return t
+}
+
+func (t *Transaction) Set(path string, node client.Node) client.Transaction {
+	t.ops = append(t.ops, multiReq{multiSet, path, node})
+	return t
+}
+
+func (t *Transaction) Delete(path string) client.Transaction {
+	t.ops = append(t.ops, multiReq{multiDelete, path, nil})
+	return t
+}
+
+// Commit applies every operation or none of them.  The conditions of each
+// operation are checked against the state before the transaction, so a node
+// may be created under a parent that is created earlier in the same
+// transaction, but the same node may not be created or deleted twice.
+func (t *Transaction) Commit() error {
+	t.conn.RLock()
+	defer t.conn.RUnlock()
+	if err := t.conn.isClosed(); err != nil {
+		return err
+	}
+	c := t.conn
+
+	req := &txnRequest{}
+	created := make(map[string]bool)
+	touched := make(map[string]bool)
+	for _, op := range t.ops {
+		p := c.abs(op.Path)
+		key := c.key(p)
+		logger := plog.WithField("path", p)
+		if touched[p] && op.Type != multiSet {
+			logger.Debug("Node is created or deleted twice in the same transaction")
+			return client.ErrBadVersion
+		}
+		touched[p] = true
+
+		switch op.Type {
+		case multiCreate:
+			data, err := json.Marshal(op.Node)
+			if err != nil {
+				logger.WithError(err).WithField("node", op.Node).Error("Could not serialize node at path")
+				return client.ErrSerialization
+			}
+			if dp := path.Dir(p); !created[dp] {
+				req.Compare = append(req.Compare, c.parentCompares(dp)...)
+			}
+			req.Compare = append(req.Compare, versionIs(key, 0))
+			req.Success = append(req.Success, requestOp{RequestPut: &putRequest{Key: key, Value: data}})
+			created[p] = true
+		case multiSet:
+			cmp, put, err := c.setOp(p, op.Node)
+			if err == client.ErrSerialization {
+				logger.WithError(err).WithField("node", op.Node).Error("Could not serialize node at path")
+				return err
+			} else if err != nil {
+				logger.WithError(err).WithField("node", op.Node).Error("Could not parse version of node at path")
+				return err
+			}
+			if !created[p] {
+				req.Compare = append(req.Compare, cmp)
+			}
+			req.Success = append(req.Success, put)
+		case multiDelete:
+			req.Compare = append(req.Compare, keyExists(key))
+			req.Success = append(req.Success, c.deleteOps(p)...)
+		}
+	}
+
+	resp, err := c.api.Txn(req)
+	if err != nil {
+		return err
+	} else if !resp.Succeeded {
+		return t.explain()
+	}
+	for _, op := range t.ops {
+		if op.Type == multiCreate {
+			op.Node.SetVersion(&Stat{Version: 1, CreateRevision: resp.Header.Revision, ModRevision: resp.Header.Revision})
+		}
+	}
+	return nil
+}
+
+// explain returns the error of the first operation that could not be applied
+func (t *Transaction) explain() error {
+	c := t.conn
+	for _, op := range t.ops {
+		p := c.abs(op.Path)
+		switch op.Type {
+		case multiCreate:
+			if kv, err := c.getKV(p); err != nil {
+				return err
+			} else if kv != nil {
+				return client.ErrNodeExists
+			}
+		case multiSet:
+			if err := c.setError(p); err == client.ErrNoNode {
+				return err
+			}
+		case multiDelete:
+			if exists, err := c.exists(p); err != nil {
+				return err
+			} else if !exists {
+				return client.ErrNoNode
+			}
+		}
+	}
+	return client.ErrBadVersion
+}
@@ -0,0 +1,134 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcd
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/control-center/serviced/coordinator/client"
+)
+
+// eventDelete is the type of a watch event for a deleted key.  Events for
+// updated keys have no type.
+const eventDelete = "DELETE"
+
+// ErrWatchCanceled is sent when etcd cancels a watch, usually because its
+// revision was compacted.
+var ErrWatchCanceled = errors.New("etcd: watch canceled")
+
+type watchCreateRequest struct {
+	Key           []byte `json:"key"`
+	RangeEnd      []byte `json:"range_end,omitempty"`
+	StartRevision int64  `json:"start_revision,string,omitempty"`
+}
+
+type watchRequest struct {
+	CreateRequest *watchCreateRequest `json:"create_request"`
+}
+
+type watchEvent struct {
+	Type string   `json:"type"`
+	Kv   keyValue `json:"kv"`
+}
+
+// nodeEvent returns the type of the coordinator event for a watched node
+func (ev *watchEvent) nodeEvent() client.EventType {
+	if ev.Type == eventDelete {
+		return client.EventNodeDeleted
+	} else if ev.Kv.Version == 1 {
+		return client.EventNodeCreated
+	}
+	return client.EventNodeDataChanged
+}
+
+type watchResponse struct {
+	Created         bool         `json:"created"`
+	Canceled        bool         `json:"canceled"`
+	CompactRevision int64        `json:"compact_revision,string,omitempty"`
+	Events          []watchEvent `json:"events"`
+}
+
+// watch watches the keys from key to rangeEnd, starting at a revision, and
+// sends a single event for the first change that match accepts.  The watch
+// ends when cancel or the connection is closed.
+func (c *Connection) watch(key, rangeEnd []byte, rev int64, cancel <-chan struct{}, match func(*watchEvent) (client.EventType, bool)) <-chan client.Event {
+	evCh := make(chan client.Event, 1)
+	api, closing := c.api, c.closing
+
+	// stop the request when the watch is canceled or the connection closes
+	stop, done := make(chan struct{}), make(chan struct{})
+	go func() {
+		select {
+		case <-cancel:
+		case <-closing:
+		case <-done:
+		}
+		close(stop)
+	}()
+
+	send := func(ev client.Event) {
+		select {
+		case evCh <- ev:
+		case <-cancel:
+		}
+	}
+
+	go func() {
+		defer close(done)
+		logger := plog.WithField("key", string(key))
+		body, err := api.post(api.streams, "/v3/watch", &watchRequest{
+			CreateRequest: &watchCreateRequest{Key: key, RangeEnd: rangeEnd, StartRevision: rev},
+		}, stop)
+		if err == nil {
+			defer body.Close()
+			decoder := json.NewDecoder(body)
+			for {
+				msg := &streamResponse{}
+				if err = decoder.Decode(msg); err != nil {
+					break
+				} else if msg.Error != nil {
+					err = msg.Error
+					break
+				}
+				resp := &watchResponse{}
+				if err = json.Unmarshal(msg.Result, resp); err != nil {
+					break
+				}
+				for i := range resp.Events {
+					if t, ok := match(&resp.Events[i]); ok {
+						send(client.Event{Type: t})
+						return
+					}
+				}
+				if resp.Canceled {
+					logger.WithField("compactrevision", resp.CompactRevision).Debug("etcd canceled watch")
+					err = ErrWatchCanceled
+					break
+				}
+			}
+		}
+
+		select {
+		case <-cancel:
+			return
+		case <-closing:
+			err = client.ErrConnectionClosed
+		default:
+			logger.WithError(err).Debug("Lost etcd watch")
+		}
+		send(client.Event{Type: client.EventNotWatching, Err: err})
+	}()
+	return evCh
+}
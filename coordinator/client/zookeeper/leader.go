@@ -15,7 +15,6 @@ package zookeeper
 
 import (
 	"encoding/json"
-	"errors"
 	"math"
 	"path"
 	"strconv"
@@ -27,14 +26,14 @@ import (
 
 var (
 	// ErrDeadlock is returned when a lock is aquired twice on the same object.
-	ErrDeadlock = errors.New("zk: trying to acquire a lock twice")
+	ErrDeadlock = client.ErrDeadlock
 
 	// ErrNotLocked is returned when a caller attempts to release a lock that
 	// has not been aquired
-	ErrNotLocked = errors.New("zk: not locked")
+	ErrNotLocked = client.ErrNotLocked
 
 	// ErrNoLeaderFound is returned when a leader has not been elected
-	ErrNoLeaderFound = errors.New("zk: no leader found")
+	ErrNoLeaderFound = client.ErrNoLeaderFound
 )
 
 // Leader is an object to facilitate creating an election in zookeeper.
@@ -19,7 +19,6 @@ import (
 	"time"
 
 	"github.com/control-center/serviced/coordinator/client"
-	"github.com/control-center/serviced/coordinator/client/zookeeper"
 	"github.com/control-center/serviced/domain/host"
 	"github.com/control-center/serviced/logging"
 )
@@ -92,7 +91,7 @@ func (s *Server) Run(shutdown <-chan interface{}, conn client.Connection) error
 	leaderDone := make(chan struct{})
 	defer close(leaderDone)
 	leaderW, err := leader.TakeLead(node, leaderDone)
-	if err != zookeeper.ErrDeadlock && err != nil {
+	if err != client.ErrDeadlock && err != nil {
 		plog.WithError(err).Error("Could not take storage lead")
 		return err
 	}
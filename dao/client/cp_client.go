@@ -221,6 +221,10 @@ func (s *ControlClient) ListBackups(dirpath string, files *[]dao.BackupFile) (er
 	return s.rpcClient.Call("ControlCenter.ListBackups", dirpath, files, 0)
 }
 
+func (s *ControlClient) PruneBackups(request dao.PruneBackupsRequest, removed *[]string) (err error) {
+	return s.rpcClient.Call("ControlCenter.PruneBackups", request, removed, 0)
+}
+
 func (s *ControlClient) BackupStatus(req dao.EntityRequest, status *string) (err error) {
 	return s.rpcClient.Call("ControlCenter.BackupStatus", req, status, 0)
 }
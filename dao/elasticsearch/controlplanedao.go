@@ -35,6 +35,8 @@ import (
 
 	"github.com/control-center/serviced/dao"
 	"github.com/control-center/serviced/datastore"
+	"github.com/control-center/serviced/dfs/archive"
+	"github.com/control-center/serviced/dfs/target"
 	"github.com/control-center/serviced/domain/service"
 	"github.com/control-center/serviced/facade"
 	"github.com/control-center/serviced/metrics"
@@ -57,7 +59,9 @@ type ControlPlaneDao struct {
 	rpcPort      int
 	facade       *facade.Facade
 	metricClient *metrics.Client
-	backupsPath  string
+	backups      target.Target
+	s3config     target.S3Config
+	archive      *archive.Config
 }
 
 func serviceGetter(ctx datastore.Context, f *facade.Facade) service.GetService {
@@ -82,6 +86,12 @@ func childFinder(ctx datastore.Context, f *facade.Facade) service.FindChildServi
 	}
 }
 
+func secretGetter(ctx datastore.Context, f *facade.Facade) service.GetSecret {
+	return func(name string) (string, error) {
+		return f.GetSecretValue(ctx, name)
+	}
+}
+
 func (this *ControlPlaneDao) Action(request dao.AttachRequest, unused *int) error {
 	ctx := datastore.Get()
 	svc, err := this.facade.GetService(ctx, request.Running.ServiceID)
@@ -94,7 +104,7 @@ func (this *ControlPlaneDao) Action(request dao.AttachRequest, unused *int) erro
 		return fmt.Errorf("missing command")
 	}
 
-	if err := svc.EvaluateActionsTemplate(serviceGetter(ctx, this.facade), childFinder(ctx, this.facade), request.Running.InstanceID); err != nil {
+	if err := svc.EvaluateActionsTemplate(serviceGetter(ctx, this.facade), childFinder(ctx, this.facade), secretGetter(ctx, this.facade), request.Running.InstanceID); err != nil {
 		return err
 	}
 
@@ -134,7 +144,7 @@ func NewControlPlaneDao(hostName string, port int, rpcPort int) (*ControlPlaneDa
 	return dao, nil
 }
 
-func NewControlSvc(hostName string, port int, facade *facade.Facade, backupsPath string, rpcPort int) (*ControlPlaneDao, error) {
+func NewControlSvc(hostName string, port int, facade *facade.Facade, backupsPath string, s3config target.S3Config, archiveConfig *archive.Config, rpcPort int) (*ControlPlaneDao, error) {
 	glog.V(2).Info("calling NewControlSvc()")
 	defer glog.V(2).Info("leaving NewControlSvc()")
 
@@ -142,7 +152,12 @@ func NewControlSvc(hostName string, port int, facade *facade.Facade, backupsPath
 	if err != nil {
 		return nil, err
 	}
-	s.backupsPath = backupsPath
+	// backups may be stored locally or in an S3-compatible object store
+	if s.backups, err = target.New(backupsPath, s3config); err != nil {
+		return nil, err
+	}
+	s.s3config = s3config
+	s.archive = archiveConfig
 
 	//Used to bridge old to new
 	s.facade = facade
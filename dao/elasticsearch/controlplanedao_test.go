@@ -30,6 +30,7 @@ import (
 	coordzk "github.com/control-center/serviced/coordinator/client/zookeeper"
 	"github.com/control-center/serviced/dao"
 	"github.com/control-center/serviced/datastore"
+	"github.com/control-center/serviced/dfs/target"
 	"github.com/control-center/serviced/domain/addressassignment"
 	"github.com/control-center/serviced/domain/host"
 	"github.com/control-center/serviced/domain/pool"
@@ -149,7 +150,7 @@ func (dt *DaoTest) SetUpSuite(c *C) {
 	err = volume.InitDriver(volume.DriverTypeRsync, tmpdir, []string{})
 	c.Assert(err, IsNil)
 
-	dt.Dao, err = NewControlSvc("localhost", int(dt.Port), dt.Facade, "", 4979)
+	dt.Dao, err = NewControlSvc("localhost", int(dt.Port), dt.Facade, "", target.S3Config{}, nil, 4979)
 	if err != nil {
 		glog.Fatalf("Could not start es container: %s", err)
 	} else {
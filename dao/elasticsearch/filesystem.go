@@ -15,9 +15,10 @@ package elasticsearch
 
 import (
 	"fmt"
-	"os"
-
+	"io"
+	"path"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -26,9 +27,11 @@ import (
 	model "github.com/control-center/serviced/dao"
 	"github.com/control-center/serviced/datastore"
 	"github.com/control-center/serviced/dfs"
+	"github.com/control-center/serviced/dfs/archive"
+	"github.com/control-center/serviced/dfs/target"
 	"github.com/control-center/serviced/logging"
 	"github.com/control-center/serviced/volume"
-	gzip "github.com/klauspost/pgzip"
+	"github.com/dustin/go-humanize"
 )
 
 var (
@@ -89,6 +92,30 @@ func (p *InProgress) GetProgress() (bool, string, string, error) {
 
 var inprogress = &InProgress{locker: &sync.RWMutex{}}
 
+// backupTarget returns the target for a backup location.  An empty location
+// refers to the default backup target.
+func (dao *ControlPlaneDao) backupTarget(location string) (target.Target, error) {
+	if location == "" {
+		return dao.backups, nil
+	}
+	return target.New(location, dao.s3config)
+}
+
+// resolveBackup returns the target and name of a backup from its location,
+// which is either the full location of a backup or the name of a backup in
+// the default target.
+func (dao *ControlPlaneDao) resolveBackup(location string) (target.Target, string, error) {
+	if strings.HasPrefix(location, "s3://") || filepath.IsAbs(location) {
+		dir, name, err := target.Split(location)
+		if err != nil {
+			return nil, "", err
+		}
+		t, err := dao.backupTarget(dir)
+		return t, name, err
+	}
+	return dao.backups, location, nil
+}
+
 // Backup takes a backup of the full application stack and returns the filename
 // that it is written to.
 func (dao *ControlPlaneDao) Backup(backupRequest model.BackupRequest, filename *string) (err error) {
@@ -101,13 +128,17 @@ func (dao *ControlPlaneDao) Backup(backupRequest model.BackupRequest, filename *
 	dfslocker.Lock("backup")
 	defer dfslocker.Unlock()
 
-	if backupRequest.Dirpath == "" {
-		backupRequest.Dirpath = dao.backupsPath
+	t, err := dao.backupTarget(backupRequest.Dirpath)
+	if err != nil {
+		log.WithError(err).WithField("dirpath", backupRequest.Dirpath).Error("Could not find backup target")
+		return
 	}
-	// CC-2421: Check for space before doing backup
+
+	// CC-2421: Check for space before doing backup.  Space can only be
+	// estimated for backups on the local filesystem, and backups to an
+	// object store are limited by the number of parts of an upload.
 	est := model.BackupEstimate{}
-	err = dao.facade.EstimateBackup(ctx, backupRequest, &est)
-	if err != nil {
+	if err = dao.estimateBackup(ctx, t, backupRequest, &est); err != nil {
 		log.WithError(err).Error("Could not estimate backup size")
 		return
 	} else if !est.AllowBackup {
@@ -120,53 +151,79 @@ func (dao *ControlPlaneDao) Backup(backupRequest model.BackupRequest, filename *
 			estlog.Warning("Backup not recommended, but proceeding because '-force' was specified")
 		} else {
 			message := fmt.Sprintf("Could not take backup - insufficient space on %s (%s estimated backup size, %s available)", est.BackupPath, est.EstimatedString, est.AvailableString)
+			if _, ok := t.(*target.S3); ok {
+				message = fmt.Sprintf("Could not take backup - %s estimated backup size exceeds the %s that can be uploaded to %s; increase the S3 part size", est.EstimatedString, est.AvailableString, est.BackupPath)
+			}
 			err := errors.New(message)
 			estlog.WithError(err).Error("Could not take backup")
 			return err
 		}
 	}
 
+	// find the parent of an incremental backup, which must be stored in the
+	// same target
+	var (
+		parentname string
+		parent     *dfs.BackupInfo
+	)
+	if backupRequest.Incremental != "" {
+		parentname = path.Base(backupRequest.Incremental)
+		if parent, err = dfs.ReadBackupFileInfo(t, parentname, dao.archive); err != nil {
+			log.WithError(err).WithField("parent", t.Location(parentname)).Error("Could not read parent backup")
+			return
+		}
+	}
+
 	// set the progress of the backup file
-	*filename = time.Now().UTC().Format("backup-2006-01-02-150405.tgz")
-	backupfilename := filepath.Join(backupRequest.Dirpath, *filename)
+	*filename = time.Now().UTC().Format("backup-2006-01-02-150405.") + dao.archive.Extension()
+	backupfilename := t.Location(*filename)
 
 	inprogress.SetProgress(backupfilename, "backup")
 	defer func() {
 		if err != nil {
 			log.WithError(err).Error("Backup failed with error")
-			os.Remove(backupfilename)
 		}
 		inprogress.SetError(err)
 	}()
 	// create the file and write
-	fh, err := os.Create(backupfilename)
+	fh, err := t.Create(*filename)
 	if err != nil {
 		log.WithError(err).WithField("backupfilename", backupfilename).Error("Could not create backup file")
 		return
 	}
-	defer fh.Close()
-	w := gzip.NewWriter(fh)
-	// CC-2292: Limit concurrency of backup gzipping
-	// This setting will cause the writer to process up to 2 100KB blocks
-	// at a time before the writer blocks. The default was 16 250KB blocks.
-	// Smaller blocks will allow other goroutines to get time more frequently.
-	w.SetConcurrency(100000, 2)
-	defer w.Close()
-	err = dao.facade.Backup(ctx, w, backupRequest.Excludes, backupRequest.SnapshotSpacePercent, backupfilename)
+	// compress and optionally encrypt the backup
+	w, err := archive.NewWriter(fh, dao.archive)
+	if err != nil {
+		log.WithError(err).WithField("backupfilename", backupfilename).Error("Could not write backup archive")
+		fh.Abort()
+		return
+	}
+	if err = dao.facade.Backup(ctx, w, backupRequest.Excludes, backupRequest.SnapshotSpacePercent, backupfilename, parentname, parent); err != nil {
+		w.Close()
+		fh.Abort()
+		return
+	}
+	if err = w.Close(); err != nil {
+		fh.Abort()
+		return
+	}
+	err = fh.Close()
 	return
 }
 
 func (dao *ControlPlaneDao) GetBackupEstimate(backupRequest model.BackupRequest, backupEstimate *model.BackupEstimate) (err error) {
 	ctx := datastore.Get()
 	start := time.Now()
-	if backupRequest.Dirpath == "" {
-		log.WithField("backupspath", dao.backupsPath).Info("Dirpath was empty. Using value from dao.BackupsPath")
-		backupRequest.Dirpath = dao.backupsPath
-	}
-	err = dao.facade.EstimateBackup(ctx, backupRequest, backupEstimate)
+	t, err := dao.backupTarget(backupRequest.Dirpath)
 	if err != nil {
 		return err
 	}
+	if local, ok := t.(*target.Local); ok && backupRequest.Dirpath == "" {
+		log.WithField("backupspath", local.Dir).Info("Dirpath was empty. Using value from dao.BackupsPath")
+	}
+	if err = dao.estimateBackup(ctx, t, backupRequest, backupEstimate); err != nil {
+		return err
+	}
 	log.WithFields(logrus.Fields{
 		"elapsed":  time.Since(start),
 		"estimate": backupEstimate,
@@ -175,6 +232,30 @@ func (dao *ControlPlaneDao) GetBackupEstimate(backupRequest model.BackupRequest,
 	return nil
 }
 
+// estimateBackup estimates the size of a backup and whether it fits in the
+// target.  A local target is limited by the free space on its filesystem and
+// an object store by the largest upload for its part size.
+func (dao *ControlPlaneDao) estimateBackup(ctx datastore.Context, t target.Target, backupRequest model.BackupRequest, backupEstimate *model.BackupEstimate) error {
+	switch t := t.(type) {
+	case *target.Local:
+		backupRequest.Dirpath = t.Dir
+		return dao.facade.EstimateBackup(ctx, backupRequest, backupEstimate)
+	case *target.S3:
+		if err := dao.facade.EstimateBackup(ctx, backupRequest, backupEstimate); err != nil {
+			return err
+		}
+		backupEstimate.BackupPath = t.Location("")
+		backupEstimate.AvailableBytes = uint64(t.MaxSize())
+		backupEstimate.AvailableString = humanize.Bytes(backupEstimate.AvailableBytes)
+		backupEstimate.AllowBackup = backupEstimate.EstimatedBytes < backupEstimate.AvailableBytes
+		return nil
+	default:
+		backupEstimate.BackupPath = t.Location("")
+		backupEstimate.AllowBackup = true
+		return nil
+	}
+}
+
 // AsyncBackup is the same as backup, but asynchronous
 func (dao *ControlPlaneDao) AsyncBackup(backupRequest model.BackupRequest, filename *string) (err error) {
 	ctx := datastore.Get()
@@ -205,22 +286,54 @@ func (dao *ControlPlaneDao) Restore(restoreRequest model.RestoreRequest, _ *int)
 		}
 		inprogress.SetError(err)
 	}()
-	info, err := dfs.ExtractBackupInfo(restoreRequest.Filename)
+	t, name, err := dao.resolveBackup(restoreRequest.Filename)
 	if err != nil {
 		return err
 	}
-	fh, err := os.Open(restoreRequest.Filename)
+	names, infos, err := dfs.BackupChain(t, name, dao.archive)
 	if err != nil {
 		return err
 	}
-	defer fh.Close()
-	gz, err := gzip.NewReader(fh)
+	// replay the parents of an incremental backup, oldest first
+	last := len(names) - 1
+	for i := 0; i < last; i++ {
+		log.WithField("parent", t.Location(names[i])).Info("Restoring parent backup")
+		if err = restoreBackupFile(t, names[i], dao.archive, func(r io.Reader) error {
+			return dao.facade.RestoreParent(ctx, r, infos[i])
+		}); err != nil {
+			return err
+		}
+	}
+	if err = restoreBackupFile(t, names[last], dao.archive, func(r io.Reader) error {
+		return dao.facade.Restore(ctx, r, infos[last], restoreRequest.Filename)
+	}); err != nil {
+		return err
+	}
+	// the snapshots of the parents are no longer needed
+	for _, info := range infos[:last] {
+		for _, snapshot := range info.Snapshots {
+			if err := dao.facade.DeleteSnapshot(ctx, snapshot); err != nil {
+				log.WithError(err).WithField("snapshot", snapshot).Warn("Could not delete snapshot of parent backup")
+			}
+		}
+	}
+	return nil
+}
+
+// restoreBackupFile opens a backup for restore, decompressing and decrypting
+// it as needed.
+func restoreBackupFile(t target.Target, name string, cfg *archive.Config, restore func(io.Reader) error) error {
+	r, err := t.Open(name)
 	if err != nil {
 		return err
 	}
-	defer gz.Close()
-	err = dao.facade.Restore(ctx, gz, info, restoreRequest.Filename)
-	return err
+	defer r.Close()
+	ar, err := archive.NewReader(r, cfg)
+	if err != nil {
+		return err
+	}
+	defer ar.Close()
+	return restore(ar)
 }
 
 // AsyncRestore is the same as restore, but asynchronous.
@@ -241,17 +354,11 @@ func (dao *ControlPlaneDao) AsyncRestore(restoreRequest model.RestoreRequest, un
 func (dao *ControlPlaneDao) ListBackups(dirpath string, files *[]model.BackupFile) (err error) {
 	*files = []model.BackupFile{}
 
-	// Read the contents of the directory
-	if dirpath == "" {
-		dirpath = dao.backupsPath
-	}
-	dir, err := os.Open(dirpath)
+	t, err := dao.backupTarget(dirpath)
 	if err != nil {
 		return err
 	}
-	defer dir.Close()
-
-	fis, err := dir.Readdir(0)
+	fis, err := t.List()
 	if err != nil {
 		return err
 	}
@@ -259,44 +366,61 @@ func (dao *ControlPlaneDao) ListBackups(dirpath string, files *[]model.BackupFil
 	running, fp, _, _ := inprogress.GetProgress()
 
 	for _, fi := range fis {
-		if !fi.IsDir() {
-			// Set up the backup file
-			fullpath := filepath.Join(dirpath, fi.Name())
-			bf := model.BackupFile{
-				InProgress: running && fullpath == fp,
-				FullPath:   fullpath,
-				Name:       fi.Name(),
-				Size:       fi.Size(),
-				Mode:       fi.Mode(),
-				ModTime:    fi.ModTime(),
-			}
-			// If it is not running, make sure the backup is legit
-			if !bf.InProgress {
-				isbackup := func(filename string) bool {
-					fh, err := os.Open(fullpath)
-					if err != nil {
-						return false
-					}
-					defer fh.Close()
-					gz, err := gzip.NewReader(fh)
-					if err != nil {
-						return false
-					}
-					defer gz.Close()
-					_, err = dao.facade.BackupInfo(datastore.Get(), gz)
-					if err != nil {
-						return false
-					}
-					return true
-				}(fullpath)
-				if !isbackup {
-					continue
-				}
+		// Set up the backup file
+		fullpath := t.Location(fi.Name)
+		bf := model.BackupFile{
+			InProgress: running && fullpath == fp,
+			FullPath:   fullpath,
+			Name:       fi.Name,
+			Size:       fi.Size,
+			Mode:       fi.Mode,
+			ModTime:    fi.ModTime,
+		}
+		// If it is not running, make sure the backup is legit
+		if !bf.InProgress {
+			// backups that are encrypted for someone else are still listed
+			if _, err := dfs.ReadBackupFileInfo(t, fi.Name, dao.archive); err != nil && err != archive.ErrNoKey {
+				continue
 			}
-			// Add the file to the list
-			*files = append(*files, bf)
 		}
+		// Add the file to the list
+		*files = append(*files, bf)
+	}
+	return
+}
+
+// PruneBackups removes all but the newest backups from a backup location
+func (dao *ControlPlaneDao) PruneBackups(request model.PruneBackupsRequest, removed *[]string) (err error) {
+	ctx := datastore.Get()
+	if len(request.Username) > 0 {
+		ctx.SetUser(request.Username)
+	}
+	if request.Keep < 1 {
+		return errors.New("at least one backup must be kept")
+	}
+	t, err := dao.backupTarget(request.Dirpath)
+	if err != nil {
+		return err
+	}
+	// don't prune while a backup is being written or restored
+	dfslocker := dao.facade.DFSLock(ctx)
+	dfslocker.Lock("prune backups")
+	defer dfslocker.Unlock()
+	// only prune files that are backups, as ListBackups does; backups that
+	// are encrypted for someone else are still backups
+	isBackup := func(name string) bool {
+		_, err := dfs.ReadBackupFileInfo(t, name, dao.archive)
+		return err == nil || err == archive.ErrNoKey
+	}
+	// keep the parents of incremental backups that are kept
+	parents := func(name string) []string {
+		names, _, err := dfs.BackupChain(t, name, dao.archive)
+		if err != nil {
+			return nil
+		}
+		return names
 	}
+	*removed, err = target.Prune(t, request.Keep, isBackup, parents)
 	return
 }
 
@@ -333,6 +457,8 @@ func (dao *ControlPlaneDao) Snapshot(req model.SnapshotRequest, snapshotID *stri
 
 	if req.ContainerID != "" {
 		*snapshotID, err = dao.facade.Commit(ctx, req.ContainerID, req.Message, tagList, req.SnapshotSpacePercent)
+	} else if req.SkipQuiesce {
+		*snapshotID, err = dao.facade.SnapshotLive(ctx, req.ServiceID, req.Message, tagList, req.SnapshotSpacePercent)
 	} else {
 		*snapshotID, err = dao.facade.Snapshot(ctx, req.ServiceID, req.Message, tagList, req.SnapshotSpacePercent)
 	}
@@ -106,6 +106,7 @@ type SnapshotRequest struct {
 	Tag                  string
 	ContainerID          string
 	SnapshotSpacePercent int
+	SkipQuiesce          bool // do not pause the services of the application
 }
 
 type TagSnapshotRequest struct {
@@ -254,6 +255,9 @@ type ControlPlane interface {
 	// ListBackups returns the list of backups
 	ListBackups(dirpath string, files *[]BackupFile) (err error)
 
+	// PruneBackups removes all but the newest backups
+	PruneBackups(request PruneBackupsRequest, removed *[]string) (err error)
+
 	// BackupStatus returns the current status of a running backup or restore
 	BackupStatus(unused EntityRequest, status *string) (err error)
 
@@ -431,6 +431,18 @@ func (_m *ControlPlane) ListBackups(dirpath string, files *[]dao.BackupFile) err
 
 	return r0
 }
+func (_m *ControlPlane) PruneBackups(request dao.PruneBackupsRequest, removed *[]string) error {
+	ret := _m.Called(request, removed)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(dao.PruneBackupsRequest, *[]string) error); ok {
+		r0 = rf(request, removed)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
 func (_m *ControlPlane) BackupStatus(unused dao.EntityRequest, status *string) error {
 	ret := _m.Called(unused, status)
 
@@ -127,6 +127,13 @@ type BackupRequest struct {
 	Excludes             []string
 	Force                bool
 	Username             string
+	Incremental          string // file name of the parent backup, if any
+}
+
+type PruneBackupsRequest struct {
+	Dirpath  string
+	Keep     int
+	Username string
 }
 
 type RestoreRequest struct {
@@ -58,7 +58,7 @@ func (ec *elasticConnection) Put(key datastore.Key, msg datastore.JSONMessage) e
 		logger.WithError(err).Error("Put failed")
 		if eserr, iseserror := err.(api.ESError); iseserror && eserr.Code == 409 {
 			// Conflict
-			return fmt.Errorf("Your changes conflict with those made by another user. Please reload and try your changes again.")
+			return datastore.ErrConflict
 		}
 		return err
 	}
@@ -112,6 +112,13 @@ func (ec *elasticConnection) Delete(key datastore.Key) error {
 }
 
 func (ec *elasticConnection) Query(query interface{}) ([]datastore.JSONMessage, error) {
+	if s, ok := query.(*datastore.Search); ok {
+		req, err := newSearchRequest(ec.index, s)
+		if err != nil {
+			return nil, err
+		}
+		query = req
+	}
 	switch s := query.(type) {
 	case *search.SearchDsl:
 		resp, err := s.Result()
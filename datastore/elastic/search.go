@@ -0,0 +1,107 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elastic
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/control-center/serviced/datastore"
+)
+
+// newSearchRequest translates a datastore search into a filtered query on
+// the index
+func newSearchRequest(index string, s *datastore.Search) (ElasticSearchRequest, error) {
+	filter, err := toFilterDsl(s.Filter)
+	if err != nil {
+		return ElasticSearchRequest{}, err
+	}
+	query := map[string]interface{}{
+		"query": map[string]interface{}{
+			"filtered": map[string]interface{}{
+				"query":  map[string]interface{}{"match_all": map[string]interface{}{}},
+				"filter": filter,
+			},
+		},
+		"size": s.Max(),
+	}
+	if len(s.Fields) > 0 {
+		query["fields"] = s.Fields
+	}
+	return ElasticSearchRequest{
+		Index: index,
+		Type:  s.Kind,
+		Query: query,
+	}, nil
+}
+
+// toFilterDsl returns the elastic filter of a datastore filter
+func toFilterDsl(f datastore.Filter) (map[string]interface{}, error) {
+	switch f.Op {
+	case datastore.OpAll:
+		return map[string]interface{}{"match_all": map[string]interface{}{}}, nil
+	case datastore.OpEqual:
+		if len(f.Values) == 1 {
+			return map[string]interface{}{
+				"term": map[string]interface{}{f.Field: toTerm(f.Values[0])},
+			}, nil
+		}
+		terms := make([]interface{}, len(f.Values))
+		for i, v := range f.Values {
+			terms[i] = toTerm(v)
+		}
+		return map[string]interface{}{
+			"terms": map[string]interface{}{f.Field: terms},
+		}, nil
+	case datastore.OpExists:
+		return map[string]interface{}{
+			"exists": map[string]interface{}{"field": f.Field},
+		}, nil
+	case datastore.OpRange:
+		bounds := make(map[string]interface{})
+		if f.Min != nil {
+			bounds["gte"] = toTerm(f.Min)
+		}
+		if f.Max != nil {
+			bounds["lte"] = toTerm(f.Max)
+		}
+		return map[string]interface{}{
+			"range": map[string]interface{}{f.Field: bounds},
+		}, nil
+	case datastore.OpRegexp:
+		return map[string]interface{}{
+			"regexp": map[string]interface{}{f.Field: f.Pattern},
+		}, nil
+	case datastore.OpAnd, datastore.OpOr:
+		filters := make([]interface{}, len(f.Filters))
+		for i, sub := range f.Filters {
+			filter, err := toFilterDsl(sub)
+			if err != nil {
+				return nil, err
+			}
+			filters[i] = filter
+		}
+		return map[string]interface{}{string(f.Op): filters}, nil
+	default:
+		return nil, fmt.Errorf("unknown filter operation %q", f.Op)
+	}
+}
+
+// toTerm returns a filter value as elastic indexes it
+func toTerm(v interface{}) interface{} {
+	if t, ok := v.(time.Time); ok {
+		return t.Format(time.RFC3339)
+	}
+	return v
+}
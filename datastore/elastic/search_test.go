@@ -0,0 +1,73 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build unit
+
+package elastic
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/control-center/serviced/datastore"
+)
+
+func TestNewSearchRequest(t *testing.T) {
+	since := time.Date(2016, 9, 12, 15, 30, 0, 0, time.UTC)
+	s := datastore.NewSearch("service").Where(
+		datastore.Equal("PoolID", "default"),
+		datastore.Equal("Tags", "daemon", "web"),
+		datastore.Or(
+			datastore.Exists("ID"),
+			datastore.Regexp("Name", ".*"),
+		),
+		datastore.Range("UpdatedAt", since, nil),
+	).Select("ID", "Name").Size(1)
+
+	req, err := newSearchRequest("controlplane", s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Index != "controlplane" || req.Type != "service" {
+		t.Errorf("unexpected index %q and type %q", req.Index, req.Type)
+	}
+	actual, err := json.Marshal(req.Query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"fields":["ID","Name"],"query":{"filtered":{"filter":{"and":[` +
+		`{"term":{"PoolID":"default"}},` +
+		`{"terms":{"Tags":["daemon","web"]}},` +
+		`{"or":[{"exists":{"field":"ID"}},{"regexp":{"Name":".*"}}]},` +
+		`{"range":{"UpdatedAt":{"gte":"2016-09-12T15:30:00Z"}}}` +
+		`]},"query":{"match_all":{}}}},"size":1}`
+	if string(actual) != expected {
+		t.Errorf("expected %s\ngot %s", expected, actual)
+	}
+}
+
+func TestNewSearchRequestAll(t *testing.T) {
+	req, err := newSearchRequest("controlplane", datastore.NewSearch("host"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, err := json.Marshal(req.Query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"query":{"filtered":{"filter":{"match_all":{}},"query":{"match_all":{}}}},"size":50000}`
+	if string(actual) != expected {
+		t.Errorf("expected %s\ngot %s", expected, actual)
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embedded
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/boltdb/bolt"
+	"github.com/control-center/serviced/datastore"
+)
+
+// record is an entity as it is stored in its bucket
+type record struct {
+	Version int
+	Source  json.RawMessage
+}
+
+type connection struct {
+	db *bolt.DB
+}
+
+// Put adds or updates an entity.  If the message has a version, it must be
+// the version of the stored entity, or Put returns datastore.ErrConflict.
+func (c *connection) Put(key datastore.Key, msg datastore.JSONMessage) error {
+	logger := plog.WithFields(log.Fields{
+		"kind": key.Kind(),
+		"id":   key.ID(),
+	})
+	logger.Debug("Put")
+	return c.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(key.Kind()))
+		if err != nil {
+			return err
+		}
+		current := record{}
+		if data := bucket.Get([]byte(key.ID())); data != nil {
+			if err := json.Unmarshal(data, &current); err != nil {
+				logger.WithError(err).Error("Could not decode stored entity")
+				return err
+			}
+		}
+		if msg.Version() > 0 && msg.Version() != current.Version {
+			logger.WithFields(log.Fields{
+				"version":        msg.Version(),
+				"currentversion": current.Version,
+			}).Debug("Entity version conflict")
+			return datastore.ErrConflict
+		}
+		data, err := json.Marshal(record{Version: current.Version + 1, Source: msg.Bytes()})
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(key.ID()), data)
+	})
+}
+
+// Get returns an entity, or datastore.ErrNoSuchEntity if it does not exist
+func (c *connection) Get(key datastore.Key) (datastore.JSONMessage, error) {
+	plog.WithFields(log.Fields{
+		"kind": key.Kind(),
+		"id":   key.ID(),
+	}).Debug("Get")
+	var rec *record
+	err := c.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(key.Kind()))
+		if bucket == nil {
+			return nil
+		}
+		data := bucket.Get([]byte(key.ID()))
+		if data == nil {
+			return nil
+		}
+		rec = &record{}
+		return json.Unmarshal(data, rec)
+	})
+	if err != nil {
+		return nil, err
+	} else if rec == nil {
+		return nil, datastore.ErrNoSuchEntity{Key: key}
+	}
+	return datastore.NewJSONMessage(rec.Source, rec.Version), nil
+}
+
+// Delete removes an entity.  It is not an error if the entity does not exist.
+func (c *connection) Delete(key datastore.Key) error {
+	plog.WithFields(log.Fields{
+		"kind": key.Kind(),
+		"id":   key.ID(),
+	}).Debug("Delete")
+	return c.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(key.Kind()))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.Delete([]byte(key.ID()))
+	})
+}
+
+// Query evaluates a *datastore.Search against the entities of its kind, in
+// the order of their ids.
+func (c *connection) Query(query interface{}) ([]datastore.JSONMessage, error) {
+	s, ok := query.(*datastore.Search)
+	if !ok {
+		return nil, fmt.Errorf("invalid search type %v", reflect.ValueOf(query))
+	}
+	match, err := s.Filter.Matcher()
+	if err != nil {
+		return nil, err
+	}
+	limit := s.Max()
+	msgs := []datastore.JSONMessage{}
+	err = c.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(s.Kind))
+		if bucket == nil {
+			return nil
+		}
+		cursor := bucket.Cursor()
+		for k, v := cursor.First(); k != nil && len(msgs) < limit; k, v = cursor.Next() {
+			rec := record{}
+			if err := json.Unmarshal(v, &rec); err != nil {
+				return err
+			}
+			var doc interface{}
+			if err := json.Unmarshal(rec.Source, &doc); err != nil {
+				return err
+			}
+			if !match(doc) {
+				continue
+			}
+			data := rec.Source
+			if len(s.Fields) > 0 {
+				if data, err = project(doc, s.Fields); err != nil {
+					return err
+				}
+			}
+			msgs = append(msgs, datastore.NewJSONMessage(data, rec.Version))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	plog.WithFields(log.Fields{
+		"kind":  s.Kind,
+		"total": len(msgs),
+	}).Debug("Query finished")
+	return msgs, nil
+}
+
+// project returns a document with only the given top-level fields
+func project(doc interface{}, fields []string) (json.RawMessage, error) {
+	obj, _ := doc.(map[string]interface{})
+	projected := make(map[string]interface{})
+	for _, field := range fields {
+		if v, ok := obj[field]; ok {
+			projected[field] = v
+		}
+	}
+	return json.Marshal(projected)
+}
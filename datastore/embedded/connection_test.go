@@ -0,0 +1,218 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build unit
+
+package embedded
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/control-center/serviced/datastore"
+)
+
+type testEntity struct {
+	ID     string
+	PoolID string
+	IPs    []testIP
+	datastore.VersionedEntity
+}
+
+type testIP struct {
+	IPAddress string
+}
+
+func (e *testEntity) ValidEntity() error {
+	return nil
+}
+
+func openTestDriver(t *testing.T) (*Driver, string) {
+	dir, err := ioutil.TempDir("", "embedded-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	driver, err := Open(filepath.Join(dir, DefaultFilename), time.Second)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return driver, dir
+}
+
+func TestPutGetDelete(t *testing.T) {
+	driver, dir := openTestDriver(t)
+	defer os.RemoveAll(dir)
+	defer driver.Close()
+	datastore.Register(driver)
+	ctx := datastore.Get()
+	ds := datastore.New()
+
+	key := datastore.NewKey("host", "host1")
+	actual := &testEntity{}
+	if err := ds.Get(ctx, key, actual); !datastore.IsErrNoSuchEntity(err) {
+		t.Fatalf("expected ErrNoSuchEntity, got %v", err)
+	}
+
+	entity := &testEntity{ID: "host1", PoolID: "default"}
+	if err := ds.Put(ctx, key, entity); err != nil {
+		t.Fatal(err)
+	}
+	if err := ds.Get(ctx, key, actual); err != nil {
+		t.Fatal(err)
+	} else if actual.PoolID != "default" || actual.DatabaseVersion != 1 {
+		t.Errorf("unexpected entity %+v", actual)
+	}
+
+	// an update at the current version succeeds
+	actual.PoolID = "other"
+	if err := ds.Put(ctx, key, actual); err != nil {
+		t.Fatal(err)
+	}
+
+	// an update at an old version conflicts
+	actual.PoolID = "stale"
+	if err := ds.Put(ctx, key, actual); err != datastore.ErrConflict {
+		t.Errorf("expected ErrConflict, got %v", err)
+	}
+	if err := ds.Get(ctx, key, actual); err != nil {
+		t.Fatal(err)
+	} else if actual.PoolID != "other" || actual.DatabaseVersion != 2 {
+		t.Errorf("unexpected entity %+v", actual)
+	}
+
+	if err := ds.Delete(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	if err := ds.Get(ctx, key, actual); !datastore.IsErrNoSuchEntity(err) {
+		t.Errorf("expected ErrNoSuchEntity, got %v", err)
+	}
+	if err := ds.Delete(ctx, key); err != nil {
+		t.Errorf("unexpected error deleting a missing entity: %s", err)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	driver, dir := openTestDriver(t)
+	defer os.RemoveAll(dir)
+	defer driver.Close()
+	datastore.Register(driver)
+	ctx := datastore.Get()
+	ds := datastore.New()
+
+	for _, e := range []*testEntity{
+		{ID: "host1", PoolID: "default", IPs: []testIP{{"10.0.0.1"}, {"10.0.1.1"}}},
+		{ID: "host2", PoolID: "default", IPs: []testIP{{"10.0.0.2"}}},
+		{ID: "host3", PoolID: "other", IPs: []testIP{{"10.0.0.3"}}},
+	} {
+		if err := ds.Put(ctx, datastore.NewKey("host", e.ID), e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ds.Put(ctx, datastore.NewKey("pool", "default"), &testEntity{ID: "default"}); err != nil {
+		t.Fatal(err)
+	}
+
+	query := func(s *datastore.Search) []string {
+		results, err := datastore.NewQuery(ctx).Execute(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids := []string{}
+		for results.HasNext() {
+			var e testEntity
+			if err := results.Next(&e); err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, e.ID)
+		}
+		return ids
+	}
+	expect := func(name string, actual []string, expected ...string) {
+		if len(actual) != len(expected) {
+			t.Errorf("%s: expected %v, got %v", name, expected, actual)
+			return
+		}
+		for i := range expected {
+			if actual[i] != expected[i] {
+				t.Errorf("%s: expected %v, got %v", name, expected, actual)
+				return
+			}
+		}
+	}
+
+	expect("all", query(datastore.NewSearch("host")), "host1", "host2", "host3")
+	expect("pool", query(datastore.NewSearch("host").Where(datastore.Equal("PoolID", "default"))), "host1", "host2")
+	expect("ip", query(datastore.NewSearch("host").Where(datastore.Equal("IPs.IPAddress", "10.0.1.1"))), "host1")
+	expect("and", query(datastore.NewSearch("host").Where(
+		datastore.Equal("PoolID", "default"),
+		datastore.Regexp("ID", ".*2"),
+	)), "host2")
+	expect("size", query(datastore.NewSearch("host").Size(1)), "host1")
+	expect("kind", query(datastore.NewSearch("pool")), "default")
+	expect("no kind", query(datastore.NewSearch("service")))
+
+	// projected results only have the selected fields
+	conn, err := driver.GetConnection()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgs, err := conn.Query(datastore.NewSearch("host").Where(datastore.Equal("ID", "host3")).Select("ID"))
+	if err != nil {
+		t.Fatal(err)
+	} else if len(msgs) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(msgs))
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(msgs[0].Bytes(), &fields); err != nil {
+		t.Fatal(err)
+	} else if len(fields) != 1 || fields["ID"] != "host3" {
+		t.Errorf("unexpected fields %v", fields)
+	} else if msgs[0].Version() != 1 {
+		t.Errorf("expected version 1, got %d", msgs[0].Version())
+	}
+
+	if _, err := conn.Query("_exists_:ID"); err == nil {
+		t.Errorf("expected an error for a query that is not a search")
+	}
+}
+
+func TestReopen(t *testing.T) {
+	driver, dir := openTestDriver(t)
+	defer os.RemoveAll(dir)
+	datastore.Register(driver)
+	ds := datastore.New()
+
+	key := datastore.NewKey("host", "host1")
+	if err := ds.Put(datastore.Get(), key, &testEntity{ID: "host1"}); err != nil {
+		t.Fatal(err)
+	}
+	driver.Close()
+
+	driver, err := Open(filepath.Join(dir, DefaultFilename), time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer driver.Close()
+	datastore.Register(driver)
+	actual := &testEntity{}
+	if err := ds.Get(datastore.Get(), key, actual); err != nil {
+		t.Fatal(err)
+	} else if actual.ID != "host1" {
+		t.Errorf("unexpected entity %+v", actual)
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package embedded implements a datastore driver on a bolt database file, so
+// that the master can keep its entities without an Elasticsearch cluster.
+// Each kind is a bucket of entities keyed by id, and every write is a
+// transaction.
+package embedded
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/control-center/serviced/datastore"
+	"github.com/control-center/serviced/logging"
+)
+
+var (
+	plog = logging.PackageLogger() // the standard package logger
+)
+
+// DefaultFilename is the name of the database file in the isvcs path
+const DefaultFilename = "controlplane.db"
+
+// Driver keeps the entities of the datastore in a bolt database file
+type Driver struct {
+	db *bolt.DB
+}
+
+// Assert that the embedded driver meets the Driver interface
+var _ datastore.Driver = &Driver{}
+
+// Open opens or creates the database file at path.  It waits for at most
+// timeout for another process to release the file.
+func Open(path string, timeout time.Duration) (*Driver, error) {
+	logger := plog.WithField("path", path)
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		logger.WithError(err).Debug("Could not create directory for datastore")
+		return nil, err
+	}
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: timeout})
+	if err != nil {
+		logger.WithError(err).Debug("Could not open datastore")
+		return nil, err
+	}
+	logger.Debug("Opened embedded datastore")
+	return &Driver{db: db}, nil
+}
+
+// Close closes the database file
+func (d *Driver) Close() error {
+	return d.db.Close()
+}
+
+// GetConnection returns a connection to the database.  Connections share the
+// database file, and need not be closed.
+func (d *Driver) GetConnection() (datastore.Connection, error) {
+	return &connection{d.db}, nil
+}
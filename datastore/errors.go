@@ -14,6 +14,7 @@
 package datastore
 
 import (
+	"errors"
 	"fmt"
 )
 
@@ -34,3 +35,7 @@ func IsErrNoSuchEntity(err error) bool {
 	}
 	return false
 }
+
+// ErrConflict is returned when an entity is put with a version that is no
+// longer current because another writer updated it first.
+var ErrConflict = errors.New("Your changes conflict with those made by another user. Please reload and try your changes again.")
@@ -0,0 +1,41 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	"encoding/json"
+
+	"github.com/control-center/serviced/datastore"
+)
+
+// document is a stored entity as plain JSON, so that a migration can change
+// fields that are no longer part of the entity's type.  Numbers are kept as
+// json.Number so that they are written back as they were read.
+type document struct {
+	fields map[string]interface{}
+	datastore.VersionedEntity
+}
+
+func (d *document) ValidEntity() error {
+	return nil
+}
+
+func (d *document) UnmarshalJSON(data []byte) error {
+	d.fields = make(map[string]interface{})
+	return datastore.SafeUnmarshal(data, &d.fields)
+}
+
+func (d *document) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.fields)
+}
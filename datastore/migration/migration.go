@@ -0,0 +1,211 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package migration upgrades the schema of the entities in the datastore.
+// Each migration changes the stored documents of one kind, migrations apply
+// in the order they are registered, and the datastore records each migration
+// once it has been applied to every entity of its kind.
+package migration
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/control-center/serviced/datastore"
+	"github.com/control-center/serviced/logging"
+)
+
+var (
+	plog = logging.PackageLogger() // the standard package logger
+)
+
+// kind is the kind of the records of applied migrations
+const kind = "migration"
+
+var (
+	// ErrInvalidMigration is returned when a migration is missing an id, a
+	// kind or an upgrade function
+	ErrInvalidMigration = errors.New("migration needs an id, a kind and an upgrade function")
+	// ErrDuplicateMigration is returned when a migration id is registered
+	// twice
+	ErrDuplicateMigration = errors.New("migration is already registered")
+)
+
+// Migration is a change to the stored entities of a kind.  Up changes a
+// document in place and returns true if it changed anything.  It must be
+// idempotent, because a migration that fails part way through is applied
+// again from the start.
+type Migration struct {
+	ID          string
+	Kind        string
+	Description string
+	Up          func(doc map[string]interface{}) (bool, error)
+}
+
+// Status is the state of a migration
+type Status struct {
+	ID          string
+	Kind        string
+	Description string
+	Applied     bool
+	AppliedAt   time.Time
+	Changed     int // entities that were, or on a dry run would be, changed
+}
+
+// Record is the datastore entity of an applied migration
+type Record struct {
+	ID          string
+	Kind        string
+	Description string
+	AppliedAt   time.Time
+	Changed     int
+	datastore.VersionedEntity
+}
+
+// ValidEntity returns an error if the record is missing its id
+func (r *Record) ValidEntity() error {
+	if r.ID == "" {
+		return ErrInvalidMigration
+	}
+	return nil
+}
+
+// Key returns the datastore key of the record of a migration
+func Key(id string) datastore.Key {
+	return datastore.NewKey(kind, id)
+}
+
+// Registry is an ordered list of migrations
+type Registry struct {
+	migrations []Migration
+	ids        map[string]struct{}
+}
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{ids: make(map[string]struct{})}
+}
+
+// Register adds migrations to the end of the registry
+func (r *Registry) Register(migrations ...Migration) error {
+	for _, m := range migrations {
+		if m.ID == "" || m.Kind == "" || m.Up == nil {
+			return ErrInvalidMigration
+		} else if _, ok := r.ids[m.ID]; ok {
+			return ErrDuplicateMigration
+		}
+		r.ids[m.ID] = struct{}{}
+		r.migrations = append(r.migrations, m)
+	}
+	return nil
+}
+
+// Status returns the state of every registered migration, in order
+func (r *Registry) Status(ctx datastore.Context) ([]Status, error) {
+	store := datastore.New()
+	statuses := make([]Status, len(r.migrations))
+	for i, m := range r.migrations {
+		statuses[i] = Status{ID: m.ID, Kind: m.Kind, Description: m.Description}
+		rec := &Record{}
+		if err := store.Get(ctx, Key(m.ID), rec); datastore.IsErrNoSuchEntity(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		statuses[i].Applied = true
+		statuses[i].AppliedAt = rec.AppliedAt
+		statuses[i].Changed = rec.Changed
+	}
+	return statuses, nil
+}
+
+// Up applies the migrations that have not been applied, in order, and
+// returns their states.  On a dry run nothing is written, and each state
+// counts the entities that the migration would change as they are stored
+// now.
+func (r *Registry) Up(ctx datastore.Context, dryRun bool) ([]Status, error) {
+	statuses, err := r.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	store := datastore.New()
+	pending := []Status{}
+	for i, m := range r.migrations {
+		if statuses[i].Applied {
+			continue
+		}
+		logger := plog.WithFields(log.Fields{
+			"migration": m.ID,
+			"kind":      m.Kind,
+			"dryrun":    dryRun,
+		})
+		status := statuses[i]
+		if status.Changed, err = r.apply(ctx, m, dryRun); err != nil {
+			logger.WithError(err).Error("Could not apply migration")
+			return pending, err
+		}
+		if !dryRun {
+			status.Applied = true
+			status.AppliedAt = time.Now().UTC()
+			rec := &Record{
+				ID:          m.ID,
+				Kind:        m.Kind,
+				Description: m.Description,
+				AppliedAt:   status.AppliedAt,
+				Changed:     status.Changed,
+			}
+			if err := store.Put(ctx, Key(m.ID), rec); err != nil {
+				logger.WithError(err).Error("Could not record migration")
+				return pending, err
+			}
+		}
+		logger.WithField("changed", status.Changed).Info("Applied migration")
+		pending = append(pending, status)
+	}
+	return pending, nil
+}
+
+// apply runs a migration on each entity of its kind and returns the number of
+// entities that changed.
+func (r *Registry) apply(ctx datastore.Context, m Migration, dryRun bool) (int, error) {
+	results, err := datastore.NewQuery(ctx).Execute(datastore.NewSearch(m.Kind))
+	if err != nil {
+		return 0, err
+	}
+	store := datastore.New()
+	changed := 0
+	for results.HasNext() {
+		doc := &document{}
+		if err := results.Next(doc); err != nil {
+			return changed, err
+		}
+		id, ok := doc.fields["ID"].(string)
+		if !ok || id == "" {
+			return changed, fmt.Errorf("%s entity has no ID", m.Kind)
+		}
+		if ok, err := m.Up(doc.fields); err != nil {
+			return changed, fmt.Errorf("could not migrate %s %s: %s", m.Kind, id, err)
+		} else if !ok {
+			continue
+		}
+		changed++
+		if !dryRun {
+			if err := store.Put(ctx, datastore.NewKey(m.Kind, id), doc); err != nil {
+				return changed, err
+			}
+		}
+	}
+	return changed, nil
+}
@@ -0,0 +1,138 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build unit
+
+package migration
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/control-center/serviced/datastore"
+	"github.com/control-center/serviced/datastore/embedded"
+)
+
+type testEntity struct {
+	ID    string
+	Name  string
+	Label string
+	datastore.VersionedEntity
+}
+
+func (e *testEntity) ValidEntity() error {
+	return nil
+}
+
+// renameLabel moves Label to Name
+func renameLabel(doc map[string]interface{}) (bool, error) {
+	label, ok := doc["Label"].(string)
+	if !ok || label == "" {
+		return false, nil
+	}
+	doc["Name"] = label
+	doc["Label"] = ""
+	return true, nil
+}
+
+func openTestDriver(t *testing.T) (*embedded.Driver, string) {
+	dir, err := ioutil.TempDir("", "migration-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	driver, err := embedded.Open(filepath.Join(dir, embedded.DefaultFilename), time.Second)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return driver, dir
+}
+
+func TestRegister(t *testing.T) {
+	r := NewRegistry()
+	m := Migration{ID: "test-0001", Kind: "test", Up: renameLabel}
+	if err := r.Register(m); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := r.Register(m); err != ErrDuplicateMigration {
+		t.Errorf("expected %s, got %v", ErrDuplicateMigration, err)
+	}
+	if err := r.Register(Migration{ID: "test-0002", Kind: "test"}); err != ErrInvalidMigration {
+		t.Errorf("expected %s, got %v", ErrInvalidMigration, err)
+	}
+}
+
+func TestUp(t *testing.T) {
+	driver, dir := openTestDriver(t)
+	defer os.RemoveAll(dir)
+	defer driver.Close()
+	datastore.Register(driver)
+	ctx := datastore.Get()
+	ds := datastore.New()
+
+	entities := []*testEntity{
+		{ID: "a", Label: "alpha"},
+		{ID: "b", Name: "beta"},
+	}
+	for _, e := range entities {
+		if err := ds.Put(ctx, datastore.NewKey("test", e.ID), e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := NewRegistry()
+	if err := r.Register(Migration{ID: "test-0001", Kind: "test", Up: renameLabel}); err != nil {
+		t.Fatal(err)
+	}
+
+	// a dry run counts the changes without making them
+	statuses, err := r.Up(ctx, true)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(statuses) != 1 || statuses[0].Applied || statuses[0].Changed != 1 {
+		t.Fatalf("unexpected dry run statuses %+v", statuses)
+	}
+	actual := &testEntity{}
+	if err := ds.Get(ctx, datastore.NewKey("test", "a"), actual); err != nil {
+		t.Fatal(err)
+	} else if actual.Name != "" || actual.Label != "alpha" {
+		t.Errorf("dry run changed entity %+v", actual)
+	}
+
+	statuses, err = r.Up(ctx, false)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(statuses) != 1 || !statuses[0].Applied || statuses[0].Changed != 1 {
+		t.Fatalf("unexpected statuses %+v", statuses)
+	}
+	if err := ds.Get(ctx, datastore.NewKey("test", "a"), actual); err != nil {
+		t.Fatal(err)
+	} else if actual.Name != "alpha" || actual.Label != "" || actual.DatabaseVersion != 2 {
+		t.Errorf("unexpected entity %+v", actual)
+	}
+
+	// applied migrations are recorded and do not run again
+	if statuses, err = r.Status(ctx); err != nil {
+		t.Fatal(err)
+	} else if len(statuses) != 1 || !statuses[0].Applied || statuses[0].AppliedAt.IsZero() {
+		t.Errorf("unexpected status %+v", statuses)
+	}
+	if statuses, err = r.Up(ctx, false); err != nil {
+		t.Fatal(err)
+	} else if len(statuses) != 0 {
+		t.Errorf("expected no pending migrations, got %+v", statuses)
+	}
+}
@@ -0,0 +1,324 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultSearchLimit is the number of entities a search returns when it does
+// not set a limit.
+const DefaultSearchLimit = 50000
+
+// Search is a query for the entities of a kind that every driver can
+// evaluate.  Pass it to Query.Execute.
+type Search struct {
+	Kind   string
+	Filter Filter   // entities must match the filter; the zero Filter matches all
+	Fields []string // top-level fields of each result; empty returns whole entities
+	Limit  int      // most results returned; 0 is DefaultSearchLimit
+}
+
+// NewSearch returns a search for all of the entities of a kind
+func NewSearch(kind string) *Search {
+	return &Search{Kind: kind}
+}
+
+// Where limits the search to the entities that match all of the filters
+func (s *Search) Where(filters ...Filter) *Search {
+	if len(filters) == 1 {
+		s.Filter = filters[0]
+	} else {
+		s.Filter = And(filters...)
+	}
+	return s
+}
+
+// Select limits the results to the given top-level fields
+func (s *Search) Select(fields ...string) *Search {
+	s.Fields = fields
+	return s
+}
+
+// Size sets the most results the search returns
+func (s *Search) Size(limit int) *Search {
+	s.Limit = limit
+	return s
+}
+
+// Max returns the most results the search returns
+func (s *Search) Max() int {
+	if s.Limit <= 0 {
+		return DefaultSearchLimit
+	}
+	return s.Limit
+}
+
+// FilterOp is the operation of a filter
+type FilterOp string
+
+const (
+	// OpAll matches every entity
+	OpAll FilterOp = ""
+	// OpEqual matches a field that is equal to any of the values
+	OpEqual FilterOp = "equal"
+	// OpExists matches a field that is set
+	OpExists FilterOp = "exists"
+	// OpRange matches a field between a minimum and a maximum, inclusive
+	OpRange FilterOp = "range"
+	// OpRegexp matches a field that matches the whole of a pattern
+	OpRegexp FilterOp = "regexp"
+	// OpAnd matches all of the filters
+	OpAnd FilterOp = "and"
+	// OpOr matches any of the filters
+	OpOr FilterOp = "or"
+)
+
+// Filter is a condition on the fields of an entity.  Fields are named by their
+// JSON path, such as "IPs.IPAddress", and a field within a list matches if
+// any of its elements does.
+type Filter struct {
+	Op       FilterOp
+	Field    string
+	Values   []interface{}
+	Min, Max interface{}
+	Pattern  string
+	Filters  []Filter
+}
+
+// Equal matches entities where the field is equal to any of the values
+func Equal(field string, values ...interface{}) Filter {
+	return Filter{Op: OpEqual, Field: field, Values: values}
+}
+
+// Exists matches entities where the field is set
+func Exists(field string) Filter {
+	return Filter{Op: OpExists, Field: field}
+}
+
+// Range matches entities where the field is between min and max, inclusive.
+// A nil bound is unbounded.  Bounds are numbers, strings or time.Time.
+func Range(field string, min, max interface{}) Filter {
+	return Filter{Op: OpRange, Field: field, Min: min, Max: max}
+}
+
+// Regexp matches entities where the field matches the whole of the pattern
+func Regexp(field, pattern string) Filter {
+	return Filter{Op: OpRegexp, Field: field, Pattern: pattern}
+}
+
+// And matches entities that match all of the filters
+func And(filters ...Filter) Filter {
+	return Filter{Op: OpAnd, Filters: filters}
+}
+
+// Or matches entities that match any of the filters
+func Or(filters ...Filter) Filter {
+	return Filter{Op: OpOr, Filters: filters}
+}
+
+// Matcher returns a function that reports whether a JSON document, decoded
+// into an interface{}, matches the filter.  Drivers that cannot translate a
+// filter into a native query evaluate it with its matcher.
+func (f Filter) Matcher() (func(doc interface{}) bool, error) {
+	switch f.Op {
+	case OpAll:
+		return func(interface{}) bool { return true }, nil
+	case OpEqual:
+		terms := make(map[string]bool)
+		for _, v := range f.Values {
+			terms[termString(v)] = true
+		}
+		return func(doc interface{}) bool {
+			for _, v := range lookup(doc, f.Field) {
+				if terms[termString(v)] {
+					return true
+				}
+			}
+			return false
+		}, nil
+	case OpExists:
+		return func(doc interface{}) bool {
+			return len(lookup(doc, f.Field)) > 0
+		}, nil
+	case OpRange:
+		return func(doc interface{}) bool {
+			for _, v := range lookup(doc, f.Field) {
+				if f.Min != nil {
+					if c, ok := compare(v, f.Min); !ok || c < 0 {
+						continue
+					}
+				}
+				if f.Max != nil {
+					if c, ok := compare(v, f.Max); !ok || c > 0 {
+						continue
+					}
+				}
+				return true
+			}
+			return false
+		}, nil
+	case OpRegexp:
+		re, err := regexp.Compile("^(?:" + f.Pattern + ")$")
+		if err != nil {
+			return nil, err
+		}
+		return func(doc interface{}) bool {
+			for _, v := range lookup(doc, f.Field) {
+				if re.MatchString(termString(v)) {
+					return true
+				}
+			}
+			return false
+		}, nil
+	case OpAnd, OpOr:
+		matchers := make([]func(interface{}) bool, len(f.Filters))
+		for i, sub := range f.Filters {
+			m, err := sub.Matcher()
+			if err != nil {
+				return nil, err
+			}
+			matchers[i] = m
+		}
+		all := f.Op == OpAnd
+		return func(doc interface{}) bool {
+			for _, m := range matchers {
+				if m(doc) != all {
+					return !all
+				}
+			}
+			return all
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown filter operation %q", f.Op)
+	}
+}
+
+// lookup returns the values at a dotted path of a document, flattening any
+// lists along the way.  Null values are left out.
+func lookup(doc interface{}, field string) []interface{} {
+	values := []interface{}{doc}
+	for _, name := range strings.Split(field, ".") {
+		next := []interface{}{}
+		for _, v := range values {
+			if obj, ok := v.(map[string]interface{}); ok {
+				next = appendValue(next, obj[name])
+			}
+		}
+		values = next
+	}
+	return values
+}
+
+func appendValue(values []interface{}, v interface{}) []interface{} {
+	switch v := v.(type) {
+	case nil:
+		return values
+	case []interface{}:
+		for _, e := range v {
+			values = appendValue(values, e)
+		}
+		return values
+	default:
+		return append(values, v)
+	}
+}
+
+// termString returns the string form of a value, so that a number in a
+// document equals the same number as an int or a string in a filter.
+func termString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case json.Number:
+		return v.String()
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// compare returns the order of a document value against a bound, and false if
+// they are not comparable.
+func compare(v, bound interface{}) (int, bool) {
+	switch b := bound.(type) {
+	case time.Time:
+		s, ok := v.(string)
+		if !ok {
+			return 0, false
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return 0, false
+		}
+		switch {
+		case t.Before(b):
+			return -1, true
+		case t.After(b):
+			return 1, true
+		}
+		return 0, true
+	case string:
+		s, ok := v.(string)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(s, b), true
+	}
+	x, ok := toFloat(v)
+	if !ok {
+		return 0, false
+	}
+	y, ok := toFloat(bound)
+	if !ok {
+		return 0, false
+	}
+	switch {
+	case x < y:
+		return -1, true
+	case x > y:
+		return 1, true
+	}
+	return 0, true
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
@@ -0,0 +1,97 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build unit
+
+package datastore
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+const testDoc = `{
+	"ID": "svc1",
+	"Name": "Zope",
+	"ParentServiceID": "",
+	"Tags": ["daemon", "web"],
+	"UpdatedAt": "2016-09-12T10:30:00.123456789-05:00",
+	"Endpoints": [
+		{"Purpose": "import", "AddressConfig": {"Port": 0}},
+		{"Purpose": "export", "AddressConfig": {"Port": 8080}, "VHostList": [{"Name": "zope"}]}
+	],
+	"Description": null
+}`
+
+func TestFilterMatcher(t *testing.T) {
+	var doc interface{}
+	if err := json.Unmarshal([]byte(testDoc), &doc); err != nil {
+		t.Fatal(err)
+	}
+	updated := time.Date(2016, 9, 12, 15, 30, 0, 0, time.UTC)
+
+	for _, tc := range []struct {
+		name   string
+		filter Filter
+		match  bool
+	}{
+		{"all", Filter{}, true},
+		{"equal", Equal("ID", "svc1"), true},
+		{"equal any", Equal("ID", "svc0", "svc1"), true},
+		{"not equal", Equal("ID", "svc2"), false},
+		{"equal empty", Equal("ParentServiceID", ""), true},
+		{"equal in list", Equal("Tags", "web"), true},
+		{"equal nested", Equal("Endpoints.Purpose", "export"), true},
+		{"equal number", Equal("Endpoints.AddressConfig.Port", 8080), true},
+		{"equal number string", Equal("Endpoints.AddressConfig.Port", "8080"), true},
+		{"exists", Exists("ID"), true},
+		{"exists null", Exists("Description"), false},
+		{"exists missing", Exists("Launch"), false},
+		{"exists nested", Exists("Endpoints.VHostList.Name"), true},
+		{"range", Range("Endpoints.AddressConfig.Port", 1, 65535), true},
+		{"range above", Range("Endpoints.AddressConfig.Port", 9000, nil), false},
+		{"range time", Range("UpdatedAt", updated.Add(-time.Second), nil), true},
+		{"range time equal", Range("UpdatedAt", updated, nil), true},
+		{"range time after", Range("UpdatedAt", updated.Add(time.Second), nil), false},
+		{"regexp", Regexp("Name", "[zZ]ope"), true},
+		{"regexp whole", Regexp("Name", "op"), false},
+		{"regexp nested", Regexp("Endpoints.VHostList.Name", ".+"), true},
+		{"and", And(Equal("ID", "svc1"), Equal("Tags", "daemon")), true},
+		{"and miss", And(Equal("ID", "svc1"), Equal("Tags", "cron")), false},
+		{"or", Or(Equal("ID", "svc2"), Regexp("Name", ".*pe")), true},
+		{"or miss", Or(Equal("ID", "svc2"), Equal("Name", "zope")), false},
+	} {
+		match, err := tc.filter.Matcher()
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", tc.name, err)
+		} else if actual := match(doc); actual != tc.match {
+			t.Errorf("%s: expected %t, got %t", tc.name, tc.match, actual)
+		}
+	}
+}
+
+func TestFilterMatcherBadRegexp(t *testing.T) {
+	if _, err := And(Regexp("Name", "(")).Matcher(); err == nil {
+		t.Errorf("expected an error for a bad pattern")
+	}
+}
+
+func TestSearchMax(t *testing.T) {
+	if max := NewSearch("service").Max(); max != DefaultSearchLimit {
+		t.Errorf("expected %d, got %d", DefaultSearchLimit, max)
+	}
+	if max := NewSearch("service").Size(1).Max(); max != 1 {
+		t.Errorf("expected 1, got %d", max)
+	}
+}
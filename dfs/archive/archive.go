@@ -0,0 +1,296 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package archive encodes backup streams with a choice of compression and
+// optional encryption.
+//
+// Archives that are gzip compressed and not encrypted are plain gzip streams,
+// so they remain readable by older versions and by tar.  All other archives
+// start with a header that records the compression and encryption used:
+//
+//	magic (8 bytes) | header length (4 bytes) | JSON header | [header MAC] | payload
+//
+// The payload is compressed and then, if the archive is encrypted, split into
+// chunks that are sealed with AES-256-GCM under a random file key.  The file
+// key is wrapped in the header once for every key that may decrypt it: a
+// symmetric key from a key file, or a P-256 recipient public key.
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+
+	"github.com/control-center/serviced/logging"
+	gzip "github.com/klauspost/pgzip"
+)
+
+const (
+	// Gzip compresses archives with gzip
+	Gzip = "gzip"
+	// Zstd compresses archives with zstd
+	Zstd = "zstd"
+
+	// AESGCM is the encryption used for encrypted archives
+	AESGCM = "aes-256-gcm"
+
+	maxHeaderSize = 1024 * 1024
+)
+
+var (
+	plog = logging.PackageLogger()
+
+	magic     = []byte("SVCDARC\x01")
+	gzipMagic = []byte{0x1f, 0x8b}
+
+	// ErrUnsupportedCompression is returned for unknown compression types
+	ErrUnsupportedCompression = errors.New("unsupported compression")
+	// ErrUnsupportedEncryption is returned for unknown encryption types
+	ErrUnsupportedEncryption = errors.New("unsupported encryption")
+	// ErrInvalidHeader is returned when the archive header cannot be read
+	ErrInvalidHeader = errors.New("invalid archive header")
+	// ErrHeaderMAC is returned when the archive header has been tampered with
+	ErrHeaderMAC = errors.New("archive header failed authentication")
+	// ErrNoKey is returned when an archive is encrypted, but none of the
+	// configured keys can decrypt it
+	ErrNoKey = errors.New("archive is encrypted and no matching key is configured")
+)
+
+// Config describes how archives are encoded and which keys may decrypt them
+type Config struct {
+	// Compression is either Gzip (the default) or Zstd
+	Compression string
+	// Key is a symmetric 32-byte key used to encrypt and decrypt archives
+	Key []byte
+	// Recipients are the public keys that encrypted archives are written to
+	Recipients []*ecdsa.PublicKey
+	// Identities are the private keys used to decrypt archives written to
+	// recipients
+	Identities []*ecdsa.PrivateKey
+}
+
+// Encrypted returns true if archives written with this config are encrypted
+func (c *Config) Encrypted() bool {
+	return c != nil && (len(c.Key) > 0 || len(c.Recipients) > 0)
+}
+
+func (c *Config) compression() string {
+	if c == nil || c.Compression == "" {
+		return Gzip
+	}
+	return c.Compression
+}
+
+// Extension returns the file extension for archives written with this config
+func (c *Config) Extension() string {
+	ext := "tgz"
+	if c.compression() == Zstd {
+		ext = "tar.zst"
+	}
+	if c.Encrypted() {
+		ext += ".enc"
+	}
+	return ext
+}
+
+// header is serialized at the start of the archive
+type header struct {
+	Compression string
+	Encryption  string   `json:",omitempty"`
+	Salt        []byte   `json:",omitempty"`
+	Stanzas     []stanza `json:",omitempty"`
+}
+
+// NewWriter returns a writer that encodes an archive into w.  The archive is
+// complete once the writer is closed; closing it does not close w.
+func NewWriter(w io.Writer, c *Config) (io.WriteCloser, error) {
+	compression := c.compression()
+	if compression != Gzip && compression != Zstd {
+		return nil, ErrUnsupportedCompression
+	}
+
+	// preserve the legacy format where possible
+	if compression == Gzip && !c.Encrypted() {
+		return newGzipWriter(w), nil
+	}
+
+	hdr := header{Compression: compression}
+	var payload io.WriteCloser = nopWriteCloser{w}
+	var fileKey []byte
+	if c.Encrypted() {
+		fileKey = make([]byte, 32)
+		if _, err := io.ReadFull(rand.Reader, fileKey); err != nil {
+			return nil, err
+		}
+		hdr.Encryption = AESGCM
+		hdr.Salt = make([]byte, 16)
+		if _, err := io.ReadFull(rand.Reader, hdr.Salt); err != nil {
+			return nil, err
+		}
+		stanzas, err := wrapFileKey(fileKey, c)
+		if err != nil {
+			return nil, err
+		}
+		hdr.Stanzas = stanzas
+	}
+
+	// write the header
+	data, err := json.Marshal(hdr)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.Write(magic)
+	binary.Write(&buf, binary.BigEndian, uint32(len(data)))
+	buf.Write(data)
+	if fileKey != nil {
+		buf.Write(headerMAC(fileKey, data))
+	}
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return nil, err
+	}
+
+	if fileKey != nil {
+		if payload, err = newEncryptWriter(w, fileKey, hdr.Salt); err != nil {
+			return nil, err
+		}
+	}
+	if compression == Zstd {
+		return newZstdWriter(payload)
+	}
+	return &chainWriter{WriteCloser: newGzipWriter(payload), next: payload}, nil
+}
+
+// NewReader returns a reader that decodes an archive from r.  Gzip streams
+// are decompressed, and anything that is not a recognized archive is passed
+// through unchanged.
+func NewReader(r io.Reader, c *Config) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	prefix, _ := br.Peek(len(magic))
+	if bytes.HasPrefix(prefix, gzipMagic) {
+		return gzip.NewReader(br)
+	} else if !bytes.Equal(prefix, magic) {
+		return ioutil.NopCloser(br), nil
+	}
+
+	// read the header
+	if _, err := br.Discard(len(magic)); err != nil {
+		return nil, err
+	}
+	var size uint32
+	if err := binary.Read(br, binary.BigEndian, &size); err != nil {
+		return nil, ErrInvalidHeader
+	} else if size > maxHeaderSize {
+		return nil, ErrInvalidHeader
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(br, data); err != nil {
+		return nil, ErrInvalidHeader
+	}
+	var hdr header
+	if err := json.Unmarshal(data, &hdr); err != nil {
+		return nil, ErrInvalidHeader
+	}
+
+	var payload io.ReadCloser = ioutil.NopCloser(br)
+	switch hdr.Encryption {
+	case "":
+	case AESGCM:
+		fileKey, err := unwrapFileKey(hdr.Stanzas, c)
+		if err != nil {
+			return nil, err
+		}
+		mac := make([]byte, sha256.Size)
+		if _, err := io.ReadFull(br, mac); err != nil {
+			return nil, ErrInvalidHeader
+		}
+		if !hmac.Equal(mac, headerMAC(fileKey, data)) {
+			return nil, ErrHeaderMAC
+		}
+		if payload, err = newDecryptReader(br, fileKey, hdr.Salt); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrUnsupportedEncryption
+	}
+
+	switch hdr.Compression {
+	case Gzip:
+		gz, err := gzip.NewReader(payload)
+		if err != nil {
+			return nil, err
+		}
+		return &chainReader{ReadCloser: gz, next: payload}, nil
+	case Zstd:
+		return newZstdReader(payload)
+	default:
+		return nil, ErrUnsupportedCompression
+	}
+}
+
+// headerMAC authenticates the header with a key derived from the file key
+func headerMAC(fileKey, data []byte) []byte {
+	h := hmac.New(sha256.New, hkdf(fileKey, nil, "header"))
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// newGzipWriter returns a gzip writer for backups
+func newGzipWriter(w io.Writer) io.WriteCloser {
+	gz := gzip.NewWriter(w)
+	// CC-2292: Limit concurrency of backup gzipping
+	// This setting will cause the writer to process up to 2 100KB blocks
+	// at a time before the writer blocks. The default was 16 250KB blocks.
+	// Smaller blocks will allow other goroutines to get time more frequently.
+	gz.SetConcurrency(100000, 2)
+	return gz
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// chainWriter closes the next writer in the chain after itself
+type chainWriter struct {
+	io.WriteCloser
+	next io.Closer
+}
+
+func (w *chainWriter) Close() error {
+	if err := w.WriteCloser.Close(); err != nil {
+		return err
+	}
+	return w.next.Close()
+}
+
+// chainReader closes the next reader in the chain after itself
+type chainReader struct {
+	io.ReadCloser
+	next io.Closer
+}
+
+func (r *chainReader) Close() error {
+	err := r.ReadCloser.Close()
+	r.next.Close()
+	return err
+}
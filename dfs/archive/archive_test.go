@@ -0,0 +1,214 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build unit
+
+package archive
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/pem"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type ArchiveSuite struct{}
+
+var _ = Suite(&ArchiveSuite{})
+
+// payload spans several encrypted chunks and ends with a partial one
+var payload = make([]byte, 3*chunkSize+100)
+
+func init() {
+	rand.Read(payload)
+}
+
+func encode(c *C, cfg *Config, data []byte) []byte {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, cfg)
+	c.Assert(err, IsNil)
+	_, err = w.Write(data)
+	c.Assert(err, IsNil)
+	c.Assert(w.Close(), IsNil)
+	return buf.Bytes()
+}
+
+func decode(cfg *Config, data []byte) ([]byte, error) {
+	r, err := NewReader(bytes.NewReader(data), cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+func newKey(c *C) []byte {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	c.Assert(err, IsNil)
+	return key
+}
+
+func (s *ArchiveSuite) TestGzipLegacy(c *C) {
+	data := encode(c, nil, payload)
+	c.Assert(bytes.HasPrefix(data, gzipMagic), Equals, true)
+	actual, err := decode(nil, data)
+	c.Assert(err, IsNil)
+	c.Assert(bytes.Equal(actual, payload), Equals, true)
+
+	// plain streams are passed through
+	actual, err = decode(nil, []byte("plain tar"))
+	c.Assert(err, IsNil)
+	c.Assert(string(actual), Equals, "plain tar")
+}
+
+func (s *ArchiveSuite) TestKey(c *C) {
+	cfg := &Config{Key: newKey(c)}
+	c.Assert(cfg.Extension(), Equals, "tgz.enc")
+	data := encode(c, cfg, payload)
+	c.Assert(bytes.HasPrefix(data, magic), Equals, true)
+	c.Assert(bytes.Contains(data, payload[:64]), Equals, false)
+
+	actual, err := decode(cfg, data)
+	c.Assert(err, IsNil)
+	c.Assert(bytes.Equal(actual, payload), Equals, true)
+
+	_, err = decode(nil, data)
+	c.Assert(err, Equals, ErrNoKey)
+	_, err = decode(&Config{Key: newKey(c)}, data)
+	c.Assert(err, Equals, ErrNoKey)
+}
+
+func (s *ArchiveSuite) TestEmpty(c *C) {
+	cfg := &Config{Key: newKey(c)}
+	actual, err := decode(cfg, encode(c, cfg, nil))
+	c.Assert(err, IsNil)
+	c.Assert(actual, HasLen, 0)
+}
+
+func (s *ArchiveSuite) TestRecipients(c *C) {
+	priv1, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, IsNil)
+	priv2, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, IsNil)
+	cfg := &Config{Recipients: []*ecdsa.PublicKey{&priv1.PublicKey, &priv2.PublicKey}}
+	data := encode(c, cfg, payload)
+
+	for _, priv := range []*ecdsa.PrivateKey{priv1, priv2} {
+		actual, err := decode(&Config{Identities: []*ecdsa.PrivateKey{priv}}, data)
+		c.Assert(err, IsNil)
+		c.Assert(bytes.Equal(actual, payload), Equals, true)
+	}
+
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, IsNil)
+	_, err = decode(&Config{Identities: []*ecdsa.PrivateKey{other}}, data)
+	c.Assert(err, Equals, ErrNoKey)
+}
+
+func (s *ArchiveSuite) TestTamper(c *C) {
+	cfg := &Config{Key: newKey(c)}
+	data := encode(c, cfg, payload)
+
+	// header
+	tampered := bytes.Replace(data, []byte(`"Compression":"gzip"`), []byte(`"Compression":"zstd"`), 1)
+	c.Assert(bytes.Equal(tampered, data), Equals, false)
+	_, err := decode(cfg, tampered)
+	c.Assert(err, Equals, ErrHeaderMAC)
+
+	// payload
+	tampered = append([]byte{}, data...)
+	tampered[len(tampered)-chunkSize] ^= 1
+	_, err = decode(cfg, tampered)
+	c.Assert(err, ErrorMatches, ".*"+ErrCorrupt.Error())
+
+	// truncated at a chunk boundary
+	start := len(magic) + 4 + int(binary.BigEndian.Uint32(data[len(magic):])) + sha256.Size
+	_, err = decode(cfg, data[:start+2*(chunkSize+16)])
+	c.Assert(err, ErrorMatches, ".*"+ErrCorrupt.Error())
+	_, err = decode(cfg, data[:start])
+	c.Assert(err, ErrorMatches, ".*"+ErrTruncated.Error())
+}
+
+func (s *ArchiveSuite) TestZstd(c *C) {
+	if _, err := exec.LookPath(zstdBinary); err != nil {
+		c.Skip("zstd is not installed")
+	}
+	for _, cfg := range []*Config{
+		{Compression: Zstd},
+		{Compression: Zstd, Key: newKey(c)},
+	} {
+		data := encode(c, cfg, payload)
+		c.Assert(bytes.HasPrefix(data, magic), Equals, true)
+		actual, err := decode(cfg, data)
+		c.Assert(err, IsNil)
+		c.Assert(bytes.Equal(actual, payload), Equals, true)
+	}
+}
+
+func (s *ArchiveSuite) TestLoadConfig(c *C) {
+	dir := c.MkDir()
+	key := newKey(c)
+	keyFile := filepath.Join(dir, "backup.key")
+	c.Assert(ioutil.WriteFile(keyFile, []byte(hex.EncodeToString(key)+"\n"), 0600), IsNil)
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, IsNil)
+	der, err := x509.MarshalECPrivateKey(priv)
+	c.Assert(err, IsNil)
+	identity := filepath.Join(dir, "identity.pem")
+	c.Assert(ioutil.WriteFile(identity, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0600), IsNil)
+	der, err = x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	c.Assert(err, IsNil)
+	recipient := filepath.Join(dir, "recipient.pem")
+	c.Assert(ioutil.WriteFile(recipient, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0644), IsNil)
+
+	cfg, err := LoadConfig("", keyFile, []string{recipient}, []string{identity})
+	c.Assert(err, IsNil)
+	c.Assert(cfg.Key, DeepEquals, key)
+	c.Assert(cfg.Recipients, HasLen, 1)
+	c.Assert(cfg.Identities, HasLen, 1)
+
+	// only the recipient identity is needed to read the archive
+	data := encode(c, cfg, payload)
+	actual, err := decode(&Config{Identities: cfg.Identities}, data)
+	c.Assert(err, IsNil)
+	c.Assert(bytes.Equal(actual, payload), Equals, true)
+
+	_, err = LoadConfig("bzip2", "", nil, nil)
+	c.Assert(err, Equals, ErrUnsupportedCompression)
+	_, err = LoadConfig("", identity, nil, nil)
+	c.Assert(err, ErrorMatches, ".*32-byte key.*")
+	_, err = LoadConfig("", "", []string{identity}, nil)
+	c.Assert(err, ErrorMatches, ".*P-256 public key.*")
+}
+
+func (s *ArchiveSuite) TestLoadConfigZstdMissing(c *C) {
+	defer func(binary string) { zstdBinary = binary }(zstdBinary)
+	zstdBinary = filepath.Join(c.MkDir(), "zstd")
+	_, err := LoadConfig(Zstd, "", nil, nil)
+	c.Assert(err, ErrorMatches, "zstd compression needs the .* command: .*")
+}
@@ -0,0 +1,299 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package archive
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os/exec"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+const (
+	stanzaKey  = "key"
+	stanzaP256 = "p256"
+)
+
+var (
+	// ErrInvalidKey is returned when a key file does not contain a 32-byte key
+	ErrInvalidKey = errors.New("key file must contain a 32-byte key, raw or hex encoded")
+	// ErrInvalidPublicKey is returned when a recipient is not a P-256 public key
+	ErrInvalidPublicKey = errors.New("recipient must be a PEM encoded P-256 public key")
+	// ErrInvalidPrivateKey is returned when an identity is not a P-256 private key
+	ErrInvalidPrivateKey = errors.New("identity must be a PEM encoded P-256 private key")
+)
+
+// stanza wraps the file key for a single key or recipient
+type stanza struct {
+	Type         string
+	KeyID        string
+	EphemeralKey []byte `json:",omitempty"`
+	Nonce        []byte
+	WrappedKey   []byte
+}
+
+// LoadConfig reads the keys for an archive config from disk
+func LoadConfig(compression, keyFile string, recipientFiles, identityFiles []string) (*Config, error) {
+	c := &Config{Compression: compression}
+	if compression = c.compression(); compression != Gzip && compression != Zstd {
+		return nil, ErrUnsupportedCompression
+	} else if compression == Zstd {
+		if _, err := exec.LookPath(zstdBinary); err != nil {
+			return nil, fmt.Errorf("%s compression needs the %s command: %s", Zstd, zstdBinary, err)
+		}
+	}
+	if keyFile != "" {
+		key, err := ReadKeyFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not load key %s: %s", keyFile, err)
+		}
+		c.Key = key
+	}
+	for _, filename := range recipientFiles {
+		pub, err := ReadPublicKeyFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("could not load recipient %s: %s", filename, err)
+		}
+		c.Recipients = append(c.Recipients, pub)
+	}
+	for _, filename := range identityFiles {
+		priv, err := ReadPrivateKeyFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("could not load identity %s: %s", filename, err)
+		}
+		c.Identities = append(c.Identities, priv)
+	}
+	return c, nil
+}
+
+// ReadKeyFile reads a 32-byte symmetric key, either raw or hex encoded
+func ReadKeyFile(filename string) ([]byte, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	if key, err := hex.DecodeString(string(bytes.TrimSpace(data))); err == nil && len(key) == 32 {
+		return key, nil
+	} else if len(data) == 32 {
+		return data, nil
+	}
+	return nil, ErrInvalidKey
+}
+
+// ReadPublicKeyFile reads a PEM encoded P-256 public key
+func ReadPublicKeyFile(filename string) (*ecdsa.PublicKey, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrInvalidPublicKey
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, ErrInvalidPublicKey
+	}
+	pub, ok := key.(*ecdsa.PublicKey)
+	if !ok || pub.Curve != elliptic.P256() {
+		return nil, ErrInvalidPublicKey
+	}
+	return pub, nil
+}
+
+// ReadPrivateKeyFile reads a PEM encoded P-256 private key in either SEC 1
+// or PKCS #8 form
+func ReadPrivateKeyFile(filename string) (*ecdsa.PrivateKey, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var block *pem.Block
+	for {
+		if block, data = pem.Decode(data); block == nil {
+			return nil, ErrInvalidPrivateKey
+		} else if block.Type == "EC PRIVATE KEY" || block.Type == "PRIVATE KEY" {
+			break
+		}
+	}
+	var priv *ecdsa.PrivateKey
+	if block.Type == "EC PRIVATE KEY" {
+		priv, err = x509.ParseECPrivateKey(block.Bytes)
+	} else {
+		var key interface{}
+		if key, err = x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+			priv, _ = key.(*ecdsa.PrivateKey)
+		}
+	}
+	if err != nil || priv == nil || priv.Curve != elliptic.P256() {
+		return nil, ErrInvalidPrivateKey
+	}
+	return priv, nil
+}
+
+// wrapFileKey wraps the file key for every configured key and recipient
+func wrapFileKey(fileKey []byte, c *Config) ([]stanza, error) {
+	var stanzas []stanza
+	if len(c.Key) > 0 {
+		if len(c.Key) != 32 {
+			return nil, ErrInvalidKey
+		}
+		s := stanza{Type: stanzaKey, KeyID: keyID(c.Key)}
+		if err := s.wrap(hkdf(c.Key, nil, "serviced-backup key"), fileKey); err != nil {
+			return nil, err
+		}
+		stanzas = append(stanzas, s)
+	}
+	for _, pub := range c.Recipients {
+		if pub.Curve != elliptic.P256() {
+			return nil, ErrInvalidPublicKey
+		}
+		ephemeral, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		recipient := elliptic.Marshal(pub.Curve, pub.X, pub.Y)
+		s := stanza{
+			Type:         stanzaP256,
+			KeyID:        keyID(recipient),
+			EphemeralKey: elliptic.Marshal(ephemeral.Curve, ephemeral.X, ephemeral.Y),
+		}
+		kek := p256KEK(pub, ephemeral.D.Bytes(), s.EphemeralKey, recipient)
+		if err := s.wrap(kek, fileKey); err != nil {
+			return nil, err
+		}
+		stanzas = append(stanzas, s)
+	}
+	return stanzas, nil
+}
+
+// unwrapFileKey returns the file key from the first stanza that can be
+// opened with the configured key or identities
+func unwrapFileKey(stanzas []stanza, c *Config) ([]byte, error) {
+	if c == nil {
+		return nil, ErrNoKey
+	}
+	for _, s := range stanzas {
+		switch s.Type {
+		case stanzaKey:
+			if len(c.Key) == 0 || s.KeyID != keyID(c.Key) {
+				continue
+			}
+			if fileKey, err := s.unwrap(hkdf(c.Key, nil, "serviced-backup key")); err == nil {
+				return fileKey, nil
+			}
+		case stanzaP256:
+			x, y := elliptic.Unmarshal(elliptic.P256(), s.EphemeralKey)
+			if x == nil {
+				continue
+			}
+			ephemeral := &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+			for _, priv := range c.Identities {
+				recipient := elliptic.Marshal(priv.Curve, priv.X, priv.Y)
+				if s.KeyID != keyID(recipient) {
+					continue
+				}
+				kek := p256KEK(ephemeral, priv.D.Bytes(), s.EphemeralKey, recipient)
+				if fileKey, err := s.unwrap(kek); err == nil {
+					return fileKey, nil
+				}
+			}
+		default:
+			plog.WithField("type", s.Type).Debug("Skipping unsupported key stanza")
+		}
+	}
+	plog.WithFields(log.Fields{
+		"stanzas":    len(stanzas),
+		"identities": len(c.Identities),
+	}).Debug("No configured key matches the archive")
+	return nil, ErrNoKey
+}
+
+// wrap seals the file key with the key encryption key
+func (s *stanza) wrap(kek, fileKey []byte) error {
+	aead, err := newGCM(kek)
+	if err != nil {
+		return err
+	}
+	s.Nonce = make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, s.Nonce); err != nil {
+		return err
+	}
+	s.WrappedKey = aead.Seal(nil, s.Nonce, fileKey, []byte(s.Type))
+	return nil
+}
+
+// unwrap opens the file key with the key encryption key
+func (s *stanza) unwrap(kek []byte) ([]byte, error) {
+	aead, err := newGCM(kek)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.Nonce) != aead.NonceSize() {
+		return nil, ErrInvalidHeader
+	}
+	return aead.Open(nil, s.Nonce, s.WrappedKey, []byte(s.Type))
+}
+
+// p256KEK derives the key encryption key from an ECDH exchange between a
+// public key and a private scalar
+func p256KEK(pub *ecdsa.PublicKey, scalar, ephemeral, recipient []byte) []byte {
+	x, _ := pub.Curve.ScalarMult(pub.X, pub.Y, scalar)
+	shared := make([]byte, 32)
+	xb := x.Bytes()
+	copy(shared[32-len(xb):], xb)
+	salt := append(append([]byte{}, ephemeral...), recipient...)
+	return hkdf(shared, salt, "serviced-backup p256")
+}
+
+// keyID identifies a key without revealing it
+func keyID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// hkdf derives a 32-byte key with HKDF-SHA256 (RFC 5869)
+func hkdf(secret, salt []byte, info string) []byte {
+	if salt == nil {
+		salt = make([]byte, sha256.Size)
+	}
+	extract := hmac.New(sha256.New, salt)
+	extract.Write(secret)
+	expand := hmac.New(sha256.New, extract.Sum(nil))
+	expand.Write([]byte(info))
+	expand.Write([]byte{1})
+	return expand.Sum(nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,178 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package archive
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// chunkSize is the size of the plaintext in each encrypted chunk
+const chunkSize = 64 * 1024
+
+var (
+	// ErrTruncated is returned when an encrypted archive ends before its
+	// final chunk
+	ErrTruncated = errors.New("encrypted archive is truncated")
+	// ErrCorrupt is returned when an encrypted chunk fails authentication
+	ErrCorrupt = errors.New("encrypted archive failed authentication")
+	// ErrWriterClosed is returned when writing to a closed archive
+	ErrWriterClosed = errors.New("archive writer is closed")
+)
+
+// chunkNonce is the chunk counter followed by a flag that marks the final
+// chunk, so that chunks cannot be reordered or the stream truncated.
+func chunkNonce(nonce []byte, counter uint64, last bool) {
+	for i := range nonce {
+		nonce[i] = 0
+	}
+	binary.BigEndian.PutUint64(nonce[len(nonce)-9:], counter)
+	if last {
+		nonce[len(nonce)-1] = 1
+	}
+}
+
+// encryptWriter seals the payload in fixed-size chunks
+type encryptWriter struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	buf     []byte
+	nonce   []byte
+	counter uint64
+	closed  bool
+}
+
+func newEncryptWriter(w io.Writer, fileKey, salt []byte) (*encryptWriter, error) {
+	aead, err := newGCM(hkdf(fileKey, salt, "payload"))
+	if err != nil {
+		return nil, err
+	}
+	return &encryptWriter{
+		w:     w,
+		aead:  aead,
+		buf:   make([]byte, 0, chunkSize+aead.Overhead()),
+		nonce: make([]byte, aead.NonceSize()),
+	}, nil
+}
+
+func (w *encryptWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, ErrWriterClosed
+	}
+	n := 0
+	for len(p) > 0 {
+		// hold back a full chunk until we know whether it is the last one
+		if len(w.buf) == chunkSize {
+			if err := w.flush(false); err != nil {
+				return n, err
+			}
+		}
+		c := copy(w.buf[len(w.buf):chunkSize], p)
+		w.buf = w.buf[:len(w.buf)+c]
+		p = p[c:]
+		n += c
+	}
+	return n, nil
+}
+
+func (w *encryptWriter) flush(last bool) error {
+	chunkNonce(w.nonce, w.counter, last)
+	w.buf = w.aead.Seal(w.buf[:0], w.nonce, w.buf, nil)
+	if _, err := w.w.Write(w.buf); err != nil {
+		return err
+	}
+	w.buf = w.buf[:0]
+	w.counter++
+	return nil
+}
+
+// Close writes the final chunk
+func (w *encryptWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.flush(true)
+}
+
+// decryptReader opens the payload one chunk at a time
+type decryptReader struct {
+	r       *bufio.Reader
+	aead    cipher.AEAD
+	buf     []byte
+	out     []byte
+	nonce   []byte
+	counter uint64
+	done    bool
+}
+
+func newDecryptReader(r *bufio.Reader, fileKey, salt []byte) (*decryptReader, error) {
+	aead, err := newGCM(hkdf(fileKey, salt, "payload"))
+	if err != nil {
+		return nil, err
+	}
+	return &decryptReader{
+		r:     r,
+		aead:  aead,
+		buf:   make([]byte, chunkSize+aead.Overhead()),
+		nonce: make([]byte, aead.NonceSize()),
+	}, nil
+}
+
+func (r *decryptReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+func (r *decryptReader) next() error {
+	n, err := io.ReadFull(r.r, r.buf)
+	if err == io.EOF || n < r.aead.Overhead() {
+		return ErrTruncated
+	} else if err != nil && err != io.ErrUnexpectedEOF {
+		return err
+	}
+
+	// a short chunk, or a full chunk at the end of the stream, is the last
+	last := err == io.ErrUnexpectedEOF
+	if !last {
+		if _, err := r.r.Peek(1); err == io.EOF {
+			last = true
+		}
+	}
+	chunkNonce(r.nonce, r.counter, last)
+	out, err := r.aead.Open(r.buf[:0], r.nonce, r.buf[:n], nil)
+	if err != nil {
+		return ErrCorrupt
+	}
+	r.out = out
+	r.counter++
+	r.done = last
+	return nil
+}
+
+func (r *decryptReader) Close() error {
+	return nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package archive
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+)
+
+// zstdBinary is the command used to (de)compress zstd streams
+var zstdBinary = "zstd"
+
+// zstdWriter pipes the payload through zstd
+type zstdWriter struct {
+	io.WriteCloser
+	cmd    *exec.Cmd
+	next   io.Closer
+	stderr bytes.Buffer
+}
+
+func newZstdWriter(w io.WriteCloser) (io.WriteCloser, error) {
+	zw := &zstdWriter{cmd: exec.Command(zstdBinary, "-q", "-c"), next: w}
+	zw.cmd.Stdout = w
+	zw.cmd.Stderr = &zw.stderr
+	stdin, err := zw.cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	zw.WriteCloser = stdin
+	if err := zw.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("could not start %s: %s", zstdBinary, err)
+	}
+	return zw, nil
+}
+
+func (w *zstdWriter) Close() error {
+	w.WriteCloser.Close()
+	if err := w.cmd.Wait(); err != nil {
+		return fmt.Errorf("%s: %s (%s)", zstdBinary, err, bytes.TrimSpace(w.stderr.Bytes()))
+	}
+	return w.next.Close()
+}
+
+// zstdReader reads the payload from zstd
+type zstdReader struct {
+	io.ReadCloser
+	cmd    *exec.Cmd
+	next   io.Closer
+	stderr bytes.Buffer
+	waited bool
+}
+
+func newZstdReader(r io.ReadCloser) (io.ReadCloser, error) {
+	zr := &zstdReader{cmd: exec.Command(zstdBinary, "-d", "-q", "-c"), next: r}
+	zr.cmd.Stdin = r
+	zr.cmd.Stderr = &zr.stderr
+	stdout, err := zr.cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	zr.ReadCloser = stdout
+	if err := zr.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("could not start %s: %s", zstdBinary, err)
+	}
+	return zr, nil
+}
+
+func (r *zstdReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if err == io.EOF && !r.waited {
+		// report decompression errors instead of a short stream
+		r.waited = true
+		if werr := r.cmd.Wait(); werr != nil {
+			return n, fmt.Errorf("%s: %s (%s)", zstdBinary, werr, bytes.TrimSpace(r.stderr.Bytes()))
+		}
+	}
+	return n, err
+}
+
+func (r *zstdReader) Close() error {
+	if !r.waited {
+		r.waited = true
+		r.cmd.Process.Kill()
+		r.cmd.Wait()
+	}
+	return r.next.Close()
+}
@@ -47,12 +47,6 @@ func (dfs *DistributedFilesystem) Backup(data BackupInfo, w io.Writer) error {
 
 	tarOut := tar.NewWriter(io.MultiWriter(w, progress))
 
-	// write the backup metadata
-	if err := dfs.writeBackupMetadata(data, tarOut); err != nil {
-		plog.WithError(err).Error("Unable to write metadata for backup")
-		return err
-	}
-
 	var images []string
 
 	baseImageLogger := backupLogger.WithField("total", len(data.BaseImages))
@@ -87,12 +81,18 @@ func (dfs *DistributedFilesystem) Backup(data BackupInfo, w io.Writer) error {
 
 	backupLogger.WithField("total", numberOfSnapshots).Info("Preparing snapshots for backup")
 
-	// export the snapshots
+	// prepare the images for each snapshot
+	type snapshotExport struct {
+		vol  volume.Volume
+		info *volume.SnapshotInfo
+	}
+	exports := make([]snapshotExport, len(data.Snapshots))
 	for i, snapshot := range data.Snapshots {
 		vol, info, err := dfs.getSnapshotVolumeAndInfo(snapshot)
 		if err != nil {
 			return err
 		}
+		exports[i] = snapshotExport{vol: vol, info: info}
 
 		// load the images from this snapshot
 		tenantLogger := backupLogger.WithField("tenant", info.TenantID)
@@ -132,12 +132,27 @@ func (dfs *DistributedFilesystem) Backup(data BackupInfo, w io.Writer) error {
 		}
 
 		timer.Stop()
+	}
+
+	// write the backup metadata, now that we know which images are required
+	data.Images = images
+	if err := dfs.writeBackupMetadata(data, tarOut); err != nil {
+		plog.WithError(err).Error("Unable to write metadata for backup")
+		return err
+	}
 
-		snapshotLogger := backupLogger.WithField("snapshot", snapshot)
+	// export the snapshots
+	for i, snapshot := range data.Snapshots {
+		vol, info := exports[i].vol, exports[i].info
+		parent := data.ParentSnapshots[snapshot]
+		snapshotLogger := backupLogger.WithFields(log.Fields{
+			"snapshot": snapshot,
+			"parent":   parent,
+		})
 
 		// dump the snapshot into the backup
 		prefix := path.Join(SnapshotsMetadataDir, info.TenantID, info.Label)
-		snapReader, errchan := dfs.snapshotSavePipe(vol, info.Label, data.SnapshotExcludes[snapshot])
+		snapReader, errchan := dfs.snapshotSavePipe(vol, info.Label, parent, data.SnapshotExcludes[snapshot])
 		if err := rewriteTar(prefix, tarOut, snapReader); err != nil {
 			// be a good citizen and clean up any running threads
 			<-errchan
@@ -154,9 +169,17 @@ func (dfs *DistributedFilesystem) Backup(data BackupInfo, w io.Writer) error {
 		}).Info("Exported snapshot to backup")
 	}
 
+	// skip any images that are already stored in the backup chain
+	images = missingImages(images, data.ParentImages)
+	imageLogger := backupLogger.WithField("images", images)
+	if len(images) == 0 && data.Parent != "" {
+		tarOut.Close()
+		imageLogger.Info("All images are available from the parent backup")
+		return nil
+	}
+
 	// dump the images from all the snapshots into the backup
 	imageReader, errchan := dfs.dockerSavePipe(images...)
-	imageLogger := backupLogger.WithField("images", images)
 	imageLogger.Info("Starting export of images to backup")
 	if err := rewriteTar(DockerImagesFile, tarOut, imageReader); err != nil {
 		// be a good citizen and clean up any running threads
@@ -193,13 +216,34 @@ func (dfs *DistributedFilesystem) dockerSavePipe(images ...string) (*io.PipeRead
 	})
 }
 
-// snapshotSavePipe returns a pipe that exports a given volume to the pipe's stdout
-func (dfs *DistributedFilesystem) snapshotSavePipe(vol volume.Volume, label string, excludes []string) (*io.PipeReader, <-chan error) {
+// snapshotSavePipe returns a pipe that exports a given volume to the pipe's
+// stdout.  If parent is set, only the changes since the parent snapshot are
+// exported, provided the volume driver supports it.
+func (dfs *DistributedFilesystem) snapshotSavePipe(vol volume.Volume, label, parent string, excludes []string) (*io.PipeReader, <-chan error) {
 	return savePipe(func(w io.Writer) error {
-		return vol.Export(label, "", w, excludes)
+		return vol.Export(label, parent, w, excludes)
 	})
 }
 
+// missingImages returns the images that are not in the list of existing
+// images, preserving order.
+func missingImages(images, existing []string) []string {
+	if len(existing) == 0 {
+		return images
+	}
+	skip := make(map[string]struct{})
+	for _, image := range existing {
+		skip[image] = struct{}{}
+	}
+	var result []string
+	for _, image := range images {
+		if _, ok := skip[image]; !ok {
+			result = append(result, image)
+		}
+	}
+	return result
+}
+
 // rewriteTar interprets an pipe reader as a tar reader and rewrites the
 // headers so they can get written to the outfile.
 func rewriteTar(prefix string, tarWriter *tar.Writer, r *io.PipeReader) error {
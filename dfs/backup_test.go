@@ -194,3 +194,55 @@ func (s *DFSTestSuite) TestBackup(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(buf.Len() > 0, Equals, true)
 }
+
+func (s *DFSTestSuite) TestBackup_Incremental(c *C) {
+	buf := bytes.NewBufferString("")
+	backupInfo := BackupInfo{
+		BaseImages: []string{"library/repo:tag"},
+		Snapshots:  []string{"BASE_LABEL"},
+		Timestamp:  time.Now().UTC(),
+		Parent:     "backup-parent.tgz",
+		ParentSnapshots: map[string]string{
+			"BASE_LABEL": "PARENTLABEL",
+		},
+		ParentImages: []string{"library/repo:tag"},
+	}
+	s.docker.On("FindImage", "library/repo:tag").Return(&dockerclient.Image{}, nil).Once()
+	vol := s.getVolumeFromSnapshot("BASE_LABEL", "BASE")
+	info := &volume.SnapshotInfo{
+		Name:     "BASE_LABEL",
+		TenantID: "BASE",
+		Label:    "LABEL",
+		Created:  time.Now().UTC(),
+	}
+	imagesbuf := bytes.NewBufferString("")
+	err := json.NewEncoder(imagesbuf).Encode([]string{"BASE/repo:tag"})
+	c.Assert(err, IsNil)
+	vol.On("SnapshotInfo", "BASE_LABEL").Return(info, nil)
+	vol.On("ReadMetadata", "LABEL", ImagesMetadataFile).Return(&NopCloser{imagesbuf}, nil)
+	s.registry.On("PullImage", mock.AnythingOfType("<-chan time.Time"), "BASE/repo:tag").Return(nil)
+	s.registry.On("ImagePath", "BASE/repo:tag").Return("testserver:5000/BASE/repo:tag", nil)
+	vol.On("Export", "LABEL", "PARENTLABEL", mock.AnythingOfType("*io.PipeWriter")).Return(nil).Run(func(a mock.Arguments) {
+		writer := a.Get(2).(io.Writer)
+		tarwriter := tar.NewWriter(writer)
+		data := []byte("here is some delta data")
+		hdr := &tar.Header{Name: "afile", Size: int64(len(data))}
+		tarwriter.WriteHeader(hdr)
+		tarwriter.Write(data)
+		tarwriter.Close()
+	})
+	newImages := []string{"testserver:5000/BASE/repo:tag"}
+	s.docker.On("SaveImages", newImages, mock.AnythingOfType("*io.PipeWriter")).Return(nil).Run(func(a mock.Arguments) {
+		writer := a.Get(1).(io.Writer)
+		tarwriter := tar.NewWriter(writer)
+		tarwriter.Close()
+	})
+	err = s.dfs.Backup(backupInfo, buf)
+	c.Assert(err, IsNil)
+
+	// the metadata lists every image required by the backup chain
+	actual, err := s.dfs.BackupInfo(buf)
+	c.Assert(err, IsNil)
+	c.Assert(actual.Parent, Equals, "backup-parent.tgz")
+	c.Assert(actual.Images, DeepEquals, []string{"library/repo:tag", "testserver:5000/BASE/repo:tag"})
+}
@@ -19,11 +19,25 @@ import (
 	"io"
 	"os/exec"
 
+	"github.com/control-center/serviced/dfs/archive"
+	"github.com/control-center/serviced/dfs/target"
 	"github.com/zenoss/glog"
 )
 
-// BackupInfo provides metadata info about the contents of a backup
+// BackupInfo provides metadata info about the contents of a backup.  The
+// backup may be a plain tar stream or a compressed or encrypted archive.
 func (dfs *DistributedFilesystem) BackupInfo(r io.Reader) (*BackupInfo, error) {
+	ar, err := archive.NewReader(r, dfs.archive)
+	if err != nil {
+		glog.Errorf("Could not open backup: %s", err)
+		return nil, err
+	}
+	defer ar.Close()
+	return ReadBackupInfo(ar)
+}
+
+// ReadBackupInfo reads the backup metadata from an uncompressed backup stream.
+func ReadBackupInfo(r io.Reader) (*BackupInfo, error) {
 	tarfile := tar.NewReader(r)
 	for {
 		header, err := tarfile.Next()
@@ -44,6 +58,25 @@ func (dfs *DistributedFilesystem) BackupInfo(r io.Reader) (*BackupInfo, error) {
 	}
 }
 
+// ReadBackupFileInfo reads the backup metadata from a backup in a target.
+// The serialized BackupInfo is stored at the front of the backup, so only the
+// beginning of the backup is read.
+func ReadBackupFileInfo(t target.Target, name string, cfg *archive.Config) (*BackupInfo, error) {
+	r, err := t.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	ar, err := archive.NewReader(r, cfg)
+	if err == archive.ErrNoKey {
+		return nil, err
+	} else if err != nil {
+		return nil, ErrRestoreNoInfo
+	}
+	defer ar.Close()
+	return ReadBackupInfo(ar)
+}
+
 // ExtractBackupInfo extracts the backup metadata from a tarball on disk in as
 // cheaply a manner as possible. The serialized BackupInfo is stored at the
 // front of the tarball to facilitate this.
@@ -58,3 +91,33 @@ func ExtractBackupInfo(filename string) (*BackupInfo, error) {
 	}
 	return &info, nil
 }
+
+// BackupChain returns the backups that need to be restored, oldest first, in
+// order to restore the named backup.  Parent backups are expected to be in the
+// same target as the backup that references them.  The last backup in the
+// chain is always the named backup.
+func BackupChain(t target.Target, name string, cfg *archive.Config) ([]string, []*BackupInfo, error) {
+	var (
+		names []string
+		infos []*BackupInfo
+	)
+	seen := make(map[string]struct{})
+	for {
+		if _, ok := seen[name]; ok {
+			return nil, nil, ErrBackupChainCycle
+		}
+		seen[name] = struct{}{}
+		info, err := ReadBackupFileInfo(t, name, cfg)
+		if err != nil {
+			glog.Errorf("Could not load backup metadata from %s: %s", t.Location(name), err)
+			return nil, nil, err
+		}
+		names = append([]string{name}, names...)
+		infos = append([]*BackupInfo{info}, infos...)
+		if info.Parent == "" {
+			break
+		}
+		name = info.Parent
+	}
+	return names, infos, nil
+}
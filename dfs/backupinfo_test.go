@@ -22,6 +22,8 @@ import (
 	"time"
 
 	. "github.com/control-center/serviced/dfs"
+	"github.com/control-center/serviced/dfs/archive"
+	"github.com/control-center/serviced/dfs/target"
 	"github.com/control-center/serviced/domain/pool"
 	"github.com/control-center/serviced/domain/servicetemplate"
 	. "gopkg.in/check.v1"
@@ -101,3 +103,67 @@ func (s *DFSTestSuite) TestBackupInfo_Success(c *C) {
 	c.Assert(actual, DeepEquals, &expected)
 	c.Assert(err, IsNil)
 }
+
+func (s *DFSTestSuite) writeBackupFile(c *C, t target.Target, name string, info BackupInfo, cfg *archive.Config) {
+	w, err := t.Create(name)
+	c.Assert(err, IsNil)
+	aw, err := archive.NewWriter(w, cfg)
+	c.Assert(err, IsNil)
+	tarfile := tar.NewWriter(aw)
+	s.writeBackupInfo(c, tarfile, info)
+	c.Assert(tarfile.Close(), IsNil)
+	c.Assert(aw.Close(), IsNil)
+	c.Assert(w.Close(), IsNil)
+}
+
+func (s *DFSTestSuite) TestBackupChain(c *C) {
+	t := target.NewLocal(c.MkDir())
+	s.writeBackupFile(c, t, "full.tgz", BackupInfo{BackupVersion: 1}, nil)
+	s.writeBackupFile(c, t, "inc1.tgz", BackupInfo{BackupVersion: 1, Parent: "full.tgz"}, nil)
+	s.writeBackupFile(c, t, "inc2.tgz", BackupInfo{BackupVersion: 1, Parent: "inc1.tgz"}, nil)
+
+	names, infos, err := BackupChain(t, "inc2.tgz", nil)
+	c.Assert(err, IsNil)
+	c.Assert(names, DeepEquals, []string{"full.tgz", "inc1.tgz", "inc2.tgz"})
+	c.Assert(infos, HasLen, 3)
+	c.Assert(infos[2].Parent, Equals, "inc1.tgz")
+
+	names, _, err = BackupChain(t, "full.tgz", nil)
+	c.Assert(err, IsNil)
+	c.Assert(names, DeepEquals, []string{"full.tgz"})
+}
+
+func (s *DFSTestSuite) TestBackupChain_Errors(c *C) {
+	t := target.NewLocal(c.MkDir())
+	s.writeBackupFile(c, t, "orphan.tgz", BackupInfo{BackupVersion: 1, Parent: "missing.tgz"}, nil)
+	_, _, err := BackupChain(t, "orphan.tgz", nil)
+	c.Assert(err, NotNil)
+
+	s.writeBackupFile(c, t, "a.tgz", BackupInfo{BackupVersion: 1, Parent: "b.tgz"}, nil)
+	s.writeBackupFile(c, t, "b.tgz", BackupInfo{BackupVersion: 1, Parent: "a.tgz"}, nil)
+	_, _, err = BackupChain(t, "a.tgz", nil)
+	c.Assert(err, Equals, ErrBackupChainCycle)
+}
+
+func (s *DFSTestSuite) TestBackupChain_Encrypted(c *C) {
+	t := target.NewLocal(c.MkDir())
+	cfg := &archive.Config{Compression: archive.Gzip, Key: bytes.Repeat([]byte{1}, 32)}
+	s.writeBackupFile(c, t, "full.tgz", BackupInfo{BackupVersion: 1}, nil)
+	s.writeBackupFile(c, t, "inc1.tgz.enc", BackupInfo{BackupVersion: 1, Parent: "full.tgz"}, cfg)
+
+	names, _, err := BackupChain(t, "inc1.tgz.enc", cfg)
+	c.Assert(err, IsNil)
+	c.Assert(names, DeepEquals, []string{"full.tgz", "inc1.tgz.enc"})
+
+	_, _, err = BackupChain(t, "inc1.tgz.enc", nil)
+	c.Assert(err, Equals, archive.ErrNoKey)
+
+	r, err := t.Open("inc1.tgz.enc")
+	c.Assert(err, IsNil)
+	defer r.Close()
+	s.dfs.SetArchiveConfig(cfg)
+	defer s.dfs.SetArchiveConfig(nil)
+	info, err := s.dfs.BackupInfo(r)
+	c.Assert(err, IsNil)
+	c.Assert(info.Parent, Equals, "full.tgz")
+}
@@ -19,6 +19,7 @@ import (
 
 	csync "github.com/control-center/serviced/commons/sync"
 	"github.com/control-center/serviced/coordinator/storage"
+	"github.com/control-center/serviced/dfs/archive"
 	"github.com/control-center/serviced/dfs/docker"
 	"github.com/control-center/serviced/dfs/registry"
 	"github.com/control-center/serviced/domain/pool"
@@ -49,6 +50,11 @@ type DFS interface {
 	Snapshot(info SnapshotInfo, SnapshotSpacePercent int) (string, error)
 	// Rollback reverts application to a specific snapshot
 	Rollback(snapshotID string) error
+	// Diff lists the files that changed between a snapshot and the current
+	// application data, or another snapshot of the same application
+	Diff(snapshotID, otherID string) ([]volume.FileChange, error)
+	// RestorePaths restores selected paths of application data from a snapshot
+	RestorePaths(snapshotID string, paths []string) error
 	// Delete deletes an application's snapshot
 	Delete(snapshotID string) error
 	// List lists snapshots for a particular application
@@ -93,6 +99,19 @@ type BackupInfo struct {
 	SnapshotExcludes map[string][]string
 	Timestamp        time.Time
 	BackupVersion    int
+	// Parent is the file name of the backup this backup is incremental to.
+	// Backups without a parent are full backups.
+	Parent string `json:",omitempty"`
+	// ParentSnapshots maps a snapshot in this backup to the label of the
+	// snapshot it was exported against.  Snapshots that are not listed
+	// here were exported in full.
+	ParentSnapshots map[string]string `json:",omitempty"`
+	// ParentImages are the images already stored in the backup chain, and
+	// are not written into this backup.
+	ParentImages []string `json:",omitempty"`
+	// Images are all of the images required to restore this backup,
+	// including those stored in its parents.
+	Images []string `json:",omitempty"`
 }
 
 // SnapshotInfo provides meta info about a snapshot
@@ -115,6 +134,7 @@ type DistributedFilesystem struct {
 	timeout time.Duration
 	locker  *csync.TimedMutex
 	tmp     string // tmp directory where backups are temporarily spooled
+	archive *archive.Config
 }
 
 // ImageInfo provides meta info about a Docker image
@@ -145,3 +165,8 @@ func (dfs *DistributedFilesystem) Timeout() time.Duration {
 func (dfs *DistributedFilesystem) SetTmp(tmp string) {
 	dfs.tmp = tmp
 }
+
+// SetArchiveConfig sets the keys used to read encrypted backups
+func (dfs *DistributedFilesystem) SetArchiveConfig(cfg *archive.Config) {
+	dfs.archive = cfg
+}
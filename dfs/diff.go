@@ -0,0 +1,53 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dfs
+
+import (
+	"errors"
+
+	"github.com/control-center/serviced/volume"
+	"github.com/zenoss/glog"
+)
+
+var (
+	ErrSnapshotTenantMismatch = errors.New("snapshots belong to different tenants")
+)
+
+// Diff lists the files that changed between a snapshot and the current state
+// of the application data, or another snapshot of the same application if
+// otherID is set.
+func (dfs *DistributedFilesystem) Diff(snapshotID, otherID string) ([]volume.FileChange, error) {
+	vol, err := dfs.disk.GetTenant(snapshotID)
+	if err != nil {
+		glog.Errorf("Could not get tenant of snapshot %s: %s", snapshotID, err)
+		return nil, err
+	}
+	if otherID != "" {
+		otherVol, err := dfs.disk.GetTenant(otherID)
+		if err != nil {
+			glog.Errorf("Could not get tenant of snapshot %s: %s", otherID, err)
+			return nil, err
+		}
+		if otherVol.Tenant() != vol.Tenant() {
+			glog.Errorf("Could not compare snapshot %s of tenant %s with snapshot %s of tenant %s", snapshotID, vol.Tenant(), otherID, otherVol.Tenant())
+			return nil, ErrSnapshotTenantMismatch
+		}
+	}
+	changes, err := vol.Diff(snapshotID, otherID)
+	if err != nil {
+		glog.Errorf("Could not diff snapshot %s: %s", snapshotID, err)
+		return nil, err
+	}
+	return changes, nil
+}
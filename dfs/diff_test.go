@@ -0,0 +1,63 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build unit
+
+package dfs_test
+
+import (
+	"errors"
+
+	. "github.com/control-center/serviced/dfs"
+	"github.com/control-center/serviced/volume"
+	volumemocks "github.com/control-center/serviced/volume/mocks"
+	. "gopkg.in/check.v1"
+)
+
+func (s *DFSTestSuite) TestDiff_NoSnapshot(c *C) {
+	s.disk.On("GetTenant", "BASE_LABEL").Return(&volumemocks.Volume{}, volume.ErrVolumeNotExists)
+	changes, err := s.dfs.Diff("BASE_LABEL", "")
+	c.Assert(err, Equals, volume.ErrVolumeNotExists)
+	c.Assert(changes, IsNil)
+}
+
+func (s *DFSTestSuite) TestDiff_Live(c *C) {
+	expected := []volume.FileChange{{Path: "etc/app.conf", Change: volume.FileDeleted}}
+	vol := s.getVolumeFromSnapshot("BASE_LABEL", "BASE")
+	vol.On("Diff", "BASE_LABEL", "").Return(expected, nil)
+	changes, err := s.dfs.Diff("BASE_LABEL", "")
+	c.Assert(err, IsNil)
+	c.Assert(changes, DeepEquals, expected)
+
+	vol.On("Diff", "BASE_LABEL2", "").Return(nil, errors.New("diff failed"))
+	s.disk.On("GetTenant", "BASE_LABEL2").Return(vol, nil)
+	_, err = s.dfs.Diff("BASE_LABEL2", "")
+	c.Assert(err, ErrorMatches, "diff failed")
+}
+
+func (s *DFSTestSuite) TestDiff_Snapshots(c *C) {
+	expected := []volume.FileChange{{Path: "data", Change: volume.FileAdded}}
+	vol := s.getVolumeFromSnapshot("BASE_LABEL", "BASE")
+	vol.On("Tenant").Return("BASE")
+	s.disk.On("GetTenant", "BASE_LABEL2").Return(vol, nil)
+	vol.On("Diff", "BASE_LABEL", "BASE_LABEL2").Return(expected, nil)
+	changes, err := s.dfs.Diff("BASE_LABEL", "BASE_LABEL2")
+	c.Assert(err, IsNil)
+	c.Assert(changes, DeepEquals, expected)
+
+	// snapshots of different tenants cannot be compared
+	other := s.getVolumeFromSnapshot("OTHER_LABEL", "OTHER")
+	other.On("Tenant").Return("OTHER")
+	_, err = s.dfs.Diff("BASE_LABEL", "OTHER_LABEL")
+	c.Assert(err, Equals, ErrSnapshotTenantMismatch)
+}
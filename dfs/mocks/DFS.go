@@ -8,6 +8,7 @@ import "time"
 
 import (
 	"github.com/control-center/serviced/domain/service"
+	"github.com/control-center/serviced/volume"
 )
 
 type DFS struct {
@@ -148,6 +149,43 @@ func (_m *DFS) Rollback(snapshotID string) error {
 	return r0
 }
 
+// Diff provides a mock function with given fields: snapshotID, otherID
+func (_m *DFS) Diff(snapshotID string, otherID string) ([]volume.FileChange, error) {
+	ret := _m.Called(snapshotID, otherID)
+
+	var r0 []volume.FileChange
+	if rf, ok := ret.Get(0).(func(string, string) []volume.FileChange); ok {
+		r0 = rf(snapshotID, otherID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]volume.FileChange)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(snapshotID, otherID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RestorePaths provides a mock function with given fields: snapshotID, paths
+func (_m *DFS) RestorePaths(snapshotID string, paths []string) error {
+	ret := _m.Called(snapshotID, paths)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, []string) error); ok {
+		r0 = rf(snapshotID, paths)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Delete provides a mock function with given fields: snapshotID
 func (_m *DFS) Delete(snapshotID string) error {
 	ret := _m.Called(snapshotID)
@@ -27,6 +27,7 @@ import (
 var (
 	ErrRestoreNoInfo        = errors.New("backup is missing metadata")
 	ErrInvalidBackupVersion = errors.New("backup has an invalid version")
+	ErrBackupChainCycle     = errors.New("backup chain refers back to itself")
 )
 
 // Restore restores application data from a backup.
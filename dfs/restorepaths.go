@@ -0,0 +1,30 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dfs
+
+import "github.com/zenoss/glog"
+
+// RestorePaths replaces the given paths of the application data with their
+// contents in a snapshot, without touching the rest of the application data.
+func (dfs *DistributedFilesystem) RestorePaths(snapshotID string, paths []string) error {
+	vol, info, err := dfs.getSnapshotVolumeAndInfo(snapshotID)
+	if err != nil {
+		return err
+	}
+	if err := vol.RestorePaths(info.Label, paths); err != nil {
+		glog.Errorf("Could not restore paths %v from snapshot %s for tenant %s: %s", paths, snapshotID, info.TenantID, err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build unit
+
+package dfs_test
+
+import (
+	"errors"
+
+	"github.com/control-center/serviced/volume"
+	volumemocks "github.com/control-center/serviced/volume/mocks"
+	. "gopkg.in/check.v1"
+)
+
+func (s *DFSTestSuite) TestRestorePaths_NoSnapshot(c *C) {
+	s.disk.On("GetTenant", "BASE_LABEL").Return(&volumemocks.Volume{}, volume.ErrVolumeNotExists)
+	err := s.dfs.RestorePaths("BASE_LABEL", []string{"etc"})
+	c.Assert(err, Equals, volume.ErrVolumeNotExists)
+
+	vol := s.getVolumeFromSnapshot("BASE2_LABEL2", "BASE2")
+	vol.On("SnapshotInfo", "BASE2_LABEL2").Return(nil, volume.ErrInvalidSnapshot)
+	err = s.dfs.RestorePaths("BASE2_LABEL2", []string{"etc"})
+	c.Assert(err, Equals, volume.ErrInvalidSnapshot)
+}
+
+func (s *DFSTestSuite) TestRestorePaths(c *C) {
+	vinfo := &volume.SnapshotInfo{
+		Name:     "BASE_LABEL",
+		TenantID: "BASE",
+		Label:    "LABEL",
+	}
+	vol := s.getVolumeFromSnapshot("BASE_LABEL", "BASE")
+	vol.On("SnapshotInfo", "BASE_LABEL").Return(vinfo, nil)
+	vol.On("RestorePaths", "LABEL", []string{"etc", "data/file"}).Return(nil).Once()
+	err := s.dfs.RestorePaths("BASE_LABEL", []string{"etc", "data/file"})
+	c.Assert(err, IsNil)
+
+	vol.On("RestorePaths", "LABEL", []string{"missing"}).Return(errors.New("restore failed")).Once()
+	err = s.dfs.RestorePaths("BASE_LABEL", []string{"missing"})
+	c.Assert(err, ErrorMatches, "restore failed")
+}
@@ -0,0 +1,85 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package target
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Local stores backups in a directory on the local filesystem
+type Local struct {
+	Dir string
+}
+
+// NewLocal returns a target for the given directory
+func NewLocal(dir string) *Local {
+	return &Local{Dir: dir}
+}
+
+// Create implements Target
+func (l *Local) Create(name string) (Writer, error) {
+	fh, err := os.Create(l.Location(name))
+	if err != nil {
+		return nil, err
+	}
+	return &localWriter{File: fh}, nil
+}
+
+// Open implements Target
+func (l *Local) Open(name string) (io.ReadCloser, error) {
+	return os.Open(l.Location(name))
+}
+
+// List implements Target
+func (l *Local) List() ([]File, error) {
+	dir, err := os.Open(l.Dir)
+	if err != nil {
+		return nil, err
+	}
+	defer dir.Close()
+	fis, err := dir.Readdir(0)
+	if err != nil {
+		return nil, err
+	}
+	files := []File{}
+	for _, fi := range fis {
+		if fi.IsDir() {
+			continue
+		}
+		files = append(files, File{Name: fi.Name(), Size: fi.Size(), Mode: fi.Mode(), ModTime: fi.ModTime()})
+	}
+	return files, nil
+}
+
+// Remove implements Target
+func (l *Local) Remove(name string) error {
+	return os.Remove(l.Location(name))
+}
+
+// Location implements Target
+func (l *Local) Location(name string) string {
+	return filepath.Join(l.Dir, name)
+}
+
+type localWriter struct {
+	*os.File
+}
+
+// Abort closes and removes the partially written file
+func (w *localWriter) Abort() error {
+	w.File.Close()
+	return os.Remove(w.File.Name())
+}
@@ -0,0 +1,505 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package target
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+const (
+	// DefaultS3Region is used when no region is configured
+	DefaultS3Region = "us-east-1"
+	// DefaultPartSize is the size of each part of a multipart upload
+	DefaultPartSize = 16 * 1024 * 1024
+	// MinPartSize is the smallest part size accepted by S3
+	MinPartSize = 5 * 1024 * 1024
+	// MaxPartSize is the largest part size accepted by S3
+	MaxPartSize = 5 * 1024 * 1024 * 1024
+	// MaxParts is the largest number of parts in a multipart upload
+	MaxParts = 10000
+
+	// partRetries is how many times a failed part is uploaded again
+	partRetries = 3
+
+	amzDateFormat = "20060102T150405Z"
+	emptyHash     = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+var (
+	// ErrS3MissingBucket is returned when no bucket is configured
+	ErrS3MissingBucket = errors.New("s3 target requires a bucket")
+	// ErrS3MissingEndpoint is returned when no endpoint is configured
+	ErrS3MissingEndpoint = errors.New("s3 target requires an endpoint")
+	// ErrWriterClosed is returned when writing to a closed backup
+	ErrWriterClosed = errors.New("backup writer is closed")
+	// ErrS3TooManyParts is returned when a backup is larger than can be
+	// uploaded with the configured part size
+	ErrS3TooManyParts = errors.New("backup is too large for the s3 part size")
+)
+
+// S3Config describes an S3-compatible object store
+type S3Config struct {
+	Endpoint        string // e.g. https://s3.amazonaws.com or http://minio:9000
+	Region          string
+	Bucket          string
+	Prefix          string
+	AccessKeyID     string
+	SecretAccessKey string
+	PartSize        int64
+}
+
+// S3Error is an error response returned by the object store
+type S3Error struct {
+	StatusCode int    `xml:"-"`
+	Code       string `xml:"Code"`
+	Message    string `xml:"Message"`
+}
+
+func (e *S3Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("s3 request failed with status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("s3 request failed with status %d: %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// S3 stores backups in a bucket of an S3-compatible object store.  Objects
+// are addressed path-style, so that stores such as MinIO work without any
+// DNS configuration.
+type S3 struct {
+	config    S3Config
+	endpoint  *url.URL
+	client    *http.Client
+	now       func() time.Time
+	maxParts  int
+	retryWait time.Duration
+}
+
+// NewS3 returns a target for an S3-compatible object store
+func NewS3(config S3Config) (*S3, error) {
+	if config.Bucket == "" {
+		return nil, ErrS3MissingBucket
+	}
+	if config.Endpoint == "" {
+		return nil, ErrS3MissingEndpoint
+	}
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return nil, ErrInvalidTarget
+	}
+	if config.Region == "" {
+		config.Region = DefaultS3Region
+	}
+	if config.PartSize == 0 {
+		config.PartSize = DefaultPartSize
+	} else if config.PartSize < MinPartSize {
+		config.PartSize = MinPartSize
+	} else if config.PartSize > MaxPartSize {
+		config.PartSize = MaxPartSize
+	}
+	config.Prefix = strings.Trim(config.Prefix, "/")
+	return &S3{
+		config:    config,
+		endpoint:  endpoint,
+		client:    newS3Client(),
+		now:       time.Now,
+		maxParts:  MaxParts,
+		retryWait: time.Second,
+	}, nil
+}
+
+// newS3Client returns an http client that gives up on unresponsive object
+// stores.  There is no overall timeout, because downloading a backup can
+// take as long as it takes.
+func newS3Client() *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			Dial: (&net.Dialer{
+				Timeout:   30 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).Dial,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ResponseHeaderTimeout: 5 * time.Minute,
+			ExpectContinueTimeout: time.Second,
+		},
+	}
+}
+
+// MaxSize returns the size of the largest backup that can be uploaded with
+// the configured part size.
+func (s *S3) MaxSize() int64 {
+	return s.config.PartSize * int64(s.maxParts)
+}
+
+// Create implements Target.  The backup is streamed to the object store in
+// parts of PartSize bytes, so it never has to be staged on local disk.
+func (s *S3) Create(name string) (Writer, error) {
+	return &s3Writer{s3: s, key: s.key(name), buf: &bytes.Buffer{}}, nil
+}
+
+// Open implements Target
+func (s *S3) Open(name string) (io.ReadCloser, error) {
+	resp, err := s.do("GET", s.key(name), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+type listBucketResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List implements Target
+func (s *S3) List() ([]File, error) {
+	files := []File{}
+	prefix := ""
+	if s.config.Prefix != "" {
+		prefix = s.config.Prefix + "/"
+	}
+	query := url.Values{"list-type": {"2"}, "prefix": {prefix}, "delimiter": {"/"}}
+	for {
+		resp, err := s.do("GET", "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+		var result listBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range result.Contents {
+			name := strings.TrimPrefix(obj.Key, prefix)
+			if name == "" {
+				continue
+			}
+			files = append(files, File{Name: name, Size: obj.Size, ModTime: obj.LastModified})
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			break
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+	return files, nil
+}
+
+// Remove implements Target
+func (s *S3) Remove(name string) error {
+	resp, err := s.do("DELETE", s.key(name), nil, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Location implements Target
+func (s *S3) Location(name string) string {
+	return fmt.Sprintf("s3://%s/%s", s.config.Bucket, s.key(name))
+}
+
+// key returns the object key of a backup
+func (s *S3) key(name string) string {
+	if s.config.Prefix == "" {
+		return name
+	}
+	return path.Join(s.config.Prefix, name)
+}
+
+// do sends a signed request for an object in the bucket, and returns an
+// S3Error if the response is not successful.
+func (s *S3) do(method, key string, query url.Values, body []byte) (*http.Response, error) {
+	u := *s.endpoint
+	u.Path = "/" + s.config.Bucket
+	if key != "" {
+		u.Path += "/" + key
+	}
+	u.RawPath = uriEncode(u.Path, false)
+	u.RawQuery = canonicalQuery(query)
+	var reader io.Reader
+	if len(body) > 0 {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, u.String(), reader)
+	if err != nil {
+		return nil, err
+	}
+	s.sign(req, body)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		s3err := &S3Error{StatusCode: resp.StatusCode}
+		data, _ := ioutil.ReadAll(resp.Body)
+		xml.Unmarshal(data, s3err)
+		return nil, s3err
+	}
+	return resp, nil
+}
+
+// sign adds an AWS Signature Version 4 authorization header to the request
+func (s *S3) sign(req *http.Request, body []byte) {
+	now := s.now().UTC()
+	amzDate := now.Format(amzDateFormat)
+	payloadHash := emptyHash
+	if len(body) > 0 {
+		sum := sha256.Sum256(body)
+		payloadHash = hex.EncodeToString(sum[:])
+	}
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		"host:" + req.URL.Host,
+		"x-amz-content-sha256:" + payloadHash,
+		"x-amz-date:" + amzDate,
+		"",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	date := now.Format("20060102")
+	scope := strings.Join([]string{date, s.config.Region, "s3", "aws4_request"}, "/")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.config.SecretAccessKey), date)
+	key = hmacSHA256(key, s.config.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.config.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// uriEncode escapes a string as required by Signature Version 4
+func uriEncode(s string, encodeSlash bool) string {
+	var buf bytes.Buffer
+	for _, b := range []byte(s) {
+		switch {
+		case b >= 'A' && b <= 'Z', b >= 'a' && b <= 'z', b >= '0' && b <= '9',
+			b == '-', b == '_', b == '.', b == '~':
+			buf.WriteByte(b)
+		case b == '/' && !encodeSlash:
+			buf.WriteByte(b)
+		default:
+			fmt.Fprintf(&buf, "%%%02X", b)
+		}
+	}
+	return buf.String()
+}
+
+// canonicalQuery encodes query parameters sorted by key
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		for _, v := range query[k] {
+			parts = append(parts, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+type completedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+// s3Writer buffers a backup into parts and uploads them as they fill up.
+// Backups smaller than a single part are written with a plain PUT.
+type s3Writer struct {
+	s3       *S3
+	key      string
+	buf      *bytes.Buffer
+	uploadID string
+	parts    []completedPart
+	closed   bool
+}
+
+// Write implements io.Writer
+func (w *s3Writer) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, ErrWriterClosed
+	}
+	n, _ := w.buf.Write(p)
+	for int64(w.buf.Len()) >= w.s3.config.PartSize {
+		// the last part is uploaded when the backup is closed
+		if len(w.parts)+1 >= w.s3.maxParts {
+			return n, ErrS3TooManyParts
+		}
+		if err := w.uploadPart(w.buf.Next(int(w.s3.config.PartSize))); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// Close uploads the remaining data and completes the upload
+func (w *s3Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if w.uploadID == "" {
+		resp, err := w.s3.do("PUT", w.key, nil, w.buf.Bytes())
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return nil
+	}
+	if w.buf.Len() > 0 {
+		if err := w.uploadPart(w.buf.Bytes()); err != nil {
+			w.Abort()
+			return err
+		}
+	}
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+		Parts   []completedPart `xml:"Part"`
+	}{Parts: w.parts})
+	if err != nil {
+		w.Abort()
+		return err
+	}
+	resp, err := w.s3.do("POST", w.key, url.Values{"uploadId": {w.uploadID}}, body)
+	if err != nil {
+		w.Abort()
+		return err
+	}
+	resp.Body.Close()
+	plog.WithFields(log.Fields{
+		"key":   w.key,
+		"parts": len(w.parts),
+	}).Debug("Completed multipart upload")
+	return nil
+}
+
+// Abort cancels the upload and discards any parts that were uploaded
+func (w *s3Writer) Abort() error {
+	w.closed = true
+	if w.uploadID == "" {
+		return nil
+	}
+	resp, err := w.s3.do("DELETE", w.key, url.Values{"uploadId": {w.uploadID}}, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// uploadPart uploads the next part, starting the multipart upload if it
+// hasn't been started yet.
+func (w *s3Writer) uploadPart(data []byte) error {
+	if w.uploadID == "" {
+		resp, err := w.s3.do("POST", w.key, url.Values{"uploads": {""}}, nil)
+		if err != nil {
+			return err
+		}
+		var result struct {
+			UploadID string `xml:"UploadId"`
+		}
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+		w.uploadID = result.UploadID
+	}
+	number := len(w.parts) + 1
+	query := url.Values{
+		"partNumber": {strconv.Itoa(number)},
+		"uploadId":   {w.uploadID},
+	}
+	var (
+		resp *http.Response
+		err  error
+	)
+	for attempt := 0; attempt <= partRetries; attempt++ {
+		if attempt > 0 {
+			plog.WithFields(log.Fields{
+				"key":     w.key,
+				"part":    number,
+				"attempt": attempt,
+			}).WithError(err).Warn("Retrying upload of part")
+			time.Sleep(time.Duration(attempt) * w.s3.retryWait)
+		}
+		if resp, err = w.s3.do("PUT", w.key, query, data); err == nil || !retryable(err) {
+			break
+		}
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	w.parts = append(w.parts, completedPart{PartNumber: number, ETag: resp.Header.Get("ETag")})
+	return nil
+}
+
+// retryable returns true if a request may succeed if it is sent again
+func retryable(err error) bool {
+	if s3err, ok := err.(*S3Error); ok {
+		return s3err.StatusCode >= 500 || s3err.StatusCode == http.StatusTooManyRequests
+	}
+	return true
+}
@@ -0,0 +1,154 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package target provides the storage locations that backups are written to
+// and restored from.
+package target
+
+import (
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/control-center/serviced/logging"
+)
+
+var (
+	plog = logging.PackageLogger()
+
+	// ErrInvalidTarget is returned when a target location cannot be parsed
+	ErrInvalidTarget = errors.New("invalid backup target")
+)
+
+// File describes a backup stored in a target
+type File struct {
+	Name    string
+	Size    int64
+	Mode    os.FileMode
+	ModTime time.Time
+}
+
+// Writer writes a new backup into a target.  Close commits the backup; Abort
+// discards everything that was written.
+type Writer interface {
+	io.WriteCloser
+	Abort() error
+}
+
+// Target is a location where backups are stored
+type Target interface {
+	// Create starts writing a new backup with the given name
+	Create(name string) (Writer, error)
+	// Open reads an existing backup
+	Open(name string) (io.ReadCloser, error)
+	// List returns the backups in the target
+	List() ([]File, error)
+	// Remove deletes a backup from the target
+	Remove(name string) error
+	// Location returns the full location of a backup, for display
+	Location(name string) string
+}
+
+// New returns the target for the given location.  Locations of the form
+// s3://bucket/prefix are stored in an S3-compatible object store, and
+// anything else is treated as a local directory.
+func New(location string, config S3Config) (Target, error) {
+	if !strings.HasPrefix(location, "s3://") {
+		return NewLocal(location), nil
+	}
+	u, err := url.Parse(location)
+	if err != nil || u.Host == "" {
+		return nil, ErrInvalidTarget
+	}
+	config.Bucket = u.Host
+	config.Prefix = strings.Trim(u.Path, "/")
+	return NewS3(config)
+}
+
+// Split splits the full location of a backup into the location of its target
+// and the name of the backup.  For s3:// locations the name is the last
+// element of the object key, and the target keeps the bucket and the rest of
+// the key as its prefix.
+func Split(location string) (string, string, error) {
+	if !strings.HasPrefix(location, "s3://") {
+		return filepath.Dir(location), filepath.Base(location), nil
+	}
+	u, err := url.Parse(location)
+	if err != nil || u.Host == "" {
+		return "", "", ErrInvalidTarget
+	}
+	key := strings.Trim(u.Path, "/")
+	if key == "" {
+		return "", "", ErrInvalidTarget
+	}
+	prefix, name := path.Split(key)
+	dir := url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/" + strings.TrimSuffix(prefix, "/")}
+	return strings.TrimSuffix(dir.String(), "/"), name, nil
+}
+
+type byModTime []File
+
+func (f byModTime) Len() int           { return len(f) }
+func (f byModTime) Less(i, j int) bool { return f[i].ModTime.Before(f[j].ModTime) }
+func (f byModTime) Swap(i, j int)      { f[i], f[j] = f[j], f[i] }
+
+// Prune removes all but the newest keep backups from the target and returns
+// the names of the backups that were removed.  Only files accepted by
+// isBackup are considered, and other files in the target are left alone.
+// Backups that a kept backup depends on, as reported by parents, are kept as
+// well.
+func Prune(t Target, keep int, isBackup func(name string) bool, parents func(name string) []string) ([]string, error) {
+	all, err := t.List()
+	if err != nil {
+		return nil, err
+	}
+	files := []File{}
+	for _, f := range all {
+		if isBackup == nil || isBackup(f.Name) {
+			files = append(files, f)
+		}
+	}
+	removed := []string{}
+	if len(files) <= keep {
+		return removed, nil
+	}
+	sort.Sort(byModTime(files))
+	inUse := make(map[string]struct{})
+	for _, f := range files[len(files)-keep:] {
+		if parents == nil {
+			continue
+		}
+		for _, name := range parents(f.Name) {
+			inUse[name] = struct{}{}
+		}
+	}
+	for _, f := range files[:len(files)-keep] {
+		if _, ok := inUse[f.Name]; ok {
+			plog.WithField("backup", t.Location(f.Name)).Debug("Keeping backup required by a newer backup")
+			continue
+		}
+		if err := t.Remove(f.Name); err != nil {
+			plog.WithError(err).WithField("backup", t.Location(f.Name)).Error("Could not remove backup")
+			return removed, err
+		}
+		plog.WithField("backup", t.Location(f.Name)).Info("Pruned backup")
+		removed = append(removed, f.Name)
+	}
+	return removed, nil
+}
@@ -0,0 +1,212 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ttl
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/control-center/serviced/dao"
+	"github.com/control-center/serviced/datastore"
+	"github.com/control-center/serviced/domain/snapshotpolicy"
+)
+
+// SnapshotPolicyStore looks up snapshot policies and records when they ran
+type SnapshotPolicyStore interface {
+	// GetSnapshotPolicies returns the snapshot policies of all tenants
+	GetSnapshotPolicies(ctx datastore.Context) ([]snapshotpolicy.SnapshotPolicy, error)
+	// SetSnapshotPolicyLastRun records when the policy of a tenant last ran
+	SetSnapshotPolicyLastRun(ctx datastore.Context, tenantID string, lastRun time.Time) error
+}
+
+// SnapshotPolicyInterface is the client handler for SnapshotPolicyRunner
+type SnapshotPolicyInterface interface {
+	// Snapshot takes a snapshot of an application
+	Snapshot(dao.SnapshotRequest, *string) error
+	// ListSnapshots returns the list of all snapshots given a service id
+	ListSnapshots(string, *[]dao.SnapshotInfo) error
+	// DeleteSnapshot deletes a snapshot by SnapshotID
+	DeleteSnapshot(string, *int) error
+}
+
+// SnapshotPolicyRunner takes the snapshots of tenants as their policies are
+// due, and removes the snapshots that the policies no longer keep
+type SnapshotPolicyRunner struct {
+	store        SnapshotPolicyStore
+	client       SnapshotPolicyInterface
+	spacePercent int // used by policies that do not set MaxSpacePercent
+}
+
+// RunSnapshotPolicies runs the snapshot policies every minute until cancel is
+// closed
+func RunSnapshotPolicies(store SnapshotPolicyStore, client SnapshotPolicyInterface, cancel <-chan interface{}, spacePercent int) {
+	runner := &SnapshotPolicyRunner{store: store, client: client, spacePercent: spacePercent}
+	plog.Info("Started running snapshot policies")
+	defer plog.Info("Stopped running snapshot policies")
+
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			if err := runner.Run(now); err != nil {
+				plog.WithError(err).Warn("Unable to run snapshot policies")
+			}
+		case <-cancel:
+			return
+		}
+	}
+}
+
+// Run takes a snapshot of each tenant whose policy is due at the given time.
+// A policy that missed several runs, e.g. while the master was down, only
+// takes one snapshot.
+func (r *SnapshotPolicyRunner) Run(now time.Time) error {
+	ctx := datastore.Get()
+	defer ctx.Metrics().Stop(ctx.Metrics().Start("SnapshotPolicyRunner.Run"))
+
+	policies, err := r.store.GetSnapshotPolicies(ctx)
+	if err != nil {
+		return err
+	}
+	for i := range policies {
+		policy := &policies[i]
+		logger := plog.WithFields(log.Fields{
+			"tenantid": policy.TenantID,
+			"schedule": policy.Schedule,
+		})
+		next, err := policy.Next()
+		if err != nil {
+			logger.WithError(err).Warn("Skipping snapshot policy")
+			continue
+		} else if next.IsZero() || next.After(now) {
+			continue
+		}
+
+		// record the run first, so that a failing snapshot is not retried
+		// every minute until the next scheduled run
+		if err := r.store.SetSnapshotPolicyLastRun(ctx, policy.TenantID, now); err != nil {
+			logger.WithError(err).Warn("Could not update snapshot policy")
+			continue
+		}
+		if err := r.snapshot(policy); err != nil {
+			logger.WithError(err).Warn("Could not take scheduled snapshot")
+			continue
+		}
+		if err := r.prune(policy); err != nil {
+			logger.WithError(err).Warn("Could not remove expired scheduled snapshots")
+		}
+	}
+	return nil
+}
+
+// snapshot takes a snapshot of the tenant of a policy
+func (r *SnapshotPolicyRunner) snapshot(policy *snapshotpolicy.SnapshotPolicy) error {
+	spacePercent := policy.MaxSpacePercent
+	if spacePercent == 0 {
+		spacePercent = r.spacePercent
+	}
+	req := dao.SnapshotRequest{
+		ServiceID:            policy.TenantID,
+		Message:              snapshotpolicy.Message,
+		SnapshotSpacePercent: spacePercent,
+		SkipQuiesce:          !policy.Quiesce,
+	}
+	var snapshotID string
+	if err := r.client.Snapshot(req, &snapshotID); err != nil {
+		return err
+	}
+	plog.WithFields(log.Fields{
+		"tenantid":   policy.TenantID,
+		"snapshotid": snapshotID,
+		"quiesce":    policy.Quiesce,
+	}).Info("Took scheduled snapshot")
+	return nil
+}
+
+// prune deletes the scheduled snapshots of the tenant of a policy that the
+// policy no longer keeps
+func (r *SnapshotPolicyRunner) prune(policy *snapshotpolicy.SnapshotPolicy) error {
+	var snapshots []dao.SnapshotInfo
+	if err := r.client.ListSnapshots(policy.TenantID, &snapshots); err != nil {
+		return err
+	}
+	for _, s := range ExpiredSnapshots(policy, snapshots) {
+		if err := r.client.DeleteSnapshot(s.SnapshotID, nil); err != nil {
+			return err
+		}
+		plog.WithFields(log.Fields{
+			"tenantid":   policy.TenantID,
+			"snapshotid": s.SnapshotID,
+		}).Info("Deleted expired scheduled snapshot")
+	}
+	return nil
+}
+
+// retentionTiers bucket the time of a snapshot by hour, day and week
+var retentionTiers = []func(t time.Time) string{
+	func(t time.Time) string { return t.Format("2006010215") },
+	func(t time.Time) string { return t.Format("20060102") },
+	func(t time.Time) string {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-%d", year, week)
+	},
+}
+
+// ExpiredSnapshots returns the scheduled snapshots that a policy no longer
+// keeps.  For each tier, the newest snapshot of each of the latest KeepHourly
+// hours, KeepDaily days and KeepWeekly weeks is kept; a snapshot is kept if
+// any tier keeps it.  Tagged snapshots and snapshots that were not taken by a
+// policy never expire.
+func ExpiredSnapshots(policy *snapshotpolicy.SnapshotPolicy, snapshots []dao.SnapshotInfo) []dao.SnapshotInfo {
+	scheduled := []dao.SnapshotInfo{}
+	for _, s := range snapshots {
+		if s.Description == snapshotpolicy.Message && len(s.Tags) == 0 && !s.Invalid {
+			scheduled = append(scheduled, s)
+		}
+	}
+	sort.Sort(sort.Reverse(snapshotsByCreated(scheduled)))
+
+	kept := make(map[string]bool)
+	for i, keep := range []int{policy.KeepHourly, policy.KeepDaily, policy.KeepWeekly} {
+		buckets := make(map[string]bool)
+		for _, s := range scheduled {
+			if len(buckets) >= keep {
+				break
+			}
+			bucket := retentionTiers[i](s.Created.Local())
+			if !buckets[bucket] {
+				buckets[bucket] = true
+				kept[s.SnapshotID] = true
+			}
+		}
+	}
+
+	expired := []dao.SnapshotInfo{}
+	for _, s := range scheduled {
+		if !kept[s.SnapshotID] {
+			expired = append(expired, s)
+		}
+	}
+	return expired
+}
+
+// snapshotsByCreated sorts snapshots from oldest to newest
+type snapshotsByCreated []dao.SnapshotInfo
+
+func (s snapshotsByCreated) Len() int           { return len(s) }
+func (s snapshotsByCreated) Less(i, j int) bool { return s[i].Created.Before(s[j].Created) }
+func (s snapshotsByCreated) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
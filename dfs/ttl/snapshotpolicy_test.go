@@ -0,0 +1,198 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build unit
+
+package ttl
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/control-center/serviced/dao"
+	"github.com/control-center/serviced/datastore"
+	datastoreMocks "github.com/control-center/serviced/datastore/mocks"
+	"github.com/control-center/serviced/domain/snapshotpolicy"
+)
+
+var _ = Suite(&SnapshotPolicyTestSuite{})
+
+type SnapshotPolicyTestSuite struct {
+	store  *TestSnapshotPolicyStore
+	client *TestSnapshotPolicyInterface
+	runner *SnapshotPolicyRunner
+}
+
+type TestSnapshotPolicyStore struct {
+	policies []snapshotpolicy.SnapshotPolicy
+}
+
+func (store *TestSnapshotPolicyStore) GetSnapshotPolicies(ctx datastore.Context) ([]snapshotpolicy.SnapshotPolicy, error) {
+	return store.policies, nil
+}
+
+func (store *TestSnapshotPolicyStore) SetSnapshotPolicyLastRun(ctx datastore.Context, tenantID string, lastRun time.Time) error {
+	for i := range store.policies {
+		if store.policies[i].TenantID == tenantID {
+			store.policies[i].LastRun = lastRun
+			return nil
+		}
+	}
+	return errors.New("policy not found")
+}
+
+type TestSnapshotPolicyInterface struct {
+	requests []dao.SnapshotRequest
+	snaps    []dao.SnapshotInfo
+	now      time.Time
+	fail     bool
+}
+
+func (iface *TestSnapshotPolicyInterface) Snapshot(req dao.SnapshotRequest, snapshotID *string) error {
+	if iface.fail {
+		return errors.New("error")
+	}
+	iface.requests = append(iface.requests, req)
+	*snapshotID = fmt.Sprintf("%s_%d", req.ServiceID, len(iface.requests))
+	iface.snaps = append(iface.snaps, dao.SnapshotInfo{
+		SnapshotID:  *snapshotID,
+		TenantID:    req.ServiceID,
+		Description: req.Message,
+		Created:     iface.now,
+	})
+	return nil
+}
+
+func (iface *TestSnapshotPolicyInterface) ListSnapshots(tenantID string, snaps *[]dao.SnapshotInfo) error {
+	*snaps = append([]dao.SnapshotInfo{}, iface.snaps...)
+	return nil
+}
+
+func (iface *TestSnapshotPolicyInterface) DeleteSnapshot(snapshotID string, _ *int) error {
+	for i, snap := range iface.snaps {
+		if snap.SnapshotID == snapshotID {
+			iface.snaps = append(iface.snaps[:i], iface.snaps[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New("snapshot not found")
+}
+
+func (s *SnapshotPolicyTestSuite) SetUpTest(c *C) {
+	datastore.Register(&datastoreMocks.Driver{})
+	s.store = &TestSnapshotPolicyStore{}
+	s.client = &TestSnapshotPolicyInterface{}
+	s.runner = &SnapshotPolicyRunner{store: s.store, client: s.client, spacePercent: 50}
+}
+
+func localTime(day, hour, minute int) time.Time {
+	return time.Date(2018, time.March, day, hour, minute, 0, 0, time.Local)
+}
+
+func (s *SnapshotPolicyTestSuite) TestRun_NotDue(c *C) {
+	s.store.policies = []snapshotpolicy.SnapshotPolicy{
+		{TenantID: "tenant", Schedule: "@daily", KeepDaily: 1, LastRun: localTime(14, 0, 0)},
+	}
+	c.Assert(s.runner.Run(localTime(14, 23, 59)), IsNil)
+	c.Assert(s.client.requests, HasLen, 0)
+	c.Assert(s.store.policies[0].LastRun, Equals, localTime(14, 0, 0))
+}
+
+func (s *SnapshotPolicyTestSuite) TestRun_Due(c *C) {
+	s.store.policies = []snapshotpolicy.SnapshotPolicy{
+		{TenantID: "quiesced", Schedule: "@daily", Quiesce: true, KeepDaily: 1, LastRun: localTime(14, 0, 0)},
+		{TenantID: "live", Schedule: "@hourly", KeepHourly: 1, MaxSpacePercent: 20, LastRun: localTime(14, 0, 0)},
+		{TenantID: "invalid", Schedule: "sometimes", KeepDaily: 1},
+	}
+	now := localTime(15, 0, 0)
+	c.Assert(s.runner.Run(now), IsNil)
+	c.Assert(s.client.requests, DeepEquals, []dao.SnapshotRequest{
+		{ServiceID: "quiesced", Message: snapshotpolicy.Message, SnapshotSpacePercent: 50},
+		{ServiceID: "live", Message: snapshotpolicy.Message, SnapshotSpacePercent: 20, SkipQuiesce: true},
+	})
+	c.Assert(s.store.policies[0].LastRun, Equals, now)
+	c.Assert(s.store.policies[1].LastRun, Equals, now)
+
+	// the missed hourly runs only take one snapshot
+	c.Assert(s.runner.Run(now.Add(time.Minute)), IsNil)
+	c.Assert(s.client.requests, HasLen, 2)
+}
+
+func (s *SnapshotPolicyTestSuite) TestRun_SnapshotFails(c *C) {
+	s.store.policies = []snapshotpolicy.SnapshotPolicy{
+		{TenantID: "tenant", Schedule: "@hourly", KeepHourly: 1, LastRun: localTime(14, 0, 0)},
+	}
+	s.client.fail = true
+	now := localTime(14, 1, 0)
+	c.Assert(s.runner.Run(now), IsNil)
+	// wait for the next scheduled run before trying again
+	c.Assert(s.store.policies[0].LastRun, Equals, now)
+}
+
+func (s *SnapshotPolicyTestSuite) TestRun_Prune(c *C) {
+	s.store.policies = []snapshotpolicy.SnapshotPolicy{
+		{TenantID: "tenant", Schedule: "@hourly", KeepHourly: 2, LastRun: localTime(14, 0, 0)},
+	}
+	s.client.snaps = []dao.SnapshotInfo{
+		{SnapshotID: "manual", Description: "before upgrade", Created: localTime(13, 0, 0)},
+		{SnapshotID: "tagged", Description: snapshotpolicy.Message, Tags: []string{"keep"}, Created: localTime(13, 0, 0)},
+	}
+	for hour := 1; hour <= 4; hour++ {
+		s.client.now = localTime(14, hour, 0)
+		c.Assert(s.runner.Run(s.client.now), IsNil)
+	}
+	ids := []string{}
+	for _, snap := range s.client.snaps {
+		ids = append(ids, snap.SnapshotID)
+	}
+	c.Assert(ids, DeepEquals, []string{"manual", "tagged", "tenant_3", "tenant_4"})
+}
+
+func (s *SnapshotPolicyTestSuite) TestExpiredSnapshots(c *C) {
+	policy := &snapshotpolicy.SnapshotPolicy{KeepHourly: 2, KeepDaily: 3, KeepWeekly: 2}
+	snaps := []dao.SnapshotInfo{}
+	// two snapshots a day, at 6:00 and 18:00, from Monday the 5th to
+	// Thursday the 15th
+	for day := 5; day <= 15; day++ {
+		for _, hour := range []int{6, 18} {
+			snaps = append(snaps, dao.SnapshotInfo{
+				SnapshotID:  fmt.Sprintf("%02d-%02d", day, hour),
+				Description: snapshotpolicy.Message,
+				Created:     localTime(day, hour, 0),
+			})
+		}
+	}
+	snaps = append(snaps, dao.SnapshotInfo{SnapshotID: "invalid", Invalid: true})
+
+	expired := make(map[string]bool)
+	for _, snap := range ExpiredSnapshots(policy, snaps) {
+		expired[snap.SnapshotID] = true
+	}
+	kept := []string{}
+	for _, snap := range snaps {
+		if !expired[snap.SnapshotID] {
+			kept = append(kept, snap.SnapshotID)
+		}
+	}
+	c.Assert(kept, DeepEquals, []string{
+		"11-18", // newest of the week of the 5th
+		"13-18", // newest of the 13th
+		"14-18", // newest of the 14th
+		"15-06", // second newest hour
+		"15-18", // newest hour, day and week
+		"invalid",
+	})
+}
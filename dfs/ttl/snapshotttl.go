@@ -19,6 +19,7 @@ import (
 	log "github.com/Sirupsen/logrus"
 	"github.com/control-center/serviced/dao"
 	"github.com/control-center/serviced/datastore"
+	"github.com/control-center/serviced/domain/snapshotpolicy"
 	"github.com/control-center/serviced/logging"
 	"github.com/control-center/serviced/utils"
 )
@@ -78,8 +79,9 @@ func (ttl *SnapshotTTL) Purge(age time.Duration) (time.Duration, error) {
 			return 0, err
 		}
 		for _, s := range snapshots {
-			//ignore snapshots that have any tag
-			if len(s.Tags) == 0 {
+			//ignore snapshots that have any tag, and scheduled snapshots,
+			//which are removed by their snapshot policy
+			if len(s.Tags) == 0 && s.Description != snapshotpolicy.Message {
 				// check the age of the snapshot
 				if timeToLive := s.Created.Sub(expire); timeToLive <= 0 {
 					snapshotLogger := logger.WithFields(log.Fields{
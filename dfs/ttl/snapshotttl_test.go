@@ -25,6 +25,7 @@ import (
 	"github.com/control-center/serviced/dao"
 	"github.com/control-center/serviced/datastore"
 	datastoreMocks "github.com/control-center/serviced/datastore/mocks"
+	"github.com/control-center/serviced/domain/snapshotpolicy"
 )
 
 func Test(t *testing.T) { TestingT(t) }
@@ -185,3 +186,26 @@ func (s *SnapshotTTLTestSuite) TestSnapshotTTL_Purge_DontDeleteTaggedSnap(c *C)
 		c.Errorf("Tags missing from remaning snapshot")
 	}
 }
+
+func (s *SnapshotTTLTestSuite) TestSnapshotTTL_Purge_DontDeleteScheduledSnap(c *C) {
+	timeCreated := time.Now().UTC().Add(-5 * time.Minute)
+
+	snapToSave := dao.SnapshotInfo{
+		SnapshotID:  "snapshottag_" + timeCreated.Format(timeFormat),
+		Description: snapshotpolicy.Message,
+		Created:     timeCreated,
+	}
+
+	iface := &TestSnapshotTTLInterface{
+		tenantIDs: []string{"test service id"},
+		snaps:     []dao.SnapshotInfo{snapToSave},
+	}
+	ttl := &SnapshotTTL{iface}
+	if _, err := ttl.Purge(time.Minute); err != nil {
+		c.Errorf("Unexpected error: %s", err)
+	}
+
+	if len(iface.snaps) != 1 {
+		c.Errorf("Scheduled snapshot should not have been deleted")
+	}
+}
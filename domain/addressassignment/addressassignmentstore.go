@@ -15,11 +15,9 @@ package addressassignment
 
 import (
 	"fmt"
-	"strconv"
 	"strings"
 
 	"github.com/control-center/serviced/datastore"
-	"github.com/zenoss/elastigo/search"
 )
 
 //NewStore creates a AddressAssignmentStore store
@@ -35,7 +33,7 @@ type Store struct {
 func (s *Store) GetAllAddressAssignments(ctx datastore.Context) ([]AddressAssignment, error) {
 	defer ctx.Metrics().Stop(ctx.Metrics().Start("AddressAssignmentStore.GetAllAddressAssignments"))
 	q := datastore.NewQuery(ctx)
-	search := search.Search("controlplane").Type(kind).Size("50000")
+	search := datastore.NewSearch(kind)
 	results, err := q.Execute(search)
 	if err != nil {
 		return nil, err
@@ -46,8 +44,7 @@ func (s *Store) GetAllAddressAssignments(ctx datastore.Context) ([]AddressAssign
 func (s *Store) GetServiceAddressAssignments(ctx datastore.Context, serviceID string) ([]AddressAssignment, error) {
 	defer ctx.Metrics().Stop(ctx.Metrics().Start("AddressAssignmentStore.GetServiceAddressAssignments"))
 	q := datastore.NewQuery(ctx)
-	query := search.Query().Term("ServiceID", serviceID)
-	search := search.Search("controlplane").Type(kind).Size("50000").Query(query)
+	search := datastore.NewSearch(kind).Where(datastore.Equal("ServiceID", serviceID))
 	results, err := q.Execute(search)
 	if err != nil {
 		return nil, err
@@ -63,10 +60,9 @@ func (s *Store) GetServiceAddressAssignmentsByPort(ctx datastore.Context, poolID
 		return nil, fmt.Errorf("port must be greater than 0")
 	}
 
-	search := search.Search("controlplane").Type(kind).Size("50000").Filter(
-		"and",
-		search.Filter().Terms("PoolID", poolID),
-		search.Filter().Terms("Port", strconv.FormatUint(uint64(port), 10)),
+	search := datastore.NewSearch(kind).Where(
+		datastore.Equal("PoolID", poolID),
+		datastore.Equal("Port", port),
 	)
 
 	if results, err := datastore.NewQuery(ctx).Execute(search); err != nil {
@@ -84,10 +80,9 @@ func (s *Store) FindAssignmentByServiceEndpoint(ctx datastore.Context, serviceID
 		return nil, fmt.Errorf("endpoint name cannot be empty")
 	}
 
-	search := search.Search("controlplane").Type(kind).Filter(
-		"and",
-		search.Filter().Terms("ServiceID", serviceID),
-		search.Filter().Terms("EndpointName", endpointName),
+	search := datastore.NewSearch(kind).Where(
+		datastore.Equal("ServiceID", serviceID),
+		datastore.Equal("EndpointName", endpointName),
 	)
 
 	if results, err := datastore.NewQuery(ctx).Execute(search); err != nil {
@@ -111,11 +106,10 @@ func (s *Store) FindAssignmentByHostPort(ctx datastore.Context, poolID string, i
 		return nil, fmt.Errorf("port must be greater than 0")
 	}
 
-	search := search.Search("controlplane").Type(kind).Size("50000").Filter(
-		"and",
-		search.Filter().Terms("PoolID", poolID),
-		search.Filter().Terms("IPAddr", ipAddr),
-		search.Filter().Terms("Port", strconv.FormatUint(uint64(port), 10)),
+	search := datastore.NewSearch(kind).Where(
+		datastore.Equal("PoolID", poolID),
+		datastore.Equal("IPAddr", ipAddr),
+		datastore.Equal("Port", port),
 	)
 
 	if results, err := datastore.NewQuery(ctx).Execute(search); err != nil {
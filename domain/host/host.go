@@ -62,7 +62,8 @@ type Host struct {
 	PrivateNetwork  string // The private network where containers run, eg 172.16.42.0/24
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
-	IPs             []HostIPResource // The static IP resources available on the host
+	IPs             []HostIPResource  // The static IP resources available on the host
+	Labels          map[string]string // Labels used to select hosts for services
 	KernelVersion   string
 	KernelRelease   string
 	ServiceD        struct {
@@ -157,6 +158,9 @@ func (a *Host) Equals(b *Host) bool {
 	if !reflect.DeepEqual(a.IPs, b.IPs) {
 		return false
 	}
+	if !reflect.DeepEqual(a.Labels, b.Labels) {
+		return false
+	}
 	if a.CreatedAt.Unix() != b.CreatedAt.Unix() {
 		return false
 	}
@@ -15,11 +15,9 @@ package host
 
 import (
 	"errors"
-	"strconv"
 	"strings"
 
 	"github.com/control-center/serviced/datastore"
-	"github.com/zenoss/elastigo/search"
 )
 
 //NewStore creates a HostStore
@@ -53,8 +51,7 @@ func (hs *storeImpl) FindHostsWithPoolID(ctx datastore.Context, poolID string) (
 		return nil, errors.New("empty poolId not allowed")
 	}
 	q := datastore.NewQuery(ctx)
-	query := search.Query().Term("PoolID", id)
-	search := search.Search("controlplane").Type(kind).Size("50000").Query(query)
+	search := datastore.NewSearch(kind).Where(datastore.Equal("PoolID", id))
 	results, err := q.Execute(search)
 	if err != nil {
 		return nil, err
@@ -69,8 +66,7 @@ func (hs *storeImpl) GetHostByIP(ctx datastore.Context, hostIP string) (*Host, e
 		return nil, errors.New("empty hostIP not allowed")
 	}
 
-	query := search.Query().Term("IPs.IPAddress", hostIP)
-	search := search.Search("controlplane").Type(kind).Query(query)
+	search := datastore.NewSearch(kind).Where(datastore.Equal("IPs.IPAddress", hostIP))
 	results, err := datastore.NewQuery(ctx).Execute(search)
 	if err != nil {
 		return nil, err
@@ -89,8 +85,7 @@ func (hs *storeImpl) GetHostByIP(ctx datastore.Context, hostIP string) (*Host, e
 func (hs *storeImpl) GetN(ctx datastore.Context, limit uint64) ([]Host, error) {
 	defer ctx.Metrics().Stop(ctx.Metrics().Start("HostStore.GetN"))
 	q := datastore.NewQuery(ctx)
-	query := search.Query().Search("_exists_:ID")
-	search := search.Search("controlplane").Type(kind).Size(strconv.FormatUint(limit, 10)).Query(query)
+	search := datastore.NewSearch(kind).Where(datastore.Exists("ID")).Size(int(limit))
 	results, err := q.Execute(search)
 	if err != nil {
 		return nil, err
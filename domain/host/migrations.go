@@ -0,0 +1,56 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package host
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/control-center/serviced/datastore/migration"
+)
+
+// Migrations are the schema migrations of stored hosts, in order
+var Migrations = []migration.Migration{
+	{
+		ID:          "host-0001-ramcommitment",
+		Kind:        kind,
+		Description: "Move the deprecated RAMCommitment of hosts to RAMLimit",
+		Up:          migrateRAMCommitment,
+	},
+}
+
+// migrateRAMCommitment sets the RAM limit of a host that only has a RAM
+// commitment, as Host.TotalRAM does, and clears the commitment.
+func migrateRAMCommitment(doc map[string]interface{}) (bool, error) {
+	var commitment int64
+	switch c := doc["RAMCommitment"].(type) {
+	case nil:
+		return false, nil
+	case json.Number:
+		var err error
+		if commitment, err = c.Int64(); err != nil {
+			return false, err
+		}
+	default:
+		return false, fmt.Errorf("unexpected RAMCommitment %v", c)
+	}
+	if commitment == 0 {
+		return false, nil
+	}
+	if limit, _ := doc["RAMLimit"].(string); limit == "" {
+		doc["RAMLimit"] = fmt.Sprintf("%d", commitment)
+	}
+	delete(doc, "RAMCommitment")
+	return true, nil
+}
@@ -0,0 +1,48 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build unit
+
+package host
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMigrateRAMCommitment(t *testing.T) {
+	doc := map[string]interface{}{"ID": "h1", "RAMCommitment": json.Number("1024")}
+	if changed, err := migrateRAMCommitment(doc); err != nil || !changed {
+		t.Fatalf("expected change, got %t, %v", changed, err)
+	}
+	if doc["RAMLimit"] != "1024" {
+		t.Errorf("unexpected RAMLimit %v", doc["RAMLimit"])
+	}
+	if _, ok := doc["RAMCommitment"]; ok {
+		t.Errorf("RAMCommitment was not removed")
+	}
+
+	// a migrated host does not change again
+	if changed, err := migrateRAMCommitment(doc); err != nil || changed {
+		t.Errorf("expected no change, got %t, %v", changed, err)
+	}
+
+	// an existing limit is kept
+	doc = map[string]interface{}{"ID": "h2", "RAMCommitment": json.Number("1024"), "RAMLimit": "50%"}
+	if changed, err := migrateRAMCommitment(doc); err != nil || !changed {
+		t.Fatalf("expected change, got %t, %v", changed, err)
+	}
+	if doc["RAMLimit"] != "50%" {
+		t.Errorf("unexpected RAMLimit %v", doc["RAMLimit"])
+	}
+}
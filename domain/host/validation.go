@@ -45,6 +45,11 @@ func (h *Host) ValidEntity() error {
 		violations.Add(errors.New("host ip can not be a loopback address"))
 
 	}
+	for key := range h.Labels {
+		if strings.TrimSpace(key) == "" || strings.ContainsAny(key, "=,") {
+			violations.Add(fmt.Errorf("invalid host label %q", key))
+		}
+	}
 	if _, err := GetRAMLimit(h.RAMLimit, h.Memory); err == ErrSizeTooBig {
 		h.RAMLimit = fmt.Sprintf("%d", h.Memory)
 	} else if err != nil {
@@ -18,7 +18,6 @@ import (
 
 	"github.com/control-center/serviced/datastore"
 	"fmt"
-	"github.com/zenoss/elastigo/search"
 )
 
 
@@ -71,8 +70,7 @@ func (s *storeImpl) Delete(ctx datastore.Context, name, version string) error {
 // GetLogFilters returns all LogFilters
 func (s *storeImpl) GetLogFilters(ctx datastore.Context) ([]*LogFilter, error) {
 	q := datastore.NewQuery(ctx)
-	query := search.Query().Search("_exists_:Name")
-	search := search.Search("controlplane").Type(kind).Size("50000").Query(query)
+	search := datastore.NewSearch(kind).Where(datastore.Exists("Name"))
 	results, err := q.Execute(search)
 	if err != nil {
 		return nil, err
@@ -39,6 +39,8 @@ type Permission uint
 const (
 	AdminAccess Permission = 1 << iota
 	DFSAccess
+	OperatorAccess
+	ViewerAccess
 )
 
 // ResourcePool A collection of computing resources with optional quotas.
@@ -154,6 +156,14 @@ func (a *ResourcePool) HasAdminAccess() bool {
 	return a.Permissions&AdminAccess != 0
 }
 
+func (a *ResourcePool) HasOperatorAccess() bool {
+	return a.Permissions&OperatorAccess != 0
+}
+
+func (a *ResourcePool) HasViewerAccess() bool {
+	return a.Permissions&ViewerAccess != 0
+}
+
 // GetType returns a ResourcePool's type or kind, can be used to get
 // the string value of ResourcePool's type without a ResourcePool instance.
 // It returns the kind as a string.
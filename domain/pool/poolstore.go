@@ -19,7 +19,6 @@ import (
 	"strings"
 
 	"github.com/control-center/serviced/datastore"
-	"github.com/zenoss/elastigo/search"
 )
 
 //NewStore creates a ResourcePool store
@@ -48,7 +47,7 @@ type storeImpl struct {
 //GetResourcePools Get a list of all the resource pools
 func (ps *storeImpl) GetResourcePools(ctx datastore.Context) ([]ResourcePool, error) {
 	defer ctx.Metrics().Stop(ctx.Metrics().Start("PoolStore.GetResourcePools"))
-	return query(ctx, "_exists_:ID")
+	return query(ctx, datastore.Exists("ID"))
 }
 
 // GetResourcePoolsByRealm gets a list of resource pools for a given realm
@@ -59,8 +58,7 @@ func (s *storeImpl) GetResourcePoolsByRealm(ctx datastore.Context, realm string)
 		return nil, errors.New("empty realm not allowed")
 	}
 	q := datastore.NewQuery(ctx)
-	query := search.Query().Term("Realm", id)
-	search := search.Search("controlplane").Type(kind).Size("50000").Query(query)
+	search := datastore.NewSearch(kind).Where(datastore.Equal("Realm", id))
 	results, err := q.Execute(search)
 	if err != nil {
 		return nil, err
@@ -77,10 +75,9 @@ func (s *storeImpl) HasVirtualIP(ctx datastore.Context, poolID, virtualIP string
 		return false, errors.New("empty virtual ip not allowed")
 	}
 
-	search := search.Search("controlplane").Type(kind).Filter(
-		"and",
-		search.Filter().Terms("ID", poolID),
-		search.Filter().Terms("VirtualIPs.IP", virtualIP),
+	search := datastore.NewSearch(kind).Where(
+		datastore.Equal("ID", poolID),
+		datastore.Equal("VirtualIPs.IP", virtualIP),
 	)
 
 	results, err := datastore.NewQuery(ctx).Execute(search)
@@ -109,10 +106,9 @@ func convert(results datastore.Results) ([]ResourcePool, error) {
 	return pools, nil
 }
 
-func query(ctx datastore.Context, query string) ([]ResourcePool, error) {
+func query(ctx datastore.Context, filter datastore.Filter) ([]ResourcePool, error) {
 	q := datastore.NewQuery(ctx)
-	elasticQuery := search.Query().Search(query)
-	search := search.Search("controlplane").Type(kind).Size("50000").Query(elasticQuery)
+	search := datastore.NewSearch(kind).Where(filter)
 	results, err := q.Execute(search)
 	if err != nil {
 		return nil, err
@@ -18,7 +18,6 @@ import (
 	"strings"
 
 	"github.com/control-center/serviced/datastore"
-	"github.com/zenoss/elastigo/search"
 )
 
 // NewStore creates a new image registry store
@@ -73,8 +72,7 @@ func (s *storeImpl) Delete(ctx datastore.Context, id string) error {
 // GetImages returns all the images that are in the registry
 func (s *storeImpl) GetImages(ctx datastore.Context) ([]Image, error) {
 	defer ctx.Metrics().Stop(ctx.Metrics().Start("ImageRegistryStore.GetImages"))
-	query := search.Query().Search("_exists_:Library")
-	search := search.Search("controlplane").Type(kind).Size("50000").Query(query)
+	search := datastore.NewSearch(kind).Where(datastore.Exists("Library"))
 	q := datastore.NewQuery(ctx)
 	results, err := q.Execute(search)
 	if err != nil {
@@ -91,10 +89,9 @@ func (s *storeImpl) SearchLibraryByTag(ctx datastore.Context, library, tag strin
 	} else if tag = strings.TrimSpace(tag); tag == "" {
 		return nil, errors.New("empty tag not allowed")
 	}
-	search := search.Search("controlplane").Type(kind).Size("50000").Filter(
-		"and",
-		search.Filter().Terms("Library", library),
-		search.Filter().Terms("Tag", tag),
+	search := datastore.NewSearch(kind).Where(
+		datastore.Equal("Library", library),
+		datastore.Equal("Tag", tag),
 	)
 	q := datastore.NewQuery(ctx)
 	results, err := q.Execute(search)
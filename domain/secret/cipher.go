@@ -0,0 +1,89 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	// ErrInvalidKey is returned when the master key is not 32 bytes
+	ErrInvalidKey = errors.New("secret key must be 32 bytes")
+	// ErrWrongKey is returned when a secret was encrypted with another key
+	ErrWrongKey = errors.New("secret was encrypted with a different key")
+)
+
+// Cipher encrypts and decrypts the values of secrets with AES-256-GCM.  The
+// name of the secret is authenticated with the value, so that ciphertext
+// cannot be moved from one secret to another.
+type Cipher struct {
+	keyID string
+	aead  cipher.AEAD
+}
+
+// NewCipher returns a cipher for a 32-byte master key
+func NewCipher(key []byte) (*Cipher, error) {
+	if len(key) != 32 {
+		return nil, ErrInvalidKey
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(key)
+	return &Cipher{keyID: hex.EncodeToString(sum[:8]), aead: aead}, nil
+}
+
+// KeyID identifies the master key without revealing it
+func (c *Cipher) KeyID() string {
+	return c.keyID
+}
+
+// Seal encrypts the value into the secret
+func (c *Cipher) Seal(s *Secret, value []byte) error {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	s.KeyID = c.keyID
+	s.Nonce = nonce
+	s.Ciphertext = c.aead.Seal(nil, nonce, value, []byte(s.Name))
+	return nil
+}
+
+// Open decrypts the value of the secret
+func (c *Cipher) Open(s *Secret) ([]byte, error) {
+	if s.KeyID != c.keyID {
+		return nil, ErrWrongKey
+	}
+	if len(s.Nonce) != c.aead.NonceSize() {
+		return nil, fmt.Errorf("secret %s has an invalid nonce", s.Name)
+	}
+	value, err := c.aead.Open(nil, s.Nonce, s.Ciphertext, []byte(s.Name))
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt secret %s: %s", s.Name, err)
+	}
+	return value, nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secret
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/control-center/serviced/datastore"
+	"github.com/control-center/serviced/datastore/elastic"
+	"github.com/control-center/serviced/logging"
+)
+
+const kind = "secret"
+
+var (
+	plog          = logging.PackageLogger()
+	mappingString = fmt.Sprintf(`
+{
+    "%s": {
+        "properties": {
+            "Name":        {"type": "string", "index": "not_analyzed"},
+            "Description": {"type": "string", "index": "not_analyzed"},
+            "Version":     {"type": "long",   "index": "not_analyzed"},
+            "KeyID":       {"type": "string", "index": "not_analyzed"},
+            "Nonce":       {"type": "string", "index": "no"},
+            "Ciphertext":  {"type": "string", "index": "no"},
+            "CreatedAt":   {"type": "date", "format": "dateOptionalTime"},
+            "UpdatedAt":   {"type": "date", "format": "dateOptionalTime"}
+        }
+    }
+}
+`, kind)
+	// MAPPING is the elastic mapping for secrets
+	MAPPING, mappingError = elastic.NewMapping(mappingString)
+)
+
+func init() {
+	if mappingError != nil {
+		plog.WithError(mappingError).Fatal("error creating mapping for the secret object")
+	}
+}
+
+// Key returns the datastore key of a secret
+func Key(name string) datastore.Key {
+	name = strings.TrimSpace(name)
+	return datastore.NewKey(kind, name)
+}
@@ -0,0 +1,60 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secret
+
+import (
+	"time"
+
+	"github.com/control-center/serviced/datastore"
+)
+
+// Redacted replaces the value of a secret wherever it is displayed
+const Redacted = "********"
+
+// Secret is a named credential that is encrypted with the master secret key.
+// Only the ciphertext is stored; the value is decrypted on the master when a
+// service that references the secret is evaluated for a container.
+type Secret struct {
+	Name        string
+	Description string
+	Version     int    // incremented each time the secret is rotated
+	KeyID       string // identifies the master key that encrypted the value
+	Nonce       []byte `json:",omitempty"`
+	Ciphertext  []byte `json:",omitempty"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	datastore.VersionedEntity
+}
+
+// Redact returns a copy of the secret without its encrypted value
+func (s Secret) Redact() Secret {
+	s.Nonce = nil
+	s.Ciphertext = nil
+	return s
+}
+
+// GetType returns the datastore kind of a secret
+func GetType() string {
+	return kind
+}
+
+// GetType returns the datastore kind of a secret
+func (s *Secret) GetType() string {
+	return GetType()
+}
+
+// GetID returns the name of the secret
+func (s *Secret) GetID() string {
+	return s.Name
+}
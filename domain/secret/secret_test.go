@@ -0,0 +1,107 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build unit integration
+
+package secret
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/control-center/serviced/validation"
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+type unitTestSuite struct{}
+
+var _ = Suite(&unitTestSuite{})
+
+var testKey = bytes.Repeat([]byte{0x5a}, 32)
+
+func (s *unitTestSuite) TestNewCipher_InvalidKey(c *C) {
+	_, err := NewCipher([]byte("short"))
+	c.Assert(err, Equals, ErrInvalidKey)
+}
+
+func (s *unitTestSuite) TestCipher_SealOpen(c *C) {
+	cipher, err := NewCipher(testKey)
+	c.Assert(err, IsNil)
+
+	sec := &Secret{Name: "db-password", Version: 1}
+	c.Assert(cipher.Seal(sec, []byte("hunter2")), IsNil)
+	c.Assert(sec.KeyID, Equals, cipher.KeyID())
+	c.Assert(bytes.Contains(sec.Ciphertext, []byte("hunter2")), Equals, false)
+	c.Assert(sec.ValidEntity(), IsNil)
+
+	value, err := cipher.Open(sec)
+	c.Assert(err, IsNil)
+	c.Assert(string(value), Equals, "hunter2")
+
+	// sealing again uses a new nonce
+	nonce := sec.Nonce
+	c.Assert(cipher.Seal(sec, []byte("hunter2")), IsNil)
+	c.Assert(sec.Nonce, Not(DeepEquals), nonce)
+}
+
+func (s *unitTestSuite) TestCipher_OpenWrongKey(c *C) {
+	cipher, err := NewCipher(testKey)
+	c.Assert(err, IsNil)
+	other, err := NewCipher(bytes.Repeat([]byte{0x17}, 32))
+	c.Assert(err, IsNil)
+
+	sec := &Secret{Name: "db-password"}
+	c.Assert(cipher.Seal(sec, []byte("hunter2")), IsNil)
+	_, err = other.Open(sec)
+	c.Assert(err, Equals, ErrWrongKey)
+}
+
+func (s *unitTestSuite) TestCipher_OpenRenamed(c *C) {
+	cipher, err := NewCipher(testKey)
+	c.Assert(err, IsNil)
+
+	sec := &Secret{Name: "db-password"}
+	c.Assert(cipher.Seal(sec, []byte("hunter2")), IsNil)
+	sec.Name = "api-token"
+	_, err = cipher.Open(sec)
+	c.Assert(err, ErrorMatches, "could not decrypt secret api-token: .*")
+}
+
+func (s *unitTestSuite) TestSecret_Redact(c *C) {
+	sec := Secret{Name: "db-password", KeyID: "abc", Nonce: []byte{1}, Ciphertext: []byte{2}}
+	redacted := sec.Redact()
+	c.Assert(redacted.Name, Equals, "db-password")
+	c.Assert(redacted.KeyID, Equals, "abc")
+	c.Assert(redacted.Nonce, IsNil)
+	c.Assert(redacted.Ciphertext, IsNil)
+	c.Assert(sec.Ciphertext, NotNil)
+}
+
+func (s *unitTestSuite) TestValidName(c *C) {
+	for _, name := range []string{"db-password", "api.token", "A_1"} {
+		c.Check(ValidName(name), IsNil)
+	}
+	for _, name := range []string{"", "-leading", "has space", "slash/name", string(bytes.Repeat([]byte("a"), 129))} {
+		err := ValidName(name)
+		c.Check(err, FitsTypeOf, validation.NewViolation(""))
+	}
+}
+
+func (s *unitTestSuite) TestValidEntity_NotEncrypted(c *C) {
+	sec := &Secret{Name: "db-password", Version: 1}
+	c.Assert(sec.ValidEntity(), ErrorMatches, "(?s).*secret db-password is not encrypted.*")
+}
@@ -0,0 +1,80 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secret
+
+import (
+	"github.com/control-center/serviced/datastore"
+)
+
+// Store manages secrets in the datastore
+type Store interface {
+	// Get a secret by name.  Return ErrNoSuchEntity if not found
+	Get(ctx datastore.Context, name string) (*Secret, error)
+
+	// Put adds or updates a secret
+	Put(ctx datastore.Context, s *Secret) error
+
+	// Delete removes a secret if it exists
+	Delete(ctx datastore.Context, name string) error
+
+	// GetSecrets returns all secrets
+	GetSecrets(ctx datastore.Context) ([]*Secret, error)
+}
+
+type storeImpl struct {
+	ds datastore.DataStore
+}
+
+// NewStore returns a new secret store
+func NewStore() Store {
+	return &storeImpl{}
+}
+
+func (s *storeImpl) Get(ctx datastore.Context, name string) (*Secret, error) {
+	defer ctx.Metrics().Stop(ctx.Metrics().Start("SecretStore.Get"))
+	val := &Secret{}
+	if err := s.ds.Get(ctx, Key(name), val); err != nil {
+		return nil, err
+	}
+	return val, nil
+}
+
+func (s *storeImpl) Put(ctx datastore.Context, val *Secret) error {
+	defer ctx.Metrics().Stop(ctx.Metrics().Start("SecretStore.Put"))
+	return s.ds.Put(ctx, Key(val.Name), val)
+}
+
+func (s *storeImpl) Delete(ctx datastore.Context, name string) error {
+	defer ctx.Metrics().Stop(ctx.Metrics().Start("SecretStore.Delete"))
+	return s.ds.Delete(ctx, Key(name))
+}
+
+func (s *storeImpl) GetSecrets(ctx datastore.Context) ([]*Secret, error) {
+	defer ctx.Metrics().Stop(ctx.Metrics().Start("SecretStore.GetSecrets"))
+	q := datastore.NewQuery(ctx)
+	search := datastore.NewSearch(kind).Where(datastore.Exists("Name"))
+	results, err := q.Execute(search)
+	if err != nil {
+		return nil, err
+	}
+	secrets := make([]*Secret, results.Len())
+	for i := range secrets {
+		val := &Secret{}
+		if err := results.Get(i, val); err != nil {
+			return nil, err
+		}
+		secrets[i] = val
+	}
+	return secrets, nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build integration
+
+package secret
+
+import (
+	"time"
+
+	"github.com/control-center/serviced/datastore"
+	"github.com/control-center/serviced/datastore/elastic"
+	. "gopkg.in/check.v1"
+)
+
+var _ = Suite(&S{
+	ElasticTest: elastic.ElasticTest{
+		Index:    "controlplane",
+		Mappings: []elastic.Mapping{MAPPING},
+	}})
+
+type S struct {
+	elastic.ElasticTest
+	ctx   datastore.Context
+	store Store
+}
+
+func (s *S) SetUpTest(c *C) {
+	s.ElasticTest.SetUpTest(c)
+	datastore.Register(s.Driver())
+	s.ctx = datastore.Get()
+	s.store = NewStore()
+}
+
+func (s *S) Test_SecretCRUD(c *C) {
+	cipher, err := NewCipher(testKey)
+	c.Assert(err, IsNil)
+	expected := &Secret{
+		Name:        "db-password",
+		Description: "database password",
+		Version:     1,
+		CreatedAt:   time.Now().UTC().Truncate(time.Second),
+		UpdatedAt:   time.Now().UTC().Truncate(time.Second),
+	}
+	c.Assert(cipher.Seal(expected, []byte("hunter2")), IsNil)
+
+	actual, err := s.store.Get(s.ctx, expected.Name)
+	c.Assert(datastore.IsErrNoSuchEntity(err), Equals, true)
+	c.Assert(actual, IsNil)
+
+	err = s.store.Put(s.ctx, expected)
+	c.Assert(err, IsNil)
+	expected.DatabaseVersion++
+
+	actual, err = s.store.Get(s.ctx, expected.Name)
+	c.Assert(err, IsNil)
+	c.Assert(actual, DeepEquals, expected)
+	value, err := cipher.Open(actual)
+	c.Assert(err, IsNil)
+	c.Assert(string(value), Equals, "hunter2")
+
+	secrets, err := s.store.GetSecrets(s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(secrets, DeepEquals, []*Secret{expected})
+
+	err = s.store.Delete(s.ctx, expected.Name)
+	c.Assert(err, IsNil)
+	_, err = s.store.Get(s.ctx, expected.Name)
+	c.Assert(datastore.IsErrNoSuchEntity(err), Equals, true)
+}
+
+func (s *S) Test_PutRequiresEncryption(c *C) {
+	err := s.store.Put(s.ctx, &Secret{Name: "plain", Version: 1})
+	c.Assert(err, NotNil)
+}
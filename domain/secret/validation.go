@@ -0,0 +1,48 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secret
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/control-center/serviced/validation"
+)
+
+var nameRegexp = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]{0,127}$`)
+
+// ValidName checks that a secret name is made of letters, digits, dots,
+// dashes and underscores
+func ValidName(name string) error {
+	if !nameRegexp.MatchString(name) {
+		return validation.NewViolation(fmt.Sprintf("invalid secret name %q", name))
+	}
+	return nil
+}
+
+// ValidEntity makes sure that a secret is named and encrypted
+func (s *Secret) ValidEntity() error {
+	violations := validation.NewValidationError()
+	violations.Add(ValidName(s.Name))
+	if s.KeyID == "" || len(s.Nonce) == 0 || len(s.Ciphertext) == 0 {
+		violations.Add(validation.NewViolation(fmt.Sprintf("secret %s is not encrypted", s.Name)))
+	}
+	if s.Version < 1 {
+		violations.Add(validation.NewViolation(fmt.Sprintf("secret %s has an invalid version %d", s.Name, s.Version)))
+	}
+	if violations.HasError() {
+		return violations
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ServiceTree is the desired state of a service and its descendants.  A
+// child without an ID matches the existing child of its parent that has the
+// same name, or is added if there is none.
+type ServiceTree struct {
+	Service  *Service
+	Services []ServiceTree
+}
+
+// ChangeAction is what applying a service tree does to a service
+type ChangeAction string
+
+const (
+	// ChangeAdd adds a service that is in the tree but does not exist
+	ChangeAdd ChangeAction = "add"
+	// ChangeUpdate updates a service whose fields differ from the tree
+	ChangeUpdate ChangeAction = "update"
+	// ChangeRemove removes a service, and its descendants, that is not in
+	// the tree
+	ChangeRemove ChangeAction = "remove"
+)
+
+// ServiceChange is a change that applying a service tree makes to a service
+type ServiceChange struct {
+	Action    ChangeAction
+	ServiceID string
+	Path      string        // names of the service and its ancestors, from the root of the tree
+	Fields    []FieldChange // the fields that an update changes
+}
+
+// FieldChange is a field of a service that an update changes
+type FieldChange struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// DiffFields returns the fields that differ between two services, other than
+// the skipped ones.
+func DiffFields(cur, updated *Service, skip ...string) []FieldChange {
+	skipped := make(map[string]bool)
+	for _, name := range skip {
+		skipped[name] = true
+	}
+	changes := []FieldChange{}
+	vCur := reflect.ValueOf(cur).Elem()
+	vUpdated := reflect.ValueOf(updated).Elem()
+	t := vUpdated.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Name
+		if skipped[name] {
+			continue
+		}
+		fCur := vCur.Field(i).Interface()
+		fUpdated := vUpdated.Field(i).Interface()
+		if !reflect.DeepEqual(fCur, fUpdated) {
+			changes = append(changes, FieldChange{
+				Field: name,
+				Old:   formatField(fCur),
+				New:   formatField(fUpdated),
+			})
+		}
+	}
+	return changes
+}
+
+// formatField prints a field's value, following pointers
+func formatField(value interface{}) string {
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "<nil>"
+		}
+		return fmt.Sprintf("%+v", v.Elem().Interface())
+	}
+	return fmt.Sprintf("%v", value)
+}
@@ -22,6 +22,7 @@ import (
 	"text/template"
 
 	log "github.com/Sirupsen/logrus"
+	"github.com/control-center/serviced/domain/secret"
 )
 
 func parent(gs GetService) func(s *runtimeContext) (*runtimeContext, error) {
@@ -111,10 +112,21 @@ func contextFilter(gs GetService) func(s *runtimeContext, prefix string) (map[st
 	}
 }
 
+// secretValue returns the value of a secret, or a placeholder if the
+// template is evaluated where secrets are not available.
+func secretValue(gsec GetSecret) func(name string) (string, error) {
+	return func(name string) (string, error) {
+		if gsec == nil {
+			return secret.Redacted, nil
+		}
+		return gsec(name)
+	}
+}
+
 // EvaluateActionsTemplate parses and evaluates the Actions string of a service.
-func (service *Service) EvaluateActionsTemplate(gs GetService, fc FindChildService, instanceID int) (err error) {
+func (service *Service) EvaluateActionsTemplate(gs GetService, fc FindChildService, gsec GetSecret, instanceID int) (err error) {
 	for key, value := range service.Actions {
-		err, result := service.evaluateTemplate(gs, fc, instanceID, value)
+		err, result := service.evaluateTemplate(gs, fc, gsec, instanceID, value)
 		if err != nil {
 			return err
 		}
@@ -127,8 +139,8 @@ func (service *Service) EvaluateActionsTemplate(gs GetService, fc FindChildServi
 }
 
 // EvaluateHostnameTemplate parses and evaluates the Hostname string of a service.
-func (service *Service) EvaluateHostnameTemplate(gs GetService, fc FindChildService, instanceID int) (err error) {
-	err, result := service.evaluateTemplate(gs, fc, instanceID, service.Hostname)
+func (service *Service) EvaluateHostnameTemplate(gs GetService, fc FindChildService, gsec GetSecret, instanceID int) (err error) {
+	err, result := service.evaluateTemplate(gs, fc, gsec, instanceID, service.Hostname)
 	if err == nil {
 		service.Hostname = result
 	}
@@ -137,9 +149,9 @@ func (service *Service) EvaluateHostnameTemplate(gs GetService, fc FindChildServ
 
 // EvaluateVolumesTemplate parses and evaluates the ResourcePath string in
 // volumes of a service
-func (service *Service) EvaluateVolumesTemplate(gs GetService, fc FindChildService, instanceID int) (err error) {
+func (service *Service) EvaluateVolumesTemplate(gs GetService, fc FindChildService, gsec GetSecret, instanceID int) (err error) {
 	for i, vol := range service.Volumes {
-		err, result := service.evaluateTemplate(gs, fc, instanceID, vol.ResourcePath)
+		err, result := service.evaluateTemplate(gs, fc, gsec, instanceID, vol.ResourcePath)
 		if err != nil {
 			return err
 		}
@@ -153,8 +165,8 @@ func (service *Service) EvaluateVolumesTemplate(gs GetService, fc FindChildServi
 }
 
 // EvaluateStartupTemplate parses and evaluates the StartUp string of a service.
-func (service *Service) EvaluateStartupTemplate(gs GetService, fc FindChildService, instanceID int) (err error) {
-	err, result := service.evaluateTemplate(gs, fc, instanceID, service.Startup)
+func (service *Service) EvaluateStartupTemplate(gs GetService, fc FindChildService, gsec GetSecret, instanceID int) (err error) {
+	err, result := service.evaluateTemplate(gs, fc, gsec, instanceID, service.Startup)
 	if err == nil && result != "" {
 		service.Startup = result
 	}
@@ -162,9 +174,9 @@ func (service *Service) EvaluateStartupTemplate(gs GetService, fc FindChildServi
 }
 
 // EvaluateRunsTemplate parses and evaluates the Runs string of a service.
-func (service *Service) EvaluateRunsTemplate(gs GetService, fc FindChildService) (err error) {
+func (service *Service) EvaluateRunsTemplate(gs GetService, fc FindChildService, gsec GetSecret) (err error) {
 	for key, value := range service.Runs {
-		err, result := service.evaluateTemplate(gs, fc, 0, value)
+		err, result := service.evaluateTemplate(gs, fc, gsec, 0, value)
 		if err != nil {
 			return err
 		}
@@ -173,7 +185,7 @@ func (service *Service) EvaluateRunsTemplate(gs GetService, fc FindChildService)
 		}
 	}
 	for key, value := range service.Commands {
-		err, result := service.evaluateTemplate(gs, fc, 0, value.Command)
+		err, result := service.evaluateTemplate(gs, fc, gsec, 0, value.Command)
 		if err != nil {
 			return err
 		}
@@ -188,7 +200,7 @@ func (service *Service) EvaluateRunsTemplate(gs GetService, fc FindChildService)
 // evaluateTemplate takes a control center client and template string and evaluates
 // the template using the service as the context. If the template is invalid or there is an error
 // then an empty string is returned.
-func (service *Service) evaluateTemplate(gs GetService, fc FindChildService, instanceID int, serviceTemplate string) (err error, result string) {
+func (service *Service) evaluateTemplate(gs GetService, fc FindChildService, gsec GetSecret, instanceID int, serviceTemplate string) (err error, result string) {
 	defer func() {
 		if r := recover(); r != nil {
 			if _, ok := r.(runtime.Error); ok {
@@ -205,6 +217,7 @@ func (service *Service) evaluateTemplate(gs GetService, fc FindChildService, ins
 		"context":       context(gs),
 		"getContext":    getContext(gs),
 		"contextFilter": contextFilter(gs),
+		"secret":        secretValue(gsec),
 		"percentScale":  percentScale,
 		"bytesToMB":     bytesToMB,
 		"plus":          plus,
@@ -231,7 +244,7 @@ func (service *Service) evaluateTemplate(gs GetService, fc FindChildService, ins
 
 // EvaluateLogConfigTemplate parses and evals the Path, Type and all the values for the tags of the log
 // configs. This happens for each LogConfig on the service.
-func (service *Service) EvaluateLogConfigTemplate(gs GetService, fc FindChildService, instanceID int) (err error) {
+func (service *Service) EvaluateLogConfigTemplate(gs GetService, fc FindChildService, gsec GetSecret, instanceID int) (err error) {
 	log.WithFields(log.Fields{
 		"servicename": service.Name,
 		"serviceid": service.ID,
@@ -241,7 +254,7 @@ func (service *Service) EvaluateLogConfigTemplate(gs GetService, fc FindChildSer
 	// evaluate the template for the LogConfig as well as the tags
 	for i, logConfig := range service.LogConfigs {
 		// Path
-		err, result := service.evaluateTemplate(gs, fc, instanceID, logConfig.Path)
+		err, result := service.evaluateTemplate(gs, fc, gsec, instanceID, logConfig.Path)
 		if err != nil {
 			return err
 		}
@@ -250,7 +263,7 @@ func (service *Service) EvaluateLogConfigTemplate(gs GetService, fc FindChildSer
 		}
 
 		// Type
-		err, result = service.evaluateTemplate(gs, fc, instanceID, logConfig.Type)
+		err, result = service.evaluateTemplate(gs, fc, gsec, instanceID, logConfig.Type)
 		if err != nil {
 			return err
 		}
@@ -260,7 +273,7 @@ func (service *Service) EvaluateLogConfigTemplate(gs GetService, fc FindChildSer
 
 		// Tags
 		for j, tag := range logConfig.LogTags {
-			err, result = service.evaluateTemplate(gs, fc, instanceID, tag.Value)
+			err, result = service.evaluateTemplate(gs, fc, gsec, instanceID, tag.Value)
 			if err != nil {
 				return err
 			}
@@ -274,7 +287,7 @@ func (service *Service) EvaluateLogConfigTemplate(gs GetService, fc FindChildSer
 
 // EvaluateConfigFilesTemplate parses and evals the Filename and Content. This happens for each
 // ConfigFile on the service.
-func (service *Service) EvaluateConfigFilesTemplate(gs GetService, fc FindChildService, instanceID int) (err error) {
+func (service *Service) EvaluateConfigFilesTemplate(gs GetService, fc FindChildService, gsec GetSecret, instanceID int) (err error) {
 	log.WithFields(log.Fields{
 		"servicename": service.Name,
 		"serviceid": service.ID,
@@ -283,7 +296,7 @@ func (service *Service) EvaluateConfigFilesTemplate(gs GetService, fc FindChildS
 
 	for key, configFile := range service.ConfigFiles {
 		// Filename
-		err, result := service.evaluateTemplate(gs, fc, instanceID, configFile.Filename)
+		err, result := service.evaluateTemplate(gs, fc, gsec, instanceID, configFile.Filename)
 		if err != nil {
 			return err
 		}
@@ -291,7 +304,7 @@ func (service *Service) EvaluateConfigFilesTemplate(gs GetService, fc FindChildS
 			configFile.Filename = result
 		}
 		// Content
-		err, result = service.evaluateTemplate(gs, fc, instanceID, configFile.Content)
+		err, result = service.evaluateTemplate(gs, fc, gsec, instanceID, configFile.Content)
 		if err != nil {
 			return err
 		}
@@ -304,7 +317,7 @@ func (service *Service) EvaluateConfigFilesTemplate(gs GetService, fc FindChildS
 }
 
 // EvaluatePrereqsTemplate parses and evals the Script field for each Prereq.
-func (service *Service) EvaluatePrereqsTemplate(gs GetService, fc FindChildService, instanceID int) (err error) {
+func (service *Service) EvaluatePrereqsTemplate(gs GetService, fc FindChildService, gsec GetSecret, instanceID int) (err error) {
 	log.WithFields(log.Fields{
 		"servicename": service.Name,
 		"serviceid": service.ID,
@@ -312,7 +325,7 @@ func (service *Service) EvaluatePrereqsTemplate(gs GetService, fc FindChildServi
 	}).Debug("Evaluating Prereq scripts")
 
 	for i, prereq := range service.Prereqs {
-		err, result := service.evaluateTemplate(gs, fc, instanceID, prereq.Script)
+		err, result := service.evaluateTemplate(gs, fc, gsec, instanceID, prereq.Script)
 		if err != nil {
 			return err
 		}
@@ -324,8 +337,8 @@ func (service *Service) EvaluatePrereqsTemplate(gs GetService, fc FindChildServi
 	return
 }
 
-// EvaluateHealthCheckTemplate parses and evals the Script field for each HealthCheck.
-func (service *Service) EvaluateHealthCheckTemplate(gs GetService, fc FindChildService, instanceID int) (err error) {
+// EvaluateHealthCheckTemplate parses and evals the Script, URL and Host fields for each HealthCheck.
+func (service *Service) EvaluateHealthCheckTemplate(gs GetService, fc FindChildService, gsec GetSecret, instanceID int) (err error) {
 	log.WithFields(log.Fields{
 		"servicename": service.Name,
 		"serviceid": service.ID,
@@ -333,14 +346,16 @@ func (service *Service) EvaluateHealthCheckTemplate(gs GetService, fc FindChildS
 	}).Debug("Evaluating HealthCheck scripts")
 
 	for key, healthcheck := range service.HealthChecks {
-		err, result := service.evaluateTemplate(gs, fc, instanceID, healthcheck.Script)
-		if err != nil {
-			return err
-		}
-		if result != "" {
-			healthcheck.Script = result
-			service.HealthChecks[key] = healthcheck
+		for _, field := range []*string{&healthcheck.Script, &healthcheck.URL, &healthcheck.Host} {
+			err, result := service.evaluateTemplate(gs, fc, gsec, instanceID, *field)
+			if err != nil {
+				return err
+			}
+			if result != "" {
+				*field = result
+			}
 		}
+		service.HealthChecks[key] = healthcheck
 	}
 	return
 }
@@ -381,7 +396,7 @@ func Round(value float64) int64 {
 
 // EvaluateEndpointTemplates parses and evaluates the "ApplicationTemplate" property
 // of each of the service endpoints for this service.
-func (service *Service) EvaluateEndpointTemplates(gs GetService, fc FindChildService, instanceID int) (err error) {
+func (service *Service) EvaluateEndpointTemplates(gs GetService, fc FindChildService, gsec GetSecret, instanceID int) (err error) {
 	for i, ep := range service.Endpoints {
 		//cache the application template (assumes this is called after service creation from svc definition)
 		if ep.Application != "" && ep.ApplicationTemplate == "" {
@@ -390,7 +405,7 @@ func (service *Service) EvaluateEndpointTemplates(gs GetService, fc FindChildSer
 		}
 
 		if ep.ApplicationTemplate != "" {
-			err, result := service.evaluateTemplate(gs, fc, instanceID, ep.ApplicationTemplate)
+			err, result := service.evaluateTemplate(gs, fc, gsec, instanceID, ep.ApplicationTemplate)
 			if err != nil {
 				return err
 			}
@@ -401,7 +416,7 @@ func (service *Service) EvaluateEndpointTemplates(gs GetService, fc FindChildSer
 
 		// we only want to evaluate exports
 		if ep.PortTemplate != "" && ep.Purpose == "export" {
-			err, result := service.evaluateTemplate(gs, fc, instanceID, ep.PortTemplate)
+			err, result := service.evaluateTemplate(gs, fc, gsec, instanceID, ep.PortTemplate)
 			if err != nil {
 				return err
 			}
@@ -422,9 +437,9 @@ func (service *Service) EvaluateEndpointTemplates(gs GetService, fc FindChildSer
 
 // EvaluateEndpointTemplates parses and evaluates the "Environment" property of
 // this service.
-func (service *Service) EvaluateEnvironmentTemplate(gs GetService, fc FindChildService, instanceID int) (err error) {
+func (service *Service) EvaluateEnvironmentTemplate(gs GetService, fc FindChildService, gsec GetSecret, instanceID int) (err error) {
 	for i, envvar := range service.Environment {
-		err, result := service.evaluateTemplate(gs, fc, instanceID, envvar)
+		err, result := service.evaluateTemplate(gs, fc, gsec, instanceID, envvar)
 		if err != nil {
 			return err
 		}
@@ -458,48 +473,48 @@ func newRuntimeContext(svc *Service, instanceID int) *runtimeContext {
 // Evaluate evaluates all the fields of the Service that we care about, using
 // a runtimeContext with the current Service embedded, and adding instanceID
 // as an extra attribute.
-func (service *Service) Evaluate(getSvc GetService, findChild FindChildService, instanceID int) (err error) {
-	if err = service.EvaluateEndpointTemplates(getSvc, findChild, instanceID); err != nil {
+func (service *Service) Evaluate(getSvc GetService, findChild FindChildService, getSecret GetSecret, instanceID int) (err error) {
+	if err = service.EvaluateEndpointTemplates(getSvc, findChild, getSecret, instanceID); err != nil {
 		plog.WithError(err).Error()
 		return err
 	}
-	if err = service.EvaluateLogConfigTemplate(getSvc, findChild, instanceID); err != nil {
+	if err = service.EvaluateLogConfigTemplate(getSvc, findChild, getSecret, instanceID); err != nil {
 		plog.WithError(err).Error()
 		return err
 	}
-	if err = service.EvaluateConfigFilesTemplate(getSvc, findChild, instanceID); err != nil {
+	if err = service.EvaluateConfigFilesTemplate(getSvc, findChild, getSecret, instanceID); err != nil {
 		plog.WithError(err).Error()
 		return err
 	}
-	if err = service.EvaluateStartupTemplate(getSvc, findChild, instanceID); err != nil {
+	if err = service.EvaluateStartupTemplate(getSvc, findChild, getSecret, instanceID); err != nil {
 		plog.WithError(err).Error()
 		return err
 	}
-	if err = service.EvaluateRunsTemplate(getSvc, findChild); err != nil {
+	if err = service.EvaluateRunsTemplate(getSvc, findChild, getSecret); err != nil {
 		plog.WithError(err).Error()
 		return err
 	}
-	if err = service.EvaluateActionsTemplate(getSvc, findChild, instanceID); err != nil {
+	if err = service.EvaluateActionsTemplate(getSvc, findChild, getSecret, instanceID); err != nil {
 		plog.WithError(err).Error()
 		return err
 	}
-	if err = service.EvaluateHostnameTemplate(getSvc, findChild, instanceID); err != nil {
+	if err = service.EvaluateHostnameTemplate(getSvc, findChild, getSecret, instanceID); err != nil {
 		plog.WithError(err).Error()
 		return err
 	}
-	if err = service.EvaluateVolumesTemplate(getSvc, findChild, instanceID); err != nil {
+	if err = service.EvaluateVolumesTemplate(getSvc, findChild, getSecret, instanceID); err != nil {
 		plog.WithError(err).Error()
 		return err
 	}
-	if err = service.EvaluatePrereqsTemplate(getSvc, findChild, instanceID); err != nil {
+	if err = service.EvaluatePrereqsTemplate(getSvc, findChild, getSecret, instanceID); err != nil {
 		plog.WithError(err).Error()
 		return err
 	}
-	if err = service.EvaluateHealthCheckTemplate(getSvc, findChild, instanceID); err != nil {
+	if err = service.EvaluateHealthCheckTemplate(getSvc, findChild, getSecret, instanceID); err != nil {
 		plog.WithError(err).Error()
 		return err
 	}
-	if err = service.EvaluateEnvironmentTemplate(getSvc, findChild, instanceID); err != nil {
+	if err = service.EvaluateEnvironmentTemplate(getSvc, findChild, getSecret, instanceID); err != nil {
 		plog.WithError(err).Error()
 		return err
 	}
@@ -69,6 +69,8 @@ type Instance struct {
 type StrategyInstance struct {
 	HostID        string
 	ServiceID     string
+	Name          string
+	DeploymentID  string
 	CPUCommitment int
 	RAMCommitment uint64
 	RAMThreshold  uint
@@ -0,0 +1,126 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"reflect"
+
+	"github.com/control-center/serviced/domain/addressassignment"
+)
+
+// FieldConflict is a field of a deployed service that was edited locally and
+// also changed by a template upgrade
+type FieldConflict struct {
+	Field    string
+	Base     string // the value in the template that the service was deployed from
+	Template string // the value in the new template
+	Live     string // the value in the deployed service
+}
+
+// ServiceConflict is a deployed service with fields that a template upgrade
+// cannot merge
+type ServiceConflict struct {
+	ServiceID string
+	Path      string
+	Fields    []FieldConflict
+}
+
+// MergeFields merges the changes between the base and updated versions of a
+// service into a copy of the live service, and returns the copy, the fields
+// it changed and the fields that conflict.  A field conflicts when the live
+// service differs from both the base and the updated version; a conflicting
+// field keeps its live value.  Skipped fields always keep their live values.
+func MergeFields(base, updated, live *Service, skip ...string) (*Service, []FieldChange, []FieldConflict) {
+	skipped := make(map[string]bool)
+	for _, name := range skip {
+		skipped[name] = true
+	}
+	merged := *live
+	changes := []FieldChange{}
+	conflicts := []FieldConflict{}
+	vBase := reflect.ValueOf(base).Elem()
+	vUpdated := reflect.ValueOf(updated).Elem()
+	vLive := reflect.ValueOf(live).Elem()
+	vMerged := reflect.ValueOf(&merged).Elem()
+	t := vMerged.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Name
+		if skipped[name] {
+			continue
+		}
+		fBase := vBase.Field(i).Interface()
+		fUpdated := vUpdated.Field(i).Interface()
+		fLive := vLive.Field(i).Interface()
+		if mergeEqual(name, fBase, fUpdated) {
+			// the template did not change the field
+			continue
+		}
+		if mergeEqual(name, fLive, fUpdated) {
+			// the service already has the new value
+			continue
+		}
+		if !mergeEqual(name, fLive, fBase) {
+			conflicts = append(conflicts, FieldConflict{
+				Field:    name,
+				Base:     formatField(fBase),
+				Template: formatField(fUpdated),
+				Live:     formatField(fLive),
+			})
+			continue
+		}
+		vMerged.Field(i).Set(vUpdated.Field(i))
+		changes = append(changes, FieldChange{
+			Field: name,
+			Old:   formatField(fLive),
+			New:   formatField(fUpdated),
+		})
+	}
+	return &merged, changes, conflicts
+}
+
+// mergeEqual compares the values of a field.  Empty and nil slices and maps
+// are equal, and endpoints are compared before their templates are
+// evaluated, so that a deployed service compares equal to the service
+// definition it was built from.
+func mergeEqual(field string, a, b interface{}) bool {
+	if field == "Endpoints" {
+		return reflect.DeepEqual(unevaluatedEndpoints(a.([]ServiceEndpoint)), unevaluatedEndpoints(b.([]ServiceEndpoint)))
+	}
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	switch va.Kind() {
+	case reflect.Slice, reflect.Map:
+		if va.Len() == 0 && vb.Len() == 0 {
+			return true
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// unevaluatedEndpoints returns endpoints without the values that are set when
+// they are deployed
+func unevaluatedEndpoints(endpoints []ServiceEndpoint) []ServiceEndpoint {
+	result := make([]ServiceEndpoint, len(endpoints))
+	for i, ep := range endpoints {
+		if ep.ApplicationTemplate == "" {
+			ep.ApplicationTemplate = ep.Application
+		}
+		ep.Application = ""
+		if ep.PortTemplate != "" && ep.Purpose == "export" {
+			ep.PortNumber = 0
+		}
+		ep.AddressAssignment = addressassignment.AddressAssignment{}
+		result[i] = ep
+	}
+	return result
+}
@@ -0,0 +1,77 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build unit
+
+package service_test
+
+import (
+	"github.com/control-center/serviced/domain/service"
+	. "gopkg.in/check.v1"
+)
+
+func (s *ServiceDomainUnitTestSuite) TestMergeFields_TemplateChange(t *C) {
+	base := &service.Service{ID: "base", Startup: "run", Instances: 1}
+	updated := &service.Service{ID: "updated", Startup: "run --fast", Instances: 1}
+	live := &service.Service{ID: "live", Startup: "run", Instances: 3}
+
+	merged, changes, conflicts := service.MergeFields(base, updated, live, "ID")
+	t.Assert(conflicts, HasLen, 0)
+	t.Assert(changes, DeepEquals, []service.FieldChange{{Field: "Startup", Old: "run", New: "run --fast"}})
+	t.Assert(merged.ID, Equals, "live")
+	t.Assert(merged.Startup, Equals, "run --fast")
+	t.Assert(merged.Instances, Equals, 3)
+	// the live service is not changed
+	t.Assert(live.Startup, Equals, "run")
+}
+
+func (s *ServiceDomainUnitTestSuite) TestMergeFields_Conflict(t *C) {
+	base := &service.Service{Startup: "run", Instances: 1}
+	updated := &service.Service{Startup: "run", Instances: 2}
+	live := &service.Service{Startup: "run", Instances: 3}
+
+	merged, changes, conflicts := service.MergeFields(base, updated, live)
+	t.Assert(changes, HasLen, 0)
+	t.Assert(conflicts, DeepEquals, []service.FieldConflict{{Field: "Instances", Base: "1", Template: "2", Live: "3"}})
+	t.Assert(merged.Instances, Equals, 3)
+}
+
+func (s *ServiceDomainUnitTestSuite) TestMergeFields_SameChange(t *C) {
+	base := &service.Service{Instances: 1, Tags: []string{}}
+	updated := &service.Service{Instances: 2}
+	live := &service.Service{Instances: 2}
+
+	merged, changes, conflicts := service.MergeFields(base, updated, live)
+	t.Assert(changes, HasLen, 0)
+	t.Assert(conflicts, HasLen, 0)
+	t.Assert(merged.Instances, Equals, 2)
+}
+
+func (s *ServiceDomainUnitTestSuite) TestMergeFields_EvaluatedEndpoints(t *C) {
+	base := &service.Service{Endpoints: []service.ServiceEndpoint{
+		{Name: "web", Purpose: "export", Application: "{{(parent .).Name}}_web", PortTemplate: "{{plus 1000 .InstanceID}}"},
+	}}
+	updated := &service.Service{Endpoints: []service.ServiceEndpoint{
+		{Name: "web", Purpose: "export", Application: "{{(parent .).Name}}_web", PortTemplate: "{{plus 1000 .InstanceID}}"},
+		{Name: "db", Purpose: "import", Application: "db"},
+	}}
+	live := &service.Service{Endpoints: []service.ServiceEndpoint{
+		{Name: "web", Purpose: "export", Application: "app_web", ApplicationTemplate: "{{(parent .).Name}}_web", PortNumber: 1000, PortTemplate: "{{plus 1000 .InstanceID}}"},
+	}}
+
+	merged, changes, conflicts := service.MergeFields(base, updated, live)
+	t.Assert(conflicts, HasLen, 0)
+	t.Assert(changes, HasLen, 1)
+	t.Assert(changes[0].Field, Equals, "Endpoints")
+	t.Assert(merged.Endpoints, DeepEquals, updated.Endpoints)
+}
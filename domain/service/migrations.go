@@ -0,0 +1,57 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"fmt"
+
+	"github.com/control-center/serviced/datastore/migration"
+)
+
+// Migrations are the schema migrations of stored services, in order
+var Migrations = []migration.Migration{
+	{
+		ID:          "service-0001-runs",
+		Kind:        kind,
+		Description: "Convert the deprecated Runs of services to Commands",
+		Up:          migrateRuns,
+	},
+}
+
+// migrateRuns converts the runs of a service to commands that commit on
+// success, as Service.UnmarshalJSON does, and clears the runs.  A service
+// that has commands keeps them.
+func migrateRuns(doc map[string]interface{}) (bool, error) {
+	runs, _ := doc["Runs"].(map[string]interface{})
+	if len(runs) == 0 {
+		return false, nil
+	}
+	delete(doc, "Runs")
+	if commands, _ := doc["Commands"].(map[string]interface{}); len(commands) > 0 {
+		return true, nil
+	}
+	commands := make(map[string]interface{})
+	for name, run := range runs {
+		command, ok := run.(string)
+		if !ok {
+			return false, fmt.Errorf("unexpected run %s: %v", name, run)
+		}
+		commands[name] = map[string]interface{}{
+			"Command":         command,
+			"CommitOnSuccess": true,
+		}
+	}
+	doc["Commands"] = commands
+	return true, nil
+}
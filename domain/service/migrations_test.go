@@ -0,0 +1,41 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build unit
+
+package service_test
+
+import (
+	"github.com/control-center/serviced/domain/service"
+	. "gopkg.in/check.v1"
+)
+
+func (s *ServiceDomainUnitTestSuite) TestMigrateRuns(c *C) {
+	up := service.Migrations[0].Up
+	doc := map[string]interface{}{
+		"ID":   "svc1",
+		"Runs": map[string]interface{}{"hello": "echo hello"},
+	}
+	changed, err := up(doc)
+	c.Assert(err, IsNil)
+	c.Assert(changed, Equals, true)
+	c.Assert(doc["Runs"], IsNil)
+	c.Assert(doc["Commands"], DeepEquals, map[string]interface{}{
+		"hello": map[string]interface{}{"Command": "echo hello", "CommitOnSuccess": true},
+	})
+
+	// a migrated service does not change again
+	changed, err = up(doc)
+	c.Assert(err, IsNil)
+	c.Assert(changed, Equals, false)
+}
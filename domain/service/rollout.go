@@ -0,0 +1,64 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import "time"
+
+// RolloutState is the stage of a rolling restart
+type RolloutState string
+
+const (
+	// RolloutCanary is restarting the canary instances
+	RolloutCanary RolloutState = "canary"
+	// RolloutRunning is restarting instances in batches
+	RolloutRunning RolloutState = "running"
+	// RolloutPaused is waiting between batches
+	RolloutPaused RolloutState = "paused"
+	// RolloutComplete has restarted every instance
+	RolloutComplete RolloutState = "complete"
+	// RolloutCancelled was stopped before every instance restarted
+	RolloutCancelled RolloutState = "cancelled"
+	// RolloutFailed was aborted because instances failed to restart or
+	// failed their health checks
+	RolloutFailed RolloutState = "failed"
+	// RolloutRollingBack is restoring the previous image after a failure
+	RolloutRollingBack RolloutState = "rollingback"
+	// RolloutRolledBack restored the previous image after a failure
+	RolloutRolledBack RolloutState = "rolledback"
+)
+
+// RolloutStatus is the progress of the most recent rolling restart of a
+// service
+type RolloutStatus struct {
+	ServiceID     string
+	State         RolloutState
+	ImageID       string // image the instances are restarting with
+	PreviousImage string // image restored on a rollback; empty if the image did not change
+	Instances     int
+	Restarted     int // instances that have been restarted
+	Batch         int // the batch being restarted, starting at 1
+	Batches       int
+	Error         string
+	StartedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// Done returns true if the rollout is no longer restarting instances
+func (s RolloutStatus) Done() bool {
+	switch s.State {
+	case RolloutComplete, RolloutCancelled, RolloutFailed, RolloutRolledBack:
+		return true
+	}
+	return false
+}
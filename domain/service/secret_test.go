@@ -0,0 +1,88 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build unit
+
+package service_test
+
+import (
+	"errors"
+
+	"github.com/control-center/serviced/domain/secret"
+	"github.com/control-center/serviced/domain/service"
+	"github.com/control-center/serviced/domain/servicedefinition"
+	. "gopkg.in/check.v1"
+)
+
+func secretTestService() *service.Service {
+	return &service.Service{
+		ID:          "svc",
+		Name:        "app",
+		Environment: []string{`DB_PASSWORD={{secret "db-password"}}`},
+		ConfigFiles: map[string]servicedefinition.ConfigFile{
+			"/etc/app.conf": {Filename: "/etc/app.conf", Content: `password = {{secret "db-password"}}`},
+		},
+		Secrets: []servicedefinition.SecretRef{
+			{Name: "api-token", File: "token", Env: "API_TOKEN"},
+		},
+	}
+}
+
+func noService(string) (service.Service, error) {
+	return service.Service{}, errors.New("not found")
+}
+
+func noChild(string, string) (service.Service, error) {
+	return service.Service{}, errors.New("not found")
+}
+
+func (s *ServiceDomainUnitTestSuite) TestEvaluate_Secrets(t *C) {
+	values := map[string]string{"db-password": "hunter2", "api-token": "abc123"}
+	getSecret := func(name string) (string, error) {
+		if value, ok := values[name]; ok {
+			return value, nil
+		}
+		return "", errors.New("no such secret")
+	}
+
+	svc := secretTestService()
+	t.Assert(svc.Evaluate(noService, noChild, getSecret, 0), IsNil)
+	t.Assert(svc.Environment, DeepEquals, []string{"DB_PASSWORD=hunter2"})
+	t.Assert(svc.ConfigFiles["/etc/app.conf"].Content, Equals, "password = hunter2")
+	t.Assert(svc.Secrets[0].Path(), Equals, "/dev/shm/secrets/token")
+}
+
+func (s *ServiceDomainUnitTestSuite) TestEvaluate_SecretsRedacted(t *C) {
+	svc := secretTestService()
+	t.Assert(svc.Evaluate(noService, noChild, nil, 0), IsNil)
+	t.Assert(svc.Environment, DeepEquals, []string{"DB_PASSWORD=" + secret.Redacted})
+	t.Assert(svc.ConfigFiles["/etc/app.conf"].Content, Equals, "password = "+secret.Redacted)
+}
+
+func (s *ServiceDomainUnitTestSuite) TestEvaluate_MissingSecret(t *C) {
+	getSecret := func(name string) (string, error) {
+		return "", errors.New("no such secret")
+	}
+	svc := secretTestService()
+	t.Assert(svc.Evaluate(noService, noChild, getSecret, 0), NotNil)
+}
+
+func (s *ServiceDomainUnitTestSuite) TestValidEntity_Secrets(t *C) {
+	svc := secretTestService()
+	svc.PoolID = "default"
+	svc.Launch = "auto"
+	t.Assert(svc.ValidEntity(), IsNil)
+
+	svc.Secrets = append(svc.Secrets, servicedefinition.SecretRef{Name: "other", File: "token"})
+	t.Assert(svc.ValidEntity(), ErrorMatches, "(?s).*secret file token is used more than once.*")
+}
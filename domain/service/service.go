@@ -227,8 +227,14 @@ type Service struct {
 	DesiredState      int
 	CurrentState      string
 	HostPolicy        servicedefinition.HostPolicy
+	NodeSelector      map[string]string
+	Affinity          []servicedefinition.AffinityRule
+	AntiAffinity      []servicedefinition.AffinityRule
+	RolloutPolicy     *servicedefinition.RolloutPolicy
 	Hostname          string
 	Privileged        bool
+	SecurityContext   *servicedefinition.SecurityContext
+	Secrets           []servicedefinition.SecretRef
 	Launch            string
 	Endpoints         []ServiceEndpoint
 	ParentServiceID   string
@@ -236,6 +242,7 @@ type Service struct {
 	CreatedAt         time.Time
 	UpdatedAt         time.Time
 	DeploymentID      string
+	TemplateID        string // ID of the template that a tenant was deployed from or last upgraded to
 	DisableImage      bool
 	LogConfigs        []servicedefinition.LogConfig
 	Snapshot          servicedefinition.SnapshotCommands
@@ -370,8 +377,14 @@ func BuildService(sd servicedefinition.ServiceDefinition, parentServiceID string
 	svc.DesiredState = desiredState
 	svc.Launch = sd.Launch
 	svc.HostPolicy = sd.HostPolicy
+	svc.NodeSelector = sd.NodeSelector
+	svc.Affinity = sd.Affinity
+	svc.AntiAffinity = sd.AntiAffinity
+	svc.RolloutPolicy = sd.RolloutPolicy
 	svc.Hostname = sd.Hostname
 	svc.Privileged = sd.Privileged
+	svc.SecurityContext = sd.SecurityContext
+	svc.Secrets = sd.Secrets
 	svc.OriginalConfigs = sd.ConfigFiles
 	svc.ConfigFiles = sd.ConfigFiles
 	svc.ParentServiceID = parentServiceID
@@ -896,9 +909,27 @@ func (s *Service) Equals(b *Service) bool {
 	if s.Privileged != b.Privileged {
 		return false
 	}
+	if !reflect.DeepEqual(s.SecurityContext, b.SecurityContext) {
+		return false
+	}
+	if !reflect.DeepEqual(s.Secrets, b.Secrets) {
+		return false
+	}
 	if s.HostPolicy != b.HostPolicy {
 		return false
 	}
+	if !reflect.DeepEqual(s.NodeSelector, b.NodeSelector) {
+		return false
+	}
+	if !reflect.DeepEqual(s.Affinity, b.Affinity) {
+		return false
+	}
+	if !reflect.DeepEqual(s.AntiAffinity, b.AntiAffinity) {
+		return false
+	}
+	if !reflect.DeepEqual(s.RolloutPolicy, b.RolloutPolicy) {
+		return false
+	}
 	if s.ParentServiceID != b.ParentServiceID {
 		return false
 	}
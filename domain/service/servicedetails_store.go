@@ -22,19 +22,13 @@ import (
 	"unicode"
 
 	"github.com/control-center/serviced/datastore"
-	"github.com/control-center/serviced/datastore/elastic"
 )
 
 func (s *storeImpl) Query(ctx datastore.Context, query Query) ([]ServiceDetails, error) {
-	searchRequest := newServiceDetailsElasticRequest(map[string]interface{}{
-		"query": map[string]interface{}{
-			"query_string": map[string]interface{}{
-				"query": "_exists_:ID",
-			},
-		},
-		"fields": serviceDetailsFields,
-		"size":   serviceDetailsLimit,
-	})
+	searchRequest := datastore.NewSearch(kind).
+		Where(datastore.Exists("ID")).
+		Select(serviceDetailsFields...).
+		Size(serviceDetailsLimit)
 
 	results, err := datastore.NewQuery(ctx).Execute(searchRequest)
 	if err != nil {
@@ -116,15 +110,10 @@ func (s *storeImpl) GetServiceDetails(ctx datastore.Context, serviceID string) (
 		return nil, errors.New("empty service id not allowed")
 	}
 
-	searchRequest := newServiceDetailsElasticRequest(map[string]interface{}{
-		"query": map[string]interface{}{
-			"ids": map[string]interface{}{
-				"values": []string{id},
-			},
-		},
-		"fields": serviceDetailsFields,
-		"size":   1,
-	})
+	searchRequest := datastore.NewSearch(kind).
+		Where(datastore.Equal("ID", id)).
+		Select(serviceDetailsFields...).
+		Size(1)
 
 	results, err := datastore.NewQuery(ctx).Execute(searchRequest)
 	if err != nil {
@@ -155,35 +144,16 @@ func (s *storeImpl) GetServiceDetails(ctx datastore.Context, serviceID string) (
 // GetChildServiceDetailsByParentID returns service details given parent service id
 func (s *storeImpl) GetServiceDetailsByParentID(ctx datastore.Context, parentID string, since time.Duration) ([]ServiceDetails, error) {
 	defer ctx.Metrics().Stop(ctx.Metrics().Start("ServiceStore.GetServiceDetailsByParentID"))
-	query := map[string]interface{}{}
-	termQuery := map[string]string{
-		"ParentServiceID": parentID,
-	}
+	filters := []datastore.Filter{datastore.Equal("ParentServiceID", parentID)}
 	if since > 0 {
 		t0 := time.Now().Add(-since)
-		query["bool"] = map[string]interface{}{
-			"must": []map[string]interface{}{
-				map[string]interface{}{
-					"term": termQuery,
-				},
-				map[string]interface{}{
-					"range": map[string]interface{}{
-						"UpdatedAt": map[string]string{
-							"gte": t0.Format(time.RFC3339),
-						},
-					},
-				},
-			},
-		}
-	} else {
-		query["term"] = termQuery
+		filters = append(filters, datastore.Range("UpdatedAt", t0, nil))
 	}
 
-	searchRequest := newServiceDetailsElasticRequest(map[string]interface{}{
-		"query":  query,
-		"fields": serviceDetailsFields,
-		"size":   serviceDetailsLimit,
-	})
+	searchRequest := datastore.NewSearch(kind).
+		Where(filters...).
+		Select(serviceDetailsFields...).
+		Size(serviceDetailsLimit)
 
 	results, err := datastore.NewQuery(ctx).Execute(searchRequest)
 	if err != nil {
@@ -243,26 +213,13 @@ func (s *storeImpl) GetServiceDetailsByIDOrName(ctx datastore.Context, query str
 	if !prefix {
 		newquery = fmt.Sprintf(".*%s", newquery)
 	}
-	searchRequest := newServiceDetailsElasticRequest(map[string]interface{}{
-		"query": map[string]interface{}{
-			"bool": map[string]interface{}{
-				"should": []map[string]interface{}{
-					map[string]interface{}{
-						"ids": map[string]interface{}{
-							"values": []string{query},
-						},
-					},
-					map[string]interface{}{
-						"regexp": map[string]interface{}{
-							"Name": newquery,
-						},
-					},
-				},
-			},
-		},
-		"fields": serviceDetailsFields,
-		"size":   serviceDetailsLimit,
-	})
+	searchRequest := datastore.NewSearch(kind).
+		Where(datastore.Or(
+			datastore.Equal("ID", query),
+			datastore.Regexp("Name", newquery),
+		)).
+		Select(serviceDetailsFields...).
+		Size(serviceDetailsLimit)
 
 	results, err := datastore.NewQuery(ctx).Execute(searchRequest)
 	if err != nil {
@@ -292,26 +249,13 @@ func (s *storeImpl) GetServiceDetailsByIDOrName(ctx datastore.Context, query str
 // GetAllPublicEndpoints returns all the public endpoints in the system
 func (s *storeImpl) GetAllPublicEndpoints(ctx datastore.Context) ([]PublicEndpoint, error) {
 	defer ctx.Metrics().Stop(ctx.Metrics().Start("ServiceStore.GetAllPublicEndpoints"))
-	searchRequest := newServiceDetailsElasticRequest(map[string]interface{}{
-		"query": map[string]interface{}{
-			"bool": map[string]interface{}{
-				"should": []map[string]interface{}{
-					map[string]interface{}{
-						"regexp": map[string]interface{}{
-							"Endpoints.VHostList.Name": ".+",
-						},
-					},
-					map[string]interface{}{
-						"regexp": map[string]interface{}{
-							"Endpoints.PortList.PortAddr": ".+",
-						},
-					},
-				},
-			},
-		},
-		"fields": serviceEndpointFields,
-		"size":   serviceDetailsLimit,
-	})
+	searchRequest := datastore.NewSearch(kind).
+		Where(datastore.Or(
+			datastore.Regexp("Endpoints.VHostList.Name", ".+"),
+			datastore.Regexp("Endpoints.PortList.PortAddr", ".+"),
+		)).
+		Select(serviceEndpointFields...).
+		Size(serviceDetailsLimit)
 
 	results, err := datastore.NewQuery(ctx).Execute(searchRequest)
 	if err != nil {
@@ -335,15 +279,10 @@ func (s *storeImpl) GetAllPublicEndpoints(ctx datastore.Context) ([]PublicEndpoi
 // GetAllExportedEndpoints returns all the exported endpoints in the system
 func (s *storeImpl) GetAllExportedEndpoints(ctx datastore.Context) ([]ExportedEndpoint, error) {
 	defer ctx.Metrics().Stop(ctx.Metrics().Start("ServiceStore.GetAllExportedEndpoints"))
-	searchRequest := newServiceDetailsElasticRequest(map[string]interface{}{
-		"query": map[string]interface{}{
-			"term": map[string]interface{}{
-				"Endpoints.Purpose": "export",
-			},
-		},
-		"fields": exportedEndpointFields,
-		"size":   serviceDetailsLimit,
-	})
+	searchRequest := datastore.NewSearch(kind).
+		Where(datastore.Equal("Endpoints.Purpose", "export")).
+		Select(exportedEndpointFields...).
+		Size(serviceDetailsLimit)
 
 	results, err := datastore.NewQuery(ctx).Execute(searchRequest)
 	if err != nil {
@@ -366,29 +305,14 @@ func (s *storeImpl) GetAllExportedEndpoints(ctx datastore.Context) ([]ExportedEn
 
 func (s *storeImpl) GetAllIPAssignments(ctx datastore.Context) ([]BaseIPAssignment, error) {
 	// All services where Endpoints.AddressConfig.Port > 0 and Endpoints.Protocol != ""
-	searchRequest := newServiceDetailsElasticRequest(map[string]interface{}{
-		"query": map[string]interface{}{
-			"bool": map[string]interface{}{
-				"must": []map[string]interface{}{
-					map[string]interface{}{
-						"range": map[string]interface{}{
-							"Endpoints.AddressConfig.Port": map[string]interface{}{
-								"gt":  0,
-								"lte": 65535, // largest valid port is 65535 (unsigned 16-bit int)
-							},
-						},
-					},
-					map[string]interface{}{
-						"regexp": map[string]interface{}{
-							"Endpoints.Protocol": ".+",
-						},
-					},
-				},
-			},
-		},
-		"fields": serviceEndpointFields,
-		"size":   serviceDetailsLimit,
-	})
+	searchRequest := datastore.NewSearch(kind).
+		Where(
+			// largest valid port is 65535 (unsigned 16-bit int)
+			datastore.Range("Endpoints.AddressConfig.Port", 1, 65535),
+			datastore.Regexp("Endpoints.Protocol", ".+"),
+		).
+		Select(serviceEndpointFields...).
+		Size(serviceDetailsLimit)
 
 	results, err := datastore.NewQuery(ctx).Execute(searchRequest)
 	if err != nil {
@@ -410,13 +334,10 @@ func (s *storeImpl) GetAllIPAssignments(ctx datastore.Context) ([]BaseIPAssignme
 }
 
 func (s *storeImpl) hasChildren(ctx datastore.Context, serviceID string) (bool, error) {
-	searchRequest := newServiceDetailsElasticRequest(map[string]interface{}{
-		"query": map[string]interface{}{
-			"term": map[string]string{"ParentServiceID": serviceID},
-		},
-		"fields": []string{"ID"},
-		"size":   1,
-	})
+	searchRequest := datastore.NewSearch(kind).
+		Where(datastore.Equal("ParentServiceID", serviceID)).
+		Select("ID").
+		Size(1)
 
 	results, err := datastore.NewQuery(ctx).Execute(searchRequest)
 	if err != nil {
@@ -527,17 +448,6 @@ func isRegexReservedChar(r rune) bool {
 	return true
 }
 
-func newServiceDetailsElasticRequest(query interface{}) elastic.ElasticSearchRequest {
-	return elastic.ElasticSearchRequest{
-		Pretty: false,
-		Index:  "controlplane",
-		Type:   "service",
-		Scroll: "",
-		Scan:   0,
-		Query:  query,
-	}
-}
-
 var serviceDetailsLimit = 50000
 
 var serviceDetailsFields = []string{
@@ -336,7 +336,7 @@ func (s *S) TestEvaluateLogConfigTemplate(t *C) {
 	t.Assert(err, IsNil)
 
 	testcase := startup_testcases[0]
-	testcase.service.EvaluateLogConfigTemplate(s.getSVC, s.findChild, 0)
+	testcase.service.EvaluateLogConfigTemplate(s.getSVC, s.findChild, nil, 0)
 	// check the tag
 	result := testcase.service.LogConfigs[0].LogTags[0].Value
 	if result != testcase.service.Name {
@@ -356,7 +356,7 @@ func (s *S) TestEvaluateConfigFilesTemplate(t *C) {
 	var instanceID = 5
 
 	testcase := startup_testcases[0]
-	testcase.service.EvaluateConfigFilesTemplate(s.getSVC, s.findChild, instanceID)
+	testcase.service.EvaluateConfigFilesTemplate(s.getSVC, s.findChild, nil, instanceID)
 
 	if len(testcase.service.ConfigFiles) != 1 {
 		t.Errorf("Was expecting 1 ConfigFile, found %d", len(testcase.service.ConfigFiles))
@@ -377,7 +377,7 @@ func (s *S) TestEvaluateConfigFilesTemplateContext(t *C) {
 	var instanceID = 5
 
 	for _, testcase := range context_testcases {
-		err := testcase.EvaluateConfigFilesTemplate(s.getSVC, s.findChild, instanceID)
+		err := testcase.EvaluateConfigFilesTemplate(s.getSVC, s.findChild, nil, instanceID)
 		if err != nil {
 			t.Errorf("Failed to eval template %s", err)
 		}
@@ -396,7 +396,7 @@ func (s *S) TestEvaluateStartupTemplate(t *C) {
 	t.Assert(err, IsNil)
 
 	for _, testcase := range startup_testcases {
-		err = testcase.service.EvaluateStartupTemplate(s.getSVC, s.findChild, 0)
+		err = testcase.service.EvaluateStartupTemplate(s.getSVC, s.findChild, nil, 0)
 		t.Assert(err, IsNil)
 		result := testcase.service.Startup
 		if result != testcase.expected {
@@ -411,7 +411,7 @@ func (s *S) TestEvaluateActionsTemplate(t *C) {
 	err := createSvcs(s.store, s.ctx)
 	t.Assert(err, IsNil)
 	for _, testcase := range startup_testcases {
-		err = testcase.service.EvaluateActionsTemplate(s.getSVC, s.findChild, 0)
+		err = testcase.service.EvaluateActionsTemplate(s.getSVC, s.findChild, nil, 0)
 		for key, result := range testcase.service.Actions {
 			expected := fmt.Sprintf("%s %s", testcase.service.Name, key)
 			if result != expected {
@@ -425,7 +425,7 @@ func (s *S) TestEvaluateEnvironmentTemplate(t *C) {
 	err := createSvcs(s.store, s.ctx)
 	t.Assert(err, IsNil)
 	for _, testcase := range environ_testcases {
-		err = testcase.service.EvaluateEnvironmentTemplate(s.getSVC, s.findChild, 0)
+		err = testcase.service.EvaluateEnvironmentTemplate(s.getSVC, s.findChild, nil, 0)
 		t.Assert(err, IsNil)
 		result := testcase.service.Environment[0]
 		if result != testcase.expected {
@@ -441,7 +441,7 @@ func (s *S) TestEvaluateEndpointTemplate(t *C) {
 	for _, testcase := range endpoint_testcases {
 		if len(testcase.service.Endpoints) > 0 {
 			oldApp := testcase.service.Endpoints[0].Application
-			err = testcase.service.EvaluateEndpointTemplates(s.getSVC, s.findChild, 0)
+			err = testcase.service.EvaluateEndpointTemplates(s.getSVC, s.findChild, nil, 0)
 
 			result := testcase.service.Endpoints[0].Application
 			if result != testcase.expected {
@@ -451,7 +451,7 @@ func (s *S) TestEvaluateEndpointTemplate(t *C) {
 				t.Errorf("Expecting \"%s\" got \"%s\"\n", oldApp, testcase.service.Endpoints[0].ApplicationTemplate)
 			}
 
-			err = testcase.service.EvaluateEndpointTemplates(s.getSVC, s.findChild, 0)
+			err = testcase.service.EvaluateEndpointTemplates(s.getSVC, s.findChild, nil, 0)
 			result = testcase.service.Endpoints[0].Application
 			if result != testcase.expected {
 				t.Errorf("Expecting \"%s\" got \"%s\"\n", testcase.expected, result)
@@ -485,7 +485,7 @@ func (s *S) TestIncompleteStartupInjection(t *C) {
 		UpdatedAt:       time.Now(),
 	}
 
-	svc.EvaluateStartupTemplate(s.getSVC, s.findChild, 0)
+	svc.EvaluateStartupTemplate(s.getSVC, s.findChild, nil, 0)
 	if svc.Startup == "/usr/bin/ping -c 64 zenoss.com" {
 		t.Errorf("Not expecting a match")
 	}
@@ -596,7 +596,7 @@ func (s *S) TestIllegalTemplates(t *C) {
 	}
 
 	for _, svc := range illegal_services {
-		err = svc.Evaluate(s.getSVC, s.findChild, 0)
+		err = svc.Evaluate(s.getSVC, s.findChild, nil, 0)
 		if err == nil {
 			t.Errorf("Expecting error for invalid template: %+v", svc)
 		}
@@ -18,7 +18,6 @@ import (
 	"strings"
 
 	"github.com/control-center/serviced/datastore"
-	"github.com/control-center/serviced/datastore/elastic"
 )
 
 func (s *storeImpl) GetServiceHealth(ctx datastore.Context, svcId string) (*ServiceHealth, error) {
@@ -29,15 +28,10 @@ func (s *storeImpl) GetServiceHealth(ctx datastore.Context, svcId string) (*Serv
 		return nil, errors.New("empty service id not allowed")
 	}
 
-	searchRequest := newServiceHealthElasticRequest(map[string]interface{}{
-		"query": map[string]interface{}{
-			"ids": map[string]interface{}{
-				"values": []string{id},
-			},
-		},
-		"fields": serviceHealthFields,
-		"size":   1,
-	})
+	searchRequest := datastore.NewSearch(kind).
+		Where(datastore.Equal("ID", id)).
+		Select(serviceHealthFields...).
+		Size(1)
 
 	results, err := datastore.NewQuery(ctx).Execute(searchRequest)
 	if err != nil {
@@ -60,15 +54,10 @@ func (s *storeImpl) GetServiceHealth(ctx datastore.Context, svcId string) (*Serv
 
 func (s *storeImpl) GetAllServiceHealth(ctx datastore.Context) ([]ServiceHealth, error) {
 	defer ctx.Metrics().Stop(ctx.Metrics().Start("ServiceStore.GetServiceHealth"))
-	searchRequest := newServiceHealthElasticRequest(map[string]interface{}{
-		"query": map[string]interface{}{
-			"query_string": map[string]string{
-				"query": "_exists_:ID",
-			},
-		},
-		"fields": serviceHealthFields,
-		"size":   serviceHealthLimit,
-	})
+	searchRequest := datastore.NewSearch(kind).
+		Where(datastore.Exists("ID")).
+		Select(serviceHealthFields...).
+		Size(serviceHealthLimit)
 
 	results, err := datastore.NewQuery(ctx).Execute(searchRequest)
 	if err != nil {
@@ -101,17 +90,6 @@ func (s *storeImpl) fillHealthVolatileInfo(sh *ServiceHealth) {
 	}
 }
 
-func newServiceHealthElasticRequest(query interface{}) elastic.ElasticSearchRequest {
-	return elastic.ElasticSearchRequest{
-		Pretty: false,
-		Index:  "controlplane",
-		Type:   "service",
-		Scroll: "",
-		Scan:   0,
-		Query:  query,
-	}
-}
-
 var serviceHealthLimit = 50000
 
 var serviceHealthFields = []string{
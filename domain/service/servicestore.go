@@ -17,7 +17,6 @@ import (
 	"github.com/control-center/serviced/datastore"
 	"github.com/control-center/serviced/domain/servicedefinition"
 	"github.com/control-center/serviced/validation"
-	"github.com/zenoss/elastigo/search"
 
 	"errors"
 	"strings"
@@ -193,7 +192,7 @@ func (s *storeImpl) Delete(ctx datastore.Context, id string) error {
 // GetServices returns all services
 func (s *storeImpl) GetServices(ctx datastore.Context) ([]Service, error) {
 	defer ctx.Metrics().Stop(ctx.Metrics().Start("ServiceStore.GetServices"))
-	return s.query(ctx, "_exists_:ID")
+	return s.query(ctx, datastore.Exists("ID"))
 }
 
 // GetUpdatedServices returns all services updated since "since" time.Duration ago
@@ -201,9 +200,10 @@ func (s *storeImpl) GetUpdatedServices(ctx datastore.Context, since time.Duratio
 	defer ctx.Metrics().Stop(ctx.Metrics().Start("ServiceStore.GetUpdatedServices"))
 	q := datastore.NewQuery(ctx)
 	t0 := time.Now().Add(-since)
-	t0s := t0.Format(time.RFC3339)
-	elasticQuery := search.Query().Range(search.Range().Field("UpdatedAt").From(t0s)).Search("_exists_:ID")
-	search := search.Search("controlplane").Type(kind).Size("50000").Query(elasticQuery)
+	search := datastore.NewSearch(kind).Where(
+		datastore.Exists("ID"),
+		datastore.Range("UpdatedAt", t0, nil),
+	)
 	results, err := q.Execute(search)
 	if err != nil {
 		return nil, err
@@ -223,8 +223,11 @@ func (s *storeImpl) GetTaggedServices(ctx datastore.Context, tags ...string) ([]
 	if len(tags) == 0 {
 		return nil, errors.New("empty tags not allowed")
 	}
-	qs := strings.Join(tags, " AND ")
-	return s.query(ctx, qs)
+	filters := make([]datastore.Filter, len(tags))
+	for i, tag := range tags {
+		filters[i] = datastore.Equal("Tags", tag)
+	}
+	return s.query(ctx, datastore.And(filters...))
 }
 
 // GetServicesByPool returns services with the given pool id
@@ -235,8 +238,7 @@ func (s *storeImpl) GetServicesByPool(ctx datastore.Context, poolID string) ([]S
 		return nil, errors.New("empty poolID not allowed")
 	}
 	q := datastore.NewQuery(ctx)
-	query := search.Query().Term("PoolID", id)
-	search := search.Search("controlplane").Type(kind).Size("50000").Query(query)
+	search := datastore.NewSearch(kind).Where(datastore.Equal("PoolID", id))
 	results, err := q.Execute(search)
 	if err != nil {
 		return nil, err
@@ -252,8 +254,7 @@ func (s *storeImpl) GetServiceCountByImage(ctx datastore.Context, imageID string
 		return 0, errors.New("empty imageID not allowed")
 	}
 	q := datastore.NewQuery(ctx)
-	query := search.Query().Term("ImageID", id)
-	search := search.Search("controlplane").Type(kind).Size("50000").Query(query)
+	search := datastore.NewSearch(kind).Where(datastore.Equal("ImageID", id))
 	results, err := q.Execute(search)
 	if err != nil {
 		return 0, err
@@ -269,8 +270,7 @@ func (s *storeImpl) GetServicesByDeployment(ctx datastore.Context, deploymentID
 		return nil, errors.New("empty deploymentID not allowed")
 	}
 	q := datastore.NewQuery(ctx)
-	query := search.Query().Term("DeploymentID", id)
-	search := search.Search("controlplane").Type(kind).Size("50000").Query(query)
+	search := datastore.NewSearch(kind).Where(datastore.Equal("DeploymentID", id))
 	results, err := q.Execute(search)
 	if err != nil {
 		return nil, err
@@ -286,8 +286,7 @@ func (s *storeImpl) GetChildServices(ctx datastore.Context, parentID string) ([]
 		return nil, errors.New("empty parent service id not allowed")
 	}
 	q := datastore.NewQuery(ctx)
-	query := search.Query().Term("ParentServiceID", id)
-	search := search.Search("controlplane").Type(kind).Size("50000").Query(query)
+	search := datastore.NewSearch(kind).Where(datastore.Equal("ParentServiceID", id))
 	results, err := q.Execute(search)
 	if err != nil {
 		return nil, err
@@ -305,11 +304,10 @@ func (s *storeImpl) FindChildService(ctx datastore.Context, deploymentID, parent
 		return nil, errors.New("empty service name not allowed")
 	}
 
-	search := search.Search("controlplane").Type(kind).Filter(
-		"and",
-		search.Filter().Terms("DeploymentID", deploymentID),
-		search.Filter().Terms("ParentServiceID", parentID),
-		search.Filter().Terms("Name", serviceName),
+	search := datastore.NewSearch(kind).Where(
+		datastore.Equal("DeploymentID", deploymentID),
+		datastore.Equal("ParentServiceID", parentID),
+		datastore.Equal("Name", serviceName),
 	)
 
 	q := datastore.NewQuery(ctx)
@@ -336,11 +334,10 @@ func (s *storeImpl) FindTenantByDeploymentID(ctx datastore.Context, deploymentID
 		return nil, errors.New("empty service name not allowed")
 	}
 
-	search := search.Search("controlplane").Type(kind).Filter(
-		"and",
-		search.Filter().Terms("DeploymentID", deploymentID),
-		search.Filter().Terms("Name", name),
-		search.Filter().Terms("ParentServiceID", ""),
+	search := datastore.NewSearch(kind).Where(
+		datastore.Equal("DeploymentID", deploymentID),
+		datastore.Equal("Name", name),
+		datastore.Equal("ParentServiceID", ""),
 	)
 
 	q := datastore.NewQuery(ctx)
@@ -358,10 +355,9 @@ func (s *storeImpl) FindTenantByDeploymentID(ctx datastore.Context, deploymentID
 	}
 }
 
-func (s *storeImpl) query(ctx datastore.Context, query string) ([]Service, error) {
+func (s *storeImpl) query(ctx datastore.Context, filter datastore.Filter) ([]Service, error) {
 	q := datastore.NewQuery(ctx)
-	elasticQuery := search.Query().Search(query)
-	search := search.Search("controlplane").Type(kind).Size("50000").Query(elasticQuery)
+	search := datastore.NewSearch(kind).Where(filter)
 	results, err := q.Execute(search)
 	if err != nil {
 		return nil, err
@@ -17,6 +17,7 @@ import (
 	"fmt"
 
 	"github.com/control-center/serviced/commons"
+	"github.com/control-center/serviced/domain/servicedefinition"
 	"github.com/control-center/serviced/validation"
 )
 
@@ -54,6 +55,16 @@ func (s *Service) ValidEntity() error {
 		vErr.Add(hc.ValidEntity())
 	}
 
+	if s.RolloutPolicy != nil {
+		vErr.Add(s.RolloutPolicy.Validate())
+	}
+
+	if s.SecurityContext != nil {
+		vErr.Add(s.SecurityContext.ValidateService(s.Privileged, s.ConfigFiles, s.Volumes))
+	}
+
+	vErr.Add(servicedefinition.ValidateSecrets(s.Secrets))
+
 	if vErr.HasError() {
 		return vErr
 	}
@@ -25,6 +25,9 @@ type GetService func(serviceID string) (Service, error)
 //FindChildService finds a child service with a given name, error if not found
 type FindChildService func(parentID, childName string) (Service, error)
 
+//GetSecret returns the value of a secret, return error if not found
+type GetSecret func(name string) (string, error)
+
 //Walk traverses the service hierarchy and calls the supplied Visit function on each service
 func Walk(serviceID string, visitFn Visit, getService GetService, getChildren GetChildServices) error {
 	// get the children
@@ -14,10 +14,7 @@
 package serviceconfigfile
 
 import (
-	"strconv"
-
 	"github.com/control-center/serviced/datastore"
-	"github.com/zenoss/elastigo/search"
 )
 
 //NewStore creates a Service Config File store
@@ -41,44 +38,25 @@ type storeImpl struct {
 //is a "/" delimited string of the service name hierarchy, i.e /Zenoss.Core/Zproxy
 func (s *storeImpl) GetConfigFiles(ctx datastore.Context, tenantID string, svcPath string) ([]*SvcConfigFile, error) {
 	defer ctx.Metrics().Stop(ctx.Metrics().Start("ServiceConfigFileStore.GetConfigFiles"))
+	search := datastore.NewSearch(kind).Where(
+		datastore.Equal("ServiceTenantID", tenantID),
+		datastore.Equal("ServicePath", svcPath),
+	)
 
-	size := 20
-	from := 0
-	var confs []*SvcConfigFile
-
-	for {
-		search := search.Search("controlplane").Type(kind).Filter(
-			"and",
-			search.Filter().Terms("ServiceTenantID", tenantID),
-			search.Filter().Terms("ServicePath", svcPath),
-		).From(strconv.Itoa(from)).Size(strconv.Itoa(size))
-
-		q := datastore.NewQuery(ctx)
-
-		results, err := q.Execute(search)
-		if err != nil {
-			return nil, err
-		}
-
-		conv, err := convert(results)
-		if err != nil {
-			return nil, err
-		} else if len(conv) == 0 {
-			return confs, nil
-		}
-
-		from += size
-		confs = append(confs, conv...)
+	q := datastore.NewQuery(ctx)
+	results, err := q.Execute(search)
+	if err != nil {
+		return nil, err
 	}
+	return convert(results)
 }
 
 func (s *storeImpl) GetConfigFile(ctx datastore.Context, tenantID, svcPath, filename string) (*SvcConfigFile, error) {
 	defer ctx.Metrics().Stop(ctx.Metrics().Start("ServiceConfigFileStore.GetConfigFile"))
-	search := search.Search("controlplane").Type(kind).Filter(
-		"and",
-		search.Filter().Terms("ServiceTenantID", tenantID),
-		search.Filter().Terms("ServicePath", svcPath),
-		search.Filter().Terms("ConfFile.Filename", filename),
+	search := datastore.NewSearch(kind).Where(
+		datastore.Equal("ServiceTenantID", tenantID),
+		datastore.Equal("ServicePath", svcPath),
+		datastore.Equal("ConfFile.Filename", filename),
 	)
 
 	q := datastore.NewQuery(ctx)
@@ -0,0 +1,103 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package servicedefinition
+
+import (
+	"fmt"
+	"time"
+)
+
+// RolloutPolicy controls how the instances of a running service are replaced
+// when the service is restarted or its image changes.  Services without a
+// policy restart one instance at a time, and are not restarted when their
+// image changes.
+type RolloutPolicy struct {
+	BatchSize           int  // Instances restarted together; 0 is 1
+	MaxUnavailable      int  // Most instances that may be restarting or unhealthy at once; 0 is BatchSize
+	CanaryInstances     int  // Instances that must restart and pass health checks before the rest
+	PauseBetweenBatches int  // Seconds to wait between batches
+	AutoRollback        bool // Restore the previous image if restarted instances fail health checks
+}
+
+// Validate returns an error if any of the policy's values are negative
+func (p RolloutPolicy) Validate() error {
+	if p.BatchSize < 0 {
+		return fmt.Errorf("rollout batch size %d is negative", p.BatchSize)
+	}
+	if p.MaxUnavailable < 0 {
+		return fmt.Errorf("rollout max unavailable %d is negative", p.MaxUnavailable)
+	}
+	if p.CanaryInstances < 0 {
+		return fmt.Errorf("rollout canary instances %d is negative", p.CanaryInstances)
+	}
+	if p.PauseBetweenBatches < 0 {
+		return fmt.Errorf("rollout pause %d is negative", p.PauseBetweenBatches)
+	}
+	return nil
+}
+
+// MaxUnavailableInstances returns the most instances that may be restarting
+// or unhealthy at once.
+func (p RolloutPolicy) MaxUnavailableInstances() int {
+	if p.MaxUnavailable > 0 {
+		return p.MaxUnavailable
+	}
+	if p.BatchSize > 0 {
+		return p.BatchSize
+	}
+	return 1
+}
+
+// Pause returns the time to wait between batches
+func (p RolloutPolicy) Pause() time.Duration {
+	return time.Duration(p.PauseBetweenBatches) * time.Second
+}
+
+// Batches returns the instance ids of a service, grouped in the order that
+// they are restarted.  The canary instances, if any, are the first batch.
+func (p RolloutPolicy) Batches(instances int) [][]int {
+	size := p.BatchSize
+	if size <= 0 {
+		size = 1
+	}
+	if max := p.MaxUnavailableInstances(); size > max {
+		size = max
+	}
+	batches := [][]int{}
+	next := 0
+	if p.CanaryInstances > 0 && instances > 0 {
+		canaries := p.CanaryInstances
+		if canaries > instances {
+			canaries = instances
+		}
+		batches = append(batches, instanceRange(0, canaries))
+		next = canaries
+	}
+	for ; next < instances; next += size {
+		end := next + size
+		if end > instances {
+			end = instances
+		}
+		batches = append(batches, instanceRange(next, end))
+	}
+	return batches
+}
+
+func instanceRange(start, end int) []int {
+	ids := make([]int, 0, end-start)
+	for i := start; i < end; i++ {
+		ids = append(ids, i)
+	}
+	return ids
+}
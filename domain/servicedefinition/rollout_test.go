@@ -0,0 +1,56 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build unit
+
+package servicedefinition_test
+
+import (
+	"reflect"
+	"testing"
+
+	. "github.com/control-center/serviced/domain/servicedefinition"
+	. "github.com/control-center/serviced/domain/servicedefinition/testutils"
+)
+
+func TestRolloutPolicyBatches(t *testing.T) {
+	for _, tc := range []struct {
+		policy    RolloutPolicy
+		instances int
+		expected  [][]int
+	}{
+		{RolloutPolicy{}, 3, [][]int{{0}, {1}, {2}}},
+		{RolloutPolicy{BatchSize: 2}, 5, [][]int{{0, 1}, {2, 3}, {4}}},
+		{RolloutPolicy{BatchSize: 4, MaxUnavailable: 2}, 4, [][]int{{0, 1}, {2, 3}}},
+		{RolloutPolicy{BatchSize: 3, CanaryInstances: 1}, 6, [][]int{{0}, {1, 2, 3}, {4, 5}}},
+		{RolloutPolicy{CanaryInstances: 5}, 2, [][]int{{0, 1}}},
+		{RolloutPolicy{BatchSize: 2}, 0, [][]int{}},
+	} {
+		if actual := tc.policy.Batches(tc.instances); !reflect.DeepEqual(actual, tc.expected) {
+			t.Errorf("policy %+v with %d instances: expected %v, got %v", tc.policy, tc.instances, tc.expected, actual)
+		}
+	}
+}
+
+func TestServiceDefinitionRolloutPolicy(t *testing.T) {
+	sd := CreateValidServiceDefinition()
+	sd.Services[0].RolloutPolicy = &RolloutPolicy{BatchSize: 2, CanaryInstances: 1}
+	if err := sd.ValidEntity(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	sd.Services[0].RolloutPolicy = &RolloutPolicy{BatchSize: -1}
+	if err := sd.ValidEntity(); err == nil {
+		t.Error("Expected error")
+	}
+}
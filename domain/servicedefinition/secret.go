@@ -0,0 +1,82 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package servicedefinition
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/control-center/serviced/domain/secret"
+)
+
+// SecretsPath is the directory in the container where secret files are
+// written.  Docker mounts a private tmpfs at /dev/shm in every container, so
+// secrets never reach the disk, and the directory is writable even if the
+// container runs as another user or with a read-only root filesystem.
+const SecretsPath = "/dev/shm/secrets"
+
+var envNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// SecretRef delivers a secret to the container when it starts, as a file in
+// SecretsPath, an environment variable of the service's process, or both.
+// The value is never part of the service; the container asks for it when it
+// starts.
+type SecretRef struct {
+	Name string // name of the secret
+	File string // name of the file in SecretsPath
+	Env  string // name of the environment variable
+}
+
+// Path returns the path of the secret file in the container, or an empty
+// string if the secret is not delivered as a file
+func (ref SecretRef) Path() string {
+	if ref.File == "" {
+		return ""
+	}
+	return SecretsPath + "/" + ref.File
+}
+
+// ValidateSecrets checks the secrets that are delivered to a container
+func ValidateSecrets(refs []SecretRef) error {
+	files := make(map[string]struct{})
+	envs := make(map[string]struct{})
+	for _, ref := range refs {
+		if err := secret.ValidName(ref.Name); err != nil {
+			return err
+		}
+		if ref.File == "" && ref.Env == "" {
+			return fmt.Errorf("secret %s must set a file or an environment variable", ref.Name)
+		}
+		if ref.File != "" {
+			if ref.File == "." || ref.File == ".." || strings.ContainsAny(ref.File, "/\x00") {
+				return fmt.Errorf("secret %s has an invalid file name %q", ref.Name, ref.File)
+			}
+			if _, ok := files[ref.File]; ok {
+				return fmt.Errorf("secret file %s is used more than once", ref.File)
+			}
+			files[ref.File] = struct{}{}
+		}
+		if ref.Env != "" {
+			if !envNameRegexp.MatchString(ref.Env) {
+				return fmt.Errorf("secret %s has an invalid environment variable %q", ref.Name, ref.Env)
+			}
+			if _, ok := envs[ref.Env]; ok {
+				return fmt.Errorf("secret environment variable %s is used more than once", ref.Env)
+			}
+			envs[ref.Env] = struct{}{}
+		}
+	}
+	return nil
+}
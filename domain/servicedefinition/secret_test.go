@@ -0,0 +1,57 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build unit
+
+
+package servicedefinition_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/control-center/serviced/domain/servicedefinition"
+)
+
+func TestValidateSecrets(t *testing.T) {
+	for _, tc := range []struct {
+		refs  []SecretRef
+		error string
+	}{
+		{nil, ""},
+		{[]SecretRef{{Name: "db-password", File: "db", Env: "DB_PASSWORD"}, {Name: "api-token", Env: "API_TOKEN"}}, ""},
+		{[]SecretRef{{Name: "bad name", Env: "X"}}, "invalid secret name"},
+		{[]SecretRef{{Name: "db-password"}}, "must set a file or an environment variable"},
+		{[]SecretRef{{Name: "db-password", File: "../etc/passwd"}}, "invalid file name"},
+		{[]SecretRef{{Name: "db-password", File: ".."}}, "invalid file name"},
+		{[]SecretRef{{Name: "db-password", Env: "1PASSWORD"}}, "invalid environment variable"},
+		{[]SecretRef{{Name: "a", Env: "PASSWORD"}, {Name: "b", Env: "PASSWORD"}}, "PASSWORD is used more than once"},
+		{[]SecretRef{{Name: "a", File: "db"}, {Name: "b", File: "db"}}, "db is used more than once"},
+	} {
+		err := ValidateSecrets(tc.refs)
+		if tc.error == "" && err != nil {
+			t.Errorf("%+v: unexpected error %s", tc.refs, err)
+		} else if tc.error != "" && (err == nil || !strings.Contains(err.Error(), tc.error)) {
+			t.Errorf("%+v: expected error containing %q, got %v", tc.refs, tc.error, err)
+		}
+	}
+}
+
+func TestSecretRefPath(t *testing.T) {
+	if path := (SecretRef{Name: "db-password", File: "db"}).Path(); path != "/dev/shm/secrets/db" {
+		t.Errorf("unexpected path %s", path)
+	}
+	if path := (SecretRef{Name: "db-password", Env: "DB_PASSWORD"}).Path(); path != "" {
+		t.Errorf("unexpected path %s", path)
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package servicedefinition
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// SecurityContext restricts the privileges of a service's containers.
+// Services without a security context run as root with Docker's default
+// capabilities, and the root filesystem of their containers is writable.
+type SecurityContext struct {
+	RunAsUser              string   // User name or uid that the container runs as
+	RunAsGroup             string   // Group name or gid that the container runs as
+	CapAdd                 []string // Linux capabilities to add, such as NET_BIND_SERVICE
+	CapDrop                []string // Linux capabilities to drop, or ALL
+	ReadOnlyRootFilesystem bool     // Mount the container's root filesystem read-only
+	WritablePaths          []string // Container paths that stay writable when the root filesystem is read-only
+	NoNewPrivileges        bool     // Prevent processes from gaining privileges, such as through setuid binaries
+	SeccompProfile         string   // "unconfined" or the name of a seccomp profile in the seccomp directory of serviced's etc path; empty is Docker's default
+	AppArmorProfile        string   // "unconfined" or the name of an AppArmor profile loaded on the host
+}
+
+// Unconfined disables a seccomp or AppArmor profile
+const Unconfined = "unconfined"
+
+// capabilities are the Linux capabilities that Docker can add or drop
+var capabilities = map[string]bool{
+	"ALL": true, "AUDIT_CONTROL": true, "AUDIT_READ": true, "AUDIT_WRITE": true,
+	"BLOCK_SUSPEND": true, "CHOWN": true, "DAC_OVERRIDE": true,
+	"DAC_READ_SEARCH": true, "FOWNER": true, "FSETID": true, "IPC_LOCK": true,
+	"IPC_OWNER": true, "KILL": true, "LEASE": true, "LINUX_IMMUTABLE": true,
+	"MAC_ADMIN": true, "MAC_OVERRIDE": true, "MKNOD": true, "NET_ADMIN": true,
+	"NET_BIND_SERVICE": true, "NET_BROADCAST": true, "NET_RAW": true,
+	"SETFCAP": true, "SETGID": true, "SETPCAP": true, "SETUID": true,
+	"SYS_ADMIN": true, "SYS_BOOT": true, "SYS_CHROOT": true, "SYS_MODULE": true,
+	"SYS_NICE": true, "SYS_PACCT": true, "SYS_PTRACE": true, "SYS_RAWIO": true,
+	"SYS_RESOURCE": true, "SYS_TIME": true, "SYS_TTY_CONFIG": true,
+	"SYSLOG": true, "WAKE_ALARM": true,
+}
+
+var (
+	// a user or group is a number, or a name as accepted by useradd
+	accountRegexp = regexp.MustCompile(`^([0-9]+|[a-zA-Z_][a-zA-Z0-9_.-]{0,31}\$?)$`)
+	profileRegexp = regexp.MustCompile(`^[a-zA-Z0-9_./-]+$`)
+	// a seccomp profile is a file name without its .json extension
+	seccompRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-][a-zA-Z0-9_.-]*$`)
+)
+
+// Validate returns an error if the security context has an invalid user,
+// group, capability, path or profile
+func (sc SecurityContext) Validate() error {
+	if sc.RunAsUser != "" && !accountRegexp.MatchString(sc.RunAsUser) {
+		return fmt.Errorf("invalid run as user %q", sc.RunAsUser)
+	}
+	if sc.RunAsGroup != "" && !accountRegexp.MatchString(sc.RunAsGroup) {
+		return fmt.Errorf("invalid run as group %q", sc.RunAsGroup)
+	}
+	dropped := make(map[string]bool)
+	for _, c := range sc.CapDrop {
+		name := capabilityName(c)
+		if !capabilities[name] {
+			return fmt.Errorf("unknown capability %q to drop", c)
+		}
+		dropped[name] = true
+	}
+	for _, c := range sc.CapAdd {
+		name := capabilityName(c)
+		if !capabilities[name] {
+			return fmt.Errorf("unknown capability %q to add", c)
+		}
+		if dropped[name] {
+			return fmt.Errorf("capability %s is both added and dropped", name)
+		}
+	}
+	if len(sc.WritablePaths) > 0 && !sc.ReadOnlyRootFilesystem {
+		return fmt.Errorf("writable paths require a read-only root filesystem")
+	}
+	for _, p := range sc.WritablePaths {
+		if !path.IsAbs(p) || path.Clean(p) != p || p == "/" {
+			return fmt.Errorf("writable path %q is not a clean absolute path below /", p)
+		}
+	}
+	if p := sc.SeccompProfile; p != "" && !seccompRegexp.MatchString(p) {
+		return fmt.Errorf("seccomp profile %q is neither %s nor the name of a profile", p, Unconfined)
+	}
+	if p := sc.AppArmorProfile; p != "" && !profileRegexp.MatchString(p) {
+		return fmt.Errorf("invalid AppArmor profile name %q", p)
+	}
+	return nil
+}
+
+// ValidateService returns an error if the security context is invalid, or if
+// a service with the given privileges, config files and volumes cannot run
+// with it.  The controller writes config files as the user of the container
+// before starting the service.  If that is root, they must be in a writable
+// path or a volume.  Otherwise the writable paths and the root filesystem
+// belong to root, so they must be in a volume owned by the user, and cannot
+// be given an owner.
+func (sc SecurityContext) ValidateService(privileged bool, configFiles map[string]ConfigFile, volumes []Volume) error {
+	if err := sc.Validate(); err != nil {
+		return err
+	}
+	if privileged {
+		return fmt.Errorf("a privileged service cannot have a security context")
+	}
+	for _, cf := range configFiles {
+		if sc.RunsAsRoot() {
+			if !sc.Writable(cf.Filename) && !inVolume(cf.Filename, volumes, "") {
+				return fmt.Errorf("config file %s is not in a writable path", cf.Filename)
+			}
+			continue
+		}
+		if !inVolume(cf.Filename, volumes, sc.RunAsUser) {
+			return fmt.Errorf("config file %s is not in a volume owned by %s, the user the service runs as", cf.Filename, sc.RunAsUser)
+		}
+		if cf.Owner != "" {
+			return fmt.Errorf("config file %s cannot be given an owner by a service that does not run as root", cf.Filename)
+		}
+	}
+	return nil
+}
+
+// User returns the user, and the group if one is set, in the form that
+// Docker accepts.  It is empty if the context does not set a user or group.
+func (sc *SecurityContext) User() string {
+	if sc == nil || (sc.RunAsUser == "" && sc.RunAsGroup == "") {
+		return ""
+	}
+	user := sc.RunAsUser
+	if user == "" {
+		user = "root"
+	}
+	if sc.RunAsGroup != "" {
+		user += ":" + sc.RunAsGroup
+	}
+	return user
+}
+
+// RunsAsRoot returns true if the container runs as root.  It is true for a
+// nil security context.
+func (sc *SecurityContext) RunsAsRoot() bool {
+	return sc == nil || sc.RunAsUser == "" || sc.RunAsUser == "root" || strings.TrimLeft(sc.RunAsUser, "0") == ""
+}
+
+// Capabilities returns the capabilities to add and to drop without their
+// CAP_ prefixes
+func (sc SecurityContext) Capabilities() (add []string, drop []string) {
+	for _, c := range sc.CapAdd {
+		add = append(add, capabilityName(c))
+	}
+	for _, c := range sc.CapDrop {
+		drop = append(drop, capabilityName(c))
+	}
+	return
+}
+
+// Writable returns true if a container path is in one of the writable paths
+func (sc SecurityContext) Writable(containerPath string) bool {
+	if !sc.ReadOnlyRootFilesystem {
+		return true
+	}
+	for _, p := range sc.WritablePaths {
+		if isPathUnder(containerPath, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// inVolume returns true if a container path is in one of the volumes, and
+// the volume belongs to the owner if one is given.  Temporary volumes are
+// created by Docker and always belong to root.
+func inVolume(containerPath string, volumes []Volume, owner string) bool {
+	for _, v := range volumes {
+		if v.ContainerPath == "" || !isPathUnder(containerPath, path.Clean(v.ContainerPath)) {
+			continue
+		}
+		if owner == "" || (v.Type != "tmp" && strings.SplitN(v.Owner, ":", 2)[0] == owner) {
+			return true
+		}
+	}
+	return false
+}
+
+func capabilityName(c string) string {
+	return strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(c)), "CAP_")
+}
+
+// isPathUnder returns true if p is dir or a path below it
+func isPathUnder(p, dir string) bool {
+	p = path.Clean(p)
+	return p == dir || strings.HasPrefix(p, strings.TrimSuffix(dir, "/")+"/")
+}
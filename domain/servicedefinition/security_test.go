@@ -0,0 +1,147 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build unit
+
+package servicedefinition_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	. "github.com/control-center/serviced/domain/servicedefinition"
+	. "github.com/control-center/serviced/domain/servicedefinition/testutils"
+)
+
+func TestSecurityContextValidate(t *testing.T) {
+	for _, tc := range []struct {
+		sc    SecurityContext
+		error string
+	}{
+		{SecurityContext{}, ""},
+		{SecurityContext{RunAsUser: "zenoss", RunAsGroup: "1201"}, ""},
+		{SecurityContext{RunAsUser: "0"}, ""},
+		{SecurityContext{RunAsUser: "bad user"}, "invalid run as user"},
+		{SecurityContext{RunAsGroup: "-1"}, "invalid run as group"},
+		{SecurityContext{CapDrop: []string{"ALL"}, CapAdd: []string{"CAP_NET_BIND_SERVICE", "chown"}}, ""},
+		{SecurityContext{CapAdd: []string{"FLY"}}, "unknown capability \"FLY\" to add"},
+		{SecurityContext{CapDrop: []string{"NET_RAW"}, CapAdd: []string{"cap_net_raw"}}, "both added and dropped"},
+		{SecurityContext{ReadOnlyRootFilesystem: true, WritablePaths: []string{"/var/log", "/opt/app/data"}}, ""},
+		{SecurityContext{WritablePaths: []string{"/var/log"}}, "require a read-only root filesystem"},
+		{SecurityContext{ReadOnlyRootFilesystem: true, WritablePaths: []string{"var/log"}}, "not a clean absolute path"},
+		{SecurityContext{ReadOnlyRootFilesystem: true, WritablePaths: []string{"/"}}, "not a clean absolute path"},
+		{SecurityContext{SeccompProfile: "unconfined", AppArmorProfile: "unconfined"}, ""},
+		{SecurityContext{SeccompProfile: "app-v1.2", AppArmorProfile: "docker-default"}, ""},
+		{SecurityContext{SeccompProfile: "/etc/serviced/seccomp.json"}, "seccomp profile"},
+		{SecurityContext{SeccompProfile: "../seccomp"}, "seccomp profile"},
+		{SecurityContext{SeccompProfile: ".."}, "seccomp profile"},
+		{SecurityContext{AppArmorProfile: "bad profile"}, "invalid AppArmor profile"},
+	} {
+		err := tc.sc.Validate()
+		if tc.error == "" && err != nil {
+			t.Errorf("%+v: unexpected error %s", tc.sc, err)
+		} else if tc.error != "" && (err == nil || !strings.Contains(err.Error(), tc.error)) {
+			t.Errorf("%+v: expected error containing %q, got %v", tc.sc, tc.error, err)
+		}
+	}
+}
+
+func TestSecurityContextUser(t *testing.T) {
+	var nilContext *SecurityContext
+	for _, tc := range []struct {
+		sc   *SecurityContext
+		user string
+		root bool
+	}{
+		{nilContext, "", true},
+		{&SecurityContext{}, "", true},
+		{&SecurityContext{RunAsUser: "zenoss"}, "zenoss", false},
+		{&SecurityContext{RunAsUser: "1201", RunAsGroup: "1201"}, "1201:1201", false},
+		{&SecurityContext{RunAsGroup: "zenoss"}, "root:zenoss", true},
+		{&SecurityContext{RunAsUser: "0"}, "0", true},
+	} {
+		if user := tc.sc.User(); user != tc.user {
+			t.Errorf("%+v: expected user %q, got %q", tc.sc, tc.user, user)
+		}
+		if root := tc.sc.RunsAsRoot(); root != tc.root {
+			t.Errorf("%+v: expected runs as root %v, got %v", tc.sc, tc.root, root)
+		}
+	}
+}
+
+func TestSecurityContextCapabilities(t *testing.T) {
+	sc := SecurityContext{CapAdd: []string{"cap_sys_time", "KILL"}, CapDrop: []string{" all "}}
+	add, drop := sc.Capabilities()
+	if !reflect.DeepEqual(add, []string{"SYS_TIME", "KILL"}) {
+		t.Errorf("unexpected capabilities to add: %v", add)
+	}
+	if !reflect.DeepEqual(drop, []string{"ALL"}) {
+		t.Errorf("unexpected capabilities to drop: %v", drop)
+	}
+}
+
+func TestServiceDefinitionSecurityContext(t *testing.T) {
+	sd := CreateValidServiceDefinition()
+	svc := &sd.Services[0]
+	svc.ConfigFiles = map[string]ConfigFile{
+		"/opt/app/etc/app.conf": {Filename: "/opt/app/etc/app.conf", Content: "debug = false"},
+	}
+	svc.Volumes = []Volume{{ResourcePath: "data", ContainerPath: "/opt/app/data", Owner: "app:app"}}
+	svc.SecurityContext = &SecurityContext{
+		CapDrop:                []string{"ALL"},
+		ReadOnlyRootFilesystem: true,
+		WritablePaths:          []string{"/opt/app/etc"},
+	}
+	if err := sd.ValidEntity(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	// config files in volumes are writable, even by a service that does
+	// not run as root if the volume belongs to its user
+	svc.ConfigFiles = map[string]ConfigFile{
+		"/opt/app/data/seed.json": {Filename: "/opt/app/data/seed.json"},
+	}
+	svc.SecurityContext.RunAsUser = "app"
+	if err := sd.ValidEntity(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	for _, tc := range []struct {
+		change func()
+		error  string
+	}{
+		{func() { svc.Volumes = nil }, "config file /opt/app/data/seed.json is not in a volume owned by app"},
+		{func() { svc.Volumes[0].Owner = "root:root" }, "config file /opt/app/data/seed.json is not in a volume owned by app"},
+		{func() { svc.Volumes[0].Type = "tmp" }, "config file /opt/app/data/seed.json is not in a volume owned by app"},
+		{func() {
+			svc.SecurityContext.WritablePaths = []string{"/opt/app/etc"}
+			svc.ConfigFiles = map[string]ConfigFile{"/opt/app/etc/app.conf": {Filename: "/opt/app/etc/app.conf"}}
+		}, "config file /opt/app/etc/app.conf is not in a volume owned by app"},
+		{func() { svc.SecurityContext.RunAsUser = ""; svc.Volumes = nil }, "config file /opt/app/data/seed.json is not in a writable path"},
+		{func() { svc.ConfigFiles["/opt/app/data/seed.json"] = ConfigFile{Filename: "/opt/app/data/seed.json", Owner: "app:app"} }, "cannot be given an owner"},
+		{func() { svc.Privileged = true }, "a privileged service cannot have a security context"},
+		{func() { svc.SecurityContext.CapAdd = []string{"WARP"} }, "unknown capability"},
+	} {
+		tc.change()
+		if err := sd.ValidEntity(); err == nil || !strings.Contains(err.Error(), tc.error) {
+			t.Errorf("Expected error containing %q, got %v", tc.error, err)
+		}
+		svc.Volumes = []Volume{{ResourcePath: "data", ContainerPath: "/opt/app/data", Owner: "app:app"}}
+		svc.ConfigFiles = map[string]ConfigFile{
+			"/opt/app/data/seed.json": {Filename: "/opt/app/data/seed.json"},
+		}
+		svc.Privileged = false
+		svc.SecurityContext = &SecurityContext{RunAsUser: "app", ReadOnlyRootFilesystem: true}
+	}
+}
@@ -42,8 +42,14 @@ type ServiceDefinition struct {
 	ChangeOptions          []ChangeOption         // Control options for what happens when a running service is changed
 	Launch                 string                 // Must be "AUTO", the default, or "MANUAL"
 	HostPolicy             HostPolicy             // Policy for starting up instances
+	NodeSelector           map[string]string      // Labels that a host must have to run instances
+	Affinity               []AffinityRule         // Services whose instances this service should run with
+	AntiAffinity           []AffinityRule         // Services whose instances this service should run apart from
+	RolloutPolicy          *RolloutPolicy         // How instances are replaced on a restart or an image change
 	Hostname               string                 // Optional hostname which should be set on run
 	Privileged             bool                   // Whether to run the container with extended privileges
+	SecurityContext        *SecurityContext       // Restrictions on the privileges of the container
+	Secrets                []SecretRef            // Secrets delivered to the container when it starts
 	ConfigFiles            map[string]ConfigFile  // Config file templates
 	Context                map[string]interface{} // Context information for the service
 	Endpoints              []EndpointDefinition   // Comms endpoints used by the service
@@ -171,6 +177,13 @@ const (
 	RequireSeparate = "REQUIRE_SEPARATE"
 )
 
+// AffinityRule places the instances of a service relative to the running
+// instances of another service in the same application.
+type AffinityRule struct {
+	Service  string // Name of the other service
+	Required bool   // If false, hosts that meet the rule are preferred, but not required
+}
+
 // UnmarshalText implements the encoding/TextUnmarshaler interface
 func (p *HostPolicy) UnmarshalText(b []byte) error {
 	s := strings.Trim(string(b), `"`)
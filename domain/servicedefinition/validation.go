@@ -66,9 +66,69 @@ func (sd *ServiceDefinition) validate(context *validationContext) error {
 		return fmt.Errorf("service definition %v: invalid monitoring profile %s", sd.Name, err)
 	}
 
+	// validate placement rules
+	if err := validPlacement(sd); err != nil {
+		return fmt.Errorf("service definition %v: %v", sd.Name, err)
+	}
+
+	// validate the rollout policy
+	if sd.RolloutPolicy != nil {
+		if err := sd.RolloutPolicy.Validate(); err != nil {
+			return fmt.Errorf("service definition %v: %v", sd.Name, err)
+		}
+	}
+
+	// validate the security context
+	if sd.SecurityContext != nil {
+		if err := sd.SecurityContext.ValidateService(sd.Privileged, sd.ConfigFiles, sd.Volumes); err != nil {
+			return fmt.Errorf("service definition %v: invalid security context: %v", sd.Name, err)
+		}
+	}
+
+	// validate the secrets
+	if err := ValidateSecrets(sd.Secrets); err != nil {
+		return fmt.Errorf("service definition %v: %v", sd.Name, err)
+	}
+
+	// validate health checks
+	for name, hc := range sd.HealthChecks {
+		if err := hc.ValidEntity(); err != nil {
+			return fmt.Errorf("service definition %v: invalid health check %s: %s", sd.Name, name, err)
+		}
+	}
+
 	return validServiceDefinitions(&sd.Services, context)
 }
 
+// validPlacement verifies the node selector and the affinity rules of a
+// service definition
+func validPlacement(sd *ServiceDefinition) error {
+	for key := range sd.NodeSelector {
+		if strings.TrimSpace(key) == "" {
+			return fmt.Errorf("empty node selector label")
+		}
+	}
+	affinity := make(map[string]struct{})
+	for _, rule := range sd.Affinity {
+		if strings.TrimSpace(rule.Service) == "" {
+			return fmt.Errorf("affinity rule is missing a service")
+		}
+		if rule.Service == sd.Name {
+			return fmt.Errorf("service cannot have affinity to itself")
+		}
+		affinity[rule.Service] = struct{}{}
+	}
+	for _, rule := range sd.AntiAffinity {
+		if strings.TrimSpace(rule.Service) == "" {
+			return fmt.Errorf("anti-affinity rule is missing a service")
+		}
+		if _, ok := affinity[rule.Service]; ok {
+			return fmt.Errorf("service %s has both affinity and anti-affinity rules", rule.Service)
+		}
+	}
+	return nil
+}
+
 // validServiceDefinitions validates an array of ServiceDefinition recursively
 func validServiceDefinitions(ds *[]ServiceDefinition, context *validationContext) error {
 	for _, sd := range *ds {
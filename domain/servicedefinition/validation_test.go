@@ -137,3 +137,30 @@ func TestServiceDefinitionEmptyEndpointName(t *testing.T) {
 		t.Errorf("Unexpected Error %v", err)
 	}
 }
+
+func TestServiceDefinitionPlacementRules(t *testing.T) {
+	sd := CreateValidServiceDefinition()
+	sd.Services[0].NodeSelector = map[string]string{"disk": "ssd"}
+	sd.Services[0].Affinity = []AffinityRule{{Service: "redis", Required: true}}
+	sd.Services[0].AntiAffinity = []AffinityRule{{Service: sd.Services[0].Name}}
+	if err := sd.ValidEntity(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	sd.Services[0].AntiAffinity = []AffinityRule{{Service: "redis"}}
+	err := sd.ValidEntity()
+	if err == nil {
+		t.Error("Expected error")
+	} else if !strings.Contains(err.Error(), "both affinity and anti-affinity") {
+		t.Errorf("Unexpected Error %v", err)
+	}
+
+	sd.Services[0].AntiAffinity = nil
+	sd.Services[0].Affinity = []AffinityRule{{Service: ""}}
+	err = sd.ValidEntity()
+	if err == nil {
+		t.Error("Expected error")
+	} else if !strings.Contains(err.Error(), "missing a service") {
+		t.Errorf("Unexpected Error %v", err)
+	}
+}
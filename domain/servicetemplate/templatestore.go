@@ -17,7 +17,6 @@ import (
 	"fmt"
 
 	"github.com/control-center/serviced/datastore"
-	"github.com/zenoss/elastigo/search"
 )
 
 //NewStore creates a ResourcePool store
@@ -76,8 +75,7 @@ func (s *storeImpl) Delete(ctx datastore.Context, id string) error {
 // GetServiceTemplates returns all ServiceTemplates
 func (s *storeImpl) GetServiceTemplates(ctx datastore.Context) ([]*ServiceTemplate, error) {
 	q := datastore.NewQuery(ctx)
-	query := search.Query().Search("_exists_:ID")
-	search := search.Search("controlplane").Type(kind).Query(query)
+	search := datastore.NewSearch(kind).Where(datastore.Exists("ID"))
 	results, err := q.Execute(search)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,38 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package servicetemplate
+
+import (
+	"github.com/control-center/serviced/domain/service"
+)
+
+// UpgradeRequest is a request to merge a new service template into a
+// deployed tenant
+type UpgradeRequest struct {
+	TenantID       string // Id of the deployed tenant
+	TemplateID     string // Id of the template to upgrade to
+	BaseTemplateID string // Id of the template the tenant was deployed from, if the tenant does not record it
+	DryRun         bool   // Report the changes without making them
+	KeepLocal      bool   // Keep locally edited fields that conflict with the new template
+}
+
+// Upgrade describes the merge of a new service template into a deployed
+// tenant
+type Upgrade struct {
+	TenantID       string
+	BaseTemplateID string
+	TemplateID     string
+	Changes        []service.ServiceChange
+	Conflicts      []service.ServiceConflict
+}
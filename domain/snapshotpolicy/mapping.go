@@ -0,0 +1,59 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshotpolicy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/control-center/serviced/datastore"
+	"github.com/control-center/serviced/datastore/elastic"
+	"github.com/control-center/serviced/logging"
+)
+
+const kind = "snapshotpolicy"
+
+var (
+	plog          = logging.PackageLogger()
+	mappingString = fmt.Sprintf(`
+{
+    "%s": {
+        "properties": {
+            "TenantID":        {"type": "string", "index": "not_analyzed"},
+            "Schedule":        {"type": "string", "index": "not_analyzed"},
+            "Quiesce":         {"type": "boolean"},
+            "KeepHourly":      {"type": "long"},
+            "KeepDaily":       {"type": "long"},
+            "KeepWeekly":      {"type": "long"},
+            "MaxSpacePercent": {"type": "long"},
+            "LastRun":         {"type": "date", "format": "dateOptionalTime"}
+        }
+    }
+}
+`, kind)
+	// MAPPING is the elastic mapping for snapshot policies
+	MAPPING, mappingError = elastic.NewMapping(mappingString)
+)
+
+func init() {
+	if mappingError != nil {
+		plog.WithError(mappingError).Fatal("error creating mapping for the snapshot policy object")
+	}
+}
+
+// Key returns the datastore key of the snapshot policy of a tenant
+func Key(tenantID string) datastore.Key {
+	tenantID = strings.TrimSpace(tenantID)
+	return datastore.NewKey(kind, tenantID)
+}
@@ -0,0 +1,77 @@
+package mocks
+
+import "github.com/control-center/serviced/domain/snapshotpolicy"
+import "github.com/stretchr/testify/mock"
+
+import "github.com/control-center/serviced/datastore"
+
+type Store struct {
+	mock.Mock
+}
+
+func (_m *Store) Get(ctx datastore.Context, tenantID string) (*snapshotpolicy.SnapshotPolicy, error) {
+	ret := _m.Called(ctx, tenantID)
+
+	var r0 *snapshotpolicy.SnapshotPolicy
+	if rf, ok := ret.Get(0).(func(datastore.Context, string) *snapshotpolicy.SnapshotPolicy); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*snapshotpolicy.SnapshotPolicy)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(datastore.Context, string) error); ok {
+		r1 = rf(ctx, tenantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+func (_m *Store) Put(ctx datastore.Context, p *snapshotpolicy.SnapshotPolicy) error {
+	ret := _m.Called(ctx, p)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(datastore.Context, *snapshotpolicy.SnapshotPolicy) error); ok {
+		r0 = rf(ctx, p)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+func (_m *Store) Delete(ctx datastore.Context, tenantID string) error {
+	ret := _m.Called(ctx, tenantID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(datastore.Context, string) error); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+func (_m *Store) GetSnapshotPolicies(ctx datastore.Context) ([]*snapshotpolicy.SnapshotPolicy, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []*snapshotpolicy.SnapshotPolicy
+	if rf, ok := ret.Get(0).(func(datastore.Context) []*snapshotpolicy.SnapshotPolicy); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*snapshotpolicy.SnapshotPolicy)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(datastore.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -0,0 +1,63 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshotpolicy
+
+import (
+	"time"
+
+	"github.com/control-center/serviced/datastore"
+)
+
+// Message is the description of the snapshots that are taken by a policy.
+// Retention only applies to snapshots with this description, so snapshots
+// that are taken by hand are left alone.
+const Message = "scheduled snapshot"
+
+// SnapshotPolicy schedules the snapshots of a tenant and decides how many of
+// them are kept
+type SnapshotPolicy struct {
+	TenantID        string
+	Schedule        string    // cron expression, e.g. "0 */4 * * *" or "@daily"
+	Quiesce         bool      // pause the services of the tenant during the snapshot
+	KeepHourly      int       // number of hours to keep the newest snapshot of
+	KeepDaily       int       // number of days to keep the newest snapshot of
+	KeepWeekly      int       // number of weeks to keep the newest snapshot of
+	MaxSpacePercent int       // snapshot space percent; zero uses the daemon setting
+	LastRun         time.Time // when the policy was set or last ran
+	datastore.VersionedEntity
+}
+
+// Next returns the next time the policy is due after it last ran
+func (p *SnapshotPolicy) Next() (time.Time, error) {
+	sched, err := ParseSchedule(p.Schedule)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return sched.Next(p.LastRun), nil
+}
+
+// GetType returns the datastore kind of a snapshot policy
+func GetType() string {
+	return kind
+}
+
+// GetType returns the datastore kind of a snapshot policy
+func (p *SnapshotPolicy) GetType() string {
+	return GetType()
+}
+
+// GetID returns the tenant of the snapshot policy
+func (p *SnapshotPolicy) GetID() string {
+	return p.TenantID
+}
@@ -0,0 +1,110 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build unit integration
+
+package snapshotpolicy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/control-center/serviced/validation"
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+type unitTestSuite struct{}
+
+var _ = Suite(&unitTestSuite{})
+
+func date(year int, month time.Month, day, hour, minute int) time.Time {
+	return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+}
+
+func (s *unitTestSuite) TestParseSchedule_Invalid(c *C) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"@yearly",
+	} {
+		_, err := ParseSchedule(expr)
+		c.Check(err, ErrorMatches, "invalid schedule.*", Commentf("%v", expr))
+	}
+}
+
+func (s *unitTestSuite) TestScheduleNext(c *C) {
+	now := date(2018, time.March, 14, 10, 7) // a Wednesday
+	for _, t := range []struct {
+		expr string
+		next time.Time
+	}{
+		{"* * * * *", date(2018, time.March, 14, 10, 8)},
+		{"@hourly", date(2018, time.March, 14, 11, 0)},
+		{"*/15 * * * *", date(2018, time.March, 14, 10, 15)},
+		{"30 */4 * * *", date(2018, time.March, 14, 12, 30)},
+		{"@daily", date(2018, time.March, 15, 0, 0)},
+		{"0 2 * * 1-5", date(2018, time.March, 15, 2, 0)},
+		{"@weekly", date(2018, time.March, 18, 0, 0)},
+		{"0 0 * * 7", date(2018, time.March, 18, 0, 0)},
+		{"0 3 1,15 * *", date(2018, time.March, 15, 3, 0)},
+		{"0 0 1 * 6", date(2018, time.March, 17, 0, 0)}, // day of month or week
+		{"0 0 29 2 *", date(2020, time.February, 29, 0, 0)},
+		{"5 10 14 3 *", date(2019, time.March, 14, 10, 5)},
+	} {
+		sched, err := ParseSchedule(t.expr)
+		c.Assert(err, IsNil, Commentf("%v", t.expr))
+		c.Check(sched.Next(now), Equals, t.next, Commentf("%v", t.expr))
+	}
+}
+
+func (s *unitTestSuite) TestScheduleNext_Never(c *C) {
+	sched, err := ParseSchedule("0 0 30 2 *")
+	c.Assert(err, IsNil)
+	c.Assert(sched.Next(date(2018, time.January, 1, 0, 0)).IsZero(), Equals, true)
+}
+
+func (s *unitTestSuite) TestSnapshotPolicyNext(c *C) {
+	p := &SnapshotPolicy{Schedule: "0 */6 * * *", LastRun: date(2018, time.March, 14, 6, 0)}
+	next, err := p.Next()
+	c.Assert(err, IsNil)
+	c.Assert(next, Equals, date(2018, time.March, 14, 12, 0))
+}
+
+func (s *unitTestSuite) TestValidEntity(c *C) {
+	p := &SnapshotPolicy{TenantID: "tenant", Schedule: "@daily", KeepDaily: 7}
+	c.Assert(p.ValidEntity(), IsNil)
+
+	for _, invalid := range []SnapshotPolicy{
+		{Schedule: "@daily", KeepDaily: 7},
+		{TenantID: "tenant", Schedule: "daily", KeepDaily: 7},
+		{TenantID: "tenant", Schedule: "0 0 31 2 *", KeepDaily: 7},
+		{TenantID: "tenant", Schedule: "@daily"},
+		{TenantID: "tenant", Schedule: "@daily", KeepDaily: 7, KeepWeekly: -1},
+		{TenantID: "tenant", Schedule: "@daily", KeepDaily: 7, MaxSpacePercent: 101},
+	} {
+		err := invalid.ValidEntity()
+		c.Check(err, FitsTypeOf, &validation.ValidationError{}, Commentf("%v", invalid))
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshotpolicy
+
+import (
+	"github.com/control-center/serviced/datastore"
+)
+
+// Store manages snapshot policies in the datastore
+type Store interface {
+	// Get the snapshot policy of a tenant.  Return ErrNoSuchEntity if not found
+	Get(ctx datastore.Context, tenantID string) (*SnapshotPolicy, error)
+
+	// Put adds or updates a snapshot policy
+	Put(ctx datastore.Context, p *SnapshotPolicy) error
+
+	// Delete removes the snapshot policy of a tenant if it exists
+	Delete(ctx datastore.Context, tenantID string) error
+
+	// GetSnapshotPolicies returns all snapshot policies
+	GetSnapshotPolicies(ctx datastore.Context) ([]*SnapshotPolicy, error)
+}
+
+type storeImpl struct {
+	ds datastore.DataStore
+}
+
+// NewStore returns a new snapshot policy store
+func NewStore() Store {
+	return &storeImpl{}
+}
+
+func (s *storeImpl) Get(ctx datastore.Context, tenantID string) (*SnapshotPolicy, error) {
+	defer ctx.Metrics().Stop(ctx.Metrics().Start("SnapshotPolicyStore.Get"))
+	val := &SnapshotPolicy{}
+	if err := s.ds.Get(ctx, Key(tenantID), val); err != nil {
+		return nil, err
+	}
+	return val, nil
+}
+
+func (s *storeImpl) Put(ctx datastore.Context, val *SnapshotPolicy) error {
+	defer ctx.Metrics().Stop(ctx.Metrics().Start("SnapshotPolicyStore.Put"))
+	return s.ds.Put(ctx, Key(val.TenantID), val)
+}
+
+func (s *storeImpl) Delete(ctx datastore.Context, tenantID string) error {
+	defer ctx.Metrics().Stop(ctx.Metrics().Start("SnapshotPolicyStore.Delete"))
+	return s.ds.Delete(ctx, Key(tenantID))
+}
+
+func (s *storeImpl) GetSnapshotPolicies(ctx datastore.Context) ([]*SnapshotPolicy, error) {
+	defer ctx.Metrics().Stop(ctx.Metrics().Start("SnapshotPolicyStore.GetSnapshotPolicies"))
+	q := datastore.NewQuery(ctx)
+	search := datastore.NewSearch(kind).Where(datastore.Exists("TenantID"))
+	results, err := q.Execute(search)
+	if err != nil {
+		return nil, err
+	}
+	policies := make([]*SnapshotPolicy, results.Len())
+	for i := range policies {
+		val := &SnapshotPolicy{}
+		if err := results.Get(i, val); err != nil {
+			return nil, err
+		}
+		policies[i] = val
+	}
+	return policies, nil
+}
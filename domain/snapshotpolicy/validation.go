@@ -0,0 +1,44 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshotpolicy
+
+import (
+	"fmt"
+
+	"github.com/control-center/serviced/validation"
+)
+
+// ValidEntity makes sure that a snapshot policy has a tenant, a schedule
+// that fires and keeps at least one snapshot
+func (p *SnapshotPolicy) ValidEntity() error {
+	violations := validation.NewValidationError()
+	violations.Add(validation.NotEmpty("TenantID", p.TenantID))
+	if sched, err := ParseSchedule(p.Schedule); err != nil {
+		violations.Add(validation.NewViolation(err.Error()))
+	} else if sched.Next(p.LastRun).IsZero() {
+		violations.Add(validation.NewViolation(fmt.Sprintf("schedule %q never runs", p.Schedule)))
+	}
+	if p.KeepHourly < 0 || p.KeepDaily < 0 || p.KeepWeekly < 0 {
+		violations.Add(validation.NewViolation("snapshot retention counts cannot be negative"))
+	} else if p.KeepHourly+p.KeepDaily+p.KeepWeekly == 0 {
+		violations.Add(validation.NewViolation("snapshot policy must keep at least one snapshot"))
+	}
+	if p.MaxSpacePercent < 0 || p.MaxSpacePercent > 100 {
+		violations.Add(validation.NewViolation(fmt.Sprintf("max space percent %d is not between 0 and 100", p.MaxSpacePercent)))
+	}
+	if violations.HasError() {
+		return violations
+	}
+	return nil
+}
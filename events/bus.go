@@ -0,0 +1,187 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// DefaultHistory is the number of events kept by a bus if no limit is given
+const DefaultHistory = 1000
+
+// Now is the id to pass to Subscribe or Events to skip the kept events, and
+// receive only the events that are published from now on
+const Now = math.MaxUint64
+
+// SubscriptionBuffer is the number of events that a subscriber may fall
+// behind before it is dropped
+const SubscriptionBuffer = 256
+
+// Bus publishes events to its subscribers and keeps the most recent events,
+// so that a client that reconnects can pick up where it left off.
+type Bus struct {
+	mu      sync.Mutex
+	limit   int
+	lastID  uint64
+	history []Event
+	subs    map[*Subscription]struct{}
+}
+
+// NewBus returns a bus that keeps up to limit events
+func NewBus(limit int) *Bus {
+	if limit <= 0 {
+		limit = DefaultHistory
+	}
+	return &Bus{limit: limit, subs: make(map[*Subscription]struct{})}
+}
+
+// Publish assigns the event an id and a timestamp, if it does not have one,
+// and sends it to every subscriber whose filter matches it.  Publish never
+// blocks; a subscriber that is not keeping up is closed.
+func (b *Bus) Publish(event Event) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lastID++
+	event.ID = b.lastID
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now().UTC()
+	}
+	b.history = append(b.history, event)
+	if over := len(b.history) - b.limit; over > 0 {
+		b.history = append([]Event{}, b.history[over:]...)
+	}
+	for sub := range b.subs {
+		if !sub.filter.Match(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			sub.overflowed = true
+			b.unsubscribe(sub)
+		}
+	}
+	return event
+}
+
+// Events returns the events after the given id that match the filter, oldest
+// first
+func (b *Bus) Events(filter Filter, since uint64) []Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.events(filter, since)
+}
+
+func (b *Bus) events(filter Filter, since uint64) []Event {
+	events := []Event{}
+	for _, event := range b.history {
+		if event.ID > since && filter.Match(event) {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// Wait returns the events after the given id that match the filter.  If
+// there are none, it waits up to timeout for the next one.
+func (b *Bus) Wait(filter Filter, since uint64, timeout time.Duration) []Event {
+	sub := b.Subscribe(filter, since)
+	defer sub.Close()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case event, ok := <-sub.Events():
+		if !ok {
+			return []Event{}
+		}
+		events := []Event{event}
+		for {
+			select {
+			case event, ok := <-sub.Events():
+				if !ok {
+					return events
+				}
+				events = append(events, event)
+			default:
+				return events
+			}
+		}
+	case <-timer.C:
+		return []Event{}
+	}
+}
+
+// Subscribe returns a subscription to the events that match the filter,
+// starting with the kept events after the given id.  Pass the id of the last
+// event seen to resume a stream, or Now to receive only new events.
+func (b *Bus) Subscribe(filter Filter, since uint64) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	backlog := b.events(filter, since)
+	size := SubscriptionBuffer
+	if len(backlog) > size {
+		size = len(backlog)
+	}
+	sub := &Subscription{bus: b, filter: filter, events: make(chan Event, size)}
+	for _, event := range backlog {
+		sub.events <- event
+	}
+	b.subs[sub] = struct{}{}
+	return sub
+}
+
+// LastID returns the id of the most recently published event
+func (b *Bus) LastID() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.lastID
+}
+
+func (b *Bus) unsubscribe(sub *Subscription) {
+	if _, ok := b.subs[sub]; ok {
+		delete(b.subs, sub)
+		close(sub.events)
+	}
+}
+
+// Subscription receives events from a bus
+type Subscription struct {
+	bus        *Bus
+	filter     Filter
+	events     chan Event
+	overflowed bool
+}
+
+// Events returns the channel of events.  The channel is closed when the
+// subscription is closed.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Overflowed returns true if the bus closed the subscription because it fell
+// behind
+func (s *Subscription) Overflowed() bool {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	return s.overflowed
+}
+
+// Close stops the subscription
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	s.bus.unsubscribe(s)
+}
@@ -0,0 +1,118 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build unit
+
+package events_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/control-center/serviced/events"
+	. "gopkg.in/check.v1"
+)
+
+func TestEvents(t *testing.T) { TestingT(t) }
+
+type BusSuite struct{}
+
+var _ = Suite(&BusSuite{})
+
+func (s *BusSuite) TestFilter(c *C) {
+	event := Event{Type: InstanceHealth, PoolID: "default", ServiceID: "svc", HostID: "host"}
+	c.Assert(Filter{}.Match(event), Equals, true)
+	c.Assert(Filter{Types: []Type{"instance"}}.Match(event), Equals, true)
+	c.Assert(Filter{Types: []Type{"inst"}}.Match(event), Equals, false)
+	c.Assert(Filter{Types: []Type{ServiceDesiredState, InstanceHealth}}.Match(event), Equals, true)
+	c.Assert(Filter{Types: []Type{"service"}}.Match(event), Equals, false)
+	c.Assert(Filter{ServiceID: "svc", HostID: "host"}.Match(event), Equals, true)
+	c.Assert(Filter{ServiceID: "other"}.Match(event), Equals, false)
+	c.Assert(Filter{PoolID: "other"}.Match(event), Equals, false)
+	c.Assert(Filter{TenantID: "tenant"}.Match(event), Equals, false)
+}
+
+func (s *BusSuite) TestParseTypes(c *C) {
+	c.Assert(ParseTypes(""), DeepEquals, []Type{})
+	c.Assert(ParseTypes("service, instance.health,"), DeepEquals, []Type{"service", InstanceHealth})
+}
+
+func (s *BusSuite) TestHistory(c *C) {
+	bus := NewBus(2)
+	first := bus.Publish(Event{Type: HostRegistered, HostID: "a"})
+	c.Assert(first.ID, Equals, uint64(1))
+	c.Assert(first.Timestamp.IsZero(), Equals, false)
+	bus.Publish(Event{Type: HostRegistered, HostID: "b"})
+	bus.Publish(Event{Type: HostUnregistered, HostID: "a"})
+	c.Assert(bus.LastID(), Equals, uint64(3))
+
+	events := bus.Events(Filter{}, 0)
+	c.Assert(events, HasLen, 2)
+	c.Assert(events[0].HostID, Equals, "b")
+	c.Assert(events[1].ID, Equals, uint64(3))
+
+	c.Assert(bus.Events(Filter{}, 2), HasLen, 1)
+	c.Assert(bus.Events(Filter{HostID: "a"}, 0), HasLen, 1)
+}
+
+func (s *BusSuite) TestSubscribe(c *C) {
+	bus := NewBus(0)
+	bus.Publish(Event{Type: ServiceDesiredState, ServiceID: "a"})
+	bus.Publish(Event{Type: ServiceDesiredState, ServiceID: "b"})
+
+	// resume after the first event
+	sub := bus.Subscribe(Filter{Types: []Type{"service"}}, 1)
+	bus.Publish(Event{Type: HostRegistered})
+	bus.Publish(Event{Type: ServiceCurrentState, ServiceID: "c"})
+
+	event := <-sub.Events()
+	c.Assert(event.ServiceID, Equals, "b")
+	event = <-sub.Events()
+	c.Assert(event.ServiceID, Equals, "c")
+
+	sub.Close()
+	_, ok := <-sub.Events()
+	c.Assert(ok, Equals, false)
+	c.Assert(sub.Overflowed(), Equals, false)
+	// closing twice is harmless
+	sub.Close()
+}
+
+func (s *BusSuite) TestOverflow(c *C) {
+	bus := NewBus(0)
+	sub := bus.Subscribe(Filter{}, 0)
+	for i := 0; i <= SubscriptionBuffer; i++ {
+		bus.Publish(Event{Type: HostRegistered})
+	}
+	c.Assert(sub.Overflowed(), Equals, true)
+	count := 0
+	for range sub.Events() {
+		count++
+	}
+	c.Assert(count, Equals, SubscriptionBuffer)
+}
+
+func (s *BusSuite) TestWait(c *C) {
+	bus := NewBus(0)
+	bus.Publish(Event{Type: HostRegistered})
+	c.Assert(bus.Wait(Filter{}, 0, time.Second), HasLen, 1)
+	c.Assert(bus.Wait(Filter{}, 1, 10*time.Millisecond), HasLen, 0)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		bus.Publish(Event{Type: HostUnregistered})
+	}()
+	events := bus.Wait(Filter{}, 1, 5*time.Second)
+	c.Assert(events, HasLen, 1)
+	c.Assert(events[0].Type, Equals, HostUnregistered)
+}
@@ -0,0 +1,125 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"strings"
+	"time"
+)
+
+// Type is the kind of state change that an event reports.  Types are dotted,
+// so that a filter can select a group of types by its prefix.
+type Type string
+
+const (
+	// ServiceDesiredState is published when a service is started, stopped or
+	// paused
+	ServiceDesiredState Type = "service.desiredstate"
+	// ServiceCurrentState is published when a service changes its current
+	// state
+	ServiceCurrentState Type = "service.currentstate"
+	// InstanceState is published when an instance of a service changes its
+	// state.  An instance that was removed has no state.
+	InstanceState Type = "instance.state"
+	// InstanceHealth is published when a health check of an instance changes
+	// its status
+	InstanceHealth Type = "instance.health"
+	// HostRegistered is published when a host is added
+	HostRegistered Type = "host.registered"
+	// HostUnregistered is published when a host is removed
+	HostUnregistered Type = "host.unregistered"
+	// BackupProgress is published when a backup starts and finishes
+	BackupProgress Type = "dfs.backup"
+	// RestoreProgress is published when a restore starts and finishes
+	RestoreProgress Type = "dfs.restore"
+	// SnapshotProgress is published when a snapshot starts and finishes
+	SnapshotProgress Type = "dfs.snapshot"
+	// ServiceThreshold is published when a data point of a service starts or
+	// stops violating a threshold of its monitoring profile
+	ServiceThreshold Type = "service.threshold"
+)
+
+// The states of backups, restores and snapshots
+const (
+	StateStarted   = "started"
+	StateCompleted = "completed"
+	StateFailed    = "failed"
+)
+
+// The states of thresholds
+const (
+	StateViolated = "violated"
+	StateCleared  = "cleared"
+)
+
+// Event is a change to the state of a service, instance, host or the dfs
+type Event struct {
+	ID         uint64 // increases with each event published by the master
+	Type       Type
+	Timestamp  time.Time
+	PoolID     string `json:",omitempty"`
+	TenantID   string `json:",omitempty"`
+	ServiceID  string `json:",omitempty"`
+	InstanceID int    // only set for instance events
+	HostID     string `json:",omitempty"`
+	Name       string `json:",omitempty"` // name of the service, host, health check, backup or snapshot
+	State      string
+	Previous   string `json:",omitempty"` // the previous state, if it is known
+	Message    string `json:",omitempty"`
+}
+
+// Filter selects events.  Empty fields match every event.
+type Filter struct {
+	Types     []Type // types, or prefixes of types such as "service"
+	PoolID    string
+	TenantID  string
+	ServiceID string
+	HostID    string
+}
+
+// Match returns true if the filter selects the event
+func (f Filter) Match(event Event) bool {
+	if f.PoolID != "" && f.PoolID != event.PoolID {
+		return false
+	}
+	if f.TenantID != "" && f.TenantID != event.TenantID {
+		return false
+	}
+	if f.ServiceID != "" && f.ServiceID != event.ServiceID {
+		return false
+	}
+	if f.HostID != "" && f.HostID != event.HostID {
+		return false
+	}
+	if len(f.Types) == 0 {
+		return true
+	}
+	for _, t := range f.Types {
+		if t == event.Type || strings.HasPrefix(string(event.Type), string(t)+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTypes splits a comma separated list of types
+func ParseTypes(list string) []Type {
+	types := []Type{}
+	for _, t := range strings.Split(list, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			types = append(types, Type(t))
+		}
+	}
+	return types
+}
@@ -0,0 +1,319 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package facade
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/control-center/serviced/commons"
+	"github.com/control-center/serviced/datastore"
+	"github.com/control-center/serviced/domain/service"
+	"github.com/control-center/serviced/utils"
+)
+
+var (
+	// ErrNoServiceTreeRoot is returned when a service tree's root does not
+	// name an existing service
+	ErrNoServiceTreeRoot = errors.New("facade: the root of a service tree must have the id of an existing service")
+)
+
+// applySkipFields are the fields of a service that hold its runtime state,
+// which applying a service tree leaves as they are.
+var applySkipFields = []string{
+	"DesiredState",
+	"CurrentState",
+	"CreatedAt",
+	"UpdatedAt",
+	"EmergencyShutdown",
+	"TemplateID",
+	"VersionedEntity",
+}
+
+// GetServiceTree returns a service and its descendants, in the form that
+// ApplyServiceTree accepts.
+func (f *Facade) GetServiceTree(ctx datastore.Context, serviceID string) (*service.ServiceTree, error) {
+	defer ctx.Metrics().Stop(ctx.Metrics().Start("Facade.GetServiceTree"))
+	svcs, err := f.GetServiceList(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+	tree := buildServiceTree(serviceID, svcs)
+	if tree == nil {
+		return nil, datastore.ErrNoSuchEntity{Key: datastore.NewKey(service.GetType(), serviceID)}
+	}
+	return tree, nil
+}
+
+// ApplyServiceTree changes a service and its descendants to match a service
+// tree.  It adds and updates services from the root down, then removes the
+// services that are not in the tree, and returns the changes.  A dry run
+// returns the changes without making them.
+func (f *Facade) ApplyServiceTree(ctx datastore.Context, tree service.ServiceTree, dryRun bool) ([]service.ServiceChange, error) {
+	defer ctx.Metrics().Stop(ctx.Metrics().Start("Facade.ApplyServiceTree"))
+	if tree.Service == nil || tree.Service.ID == "" {
+		return nil, ErrNoServiceTreeRoot
+	}
+	current, err := f.GetServiceList(ctx, tree.Service.ID)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := planServiceTree(current, tree)
+	if err != nil {
+		return nil, err
+	}
+	if dryRun {
+		return plan.changes(), nil
+	}
+
+	logger := plog.WithField("rootserviceid", tree.Service.ID)
+	for i, step := range plan.steps {
+		slogger := logger.WithFields(log.Fields{
+			"action":    step.change.Action,
+			"serviceid": step.change.ServiceID,
+			"path":      step.change.Path,
+		})
+		switch step.change.Action {
+		case service.ChangeAdd:
+			err = f.AddService(ctx, *step.svc)
+		case service.ChangeUpdate:
+			err = f.UpdateService(ctx, *step.svc)
+		case service.ChangeRemove:
+			// removing a service removes its descendants
+			if step.topmost {
+				err = f.RemoveService(ctx, step.change.ServiceID)
+			}
+		}
+		if err != nil {
+			slogger.WithError(err).Error("Could not apply service tree")
+			return plan.changes()[:i], fmt.Errorf("could not %s service %s: %s", step.change.Action, step.change.Path, err)
+		}
+		slogger.Debug("Applied change to service")
+	}
+	logger.WithField("changes", len(plan.steps)).Info("Applied service tree")
+	return plan.changes(), nil
+}
+
+// applyStep is a change to a service, and the service as it will be stored
+type applyStep struct {
+	change  service.ServiceChange
+	svc     *service.Service
+	topmost bool // the removed service's parent is not removed
+}
+
+// serviceTreePlan is the ordered changes that apply a service tree
+type serviceTreePlan struct {
+	steps []applyStep
+}
+
+func (p *serviceTreePlan) changes() []service.ServiceChange {
+	changes := make([]service.ServiceChange, len(p.steps))
+	for i, step := range p.steps {
+		changes[i] = step.change
+	}
+	return changes
+}
+
+// planServiceTree returns the changes that make the current services, a
+// root and its descendants, match a service tree.
+func planServiceTree(current []*service.Service, tree service.ServiceTree) (*serviceTreePlan, error) {
+	if tree.Service == nil || tree.Service.ID == "" {
+		return nil, ErrNoServiceTreeRoot
+	}
+	byID := make(map[string]*service.Service)
+	children := make(map[string][]*service.Service)
+	for _, svc := range current {
+		byID[svc.ID] = svc
+		children[svc.ParentServiceID] = append(children[svc.ParentServiceID], svc)
+	}
+	if _, ok := byID[tree.Service.ID]; !ok {
+		return nil, ErrNoServiceTreeRoot
+	}
+
+	plan := &serviceTreePlan{}
+	matched := make(map[string]bool)
+	var walk func(node service.ServiceTree, parent *service.Service, parentPath string) error
+	walk = func(node service.ServiceTree, parent *service.Service, parentPath string) error {
+		if node.Service == nil {
+			return fmt.Errorf("%s: service tree has a child without a service", parentPath)
+		}
+		desired := *node.Service
+		path := desired.Name
+		if parentPath != "" {
+			path = parentPath + "/" + desired.Name
+		}
+
+		// Find the service that the node describes
+		var cur *service.Service
+		if desired.ID != "" {
+			cur = byID[desired.ID]
+		} else if parent != nil {
+			for _, child := range children[parent.ID] {
+				if child.Name == desired.Name {
+					cur = child
+					break
+				}
+			}
+		}
+		if parent != nil {
+			desired.ParentServiceID = parent.ID
+		}
+
+		if cur == nil {
+			if parent == nil {
+				return ErrNoServiceTreeRoot
+			}
+			if desired.ID == "" {
+				id, err := utils.NewUUID36()
+				if err != nil {
+					return err
+				}
+				desired.ID = id
+			}
+			if desired.PoolID == "" {
+				desired.PoolID = parent.PoolID
+			}
+			if desired.DeploymentID == "" {
+				desired.DeploymentID = parent.DeploymentID
+			}
+			if desired.Launch == "" {
+				desired.Launch = commons.AUTO
+			}
+			desired.DesiredState = int(service.SVCStop)
+			desired.DatabaseVersion = 0
+			if err := desired.ValidEntity(); err != nil {
+				return fmt.Errorf("%s: %s", path, err)
+			}
+			plan.steps = append(plan.steps, applyStep{
+				change: service.ServiceChange{Action: service.ChangeAdd, ServiceID: desired.ID, Path: path},
+				svc:    &desired,
+			})
+		} else {
+			if matched[cur.ID] {
+				return fmt.Errorf("%s: service %s is in the tree more than once", path, cur.ID)
+			}
+			desired.ID = cur.ID
+			if parent == nil {
+				desired.ParentServiceID = cur.ParentServiceID
+			}
+			if desired.PoolID == "" {
+				desired.PoolID = cur.PoolID
+			}
+			if desired.DeploymentID == "" {
+				desired.DeploymentID = cur.DeploymentID
+			}
+			desired.DesiredState = cur.DesiredState
+			desired.CurrentState = cur.CurrentState
+			desired.CreatedAt = cur.CreatedAt
+			desired.UpdatedAt = cur.UpdatedAt
+			desired.EmergencyShutdown = cur.EmergencyShutdown
+			desired.TemplateID = cur.TemplateID
+			desired.VersionedEntity = cur.VersionedEntity
+			if fields := service.DiffFields(cur, &desired, applySkipFields...); len(fields) > 0 {
+				if err := desired.ValidEntity(); err != nil {
+					return fmt.Errorf("%s: %s", path, err)
+				}
+				plan.steps = append(plan.steps, applyStep{
+					change: service.ServiceChange{Action: service.ChangeUpdate, ServiceID: desired.ID, Path: path, Fields: fields},
+					svc:    &desired,
+				})
+			}
+		}
+		matched[desired.ID] = true
+
+		names := make(map[string]bool)
+		for _, child := range node.Services {
+			if child.Service != nil {
+				if names[child.Service.Name] {
+					return fmt.Errorf("%s: more than one child is named %s", path, child.Service.Name)
+				}
+				names[child.Service.Name] = true
+			}
+			if err := walk(child, &desired, path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(tree, nil, ""); err != nil {
+		return nil, err
+	}
+
+	// Remove the services that are not in the tree, parents first
+	var remove func(node service.ServiceTree, parentPath string, parentRemoved bool) error
+	remove = func(node service.ServiceTree, parentPath string, parentRemoved bool) error {
+		svc := node.Service
+		path := svc.Name
+		if parentPath != "" {
+			path = parentPath + "/" + svc.Name
+		}
+		removed := !matched[svc.ID]
+		if removed {
+			if svc.DesiredState != int(service.SVCStop) {
+				return fmt.Errorf("%s: service must be stopped before it is removed", path)
+			}
+			plan.steps = append(plan.steps, applyStep{
+				change:  service.ServiceChange{Action: service.ChangeRemove, ServiceID: svc.ID, Path: path},
+				topmost: !parentRemoved,
+			})
+		}
+		for _, child := range node.Services {
+			if err := remove(child, path, removed); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := remove(*buildServiceTree(tree.Service.ID, current), "", false); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// buildServiceTree arranges a list of services into the tree under the root,
+// with children sorted by name.  It returns nil if the root is not listed.
+func buildServiceTree(rootID string, svcs []*service.Service) *service.ServiceTree {
+	var root *service.Service
+	children := make(map[string][]*service.Service)
+	for _, svc := range svcs {
+		if svc.ID == rootID {
+			root = svc
+		} else {
+			children[svc.ParentServiceID] = append(children[svc.ParentServiceID], svc)
+		}
+	}
+	if root == nil {
+		return nil
+	}
+	var build func(svc *service.Service) service.ServiceTree
+	build = func(svc *service.Service) service.ServiceTree {
+		node := service.ServiceTree{Service: svc}
+		kids := children[svc.ID]
+		sort.Sort(servicesByName(kids))
+		for _, kid := range kids {
+			node.Services = append(node.Services, build(kid))
+		}
+		return node
+	}
+	tree := build(root)
+	return &tree
+}
+
+type servicesByName []*service.Service
+
+func (s servicesByName) Len() int           { return len(s) }
+func (s servicesByName) Less(i, j int) bool { return s[i].Name < s[j].Name }
+func (s servicesByName) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
@@ -0,0 +1,176 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build unit
+
+package facade
+
+import (
+	"github.com/control-center/serviced/commons"
+	"github.com/control-center/serviced/domain/service"
+	. "gopkg.in/check.v1"
+)
+
+var _ = Suite(&ApplyServiceTreeTest{})
+
+type ApplyServiceTreeTest struct {
+	current []*service.Service
+}
+
+func (t *ApplyServiceTreeTest) SetUpTest(c *C) {
+	t.current = []*service.Service{
+		applyTestService("root", "", "app"),
+		applyTestService("web", "root", "web"),
+		applyTestService("db", "root", "db"),
+		applyTestService("replica", "db", "replica"),
+	}
+}
+
+func applyTestService(id, parentID, name string) *service.Service {
+	return &service.Service{
+		ID:              id,
+		ParentServiceID: parentID,
+		Name:            name,
+		PoolID:          "default",
+		DeploymentID:    "deployment",
+		Launch:          commons.AUTO,
+		DesiredState:    int(service.SVCStop),
+		Instances:       1,
+		ImageID:         "image:1",
+	}
+}
+
+// applyTestTree returns a tree that matches the current services
+func (t *ApplyServiceTreeTest) applyTestTree() service.ServiceTree {
+	tree := buildServiceTree("root", t.current)
+	return copyServiceTree(*tree)
+}
+
+func copyServiceTree(tree service.ServiceTree) service.ServiceTree {
+	svc := *tree.Service
+	node := service.ServiceTree{Service: &svc}
+	for _, child := range tree.Services {
+		node.Services = append(node.Services, copyServiceTree(child))
+	}
+	return node
+}
+
+func (t *ApplyServiceTreeTest) TestPlanServiceTree_NoChanges(c *C) {
+	plan, err := planServiceTree(t.current, t.applyTestTree())
+	c.Assert(err, IsNil)
+	c.Assert(plan.changes(), HasLen, 0)
+}
+
+func (t *ApplyServiceTreeTest) TestPlanServiceTree_NoRoot(c *C) {
+	tree := t.applyTestTree()
+	tree.Service.ID = "missing"
+	_, err := planServiceTree(t.current, tree)
+	c.Assert(err, Equals, ErrNoServiceTreeRoot)
+}
+
+func (t *ApplyServiceTreeTest) TestPlanServiceTree_Update(c *C) {
+	tree := t.applyTestTree()
+	web := tree.Services[1].Service
+	c.Assert(web.Name, Equals, "web")
+	web.ImageID = "image:2"
+	web.Instances = 3
+	// runtime state in the tree is ignored
+	web.DesiredState = int(service.SVCRun)
+
+	plan, err := planServiceTree(t.current, tree)
+	c.Assert(err, IsNil)
+	changes := plan.changes()
+	c.Assert(changes, HasLen, 1)
+	c.Assert(changes[0].Action, Equals, service.ChangeUpdate)
+	c.Assert(changes[0].ServiceID, Equals, "web")
+	c.Assert(changes[0].Path, Equals, "app/web")
+	c.Assert(changes[0].Fields, DeepEquals, []service.FieldChange{
+		{Field: "Instances", Old: "1", New: "3"},
+		{Field: "ImageID", Old: "image:1", New: "image:2"},
+	})
+	c.Assert(plan.steps[0].svc.DesiredState, Equals, int(service.SVCStop))
+}
+
+func (t *ApplyServiceTreeTest) TestPlanServiceTree_AddByName(c *C) {
+	tree := t.applyTestTree()
+	tree.Services = append(tree.Services, service.ServiceTree{
+		Service: &service.Service{Name: "cache", ImageID: "cache:1"},
+		Services: []service.ServiceTree{
+			{Service: &service.Service{Name: "warmer"}},
+		},
+	})
+	// a child without an id matches the existing service with its name
+	tree.Services[0].Service.ID = ""
+
+	plan, err := planServiceTree(t.current, tree)
+	c.Assert(err, IsNil)
+	changes := plan.changes()
+	c.Assert(changes, HasLen, 2)
+	c.Assert(changes[0].Action, Equals, service.ChangeAdd)
+	c.Assert(changes[0].Path, Equals, "app/cache")
+	c.Assert(changes[1].Action, Equals, service.ChangeAdd)
+	c.Assert(changes[1].Path, Equals, "app/cache/warmer")
+
+	cache, warmer := plan.steps[0].svc, plan.steps[1].svc
+	c.Assert(cache.ID, Not(Equals), "")
+	c.Assert(cache.ParentServiceID, Equals, "root")
+	c.Assert(cache.PoolID, Equals, "default")
+	c.Assert(cache.DeploymentID, Equals, "deployment")
+	c.Assert(cache.Launch, Equals, commons.AUTO)
+	c.Assert(cache.DesiredState, Equals, int(service.SVCStop))
+	c.Assert(warmer.ParentServiceID, Equals, cache.ID)
+}
+
+func (t *ApplyServiceTreeTest) TestPlanServiceTree_Remove(c *C) {
+	tree := t.applyTestTree()
+	// drop db and its replica
+	tree.Services = tree.Services[1:]
+
+	plan, err := planServiceTree(t.current, tree)
+	c.Assert(err, IsNil)
+	changes := plan.changes()
+	c.Assert(changes, HasLen, 2)
+	c.Assert(changes[0].Action, Equals, service.ChangeRemove)
+	c.Assert(changes[0].Path, Equals, "app/db")
+	c.Assert(plan.steps[0].topmost, Equals, true)
+	c.Assert(changes[1].Action, Equals, service.ChangeRemove)
+	c.Assert(changes[1].Path, Equals, "app/db/replica")
+	c.Assert(plan.steps[1].topmost, Equals, false)
+}
+
+func (t *ApplyServiceTreeTest) TestPlanServiceTree_RemoveRunning(c *C) {
+	t.current[3].DesiredState = int(service.SVCRun)
+	tree := t.applyTestTree()
+	tree.Services[0].Services = nil
+
+	_, err := planServiceTree(t.current, tree)
+	c.Assert(err, ErrorMatches, "app/db/replica: service must be stopped before it is removed")
+}
+
+func (t *ApplyServiceTreeTest) TestPlanServiceTree_DuplicateName(c *C) {
+	tree := t.applyTestTree()
+	tree.Services = append(tree.Services, service.ServiceTree{
+		Service: &service.Service{Name: "web"},
+	})
+
+	_, err := planServiceTree(t.current, tree)
+	c.Assert(err, ErrorMatches, "app: more than one child is named web")
+}
+
+func (t *ApplyServiceTreeTest) TestPlanServiceTree_Invalid(c *C) {
+	tree := t.applyTestTree()
+	tree.Services[1].Service.Launch = "sometimes"
+
+	_, err := planServiceTree(t.current, tree)
+	c.Assert(err, ErrorMatches, "(?s)app/web: .*not in \\[auto manual\\]")
+}
@@ -21,6 +21,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/Sirupsen/logrus"
@@ -33,6 +34,7 @@ import (
 	"github.com/control-center/serviced/dfs"
 	"github.com/control-center/serviced/domain/pool"
 	"github.com/control-center/serviced/domain/service"
+	"github.com/control-center/serviced/events"
 	"github.com/control-center/serviced/metrics"
 	"github.com/control-center/serviced/volume"
 	"github.com/dustin/go-humanize"
@@ -45,6 +47,9 @@ const (
 	oldLocalRegistryContainerNameBase = "cc-temp-registry-v%d"
 	registryRootSubdir                = "docker-registry"
 	upgradedMarkerFile                = "cc-upgraded"
+	// backupParentTag marks the snapshot of the most recent backup of a
+	// tenant, so that the next incremental backup can export against it.
+	backupParentTag = "backup-parent"
 )
 
 type registryVersionInfo struct {
@@ -66,9 +71,14 @@ var registryVersionInfos = map[int]registryVersionInfo{
 	},
 }
 
-// Backup takes a backup of all installed applications
-func (f *Facade) Backup(ctx datastore.Context, w io.Writer, excludes []string, snapshotSpacePercent int, backupFilename string) error {
+// Backup takes a backup of all installed applications.  If parent is set, the
+// backup is incremental to the backup named parentName: tenant data is
+// exported as a delta against the parent's snapshot where it is still
+// available, and images already stored in the parent are skipped.
+func (f *Facade) Backup(ctx datastore.Context, w io.Writer, excludes []string, snapshotSpacePercent int, backupFilename, parentName string, parent *dfs.BackupInfo) (err error) {
 	defer ctx.Metrics().Stop(ctx.Metrics().Start("Facade.Backup"))
+	op := f.startOperation(events.Event{Type: events.BackupProgress, Name: backupFilename})
+	defer func() { op.finish(err) }()
 	// Do not DFSLock here, ControlPlaneDao does that
 	stime := time.Now()
 	message := fmt.Sprintf("started backup at %s", stime.UTC())
@@ -98,8 +108,24 @@ func (f *Facade) Backup(ctx datastore.Context, w io.Writer, excludes []string, s
 		plog.WithError(err).Debug("Could not get tenants")
 		return alog.Error(err)
 	}
+	parentLabels := map[string]string{}
+	if parent != nil {
+		for _, snapshot := range parent.Snapshots {
+			info, err := f.dfs.Info(snapshot)
+			if err != nil {
+				plog.WithField("snapshot", snapshot).Info("Snapshot of parent backup is not available; tenant will be exported in full")
+				continue
+			}
+			parentLabels[info.TenantID] = info.Label
+		}
+	}
+	// keep the snapshots of a successful backup, so they can be used by the
+	// next incremental backup, but only if incremental backups are in use
+	var succeeded bool
+	keep := parentName != "" || f.incrementalBackups
 	snapshots := make([]string, len(tenants))
 	snapshotExcludes := map[string][]string{}
+	parentSnapshots := map[string]string{}
 	for i, tenant := range tenants {
 		tenantLogger := plog.WithField("tenant", tenant)
 		tag := fmt.Sprintf("backup-%s-%s", tenant, stime)
@@ -110,6 +136,13 @@ func (f *Facade) Backup(ctx datastore.Context, w io.Writer, excludes []string, s
 		}
 
 		defer func(tenant, snapshot, tag string) {
+			if succeeded && keep {
+				err := f.keepBackupSnapshot(ctx, tenant, snapshot)
+				if err == nil {
+					return
+				}
+				tenantLogger.WithError(err).Warn("Could not keep snapshot for incremental backups")
+			}
 			if err := f.DeleteSnapshot(ctx, snapshot); err != nil {
 				tenantLogger.WithError(err).Warn("Could not delete snapshot; untagging for consumption by TTL")
 				if _, err := f.RemoveSnapshotTag(ctx, tenant, tag); err != nil {
@@ -120,6 +153,9 @@ func (f *Facade) Backup(ctx datastore.Context, w io.Writer, excludes []string, s
 
 		snapshots[i] = snapshot
 		snapshotExcludes[snapshot] = append(excludes, f.getExcludedVolumes(ctx, tenant)...)
+		if label, ok := parentLabels[tenant]; ok {
+			parentSnapshots[snapshot] = label
+		}
 		tenantLogger.WithField("snapshot", snapshot).Info("Created a snapshot for tenant")
 	}
 	plog.WithField("elapsed", time.Since(stime)).Info("Loaded tenants")
@@ -132,11 +168,17 @@ func (f *Facade) Backup(ctx datastore.Context, w io.Writer, excludes []string, s
 		Timestamp:        stime,
 		BackupVersion:    1,
 	}
+	if parent != nil {
+		data.Parent = parentName
+		data.ParentSnapshots = parentSnapshots
+		data.ParentImages = parent.Images
+	}
 	plog.WithField("data", data).Info("Calling dfs.Backup")
 	if err := f.dfs.Backup(data, w); err != nil {
 		plog.WithError(err).Debug("Could not backup")
 		return alog.Error(err)
 	}
+	succeeded = true
 	duration := time.Since(stime)
 	plog.WithField("duration", duration).Info("Completed backup")
 	alog.WithFields(logrus.Fields{
@@ -146,6 +188,21 @@ func (f *Facade) Backup(ctx datastore.Context, w io.Writer, excludes []string, s
 	return nil
 }
 
+// keepBackupSnapshot tags the snapshot of a backup as the parent for the next
+// incremental backup of the tenant, and deletes the snapshot of the previous
+// backup.
+func (f *Facade) keepBackupSnapshot(ctx datastore.Context, tenantID, snapshotID string) error {
+	if info, err := f.dfs.TagInfo(tenantID, backupParentTag); err == nil && info.Name != snapshotID {
+		if _, err := f.dfs.Untag(tenantID, backupParentTag); err != nil {
+			return err
+		}
+		if err := f.DeleteSnapshot(ctx, info.Name); err != nil {
+			plog.WithError(err).WithField("snapshot", info.Name).Warn("Could not delete snapshot of previous backup")
+		}
+	}
+	return f.dfs.Tag(snapshotID, backupParentTag)
+}
+
 // EstimateBackup estimates storage requirements to take a backup of all installed applications
 func (f *Facade) EstimateBackup(ctx datastore.Context, request dao.BackupRequest, estimate *dao.BackupEstimate) error {
 	defer ctx.Metrics().Stop(ctx.Metrics().Start("Facade.EstimateBackup"))
@@ -575,8 +632,10 @@ func (f *Facade) markLocalDockerRegistryUpgraded(version int) error {
 }
 
 // Restore restores application data from a backup.
-func (f *Facade) Restore(ctx datastore.Context, r io.Reader, backupInfo *dfs.BackupInfo, backupFilename string) error {
+func (f *Facade) Restore(ctx datastore.Context, r io.Reader, backupInfo *dfs.BackupInfo, backupFilename string) (err error) {
 	defer ctx.Metrics().Stop(ctx.Metrics().Start("Facade.Restore"))
+	op := f.startOperation(events.Event{Type: events.RestoreProgress, Name: backupFilename})
+	defer func() { op.finish(err) }()
 	// Do not DFSLock here, ControlPlaneDao does that
 	stime := time.Now()
 	plog.Info("Started restore from backup")
@@ -636,6 +695,18 @@ func (f *Facade) Restore(ctx datastore.Context, r io.Reader, backupInfo *dfs.Bac
 	return nil
 }
 
+// RestoreParent loads the application data of a parent backup, so that an
+// incremental backup that depends on it can be restored afterwards.
+func (f *Facade) RestoreParent(ctx datastore.Context, r io.Reader, backupInfo *dfs.BackupInfo) error {
+	defer ctx.Metrics().Stop(ctx.Metrics().Start("Facade.RestoreParent"))
+	// Do not DFSLock here, ControlPlaneDao does that
+	if err := f.dfs.Restore(r, backupInfo.BackupVersion); err != nil {
+		plog.WithError(err).Debug("Could not restore from parent backup")
+		return err
+	}
+	return nil
+}
+
 // Rollback rolls back an application to state described in the provided
 // snapshot.
 func (f *Facade) Rollback(ctx datastore.Context, snapshotID string, force bool) error {
@@ -709,13 +780,64 @@ func (f *Facade) Rollback(ctx datastore.Context, snapshotID string, force bool)
 	return nil
 }
 
+// DiffSnapshot lists the files that changed between a snapshot and the
+// current application data, or another snapshot if otherID is set.
+func (f *Facade) DiffSnapshot(ctx datastore.Context, snapshotID, otherID string) ([]volume.FileChange, error) {
+	defer ctx.Metrics().Stop(ctx.Metrics().Start("Facade.DiffSnapshot"))
+	changes, err := f.dfs.Diff(snapshotID, otherID)
+	if err != nil {
+		plog.WithFields(logrus.Fields{
+			"snapshotid": snapshotID,
+			"otherid":    otherID,
+		}).WithError(err).Debug("Could not diff snapshot")
+		return nil, err
+	}
+	return changes, nil
+}
+
+// RestoreSnapshotPaths restores selected paths of an application's data from
+// a snapshot.  Unlike a rollback, the services of the application keep
+// running and the rest of its data is left as it is.
+func (f *Facade) RestoreSnapshotPaths(ctx datastore.Context, snapshotID string, paths []string) error {
+	defer ctx.Metrics().Stop(ctx.Metrics().Start("Facade.RestoreSnapshotPaths"))
+	alog := f.auditLogger.Message(ctx, "Restoring Paths from Snapshot").Action(audit.Restore).
+		WithFields(logrus.Fields{
+			"snapshotid": snapshotID,
+			"paths":      strings.Join(paths, ","),
+		})
+	if err := f.DFSLock(ctx).LockWithTimeout("restore snapshot paths", userLockTimeout); err != nil {
+		plog.WithError(err).Debug("Cannot restore snapshot paths")
+		return alog.Error(err)
+	}
+	defer f.DFSLock(ctx).Unlock()
+	if err := f.dfs.RestorePaths(snapshotID, paths); err != nil {
+		plog.WithField("snapshotid", snapshotID).WithError(err).Debug("Could not restore paths from snapshot")
+		return alog.Error(err)
+	}
+	alog.Succeeded()
+	return nil
+}
+
 // Snapshot takes a snapshot for a particular application.
-func (f *Facade) Snapshot(ctx datastore.Context, serviceID, message string, tags []string, snapshotSpacePercent int) (string, error) {
+func (f *Facade) Snapshot(ctx datastore.Context, serviceID, message string, tags []string, snapshotSpacePercent int) (snapshotID string, err error) {
 	defer ctx.Metrics().Stop(ctx.Metrics().Start("Facade.Snapshot"))
 	// Do not DFSLock here, ControlPlaneDao does that
+	return f.snapshot(ctx, serviceID, message, tags, snapshotSpacePercent, true)
+}
+
+// SnapshotLive takes a snapshot for a particular application without pausing
+// its services, so the data of running services may not be consistent.
+func (f *Facade) SnapshotLive(ctx datastore.Context, serviceID, message string, tags []string, snapshotSpacePercent int) (snapshotID string, err error) {
+	defer ctx.Metrics().Stop(ctx.Metrics().Start("Facade.SnapshotLive"))
+	// Do not DFSLock here, ControlPlaneDao does that
+	return f.snapshot(ctx, serviceID, message, tags, snapshotSpacePercent, false)
+}
+
+func (f *Facade) snapshot(ctx datastore.Context, serviceID, message string, tags []string, snapshotSpacePercent int, quiesce bool) (snapshotID string, err error) {
 
 	logger := plog.WithFields(logrus.Fields{
 		"serviceid": serviceID,
+		"quiesce":   quiesce,
 	})
 
 	tenantID, err := f.GetTenantID(ctx, serviceID)
@@ -724,6 +846,11 @@ func (f *Facade) Snapshot(ctx datastore.Context, serviceID, message string, tags
 		return "", err
 	}
 	logger = logger.WithField("tenantid", tenantID)
+	op := f.startOperation(events.Event{Type: events.SnapshotProgress, TenantID: tenantID, ServiceID: serviceID})
+	defer func() {
+		op.event.Name = snapshotID
+		op.finish(err)
+	}()
 	if err := f.lockTenant(ctx, tenantID); err != nil {
 		logger.WithError(err).Debug("Could not lock tenant")
 		return "", err
@@ -758,7 +885,7 @@ func (f *Facade) Snapshot(ctx datastore.Context, serviceID, message string, tags
 		}
 
 		if hasDFS {
-			if svc.DesiredState == int(service.SVCRun) {
+			if quiesce && svc.DesiredState == int(service.SVCRun) {
 				servicesToPause = append(servicesToPause, svc)
 				pausedServiceIds = append(pausedServiceIds, svc.ID)
 			}
@@ -772,24 +899,26 @@ func (f *Facade) Snapshot(ctx datastore.Context, serviceID, message string, tags
 			}
 		}
 	}
-	// Pause the services that need pausing in a batch
-	if _, err := scheduleServices(f, servicesToPause, ctx, tenantID, service.SVCPause, false); err != nil {
-		logger.WithError(err).Debug("Could not pause services for snapshot")
-		return "", err
-	}
+	if quiesce {
+		// Pause the services that need pausing in a batch
+		if _, err := scheduleServices(f, servicesToPause, ctx, tenantID, service.SVCPause, false); err != nil {
+			logger.WithError(err).Debug("Could not pause services for snapshot")
+			return "", err
+		}
 
-	defer func() {
-		// Refresh service objects in case something has changed (like current state)
-		servicesToPause = f.GetServicesForScheduling(ctx, pausedServiceIds)
-		scheduleServices(f, servicesToPause, ctx, tenantID, service.SVCRun, false)
-	}()
+		defer func() {
+			// Refresh service objects in case something has changed (like current state)
+			servicesToPause = f.GetServicesForScheduling(ctx, pausedServiceIds)
+			scheduleServices(f, servicesToPause, ctx, tenantID, service.SVCRun, false)
+		}()
 
-	// Wait for the paused services to reach the paused state (and other services to reach stopped)
-	if err := f.WaitService(ctx, service.SVCPause, f.dfs.Timeout(), false, serviceids...); err != nil {
-		logger.WithError(err).Debug("Could not wait for services to pause during snapshot")
-		return "", err
+		// Wait for the paused services to reach the paused state (and other services to reach stopped)
+		if err := f.WaitService(ctx, service.SVCPause, f.dfs.Timeout(), false, serviceids...); err != nil {
+			logger.WithError(err).Debug("Could not wait for services to pause during snapshot")
+			return "", err
+		}
+		logger.Infof("Services are now paused for snapshot")
 	}
-	logger.Infof("Services are now paused for snapshot")
 	data := dfs.SnapshotInfo{
 		SnapshotInfo: &volume.SnapshotInfo{
 			TenantID: tenantID,
@@ -799,7 +928,7 @@ func (f *Facade) Snapshot(ctx datastore.Context, serviceID, message string, tags
 		Services: svcs,
 		Images:   images,
 	}
-	snapshotID, err := f.dfs.Snapshot(data, snapshotSpacePercent)
+	snapshotID, err = f.dfs.Snapshot(data, snapshotSpacePercent)
 	if err != nil {
 		logger.WithError(err).Debug("Could not snapshot disk and images for tenant")
 		return "", err
@@ -0,0 +1,45 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build unit
+
+package facade_test
+
+import (
+	"github.com/control-center/serviced/volume"
+	. "gopkg.in/check.v1"
+)
+
+func (ft *FacadeUnitTest) Test_DiffSnapshot(c *C) {
+	expected := []volume.FileChange{{Path: "etc/app.conf", Change: volume.FileDeleted}}
+	ft.dfs.On("Diff", "tenant_snap", "tenant_snap2").Return(expected, nil)
+	changes, err := ft.Facade.DiffSnapshot(ft.ctx, "tenant_snap", "tenant_snap2")
+	c.Assert(err, IsNil)
+	c.Assert(changes, DeepEquals, expected)
+
+	ft.dfs.On("Diff", "tenant_missing", "").Return(nil, volume.ErrSnapshotDoesNotExist)
+	changes, err = ft.Facade.DiffSnapshot(ft.ctx, "tenant_missing", "")
+	c.Assert(err, Equals, volume.ErrSnapshotDoesNotExist)
+	c.Assert(changes, IsNil)
+}
+
+func (ft *FacadeUnitTest) Test_RestoreSnapshotPaths(c *C) {
+	ft.setupMockDFSLocking()
+	ft.dfs.On("RestorePaths", "tenant_snap", []string{"etc/app.conf"}).Return(nil).Once()
+	err := ft.Facade.RestoreSnapshotPaths(ft.ctx, "tenant_snap", []string{"etc/app.conf"})
+	c.Assert(err, IsNil)
+
+	ft.dfs.On("RestorePaths", "tenant_snap", []string{"missing"}).Return(volume.ErrPathNotInSnapshot).Once()
+	err = ft.Facade.RestoreSnapshotPaths(ft.ctx, "tenant_snap", []string{"missing"})
+	c.Assert(err, Equals, volume.ErrPathNotInSnapshot)
+}
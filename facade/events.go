@@ -0,0 +1,196 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package facade
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/control-center/serviced/datastore"
+	"github.com/control-center/serviced/domain/host"
+	"github.com/control-center/serviced/domain/service"
+	"github.com/control-center/serviced/events"
+	"github.com/control-center/serviced/health"
+	"github.com/control-center/serviced/threshold"
+)
+
+// MaxEventWait is the longest that GetEvents waits for an event
+const MaxEventWait = time.Minute
+
+// GetEvents returns the events after the given id that match the filter.  If
+// there are none, it waits up to timeout for the next one.
+func (f *Facade) GetEvents(ctx datastore.Context, filter events.Filter, since uint64, timeout time.Duration) ([]events.Event, error) {
+	defer ctx.Metrics().Stop(ctx.Metrics().Start("Facade.GetEvents"))
+	if timeout <= 0 {
+		return f.eventBus.Events(filter, since), nil
+	} else if timeout > MaxEventWait {
+		timeout = MaxEventWait
+	}
+	return f.eventBus.Wait(filter, since, timeout), nil
+}
+
+// SubscribeEvents returns a subscription to the events that match the
+// filter, starting after the event with the given id.
+func (f *Facade) SubscribeEvents(filter events.Filter, since uint64) *events.Subscription {
+	return f.eventBus.Subscribe(filter, since)
+}
+
+// InstanceStateChanged publishes a change to the state of a service instance.
+// An instance that was removed has an empty state.
+func (f *Facade) InstanceStateChanged(poolID, serviceID, hostID string, instanceID int, previous, current service.InstanceCurrentState) {
+	f.publishServiceEvent(datastore.Get(), events.Event{
+		Type:       events.InstanceState,
+		PoolID:     poolID,
+		ServiceID:  serviceID,
+		InstanceID: instanceID,
+		HostID:     hostID,
+		State:      string(current),
+		Previous:   string(previous),
+	})
+}
+
+// publishServiceEvent fills in the pool, tenant and, unless the event names a
+// health check, the name of the service that the event is about, and
+// publishes it.
+func (f *Facade) publishServiceEvent(ctx datastore.Context, event events.Event) {
+	if f.eventBus == nil {
+		return
+	}
+	logger := plog.WithFields(log.Fields{
+		"serviceid": event.ServiceID,
+		"eventtype": event.Type,
+	})
+	if event.PoolID == "" || event.Name == "" {
+		if svc, err := f.GetServiceDetails(ctx, event.ServiceID); err != nil {
+			logger.WithError(err).Debug("Could not look up service for event")
+		} else {
+			event.PoolID = svc.PoolID
+			if event.Name == "" {
+				event.Name = svc.Name
+			}
+		}
+	}
+	if tenantID, err := f.GetTenantID(ctx, event.ServiceID); err != nil {
+		logger.WithError(err).Debug("Could not look up tenant for event")
+	} else {
+		event.TenantID = tenantID
+	}
+	f.eventBus.Publish(event)
+}
+
+// ThresholdEvents returns an event store that publishes the events of the
+// threshold monitor, so that they are streamed by the events endpoint
+func (f *Facade) ThresholdEvents() threshold.EventStore {
+	return thresholdEvents{f: f}
+}
+
+type thresholdEvents struct {
+	f *Facade
+}
+
+// Add implements threshold.EventStore
+func (t thresholdEvents) Add(event threshold.Event) {
+	t.f.publishServiceEvent(datastore.Get(), thresholdEvent(event))
+}
+
+// thresholdEvent converts a threshold event to a service event.  The event is
+// named after the threshold, and says which data point crossed it.
+func thresholdEvent(event threshold.Event) events.Event {
+	e := events.Event{
+		Type:      events.ServiceThreshold,
+		Timestamp: event.Timestamp.UTC(),
+		ServiceID: event.ServiceID,
+		Name:      event.Threshold,
+		State:     events.StateViolated,
+		Previous:  events.StateCleared,
+		Message:   fmt.Sprintf("%s is %g", event.DataPoint, event.Value),
+	}
+	if event.Cleared {
+		e.State, e.Previous = events.StateCleared, events.StateViolated
+	}
+	if event.Message != "" {
+		e.Message += ": " + event.Message
+	}
+	return e
+}
+
+// publishHealthEvent publishes the status of a health check, if it changed
+func (f *Facade) publishHealthEvent(key health.HealthStatusKey, previous *health.HealthStatus, current health.HealthStatus) {
+	if previous != nil && previous.Status == current.Status {
+		return
+	}
+	event := events.Event{
+		Type:       events.InstanceHealth,
+		ServiceID:  key.ServiceID,
+		InstanceID: key.InstanceID,
+		Name:       key.HealthCheckName,
+		State:      healthStatusName(current.Status),
+	}
+	if previous != nil {
+		event.Previous = healthStatusName(previous.Status)
+	}
+	f.publishServiceEvent(datastore.Get(), event)
+}
+
+// publishHostEvent publishes that a host was added or removed
+func (f *Facade) publishHostEvent(eventType events.Type, h *host.Host) {
+	f.publishEvent(events.Event{
+		Type:   eventType,
+		PoolID: h.PoolID,
+		HostID: h.ID,
+		Name:   h.Name,
+	})
+}
+
+// publishEvent publishes an event that is not about a service
+func (f *Facade) publishEvent(event events.Event) {
+	if f.eventBus != nil {
+		f.eventBus.Publish(event)
+	}
+}
+
+// operation is a backup, restore or snapshot that publishes its progress
+type operation struct {
+	f     *Facade
+	event events.Event
+}
+
+// startOperation publishes that a backup, restore or snapshot has started
+func (f *Facade) startOperation(event events.Event) *operation {
+	event.State = events.StateStarted
+	f.publishEvent(event)
+	return &operation{f: f, event: event}
+}
+
+// finish publishes the result of the operation
+func (op *operation) finish(err error) {
+	op.event.Previous = op.event.State
+	if err != nil {
+		op.event.State = events.StateFailed
+		op.event.Message = err.Error()
+	} else {
+		op.event.State = events.StateCompleted
+	}
+	op.f.publishEvent(op.event)
+}
+
+func healthStatusName(status health.Status) string {
+	name, err := status.MarshalJSON()
+	if err != nil {
+		return "invalid"
+	}
+	return strings.Trim(string(name), `"`)
+}
@@ -0,0 +1,107 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build unit
+
+package facade
+
+import (
+	"errors"
+	"time"
+
+	"github.com/control-center/serviced/domain/host"
+	"github.com/control-center/serviced/events"
+	"github.com/control-center/serviced/health"
+	"github.com/control-center/serviced/threshold"
+	. "gopkg.in/check.v1"
+)
+
+var _ = Suite(&EventsTest{})
+
+type EventsTest struct {
+	f *Facade
+}
+
+func (t *EventsTest) SetUpTest(c *C) {
+	t.f = &Facade{eventBus: events.NewBus(0)}
+}
+
+func (t *EventsTest) TestOperation(c *C) {
+	op := t.f.startOperation(events.Event{Type: events.BackupProgress, Name: "backup.tgz"})
+	op.finish(nil)
+	op = t.f.startOperation(events.Event{Type: events.RestoreProgress, Name: "backup.tgz"})
+	op.finish(errors.New("no space left"))
+
+	evts := t.f.eventBus.Events(events.Filter{}, 0)
+	c.Assert(evts, HasLen, 4)
+	c.Assert(evts[0].State, Equals, events.StateStarted)
+	c.Assert(evts[1].Type, Equals, events.BackupProgress)
+	c.Assert(evts[1].State, Equals, events.StateCompleted)
+	c.Assert(evts[1].Previous, Equals, events.StateStarted)
+	c.Assert(evts[3].Type, Equals, events.RestoreProgress)
+	c.Assert(evts[3].State, Equals, events.StateFailed)
+	c.Assert(evts[3].Message, Equals, "no space left")
+}
+
+func (t *EventsTest) TestThresholdEvent(c *C) {
+	now := time.Now()
+	event := thresholdEvent(threshold.Event{
+		Timestamp:   now,
+		ServiceID:   "svc1",
+		ThresholdID: "cpu.high",
+		Threshold:   "CPU high",
+		DataPoint:   "cpu.user",
+		Value:       95,
+		Message:     "above 90",
+	})
+	c.Assert(event.Type, Equals, events.ServiceThreshold)
+	c.Assert(event.Timestamp.Equal(now), Equals, true)
+	c.Assert(event.ServiceID, Equals, "svc1")
+	c.Assert(event.Name, Equals, "CPU high")
+	c.Assert(event.State, Equals, events.StateViolated)
+	c.Assert(event.Message, Equals, "cpu.user is 95: above 90")
+
+	event = thresholdEvent(threshold.Event{ServiceID: "svc1", Threshold: "CPU high", DataPoint: "cpu.user", Value: 50, Cleared: true})
+	c.Assert(event.State, Equals, events.StateCleared)
+	c.Assert(event.Previous, Equals, events.StateViolated)
+	c.Assert(event.Message, Equals, "cpu.user is 50")
+	c.Assert(events.Filter{Types: []events.Type{"service"}}.Match(event), Equals, true)
+}
+
+func (t *EventsTest) TestHostEvent(c *C) {
+	t.f.publishHostEvent(events.HostRegistered, &host.Host{ID: "host1", PoolID: "pool1", Name: "agent"})
+	evts := t.f.eventBus.Events(events.Filter{HostID: "host1"}, 0)
+	c.Assert(evts, HasLen, 1)
+	c.Assert(evts[0].PoolID, Equals, "pool1")
+	c.Assert(evts[0].Name, Equals, "agent")
+}
+
+func (t *EventsTest) TestHealthEvent_Unchanged(c *C) {
+	key := health.HealthStatusKey{ServiceID: "svc1", InstanceID: 0, HealthCheckName: "running"}
+	previous := health.HealthStatus{Status: health.OK}
+	t.f.publishHealthEvent(key, &previous, health.HealthStatus{Status: health.OK})
+	c.Assert(t.f.eventBus.Events(events.Filter{}, 0), HasLen, 0)
+}
+
+func (t *EventsTest) TestHealthStatusName(c *C) {
+	c.Assert(healthStatusName(health.OK), Equals, "passed")
+	c.Assert(healthStatusName(health.NotRunning), Equals, "not_running")
+	c.Assert(healthStatusName(health.Status(42)), Equals, "invalid")
+}
+
+func (t *EventsTest) TestNoBus(c *C) {
+	// a facade without a bus does not publish
+	f := &Facade{}
+	f.publishHostEvent(events.HostUnregistered, &host.Host{ID: "host1"})
+	f.startOperation(events.Event{Type: events.SnapshotProgress}).finish(nil)
+}
@@ -17,16 +17,20 @@ import (
 	"time"
 
 	"github.com/control-center/serviced/audit"
+	"github.com/control-center/serviced/datastore/migration"
 	"github.com/control-center/serviced/auth"
 	"github.com/control-center/serviced/dfs"
 	"github.com/control-center/serviced/domain/host"
 	"github.com/control-center/serviced/domain/hostkey"
 	"github.com/control-center/serviced/domain/pool"
+	"github.com/control-center/serviced/domain/secret"
+	"github.com/control-center/serviced/domain/snapshotpolicy"
 	"github.com/control-center/serviced/domain/registry"
 	"github.com/control-center/serviced/domain/service"
 	"github.com/control-center/serviced/domain/serviceconfigfile"
 	"github.com/control-center/serviced/domain/servicetemplate"
 	"github.com/control-center/serviced/domain/user"
+	"github.com/control-center/serviced/events"
 	"github.com/control-center/serviced/health"
 	"github.com/control-center/serviced/logging"
 	"github.com/control-center/serviced/metrics"
@@ -58,11 +62,16 @@ func New() *Facade {
 		templateStore:  servicetemplate.NewStore(),
 		logFilterStore: logfilter.NewStore(),
 		userStore:      user.NewStore(),
+		secretStore:    secret.NewStore(),
+		policyStore:    snapshotpolicy.NewStore(),
 		serviceCache:   NewServiceCache(),
 		poolCache:      NewPoolCache(),
 		hostRegistry:   auth.NewHostExpirationRegistry(),
 		deployments:    NewPendingDeploymentMgr(),
 		zzk:            getZZK(),
+		migrations:     newMigrationRegistry(),
+		rollouts:       newRolloutTracker(),
+		eventBus:       events.NewBus(events.DefaultHistory),
 	}
 }
 
@@ -77,6 +86,8 @@ type Facade struct {
 	serviceStore   service.Store
 	configStore    serviceconfigfile.Store
 	userStore      user.Store
+	secretStore    secret.Store
+	policyStore    snapshotpolicy.Store
 
 	auditLogger   audit.Logger
 	zzk           ZZK
@@ -89,8 +100,13 @@ type Facade struct {
 	deployments   *PendingDeploymentMgr
 	ssm           servicestatemanager.ServiceStateManager
 	isvcsPath     string
+	migrations    *migration.Registry
+	rollouts      *rolloutTracker
+	eventBus      *events.Bus
+	secretCipher  *secret.Cipher
 
 	rollingRestartTimeout time.Duration
+	incrementalBackups    bool
 }
 
 func (f *Facade) SetAuditLogger(logger audit.Logger) { f.auditLogger = logger }
@@ -124,6 +140,10 @@ func (f *Facade) SetConfigStore(store serviceconfigfile.Store) { f.configStore =
 
 func (f *Facade) SetUserStore(store user.Store) { f.userStore = store }
 
+func (f *Facade) SetSecretStore(store secret.Store) { f.secretStore = store }
+
+func (f *Facade) SetSnapshotPolicyStore(store snapshotpolicy.Store) { f.policyStore = store }
+
 func (f *Facade) SetTemplateStore(store servicetemplate.Store) { f.templateStore = store }
 
 func (f *Facade) SetLogFilterStore(store logfilter.Store) { f.logFilterStore = store }
@@ -141,3 +161,5 @@ func (f *Facade) SetHostExpirationRegistry(hostRegistry auth.HostExpirationRegis
 func (f *Facade) SetDeploymentMgr(mgr *PendingDeploymentMgr) { f.deployments = mgr }
 
 func (f *Facade) SetRollingRestartTimeout(t time.Duration) { f.rollingRestartTimeout = t }
+
+func (f *Facade) SetIncrementalBackups(enabled bool) { f.incrementalBackups = enabled }
@@ -27,6 +27,8 @@ import (
 	keymocks "github.com/control-center/serviced/domain/hostkey/mocks"
 	poolmocks "github.com/control-center/serviced/domain/pool/mocks"
 	registrymocks "github.com/control-center/serviced/domain/registry/mocks"
+	secretmocks "github.com/control-center/serviced/domain/secret/mocks"
+	policymocks "github.com/control-center/serviced/domain/snapshotpolicy/mocks"
 	servicemocks "github.com/control-center/serviced/domain/service/mocks"
 	configmocks "github.com/control-center/serviced/domain/serviceconfigfile/mocks"
 	templatemocks "github.com/control-center/serviced/domain/servicetemplate/mocks"
@@ -53,6 +55,8 @@ type FacadeUnitTest struct {
 	configStore      *configmocks.Store
 	templateStore    *templatemocks.Store
 	logFilterStore   *logfiltermocks.Store
+	secretStore      *secretmocks.Store
+	policyStore      *policymocks.Store
 	metricsClient    *zzkmocks.MetricsClient
 	hostauthregistry *authmocks.HostExpirationRegistryInterface
 }
@@ -112,6 +116,12 @@ func (ft *FacadeUnitTest) SetUpTest(c *C) {
 	ft.logFilterStore = &logfiltermocks.Store{}
 	ft.Facade.SetLogFilterStore(ft.logFilterStore)
 
+	ft.secretStore = &secretmocks.Store{}
+	ft.Facade.SetSecretStore(ft.secretStore)
+
+	ft.policyStore = &policymocks.Store{}
+	ft.Facade.SetSnapshotPolicyStore(ft.policyStore)
+
 	ft.zzk = &zzkmocks.ZZK{}
 	ft.Facade.SetZZK(ft.zzk)
 
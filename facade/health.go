@@ -25,7 +25,12 @@ import (
 
 // ReportHealthStatus writes the status of a health check to the cache.
 func (f *Facade) ReportHealthStatus(key health.HealthStatusKey, value health.HealthStatus, expires time.Duration) {
+	var previous *health.HealthStatus
+	if status, ok := f.hcache.Get(key); ok {
+		previous = &status
+	}
 	f.hcache.Set(key, value, expires)
+	f.publishHealthEvent(key, previous, value)
 }
 
 // ReportInstanceDead removes all health checks of a particular instance from
@@ -25,6 +25,7 @@ import (
 	"github.com/control-center/serviced/domain/host"
 	"github.com/control-center/serviced/domain/hostkey"
 	"github.com/control-center/serviced/domain/service"
+	"github.com/control-center/serviced/events"
 	"github.com/control-center/serviced/utils"
 	"github.com/zenoss/glog"
 )
@@ -126,6 +127,9 @@ func (f *Facade) addHost(ctx datastore.Context, entity *host.Host) ([]byte, erro
 	err = f.zzk.AddHost(entity)
 
 	f.poolCache.SetDirty()
+	if err == nil {
+		f.publishHostEvent(events.HostRegistered, entity)
+	}
 
 	return delegatePEMBlock, err
 }
@@ -171,6 +175,9 @@ func (f *Facade) addHostPrivate(ctx datastore.Context, entity *host.Host) ([]byt
 	err = f.zzk.AddHost(entity)
 
 	f.poolCache.SetDirty()
+	if err == nil {
+		f.publishHostEvent(events.HostRegistered, entity)
+	}
 
 	return commonPEMBlock, err
 }
@@ -369,6 +376,7 @@ func (f *Facade) RemoveHost(ctx datastore.Context, hostID string) (err error) {
 	}
 
 	f.poolCache.SetDirty()
+	f.publishHostEvent(events.HostUnregistered, _host)
 
 	alog.Succeeded()
 	return nil
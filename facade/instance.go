@@ -439,6 +439,8 @@ func (f *Facade) GetHostStrategyInstances(ctx datastore.Context, hosts []host.Ho
 				}
 				inst = service.StrategyInstance{
 					ServiceID:     s.ID,
+					Name:          s.Name,
+					DeploymentID:  s.DeploymentID,
 					CPUCommitment: int(s.CPUCommitment),
 					RAMCommitment: s.RAMCommitment.Value,
 					HostPolicy:    s.HostPolicy,
@@ -542,7 +544,7 @@ func (f *Facade) SendDockerAction(ctx datastore.Context, serviceID string, insta
 		return *s, nil
 	}
 
-	if err := svc.EvaluateActionsTemplate(get, getchild, instanceID); err != nil {
+	if err := svc.EvaluateActionsTemplate(get, getchild, f.secretGetter(ctx), instanceID); err != nil {
 		logger.WithError(err).Debug("Could not evaluate service actions template")
 		return err
 	}
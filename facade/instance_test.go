@@ -420,6 +420,7 @@ func (ft *FacadeUnitTest) TestGetHostStrategyInstances(c *C) {
 		ID:            "testservice",
 		PoolID:        "default",
 		Name:          "serviceA",
+		DeploymentID:  "testdeployment",
 		CPUCommitment: 10,
 		RAMCommitment: utils.EngNotation{
 			Value: uint64(1000),
@@ -432,6 +433,8 @@ func (ft *FacadeUnitTest) TestGetHostStrategyInstances(c *C) {
 		hst1.ID: {
 			HostID:        hst1.ID,
 			ServiceID:     svc.ID,
+			Name:          svc.Name,
+			DeploymentID:  svc.DeploymentID,
 			CPUCommitment: int(svc.CPUCommitment),
 			RAMCommitment: svc.RAMCommitment.Value,
 			HostPolicy:    svc.HostPolicy,
@@ -439,6 +442,8 @@ func (ft *FacadeUnitTest) TestGetHostStrategyInstances(c *C) {
 		hst2.ID: {
 			HostID:        hst2.ID,
 			ServiceID:     svc.ID,
+			Name:          svc.Name,
+			DeploymentID:  svc.DeploymentID,
 			CPUCommitment: int(svc.CPUCommitment),
 			RAMCommitment: svc.RAMCommitment.Value,
 			HostPolicy:    svc.HostPolicy,
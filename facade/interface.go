@@ -18,17 +18,22 @@ import (
 
 	"github.com/control-center/serviced/dao"
 	"github.com/control-center/serviced/datastore"
+	"github.com/control-center/serviced/datastore/migration"
 	"github.com/control-center/serviced/domain"
+	"github.com/control-center/serviced/events"
 	"github.com/control-center/serviced/health"
 
 	"github.com/control-center/serviced/domain/addressassignment"
 	"github.com/control-center/serviced/domain/host"
 	"github.com/control-center/serviced/domain/pool"
+	"github.com/control-center/serviced/domain/secret"
+	"github.com/control-center/serviced/domain/snapshotpolicy"
 	"github.com/control-center/serviced/domain/service"
 	"github.com/control-center/serviced/domain/servicedefinition"
 	"github.com/control-center/serviced/domain/servicetemplate"
 	"github.com/control-center/serviced/domain/user"
 	"github.com/control-center/serviced/utils"
+	"github.com/control-center/serviced/volume"
 )
 
 // The FacadeInterface is the API for a Facade
@@ -78,6 +83,8 @@ type FacadeInterface interface {
 
 	DeployTemplateStatus(deploymentID string, lastStatus string, timeout time.Duration) (status string, err error)
 
+	UpgradeTemplate(ctx datastore.Context, request servicetemplate.UpgradeRequest) (*servicetemplate.Upgrade, error)
+
 	AddHost(ctx datastore.Context, entity *host.Host) ([]byte, error)
 
 	AddHostPrivate(ctx datastore.Context, entity *host.Host) ([]byte, error)
@@ -216,7 +223,41 @@ type FacadeInterface interface {
 
 	RestartService(ctx datastore.Context, request dao.ScheduleServiceRequest) (int, error)
 
+	GetRolloutStatus(ctx datastore.Context, serviceID string) (*service.RolloutStatus, error)
+
+	GetServiceTree(ctx datastore.Context, serviceID string) (*service.ServiceTree, error)
+
+	ApplyServiceTree(ctx datastore.Context, tree service.ServiceTree, dryRun bool) ([]service.ServiceChange, error)
+
 	StopService(ctx datastore.Context, request dao.ScheduleServiceRequest) (int, error)
 
 	PauseService(ctx datastore.Context, request dao.ScheduleServiceRequest) (int, error)
+
+	GetMigrationStatus(ctx datastore.Context) ([]migration.Status, error)
+
+	MigrateEntities(ctx datastore.Context, dryRun bool) ([]migration.Status, error)
+
+	GetEvents(ctx datastore.Context, filter events.Filter, since uint64, timeout time.Duration) ([]events.Event, error)
+
+	SubscribeEvents(filter events.Filter, since uint64) *events.Subscription
+
+	AddSecret(ctx datastore.Context, name, description string, value []byte) error
+
+	RotateSecret(ctx datastore.Context, name string, value []byte) error
+
+	RemoveSecret(ctx datastore.Context, name string) error
+
+	GetSecrets(ctx datastore.Context) ([]secret.Secret, error)
+
+	GetServiceSecrets(ctx datastore.Context, hostID, serviceID string, instanceID int) (map[string]string, error)
+
+	SetSnapshotPolicy(ctx datastore.Context, policy snapshotpolicy.SnapshotPolicy) error
+
+	GetSnapshotPolicies(ctx datastore.Context) ([]snapshotpolicy.SnapshotPolicy, error)
+
+	RemoveSnapshotPolicy(ctx datastore.Context, tenantID string) error
+
+	DiffSnapshot(ctx datastore.Context, snapshotID, otherID string) ([]volume.FileChange, error)
+
+	RestoreSnapshotPaths(ctx datastore.Context, snapshotID string, paths []string) error
 }
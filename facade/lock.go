@@ -15,6 +15,7 @@ package facade
 
 import (
 	"errors"
+	"sort"
 	"sync"
 	"time"
 
@@ -91,6 +92,34 @@ func (f *Facade) unlockTenant(ctx datastore.Context, tenantID string) (err error
 	return
 }
 
+// lockAllTenants sets the write lock of every tenant, so that no service can
+// be written until the returned function unlocks them.  The tenants are locked
+// in order so that two callers cannot deadlock.
+func (f *Facade) lockAllTenants(ctx datastore.Context) (func(), error) {
+	defer ctx.Metrics().Stop(ctx.Metrics().Start("Facade.lockAllTenants"))
+	tenantIDs, err := f.GetTenantIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(tenantIDs)
+	var mutexes []*sync.RWMutex
+	for _, tenantID := range tenantIDs {
+		mutex := getTenantLock(tenantID)
+		mutex.Lock()
+		mutexes = append(mutexes, mutex)
+
+		// Wait for current processing by the service state manager to complete
+		if f.ssm != nil {
+			f.ssm.Wait(tenantID)
+		}
+	}
+	return func() {
+		for i := len(mutexes) - 1; i >= 0; i-- {
+			mutexes[i].Unlock()
+		}
+	}, nil
+}
+
 // retryUnlockTenant is a persistent unlock for a given tenant
 func (f *Facade) retryUnlockTenant(ctx datastore.Context, tenantID string, cancel <-chan time.Time, interval time.Duration) error {
 	for {
@@ -0,0 +1,60 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package facade
+
+import (
+	"github.com/control-center/serviced/datastore"
+	"github.com/control-center/serviced/datastore/migration"
+	"github.com/control-center/serviced/domain/host"
+	"github.com/control-center/serviced/domain/service"
+)
+
+// newMigrationRegistry returns the schema migrations of the entities in the
+// datastore.  New migrations go at the end of their kind's list.
+func newMigrationRegistry() *migration.Registry {
+	registry := migration.NewRegistry()
+	for _, migrations := range [][]migration.Migration{
+		host.Migrations,
+		service.Migrations,
+	} {
+		if err := registry.Register(migrations...); err != nil {
+			panic(err)
+		}
+	}
+	return registry
+}
+
+// SetMigrations sets the schema migrations of the datastore entities
+func (f *Facade) SetMigrations(registry *migration.Registry) { f.migrations = registry }
+
+// GetMigrationStatus returns the state of every schema migration, in order
+func (f *Facade) GetMigrationStatus(ctx datastore.Context) ([]migration.Status, error) {
+	defer ctx.Metrics().Stop(ctx.Metrics().Start("Facade.GetMigrationStatus"))
+	return f.migrations.Status(ctx)
+}
+
+// MigrateEntities applies the schema migrations that have not been applied
+// and returns their states.  A dry run counts the entities that would change
+// without changing them.  Every tenant is locked while the migrations run, so
+// that they do not interleave with service writes.
+func (f *Facade) MigrateEntities(ctx datastore.Context, dryRun bool) ([]migration.Status, error) {
+	defer ctx.Metrics().Stop(ctx.Metrics().Start("Facade.MigrateEntities"))
+	unlock, err := f.lockAllTenants(ctx)
+	if err != nil {
+		plog.WithError(err).Debug("Cannot migrate entities")
+		return nil, err
+	}
+	defer unlock()
+	return f.migrations.Up(ctx, dryRun)
+}
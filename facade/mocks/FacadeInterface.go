@@ -4,17 +4,22 @@ import addressassignment "github.com/control-center/serviced/domain/addressassig
 import dao "github.com/control-center/serviced/dao"
 import datastore "github.com/control-center/serviced/datastore"
 import domain "github.com/control-center/serviced/domain"
+import events "github.com/control-center/serviced/events"
 
 import health "github.com/control-center/serviced/health"
+import migration "github.com/control-center/serviced/datastore/migration"
 import host "github.com/control-center/serviced/domain/host"
 import mock "github.com/stretchr/testify/mock"
 import pool "github.com/control-center/serviced/domain/pool"
+import secret "github.com/control-center/serviced/domain/secret"
+import snapshotpolicy "github.com/control-center/serviced/domain/snapshotpolicy"
 import service "github.com/control-center/serviced/domain/service"
 import servicedefinition "github.com/control-center/serviced/domain/servicedefinition"
 import servicetemplate "github.com/control-center/serviced/domain/servicetemplate"
 import time "time"
 import user "github.com/control-center/serviced/domain/user"
 import "github.com/control-center/serviced/utils"
+import volume "github.com/control-center/serviced/volume"
 
 // FacadeInterface is an autogenerated mock type for the FacadeInterface type
 type FacadeInterface struct {
@@ -313,6 +318,29 @@ func (_m *FacadeInterface) DeployTemplate(ctx datastore.Context, poolID string,
 	return r0, r1
 }
 
+// UpgradeTemplate provides a mock function with given fields: ctx, request
+func (_m *FacadeInterface) UpgradeTemplate(ctx datastore.Context, request servicetemplate.UpgradeRequest) (*servicetemplate.Upgrade, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *servicetemplate.Upgrade
+	if rf, ok := ret.Get(0).(func(datastore.Context, servicetemplate.UpgradeRequest) *servicetemplate.Upgrade); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*servicetemplate.Upgrade)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(datastore.Context, servicetemplate.UpgradeRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeployTemplateActive provides a mock function with given fields:
 func (_m *FacadeInterface) DeployTemplateActive() ([]map[string]string, error) {
 	ret := _m.Called()
@@ -774,6 +802,29 @@ func (_m *FacadeInterface) GetResourcePool(ctx datastore.Context, poolID string)
 	return r0, r1
 }
 
+// GetMigrationStatus provides a mock function with given fields: ctx
+func (_m *FacadeInterface) GetMigrationStatus(ctx datastore.Context) ([]migration.Status, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []migration.Status
+	if rf, ok := ret.Get(0).(func(datastore.Context) []migration.Status); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]migration.Status)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(datastore.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetResourcePools provides a mock function with given fields: ctx
 func (_m *FacadeInterface) GetResourcePools(ctx datastore.Context) ([]pool.ResourcePool, error) {
 	ret := _m.Called(ctx)
@@ -1586,6 +1637,68 @@ func (_m *FacadeInterface) PauseService(ctx datastore.Context, request dao.Sched
 	return r0, r1
 }
 
+// MigrateEntities provides a mock function with given fields: ctx, dryRun
+func (_m *FacadeInterface) MigrateEntities(ctx datastore.Context, dryRun bool) ([]migration.Status, error) {
+	ret := _m.Called(ctx, dryRun)
+
+	var r0 []migration.Status
+	if rf, ok := ret.Get(0).(func(datastore.Context, bool) []migration.Status); ok {
+		r0 = rf(ctx, dryRun)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]migration.Status)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(datastore.Context, bool) error); ok {
+		r1 = rf(ctx, dryRun)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetEvents provides a mock function with given fields: ctx, filter, since, timeout
+func (_m *FacadeInterface) GetEvents(ctx datastore.Context, filter events.Filter, since uint64, timeout time.Duration) ([]events.Event, error) {
+	ret := _m.Called(ctx, filter, since, timeout)
+
+	var r0 []events.Event
+	if rf, ok := ret.Get(0).(func(datastore.Context, events.Filter, uint64, time.Duration) []events.Event); ok {
+		r0 = rf(ctx, filter, since, timeout)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]events.Event)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(datastore.Context, events.Filter, uint64, time.Duration) error); ok {
+		r1 = rf(ctx, filter, since, timeout)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SubscribeEvents provides a mock function with given fields: filter, since
+func (_m *FacadeInterface) SubscribeEvents(filter events.Filter, since uint64) *events.Subscription {
+	ret := _m.Called(filter, since)
+
+	var r0 *events.Subscription
+	if rf, ok := ret.Get(0).(func(events.Filter, uint64) *events.Subscription); ok {
+		r0 = rf(filter, since)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*events.Subscription)
+		}
+	}
+
+	return r0
+}
+
 //RestartService provides a mock function with given fields: ctx, ScheduleServiceRequest
 func (_m *FacadeInterface) RestartService(ctx datastore.Context, request dao.ScheduleServiceRequest) (int, error) {
 
@@ -1807,3 +1920,248 @@ func (_m *FacadeInterface) GetServiceNamePath(ctx datastore.Context, serviceID s
 
 	return r0, r1, r2
 }
+
+// GetRolloutStatus provides a mock function with given fields: ctx, serviceID
+func (_m *FacadeInterface) GetRolloutStatus(ctx datastore.Context, serviceID string) (*service.RolloutStatus, error) {
+	ret := _m.Called(ctx, serviceID)
+
+	var r0 *service.RolloutStatus
+	if rf, ok := ret.Get(0).(func(datastore.Context, string) *service.RolloutStatus); ok {
+		r0 = rf(ctx, serviceID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*service.RolloutStatus)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(datastore.Context, string) error); ok {
+		r1 = rf(ctx, serviceID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetServiceTree provides a mock function with given fields: ctx, serviceID
+func (_m *FacadeInterface) GetServiceTree(ctx datastore.Context, serviceID string) (*service.ServiceTree, error) {
+	ret := _m.Called(ctx, serviceID)
+
+	var r0 *service.ServiceTree
+	if rf, ok := ret.Get(0).(func(datastore.Context, string) *service.ServiceTree); ok {
+		r0 = rf(ctx, serviceID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*service.ServiceTree)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(datastore.Context, string) error); ok {
+		r1 = rf(ctx, serviceID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ApplyServiceTree provides a mock function with given fields: ctx, tree, dryRun
+func (_m *FacadeInterface) ApplyServiceTree(ctx datastore.Context, tree service.ServiceTree, dryRun bool) ([]service.ServiceChange, error) {
+	ret := _m.Called(ctx, tree, dryRun)
+
+	var r0 []service.ServiceChange
+	if rf, ok := ret.Get(0).(func(datastore.Context, service.ServiceTree, bool) []service.ServiceChange); ok {
+		r0 = rf(ctx, tree, dryRun)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]service.ServiceChange)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(datastore.Context, service.ServiceTree, bool) error); ok {
+		r1 = rf(ctx, tree, dryRun)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AddSecret provides a mock function with given fields: ctx, name, description, value
+func (_m *FacadeInterface) AddSecret(ctx datastore.Context, name string, description string, value []byte) error {
+	ret := _m.Called(ctx, name, description, value)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(datastore.Context, string, string, []byte) error); ok {
+		r0 = rf(ctx, name, description, value)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RotateSecret provides a mock function with given fields: ctx, name, value
+func (_m *FacadeInterface) RotateSecret(ctx datastore.Context, name string, value []byte) error {
+	ret := _m.Called(ctx, name, value)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(datastore.Context, string, []byte) error); ok {
+		r0 = rf(ctx, name, value)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RemoveSecret provides a mock function with given fields: ctx, name
+func (_m *FacadeInterface) RemoveSecret(ctx datastore.Context, name string) error {
+	ret := _m.Called(ctx, name)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(datastore.Context, string) error); ok {
+		r0 = rf(ctx, name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetSecrets provides a mock function with given fields: ctx
+func (_m *FacadeInterface) GetSecrets(ctx datastore.Context) ([]secret.Secret, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []secret.Secret
+	if rf, ok := ret.Get(0).(func(datastore.Context) []secret.Secret); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]secret.Secret)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(datastore.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetServiceSecrets provides a mock function with given fields: ctx, hostID, serviceID, instanceID
+func (_m *FacadeInterface) GetServiceSecrets(ctx datastore.Context, hostID string, serviceID string, instanceID int) (map[string]string, error) {
+	ret := _m.Called(ctx, hostID, serviceID, instanceID)
+
+	var r0 map[string]string
+	if rf, ok := ret.Get(0).(func(datastore.Context, string, string, int) map[string]string); ok {
+		r0 = rf(ctx, hostID, serviceID, instanceID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(datastore.Context, string, string, int) error); ok {
+		r1 = rf(ctx, hostID, serviceID, instanceID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetSnapshotPolicy provides a mock function with given fields: ctx, policy
+func (_m *FacadeInterface) SetSnapshotPolicy(ctx datastore.Context, policy snapshotpolicy.SnapshotPolicy) error {
+	ret := _m.Called(ctx, policy)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(datastore.Context, snapshotpolicy.SnapshotPolicy) error); ok {
+		r0 = rf(ctx, policy)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetSnapshotPolicies provides a mock function with given fields: ctx
+func (_m *FacadeInterface) GetSnapshotPolicies(ctx datastore.Context) ([]snapshotpolicy.SnapshotPolicy, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []snapshotpolicy.SnapshotPolicy
+	if rf, ok := ret.Get(0).(func(datastore.Context) []snapshotpolicy.SnapshotPolicy); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]snapshotpolicy.SnapshotPolicy)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(datastore.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveSnapshotPolicy provides a mock function with given fields: ctx, tenantID
+func (_m *FacadeInterface) RemoveSnapshotPolicy(ctx datastore.Context, tenantID string) error {
+	ret := _m.Called(ctx, tenantID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(datastore.Context, string) error); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DiffSnapshot provides a mock function with given fields: ctx, snapshotID, otherID
+func (_m *FacadeInterface) DiffSnapshot(ctx datastore.Context, snapshotID string, otherID string) ([]volume.FileChange, error) {
+	ret := _m.Called(ctx, snapshotID, otherID)
+
+	var r0 []volume.FileChange
+	if rf, ok := ret.Get(0).(func(datastore.Context, string, string) []volume.FileChange); ok {
+		r0 = rf(ctx, snapshotID, otherID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]volume.FileChange)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(datastore.Context, string, string) error); ok {
+		r1 = rf(ctx, snapshotID, otherID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RestoreSnapshotPaths provides a mock function with given fields: ctx, snapshotID, paths
+func (_m *FacadeInterface) RestoreSnapshotPaths(ctx datastore.Context, snapshotID string, paths []string) error {
+	ret := _m.Called(ctx, snapshotID, paths)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(datastore.Context, string, []string) error); ok {
+		r0 = rf(ctx, snapshotID, paths)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
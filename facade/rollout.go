@@ -0,0 +1,468 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package facade
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/control-center/serviced/datastore"
+	"github.com/control-center/serviced/domain/service"
+	"github.com/control-center/serviced/domain/servicedefinition"
+	"github.com/control-center/serviced/health"
+	zkservice "github.com/control-center/serviced/zzk/service"
+)
+
+var (
+	// ErrNoRollout is returned when a service has not been restarted since
+	// the master started
+	ErrNoRollout = errors.New("facade: service has no rollout")
+)
+
+// rolloutTracker keeps the progress of the most recent rolling restart of
+// each service, the cancel channels of running image rollouts, and the locks
+// that keep two rollouts of a service from running at once.
+type rolloutTracker struct {
+	mu       sync.RWMutex
+	statuses map[string]service.RolloutStatus
+	cancels  map[string]chan interface{}
+	locks    map[string]chan struct{}
+}
+
+func newRolloutTracker() *rolloutTracker {
+	return &rolloutTracker{
+		statuses: make(map[string]service.RolloutStatus),
+		cancels:  make(map[string]chan interface{}),
+		locks:    make(map[string]chan struct{}),
+	}
+}
+
+// lock waits until no other rollout of a service is running, and returns a
+// function that releases the lock.  It returns false if the rollout is
+// cancelled while it waits.
+func (t *rolloutTracker) lock(serviceID string, cancel <-chan interface{}) (func(), bool) {
+	t.mu.Lock()
+	sem, ok := t.locks[serviceID]
+	if !ok {
+		sem = make(chan struct{}, 1)
+		t.locks[serviceID] = sem
+	}
+	t.mu.Unlock()
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }, true
+	case <-cancel:
+		return nil, false
+	}
+}
+
+// set updates the progress of a service's rollout
+func (t *rolloutTracker) set(status service.RolloutStatus) {
+	status.UpdatedAt = time.Now()
+	t.mu.Lock()
+	t.statuses[status.ServiceID] = status
+	t.mu.Unlock()
+}
+
+// get returns the progress of a service's most recent rollout
+func (t *rolloutTracker) get(serviceID string) (service.RolloutStatus, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	status, ok := t.statuses[serviceID]
+	return status, ok
+}
+
+// start returns the cancel channel of a new image rollout of a service, and
+// cancels the service's previous image rollout if it is still running.
+func (t *rolloutTracker) start(serviceID string) chan interface{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if cancel, ok := t.cancels[serviceID]; ok {
+		close(cancel)
+	}
+	cancel := make(chan interface{})
+	t.cancels[serviceID] = cancel
+	return cancel
+}
+
+// finish forgets the cancel channel of an image rollout that has returned
+func (t *rolloutTracker) finish(serviceID string, cancel chan interface{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.cancels[serviceID] == cancel {
+		delete(t.cancels, serviceID)
+	}
+}
+
+// GetRolloutStatus returns the progress of the most recent rolling restart of
+// a service.
+func (f *Facade) GetRolloutStatus(ctx datastore.Context, serviceID string) (*service.RolloutStatus, error) {
+	defer ctx.Metrics().Stop(ctx.Metrics().Start("Facade.GetRolloutStatus"))
+	status, ok := f.rollouts.get(serviceID)
+	if !ok {
+		return nil, ErrNoRollout
+	}
+	return &status, nil
+}
+
+// startImageRollout restarts a running service onto its new image in the
+// background, and cancels any earlier image rollout of the service.  The
+// rollout outlives the request that started it, so it gets its own context.
+func (f *Facade) startImageRollout(ctx datastore.Context, svc *service.Service, previousImage string) {
+	rctx := datastore.GetNewInstance()
+	rctx.SetUser(ctx.User())
+	cancel := f.rollouts.start(svc.ID)
+	go func() {
+		defer f.rollouts.finish(svc.ID, cancel)
+		if err := f.rollout(rctx, svc, previousImage, f.rollingRestartTimeout, cancel); err != nil {
+			plog.WithError(err).WithFields(log.Fields{
+				"serviceid":   svc.ID,
+				"servicename": svc.Name,
+				"imageid":     svc.ImageID,
+			}).Warn("Could not roll out new image for service")
+		}
+	}()
+}
+
+// rollingRestart restarts a service in batches, following its rollout
+// policy, and waits for each batch to pass health checks before restarting
+// the next one.
+func (f *Facade) rollingRestart(ctx datastore.Context, svc *service.Service, timeout time.Duration, cancel <-chan interface{}) error {
+	return f.rollout(ctx, svc, "", timeout, cancel)
+}
+
+// rollout restarts the instances of a service in batches.  previousImage is
+// the image that the service is moving off of, if its image has changed, and
+// the service is rolled back to it if a batch fails its health checks and the
+// policy allows.  A rollout waits for any other rollout of the service, such
+// as a rolling restart by the scheduler, to finish first.
+func (f *Facade) rollout(ctx datastore.Context, svc *service.Service, previousImage string, timeout time.Duration, cancel <-chan interface{}) error {
+	logger := plog.WithFields(log.Fields{
+		"serviceid":   svc.ID,
+		"servicename": svc.Name,
+	})
+
+	unlock, ok := f.rollouts.lock(svc.ID, cancel)
+	if !ok {
+		logger.Debug("Rollout cancelled while waiting for another rollout of the service")
+		return nil
+	}
+	defer unlock()
+
+	policy := servicedefinition.RolloutPolicy{}
+	if svc.RolloutPolicy != nil {
+		policy = *svc.RolloutPolicy
+	}
+	batches := policy.Batches(svc.Instances)
+	status := service.RolloutStatus{
+		ServiceID:     svc.ID,
+		State:         service.RolloutRunning,
+		ImageID:       svc.ImageID,
+		PreviousImage: previousImage,
+		Instances:     svc.Instances,
+		Batches:       len(batches),
+		StartedAt:     time.Now(),
+	}
+	f.rollouts.set(status)
+	fail := func(err error) error {
+		status.State = service.RolloutFailed
+		status.Error = err.Error()
+		f.rollouts.set(status)
+		return err
+	}
+
+	// Run through and set all instances to "Pending Restart"
+	for instanceID := 0; instanceID < svc.Instances; instanceID++ {
+		err := f.zzk.UpdateInstanceCurrentState(ctx, svc.PoolID, svc.ID, instanceID, service.StatePendingRestart)
+		if err != nil {
+			logger.WithError(err).Debug("Failed to update instance current state to pending restart")
+			return fail(err)
+		}
+	}
+
+	// Keep track of which instances haven't been restarted yet, so we can
+	// revert the current state if we exit prematurely.  Batches restart the
+	// instances in order.
+	restarted := 0
+	defer func() {
+		for instance := restarted; instance < svc.Instances; instance++ {
+			err := f.zzk.UpdateInstanceCurrentState(ctx, svc.PoolID, svc.ID, instance, service.StateRunning)
+			if err != nil {
+				logger.WithField("instance", instance).WithError(err).Error("Failed to revert instance current state to started")
+			}
+		}
+	}()
+
+	// Build the service health object to use for getting instance health
+	svch := service.BuildServiceHealth(*svc)
+	punctualInstances := 0
+
+	for i, batch := range batches {
+		blogger := logger.WithFields(log.Fields{
+			"batch":     i + 1,
+			"instances": batch,
+		})
+
+		select {
+		case <-cancel:
+			status.State = service.RolloutCancelled
+			f.rollouts.set(status)
+			return nil
+		default:
+		}
+
+		if i > 0 && policy.PauseBetweenBatches > 0 {
+			status.State = service.RolloutPaused
+			f.rollouts.set(status)
+			select {
+			case <-cancel:
+				status.State = service.RolloutCancelled
+				f.rollouts.set(status)
+				return nil
+			case <-time.After(policy.Pause()):
+			}
+		}
+
+		canary := i == 0 && policy.CanaryInstances > 0
+		status.Batch = i + 1
+		status.State = service.RolloutRunning
+		if canary {
+			status.State = service.RolloutCanary
+		}
+		f.rollouts.set(status)
+
+		// Canaries, and every batch of a rollout that can be rolled back,
+		// must pass health checks.  Otherwise, don't wait on the last batch,
+		// and let as many instances of a batch stay unhealthy as the next
+		// batch leaves room for.
+		last := i == len(batches)-1
+		waitHealth := !last || canary || policy.AutoRollback
+		allowance := 0
+		if !last && !canary {
+			if allowance = policy.MaxUnavailableInstances() - len(batches[i+1]); allowance < 0 {
+				allowance = 0
+			}
+		}
+
+		blogger.Debug("Restarting batch")
+		result, err := f.restartBatch(ctx, svc, svch, batch, allowance, waitHealth, timeout, cancel)
+		restarted += result.restarted
+		status.Restarted = restarted
+		if err != nil {
+			return fail(err)
+		}
+		if result.punctual {
+			punctualInstances += len(batch)
+		}
+		if result.cancelled {
+			status.State = service.RolloutCancelled
+			f.rollouts.set(status)
+			return nil
+		}
+		if !result.healthy && (canary || policy.AutoRollback) {
+			err := fmt.Errorf("instances %v did not pass health checks", batch)
+			blogger.Warn("Aborting rollout, restarted instances did not pass health checks")
+			if policy.AutoRollback && previousImage != "" {
+				status.Error = err.Error()
+				return f.rollback(ctx, svc, previousImage, restarted, policy, timeout, cancel, &status)
+			}
+			return fail(err)
+		}
+		f.rollouts.set(status)
+		blogger.Debug("Done restarting batch")
+	}
+
+	if punctualInstances == svc.Instances {
+		f.SetServicesCurrentState(ctx, service.SVCCSRunning, svc.ID)
+	}
+	status.State = service.RolloutComplete
+	f.rollouts.set(status)
+	return nil
+}
+
+// rollback restores the previous image of a service after a failed rollout,
+// and restarts the instances that had moved to the new image.
+func (f *Facade) rollback(ctx datastore.Context, svc *service.Service, previousImage string, restarted int, policy servicedefinition.RolloutPolicy, timeout time.Duration, cancel <-chan interface{}, status *service.RolloutStatus) error {
+	logger := plog.WithFields(log.Fields{
+		"serviceid":     svc.ID,
+		"servicename":   svc.Name,
+		"previousimage": previousImage,
+	})
+	reason := status.Error
+	fail := func(err error) error {
+		status.State = service.RolloutFailed
+		status.Error = fmt.Sprintf("%s; rollback failed: %s", reason, err)
+		f.rollouts.set(*status)
+		return err
+	}
+
+	status.State = service.RolloutRollingBack
+	f.rollouts.set(*status)
+	logger.Info("Rolling back service to its previous image")
+
+	current, err := f.serviceStore.Get(ctx, svc.ID)
+	if err != nil {
+		logger.WithError(err).Debug("Could not look up service")
+		return fail(err)
+	}
+	current.ImageID = previousImage
+	if err := f.UpdateService(ctx, *current); err != nil {
+		logger.WithError(err).Debug("Could not restore the previous image")
+		return fail(err)
+	}
+
+	svch := service.BuildServiceHealth(*current)
+	for _, batch := range policy.Batches(restarted) {
+		result, err := f.restartBatch(ctx, current, svch, batch, 0, false, timeout, cancel)
+		if err != nil {
+			return fail(err)
+		} else if result.cancelled {
+			break
+		}
+	}
+	status.State = service.RolloutRolledBack
+	f.rollouts.set(*status)
+	return fmt.Errorf("rolled back to image %s: %s", previousImage, reason)
+}
+
+// batchResult is the outcome of restarting a batch of instances
+type batchResult struct {
+	restarted int  // instances of the batch that were restarted
+	punctual  bool // the batch restarted and passed health checks before the timeout
+	healthy   bool // the batch passed health checks, or was not checked
+	cancelled bool // the rollout was cancelled
+}
+
+// restartBatch restarts a batch of instances of a service, waits for their
+// containers to change and, if waitHealth is set, for all but allowance of
+// them to pass health checks.  Waits end when the timeout expires.
+func (f *Facade) restartBatch(ctx datastore.Context, svc *service.Service, svch *service.ServiceHealth, batch []int, allowance int, waitHealth bool, timeout time.Duration, cancel <-chan interface{}) (batchResult, error) {
+	logger := plog.WithFields(log.Fields{
+		"serviceid":   svc.ID,
+		"servicename": svc.Name,
+		"instances":   batch,
+	})
+	result := batchResult{healthy: !waitHealth}
+
+	// Set up the timeout
+	cancelWait := make(chan struct{})
+	done := make(chan struct{})
+	punctual := make(chan bool, 1)
+	timer := time.NewTimer(timeout)
+	go func() {
+		onTime := false
+		select {
+		case <-timer.C:
+			logger.Warn("Timeout waiting for instances to restart")
+		case <-done:
+			onTime = true
+		case <-cancel:
+			logger.Debug("Rolling restart cancelled")
+		}
+		timer.Stop()
+		close(cancelWait)
+		punctual <- onTime
+	}()
+	finish := func() {
+		close(done)
+		result.punctual = <-punctual
+	}
+
+	oldContainers := make(map[int]string)
+	for _, instanceID := range batch {
+		// Before we restart, check the current instance's container ID
+		state, err := f.zzk.GetServiceState(ctx, svc.PoolID, svc.ID, instanceID)
+		if err != nil {
+			finish()
+			logger.WithField("instance", instanceID).WithError(err).Debug("Failed to get service's current container ID")
+			return result, err
+		}
+		oldContainers[instanceID] = state.ContainerID
+
+		if err = f.zzk.RestartInstance(ctx, svc.PoolID, svc.ID, instanceID); err != nil {
+			finish()
+			logger.WithField("instance", instanceID).WithError(err).Debug("Failed to restart instance")
+			return result, err
+		}
+		result.restarted++
+	}
+
+	for _, instanceID := range batch {
+		// Wait for the instance's containerID to change
+		oldContainer := oldContainers[instanceID]
+		checkContainer := func(s *zkservice.State, exists bool) bool {
+			if !exists {
+				return true
+			}
+			if s.ContainerID != "" && s.ContainerID != oldContainer {
+				return service.InstanceCurrentState(s.Status) == service.StateRunning
+			}
+			return false
+		}
+		if err := f.zzk.WaitInstance(ctx, svc, instanceID, checkContainer, cancelWait); err != nil {
+			finish()
+			logger.WithField("instance", instanceID).WithError(err).Debug("Failed to wait on instance")
+			return result, err
+		}
+	}
+
+	// Check whether the batch is healthy on an interval, until it is or the
+	// wait is over
+	ready := func() bool {
+		unhealthy := 0
+		for _, instanceID := range batch {
+			for key, status := range f.getInstanceHealth(svch, instanceID) {
+				logger.WithFields(log.Fields{
+					"instance": instanceID,
+					"status":   status,
+					"key":      key,
+				}).Debug("Got health status for instance")
+				if status != health.OK {
+					unhealthy++
+					break
+				}
+			}
+		}
+		return unhealthy <= allowance
+	}
+
+	if waitHealth {
+		hctimer := time.NewTimer(500 * time.Millisecond)
+	check:
+		for {
+			if result.healthy = ready(); result.healthy {
+				break
+			}
+			logger.Debug("Instances not ready yet, checking again in 500 ms")
+			select {
+			case <-cancelWait:
+				break check
+			case <-hctimer.C:
+				hctimer.Reset(500 * time.Millisecond)
+			}
+		}
+		hctimer.Stop()
+	}
+
+	finish()
+	select {
+	case <-cancel:
+		result.cancelled = true
+	default:
+	}
+	return result, nil
+}
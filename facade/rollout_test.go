@@ -0,0 +1,64 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build unit
+
+package facade
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+var _ = Suite(&RolloutTrackerTest{})
+
+type RolloutTrackerTest struct{}
+
+func (t *RolloutTrackerTest) TestLock(c *C) {
+	tracker := newRolloutTracker()
+	unlock, ok := tracker.lock("svc1", nil)
+	c.Assert(ok, Equals, true)
+
+	// other services are not blocked
+	unlock2, ok := tracker.lock("svc2", nil)
+	c.Assert(ok, Equals, true)
+	unlock2()
+
+	// a second rollout of the service waits for the first
+	locked := make(chan func())
+	go func() {
+		next, _ := tracker.lock("svc1", nil)
+		locked <- next
+	}()
+	select {
+	case <-locked:
+		c.Fatalf("Second rollout of the service did not wait")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlock()
+	select {
+	case next := <-locked:
+		next()
+	case <-time.After(time.Second):
+		c.Fatalf("Second rollout of the service did not get the lock")
+	}
+
+	// a rollout that is cancelled while it waits gives up
+	unlock, _ = tracker.lock("svc1", nil)
+	defer unlock()
+	cancel := make(chan interface{})
+	close(cancel)
+	_, ok = tracker.lock("svc1", cancel)
+	c.Assert(ok, Equals, false)
+}
@@ -0,0 +1,201 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package facade
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/control-center/serviced/audit"
+	"github.com/control-center/serviced/datastore"
+	"github.com/control-center/serviced/domain/secret"
+	"github.com/control-center/serviced/domain/service"
+)
+
+var (
+	// ErrNoSecretKey is returned when secrets are used on a master that was
+	// not started with a secret key
+	ErrNoSecretKey = errors.New("facade: no secret key is configured")
+	// ErrSecretExists is returned when a secret is added with the name of an
+	// existing secret
+	ErrSecretExists = errors.New("facade: secret exists")
+	// ErrEmptySecret is returned when a secret is given an empty value
+	ErrEmptySecret = errors.New("facade: secret value is empty")
+	// ErrInstanceNotOnHost is returned when a host asks for the secrets of a
+	// service instance that it does not run
+	ErrInstanceNotOnHost = errors.New("facade: service instance does not run on the host")
+)
+
+// SetSecretKey sets the master key that encrypts the values of secrets
+func (f *Facade) SetSecretKey(key []byte) error {
+	c, err := secret.NewCipher(key)
+	if err != nil {
+		return err
+	}
+	f.secretCipher = c
+	return nil
+}
+
+// AddSecret encrypts and stores a new secret
+func (f *Facade) AddSecret(ctx datastore.Context, name, description string, value []byte) error {
+	defer ctx.Metrics().Stop(ctx.Metrics().Start("Facade.AddSecret"))
+	alog := f.auditLogger.Message(ctx, "Adding Secret").Action(audit.Add).
+		ID(name).Type(secret.GetType())
+	if f.secretCipher == nil {
+		return alog.Error(ErrNoSecretKey)
+	} else if len(value) == 0 {
+		return alog.Error(ErrEmptySecret)
+	}
+	if _, err := f.secretStore.Get(ctx, name); err == nil {
+		return alog.Error(ErrSecretExists)
+	} else if !datastore.IsErrNoSuchEntity(err) {
+		return alog.Error(err)
+	}
+	now := time.Now()
+	s := &secret.Secret{
+		Name:        name,
+		Description: description,
+		Version:     1,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := f.secretCipher.Seal(s, value); err != nil {
+		return alog.Error(err)
+	}
+	if err := f.secretStore.Put(ctx, s); err != nil {
+		return alog.Error(err)
+	}
+	alog.Succeeded()
+	return nil
+}
+
+// RotateSecret replaces the value of a secret.  Containers that are already
+// running keep the previous value until they are restarted.
+func (f *Facade) RotateSecret(ctx datastore.Context, name string, value []byte) error {
+	defer ctx.Metrics().Stop(ctx.Metrics().Start("Facade.RotateSecret"))
+	alog := f.auditLogger.Message(ctx, "Rotating Secret").Action(audit.Update).
+		ID(name).Type(secret.GetType())
+	if f.secretCipher == nil {
+		return alog.Error(ErrNoSecretKey)
+	} else if len(value) == 0 {
+		return alog.Error(ErrEmptySecret)
+	}
+	s, err := f.secretStore.Get(ctx, name)
+	if err != nil {
+		return alog.Error(err)
+	}
+	if err := f.secretCipher.Seal(s, value); err != nil {
+		return alog.Error(err)
+	}
+	s.Version++
+	s.UpdatedAt = time.Now()
+	if err := f.secretStore.Put(ctx, s); err != nil {
+		return alog.Error(err)
+	}
+	alog.WithField("version", strconv.Itoa(s.Version)).Succeeded()
+	return nil
+}
+
+// RemoveSecret deletes a secret.  Services that still reference the secret
+// cannot start until it is added again.
+func (f *Facade) RemoveSecret(ctx datastore.Context, name string) error {
+	defer ctx.Metrics().Stop(ctx.Metrics().Start("Facade.RemoveSecret"))
+	alog := f.auditLogger.Message(ctx, "Removing Secret").Action(audit.Remove).
+		ID(name).Type(secret.GetType())
+	if _, err := f.secretStore.Get(ctx, name); err != nil {
+		return alog.Error(err)
+	}
+	if err := f.secretStore.Delete(ctx, name); err != nil {
+		return alog.Error(err)
+	}
+	alog.Succeeded()
+	return nil
+}
+
+// GetSecrets returns all secrets without their values
+func (f *Facade) GetSecrets(ctx datastore.Context) ([]secret.Secret, error) {
+	defer ctx.Metrics().Stop(ctx.Metrics().Start("Facade.GetSecrets"))
+	secrets, err := f.secretStore.GetSecrets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]secret.Secret, len(secrets))
+	for i, s := range secrets {
+		result[i] = s.Redact()
+	}
+	return result, nil
+}
+
+// GetSecretValue decrypts the value of a secret
+func (f *Facade) GetSecretValue(ctx datastore.Context, name string) (string, error) {
+	defer ctx.Metrics().Stop(ctx.Metrics().Start("Facade.GetSecretValue"))
+	if f.secretCipher == nil {
+		return "", ErrNoSecretKey
+	}
+	s, err := f.secretStore.Get(ctx, name)
+	if err != nil {
+		plog.WithField("secret", name).WithError(err).Debug("Could not look up secret")
+		return "", err
+	}
+	value, err := f.secretCipher.Open(s)
+	if err != nil {
+		return "", err
+	}
+	return string(value), nil
+}
+
+// GetServiceSecrets decrypts the secrets that are delivered to a service
+// instance, by name.  The instance must be scheduled on the host.
+func (f *Facade) GetServiceSecrets(ctx datastore.Context, hostID, serviceID string, instanceID int) (map[string]string, error) {
+	defer ctx.Metrics().Stop(ctx.Metrics().Start("Facade.GetServiceSecrets"))
+	logger := plog.WithFields(log.Fields{
+		"hostid":     hostID,
+		"serviceid":  serviceID,
+		"instanceid": instanceID,
+	})
+
+	svc, err := f.serviceStore.Get(ctx, serviceID)
+	if err != nil {
+		logger.WithError(err).Debug("Could not look up service")
+		return nil, err
+	}
+
+	state, err := f.zzk.GetServiceState(ctx, svc.PoolID, svc.ID, instanceID)
+	if err != nil {
+		logger.WithError(err).Debug("Could not locate service instance")
+		return nil, err
+	}
+	if hostID == "" || state.HostID != hostID {
+		logger.WithField("instancehostid", state.HostID).Warn("Host asked for the secrets of a service instance that it does not run")
+		return nil, ErrInstanceNotOnHost
+	}
+
+	values := make(map[string]string)
+	for _, ref := range svc.Secrets {
+		if values[ref.Name], err = f.GetSecretValue(ctx, ref.Name); err != nil {
+			logger.WithField("secret", ref.Name).WithError(err).Debug("Could not get secret value")
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// secretGetter returns the secret lookup used to evaluate services
+func (f *Facade) secretGetter(ctx datastore.Context) service.GetSecret {
+	return func(name string) (string, error) {
+		return f.GetSecretValue(ctx, name)
+	}
+}
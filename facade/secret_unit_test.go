@@ -0,0 +1,178 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build unit
+
+package facade_test
+
+import (
+	"bytes"
+
+	auditmocks "github.com/control-center/serviced/audit/mocks"
+	"github.com/control-center/serviced/datastore"
+	"github.com/control-center/serviced/domain/secret"
+	"github.com/control-center/serviced/domain/service"
+	"github.com/control-center/serviced/domain/serviceconfigfile"
+	"github.com/control-center/serviced/domain/servicedefinition"
+	"github.com/control-center/serviced/facade"
+	zks "github.com/control-center/serviced/zzk/service"
+	"github.com/stretchr/testify/mock"
+	. "gopkg.in/check.v1"
+)
+
+var secretTestKey = bytes.Repeat([]byte{0x42}, 32)
+
+// storeSecrets keeps the secrets put into the mock store
+func (ft *FacadeUnitTest) storeSecrets() map[string]*secret.Secret {
+	secrets := make(map[string]*secret.Secret)
+	ft.secretStore.On("Put", ft.ctx, mock.AnythingOfType("*secret.Secret")).Return(nil).Run(func(args mock.Arguments) {
+		s := *args.Get(1).(*secret.Secret)
+		secrets[s.Name] = &s
+	})
+	ft.secretStore.On("Get", ft.ctx, mock.AnythingOfType("string")).Return(
+		func(ctx datastore.Context, name string) *secret.Secret {
+			if s, ok := secrets[name]; ok {
+				copy := *s
+				return &copy
+			}
+			return nil
+		},
+		func(ctx datastore.Context, name string) error {
+			if _, ok := secrets[name]; ok {
+				return nil
+			}
+			return datastore.ErrNoSuchEntity{Key: secret.Key(name)}
+		})
+	return secrets
+}
+
+func (ft *FacadeUnitTest) Test_AddSecret(c *C) {
+	c.Assert(ft.Facade.SetSecretKey(secretTestKey), IsNil)
+	secrets := ft.storeSecrets()
+
+	err := ft.Facade.AddSecret(ft.ctx, "db-password", "database password", []byte("hunter2"))
+	c.Assert(err, IsNil)
+	stored := secrets["db-password"]
+	c.Assert(stored, NotNil)
+	c.Assert(stored.Version, Equals, 1)
+	c.Assert(stored.Description, Equals, "database password")
+	c.Assert(bytes.Contains(stored.Ciphertext, []byte("hunter2")), Equals, false)
+
+	value, err := ft.Facade.GetSecretValue(ft.ctx, "db-password")
+	c.Assert(err, IsNil)
+	c.Assert(value, Equals, "hunter2")
+
+	err = ft.Facade.AddSecret(ft.ctx, "db-password", "", []byte("other"))
+	c.Assert(err, Equals, facade.ErrSecretExists)
+
+	err = ft.Facade.AddSecret(ft.ctx, "empty", "", nil)
+	c.Assert(err, Equals, facade.ErrEmptySecret)
+}
+
+func (ft *FacadeUnitTest) Test_AddSecretNoKey(c *C) {
+	mockLogger := &auditmocks.Logger{}
+	mockLogger.On("Message", ft.ctx, mock.AnythingOfType("string")).Return(mockLogger)
+	mockLogger.On("Action", mock.AnythingOfType("string")).Return(mockLogger)
+	mockLogger.On("ID", mock.AnythingOfType("string")).Return(mockLogger)
+	mockLogger.On("Type", mock.AnythingOfType("string")).Return(mockLogger)
+	mockLogger.On("Error", mock.Anything).Return(func(err error) error { return err })
+	f := facade.New()
+	f.SetAuditLogger(mockLogger)
+	f.SetSecretStore(ft.secretStore)
+	err := f.AddSecret(ft.ctx, "db-password", "", []byte("hunter2"))
+	c.Assert(err, Equals, facade.ErrNoSecretKey)
+	_, err = f.GetSecretValue(ft.ctx, "db-password")
+	c.Assert(err, Equals, facade.ErrNoSecretKey)
+}
+
+func (ft *FacadeUnitTest) Test_RotateSecret(c *C) {
+	c.Assert(ft.Facade.SetSecretKey(secretTestKey), IsNil)
+	secrets := ft.storeSecrets()
+	c.Assert(ft.Facade.AddSecret(ft.ctx, "db-password", "", []byte("hunter2")), IsNil)
+
+	err := ft.Facade.RotateSecret(ft.ctx, "db-password", []byte("correct horse"))
+	c.Assert(err, IsNil)
+	c.Assert(secrets["db-password"].Version, Equals, 2)
+	value, err := ft.Facade.GetSecretValue(ft.ctx, "db-password")
+	c.Assert(err, IsNil)
+	c.Assert(value, Equals, "correct horse")
+
+	err = ft.Facade.RotateSecret(ft.ctx, "missing", []byte("value"))
+	c.Assert(datastore.IsErrNoSuchEntity(err), Equals, true)
+}
+
+func (ft *FacadeUnitTest) Test_GetSecretsRedacted(c *C) {
+	c.Assert(ft.Facade.SetSecretKey(secretTestKey), IsNil)
+	secrets := ft.storeSecrets()
+	c.Assert(ft.Facade.AddSecret(ft.ctx, "db-password", "", []byte("hunter2")), IsNil)
+	ft.secretStore.On("GetSecrets", ft.ctx).Return([]*secret.Secret{secrets["db-password"]}, nil)
+
+	result, err := ft.Facade.GetSecrets(ft.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(result, HasLen, 1)
+	c.Assert(result[0].Name, Equals, "db-password")
+	c.Assert(result[0].Ciphertext, IsNil)
+	c.Assert(result[0].Nonce, IsNil)
+	c.Assert(secrets["db-password"].Ciphertext, NotNil)
+}
+
+func (ft *FacadeUnitTest) Test_GetEvaluatedServiceSecrets(c *C) {
+	c.Assert(ft.Facade.SetSecretKey(secretTestKey), IsNil)
+	ft.storeSecrets()
+	c.Assert(ft.Facade.AddSecret(ft.ctx, "db-password", "", []byte("hunter2")), IsNil)
+
+	serviceID := "secretservice"
+	svc := service.Service{
+		ID:          serviceID,
+		Name:        "app",
+		Environment: []string{`PASSWORD={{secret "db-password"}}`},
+		Secrets:     []servicedefinition.SecretRef{{Name: "db-password", File: "db"}},
+	}
+	ft.serviceStore.On("GetServiceDetails", ft.ctx, serviceID).Return(&service.ServiceDetails{ID: serviceID}, nil)
+	ft.serviceStore.On("Get", ft.ctx, serviceID).Return(&svc, nil)
+	ft.configStore.On("GetConfigFiles", ft.ctx, serviceID, "/"+serviceID).Return([]*serviceconfigfile.SvcConfigFile{}, nil)
+
+	result, err := ft.Facade.GetEvaluatedService(ft.ctx, serviceID, 0)
+	c.Assert(err, IsNil)
+	c.Assert(result.Environment, DeepEquals, []string{"PASSWORD=hunter2"})
+}
+
+func (ft *FacadeUnitTest) Test_GetServiceSecrets(c *C) {
+	c.Assert(ft.Facade.SetSecretKey(secretTestKey), IsNil)
+	ft.storeSecrets()
+	c.Assert(ft.Facade.AddSecret(ft.ctx, "db-password", "", []byte("hunter2")), IsNil)
+
+	serviceID := "secretservice"
+	svc := service.Service{
+		ID:      serviceID,
+		PoolID:  "default",
+		Name:    "app",
+		Secrets: []servicedefinition.SecretRef{{Name: "db-password", File: "db"}},
+	}
+	ft.serviceStore.On("Get", ft.ctx, serviceID).Return(&svc, nil)
+	ft.zzk.On("GetServiceState", ft.ctx, "default", serviceID, 1).Return(&zks.State{HostID: "hostid"}, nil)
+
+	values, err := ft.Facade.GetServiceSecrets(ft.ctx, "hostid", serviceID, 1)
+	c.Assert(err, IsNil)
+	c.Assert(values, DeepEquals, map[string]string{"db-password": "hunter2"})
+
+	// the instance runs on another host
+	values, err = ft.Facade.GetServiceSecrets(ft.ctx, "otherhostid", serviceID, 1)
+	c.Assert(err, Equals, facade.ErrInstanceNotOnHost)
+	c.Assert(values, IsNil)
+
+	// the sender has no host
+	values, err = ft.Facade.GetServiceSecrets(ft.ctx, "", serviceID, 1)
+	c.Assert(err, Equals, facade.ErrInstanceNotOnHost)
+	c.Assert(values, IsNil)
+}
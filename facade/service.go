@@ -24,7 +24,6 @@ import (
 	"strings"
 	"sync"
 	"time"
-	"reflect"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/zenoss/glog"
@@ -39,6 +38,7 @@ import (
 	"github.com/control-center/serviced/domain/host"
 	"github.com/control-center/serviced/domain/service"
 	"github.com/control-center/serviced/domain/servicedefinition"
+	"github.com/control-center/serviced/events"
 	"github.com/control-center/serviced/health"
 	"github.com/control-center/serviced/metrics"
 	"github.com/control-center/serviced/scheduler/servicestatemanager"
@@ -259,7 +259,7 @@ func validateServiceOptions(svc *service.Service) error {
 }
 
 //Get changes made by "serviced service edit" call
-func (f * Facade) getChanges(ctx datastore.Context, svc service.Service) string {
+func (f *Facade) getChanges(ctx datastore.Context, svc service.Service) string {
 	var updates string
 	store := f.serviceStore
 	cursvc, err := store.Get(ctx, svc.ID)
@@ -267,26 +267,9 @@ func (f * Facade) getChanges(ctx datastore.Context, svc service.Service) string
 		glog.Errorf("Could not load service %s (%s) from database: %s", svc.Name, svc.ID, err)
 		return updates
 	}
-        if !reflect.DeepEqual(svc, cursvc) {
-		sUpdated := reflect.ValueOf(&svc).Elem()
-		typeOfS := sUpdated.Type()
-		sCur := reflect.ValueOf(cursvc).Elem()
-		for i := 0; i < sUpdated.NumField(); i++ {
-			fUpdated := sUpdated.Field(i)
-			fCur:= sCur.Field(i)
-			fValue := fUpdated.Interface()
-			rt := reflect.TypeOf(fValue)
-			if rt.Kind() == reflect.Slice || rt.Kind() == reflect.Map || rt.Kind() == reflect.Struct {
-				// we don't record changes in ConfigFiles
-				if !reflect.DeepEqual(fUpdated.Interface(), fCur.Interface()) && typeOfS.Field(i).Name != "ConfigFiles" {
-					str := fmt.Sprintf("%s:%v;", typeOfS.Field(i).Name, fUpdated.Interface())
-					updates = updates + str
-				}
-			} else if fUpdated.Interface() != fCur.Interface() {
-				str := fmt.Sprintf("%s:%v;", typeOfS.Field(i).Name, fUpdated.Interface())
-				updates = updates + str
-			}
-		}
+	// we don't record changes in ConfigFiles
+	for _, change := range service.DiffFields(cursvc, &svc, "ConfigFiles") {
+		updates = updates + fmt.Sprintf("%s:%s;", change.Field, change.New)
 	}
 	return updates
 }
@@ -994,6 +977,7 @@ func (f *Facade) RemoveService(ctx datastore.Context, id string) error {
 		}
 		f.zzk.RemoveTenantExports(tenantID)
 		f.zzk.DeleteRegistryLibrary(tenantID)
+		f.removeTenantSnapshotPolicy(ctx, tenantID)
 	}
 	alog.Succeeded()
 	return nil
@@ -1151,7 +1135,7 @@ func (f *Facade) evaluateService(ctx datastore.Context, svc *service.Service, in
 		}
 		return svc, err
 	}
-	return svc.Evaluate(getService, getServiceChild, instanceID)
+	return svc.Evaluate(getService, getServiceChild, f.secretGetter(ctx), instanceID)
 }
 
 // GetServices looks up all services. Allows filtering by tenant ID, name (regular expression), and/or update time.
@@ -1813,167 +1797,23 @@ func (f *Facade) updateDesiredState(ctx datastore.Context, svc *servicestatemana
 		default:
 		}
 
+		previous := service.DesiredState(svc.DesiredState)
 		svc.DesiredState = int(desiredState)
 		// write the service into the database
 		if err := f.serviceStore.UpdateDesiredState(ctx, svc.ID, svc.DesiredState); err != nil {
 			logger.WithError(err).Debug("Could not update desired state")
 			return err
 		}
-	}
-
-	return nil
-}
-
-// rollingRestart restarts a service one instance at a time and waits for it to reach pass health checks.
-func (f *Facade) rollingRestart(ctx datastore.Context, svc *service.Service, timeout time.Duration, cancel <-chan interface{}) error {
-	logger := plog.WithFields(log.Fields{
-		"serviceid":   svc.ID,
-		"servicename": svc.Name,
-	})
-
-	// Run through and set all instances to "Pending Restart"
-	for instanceID := 0; instanceID < svc.Instances; instanceID++ {
-		err := f.zzk.UpdateInstanceCurrentState(ctx, svc.PoolID, svc.ID, instanceID, service.StatePendingRestart)
-		if err != nil {
-			logger.WithError(err).Debug("Failed to update instance current state to pending restart")
-			return err
-		}
-	}
-
-	// Keep track of which instances haven't been restarted yet, so we can revert the current state if we exit
-	// prematurely
-	nextInstance := 0
-	defer func() {
-		for instance := nextInstance; instance < svc.Instances; instance++ {
-			err := f.zzk.UpdateInstanceCurrentState(ctx, svc.PoolID, svc.ID, instance, service.StateRunning)
-			if err != nil {
-				logger.WithField("instance", instance).WithError(err).Error("Failed to revert instance current state to started")
-			}
-		}
-	}()
-
-	// Build the service health object to use for getting instance health
-	svch := service.BuildServiceHealth(*svc)
-	var punctualLock sync.RWMutex
-	punctualInstances := 0
-
-	for instanceID := 0; instanceID < svc.Instances; instanceID++ {
-		ilogger := logger.WithField("instance", instanceID)
-		ilogger.Debug("Restarting instance")
-
-		select {
-		case <-cancel:
-			return nil
-		default:
-		}
-
-		// Set up the timeout
-		cancelWait := make(chan struct{})
-		done := make(chan struct{})
-		timer := time.NewTimer(timeout)
-		go func() {
-			select {
-			case <-timer.C:
-				ilogger.Warn("Timeout waiting for instance to restart")
-			case <-done:
-				punctualLock.Lock()
-				punctualInstances++
-				punctualLock.Unlock()
-			case <-cancel:
-				ilogger.Debug("Rolling restart cancelled")
-			}
-			timer.Stop()
-			close(cancelWait)
-		}()
-
-		// Before we restart, check the current instance's container ID
-		state, err := f.zzk.GetServiceState(ctx, svc.PoolID, svc.ID, instanceID)
-		if err != nil {
-			close(done)
-			logger.WithError(err).Debug("Failed to get service's current container ID")
-			return err
-		}
-
-		oldContainer := state.ContainerID
-
-		if err = f.zzk.RestartInstance(ctx, svc.PoolID, svc.ID, instanceID); err != nil {
-			close(done)
-			logger.WithError(err).Debug("Failed to restart instance")
-			return err
-		}
-
-		// Increment nextInstance now, since we have started the restart process for this instance
-		nextInstance++
-
-		// Wait for the instance's containerID to change
-		checkContainer := func(s *zkservice.State, exists bool) bool {
-			if !exists {
-				return true
-			}
-			if s.ContainerID != "" && s.ContainerID != oldContainer {
-				return service.InstanceCurrentState(s.Status) == service.StateRunning
-			}
-			return false
-		}
-		if err = f.zzk.WaitInstance(ctx, svc, instanceID, checkContainer, cancelWait); err != nil {
-			close(done)
-			logger.WithError(err).Debug("Failed to wait on instance")
-			return err
-		}
-
-		// Check if we're ready to move on to the next instance on an interval
-		ready := func() bool {
-			// I don't care if it is the last instance
-			if instanceID == svc.Instances-1 {
-				return true
-			}
-
-			// Wait for health checks to pass
-			statuses := f.getInstanceHealth(svch, instanceID)
-			for key, status := range statuses {
-				ilogger.WithFields(log.Fields{
-					"status": status,
-					"key":    key,
-				}).Debug("Got health status for instance")
-				if status != health.OK {
-					return false
-				}
-			}
-
-			return true
-		}
-
-		hctimer := time.NewTimer(500 * time.Millisecond)
-
-		// See if we have timed out
-		cancelled := false
-		select {
-		case <-cancelWait:
-			cancelled = true
-		default:
-		}
-
-		for !cancelled && !ready() {
-			ilogger.Debug("Instance not ready yet, checking again in 500 ms")
-			select {
-			case <-cancelWait:
-				cancelled = true
-			case <-hctimer.C:
-				hctimer.Stop()
-				hctimer.Reset(500 * time.Millisecond)
-			}
+		if previous != desiredState {
+			f.publishServiceEvent(ctx, events.Event{
+				Type:      events.ServiceDesiredState,
+				PoolID:    svc.PoolID,
+				ServiceID: svc.ID,
+				Name:      svc.Name,
+				State:     desiredState.String(),
+				Previous:  previous.String(),
+			})
 		}
-		hctimer.Stop()
-		ilogger.Debug("Done restarting instance")
-		close(done)
-		<-cancelWait
-	}
-
-	punctualLock.RLock()
-	instances := punctualInstances
-	punctualLock.RUnlock()
-	if instances == svc.Instances {
-		f.SetServicesCurrentState(ctx, service.SVCCSRunning, svc.ID)
 	}
 
 	return nil
@@ -2382,6 +2222,7 @@ func (f *Facade) ServiceUse(ctx datastore.Context, serviceID, imageName, registr
 			return fmt.Errorf("error parsing image ID %s: %s", imageName, err)
 		}
 		var svcsToUpdate []*service.Service
+		previousImages := make(map[string]string)
 		for _, svc := range svcs {
 			if svc.ImageID == "" {
 				continue
@@ -2398,6 +2239,7 @@ func (f *Facade) ServiceUse(ctx datastore.Context, serviceID, imageName, registr
 			// Change the image in the affected svc to point to our new image
 			origImg.Merge(&commons.ImageID{Repo: newImg.Repo})
 			glog.Infof("Updating image in service %s to %s", svc.Name, origImg.String())
+			previousImages[svc.ID] = svc.ImageID
 			svc.ImageID = origImg.String()
 			svcsToUpdate = append(svcsToUpdate, svc)
 		}
@@ -2408,6 +2250,15 @@ func (f *Facade) ServiceUse(ctx datastore.Context, serviceID, imageName, registr
 				return fmt.Errorf("error updating service %s: %s", svc.Name, err)
 			}
 		}
+
+		// Roll running services that have a rollout policy onto their new
+		// images
+		for _, svc := range svcsToUpdate {
+			if svc.RolloutPolicy != nil && svc.DesiredState == int(service.SVCRun) {
+				glog.Infof("Rolling out image %s to service %s", svc.ImageID, svc.Name)
+				f.startImageRollout(ctx, svc, previousImages[svc.ID])
+			}
+		}
 	}
 	return nil
 }
@@ -2765,8 +2616,19 @@ func (f *Facade) SyncCurrentStates(ctx datastore.Context) error {
 func (f *Facade) SetServicesCurrentState(ctx datastore.Context, currentState service.ServiceCurrentState, serviceIDs ...string) {
 	logger := plog.WithField("currentstate", currentState)
 	for _, sid := range serviceIDs {
+		var previous string
+		if svc, err := f.serviceStore.GetServiceDetails(ctx, sid); err == nil {
+			previous = svc.CurrentState
+		}
 		if err := f.serviceStore.UpdateCurrentState(ctx, sid, string(currentState)); err != nil {
 			logger.WithField("serviceid", sid).WithError(err).Error("Failed to update service current state")
+		} else if previous != string(currentState) {
+			f.publishServiceEvent(ctx, events.Event{
+				Type:      events.ServiceCurrentState,
+				ServiceID: sid,
+				State:     string(currentState),
+				Previous:  previous,
+			})
 		}
 	}
 }
@@ -3402,6 +3402,156 @@ func (ft *FacadeIntegrationTest) TestFacade_rollingRestart_FailGetState(c *C) {
 	c.Assert(err, Equals, testerr)
 }
 
+func (ft *FacadeIntegrationTest) TestFacade_rollingRestart_Batches(c *C) {
+	svc := service.Service{
+		ID:                "serviceID",
+		Name:              "Service",
+		Startup:           "/usr/bin/ping -c localhost",
+		Description:       "Ping a remote host a fixed number of times",
+		Instances:         3,
+		InstanceLimits:    domain.MinMax{Min: 1, Max: 3, Default: 3},
+		ImageID:           "test/pinger",
+		PoolID:            "default",
+		DeploymentID:      "deployment_id",
+		DesiredState:      int(service.SVCRun),
+		Launch:            "auto",
+		Endpoints:         []service.ServiceEndpoint{},
+		CreatedAt:         time.Now(),
+		UpdatedAt:         time.Now(),
+		EmergencyShutdown: false,
+		HealthChecks:      map[string]health.HealthCheck{"healthcheck": health.HealthCheck{}},
+		RolloutPolicy:     &servicedefinition.RolloutPolicy{BatchSize: 2},
+	}
+
+	hcache := health.New()
+	ft.Facade.SetHealthCache(hcache)
+	statusOK := health.HealthStatus{
+		Status:    health.OK,
+		StartedAt: time.Now(),
+		Duration:  time.Minute,
+	}
+	statusFailed := health.HealthStatus{
+		Status:    health.Failed,
+		StartedAt: time.Now(),
+		Duration:  time.Minute,
+	}
+	keys := make([]health.HealthStatusKey, svc.Instances)
+	restarted := make([]chan struct{}, svc.Instances)
+	for i := range keys {
+		keys[i] = health.HealthStatusKey{
+			ServiceID:       svc.ID,
+			InstanceID:      i,
+			HealthCheckName: "healthcheck",
+		}
+		hcache.Set(keys[i], statusFailed, time.Hour)
+		restarted[i] = make(chan struct{})
+		ch := restarted[i]
+		ft.zzk.On("GetServiceState", ft.CTX, svc.PoolID, svc.ID, i).Return(&zks.State{}, nil).Once()
+		ft.zzk.On("RestartInstance", ft.CTX, svc.PoolID, svc.ID, i).Return(nil).Once()
+		ft.zzk.On("WaitInstance", ft.CTX, &svc, i, mock.AnythingOfType("func(*service.State, bool) bool"),
+			mock.AnythingOfType("<-chan struct {}")).Run(func(args mock.Arguments) {
+			close(ch)
+		}).Return(nil).Once()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		err := ft.Facade.rollingRestart(ft.CTX, &svc, 30*time.Second, make(chan interface{}))
+		c.Assert(err, IsNil)
+		close(done)
+	}()
+
+	// Instances 0 and 1 restart together
+	timer := time.NewTimer(5 * time.Second)
+	for _, i := range []int{0, 1} {
+		select {
+		case <-restarted[2]:
+			c.Fatalf("Instance 2 restarted in the first batch")
+		case <-timer.C:
+			c.Fatalf("Timeout waiting for instance %d to restart", i)
+		case <-restarted[i]:
+		}
+	}
+
+	// Instance 2 won't restart while both instances of the first batch are
+	// unhealthy, because that would leave 3 instances unavailable
+	timer.Reset(2 * time.Second)
+	select {
+	case <-restarted[2]:
+		c.Fatalf("Instance 2 restarted before the first batch passed healthcheck")
+	case <-timer.C:
+	}
+
+	// With one instance healthy, restarting instance 2 leaves 2 unavailable
+	hcache.Set(keys[0], statusOK, time.Hour)
+	timer.Reset(5 * time.Second)
+	select {
+	case <-timer.C:
+		c.Fatalf("Timeout waiting for instance 2 to restart")
+	case <-restarted[2]:
+	}
+
+	select {
+	case <-timer.C:
+		c.Fatalf("Timeout waiting for rolling restart")
+	case <-done:
+	}
+	status, err := ft.Facade.GetRolloutStatus(ft.CTX, svc.ID)
+	c.Assert(err, IsNil)
+	c.Assert(status.State, Equals, service.RolloutComplete)
+	c.Assert(status.Restarted, Equals, 3)
+	c.Assert(status.Batches, Equals, 2)
+}
+
+func (ft *FacadeIntegrationTest) TestFacade_rollingRestart_CanaryFails(c *C) {
+	svc := service.Service{
+		ID:                "serviceID",
+		Name:              "Service",
+		Startup:           "/usr/bin/ping -c localhost",
+		Description:       "Ping a remote host a fixed number of times",
+		Instances:         3,
+		InstanceLimits:    domain.MinMax{Min: 1, Max: 3, Default: 3},
+		ImageID:           "test/pinger",
+		PoolID:            "default",
+		DeploymentID:      "deployment_id",
+		DesiredState:      int(service.SVCRun),
+		Launch:            "auto",
+		Endpoints:         []service.ServiceEndpoint{},
+		CreatedAt:         time.Now(),
+		UpdatedAt:         time.Now(),
+		EmergencyShutdown: false,
+		HealthChecks:      map[string]health.HealthCheck{"healthcheck": health.HealthCheck{}},
+		RolloutPolicy:     &servicedefinition.RolloutPolicy{BatchSize: 2, CanaryInstances: 1},
+	}
+
+	hcache := health.New()
+	ft.Facade.SetHealthCache(hcache)
+	hcache.Set(health.HealthStatusKey{
+		ServiceID:       svc.ID,
+		InstanceID:      0,
+		HealthCheckName: "healthcheck",
+	}, health.HealthStatus{
+		Status:    health.Failed,
+		StartedAt: time.Now(),
+		Duration:  time.Minute,
+	}, time.Hour)
+
+	// Only the canary restarts
+	ft.zzk.On("GetServiceState", ft.CTX, svc.PoolID, svc.ID, 0).Return(&zks.State{}, nil).Once()
+	ft.zzk.On("RestartInstance", ft.CTX, svc.PoolID, svc.ID, 0).Return(nil).Once()
+	ft.zzk.On("WaitInstance", ft.CTX, &svc, 0, mock.AnythingOfType("func(*service.State, bool) bool"),
+		mock.AnythingOfType("<-chan struct {}")).Return(nil).Once()
+
+	err := ft.Facade.rollingRestart(ft.CTX, &svc, time.Second, make(chan interface{}))
+	c.Assert(err, NotNil)
+	ft.zzk.AssertNotCalled(c, "RestartInstance", ft.CTX, svc.PoolID, svc.ID, 1)
+
+	status, err := ft.Facade.GetRolloutStatus(ft.CTX, svc.ID)
+	c.Assert(err, IsNil)
+	c.Assert(status.State, Equals, service.RolloutFailed)
+	c.Assert(status.Restarted, Equals, 1)
+}
+
 func (ft *FacadeIntegrationTest) TestFacade_StartMultipleServices(c *C) {
 	// create a service tree that looks like this:
 	// ParentServiceID
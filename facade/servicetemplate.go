@@ -349,6 +349,10 @@ func (f *Facade) DeployTemplate(ctx datastore.Context, poolID string, templateID
 			logger.WithError(err).Error("Could not deploy application")
 			return nil, alog.Error(err)
 		}
+		if err := f.setTenantTemplate(ctx, tenantID, templateID); err != nil {
+			logger.WithError(err).WithField("tenantid", tenantID).Error("Could not record the template for tenant")
+			return nil, alog.Error(err)
+		}
 		if err := f.dfs.Create(tenantID); err != nil {
 			logger.WithError(err).WithField("tenantid", tenantID).Error("Could not initialize volume for tenant")
 			return nil, alog.Error(err)
@@ -511,5 +515,5 @@ func (f *Facade) evaluateEndpointTemplates(ctx datastore.Context, newsvc *servic
 		return *s, err
 	}
 
-	return newsvc.EvaluateEndpointTemplates(getService, findChildService, 0)
+	return newsvc.EvaluateEndpointTemplates(getService, findChildService, nil, 0)
 }
@@ -0,0 +1,101 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package facade
+
+import (
+	"time"
+
+	"github.com/control-center/serviced/audit"
+	"github.com/control-center/serviced/datastore"
+	"github.com/control-center/serviced/domain/snapshotpolicy"
+)
+
+// SetSnapshotPolicy adds or replaces the snapshot policy of the tenant of a
+// service.  The schedule starts from the time the policy is set, unless the
+// schedule of an existing policy is not changed.
+func (f *Facade) SetSnapshotPolicy(ctx datastore.Context, policy snapshotpolicy.SnapshotPolicy) error {
+	defer ctx.Metrics().Stop(ctx.Metrics().Start("Facade.SetSnapshotPolicy"))
+	alog := f.auditLogger.Message(ctx, "Setting Snapshot Policy").Action(audit.Update).
+		ID(policy.TenantID).Type(snapshotpolicy.GetType())
+	tenantID, err := f.GetTenantID(ctx, policy.TenantID)
+	if err != nil {
+		return alog.Error(err)
+	}
+	policy.TenantID = tenantID
+	policy.LastRun = time.Now()
+	if current, err := f.policyStore.Get(ctx, tenantID); err == nil {
+		policy.DatabaseVersion = current.DatabaseVersion
+		if current.Schedule == policy.Schedule {
+			policy.LastRun = current.LastRun
+		}
+	} else if !datastore.IsErrNoSuchEntity(err) {
+		return alog.Error(err)
+	}
+	if err := f.policyStore.Put(ctx, &policy); err != nil {
+		return alog.Error(err)
+	}
+	alog.WithField("tenantid", tenantID).Succeeded()
+	return nil
+}
+
+// GetSnapshotPolicies returns the snapshot policies of all tenants
+func (f *Facade) GetSnapshotPolicies(ctx datastore.Context) ([]snapshotpolicy.SnapshotPolicy, error) {
+	defer ctx.Metrics().Stop(ctx.Metrics().Start("Facade.GetSnapshotPolicies"))
+	policies, err := f.policyStore.GetSnapshotPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]snapshotpolicy.SnapshotPolicy, len(policies))
+	for i, p := range policies {
+		result[i] = *p
+	}
+	return result, nil
+}
+
+// RemoveSnapshotPolicy removes the snapshot policy of a tenant.  Snapshots
+// that were taken by the policy are kept until they are removed by hand.
+func (f *Facade) RemoveSnapshotPolicy(ctx datastore.Context, tenantID string) error {
+	defer ctx.Metrics().Stop(ctx.Metrics().Start("Facade.RemoveSnapshotPolicy"))
+	alog := f.auditLogger.Message(ctx, "Removing Snapshot Policy").Action(audit.Remove).
+		ID(tenantID).Type(snapshotpolicy.GetType())
+	if _, err := f.policyStore.Get(ctx, tenantID); err != nil {
+		return alog.Error(err)
+	}
+	if err := f.policyStore.Delete(ctx, tenantID); err != nil {
+		return alog.Error(err)
+	}
+	alog.Succeeded()
+	return nil
+}
+
+// SetSnapshotPolicyLastRun records when the snapshot policy of a tenant last
+// ran, so that a new master leader picks up the schedule where the previous
+// one left off.
+func (f *Facade) SetSnapshotPolicyLastRun(ctx datastore.Context, tenantID string, lastRun time.Time) error {
+	defer ctx.Metrics().Stop(ctx.Metrics().Start("Facade.SetSnapshotPolicyLastRun"))
+	policy, err := f.policyStore.Get(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	policy.LastRun = lastRun
+	return f.policyStore.Put(ctx, policy)
+}
+
+// removeTenantSnapshotPolicy removes the snapshot policy of a tenant that is
+// being removed, if it has one
+func (f *Facade) removeTenantSnapshotPolicy(ctx datastore.Context, tenantID string) {
+	if err := f.policyStore.Delete(ctx, tenantID); err != nil && !datastore.IsErrNoSuchEntity(err) {
+		plog.WithField("tenantid", tenantID).WithError(err).Warn("Could not remove snapshot policy of tenant")
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build unit
+
+package facade_test
+
+import (
+	"time"
+
+	"github.com/control-center/serviced/datastore"
+	"github.com/control-center/serviced/domain/service"
+	"github.com/control-center/serviced/domain/snapshotpolicy"
+	"github.com/stretchr/testify/mock"
+	. "gopkg.in/check.v1"
+)
+
+// storeSnapshotPolicies keeps the snapshot policies put into the mock store
+func (ft *FacadeUnitTest) storeSnapshotPolicies() map[string]*snapshotpolicy.SnapshotPolicy {
+	policies := make(map[string]*snapshotpolicy.SnapshotPolicy)
+	ft.policyStore.On("Put", ft.ctx, mock.AnythingOfType("*snapshotpolicy.SnapshotPolicy")).Return(nil).Run(func(args mock.Arguments) {
+		p := *args.Get(1).(*snapshotpolicy.SnapshotPolicy)
+		policies[p.TenantID] = &p
+	})
+	ft.policyStore.On("Get", ft.ctx, mock.AnythingOfType("string")).Return(
+		func(ctx datastore.Context, tenantID string) *snapshotpolicy.SnapshotPolicy {
+			if p, ok := policies[tenantID]; ok {
+				copy := *p
+				return &copy
+			}
+			return nil
+		},
+		func(ctx datastore.Context, tenantID string) error {
+			if _, ok := policies[tenantID]; ok {
+				return nil
+			}
+			return datastore.ErrNoSuchEntity{Key: snapshotpolicy.Key(tenantID)}
+		})
+	return policies
+}
+
+func (ft *FacadeUnitTest) Test_SetSnapshotPolicy(c *C) {
+	policies := ft.storeSnapshotPolicies()
+	ft.serviceStore.On("GetServiceDetails", ft.ctx, "tenant").Return(&service.ServiceDetails{ID: "tenant"}, nil)
+	ft.serviceStore.On("GetServiceDetails", ft.ctx, "child").Return(&service.ServiceDetails{ID: "child", ParentServiceID: "tenant"}, nil)
+
+	// a policy set on a child service applies to its tenant
+	err := ft.Facade.SetSnapshotPolicy(ft.ctx, snapshotpolicy.SnapshotPolicy{
+		TenantID:  "child",
+		Schedule:  "@daily",
+		KeepDaily: 7,
+	})
+	c.Assert(err, IsNil)
+	stored := policies["tenant"]
+	c.Assert(stored, NotNil)
+	c.Assert(stored.KeepDaily, Equals, 7)
+	c.Assert(stored.LastRun.IsZero(), Equals, false)
+
+	// keep the schedule going when only the retention changes
+	lastRun := time.Date(2018, time.March, 14, 0, 0, 0, 0, time.UTC)
+	stored.LastRun = lastRun
+	err = ft.Facade.SetSnapshotPolicy(ft.ctx, snapshotpolicy.SnapshotPolicy{
+		TenantID:   "tenant",
+		Schedule:   "@daily",
+		KeepDaily:  7,
+		KeepWeekly: 4,
+	})
+	c.Assert(err, IsNil)
+	c.Assert(policies["tenant"].KeepWeekly, Equals, 4)
+	c.Assert(policies["tenant"].LastRun, Equals, lastRun)
+
+	// restart the schedule when it changes
+	err = ft.Facade.SetSnapshotPolicy(ft.ctx, snapshotpolicy.SnapshotPolicy{
+		TenantID:  "tenant",
+		Schedule:  "@hourly",
+		KeepDaily: 7,
+	})
+	c.Assert(err, IsNil)
+	c.Assert(policies["tenant"].LastRun.After(lastRun), Equals, true)
+}
+
+func (ft *FacadeUnitTest) Test_SetSnapshotPolicyLastRun(c *C) {
+	policies := ft.storeSnapshotPolicies()
+	policies["tenant"] = &snapshotpolicy.SnapshotPolicy{TenantID: "tenant", Schedule: "@daily", KeepDaily: 1}
+
+	lastRun := time.Date(2018, time.March, 14, 0, 0, 0, 0, time.UTC)
+	err := ft.Facade.SetSnapshotPolicyLastRun(ft.ctx, "tenant", lastRun)
+	c.Assert(err, IsNil)
+	c.Assert(policies["tenant"].LastRun, Equals, lastRun)
+
+	err = ft.Facade.SetSnapshotPolicyLastRun(ft.ctx, "missing", lastRun)
+	c.Assert(datastore.IsErrNoSuchEntity(err), Equals, true)
+}
+
+func (ft *FacadeUnitTest) Test_RemoveSnapshotPolicy(c *C) {
+	policies := ft.storeSnapshotPolicies()
+	policies["tenant"] = &snapshotpolicy.SnapshotPolicy{TenantID: "tenant", Schedule: "@daily", KeepDaily: 1}
+	ft.policyStore.On("Delete", ft.ctx, "tenant").Return(nil)
+
+	err := ft.Facade.RemoveSnapshotPolicy(ft.ctx, "tenant")
+	c.Assert(err, IsNil)
+	ft.policyStore.AssertCalled(c, "Delete", ft.ctx, "tenant")
+
+	err = ft.Facade.RemoveSnapshotPolicy(ft.ctx, "missing")
+	c.Assert(datastore.IsErrNoSuchEntity(err), Equals, true)
+}
@@ -0,0 +1,362 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package facade
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/control-center/serviced/audit"
+	"github.com/control-center/serviced/datastore"
+	"github.com/control-center/serviced/domain/service"
+	"github.com/control-center/serviced/domain/servicedefinition"
+	"github.com/control-center/serviced/domain/servicetemplate"
+)
+
+var (
+	// ErrNoBaseTemplate is returned when upgrading a tenant that does not
+	// record the template it was deployed from
+	ErrNoBaseTemplate = errors.New("facade: tenant does not record the template it was deployed from")
+
+	// ErrUpgradeConflicts is returned when a template upgrade changes fields
+	// that were edited locally
+	ErrUpgradeConflicts = errors.New("facade: template upgrade conflicts with locally edited services")
+)
+
+// upgradeSkipFields are the fields of a service that are set when it is
+// deployed, which a template upgrade does not merge.
+var upgradeSkipFields = []string{
+	"ID",
+	"ParentServiceID",
+	"PoolID",
+	"DeploymentID",
+	"TemplateID",
+	"ImageID",
+	"DesiredState",
+	"CurrentState",
+	"CreatedAt",
+	"UpdatedAt",
+	"EmergencyShutdown",
+	"VersionedEntity",
+}
+
+// UpgradeTemplate merges a new service template into a deployed tenant.  The
+// changes between the template the tenant was deployed from and the new
+// template are applied to the tenant's services, unless a service has local
+// edits to the same fields.  Those conflicts fail the upgrade, unless the
+// request keeps the local edits.  A dry run returns the changes and conflicts
+// without making them.
+func (f *Facade) UpgradeTemplate(ctx datastore.Context, request servicetemplate.UpgradeRequest) (*servicetemplate.Upgrade, error) {
+	defer ctx.Metrics().Stop(ctx.Metrics().Start("Facade.UpgradeTemplate"))
+	logger := plog.WithFields(logrus.Fields{
+		"tenantid":   request.TenantID,
+		"templateid": request.TemplateID,
+		"dryrun":     request.DryRun,
+	})
+
+	tenant, err := f.GetService(ctx, request.TenantID)
+	if err != nil {
+		logger.WithError(err).Debug("Could not look up tenant")
+		return nil, err
+	}
+	if tenant.ParentServiceID != "" {
+		return nil, fmt.Errorf("service %s is not a tenant", tenant.Name)
+	}
+	baseTemplateID := tenant.TemplateID
+	if request.BaseTemplateID != "" {
+		baseTemplateID = request.BaseTemplateID
+	}
+	if baseTemplateID == "" {
+		return nil, ErrNoBaseTemplate
+	}
+	logger = logger.WithField("basetemplateid", baseTemplateID)
+
+	baseDef, err := f.getTemplateServiceDefinition(ctx, baseTemplateID, tenant.Name)
+	if err != nil {
+		logger.WithError(err).Debug("Could not load the tenant's deployed template")
+		return nil, err
+	}
+	newDef, err := f.getTemplateServiceDefinition(ctx, request.TemplateID, tenant.Name)
+	if err != nil {
+		logger.WithError(err).Debug("Could not load the new template")
+		return nil, err
+	}
+	current, err := f.GetServiceList(ctx, tenant.ID)
+	if err != nil {
+		logger.WithError(err).Debug("Could not look up the tenant's services")
+		return nil, err
+	}
+	plan, err := planTemplateUpgrade(current, tenant.ID, baseDef, newDef)
+	if err != nil {
+		return nil, err
+	}
+	upgrade := &servicetemplate.Upgrade{
+		TenantID:       tenant.ID,
+		BaseTemplateID: baseTemplateID,
+		TemplateID:     request.TemplateID,
+		Changes:        plan.changes(),
+		Conflicts:      plan.conflicts,
+	}
+	if request.DryRun {
+		return upgrade, nil
+	}
+	if len(plan.conflicts) > 0 && !request.KeepLocal {
+		return nil, ErrUpgradeConflicts
+	}
+
+	alog := f.auditLogger.Message(ctx, "Upgrading Service Template").
+		Action(audit.Update).ID(request.TemplateID).Type(servicetemplate.GetType()).
+		WithFields(logrus.Fields{"tenantid": tenant.ID, "basetemplateid": baseTemplateID})
+	for _, step := range plan.steps {
+		slogger := logger.WithFields(logrus.Fields{
+			"action": step.change.Action,
+			"path":   step.change.Path,
+		})
+		switch step.change.Action {
+		case service.ChangeAdd:
+			_, err = f.deployService(ctx, tenant.ID, step.parentID, tenant.DeploymentID, tenant.PoolID, false, *step.def, func(string) {})
+		case service.ChangeUpdate:
+			err = f.upgradeService(ctx, tenant.ID, step)
+		case service.ChangeRemove:
+			err = f.RemoveService(ctx, step.change.ServiceID)
+		}
+		if err != nil {
+			slogger.WithError(err).Error("Could not upgrade service")
+			return nil, alog.Error(fmt.Errorf("could not %s service %s: %s", step.change.Action, step.change.Path, err))
+		}
+		slogger.Debug("Upgraded service")
+	}
+	if err := f.setTenantTemplate(ctx, tenant.ID, request.TemplateID); err != nil {
+		logger.WithError(err).Error("Could not record the tenant's template")
+		return nil, alog.Error(err)
+	}
+	if err := f.ReloadLogstashConfig(ctx); err != nil {
+		logger.WithError(err).Error("Could not reload logstash configs after upgrading")
+	}
+	logger.WithFields(logrus.Fields{
+		"changes":   len(upgrade.Changes),
+		"conflicts": len(upgrade.Conflicts),
+	}).Info("Upgraded tenant to template")
+	alog.Succeeded()
+	return upgrade, nil
+}
+
+// upgradeService updates a service with the fields merged from a template
+func (f *Facade) upgradeService(ctx datastore.Context, tenantID string, step upgradeStep) error {
+	svc := *step.svc
+	if step.image != "" {
+		image, err := f.dfs.Download(step.image, tenantID, false)
+		if err != nil {
+			return err
+		}
+		svc.ImageID = image
+	}
+	for _, field := range step.change.Fields {
+		if field.Field == "Endpoints" {
+			if err := f.evaluateEndpointTemplates(ctx, &svc); err != nil {
+				return err
+			}
+			break
+		}
+	}
+	return f.UpdateService(ctx, svc)
+}
+
+// setTenantTemplate records the template that a tenant was deployed from
+func (f *Facade) setTenantTemplate(ctx datastore.Context, tenantID, templateID string) error {
+	svc, err := f.serviceStore.Get(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	svc.TemplateID = templateID
+	return f.serviceStore.Put(ctx, svc)
+}
+
+// getTemplateServiceDefinition returns the top level service of a template
+// that deploys the named tenant
+func (f *Facade) getTemplateServiceDefinition(ctx datastore.Context, templateID, name string) (*servicedefinition.ServiceDefinition, error) {
+	template, err := f.templateStore.Get(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range template.Services {
+		if template.Services[i].Name == name {
+			return &template.Services[i], nil
+		}
+	}
+	return nil, fmt.Errorf("template %s does not have a service named %s", templateID, name)
+}
+
+// upgradeStep is a change that a template upgrade makes to a service
+type upgradeStep struct {
+	change   service.ServiceChange
+	svc      *service.Service                     // the merged service to update
+	image    string                               // the template image to pull for the update
+	def      *servicedefinition.ServiceDefinition // the service definition to add
+	parentID string                               // the parent of the service to add
+}
+
+// templateUpgradePlan is the ordered changes, and the conflicts, that merge
+// a template into a tenant
+type templateUpgradePlan struct {
+	steps     []upgradeStep
+	conflicts []service.ServiceConflict
+}
+
+func (p *templateUpgradePlan) changes() []service.ServiceChange {
+	changes := make([]service.ServiceChange, len(p.steps))
+	for i, step := range p.steps {
+		changes[i] = step.change
+	}
+	return changes
+}
+
+// planTemplateUpgrade returns the changes that merge the differences between
+// two versions of a tenant's service definition into the tenant's services.
+// Services are matched by name under their parents.
+func planTemplateUpgrade(current []*service.Service, tenantID string, baseDef, newDef *servicedefinition.ServiceDefinition) (*templateUpgradePlan, error) {
+	byID := make(map[string]*service.Service)
+	children := make(map[string]map[string]*service.Service)
+	for _, svc := range current {
+		byID[svc.ID] = svc
+		if children[svc.ParentServiceID] == nil {
+			children[svc.ParentServiceID] = make(map[string]*service.Service)
+		}
+		children[svc.ParentServiceID][svc.Name] = svc
+	}
+	tenant, ok := byID[tenantID]
+	if !ok {
+		return nil, datastore.ErrNoSuchEntity{Key: datastore.NewKey(service.GetType(), tenantID)}
+	}
+
+	plan := &templateUpgradePlan{}
+	var removals []upgradeStep
+	var walk func(baseDef, newDef *servicedefinition.ServiceDefinition, live *service.Service, path string) error
+	walk = func(baseDef, newDef *servicedefinition.ServiceDefinition, live *service.Service, path string) error {
+		newSvc, err := buildUpgradeService(*newDef, live)
+		if err != nil {
+			return err
+		}
+		var merged *service.Service
+		var fields []service.FieldChange
+		var conflicts []service.FieldConflict
+		if baseDef != nil {
+			baseSvc, err := buildUpgradeService(*baseDef, live)
+			if err != nil {
+				return err
+			}
+			merged, fields, conflicts = service.MergeFields(baseSvc, newSvc, live, upgradeSkipFields...)
+		} else {
+			// the service was added locally and by the template, so every
+			// difference is a conflict
+			merged = live
+			for _, field := range service.DiffFields(live, newSvc, upgradeSkipFields...) {
+				conflicts = append(conflicts, service.FieldConflict{Field: field.Field, Template: field.New, Live: field.Old})
+			}
+		}
+		step := upgradeStep{svc: merged}
+		if baseDef != nil && baseDef.ImageID != newDef.ImageID {
+			fields = append(fields, service.FieldChange{Field: "ImageID", Old: live.ImageID, New: newDef.ImageID})
+			step.image = newDef.ImageID
+		}
+		if len(conflicts) > 0 {
+			plan.conflicts = append(plan.conflicts, service.ServiceConflict{ServiceID: live.ID, Path: path, Fields: conflicts})
+		}
+		if len(fields) > 0 {
+			step.change = service.ServiceChange{Action: service.ChangeUpdate, ServiceID: live.ID, Path: path, Fields: fields}
+			plan.steps = append(plan.steps, step)
+		}
+
+		baseChildren := make(map[string]*servicedefinition.ServiceDefinition)
+		if baseDef != nil {
+			for i := range baseDef.Services {
+				baseChildren[baseDef.Services[i].Name] = &baseDef.Services[i]
+			}
+		}
+		newChildren := make(map[string]bool)
+		for i := range newDef.Services {
+			child := &newDef.Services[i]
+			newChildren[child.Name] = true
+			childPath := path + "/" + child.Name
+			baseChild := baseChildren[child.Name]
+			if liveChild := children[live.ID][child.Name]; liveChild != nil {
+				if err := walk(baseChild, child, liveChild, childPath); err != nil {
+					return err
+				}
+			} else if baseChild == nil {
+				plan.steps = append(plan.steps, upgradeStep{
+					change:   service.ServiceChange{Action: service.ChangeAdd, Path: childPath},
+					def:      child,
+					parentID: live.ID,
+				})
+			} else if !reflect.DeepEqual(baseChild, child) {
+				// the service was removed locally and changed by the template
+				plan.conflicts = append(plan.conflicts, service.ServiceConflict{
+					Path:   childPath,
+					Fields: []service.FieldConflict{{Field: "Service", Base: "deployed", Template: "changed", Live: "removed"}},
+				})
+			}
+		}
+
+		// remove the services that the template removed, but keep the
+		// services that were added locally
+		for name, liveChild := range children[live.ID] {
+			if newChildren[name] || baseChildren[name] == nil {
+				continue
+			}
+			childPath := path + "/" + name
+			if liveChild.DesiredState != int(service.SVCStop) {
+				return fmt.Errorf("%s: service must be stopped before it is removed", childPath)
+			}
+			removals = append(removals, upgradeStep{
+				change: service.ServiceChange{Action: service.ChangeRemove, ServiceID: liveChild.ID, Path: childPath},
+			})
+		}
+		return nil
+	}
+	if err := walk(baseDef, newDef, tenant, tenant.Name); err != nil {
+		return nil, err
+	}
+	sort.Sort(upgradeStepsByPath(removals))
+	plan.steps = append(plan.steps, removals...)
+	return plan, nil
+}
+
+// buildUpgradeService builds a service from a service definition, as it
+// would have been deployed in place of a live service
+func buildUpgradeService(sd servicedefinition.ServiceDefinition, live *service.Service) (*service.Service, error) {
+	svc, err := service.BuildService(sd, live.ParentServiceID, live.PoolID, live.DesiredState, live.DeploymentID)
+	if err != nil {
+		return nil, err
+	}
+	svc.ID = live.ID
+	tags := map[string][]string{
+		"controlplane_service_id": []string{svc.ID},
+	}
+	profile, err := sd.MonitoringProfile.ReBuild("1h-ago", tags)
+	if err != nil {
+		return nil, err
+	}
+	svc.MonitoringProfile = *profile
+	return svc, nil
+}
+
+type upgradeStepsByPath []upgradeStep
+
+func (s upgradeStepsByPath) Len() int           { return len(s) }
+func (s upgradeStepsByPath) Less(i, j int) bool { return s[i].change.Path < s[j].change.Path }
+func (s upgradeStepsByPath) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
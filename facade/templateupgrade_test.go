@@ -0,0 +1,162 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build unit
+
+package facade
+
+import (
+	"github.com/control-center/serviced/commons"
+	"github.com/control-center/serviced/domain/service"
+	"github.com/control-center/serviced/domain/servicedefinition"
+	. "gopkg.in/check.v1"
+)
+
+var _ = Suite(&TemplateUpgradeTest{})
+
+type TemplateUpgradeTest struct {
+	baseDef *servicedefinition.ServiceDefinition
+	current []*service.Service
+}
+
+func (t *TemplateUpgradeTest) SetUpTest(c *C) {
+	t.baseDef = &servicedefinition.ServiceDefinition{
+		Name:    "app",
+		Command: "app",
+		ImageID: "app:1",
+		Launch:  commons.AUTO,
+		Services: []servicedefinition.ServiceDefinition{
+			{Name: "db", Command: "db", Launch: commons.AUTO},
+			{Name: "web", Command: "web", Launch: commons.AUTO},
+		},
+	}
+	t.current = nil
+	t.deploy(c, *t.baseDef, "", "tenant")
+}
+
+// deploy adds the services built from a service definition to the current
+// services
+func (t *TemplateUpgradeTest) deploy(c *C, sd servicedefinition.ServiceDefinition, parentID, id string) {
+	svc, err := buildUpgradeService(sd, &service.Service{
+		ID:              id,
+		ParentServiceID: parentID,
+		PoolID:          "default",
+		DeploymentID:    "deployment",
+		DesiredState:    int(service.SVCStop),
+	})
+	c.Assert(err, IsNil)
+	svc.ImageID = "localhost:5000/" + id + "/" + sd.ImageID
+	t.current = append(t.current, svc)
+	for _, child := range sd.Services {
+		t.deploy(c, child, id, id+"-"+child.Name)
+	}
+}
+
+func (t *TemplateUpgradeTest) newDef() *servicedefinition.ServiceDefinition {
+	sd := *t.baseDef
+	sd.Services = append([]servicedefinition.ServiceDefinition{}, t.baseDef.Services...)
+	return &sd
+}
+
+func (t *TemplateUpgradeTest) TestPlanTemplateUpgrade_NoChanges(c *C) {
+	plan, err := planTemplateUpgrade(t.current, "tenant", t.baseDef, t.newDef())
+	c.Assert(err, IsNil)
+	c.Assert(plan.changes(), HasLen, 0)
+	c.Assert(plan.conflicts, HasLen, 0)
+}
+
+func (t *TemplateUpgradeTest) TestPlanTemplateUpgrade_Update(c *C) {
+	newDef := t.newDef()
+	newDef.ImageID = "app:2"
+	newDef.Services[1].Command = "web --fast"
+
+	plan, err := planTemplateUpgrade(t.current, "tenant", t.baseDef, newDef)
+	c.Assert(err, IsNil)
+	c.Assert(plan.conflicts, HasLen, 0)
+	changes := plan.changes()
+	c.Assert(changes, HasLen, 2)
+	c.Assert(changes[0].Path, Equals, "app")
+	c.Assert(changes[0].Fields, DeepEquals, []service.FieldChange{
+		{Field: "ImageID", Old: "localhost:5000/tenant/app:1", New: "app:2"},
+	})
+	c.Assert(plan.steps[0].image, Equals, "app:2")
+	c.Assert(changes[1].Action, Equals, service.ChangeUpdate)
+	c.Assert(changes[1].ServiceID, Equals, "tenant-web")
+	c.Assert(changes[1].Fields, DeepEquals, []service.FieldChange{
+		{Field: "Startup", Old: "web", New: "web --fast"},
+	})
+	c.Assert(plan.steps[1].svc.Startup, Equals, "web --fast")
+	c.Assert(plan.steps[1].svc.ID, Equals, "tenant-web")
+}
+
+func (t *TemplateUpgradeTest) TestPlanTemplateUpgrade_Conflict(c *C) {
+	t.current[2].Startup = "web --debug"
+	t.current[2].Instances = 4
+	newDef := t.newDef()
+	newDef.Services[1].Command = "web --fast"
+
+	plan, err := planTemplateUpgrade(t.current, "tenant", t.baseDef, newDef)
+	c.Assert(err, IsNil)
+	c.Assert(plan.changes(), HasLen, 0)
+	c.Assert(plan.conflicts, DeepEquals, []service.ServiceConflict{
+		{
+			ServiceID: "tenant-web",
+			Path:      "app/web",
+			Fields: []service.FieldConflict{
+				{Field: "Startup", Base: "web", Template: "web --fast", Live: "web --debug"},
+			},
+		},
+	})
+}
+
+func (t *TemplateUpgradeTest) TestPlanTemplateUpgrade_AddAndRemove(c *C) {
+	t.current = append(t.current, &service.Service{ID: "local", ParentServiceID: "tenant", Name: "local"})
+	newDef := t.newDef()
+	newDef.Services = []servicedefinition.ServiceDefinition{
+		newDef.Services[1],
+		{Name: "cache", Command: "cache", Launch: commons.AUTO},
+	}
+
+	plan, err := planTemplateUpgrade(t.current, "tenant", t.baseDef, newDef)
+	c.Assert(err, IsNil)
+	c.Assert(plan.conflicts, HasLen, 0)
+	changes := plan.changes()
+	c.Assert(changes, HasLen, 2)
+	c.Assert(changes[0].Action, Equals, service.ChangeAdd)
+	c.Assert(changes[0].Path, Equals, "app/cache")
+	c.Assert(plan.steps[0].parentID, Equals, "tenant")
+	c.Assert(plan.steps[0].def.Name, Equals, "cache")
+	c.Assert(changes[1].Action, Equals, service.ChangeRemove)
+	c.Assert(changes[1].ServiceID, Equals, "tenant-db")
+}
+
+func (t *TemplateUpgradeTest) TestPlanTemplateUpgrade_RemoveRunning(c *C) {
+	t.current[1].DesiredState = int(service.SVCRun)
+	newDef := t.newDef()
+	newDef.Services = newDef.Services[1:]
+
+	_, err := planTemplateUpgrade(t.current, "tenant", t.baseDef, newDef)
+	c.Assert(err, ErrorMatches, "app/db: service must be stopped before it is removed")
+}
+
+func (t *TemplateUpgradeTest) TestPlanTemplateUpgrade_RemovedLocally(c *C) {
+	t.current = t.current[:2]
+	newDef := t.newDef()
+	newDef.Services[1].Command = "web --fast"
+
+	plan, err := planTemplateUpgrade(t.current, "tenant", t.baseDef, newDef)
+	c.Assert(err, IsNil)
+	c.Assert(plan.changes(), HasLen, 0)
+	c.Assert(plan.conflicts, HasLen, 1)
+	c.Assert(plan.conflicts[0].Path, Equals, "app/web")
+}
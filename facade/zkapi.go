@@ -58,9 +58,9 @@ func (zk *zkf) syncServiceRegistry(ctx datastore.Context, tenantID string, svcs
 	return nil
 }
 
-func updateServices(ctx datastore.Context, poolconn client.Connection, svcs []*service.Service, setLockOnCreate, setLockOnUpdate bool) error {
+func updateServices(ctx datastore.Context, poolconn client.Connection, tenantID string, svcs []*service.Service, setLockOnCreate, setLockOnUpdate bool) error {
 	defer ctx.Metrics().Stop(ctx.Metrics().Start("zks.updateServices"))
-	return zks.UpdateServices(poolconn, svcs, setLockOnCreate, setLockOnUpdate)
+	return zks.UpdateServices(poolconn, tenantID, svcs, setLockOnCreate, setLockOnUpdate)
 }
 
 func zkr_SyncServiceRegistry(ctx datastore.Context, conn client.Connection, request zkr.ServiceRegistrySyncRequest) error {
@@ -107,7 +107,7 @@ func (zk *zkf) UpdateServices(ctx datastore.Context, tenantID string, svcs []*se
 			poolLogger.WithError(err).Debug("Could not sync public endpoints in zookeeper")
 			return err
 		}
-		if err := updateServices(ctx, poolconn, *poolSvcs, setLockOnCreate, setLockOnUpdate); err != nil {
+		if err := updateServices(ctx, poolconn, tenantID, *poolSvcs, setLockOnCreate, setLockOnUpdate); err != nil {
 			poolLogger.WithError(err).Debug("Could not update the services in zookeeper")
 			return err
 		}
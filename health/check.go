@@ -14,9 +14,16 @@
 package health
 
 import (
+	"crypto/tls"
 	"encoding/json"
 	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
 	"os/exec"
+	"regexp"
+	"strconv"
 	"syscall"
 	"time"
 
@@ -40,6 +47,26 @@ const DefaultTimeout time.Duration = 30 * time.Second
 // updates.
 const DefaultExpiration time.Duration = time.Minute
 
+// Health check types
+const (
+	// ScriptCheck runs a script in a shell; the check passes if the script
+	// exits with 0.  This is the default if no type is given.
+	ScriptCheck = "script"
+	// HTTPCheck makes a GET request to a URL; the check passes if the
+	// response has the expected status and the body matches the regex.
+	HTTPCheck = "http"
+	// TCPCheck opens a connection to a port; the check passes if the
+	// connection is established.
+	TCPCheck = "tcp"
+)
+
+// DefaultHost is the host that tcp health checks connect to if none is given
+const DefaultHost = "localhost"
+
+// maxBodySize limits how much of an http response body is read to match the
+// body regex
+const maxBodySize int64 = 1 << 20
+
 // Status is the status of a health check
 type Status int
 
@@ -107,62 +134,119 @@ type HealthStatus struct {
 
 // HealthCheck is the health check object.
 type HealthCheck struct {
+	Type      string // script (default), http or tcp
 	Script    string
 	Timeout   time.Duration
 	Interval  time.Duration
 	Tolerance int
 	// Kill properties will kill the container if the observed error code is in the error code list
 	// and this happens <count>-times.  If the error codes list is empty and the kill count is >0, then any
-	// non-zero error code will count toward the kill count.
+	// non-zero error code will count toward the kill count.  For http checks, the error code is the status
+	// code of the response.
 	KillExitCodes  []int
 	KillCountLimit int
 	KillCounter    int
+	// http check properties
+	URL            string // URL to GET
+	ExpectedStatus int    // expected response status; any 2xx if 0
+	BodyRegex      string // regex that the response body must match
+	TLSSkipVerify  bool   // do not verify the server certificate for https URLs
+	// tcp check properties
+	Host string // host to connect to; DefaultHost if empty
+	Port int    // port to connect to
+}
+
+// jsonHealthCheck is the serialized form of a HealthCheck, with the timeout
+// and interval in seconds.
+type jsonHealthCheck struct {
+	Type           string `json:",omitempty"`
+	Script         string
+	Timeout        float64
+	Interval       float64
+	Tolerance      int
+	KillExitCodes  []int  `json:",omitempty"`
+	KillCountLimit int    `json:",omitempty"`
+	URL            string `json:",omitempty"`
+	ExpectedStatus int    `json:",omitempty"`
+	BodyRegex      string `json:",omitempty"`
+	TLSSkipVerify  bool   `json:",omitempty"`
+	Host           string `json:",omitempty"`
+	Port           int    `json:",omitempty"`
 }
 
 // MarshalJSON implements json.Marshaller
 func (hc HealthCheck) MarshalJSON() ([]byte, error) {
-	jhc := struct {
-		Script         string
-		Timeout        float64
-		Interval       float64
-		Tolerance      int
-		KillExitCodes  []int `json:",omitempty"`
-		KillCountLimit int   `json:",omitempty"`
-	}{
+	jhc := jsonHealthCheck{
+		Type:           hc.Type,
 		Script:         hc.Script,
 		Timeout:        hc.Timeout.Seconds(),
 		Interval:       hc.Interval.Seconds(),
 		Tolerance:      hc.Tolerance,
 		KillCountLimit: hc.KillCountLimit,
 		KillExitCodes:  hc.KillExitCodes,
+		URL:            hc.URL,
+		ExpectedStatus: hc.ExpectedStatus,
+		BodyRegex:      hc.BodyRegex,
+		TLSSkipVerify:  hc.TLSSkipVerify,
+		Host:           hc.Host,
+		Port:           hc.Port,
 	}
 	return json.Marshal(jhc)
 }
 
 // UnmarshalJSON implements json.Unmarshaller
 func (hc *HealthCheck) UnmarshalJSON(data []byte) error {
-	jhc := struct {
-		Script         string
-		Timeout        float64
-		Interval       float64
-		Tolerance      int
-		KillExitCodes  []int `json:",omitempty"`
-		KillCountLimit int   `json:",omitempty"`
-	}{}
+	jhc := jsonHealthCheck{}
 	if err := json.Unmarshal(data, &jhc); err != nil {
 		return err
 	}
 	*hc = HealthCheck{
+		Type:           jhc.Type,
 		Script:         jhc.Script,
 		Timeout:        time.Duration(jhc.Timeout) * time.Second,
 		Interval:       time.Duration(jhc.Interval) * time.Second,
 		Tolerance:      jhc.Tolerance,
 		KillCountLimit: jhc.KillCountLimit,
 		KillExitCodes:  jhc.KillExitCodes,
+		URL:            jhc.URL,
+		ExpectedStatus: jhc.ExpectedStatus,
+		BodyRegex:      jhc.BodyRegex,
+		TLSSkipVerify:  jhc.TLSSkipVerify,
+		Host:           jhc.Host,
+		Port:           jhc.Port,
 	}
 	return nil
 }
 
+// GetType returns the type of the health check
+func (hc *HealthCheck) GetType() string {
+	if hc.Type == "" {
+		return ScriptCheck
+	}
+	return hc.Type
+}
+
+// Target describes what the health check runs or connects to
+func (hc *HealthCheck) Target() string {
+	switch hc.GetType() {
+	case HTTPCheck:
+		return hc.URL
+	case TCPCheck:
+		return hc.address()
+	default:
+		return hc.Script
+	}
+}
+
+// address returns the host:port for a tcp health check
+func (hc *HealthCheck) address() string {
+	host := hc.Host
+	if host == "" {
+		host = DefaultHost
+	}
+	return net.JoinHostPort(host, strconv.Itoa(hc.Port))
+}
+
 // GetTimeout returns the timeout duration.
 func (hc *HealthCheck) GetTimeout() time.Duration {
 	timeout := hc.Timeout
@@ -199,15 +283,24 @@ func (hc *HealthCheck) Unknown() HealthStatus {
 	}
 }
 
-// Run returns the health status as a result of running the health check
-// script.
+// Run returns the health status as a result of running the health check.
 func (hc *HealthCheck) Run(key HealthStatusKey) (stat HealthStatus) {
-	logger := plog.WithFields(log.Fields{
-		"service":     key.ServiceID,
-		"instance":    key.InstanceID,
-		"healthcheck": key.HealthCheckName,
-	})
 	stat.StartedAt = time.Now()
+	switch hc.GetType() {
+	case HTTPCheck:
+		stat.Status = hc.runHTTP(key)
+	case TCPCheck:
+		stat.Status = hc.runTCP(key)
+	default:
+		stat.Status = hc.runScript(key)
+	}
+	stat.Duration = time.Since(stat.StartedAt)
+	return
+}
+
+// runScript runs the health check script in a shell.
+func (hc *HealthCheck) runScript(key HealthStatusKey) Status {
+	logger := hc.logger(key)
 	cmd := exec.Command("sh", "-c", hc.Script)
 	cmd.Start()
 	timer := time.NewTimer(hc.GetTimeout())
@@ -219,54 +312,153 @@ func (hc *HealthCheck) Run(key HealthStatusKey) (stat HealthStatus) {
 		if err != nil {
 			// If the command gives an error, the healthcheck status is Failed (curl command failed, connection
 			// refused, or any other error message including one that might contribute to the kill count)
-			stat.Status = Failed
-			if hc.KillCountLimit > 0 {
-				logger.Debug("Healthcheck has a KillCount.. checking the exit code")
-
-				if len(hc.KillExitCodes) == 0 {
-					// No error codes listed means any error code will count toward the health check kill count.
-					hc.KillCounter++
-					logger.Debugf("No KillExitCodes provided; KillCounter is now %d", hc.KillCounter)
-				} else if exitError, ok := err.(*exec.ExitError); ok {
-					// Get the exit code and compare to our kill code list.
-					ws := exitError.Sys().(syscall.WaitStatus)
-					exitcode := ws.ExitStatus()
-					logger.Debugf("Got exit code: %d", exitcode)
-					found := false
-					for i := range hc.KillExitCodes {
-						if hc.KillExitCodes[i] == exitcode {
-							hc.KillCounter++
-							logger.Infof("found matching exit code %d.. KillCounter is now %d", exitcode, hc.KillCounter)
-							found = true
-							break
-						}
-					}
-					if !found {
-						// This isn't in our list of kill codes; we don't care about any of the others.  Reset the count
-						logger.Debug("No matching exit code was found")
-						if hc.KillCounter > 0 {
-							logger.Infof("Resetting KillCounter. KillCounter was %d", hc.KillCounter)
-							hc.KillCounter = 0
-						}
-					}
-				} else {
-					logger.WithError(err).Warn("Unable to read the health check exit code")
-				}
+			if exitError, ok := err.(*exec.ExitError); ok {
+				// Get the exit code and compare to our kill code list.
+				ws := exitError.Sys().(syscall.WaitStatus)
+				hc.countFailure(logger, ws.ExitStatus(), true)
+			} else {
+				hc.countFailure(logger.WithError(err), 0, false)
 			}
-		} else {
-			if hc.KillCounter > 0 {
-				logger.Infof("Resetting KillCounter. KillCounter was %d", hc.KillCounter)
-				hc.KillCounter = 0
-			}
-			stat.Status = OK
+			return Failed
 		}
+		hc.resetFailures(logger)
+		return OK
 	case <-timer.C:
 		cmd.Process.Kill()
 		<-errC
-		stat.Status = Timeout
+		return Timeout
 	}
-	stat.Duration = time.Since(stat.StartedAt)
-	return
+}
+
+// runHTTP makes a GET request to the health check URL.
+func (hc *HealthCheck) runHTTP(key HealthStatusKey) Status {
+	logger := hc.logger(key).WithField("url", hc.URL)
+	client := &http.Client{
+		Timeout: hc.GetTimeout(),
+		Transport: &http.Transport{
+			Proxy:             http.ProxyFromEnvironment,
+			DisableKeepAlives: true,
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: hc.TLSSkipVerify},
+		},
+	}
+	resp, err := client.Get(hc.URL)
+	if err != nil {
+		if isTimeout(err) {
+			return Timeout
+		}
+		hc.countFailure(logger.WithError(err), 0, false)
+		return Failed
+	}
+	defer resp.Body.Close()
+
+	if !hc.expectedStatus(resp.StatusCode) {
+		logger.WithField("statuscode", resp.StatusCode).Debug("Unexpected response status")
+		io.Copy(ioutil.Discard, io.LimitReader(resp.Body, maxBodySize))
+		hc.countFailure(logger, resp.StatusCode, true)
+		return Failed
+	}
+
+	if hc.BodyRegex != "" {
+		body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+		if err != nil {
+			if isTimeout(err) {
+				return Timeout
+			}
+			hc.countFailure(logger.WithError(err), 0, false)
+			return Failed
+		}
+		re, err := regexp.Compile(hc.BodyRegex)
+		if err != nil {
+			hc.countFailure(logger.WithError(err), 0, false)
+			return Failed
+		}
+		if !re.Match(body) {
+			logger.WithField("bodyregex", hc.BodyRegex).Debug("Response body did not match")
+			hc.countFailure(logger, 0, false)
+			return Failed
+		}
+	}
+	hc.resetFailures(logger)
+	return OK
+}
+
+// expectedStatus returns true if the http status code passes the check
+func (hc *HealthCheck) expectedStatus(code int) bool {
+	if hc.ExpectedStatus == 0 {
+		return code >= 200 && code < 300
+	}
+	return code == hc.ExpectedStatus
+}
+
+// runTCP opens a connection to the health check port.
+func (hc *HealthCheck) runTCP(key HealthStatusKey) Status {
+	logger := hc.logger(key).WithField("address", hc.address())
+	conn, err := net.DialTimeout("tcp", hc.address(), hc.GetTimeout())
+	if err != nil {
+		if isTimeout(err) {
+			return Timeout
+		}
+		hc.countFailure(logger.WithError(err), 0, false)
+		return Failed
+	}
+	conn.Close()
+	hc.resetFailures(logger)
+	return OK
+}
+
+// logger returns a log entry for the health check instance
+func (hc *HealthCheck) logger(key HealthStatusKey) *log.Entry {
+	return plog.WithFields(log.Fields{
+		"service":     key.ServiceID,
+		"instance":    key.InstanceID,
+		"healthcheck": key.HealthCheckName,
+	})
+}
+
+// countFailure increments the kill counter for a failed check.  If the check
+// has kill exit codes, only failures with a matching code are counted and any
+// other code resets the counter.
+func (hc *HealthCheck) countFailure(logger *log.Entry, code int, hasCode bool) {
+	if hc.KillCountLimit <= 0 {
+		return
+	}
+	logger.Debug("Healthcheck has a KillCount.. checking the exit code")
+
+	if len(hc.KillExitCodes) == 0 {
+		// No error codes listed means any error code will count toward the health check kill count.
+		hc.KillCounter++
+		logger.Debugf("No KillExitCodes provided; KillCounter is now %d", hc.KillCounter)
+		return
+	}
+	if !hasCode {
+		logger.Warn("Unable to read the health check exit code")
+		return
+	}
+	logger.Debugf("Got exit code: %d", code)
+	for i := range hc.KillExitCodes {
+		if hc.KillExitCodes[i] == code {
+			hc.KillCounter++
+			logger.Infof("found matching exit code %d.. KillCounter is now %d", code, hc.KillCounter)
+			return
+		}
+	}
+	// This isn't in our list of kill codes; we don't care about any of the others.  Reset the count
+	logger.Debug("No matching exit code was found")
+	hc.resetFailures(logger)
+}
+
+// resetFailures resets the kill counter
+func (hc *HealthCheck) resetFailures(logger *log.Entry) {
+	if hc.KillCounter > 0 {
+		logger.Infof("Resetting KillCounter. KillCounter was %d", hc.KillCounter)
+		hc.KillCounter = 0
+	}
+}
+
+// isTimeout returns true if the error is a network timeout
+func isTimeout(err error) bool {
+	netErr, ok := err.(net.Error)
+	return ok && netErr.Timeout()
 }
 
 // Ping performs the health check on the specified interval.
@@ -17,6 +17,10 @@ package health_test
 
 import (
 	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"time"
 
 	. "github.com/control-center/serviced/health"
@@ -413,3 +417,130 @@ func (s *HealthCheckTestSuite) TestKillScript_KillCountResetsOnOtherErrors(c *C)
 		}
 	})
 }
+
+func (s *HealthCheckTestSuite) TestMarshalJSON_HTTP(c *C) {
+	// Verify http properties survive a round trip
+	check := HealthCheck{
+		Type:           HTTPCheck,
+		URL:            "https://localhost:8443/ping",
+		ExpectedStatus: 204,
+		BodyRegex:      "^ok$",
+		TLSSkipVerify:  true,
+		Timeout:        time.Second,
+		Interval:       5 * time.Second,
+	}
+	data, err := json.Marshal(&check)
+	c.Assert(err, IsNil)
+	var actual HealthCheck
+	err = json.Unmarshal(data, &actual)
+	c.Assert(err, IsNil)
+	c.Check(actual, DeepEquals, check)
+}
+
+func (s *HealthCheckTestSuite) TestRunHTTP_Passed(c *C) {
+	// Verify a passing http health check
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "status: ok")
+	}))
+	defer server.Close()
+	check := HealthCheck{
+		Type:      HTTPCheck,
+		URL:       server.URL,
+		BodyRegex: "status: ok",
+		Timeout:   time.Second,
+		Interval:  time.Second,
+	}
+	stat := check.Run(hcKey)
+	c.Check(stat.Status, Equals, OK)
+}
+
+func (s *HealthCheckTestSuite) TestRunHTTP_Failed(c *C) {
+	// Verify http checks fail on an unexpected status or body
+	code := http.StatusServiceUnavailable
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(code)
+		fmt.Fprint(w, "status: starting")
+	}))
+	defer server.Close()
+	check := HealthCheck{
+		Type:     HTTPCheck,
+		URL:      server.URL,
+		Timeout:  time.Second,
+		Interval: time.Second,
+	}
+	stat := check.Run(hcKey)
+	c.Check(int(stat.Status), Equals, Failed)
+
+	// expected status matches, but the body does not
+	check.ExpectedStatus = http.StatusServiceUnavailable
+	stat = check.Run(hcKey)
+	c.Check(stat.Status, Equals, OK)
+	check.BodyRegex = "status: ok"
+	stat = check.Run(hcKey)
+	c.Check(int(stat.Status), Equals, Failed)
+}
+
+func (s *HealthCheckTestSuite) TestRunHTTP_Timeout(c *C) {
+	// Verify a timed out http health check
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer server.Close()
+	defer close(done)
+	check := HealthCheck{
+		Type:     HTTPCheck,
+		URL:      server.URL,
+		Timeout:  250 * time.Millisecond,
+		Interval: time.Second,
+	}
+	stat := check.Run(hcKey)
+	c.Check(int(stat.Status), Equals, Timeout)
+	c.Check(stat.Duration < 5*time.Second, Equals, true)
+}
+
+func (s *HealthCheckTestSuite) TestRunHTTP_KillStatusCode(c *C) {
+	// Verify the response status code counts toward the kill count
+	code := http.StatusInternalServerError
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(code)
+	}))
+	defer server.Close()
+	hc := HealthCheck{
+		Type:           HTTPCheck,
+		URL:            server.URL,
+		Timeout:        time.Second,
+		Interval:       time.Second,
+		KillExitCodes:  []int{http.StatusInternalServerError},
+		KillCountLimit: 3,
+	}
+	hc.Run(hcKey)
+	hc.Run(hcKey)
+	c.Check(hc.KillCounter, Equals, 2)
+
+	// another failing status resets the count
+	code = http.StatusNotFound
+	hc.Run(hcKey)
+	c.Check(hc.KillCounter, Equals, 0)
+}
+
+func (s *HealthCheckTestSuite) TestRunTCP(c *C) {
+	// Verify a tcp health check passes while the port is listening
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, IsNil)
+	port := listener.Addr().(*net.TCPAddr).Port
+	check := HealthCheck{
+		Type:     TCPCheck,
+		Host:     "127.0.0.1",
+		Port:     port,
+		Timeout:  time.Second,
+		Interval: time.Second,
+	}
+	stat := check.Run(hcKey)
+	c.Check(stat.Status, Equals, OK)
+
+	// and fails once it is closed
+	listener.Close()
+	stat = check.Run(hcKey)
+	c.Check(int(stat.Status), Equals, Failed)
+}
@@ -15,6 +15,10 @@ package health
 
 import (
 	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
 	"github.com/control-center/serviced/validation"
 )
 
@@ -24,6 +28,33 @@ func (hc HealthCheck) ValidEntity() error {
 		violations.Add(fmt.Errorf("the KillCountLimit must be set if KillExitCodes are specified"))
 	}
 
+	switch hc.GetType() {
+	case ScriptCheck:
+	case HTTPCheck:
+		if hc.URL == "" {
+			violations.Add(fmt.Errorf("the URL must be set for http health checks"))
+		} else if !strings.Contains(hc.URL, "{{") {
+			// templated urls are checked when they are evaluated
+			if u, err := url.Parse(hc.URL); err != nil {
+				violations.Add(fmt.Errorf("invalid health check URL %s: %s", hc.URL, err))
+			} else if u.Scheme != "http" && u.Scheme != "https" {
+				violations.Add(fmt.Errorf("health check URL %s must use http or https", hc.URL))
+			}
+		}
+		if hc.ExpectedStatus != 0 && (hc.ExpectedStatus < 100 || hc.ExpectedStatus > 599) {
+			violations.Add(fmt.Errorf("invalid ExpectedStatus %d", hc.ExpectedStatus))
+		}
+		if _, err := regexp.Compile(hc.BodyRegex); err != nil {
+			violations.Add(fmt.Errorf("invalid BodyRegex %s: %s", hc.BodyRegex, err))
+		}
+	case TCPCheck:
+		if err := validation.ValidPort(hc.Port); err != nil {
+			violations.Add(err)
+		}
+	default:
+		violations.Add(fmt.Errorf("invalid health check type %s", hc.Type))
+	}
+
 	if violations.HasError() {
 		return violations
 	}
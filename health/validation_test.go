@@ -37,3 +37,35 @@ func (vs *ValidationSuite) Test_Validation_Invalid_HealthCheck(c *C) {
 	err = hc.ValidEntity()
 	c.Assert(err, IsNil)
 }
+
+func (vs *ValidationSuite) Test_Validation_HTTP_HealthCheck(c *C) {
+	hc := HealthCheck{Type: HTTPCheck}
+	c.Assert(hc.ValidEntity(), NotNil)
+
+	hc.URL = "ftp://localhost/ping"
+	c.Assert(hc.ValidEntity(), NotNil)
+
+	hc.URL = "http://localhost:8080/ping"
+	c.Assert(hc.ValidEntity(), IsNil)
+
+	// templated urls are checked after evaluation
+	hc.URL = "{{(context).url}}"
+	c.Assert(hc.ValidEntity(), IsNil)
+
+	hc.BodyRegex = "ok("
+	c.Assert(hc.ValidEntity(), NotNil)
+	hc.BodyRegex = "ok"
+	hc.ExpectedStatus = 1000
+	c.Assert(hc.ValidEntity(), NotNil)
+}
+
+func (vs *ValidationSuite) Test_Validation_TCP_HealthCheck(c *C) {
+	hc := HealthCheck{Type: TCPCheck}
+	c.Assert(hc.ValidEntity(), NotNil)
+
+	hc.Port = 8080
+	c.Assert(hc.ValidEntity(), IsNil)
+
+	hc.Type = "udp"
+	c.Assert(hc.ValidEntity(), NotNil)
+}
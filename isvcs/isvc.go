@@ -77,11 +77,17 @@ func Init(esStartupTimeoutInSeconds int, dockerLogDriver string, dockerLogConfig
 
 	Mgr = NewManager(utils.LocalDir("images"), utils.TempDir("var/isvcs"), dockerLogDriver, dockerLogConfig)
 
-	elasticsearch_serviced.docker = dockerAPI
-	if err := Mgr.Register(elasticsearch_serviced); err != nil {
-		log.WithFields(logrus.Fields{
-			"isvc": "elasticsearch-serviced",
-		}).WithError(err).Fatal("Unable to register internal service")
+	// The embedded datastore replaces the elasticsearch that holds the
+	// control plane entities.
+	if config.GetOptions().DatastoreDriver != config.DatastoreEmbedded {
+		elasticsearch_serviced.docker = dockerAPI
+		if err := Mgr.Register(elasticsearch_serviced); err != nil {
+			log.WithFields(logrus.Fields{
+				"isvc": "elasticsearch-serviced",
+			}).WithError(err).Fatal("Unable to register internal service")
+		}
+	} else {
+		log.WithFields(logrus.Fields{"isvc": "elasticsearch-serviced"}).Debug("NOT starting service per config.")
 	}
 	elasticsearch_logstash.docker = dockerAPI
 	if err := Mgr.Register(elasticsearch_logstash); err != nil {
@@ -0,0 +1,54 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"fmt"
+	"time"
+)
+
+// GetMetricSeries returns the series of each metric over the window ending
+// now, filtered by the tags and downsampled to the resolution with the
+// aggregator.
+func (c *Client) GetMetricSeries(window, resolution time.Duration, aggregator string, tags map[string][]string, metrics ...string) (map[string]MetricSeries, error) {
+	log.WithField("metrics", metrics).Debug("Requesting metric series")
+
+	options := PerformanceOptions{
+		Start:     time.Now().UTC().Add(-window).Format(timeFormat),
+		End:       "now",
+		Returnset: "exact",
+		Tags:      tags,
+	}
+	if seconds := int(resolution.Seconds()); seconds > 0 {
+		options.Downsample = fmt.Sprintf("%ds-%s", seconds, aggregator)
+	}
+	for _, metric := range metrics {
+		options.Metrics = append(options.Metrics, MetricOptions{
+			Metric:     metric,
+			Name:       metric,
+			Aggregator: aggregator,
+			Tags:       tags,
+		})
+	}
+	data, err := c.performanceQuery(options)
+	if err != nil {
+		log.WithError(err).WithField("options", options).Debug("Metric series query failed")
+		return nil, err
+	}
+	series := make(map[string]MetricSeries)
+	for _, result := range data.Results {
+		series[result.Metric] = DatapointsToSeries(result.Datapoints)
+	}
+	return series, nil
+}
@@ -35,6 +35,7 @@ import (
 	"github.com/control-center/serviced/commons/docker"
 	"github.com/control-center/serviced/commons/iptables"
 	coordclient "github.com/control-center/serviced/coordinator/client"
+	coordetcd "github.com/control-center/serviced/coordinator/client/etcd"
 	coordzk "github.com/control-center/serviced/coordinator/client/zookeeper"
 	"github.com/control-center/serviced/dfs/registry"
 	"github.com/control-center/serviced/domain/addressassignment"
@@ -80,6 +81,7 @@ type HostAgent struct {
 	useTLS               bool   // true if TLS should be enabled for MUX
 	proxyRegistry        proxy.ProxyRegistry
 	zkClient             *coordclient.Client
+	coordinatorDriver    string          // driver of zkClient, either zookeeper or etcd
 	maxContainerAge      time.Duration   // maximum age for a stopped container before it is removed
 	virtualAddressSubnet string          // subnet for virtual addresses
 	servicedChain        *iptables.Chain // Assigned IP rule chain
@@ -143,6 +145,8 @@ type AgentOptions struct {
 	ZKPerHostConnectDelay int
 	ZKReconnectStartDelay int
 	ZKReconnectMaxDelay  int
+	CoordinatorDriver    string   // either zookeeper or etcd; defaults to zookeeper
+	EtcdEndpoints        []string // client URLs of the etcd cluster
 	DelegateKeyFile      string
 	TokenFile            string
 	ConntrackFlush       bool
@@ -177,13 +181,22 @@ func NewHostAgent(options AgentOptions, reg registry.Registry) (*HostAgent, erro
 	agent.serviceCache = NewServiceCache(options.Master)
 
 	var err error
-	dsn := getZkDSN(options.Zookeepers,
-		agent.zkSessionTimeout,
-		options.ZKConnectTimeout,
-		options.ZKPerHostConnectDelay,
-		options.ZKReconnectStartDelay,
-		options.ZKReconnectMaxDelay)
-	if agent.zkClient, err = coordclient.New("zookeeper", dsn, "", nil); err != nil {
+	var dsn string
+	agent.coordinatorDriver = options.CoordinatorDriver
+	if agent.coordinatorDriver == "etcd" {
+		dsn = coordetcd.NewDSN(options.EtcdEndpoints,
+			time.Duration(agent.zkSessionTimeout)*time.Second,
+			time.Duration(options.ZKConnectTimeout)*time.Second).String()
+	} else {
+		agent.coordinatorDriver = "zookeeper"
+		dsn = getZkDSN(options.Zookeepers,
+			agent.zkSessionTimeout,
+			options.ZKConnectTimeout,
+			options.ZKPerHostConnectDelay,
+			options.ZKReconnectStartDelay,
+			options.ZKReconnectMaxDelay)
+	}
+	if agent.zkClient, err = coordclient.New(agent.coordinatorDriver, dsn, "", nil); err != nil {
 		return nil, err
 	}
 	if agent.storage, err = volume.GetDriver(options.VolumesPath); err != nil {
@@ -47,6 +47,7 @@ type ServiceLogInfo struct {
 type ZkInfo struct {
 	ZkDSN  string
 	PoolID string
+	Driver string // coordinator driver of ZkDSN; empty for zookeeper
 }
 
 func (a *HostAgent) SendLogMessage(serviceLogInfo ServiceLogInfo, _ *struct{}) (err error) {
@@ -88,6 +89,39 @@ func (a *HostAgent) GetEvaluatedService(request EvaluateServiceRequest, response
 	return nil
 }
 
+// ErrNotThisHost is returned when a request that only containers on this host
+// may make is sent by another host
+var ErrNotThisHost = errors.New("request was not sent by this host")
+
+// GetServiceSecrets proxies GetServiceSecrets to the master server.  Only
+// containers on this host may ask for secrets, and the master checks that the
+// instance runs on this host.
+func (a *HostAgent) GetServiceSecrets(request master.ServiceSecretsRequest, response *map[string]string) error {
+	logger := plog.WithFields(log.Fields{
+		"serviceID":  request.ServiceID,
+		"instanceID": request.InstanceID,
+	})
+
+	if request.HostID() != a.hostID {
+		logger.WithField("senderhostid", request.HostID()).Warn("Another host asked for the secrets of a service instance")
+		return ErrNotThisHost
+	}
+
+	masterClient, err := master.NewClient(a.master)
+	if err != nil {
+		logger.WithError(err).Error("Could not start Control Center client")
+		return err
+	}
+	defer masterClient.Close()
+	values, err := masterClient.GetServiceSecrets(request.ServiceID, request.InstanceID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get service secrets")
+		return err
+	}
+	*response = values
+	return nil
+}
+
 // GetProxySnapshotQuiece blocks until there is a snapshot request to the service
 func (a *HostAgent) GetProxySnapshotQuiece(serviceId string, snapshotId *string) error {
 	glog.Errorf("GetProxySnapshotQuiece() Unimplemented")
@@ -123,6 +157,20 @@ func (a *HostAgent) ReportInstanceDead(req master.ServiceInstanceRequest, unused
 	return masterClient.ReportInstanceDead(req.ServiceID, req.InstanceID)
 }
 
+// ControlPlanePorts returns the ports of the control center endpoints that
+// services reach through the mux on the master: the ui port, the consumer,
+// logstash and kibana.
+func ControlPlanePorts(uiport string) []uint16 {
+	ports := []uint16{8443, 5042, 5043, 5601}
+	parts := strings.Split(uiport, ":")
+	if port, err := strconv.Atoi(parts[len(parts)-1]); err == nil {
+		ports = append(ports, uint16(port))
+	} else {
+		glog.Errorf("Unable to interpret ui port.")
+	}
+	return ports
+}
+
 // addControlPlaneEndpoint adds an application endpoint mapping for the master control center api
 func (a *HostAgent) addControlPlaneEndpoint(endpoints map[string][]applicationendpoint.ApplicationEndpoint) {
 	key := "tcp" + a.uiport
@@ -236,6 +284,7 @@ func (a *HostAgent) GetZkInfo(_ string, zkInfo *ZkInfo) error {
 	localDSN := a.zkClient.ConnectionString()
 	zkInfo.ZkDSN = strings.Replace(localDSN, "127.0.0.1", strings.Split(a.master, ":")[0], -1)
 	zkInfo.PoolID = a.poolID
+	zkInfo.Driver = a.coordinatorDriver
 	glog.V(4).Infof("ControlCenterAgent.GetZkInfo(): %+v", zkInfo)
 	return nil
 }
@@ -23,6 +23,7 @@ package node
 
 import (
 	"github.com/control-center/serviced/domain/applicationendpoint"
+	"github.com/control-center/serviced/rpc/master"
 
 	"fmt"
 	"testing"
@@ -85,3 +86,30 @@ func TestAddControlPlaneEndpoints(t *testing.T) {
 		t.Fatalf(" mapping failed %+v expected %+v", endpoints[ccuiport][0], controlplane_endpoint)
 	}
 }
+
+func TestGetServiceSecretsOtherHost(t *testing.T) {
+	agent := &HostAgent{hostID: "hostid"}
+
+	request := master.ServiceSecretsRequest{ServiceID: "serviceid", InstanceID: 0}
+	request.SetHostID("otherhostid")
+	values := make(map[string]string)
+	if err := agent.GetServiceSecrets(request, &values); err != ErrNotThisHost {
+		t.Errorf("Expected %s, got %v", ErrNotThisHost, err)
+	}
+	if len(values) > 0 {
+		t.Errorf("Expected no secrets, got %v", values)
+	}
+}
+
+func TestControlPlanePorts(t *testing.T) {
+	ports := ControlPlanePorts(":443")
+	expected := map[uint16]bool{443: true, 8443: true, 5042: true, 5043: true, 5601: true}
+	if len(ports) != len(expected) {
+		t.Fatalf("Expected %d ports, got %v", len(expected), ports)
+	}
+	for _, port := range ports {
+		if !expected[port] {
+			t.Errorf("Unexpected port %d", port)
+		}
+	}
+}
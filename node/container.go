@@ -790,6 +790,12 @@ func (a *HostAgent) createContainerConfig(tenantID string, svc *service.Service,
 	if svc.Privileged {
 		hcfg.Privileged = true
 	}
+	if svc.SecurityContext != nil {
+		if err := applySecurityContext(svc.SecurityContext, tmpVolumes, filepath.Dir(a.delegateKeyFile), cfg, hcfg); err != nil {
+			logger.WithError(err).Error("Could not apply the security context of the service")
+			return nil, nil, nil, err
+		}
+	}
 
 	// Memory and CpuShares should never be negative
 	if svc.MemoryLimit < 0 {
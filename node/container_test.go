@@ -16,12 +16,16 @@
 package node
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
 	regmocks "github.com/control-center/serviced/dfs/registry/mocks"
 	"github.com/control-center/serviced/domain/service"
+	"github.com/control-center/serviced/domain/servicedefinition"
 )
 
 func TestSetupContainer_DockerLog(t *testing.T) {
@@ -61,3 +65,102 @@ func TestSetupContainer_DockerLog(t *testing.T) {
 	assert.Equal(hcfg.LogConfig.Config["bravo"], "two")
 	assert.Equal(hcfg.LogConfig.Config["charlie"], "three")
 }
+
+func TestSetupContainer_SecurityContext(t *testing.T) {
+	assert := assert.New(t)
+
+	fakeHostAgent := &HostAgent{
+		uiport:               ":443",
+		virtualAddressSubnet: "0.0.0.0",
+		pullreg:              &regmocks.Registry{},
+	}
+
+	fakeService := &service.Service{
+		ImageID: "busybox:latest",
+		ID:      "faketestService",
+		Name:    "fakeTestServiceName",
+		Volumes: []servicedefinition.Volume{
+			{Type: "tmp", ContainerPath: "/var/run/app"},
+		},
+	}
+
+	// without a security context the container runs as root
+	cfg, hcfg, _, err := fakeHostAgent.createContainerConfig("unused", fakeService, 0, "unused")
+	assert.Nil(err)
+	assert.Equal("root", cfg.User)
+	assert.False(hcfg.ReadonlyRootfs)
+	assert.Empty(hcfg.SecurityOpt)
+
+	fakeService.SecurityContext = &servicedefinition.SecurityContext{
+		RunAsUser:              "app",
+		RunAsGroup:             "1000",
+		CapAdd:                 []string{"cap_net_bind_service"},
+		CapDrop:                []string{"ALL"},
+		ReadOnlyRootFilesystem: true,
+		WritablePaths:          []string{"/opt/app/data"},
+		NoNewPrivileges:        true,
+		SeccompProfile:         servicedefinition.Unconfined,
+		AppArmorProfile:        "docker-app",
+	}
+	cfg, hcfg, _, err = fakeHostAgent.createContainerConfig("unused", fakeService, 0, "unused")
+	assert.Nil(err)
+	assert.Equal("app:1000", cfg.User)
+	assert.Equal([]string{"NET_BIND_SERVICE"}, hcfg.CapAdd)
+	assert.Equal([]string{"ALL"}, hcfg.CapDrop)
+	assert.True(hcfg.ReadonlyRootfs)
+	assert.Equal(map[string]struct{}{
+		"/tmp":           {},
+		"/var/run/app":   {},
+		"/etc/profile.d": {},
+		"/opt/app/data":  {},
+	}, cfg.Volumes)
+	assert.Equal([]string{"no-new-privileges", "seccomp=unconfined", "apparmor=docker-app"}, hcfg.SecurityOpt)
+
+	// seccomp profiles are read by name from the agent's etc path
+	etcPath, err := ioutil.TempDir("", "serviced-etc-")
+	assert.Nil(err)
+	defer os.RemoveAll(etcPath)
+	fakeHostAgent.delegateKeyFile = filepath.Join(etcPath, "delegate.keys")
+	assert.Nil(os.MkdirAll(filepath.Join(etcPath, "seccomp"), 0755))
+	assert.Nil(ioutil.WriteFile(filepath.Join(etcPath, "seccomp", "app.json"), []byte(`{"defaultAction":"SCMP_ACT_ERRNO"}`), 0644))
+	fakeService.SecurityContext.SeccompProfile = "app"
+	_, hcfg, _, err = fakeHostAgent.createContainerConfig("unused", fakeService, 0, "unused")
+	assert.Nil(err)
+	assert.Contains(hcfg.SecurityOpt, `seccomp={"defaultAction":"SCMP_ACT_ERRNO"}`)
+
+	// a seccomp profile that cannot be read fails the container
+	fakeService.SecurityContext.SeccompProfile = "missing"
+	_, _, _, err = fakeHostAgent.createContainerConfig("unused", fakeService, 0, "unused")
+	assert.NotNil(err)
+}
+
+func TestSetupContainer_Secrets(t *testing.T) {
+	assert := assert.New(t)
+
+	fakeHostAgent := &HostAgent{
+		uiport:               ":443",
+		virtualAddressSubnet: "0.0.0.0",
+		pullreg:              &regmocks.Registry{},
+	}
+
+	fakeService := &service.Service{
+		ImageID: "busybox:latest",
+		ID:      "faketestService",
+		Name:    "fakeTestServiceName",
+		Secrets: []servicedefinition.SecretRef{
+			{Name: "db-password", Env: "DB_PASSWORD"},
+			{Name: "api-token", File: "token"},
+		},
+	}
+
+	// secrets are delivered by the controller, so they are never set in the
+	// container's configuration
+	cfg, hcfg, _, err := fakeHostAgent.createContainerConfig("unused", fakeService, 0, "unused")
+	assert.Nil(err)
+	for _, env := range cfg.Env {
+		assert.NotContains(env, "DB_PASSWORD")
+	}
+	for _, bind := range hcfg.Binds {
+		assert.NotContains(bind, servicedefinition.SecretsPath)
+	}
+}
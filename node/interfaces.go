@@ -132,6 +132,9 @@ type LoadBalancer interface {
 	// GetEvaluatedService returns a service where an evaluation has been executed against all templated properties.
 	GetEvaluatedService(request EvaluateServiceRequest, response *EvaluateServiceResponse) error
 
+	// GetServiceSecrets returns the values of the secrets that are delivered to a service instance on this host.
+	GetServiceSecrets(request master.ServiceSecretsRequest, response *map[string]string) error
+
 	// Ping waits for the specified time then returns the server time
 	Ping(waitFor time.Duration, timestamp *time.Time) error
 }
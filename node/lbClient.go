@@ -71,6 +71,13 @@ func (a *LBClient) GetEvaluatedService(request EvaluateServiceRequest, response
 	return a.rpcClient.Call("ControlCenterAgent.GetEvaluatedService", request, response, 0)
 }
 
+// GetServiceSecrets returns the values of the secrets that are delivered to a
+// service instance on this host.
+func (a *LBClient) GetServiceSecrets(request master.ServiceSecretsRequest, response *map[string]string) error {
+	glog.V(4).Infof("ControlCenterAgent.GetServiceSecrets()")
+	return a.rpcClient.Call("ControlCenterAgent.GetServiceSecrets", request, response, 0)
+}
+
 // GetProxySnapshotQuiece blocks until there is a snapshot request to the service
 func (a *LBClient) GetProxySnapshotQuiece(serviceId string, snapshotId *string) error {
 	glog.V(4).Infof("ControlCenterAgent.GetProxySnapshotQuiece()")
@@ -0,0 +1,76 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package node
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/control-center/serviced/domain/servicedefinition"
+	dockerclient "github.com/fsouza/go-dockerclient"
+)
+
+// controllerWritablePaths are the container paths that the controller writes
+// to before it starts the service
+var controllerWritablePaths = []string{"/etc/profile.d"}
+
+// seccompProfileDir is the directory in serviced's etc path that holds the
+// seccomp profiles services may use
+const seccompProfileDir = "seccomp"
+
+// applySecurityContext restricts a container to the security context of its
+// service.  If the root filesystem is read-only, the container's temporary
+// paths, the paths written by the controller and the context's writable
+// paths are created as volumes.  Seccomp profiles are read from the profile
+// directory of etcPath.
+func applySecurityContext(sc *servicedefinition.SecurityContext, tmpPaths []string, etcPath string, cfg *dockerclient.Config, hcfg *dockerclient.HostConfig) error {
+	if user := sc.User(); user != "" {
+		cfg.User = user
+	}
+	hcfg.CapAdd, hcfg.CapDrop = sc.Capabilities()
+
+	if sc.ReadOnlyRootFilesystem {
+		hcfg.ReadonlyRootfs = true
+		if cfg.Volumes == nil {
+			cfg.Volumes = make(map[string]struct{})
+		}
+		for _, paths := range [][]string{tmpPaths, controllerWritablePaths, sc.WritablePaths} {
+			for _, p := range paths {
+				cfg.Volumes[p] = struct{}{}
+			}
+		}
+	}
+
+	if sc.NoNewPrivileges {
+		hcfg.SecurityOpt = append(hcfg.SecurityOpt, "no-new-privileges")
+	}
+	switch sc.SeccompProfile {
+	case "":
+	case servicedefinition.Unconfined:
+		hcfg.SecurityOpt = append(hcfg.SecurityOpt, "seccomp=unconfined")
+	default:
+		// docker expects the profile itself, not the path to it
+		filename := filepath.Join(etcPath, seccompProfileDir, sc.SeccompProfile+".json")
+		profile, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return fmt.Errorf("could not read seccomp profile %s: %s", sc.SeccompProfile, err)
+		}
+		hcfg.SecurityOpt = append(hcfg.SecurityOpt, "seccomp="+string(profile))
+	}
+	if sc.AppArmorProfile != "" {
+		hcfg.SecurityOpt = append(hcfg.SecurityOpt, "apparmor="+sc.AppArmorProfile)
+	}
+	return nil
+}
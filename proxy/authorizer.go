@@ -0,0 +1,253 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/control-center/serviced/auth"
+	"github.com/control-center/serviced/coordinator/client"
+	"github.com/control-center/serviced/zzk/registry"
+	zkservice "github.com/control-center/serviced/zzk/service"
+)
+
+var (
+	// ErrMuxNoIdentity is returned when a mux connection has no sender
+	ErrMuxNoIdentity = errors.New("mux connection has no sender identity")
+	// ErrMuxNotExported is returned when the address of a mux connection is
+	// not exported by the sender's tenant
+	ErrMuxNotExported = errors.New("address is not exported by the tenant")
+	// ErrMuxTenantNotInPool is returned when the sender of a mux connection
+	// names a tenant that has no services in the sender's pool
+	ErrMuxTenantNotInPool = errors.New("tenant is not in the sender's pool")
+)
+
+const (
+	// exportCacheTTL is how long the exports of a tenant are cached
+	exportCacheTTL = 30 * time.Second
+	// exportRefreshInterval is how often the exports of a tenant may be
+	// reloaded to look for an address that is not cached
+	exportRefreshInterval = time.Second
+)
+
+// MuxAuthorizer decides whether the sender of a mux connection may connect to
+// the requested address.
+type MuxAuthorizer interface {
+	Authorize(ident auth.Identity, tenantID, address string) error
+}
+
+// ExportAuthorizer allows a mux connection to a container only if the address
+// is exported by the tenant of the sender.  The tenant in the mux header is
+// chosen by the sender, so it is only trusted if any service of the tenant
+// runs in the pool of the sender's host, or if the sender has admin access,
+// as the master does when it proxies public endpoints and virtual hosts.
+// Senders that are too old to name a tenant are allowed if the address is
+// exported by any tenant that they could have named.  Addresses on the mux's
+// own host are only allowed on the ports of the control center endpoints
+// that every service imports.
+type ExportAuthorizer struct {
+	getConnection func() (client.Connection, error)
+	isHostAddress func(ip net.IP) bool
+	hostPorts     map[uint16]struct{}
+	now           func() time.Time
+
+	mu      *sync.Mutex
+	exports map[string]*tenantExports
+}
+
+// tenantExports are the cached export addresses of a tenant, and whether the
+// tenant runs in the pools that have been checked so far
+type tenantExports struct {
+	addresses map[string]struct{}
+	pools     map[string]bool
+	loaded    time.Time
+}
+
+// NewExportAuthorizer returns a new authorizer that looks up exports using
+// connections to the coordinator from getConnection, and that allows
+// connections to this host on hostPorts.
+func NewExportAuthorizer(getConnection func() (client.Connection, error), hostPorts []uint16) *ExportAuthorizer {
+	a := &ExportAuthorizer{
+		getConnection: getConnection,
+		isHostAddress: isHostAddress,
+		hostPorts:     make(map[uint16]struct{}),
+		now:           time.Now,
+		mu:            &sync.Mutex{},
+		exports:       make(map[string]*tenantExports),
+	}
+	for _, port := range hostPorts {
+		a.hostPorts[port] = struct{}{}
+	}
+	return a
+}
+
+// Authorize implements MuxAuthorizer
+func (a *ExportAuthorizer) Authorize(ident auth.Identity, tenantID, address string) error {
+	if ident == nil {
+		return ErrMuxNoIdentity
+	}
+
+	host, portstr, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip != nil && a.isHostAddress(ip) {
+		if port, err := strconv.ParseUint(portstr, 10, 16); err == nil {
+			if _, ok := a.hostPorts[uint16(port)]; ok {
+				return nil
+			}
+		}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if tenantID == "" {
+		return a.authorizeAnyTenant(ident, address)
+	}
+
+	cached, err := a.lookupExports(tenantID, address)
+	if err != nil {
+		return err
+	}
+	if err := a.checkPool(cached, tenantID, ident); err != nil {
+		return err
+	}
+	if _, ok := cached.addresses[address]; !ok {
+		return ErrMuxNotExported
+	}
+	return nil
+}
+
+// authorizeAnyTenant allows a sender that does not name a tenant to connect
+// to an address exported by any tenant that runs in the sender's pool
+func (a *ExportAuthorizer) authorizeAnyTenant(ident auth.Identity, address string) error {
+	conn, err := a.getConnection()
+	if err != nil {
+		return err
+	}
+	tenantIDs, err := conn.Children("/net/export")
+	if err == client.ErrNoNode {
+		return ErrMuxNotExported
+	} else if err != nil {
+		return err
+	}
+
+	err = ErrMuxNotExported
+	for _, tenantID := range tenantIDs {
+		cached, lerr := a.lookupExports(tenantID, address)
+		if lerr != nil {
+			return lerr
+		}
+		if _, ok := cached.addresses[address]; !ok {
+			continue
+		}
+		if err = a.checkPool(cached, tenantID, ident); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// lookupExports returns the cached exports of a tenant.  They are reloaded
+// when the cache expires, or when the address is not cached, but not more
+// than once a second for addresses that aren't exported.
+func (a *ExportAuthorizer) lookupExports(tenantID, address string) (*tenantExports, error) {
+	now := a.now()
+	cached, ok := a.exports[tenantID]
+	if ok && now.Sub(cached.loaded) < exportCacheTTL {
+		if _, ok := cached.addresses[address]; ok || now.Sub(cached.loaded) < exportRefreshInterval {
+			return cached, nil
+		}
+	}
+
+	cached, err := a.loadExports(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	cached.loaded = now
+	a.exports[tenantID] = cached
+	return cached, nil
+}
+
+// checkPool verifies that the sender has admin access or that any service of
+// the tenant runs in the pool of the sender
+func (a *ExportAuthorizer) checkPool(cached *tenantExports, tenantID string, ident auth.Identity) error {
+	if ident.HasAdminAccess() {
+		return nil
+	}
+	poolID := ident.PoolID()
+	if poolID == "" {
+		return ErrMuxTenantNotInPool
+	}
+	inPool, ok := cached.pools[poolID]
+	if !ok {
+		conn, err := a.getConnection()
+		if err != nil {
+			return err
+		}
+		if inPool, err = zkservice.PoolHasTenant(conn, poolID, tenantID); err != nil {
+			return err
+		}
+		cached.pools[poolID] = inPool
+	}
+	if !inPool {
+		return ErrMuxTenantNotInPool
+	}
+	return nil
+}
+
+// loadExports looks up the export addresses of a tenant
+func (a *ExportAuthorizer) loadExports(tenantID string) (*tenantExports, error) {
+	conn, err := a.getConnection()
+	if err != nil {
+		return nil, err
+	}
+	exports, err := registry.GetExports(conn, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	cached := &tenantExports{
+		addresses: make(map[string]struct{}),
+		pools:     make(map[string]bool),
+	}
+	for _, export := range exports {
+		cached.addresses[fmt.Sprintf("%s:%d", export.PrivateIP, export.PortNumber)] = struct{}{}
+	}
+	return cached, nil
+}
+
+// isHostAddress returns true if the ip is a loopback address or belongs to
+// one of the interfaces of this host.
+func isHostAddress(ip net.IP) bool {
+	if ip.IsLoopback() {
+		return true
+	}
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		log.WithError(err).Warn("Could not look up the addresses of this host")
+		return false
+	}
+	for _, addr := range addrs {
+		if ipnet, ok := addr.(*net.IPNet); ok && ipnet.IP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,240 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build unit
+
+package proxy
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	authmocks "github.com/control-center/serviced/auth/mocks"
+	"github.com/control-center/serviced/coordinator/client"
+	"github.com/control-center/serviced/zzk/registry"
+	"github.com/control-center/serviced/zzk/service"
+)
+
+// fakeConnection is a coordinator connection that serves exports and the
+// services in each pool
+type fakeConnection struct {
+	client.Connection
+	children map[string][]string
+	exports  map[string]registry.ExportDetails
+	services map[string]service.ServiceNode
+	gets     int
+}
+
+func (conn *fakeConnection) Children(p string) ([]string, error) {
+	ch, ok := conn.children[p]
+	if !ok {
+		return nil, client.ErrNoNode
+	}
+	return ch, nil
+}
+
+func (conn *fakeConnection) Get(p string, node client.Node) error {
+	if svc, ok := conn.services[p]; ok {
+		*node.(*service.ServiceNode) = svc
+		return nil
+	}
+	conn.gets++
+	export, ok := conn.exports[p]
+	if !ok {
+		return client.ErrNoNode
+	}
+	*node.(*registry.ExportDetails) = export
+	return nil
+}
+
+// testAuthorizer returns an authorizer whose coordinator has one export for
+// tenant1 at 172.17.0.2:8080 and one export for tenant2 at 172.17.0.3:8080.
+// tenant1 is deployed to pool1 and runs a child service in pool3, and tenant2
+// is deployed to pool2.  Connections to this host are allowed on port 5042.
+func testAuthorizer() (*ExportAuthorizer, *fakeConnection, *time.Time) {
+	conn := &fakeConnection{
+		children: map[string][]string{
+			"/net/export":             {"tenant1", "tenant2"},
+			"/net/export/tenant1":     {"app"},
+			"/net/export/tenant1/app": {"tenant1-app-0"},
+			"/net/export/tenant2":     {"app"},
+			"/net/export/tenant2/app": {"tenant2-app-0"},
+			"/pools/pool1/services":   {"tenant1"},
+			"/pools/pool2/services":   {"tenant2"},
+			"/pools/pool3/services":   {"collector"},
+		},
+		exports: map[string]registry.ExportDetails{
+			"/net/export/tenant1/app/tenant1-app-0": {
+				ExportBinding: service.ExportBinding{PortNumber: 8080},
+				PrivateIP:     "172.17.0.2",
+			},
+			"/net/export/tenant2/app/tenant2-app-0": {
+				ExportBinding: service.ExportBinding{PortNumber: 8080},
+				PrivateIP:     "172.17.0.3",
+			},
+		},
+		services: map[string]service.ServiceNode{
+			"/pools/pool3/services/collector": {ID: "collector", TenantID: "tenant1"},
+		},
+	}
+
+	now := time.Unix(1000, 0)
+	a := NewExportAuthorizer(func() (client.Connection, error) { return conn, nil }, []uint16{5042})
+	a.now = func() time.Time { return now }
+	a.isHostAddress = func(ip net.IP) bool { return ip.IsLoopback() || ip.String() == "10.0.0.1" }
+	return a, conn, &now
+}
+
+// testIdentity returns the identity of a host in a pool
+func testIdentity(poolID string) *authmocks.Identity {
+	ident := &authmocks.Identity{}
+	ident.On("PoolID").Return(poolID)
+	ident.On("HasAdminAccess").Return(false)
+	return ident
+}
+
+// testAdminIdentity returns the identity of a host with admin access
+func testAdminIdentity(poolID string) *authmocks.Identity {
+	ident := &authmocks.Identity{}
+	ident.On("PoolID").Return(poolID)
+	ident.On("HasAdminAccess").Return(true)
+	return ident
+}
+
+func TestExportAuthorizerAllowsTenantExport(t *testing.T) {
+	a, _, _ := testAuthorizer()
+	ident := testIdentity("pool1")
+
+	if err := a.Authorize(ident, "tenant1", "172.17.0.2:8080"); err != nil {
+		t.Errorf("expected the export to be allowed, got %s", err)
+	}
+	if err := a.Authorize(ident, "tenant1", "172.17.0.2:22"); err != ErrMuxNotExported {
+		t.Errorf("expected a port that is not exported to be denied, got %v", err)
+	}
+	if err := a.Authorize(ident, "tenant2", "172.17.0.2:8080"); err != ErrMuxTenantNotInPool {
+		t.Errorf("expected another tenant's export to be denied, got %v", err)
+	}
+	if err := a.Authorize(nil, "tenant1", "172.17.0.2:8080"); err != ErrMuxNoIdentity {
+		t.Errorf("expected a connection without an identity to be denied, got %v", err)
+	}
+}
+
+func TestExportAuthorizerDeniesOtherTenants(t *testing.T) {
+	a, _, _ := testAuthorizer()
+
+	// a host in pool1 claims to be connecting for tenant2
+	if err := a.Authorize(testIdentity("pool1"), "tenant2", "172.17.0.3:8080"); err != ErrMuxTenantNotInPool {
+		t.Errorf("expected a sender claiming another tenant to be denied, got %v", err)
+	}
+	if err := a.Authorize(testIdentity(""), "tenant2", "172.17.0.3:8080"); err != ErrMuxTenantNotInPool {
+		t.Errorf("expected a sender without a pool to be denied, got %v", err)
+	}
+	if err := a.Authorize(testIdentity("pool2"), "tenant2", "172.17.0.3:8080"); err != nil {
+		t.Errorf("expected a sender in the tenant's pool to be allowed, got %s", err)
+	}
+}
+
+func TestExportAuthorizerAllowsChildServicePools(t *testing.T) {
+	a, _, _ := testAuthorizer()
+
+	// pool3 only runs a child service of tenant1
+	if err := a.Authorize(testIdentity("pool3"), "tenant1", "172.17.0.2:8080"); err != nil {
+		t.Errorf("expected a sender in a pool with a child service to be allowed, got %s", err)
+	}
+	if err := a.Authorize(testIdentity("pool3"), "tenant2", "172.17.0.3:8080"); err != ErrMuxTenantNotInPool {
+		t.Errorf("expected a sender claiming another tenant to be denied, got %v", err)
+	}
+	if err := a.Authorize(testIdentity("pool4"), "tenant1", "172.17.0.2:8080"); err != ErrMuxTenantNotInPool {
+		t.Errorf("expected a sender in an empty pool to be denied, got %v", err)
+	}
+}
+
+func TestExportAuthorizerAllowsAdmin(t *testing.T) {
+	a, _, _ := testAuthorizer()
+
+	// the master proxies public endpoints and vhosts from its own pool
+	ident := testAdminIdentity("default")
+	if err := a.Authorize(ident, "tenant1", "172.17.0.2:8080"); err != nil {
+		t.Errorf("expected an admin sender to be allowed, got %s", err)
+	}
+	if err := a.Authorize(ident, "tenant2", "172.17.0.2:8080"); err != ErrMuxNotExported {
+		t.Errorf("expected an address that is not exported to be denied, got %v", err)
+	}
+}
+
+func TestExportAuthorizerWithoutTenant(t *testing.T) {
+	a, _, _ := testAuthorizer()
+
+	if err := a.Authorize(testIdentity("pool1"), "", "172.17.0.2:8080"); err != nil {
+		t.Errorf("expected the export of the sender's tenant to be allowed, got %s", err)
+	}
+	if err := a.Authorize(testIdentity("pool3"), "", "172.17.0.2:8080"); err != nil {
+		t.Errorf("expected the export of a child service's tenant to be allowed, got %s", err)
+	}
+	if err := a.Authorize(testIdentity("pool1"), "", "172.17.0.3:8080"); err != ErrMuxTenantNotInPool {
+		t.Errorf("expected another tenant's export to be denied, got %v", err)
+	}
+	if err := a.Authorize(testIdentity("pool1"), "", "172.17.0.2:22"); err != ErrMuxNotExported {
+		t.Errorf("expected a port that is not exported to be denied, got %v", err)
+	}
+}
+
+func TestExportAuthorizerHostAddresses(t *testing.T) {
+	a, conn, _ := testAuthorizer()
+	ident := testIdentity("pool1")
+
+	for _, address := range []string{"127.0.0.1:5042", "10.0.0.1:5042"} {
+		if err := a.Authorize(ident, "", address); err != nil {
+			t.Errorf("expected %s to be allowed, got %s", address, err)
+		}
+	}
+	if conn.gets != 0 {
+		t.Errorf("expected no exports to be looked up")
+	}
+	for _, address := range []string{"127.0.0.1:22", "10.0.0.1:2181"} {
+		if err := a.Authorize(ident, "tenant1", address); err != ErrMuxNotExported {
+			t.Errorf("expected %s to be denied, got %v", address, err)
+		}
+	}
+}
+
+func TestExportAuthorizerCache(t *testing.T) {
+	a, conn, now := testAuthorizer()
+	ident := testIdentity("pool1")
+
+	// exports are cached
+	a.Authorize(ident, "tenant1", "172.17.0.2:8080")
+	a.Authorize(ident, "tenant1", "172.17.0.2:8080")
+	if conn.gets != 1 {
+		t.Errorf("expected 1 lookup, got %d", conn.gets)
+	}
+
+	// unknown addresses are looked up at most once a second
+	a.Authorize(ident, "tenant1", "172.17.0.2:22")
+	if conn.gets != 1 {
+		t.Errorf("expected 1 lookup, got %d", conn.gets)
+	}
+	*now = now.Add(exportRefreshInterval)
+	a.Authorize(ident, "tenant1", "172.17.0.2:22")
+	if conn.gets != 2 {
+		t.Errorf("expected 2 lookups, got %d", conn.gets)
+	}
+
+	// and the cache expires
+	*now = now.Add(exportCacheTTL)
+	a.Authorize(ident, "tenant1", "172.17.0.2:8080")
+	if conn.gets != 3 {
+		t.Errorf("expected 3 lookups, got %d", conn.gets)
+	}
+}
@@ -0,0 +1,333 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/control-center/serviced/stats"
+	"github.com/rcrowley/go-metrics"
+)
+
+// quantiles are the percentiles of latency that are published
+var quantiles = []float64{0.5, 0.95, 0.99}
+
+// ConnectionMetrics keeps statistics about proxied connections for each
+// endpoint, so that they can be posted to the metrics store and scraped in
+// the Prometheus text format.  Metric names start with the prefix, such as
+// "net.proxy" for the endpoint proxies in a container or "net.mux" for the
+// TCP mux.
+type ConnectionMetrics struct {
+	prefix    string
+	mu        *sync.Mutex
+	endpoints map[string]*EndpointMetrics
+}
+
+// NewConnectionMetrics returns an empty set of connection metrics
+func NewConnectionMetrics(prefix string) *ConnectionMetrics {
+	return &ConnectionMetrics{
+		prefix:    prefix,
+		mu:        &sync.Mutex{},
+		endpoints: make(map[string]*EndpointMetrics),
+	}
+}
+
+// EndpointMetrics are the connection statistics of one endpoint.  A nil
+// *EndpointMetrics records nothing.
+type EndpointMetrics struct {
+	tags map[string]string
+
+	active    metrics.Counter   // open connections
+	total     metrics.Counter   // connections established
+	failed    metrics.Counter   // connections that could not be established
+	bytesIn   metrics.Counter   // bytes received from the backend
+	bytesOut  metrics.Counter   // bytes sent to the backend
+	dialTime  metrics.Histogram // time to connect to the backend, in microseconds
+	firstByte metrics.Histogram // time to the first byte from the backend, in microseconds
+}
+
+func newHistogram() metrics.Histogram {
+	return metrics.NewHistogram(metrics.NewExpDecaySample(1028, 0.015))
+}
+
+// Endpoint returns the metrics of the endpoint with the given tags, creating
+// them if they do not exist.
+func (m *ConnectionMetrics) Endpoint(tags map[string]string) *EndpointMetrics {
+	if m == nil {
+		return nil
+	}
+	key := tagString(tags)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e, ok := m.endpoints[key]; ok {
+		return e
+	}
+	e := &EndpointMetrics{
+		tags:      tags,
+		active:    metrics.NewCounter(),
+		total:     metrics.NewCounter(),
+		failed:    metrics.NewCounter(),
+		bytesIn:   metrics.NewCounter(),
+		bytesOut:  metrics.NewCounter(),
+		dialTime:  newHistogram(),
+		firstByte: newHistogram(),
+	}
+	m.endpoints[key] = e
+	return e
+}
+
+// Failed records a connection that could not be established
+func (e *EndpointMetrics) Failed() {
+	if e == nil {
+		return
+	}
+	e.failed.Inc(1)
+}
+
+// MeterConn records the connection to a backend, which was dialed at start,
+// and returns a connection that tracks the bytes transferred and the time to
+// the first byte from the backend.  The connection is logged with a
+// breakdown of its timings when it is closed, so that slow connections can be
+// traced to the mux hop or to the backend.
+func MeterConn(conn net.Conn, e *EndpointMetrics, start time.Time, logger *logrus.Entry) net.Conn {
+	if e == nil {
+		return conn
+	}
+	dialed := time.Now()
+	e.dialTime.Update(int64(dialed.Sub(start) / time.Microsecond))
+	e.total.Inc(1)
+	e.active.Inc(1)
+	return &meteredConn{
+		conn:     conn,
+		endpoint: e,
+		start:    start,
+		dialed:   dialed,
+		logger:   logger,
+	}
+}
+
+// meteredConn is a connection to a backend that records its metrics.  It does
+// not embed the connection so that io.Copy cannot bypass Read and Write.
+type meteredConn struct {
+	conn     net.Conn
+	endpoint *EndpointMetrics
+	start    time.Time
+	dialed   time.Time
+	logger   *logrus.Entry
+
+	mu        sync.Mutex
+	firstByte time.Time
+	in, out   int64
+	closed    bool
+}
+
+func (c *meteredConn) Read(b []byte) (int, error) {
+	n, err := c.conn.Read(b)
+	if n > 0 {
+		c.mu.Lock()
+		if c.firstByte.IsZero() {
+			c.firstByte = time.Now()
+			c.endpoint.firstByte.Update(int64(c.firstByte.Sub(c.dialed) / time.Microsecond))
+		}
+		c.in += int64(n)
+		c.mu.Unlock()
+		c.endpoint.bytesIn.Inc(int64(n))
+	}
+	return n, err
+}
+
+func (c *meteredConn) Write(b []byte) (int, error) {
+	n, err := c.conn.Write(b)
+	if n > 0 {
+		c.mu.Lock()
+		c.out += int64(n)
+		c.mu.Unlock()
+		c.endpoint.bytesOut.Inc(int64(n))
+	}
+	return n, err
+}
+
+func (c *meteredConn) Close() error {
+	c.mu.Lock()
+	if !c.closed {
+		c.closed = true
+		c.endpoint.active.Dec(1)
+		if c.logger != nil {
+			fields := logrus.Fields{
+				"dialtime":  c.dialed.Sub(c.start).String(),
+				"duration":  time.Since(c.start).String(),
+				"bytesin":   c.in,
+				"bytesout":  c.out,
+				"firstbyte": "none",
+			}
+			if !c.firstByte.IsZero() {
+				fields["firstbyte"] = c.firstByte.Sub(c.dialed).String()
+			}
+			c.logger.WithFields(fields).Debug("Closed proxied connection")
+		}
+	}
+	c.mu.Unlock()
+	return c.conn.Close()
+}
+
+func (c *meteredConn) LocalAddr() net.Addr                { return c.conn.LocalAddr() }
+func (c *meteredConn) RemoteAddr() net.Addr               { return c.conn.RemoteAddr() }
+func (c *meteredConn) SetDeadline(t time.Time) error      { return c.conn.SetDeadline(t) }
+func (c *meteredConn) SetReadDeadline(t time.Time) error  { return c.conn.SetReadDeadline(t) }
+func (c *meteredConn) SetWriteDeadline(t time.Time) error { return c.conn.SetWriteDeadline(t) }
+
+// snapshot returns the endpoints sorted by their tags
+func (m *ConnectionMetrics) snapshot() []*EndpointMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	keys := make([]string, 0, len(m.endpoints))
+	for key := range m.endpoints {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	endpoints := make([]*EndpointMetrics, len(keys))
+	for i, key := range keys {
+		endpoints[i] = m.endpoints[key]
+	}
+	return endpoints
+}
+
+// Samples returns the current value of every metric for the metrics store.
+// Latencies are in milliseconds.
+func (m *ConnectionMetrics) Samples(t time.Time) []stats.Sample {
+	if m == nil {
+		return nil
+	}
+	var samples []stats.Sample
+	ts := t.Unix()
+	for _, e := range m.snapshot() {
+		add := func(name, value string) {
+			samples = append(samples, stats.Sample{
+				Metric:    m.prefix + "." + name,
+				Value:     value,
+				Timestamp: ts,
+				Tags:      e.tags,
+			})
+		}
+		add("connections.active", strconv.FormatInt(e.active.Count(), 10))
+		add("connections.total", strconv.FormatInt(e.total.Count(), 10))
+		add("connections.failed", strconv.FormatInt(e.failed.Count(), 10))
+		add("bytes.in", strconv.FormatInt(e.bytesIn.Count(), 10))
+		add("bytes.out", strconv.FormatInt(e.bytesOut.Count(), 10))
+		for _, h := range []struct {
+			name string
+			hist metrics.Histogram
+		}{{"dial", e.dialTime}, {"firstbyte", e.firstByte}} {
+			s := h.hist.Snapshot()
+			add(h.name+".time.mean", formatMillis(s.Mean()))
+			for i, p := range s.Percentiles(quantiles) {
+				add(fmt.Sprintf("%s.time.p%d", h.name, int(quantiles[i]*100)), formatMillis(p))
+			}
+		}
+	}
+	return samples
+}
+
+func formatMillis(micros float64) string {
+	return strconv.FormatFloat(micros/1000, 'f', 3, 64)
+}
+
+// WritePrometheus writes every metric in the Prometheus text format.
+// Latencies are summaries in seconds.
+func (m *ConnectionMetrics) WritePrometheus(w io.Writer) error {
+	var buf bytes.Buffer
+	name := "serviced_" + strings.Replace(m.prefix, ".", "_", -1)
+	endpoints := m.snapshot()
+
+	counter := func(metric, help, kind string, value func(*EndpointMetrics) int64) {
+		fmt.Fprintf(&buf, "# HELP %s_%s %s\n# TYPE %s_%s %s\n", name, metric, help, name, metric, kind)
+		for _, e := range endpoints {
+			fmt.Fprintf(&buf, "%s_%s{%s} %d\n", name, metric, promLabels(e.tags), value(e))
+		}
+	}
+	counter("connections_active", "Open connections.", "gauge", func(e *EndpointMetrics) int64 { return e.active.Count() })
+	counter("connections_total", "Connections established.", "counter", func(e *EndpointMetrics) int64 { return e.total.Count() })
+	counter("connections_failed_total", "Connections that could not be established.", "counter", func(e *EndpointMetrics) int64 { return e.failed.Count() })
+	counter("received_bytes_total", "Bytes received from the backend.", "counter", func(e *EndpointMetrics) int64 { return e.bytesIn.Count() })
+	counter("sent_bytes_total", "Bytes sent to the backend.", "counter", func(e *EndpointMetrics) int64 { return e.bytesOut.Count() })
+
+	summary := func(metric, help string, hist func(*EndpointMetrics) metrics.Histogram) {
+		fmt.Fprintf(&buf, "# HELP %s_%s %s\n# TYPE %s_%s summary\n", name, metric, help, name, metric)
+		for _, e := range endpoints {
+			s := hist(e).Snapshot()
+			labels := promLabels(e.tags)
+			sep := ","
+			if labels == "" {
+				sep = ""
+			}
+			for i, p := range s.Percentiles(quantiles) {
+				fmt.Fprintf(&buf, "%s_%s{%s%squantile=\"%g\"} %g\n", name, metric, labels, sep, quantiles[i], p/1e6)
+			}
+			fmt.Fprintf(&buf, "%s_%s_sum{%s} %g\n", name, metric, labels, float64(s.Sum())/1e6)
+			fmt.Fprintf(&buf, "%s_%s_count{%s} %d\n", name, metric, labels, s.Count())
+		}
+	}
+	summary("dial_seconds", "Time to connect to the backend.", func(e *EndpointMetrics) metrics.Histogram { return e.dialTime })
+	summary("first_byte_seconds", "Time from connecting to the first byte from the backend.", func(e *EndpointMetrics) metrics.Histogram { return e.firstByte })
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// ServeHTTP serves the metrics in the Prometheus text format
+func (m *ConnectionMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	if err := m.WritePrometheus(w); err != nil {
+		log.WithError(err).Debug("Could not write connection metrics")
+	}
+}
+
+// tagString returns a key that identifies a set of tags
+func tagString(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + "=" + tags[k]
+	}
+	return strings.Join(parts, ",")
+}
+
+// promLabels formats tags as Prometheus labels
+func promLabels(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%s=%s", k, strconv.Quote(tags[k]))
+	}
+	return strings.Join(parts, ",")
+}
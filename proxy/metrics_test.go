@@ -0,0 +1,117 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build unit
+
+package proxy
+
+import (
+	"bytes"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMeterConn(t *testing.T) {
+	m := NewConnectionMetrics("net.mux")
+	e := m.Endpoint(map[string]string{"tenantid": "tenant1"})
+	if m.Endpoint(map[string]string{"tenantid": "tenant1"}) != e {
+		t.Fatalf("expected the same endpoint for the same tags")
+	}
+
+	local, remote := net.Pipe()
+	conn := MeterConn(local, e, time.Now(), nil)
+	go func() {
+		buf := make([]byte, 5)
+		remote.Read(buf)
+		remote.Write([]byte("hi"))
+	}()
+	conn.Write([]byte("hello"))
+	conn.Read(make([]byte, 10))
+	if e.active.Count() != 1 || e.total.Count() != 1 {
+		t.Errorf("expected 1 active connection, got %d of %d", e.active.Count(), e.total.Count())
+	}
+	conn.Close()
+	conn.Close()
+	remote.Close()
+	e.Failed()
+
+	if e.active.Count() != 0 {
+		t.Errorf("expected no active connections, got %d", e.active.Count())
+	}
+	if e.bytesOut.Count() != 5 || e.bytesIn.Count() != 2 {
+		t.Errorf("expected 5 bytes out and 2 in, got %d and %d", e.bytesOut.Count(), e.bytesIn.Count())
+	}
+	if e.failed.Count() != 1 {
+		t.Errorf("expected 1 failed connection, got %d", e.failed.Count())
+	}
+	if e.dialTime.Count() != 1 || e.firstByte.Count() != 1 {
+		t.Errorf("expected 1 latency sample each, got %d and %d", e.dialTime.Count(), e.firstByte.Count())
+	}
+}
+
+func TestConnectionMetricsSamples(t *testing.T) {
+	var nilMetrics *ConnectionMetrics
+	if nilMetrics.Samples(time.Now()) != nil || nilMetrics.Endpoint(nil) != nil {
+		t.Fatalf("expected nil metrics to record nothing")
+	}
+
+	m := NewConnectionMetrics("net.proxy")
+	m.Endpoint(map[string]string{"application": "app"}).Failed()
+	values := make(map[string]string)
+	for _, s := range m.Samples(time.Unix(1000, 0)) {
+		if s.Timestamp != 1000 || s.Tags["application"] != "app" {
+			t.Errorf("unexpected sample %+v", s)
+		}
+		values[s.Metric] = s.Value
+	}
+	for _, name := range []string{
+		"net.proxy.connections.active",
+		"net.proxy.connections.total",
+		"net.proxy.bytes.in",
+		"net.proxy.dial.time.p95",
+		"net.proxy.firstbyte.time.mean",
+	} {
+		if _, ok := values[name]; !ok {
+			t.Errorf("expected sample %s", name)
+		}
+	}
+	if values["net.proxy.connections.failed"] != "1" {
+		t.Errorf("expected 1 failed connection, got %s", values["net.proxy.connections.failed"])
+	}
+}
+
+func TestConnectionMetricsWritePrometheus(t *testing.T) {
+	m := NewConnectionMetrics("net.mux")
+	e := m.Endpoint(map[string]string{"tenantid": "tenant1", "containeraddr": "172.17.0.2:8080"})
+	MeterConn(&net.TCPConn{}, e, time.Now().Add(-time.Millisecond), nil)
+
+	var buf bytes.Buffer
+	if err := m.WritePrometheus(&buf); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	out := buf.String()
+	for _, line := range []string{
+		"# TYPE serviced_net_mux_connections_active gauge",
+		`serviced_net_mux_connections_active{containeraddr="172.17.0.2:8080",tenantid="tenant1"} 1`,
+		`serviced_net_mux_connections_total{containeraddr="172.17.0.2:8080",tenantid="tenant1"} 1`,
+		"# TYPE serviced_net_mux_dial_seconds summary",
+		`serviced_net_mux_dial_seconds{containeraddr="172.17.0.2:8080",tenantid="tenant1",quantile="0.99"}`,
+		`serviced_net_mux_dial_seconds_count{containeraddr="172.17.0.2:8080",tenantid="tenant1"} 1`,
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("expected %q in output:\n%s", line, out)
+		}
+	}
+}
@@ -15,6 +15,7 @@ package proxy
 
 import (
 	"github.com/Sirupsen/logrus"
+	"github.com/control-center/serviced/audit"
 	"github.com/control-center/serviced/auth"
 	"github.com/control-center/serviced/logging"
 	"github.com/control-center/serviced/utils"
@@ -33,16 +34,20 @@ var (
 
 // TCPMux is an implementation of tcp muxing RFC 1078.
 type TCPMux struct {
-	listener    net.Listener    // the connection this mux listens on
-	connections chan net.Conn   // stream of accepted connections
-	closing     chan chan error // shutdown noticiation
+	listener    net.Listener       // the connection this mux listens on
+	connections chan net.Conn      // stream of accepted connections
+	closing     chan chan error    // shutdown noticiation
+	authorizer  MuxAuthorizer      // decides which senders may reach which addresses
+	audit       audit.Logger       // records denied connections
+	metrics     *ConnectionMetrics // connection statistics per tenant
 	log         *logrus.Entry
 }
 
 // NewTCPMux creates a new tcp mux with the given listener. If it succees, it
 // is expected that this object is the owner of the listener and will close it
-// when Close() is called on the TCPMux.
-func NewTCPMux(listener net.Listener) (mux *TCPMux, err error) {
+// when Close() is called on the TCPMux.  Connections are checked with the
+// authorizer and recorded in the metrics, if they are given.
+func NewTCPMux(listener net.Listener, authorizer MuxAuthorizer, metrics *ConnectionMetrics) (mux *TCPMux, err error) {
 	log.Debug("Starting TCP multiplexer")
 	if listener == nil {
 		return nil, fmt.Errorf("listener can not be nil")
@@ -51,6 +56,9 @@ func NewTCPMux(listener net.Listener) (mux *TCPMux, err error) {
 		listener:    listener,
 		connections: make(chan net.Conn),
 		closing:     make(chan chan error),
+		authorizer:  authorizer,
+		audit:       audit.NewLogger(),
+		metrics:     metrics,
 		log: log.WithFields(logrus.Fields{
 			"address": listener.Addr(),
 		}),
@@ -131,9 +139,10 @@ func (mux *TCPMux) loop() {
 
 // muxConnection takes an inbound connection reads a line from it and
 // then attempts to set up a connection to the service specified by the
-// line. The service is specified in the form "IP:PORT\n". If the connection
-// to the service is sucessful, all traffic continues to be proxied between
-// two connections.
+// line. The service is specified in the form "IP:PORT\n". The sender is
+// answered with the result of the connection; if the sender is authorized
+// and the connection to the service is sucessful, all traffic continues to
+// be proxied between two connections.
 func (mux *TCPMux) muxConnection(conn net.Conn) {
 
 	log := mux.log.WithFields(logrus.Fields{
@@ -142,8 +151,7 @@ func (mux *TCPMux) muxConnection(conn net.Conn) {
 	// make sure that we don't block indefinitely
 	conn.SetReadDeadline(time.Now().Add(time.Second * 5))
 
-	// TODO retrieve and validate the identity of the sender
-	addrPacked, _, err := auth.ReadMuxHeader(conn)
+	addrPacked, tenantID, ident, err := auth.ReadMuxHeader(conn)
 	if err != nil {
 		log.WithError(err).Warn("Unable to read valid mux header. Closing connection")
 		conn.Close()
@@ -152,6 +160,14 @@ func (mux *TCPMux) muxConnection(conn net.Conn) {
 
 	address := utils.UnpackTCPAddressToString(addrPacked)
 
+	// Make sure the sender may connect to the requested address
+	if mux.authorizer != nil {
+		if err := mux.authorizer.Authorize(ident, tenantID, address); err != nil {
+			mux.deny(conn, ident, tenantID, address, err)
+			return
+		}
+	}
+
 	// Restore the read deadline
 	conn.SetReadDeadline(time.Time{})
 
@@ -160,18 +176,55 @@ func (mux *TCPMux) muxConnection(conn net.Conn) {
 		"remoteaddr":    conn.RemoteAddr(),
 		"containeraddr": address,
 	})
+	// metrics are kept per tenant; container addresses change whenever a
+	// container restarts, so keying by address would grow without bound
+	endpoint := mux.metrics.Endpoint(map[string]string{
+		"tenantid": tenantID,
+	})
+	start := time.Now()
 	svc, err := net.Dial("tcp4", address)
 	if err != nil {
 		log.Debug("Unable to dial container address. Perhaps the container is still starting?")
+		endpoint.Failed()
+		auth.WriteMuxResult(conn, auth.MuxUnreachable)
+		conn.Close()
+		return
+	}
+
+	// Let the sender know the connection is established
+	if err := auth.WriteMuxResult(conn, auth.MuxOK); err != nil {
+		log.WithError(err).Debug("Unable to answer mux header. Closing connection")
+		svc.Close()
 		conn.Close()
 		return
 	}
+	svc = MeterConn(svc, endpoint, start, log)
 
 	// Wire up the incoming connection to the one we just dialed
 	quit := make(chan bool)
 	go ProxyLoop(conn, svc, quit)
 }
 
+// deny closes a connection that the sender is not authorized to make and
+// records it in the audit log.
+func (mux *TCPMux) deny(conn net.Conn, ident auth.Identity, tenantID, address string, err error) {
+	user := "unknown"
+	fields := logrus.Fields{
+		"remoteaddr":    conn.RemoteAddr().String(),
+		"containeraddr": address,
+		"tenantid":      tenantID,
+	}
+	if ident != nil {
+		user = "host:" + ident.HostID()
+		fields["hostid"] = ident.HostID()
+		fields["poolid"] = ident.PoolID()
+	}
+	mux.log.WithFields(fields).WithError(err).Warn("Denied mux connection. Closing connection")
+	mux.audit.MessageAs(user, "Denied mux connection").Action(audit.Connect).Type("endpoint").ID(address).WithFields(fields).WithField("reason", err.Error()).Failed()
+	auth.WriteMuxResult(conn, auth.MuxDenied)
+	conn.Close()
+}
+
 func ProxyLoop(client net.Conn, backend net.Conn, quit chan bool) {
 	event := make(chan int64)
 	var broker = func(to, from net.Conn) {
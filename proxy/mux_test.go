@@ -130,7 +130,7 @@ func TestTCPMux(t *testing.T) {
 	if err != nil {
 		t.Fatalf("could not create tcpmux endpoint: %s", err)
 	}
-	mux, err := NewTCPMux(muxEndpoint)
+	mux, err := NewTCPMux(muxEndpoint, nil, nil)
 	if err != nil {
 		t.Fatalf("did not expect failure creating TCPMux: %s", err)
 	}
@@ -146,7 +146,10 @@ func TestTCPMux(t *testing.T) {
 	if err != nil {
 		t.Fail()
 	}
-	auth.AddSignedMuxHeader(conn, addr, token)
+	auth.AddSignedMuxHeader(conn, addr, "tenant", token)
+	if err := auth.ReadMuxResult(conn); err != nil {
+		t.Fatalf("expected the mux to connect: %s", err)
+	}
 	conn.Write([]byte(testMsg))
 	buffer := make([]byte, 4096)
 	n, err := conn.Read(buffer)
@@ -161,3 +164,130 @@ func TestTCPMux(t *testing.T) {
 	conn.Close()
 
 }
+
+func TestTCPMuxMetrics(t *testing.T) {
+	pub, priv, _ := auth.GenerateRSAKeyPairPEM(nil)
+	auth.LoadMasterKeysFromPEM(pub, priv)
+
+	dpub, priv, _ := auth.GenerateRSAKeyPairPEM(nil)
+	auth.LoadDelegateKeysFromPEM(pub, priv)
+
+	auth.RefreshToken(func() (string, int64, error) {
+		return auth.CreateJWTIdentity("host", "pool", true, true, dpub, time.Duration(365*24*60*60)*time.Second)
+	}, "")
+
+	muxEndpoint, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("could not create tcpmux endpoint: %s", err)
+	}
+	metrics := NewConnectionMetrics("net.mux")
+	mux, err := NewTCPMux(muxEndpoint, nil, metrics)
+	if err != nil {
+		t.Fatalf("did not expect failure creating TCPMux: %s", err)
+	}
+
+	// connections to every container of a tenant share its metrics
+	for i := 0; i < 2; i++ {
+		target := newEchoListener(t)
+		conn := mux.testConnect(t)
+		addr, err := utils.PackTCPAddressString(fmt.Sprintf("127.0.0.1:%s", listenerToPort(target.listener)))
+		if err != nil {
+			t.Fatalf("could not pack address: %s", err)
+		}
+		token, err := auth.AuthTokenNonBlocking()
+		if err != nil {
+			t.Fatalf("could not get token: %s", err)
+		}
+		auth.AddSignedMuxHeader(conn, addr, "tenant", token)
+		if err := auth.ReadMuxResult(conn); err != nil {
+			t.Fatalf("expected the mux to connect: %s", err)
+		}
+		conn.Write([]byte("hello"))
+		if n, _ := conn.Read(make([]byte, 4096)); n <= 0 {
+			t.Fatalf("expected something")
+		}
+		conn.Close()
+		target.Close()
+	}
+
+	endpoints := metrics.snapshot()
+	if len(endpoints) != 1 {
+		t.Fatalf("expected metrics for 1 tenant, got %d", len(endpoints))
+	}
+	if tags := endpoints[0].tags; len(tags) != 1 || tags["tenantid"] != "tenant" {
+		t.Errorf("unexpected tags %v", tags)
+	}
+	if total := endpoints[0].total.Count(); total != 2 {
+		t.Errorf("expected 2 connections, got %d", total)
+	}
+}
+
+type denyAll struct{}
+
+func (denyAll) Authorize(ident auth.Identity, tenantID, address string) error {
+	return ErrMuxNotExported
+}
+
+func TestTCPMuxResult(t *testing.T) {
+	pub, priv, _ := auth.GenerateRSAKeyPairPEM(nil)
+	auth.LoadMasterKeysFromPEM(pub, priv)
+
+	dpub, priv, _ := auth.GenerateRSAKeyPairPEM(nil)
+	auth.LoadDelegateKeysFromPEM(pub, priv)
+
+	auth.RefreshToken(func() (string, int64, error) {
+		return auth.CreateJWTIdentity("host", "pool", true, true, dpub, time.Duration(365*24*60*60)*time.Second)
+	}, "")
+	token, err := auth.AuthTokenNonBlocking()
+	if err != nil {
+		t.Fatalf("could not get token: %s", err)
+	}
+
+	target := newEchoListener(t)
+	defer target.Close()
+	addr, err := utils.PackTCPAddressString(fmt.Sprintf("127.0.0.1:%s", listenerToPort(target.listener)))
+	if err != nil {
+		t.Fatalf("could not pack address: %s", err)
+	}
+
+	// the sender is told when it is not authorized
+	muxEndpoint, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("could not create tcpmux endpoint: %s", err)
+	}
+	mux, err := NewTCPMux(muxEndpoint, denyAll{}, nil)
+	if err != nil {
+		t.Fatalf("did not expect failure creating TCPMux: %s", err)
+	}
+	conn := mux.testConnect(t)
+	auth.AddSignedMuxHeader(conn, addr, "tenant", token)
+	if err := auth.ReadMuxResult(conn); err != auth.ErrMuxDenied {
+		t.Errorf("expected %s, got %v", auth.ErrMuxDenied, err)
+	}
+	conn.Close()
+
+	// the sender is told when the address cannot be reached
+	muxEndpoint, err = net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("could not create tcpmux endpoint: %s", err)
+	}
+	mux, err = NewTCPMux(muxEndpoint, nil, nil)
+	if err != nil {
+		t.Fatalf("did not expect failure creating TCPMux: %s", err)
+	}
+	closed, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("could not listen: %s", err)
+	}
+	closed.Close()
+	addr, err = utils.PackTCPAddressString(fmt.Sprintf("127.0.0.1:%s", listenerToPort(closed)))
+	if err != nil {
+		t.Fatalf("could not pack address: %s", err)
+	}
+	conn = mux.testConnect(t)
+	auth.AddSignedMuxHeader(conn, addr, "tenant", token)
+	if err := auth.ReadMuxResult(conn); err != auth.ErrMuxUnreachable {
+		t.Errorf("expected %s, got %v", auth.ErrMuxUnreachable, err)
+	}
+	conn.Close()
+}
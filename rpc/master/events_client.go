@@ -0,0 +1,38 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package master
+
+import (
+	"time"
+
+	"github.com/control-center/serviced/events"
+)
+
+// EventsRequest selects the events returned by GetEvents
+type EventsRequest struct {
+	Filter  events.Filter
+	Since   uint64        // return the events after the event with this id
+	Timeout time.Duration // how long to wait if there are no events yet
+}
+
+// GetEvents returns the events after the given id that match the filter,
+// waiting up to timeout for one to be published
+func (c *Client) GetEvents(filter events.Filter, since uint64, timeout time.Duration) ([]events.Event, error) {
+	request := EventsRequest{Filter: filter, Since: since, Timeout: timeout}
+	response := []events.Event{}
+	if err := c.call("GetEvents", request, &response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
@@ -0,0 +1,29 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package master
+
+import (
+	"github.com/control-center/serviced/events"
+)
+
+// GetEvents returns the events after the given id that match the filter,
+// waiting up to timeout for one to be published
+func (s *Server) GetEvents(request EventsRequest, reply *[]events.Event) error {
+	result, err := s.f.GetEvents(s.context(), request.Filter, request.Since, request.Timeout)
+	if err != nil {
+		return err
+	}
+	*reply = result
+	return nil
+}
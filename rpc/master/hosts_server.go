@@ -19,7 +19,6 @@ import (
 
 	"github.com/control-center/serviced/auth"
 	"github.com/control-center/serviced/domain/host"
-	"github.com/control-center/serviced/domain/pool"
 	"github.com/control-center/serviced/facade"
 
 	"errors"
@@ -164,9 +163,16 @@ func (s *Server) AuthenticateHost(req HostAuthenticationRequest, resp *HostAuthe
 	if p == nil {
 		return facade.ErrPoolNotExists
 	}
-	adminAccess := p.Permissions&pool.AdminAccess != 0
-	dfsAccess := p.Permissions&pool.DFSAccess != 0
-	signed, expires, err := auth.CreateJWTIdentity(host.ID, host.PoolID, adminAccess, dfsAccess, keypem, s.expiration)
+	role := auth.RoleNone
+	switch {
+	case p.HasAdminAccess():
+		role = auth.RoleAdmin
+	case p.HasOperatorAccess():
+		role = auth.RoleOperator
+	case p.HasViewerAccess():
+		role = auth.RoleViewer
+	}
+	signed, expires, err := auth.CreateJWTIdentityWithRole(host.ID, host.PoolID, role, p.HasDfsAccess(), keypem, s.expiration)
 	if err != nil {
 		s.f.RemoveHostExpiration(s.context(), host.ID)
 		return err
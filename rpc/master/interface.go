@@ -16,14 +16,18 @@ package master
 import (
 	"time"
 
+	"github.com/control-center/serviced/datastore/migration"
 	"github.com/control-center/serviced/domain/addressassignment"
 	"github.com/control-center/serviced/domain/applicationendpoint"
 	"github.com/control-center/serviced/domain/host"
 	"github.com/control-center/serviced/domain/pool"
+	"github.com/control-center/serviced/domain/secret"
 	"github.com/control-center/serviced/domain/service"
 	"github.com/control-center/serviced/domain/servicedefinition"
 	"github.com/control-center/serviced/domain/servicetemplate"
+	"github.com/control-center/serviced/domain/snapshotpolicy"
 	"github.com/control-center/serviced/domain/user"
+	"github.com/control-center/serviced/events"
 	"github.com/control-center/serviced/health"
 	"github.com/control-center/serviced/isvcs"
 	"github.com/control-center/serviced/volume"
@@ -126,6 +130,15 @@ type ClientInterface interface {
 	// ClearEmergency will set EmergencyShutdown to false on the service and all child services
 	ClearEmergency(serviceID string) (int, error)
 
+	// GetRolloutStatus returns the progress of the most recent rolling restart of a service
+	GetRolloutStatus(serviceID string) (*service.RolloutStatus, error)
+
+	// GetServiceTree returns a service and its descendants in the form that ApplyServiceTree accepts
+	GetServiceTree(serviceID string) (*service.ServiceTree, error)
+
+	// ApplyServiceTree changes a service and its descendants to match a service tree
+	ApplyServiceTree(tree service.ServiceTree, dryRun bool) ([]service.ServiceChange, error)
+
 	//--------------------------------------------------------------------------
 	// Service Instance Management Functions
 
@@ -163,6 +176,9 @@ type ClientInterface interface {
 	// Deploy an application template
 	DeployTemplate(request servicetemplate.ServiceTemplateDeploymentRequest) (tenantIDs []string, err error)
 
+	// Merge a new service template into a deployed tenant
+	UpgradeTemplate(request servicetemplate.UpgradeRequest) (*servicetemplate.Upgrade, error)
+
 	//--------------------------------------------------------------------------
 	// Volume Management Functions
 
@@ -249,4 +265,61 @@ type ClientInterface interface {
 
 	// Assigns an IP address to a services that haven't IP Assignment by default
 	SetIPs(request addressassignment.AssignmentRequest) error
+
+	//--------------------------------------------------------------------------
+	// Migration Functions
+
+	// GetMigrationStatus returns the state of every schema migration
+	GetMigrationStatus() ([]migration.Status, error)
+
+	// MigrateEntities applies the pending schema migrations
+	MigrateEntities(dryRun bool) ([]migration.Status, error)
+
+	//--------------------------------------------------------------------------
+	// Event Functions
+
+	// GetEvents returns the events after the given id that match the filter,
+	// waiting up to timeout for one to be published
+	GetEvents(filter events.Filter, since uint64, timeout time.Duration) ([]events.Event, error)
+
+	//--------------------------------------------------------------------------
+	// Secret Functions
+
+	// AddSecret encrypts and stores a new secret
+	AddSecret(request SecretRequest) error
+
+	// RotateSecret replaces the value of a secret
+	RotateSecret(request SecretRequest) error
+
+	// RemoveSecret deletes a secret
+	RemoveSecret(name string) error
+
+	// GetSecrets returns all secrets without their values
+	GetSecrets() ([]secret.Secret, error)
+
+	// GetServiceSecrets returns the values of the secrets that are delivered
+	// to a service instance that runs on this host
+	GetServiceSecrets(serviceID string, instanceID int) (map[string]string, error)
+
+	//--------------------------------------------------------------------------
+	// Snapshot Policy Functions
+
+	// SetSnapshotPolicy adds or replaces the snapshot policy of a tenant
+	SetSnapshotPolicy(policy snapshotpolicy.SnapshotPolicy) error
+
+	// GetSnapshotPolicies returns the snapshot policies of all tenants
+	GetSnapshotPolicies() ([]snapshotpolicy.SnapshotPolicy, error)
+
+	// RemoveSnapshotPolicy removes the snapshot policy of a tenant
+	RemoveSnapshotPolicy(tenantID string) error
+
+	//--------------------------------------------------------------------------
+	// Snapshot Functions
+
+	// DiffSnapshot lists the files that differ between a snapshot and the
+	// live volume, or between two snapshots if otherID is set
+	DiffSnapshot(snapshotID, otherID string) ([]volume.FileChange, error)
+
+	// RestoreSnapshotPaths restores the given paths in place from a snapshot
+	RestoreSnapshotPaths(snapshotID string, paths []string) error
 }
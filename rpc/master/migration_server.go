@@ -0,0 +1,39 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package master
+
+import (
+	"github.com/control-center/serviced/datastore/migration"
+)
+
+// GetMigrationStatus returns the state of every schema migration, in order
+func (s *Server) GetMigrationStatus(unused struct{}, reply *[]migration.Status) error {
+	statuses, err := s.f.GetMigrationStatus(s.context())
+	if err != nil {
+		return err
+	}
+	*reply = statuses
+	return nil
+}
+
+// MigrateEntities applies the schema migrations that have not been applied
+// and returns their states.
+func (s *Server) MigrateEntities(dryRun bool, reply *[]migration.Status) error {
+	statuses, err := s.f.MigrateEntities(s.context(), dryRun)
+	if err != nil {
+		return err
+	}
+	*reply = statuses
+	return nil
+}
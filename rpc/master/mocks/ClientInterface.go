@@ -7,13 +7,17 @@ import isvcs "github.com/control-center/serviced/isvcs"
 import master "github.com/control-center/serviced/rpc/master"
 import mock "github.com/stretchr/testify/mock"
 import pool "github.com/control-center/serviced/domain/pool"
+import secret "github.com/control-center/serviced/domain/secret"
 import service "github.com/control-center/serviced/domain/service"
 import servicedefinition "github.com/control-center/serviced/domain/servicedefinition"
 import servicetemplate "github.com/control-center/serviced/domain/servicetemplate"
+import snapshotpolicy "github.com/control-center/serviced/domain/snapshotpolicy"
 import time "time"
 import user "github.com/control-center/serviced/domain/user"
 import volume "github.com/control-center/serviced/volume"
 import addressassignment "github.com/control-center/serviced/domain/addressassignment"
+import migration "github.com/control-center/serviced/datastore/migration"
+import events "github.com/control-center/serviced/events"
 
 // ClientInterface is an autogenerated mock type for the ClientInterface type
 type ClientInterface struct {
@@ -289,6 +293,29 @@ func (_m *ClientInterface) DeployTemplate(request servicetemplate.ServiceTemplat
 	return r0, r1
 }
 
+// UpgradeTemplate provides a mock function with given fields: request
+func (_m *ClientInterface) UpgradeTemplate(request servicetemplate.UpgradeRequest) (*servicetemplate.Upgrade, error) {
+	ret := _m.Called(request)
+
+	var r0 *servicetemplate.Upgrade
+	if rf, ok := ret.Get(0).(func(servicetemplate.UpgradeRequest) *servicetemplate.Upgrade); ok {
+		r0 = rf(request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*servicetemplate.Upgrade)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(servicetemplate.UpgradeRequest) error); ok {
+		r1 = rf(request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DockerOverride provides a mock function with given fields: newImage, oldImage
 func (_m *ClientInterface) DockerOverride(newImage string, oldImage string) error {
 	ret := _m.Called(newImage, oldImage)
@@ -1207,3 +1234,317 @@ func (_m *ClientInterface) SetIPs(assignmentRequest addressassignment.Assignment
 }
 
 var _ master.ClientInterface = (*ClientInterface)(nil)
+
+// GetMigrationStatus provides a mock function with given fields:
+func (_m *ClientInterface) GetMigrationStatus() ([]migration.Status, error) {
+	ret := _m.Called()
+
+	var r0 []migration.Status
+	if rf, ok := ret.Get(0).(func() []migration.Status); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]migration.Status)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetEvents provides a mock function with given fields: filter, since, timeout
+func (_m *ClientInterface) GetEvents(filter events.Filter, since uint64, timeout time.Duration) ([]events.Event, error) {
+	ret := _m.Called(filter, since, timeout)
+
+	var r0 []events.Event
+	if rf, ok := ret.Get(0).(func(events.Filter, uint64, time.Duration) []events.Event); ok {
+		r0 = rf(filter, since, timeout)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]events.Event)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(events.Filter, uint64, time.Duration) error); ok {
+		r1 = rf(filter, since, timeout)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MigrateEntities provides a mock function with given fields: dryRun
+func (_m *ClientInterface) MigrateEntities(dryRun bool) ([]migration.Status, error) {
+	ret := _m.Called(dryRun)
+
+	var r0 []migration.Status
+	if rf, ok := ret.Get(0).(func(bool) []migration.Status); ok {
+		r0 = rf(dryRun)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]migration.Status)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(bool) error); ok {
+		r1 = rf(dryRun)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRolloutStatus provides a mock function with given fields: serviceID
+func (_m *ClientInterface) GetRolloutStatus(serviceID string) (*service.RolloutStatus, error) {
+	ret := _m.Called(serviceID)
+
+	var r0 *service.RolloutStatus
+	if rf, ok := ret.Get(0).(func(string) *service.RolloutStatus); ok {
+		r0 = rf(serviceID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*service.RolloutStatus)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(serviceID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetServiceTree provides a mock function with given fields: serviceID
+func (_m *ClientInterface) GetServiceTree(serviceID string) (*service.ServiceTree, error) {
+	ret := _m.Called(serviceID)
+
+	var r0 *service.ServiceTree
+	if rf, ok := ret.Get(0).(func(string) *service.ServiceTree); ok {
+		r0 = rf(serviceID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*service.ServiceTree)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(serviceID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ApplyServiceTree provides a mock function with given fields: tree, dryRun
+func (_m *ClientInterface) ApplyServiceTree(tree service.ServiceTree, dryRun bool) ([]service.ServiceChange, error) {
+	ret := _m.Called(tree, dryRun)
+
+	var r0 []service.ServiceChange
+	if rf, ok := ret.Get(0).(func(service.ServiceTree, bool) []service.ServiceChange); ok {
+		r0 = rf(tree, dryRun)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]service.ServiceChange)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(service.ServiceTree, bool) error); ok {
+		r1 = rf(tree, dryRun)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AddSecret provides a mock function with given fields: request
+func (_m *ClientInterface) AddSecret(request master.SecretRequest) error {
+	ret := _m.Called(request)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(master.SecretRequest) error); ok {
+		r0 = rf(request)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RotateSecret provides a mock function with given fields: request
+func (_m *ClientInterface) RotateSecret(request master.SecretRequest) error {
+	ret := _m.Called(request)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(master.SecretRequest) error); ok {
+		r0 = rf(request)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RemoveSecret provides a mock function with given fields: name
+func (_m *ClientInterface) RemoveSecret(name string) error {
+	ret := _m.Called(name)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetSecrets provides a mock function with given fields:
+func (_m *ClientInterface) GetSecrets() ([]secret.Secret, error) {
+	ret := _m.Called()
+
+	var r0 []secret.Secret
+	if rf, ok := ret.Get(0).(func() []secret.Secret); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]secret.Secret)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetServiceSecrets provides a mock function with given fields: serviceID, instanceID
+func (_m *ClientInterface) GetServiceSecrets(serviceID string, instanceID int) (map[string]string, error) {
+	ret := _m.Called(serviceID, instanceID)
+
+	var r0 map[string]string
+	if rf, ok := ret.Get(0).(func(string, int) map[string]string); ok {
+		r0 = rf(serviceID, instanceID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, int) error); ok {
+		r1 = rf(serviceID, instanceID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetSnapshotPolicy provides a mock function with given fields: policy
+func (_m *ClientInterface) SetSnapshotPolicy(policy snapshotpolicy.SnapshotPolicy) error {
+	ret := _m.Called(policy)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(snapshotpolicy.SnapshotPolicy) error); ok {
+		r0 = rf(policy)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetSnapshotPolicies provides a mock function with given fields:
+func (_m *ClientInterface) GetSnapshotPolicies() ([]snapshotpolicy.SnapshotPolicy, error) {
+	ret := _m.Called()
+
+	var r0 []snapshotpolicy.SnapshotPolicy
+	if rf, ok := ret.Get(0).(func() []snapshotpolicy.SnapshotPolicy); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]snapshotpolicy.SnapshotPolicy)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveSnapshotPolicy provides a mock function with given fields: tenantID
+func (_m *ClientInterface) RemoveSnapshotPolicy(tenantID string) error {
+	ret := _m.Called(tenantID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(tenantID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DiffSnapshot provides a mock function with given fields: snapshotID, otherID
+func (_m *ClientInterface) DiffSnapshot(snapshotID string, otherID string) ([]volume.FileChange, error) {
+	ret := _m.Called(snapshotID, otherID)
+
+	var r0 []volume.FileChange
+	if rf, ok := ret.Get(0).(func(string, string) []volume.FileChange); ok {
+		r0 = rf(snapshotID, otherID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]volume.FileChange)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(snapshotID, otherID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RestoreSnapshotPaths provides a mock function with given fields: snapshotID, paths
+func (_m *ClientInterface) RestoreSnapshotPaths(snapshotID string, paths []string) error {
+	ret := _m.Called(snapshotID, paths)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, []string) error); ok {
+		r0 = rf(snapshotID, paths)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -0,0 +1,78 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package master
+
+import (
+	"github.com/control-center/serviced/domain/secret"
+)
+
+// SecretRequest is the name, description and value of a secret
+type SecretRequest struct {
+	Name        string
+	Description string
+	Value       []byte
+}
+
+// ServiceSecretsRequest asks for the secrets of a service instance.  The
+// host id is set by the server from the identity of the sender.
+type ServiceSecretsRequest struct {
+	ServiceID  string
+	InstanceID int
+	hostID     string
+}
+
+// SetHostID implements rpcutils.HostRequest
+func (r *ServiceSecretsRequest) SetHostID(hostID string) {
+	r.hostID = hostID
+}
+
+// HostID returns the id of the host that sent the request
+func (r ServiceSecretsRequest) HostID() string {
+	return r.hostID
+}
+
+// AddSecret encrypts and stores a new secret
+func (c *Client) AddSecret(request SecretRequest) error {
+	return c.call("AddSecret", request, nil)
+}
+
+// RotateSecret replaces the value of a secret
+func (c *Client) RotateSecret(request SecretRequest) error {
+	return c.call("RotateSecret", request, nil)
+}
+
+// RemoveSecret deletes a secret
+func (c *Client) RemoveSecret(name string) error {
+	return c.call("RemoveSecret", name, nil)
+}
+
+// GetSecrets returns all secrets without their values
+func (c *Client) GetSecrets() ([]secret.Secret, error) {
+	response := []secret.Secret{}
+	if err := c.call("GetSecrets", empty, &response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// GetServiceSecrets returns the values of the secrets that are delivered to a
+// service instance that runs on this host
+func (c *Client) GetServiceSecrets(serviceID string, instanceID int) (map[string]string, error) {
+	request := ServiceSecretsRequest{ServiceID: serviceID, InstanceID: instanceID}
+	response := make(map[string]string)
+	if err := c.call("GetServiceSecrets", request, &response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
@@ -0,0 +1,54 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package master
+
+import (
+	"github.com/control-center/serviced/domain/secret"
+)
+
+// AddSecret encrypts and stores a new secret
+func (s *Server) AddSecret(request SecretRequest, _ *struct{}) error {
+	return s.f.AddSecret(s.context(), request.Name, request.Description, request.Value)
+}
+
+// RotateSecret replaces the value of a secret
+func (s *Server) RotateSecret(request SecretRequest, _ *struct{}) error {
+	return s.f.RotateSecret(s.context(), request.Name, request.Value)
+}
+
+// RemoveSecret deletes a secret
+func (s *Server) RemoveSecret(name string, _ *struct{}) error {
+	return s.f.RemoveSecret(s.context(), name)
+}
+
+// GetSecrets returns all secrets without their values
+func (s *Server) GetSecrets(_ struct{}, reply *[]secret.Secret) error {
+	secrets, err := s.f.GetSecrets(s.context())
+	if err != nil {
+		return err
+	}
+	*reply = secrets
+	return nil
+}
+
+// GetServiceSecrets returns the values of the secrets that are delivered to a
+// service instance, if the instance runs on the host that sent the request
+func (s *Server) GetServiceSecrets(request ServiceSecretsRequest, reply *map[string]string) error {
+	values, err := s.f.GetServiceSecrets(s.context(), request.HostID(), request.ServiceID, request.InstanceID)
+	if err != nil {
+		return err
+	}
+	*reply = values
+	return nil
+}
@@ -114,6 +114,28 @@ func (c *Client) ClearEmergency(serviceID string) (int, error) {
 	return affected, err
 }
 
+// GetRolloutStatus returns the progress of the most recent rolling restart of a service
+func (c *Client) GetRolloutStatus(serviceID string) (*service.RolloutStatus, error) {
+	status := &service.RolloutStatus{}
+	err := c.call("GetRolloutStatus", serviceID, status)
+	return status, err
+}
+
+// GetServiceTree returns a service and its descendants in the form that ApplyServiceTree accepts
+func (c *Client) GetServiceTree(serviceID string) (*service.ServiceTree, error) {
+	tree := &service.ServiceTree{}
+	err := c.call("GetServiceTree", serviceID, tree)
+	return tree, err
+}
+
+// ApplyServiceTree changes a service and its descendants to match a service tree
+func (c *Client) ApplyServiceTree(tree service.ServiceTree, dryRun bool) ([]service.ServiceChange, error) {
+	request := ApplyServiceTreeRequest{Tree: tree, DryRun: dryRun}
+	changes := []service.ServiceChange{}
+	err := c.call("ApplyServiceTree", request, &changes)
+	return changes, err
+}
+
 // Remove the IP assignment of a service's endpoints
 func (c *Client) RemoveIPs(args []string) error {
 	return c.call("RemoveIPs", args, new(string))
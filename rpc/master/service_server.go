@@ -51,6 +51,11 @@ type ServiceDetailsByTenantIDRequest struct {
 	Since    time.Duration
 }
 
+type ApplyServiceTreeRequest struct {
+	Tree   service.ServiceTree
+	DryRun bool
+}
+
 // Use a new image for a given service - this will pull the image and tag it
 func (s *Server) ServiceUse(request *ServiceUseRequest, response *string) error {
 	if err := s.f.ServiceUse(s.context(), request.ServiceID, request.ImageID, request.Registry, request.ReplaceImgs, request.NoOp); err != nil {
@@ -154,6 +159,33 @@ func (s *Server) ClearEmergency(serviceID string, count *int) error {
 	return nil
 }
 
+// GetRolloutStatus returns the progress of the most recent rolling restart of a service
+func (s *Server) GetRolloutStatus(serviceID string, status *service.RolloutStatus) error {
+	st, err := s.f.GetRolloutStatus(s.context(), serviceID)
+	if err != nil {
+		return err
+	}
+	*status = *st
+	return nil
+}
+
+// GetServiceTree returns a service and its descendants in the form that ApplyServiceTree accepts
+func (s *Server) GetServiceTree(serviceID string, tree *service.ServiceTree) error {
+	t, err := s.f.GetServiceTree(s.context(), serviceID)
+	if err != nil {
+		return err
+	}
+	*tree = *t
+	return nil
+}
+
+// ApplyServiceTree changes a service and its descendants to match a service tree
+func (s *Server) ApplyServiceTree(request ApplyServiceTreeRequest, changes *[]service.ServiceChange) error {
+	c, err := s.f.ApplyServiceTree(s.context(), request.Tree, request.DryRun)
+	*changes = c
+	return err
+}
+
 func (s *Server) RemoveIPs(args []string, unused *string) error {
 	return s.f.RemoveIPs(s.context(), args)
 }
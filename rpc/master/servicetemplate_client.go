@@ -54,3 +54,11 @@ func (c *Client) DeployTemplate(request servicetemplate.ServiceTemplateDeploymen
 
 }
 
+// Merge a new service template into a deployed tenant
+func (c *Client) UpgradeTemplate(request servicetemplate.UpgradeRequest) (*servicetemplate.Upgrade, error) {
+	response := &servicetemplate.Upgrade{}
+	if err := c.call("UpgradeTemplate", request, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
@@ -52,3 +52,13 @@ func (s *Server) DeployTemplate(request servicetemplate.ServiceTemplateDeploymen
 	*response = tenantIDs
 	return nil
 }
+
+// Merge a new service template into a deployed tenant
+func (s *Server) UpgradeTemplate(request servicetemplate.UpgradeRequest, response *servicetemplate.Upgrade) error {
+	upgrade, err := s.f.UpgradeTemplate(s.context(), request)
+	if err != nil {
+		return err
+	}
+	*response = *upgrade
+	return nil
+}
@@ -0,0 +1,49 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package master
+
+import (
+	"github.com/control-center/serviced/volume"
+)
+
+// SnapshotDiffRequest identifies a snapshot to compare against the live
+// volume, or against another snapshot if OtherID is set
+type SnapshotDiffRequest struct {
+	SnapshotID string
+	OtherID    string
+}
+
+// SnapshotRestorePathsRequest identifies a snapshot and the paths to restore
+// from it
+type SnapshotRestorePathsRequest struct {
+	SnapshotID string
+	Paths      []string
+}
+
+// DiffSnapshot lists the files that differ between a snapshot and the live
+// volume or another snapshot
+func (c *Client) DiffSnapshot(snapshotID, otherID string) ([]volume.FileChange, error) {
+	request := SnapshotDiffRequest{SnapshotID: snapshotID, OtherID: otherID}
+	response := []volume.FileChange{}
+	if err := c.call("DiffSnapshot", request, &response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// RestoreSnapshotPaths restores the given paths in place from a snapshot
+func (c *Client) RestoreSnapshotPaths(snapshotID string, paths []string) error {
+	request := SnapshotRestorePathsRequest{SnapshotID: snapshotID, Paths: paths}
+	return c.call("RestoreSnapshotPaths", request, nil)
+}
@@ -0,0 +1,34 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package master
+
+import (
+	"github.com/control-center/serviced/volume"
+)
+
+// DiffSnapshot lists the files that differ between a snapshot and the live
+// volume or another snapshot
+func (s *Server) DiffSnapshot(request SnapshotDiffRequest, reply *[]volume.FileChange) error {
+	changes, err := s.f.DiffSnapshot(s.context(), request.SnapshotID, request.OtherID)
+	if err != nil {
+		return err
+	}
+	*reply = changes
+	return nil
+}
+
+// RestoreSnapshotPaths restores the given paths in place from a snapshot
+func (s *Server) RestoreSnapshotPaths(request SnapshotRestorePathsRequest, _ *struct{}) error {
+	return s.f.RestoreSnapshotPaths(s.context(), request.SnapshotID, request.Paths)
+}
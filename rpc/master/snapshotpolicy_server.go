@@ -0,0 +1,38 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package master
+
+import (
+	"github.com/control-center/serviced/domain/snapshotpolicy"
+)
+
+// SetSnapshotPolicy adds or replaces the snapshot policy of a tenant
+func (s *Server) SetSnapshotPolicy(policy snapshotpolicy.SnapshotPolicy, _ *struct{}) error {
+	return s.f.SetSnapshotPolicy(s.context(), policy)
+}
+
+// GetSnapshotPolicies returns the snapshot policies of all tenants
+func (s *Server) GetSnapshotPolicies(_ struct{}, reply *[]snapshotpolicy.SnapshotPolicy) error {
+	policies, err := s.f.GetSnapshotPolicies(s.context())
+	if err != nil {
+		return err
+	}
+	*reply = policies
+	return nil
+}
+
+// RemoveSnapshotPolicy removes the snapshot policy of a tenant
+func (s *Server) RemoveSnapshotPolicy(tenantID string, _ *struct{}) error {
+	return s.f.RemoveSnapshotPolicy(s.context(), tenantID)
+}
@@ -39,11 +39,13 @@ var (
 		"Master.GetHost":                         struct{}{},
 		"Master.GetHosts":                        struct{}{},
 		"Master.GetEvaluatedService":             struct{}{},
+		"Master.GetServiceSecrets":               struct{}{},
 		"Master.GetSystemUser":                   struct{}{},
 		"Master.ReportHealthStatus":              struct{}{},
 		"Master.ReportInstanceDead":              struct{}{},
 		"Master.UpdateHost":                      struct{}{},
 		"ControlCenterAgent.GetEvaluatedService": struct{}{},
+		"ControlCenterAgent.GetServiceSecrets":   struct{}{},
 		"ControlCenterAgent.GetHostID":           struct{}{},
 		"ControlCenterAgent.GetZkInfo":           struct{}{},
 		"ControlCenterAgent.GetISvcEndpoints":    struct{}{},
@@ -52,13 +54,76 @@ var (
 		"ControlCenterAgent.SendLogMessage":      struct{}{},
 		"ControlCenterAgent.AddHostPrivate":      struct{}{},
 	}
+	// RPC calls that require less than admin access.  Calls that are not
+	// listed here or in NonAdminRequiredCalls require admin access.
+	RoleRequiredCalls = map[string]auth.Role{
+		"Master.GetActiveHostIDs":                    auth.RoleViewer,
+		"Master.FindHostsInPool":                     auth.RoleViewer,
+		"Master.HostsAuthenticated":                  auth.RoleViewer,
+		"Master.GetServiceEndpoints":                 auth.RoleViewer,
+		"Master.GetISvcsHealth":                      auth.RoleViewer,
+		"Master.GetServicesHealth":                   auth.RoleViewer,
+		"Master.GetResourcePools":                    auth.RoleViewer,
+		"Master.GetResourcePool":                     auth.RoleViewer,
+		"Master.GetPoolIPs":                          auth.RoleViewer,
+		"Master.GetAllPublicEndpoints":               auth.RoleViewer,
+		"Master.GetAllServiceDetails":                auth.RoleViewer,
+		"Master.GetServiceDetails":                   auth.RoleViewer,
+		"Master.GetServiceDetailsByTenantID":         auth.RoleViewer,
+		"Master.GetService":                          auth.RoleViewer,
+		"Master.GetTenantID":                         auth.RoleViewer,
+		"Master.ResolveServicePath":                  auth.RoleViewer,
+		"Master.GetServiceTemplates":                 auth.RoleViewer,
+		"Master.GetVolumeStatus":                     auth.RoleViewer,
+		"Master.GetMigrationStatus":                  auth.RoleViewer,
+		"Master.GetRolloutStatus":                    auth.RoleViewer,
+		"Master.GetServiceTree":                      auth.RoleViewer,
+		"Master.GetEvents":                           auth.RoleViewer,
+		"Master.GetSecrets":                          auth.RoleViewer,
+		"Master.GetSnapshotPolicies":                 auth.RoleViewer,
+		"Master.DiffSnapshot":                        auth.RoleViewer,
+		"ControlCenter.GetServiceLogs":               auth.RoleViewer,
+		"ControlCenter.GetServiceStateLogs":          auth.RoleViewer,
+		"ControlCenter.GetHostMemoryStats":           auth.RoleViewer,
+		"ControlCenter.GetServiceMemoryStats":        auth.RoleViewer,
+		"ControlCenter.GetInstanceMemoryStats":       auth.RoleViewer,
+		"ControlCenter.GetRunningServices":           auth.RoleViewer,
+		"ControlCenter.GetRunningServicesForHost":    auth.RoleViewer,
+		"ControlCenter.GetRunningServicesForService": auth.RoleViewer,
+		"ControlCenter.GetServiceList":               auth.RoleViewer,
+		"ControlCenter.GetService":                   auth.RoleViewer,
+		"ControlCenter.GetTenantIDs":                 auth.RoleViewer,
+		"ControlCenter.FindChildService":             auth.RoleViewer,
+		"ControlCenter.GetServiceStatus":             auth.RoleViewer,
+		"ControlCenter.ListBackups":                  auth.RoleViewer,
+		"ControlCenter.BackupStatus":                 auth.RoleViewer,
+		"ControlCenter.GetBackupEstimate":            auth.RoleViewer,
+		"ControlCenter.ListSnapshots":                auth.RoleViewer,
+		"ControlCenter.GetSnapshotByServiceIDAndTag": auth.RoleViewer,
+		"ControlCenter.StartService":                 auth.RoleOperator,
+		"ControlCenter.RestartService":               auth.RoleOperator,
+		"ControlCenter.StopService":                  auth.RoleOperator,
+		"ControlCenter.PauseService":                 auth.RoleOperator,
+		"ControlCenter.RebalanceService":             auth.RoleOperator,
+		"ControlCenter.WaitService":                  auth.RoleOperator,
+		"ControlCenter.StopRunningInstance":          auth.RoleOperator,
+		"Master.WaitService":                         auth.RoleOperator,
+	}
 	endian = binary.BigEndian
 
-	ErrNoAdmin = errors.New("Delegate does not have admin access")
+	ErrNoAdmin          = errors.New("Delegate does not have admin access")
+	ErrInsufficientRole = errors.New("Delegate does not have the role required for this call")
 
 	log = logging.PackageLogger()
 )
 
+// HostRequest is implemented by requests that need the id of the host that
+// sent them.  The server codec sets it from the identity of the sender after
+// the request is decoded, so the sender cannot choose it.
+type HostRequest interface {
+	SetHostID(hostID string)
+}
+
 // Checks the RPC method name to see if authentication is required.
 //  If it is, calls on the client side will include a signed header, which will be
 //  Verified on the server side
@@ -74,8 +139,18 @@ func requiresAuthentication(callName string) bool {
 // Checks the RPC method name to see if admin-level permissions are required.
 //  If they are, it will also check the "admin" attribute on the identity after validating it.
 func requiresAdmin(callName string) bool {
-	_, ok := NonAdminRequiredCalls[callName]
-	return !ok
+	return requiredRole(callName) == auth.RoleAdmin
+}
+
+// Returns the role the identity must have to make the RPC call
+func requiredRole(callName string) auth.Role {
+	if _, ok := NonAdminRequiredCalls[callName]; ok {
+		return auth.RoleNone
+	}
+	if role, ok := RoleRequiredCalls[callName]; ok {
+		return role
+	}
+	return auth.RoleAdmin
 }
 
 // We nead a ReadWriteCloser that we can pass to the underlying codec and use
@@ -107,6 +182,7 @@ type AuthServerCodec struct {
 	parser       auth.RPCHeaderParser
 	wBuffMutex   sync.Mutex // Make sure we buffer one response at a time
 	lastError    error
+	ident        auth.Identity // The verified identity of the current request
 }
 
 func NewDefaultAuthServerCodec(conn io.ReadWriteCloser) rpc.ServerCodec {
@@ -134,6 +210,7 @@ func (a *AuthServerCodec) ReadRequestHeader(r *rpc.Request) error {
 
 	// Reset state
 	a.lastError = nil
+	a.ident = nil
 	a.buff.ReadBuff.Reset()
 
 	ident, body, err := a.parser.ReadHeader(a.conn)
@@ -166,25 +243,46 @@ func (a *AuthServerCodec) ReadRequestHeader(r *rpc.Request) error {
 	//   (unless ReadRequestHeader returns an error)
 	if requiresAuthentication(r.ServiceMethod) {
 		if a.lastError == nil {
-			if requiresAdmin(r.ServiceMethod) && (ident == nil || !ident.HasAdminAccess()) {
-				log.WithField("ServiceMethod", r.ServiceMethod).Debug("Received unauthorized RPC request")
-				a.lastError = ErrNoAdmin
+			switch role := requiredRole(r.ServiceMethod); role {
+			case auth.RoleNone:
+			case auth.RoleAdmin:
+				if ident == nil || !ident.HasAdminAccess() {
+					log.WithField("ServiceMethod", r.ServiceMethod).Debug("Received unauthorized RPC request")
+					a.lastError = ErrNoAdmin
+				}
+			default:
+				if ident == nil || !ident.Role().Includes(role) {
+					log.WithField("ServiceMethod", r.ServiceMethod).WithField("role", role).Debug("Received unauthorized RPC request")
+					a.lastError = ErrInsufficientRole
+				}
 			}
 		}
-		//TODO: save the identity so we can inject it into the request body later
+		if a.lastError == nil {
+			a.ident = ident
+		}
 	}
 	return nil
 }
 
 // Decodes the request and populates the body object with the body of the request
-//  We don't change anything here, just let the underlying codec handle it.
+//  The underlying codec decodes the body, then the host id of the sender is
+//  set on requests that implement HostRequest.
 //  This always gets called after ReadRequestHeader
 func (a *AuthServerCodec) ReadRequestBody(body interface{}) error {
 	if a.lastError != nil {
 		return a.lastError
 	}
-	// TODO: Use reflection and add the identity to the body if necessary
-	return a.wrappedcodec.ReadRequestBody(body)
+	if err := a.wrappedcodec.ReadRequestBody(body); err != nil {
+		return err
+	}
+	if req, ok := body.(HostRequest); ok {
+		hostID := ""
+		if a.ident != nil {
+			hostID = a.ident.HostID()
+		}
+		req.SetHostID(hostID)
+	}
+	return nil
 }
 
 //  Encodes the response before sending it back down to the client.
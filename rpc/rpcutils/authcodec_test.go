@@ -135,6 +135,33 @@ func (s *MySuite) TestReadRequestHeader(c *C) {
 	codectest.conn.AssertExpectations(c)
 }
 
+func (s *MySuite) TestReadRequestHeaderRole(c *C) {
+	RoleRequiredCalls["RPCTestType.OperatorRequiredCall"] = auth.RoleOperator
+	defer delete(RoleRequiredCalls, "RPCTestType.OperatorRequiredCall")
+
+	req := &rpc.Request{ServiceMethod: "RPCTestType.OperatorRequiredCall"}
+	ident := &authmocks.Identity{}
+	body := []byte("Body1")
+	b := struct{}{}
+
+	// Test error with a role below the required role
+	codectest.wrappedServerCodec.On("ReadRequestHeader", req).Return(nil).Once()
+	codectest.headerParser.On("ReadHeader", codectest.conn).Return(ident, body, nil).Once()
+	ident.On("Role").Return(auth.RoleViewer).Once()
+	err := codectest.authServerCodec.ReadRequestHeader(req)
+	c.Assert(err, IsNil)
+	err = codectest.authServerCodec.ReadRequestBody(&b)
+	c.Assert(err, Equals, ErrInsufficientRole)
+
+	// Test success with a role above the required role
+	codectest.wrappedServerCodec.On("ReadRequestHeader", req).Return(nil).Once()
+	codectest.headerParser.On("ReadHeader", codectest.conn).Return(ident, body, nil).Once()
+	ident.On("Role").Return(auth.RoleAdmin).Once()
+	err = codectest.authServerCodec.ReadRequestHeader(req)
+	c.Assert(err, IsNil)
+	ident.AssertExpectations(c)
+}
+
 func (s *MySuite) TestReadRequestBody(c *C) {
 	body := 0
 	codectest.wrappedServerCodec.On("ReadRequestBody", body).Return(ErrTestCodec).Once()
@@ -145,6 +172,44 @@ func (s *MySuite) TestReadRequestBody(c *C) {
 	c.Assert(err, IsNil)
 }
 
+type testHostRequest struct {
+	hostID string
+}
+
+func (r *testHostRequest) SetHostID(hostID string) {
+	r.hostID = hostID
+}
+
+func (s *MySuite) TestReadRequestBodyHostID(c *C) {
+	ident := &authmocks.Identity{}
+	ident.On("HostID").Return("hostid")
+	body := []byte("Body1")
+
+	// the host id of the verified sender is set on the request
+	req := &rpc.Request{ServiceMethod: "RPCTestType.NonAdminRequiredCall"}
+	codectest.wrappedServerCodec.On("ReadRequestHeader", req).Return(nil).Once()
+	codectest.headerParser.On("ReadHeader", codectest.conn).Return(ident, body, nil).Once()
+	err := codectest.authServerCodec.ReadRequestHeader(req)
+	c.Assert(err, IsNil)
+	r := &testHostRequest{hostID: "otherhostid"}
+	codectest.wrappedServerCodec.On("ReadRequestBody", r).Return(nil).Once()
+	err = codectest.authServerCodec.ReadRequestBody(r)
+	c.Assert(err, IsNil)
+	c.Assert(r.hostID, Equals, "hostid")
+
+	// calls that are not authenticated have no host id
+	req = &rpc.Request{ServiceMethod: "RPCTestType.NonAuthenticatingCall"}
+	codectest.wrappedServerCodec.On("ReadRequestHeader", req).Return(nil).Once()
+	codectest.headerParser.On("ReadHeader", codectest.conn).Return(ident, body, nil).Once()
+	err = codectest.authServerCodec.ReadRequestHeader(req)
+	c.Assert(err, IsNil)
+	r = &testHostRequest{hostID: "otherhostid"}
+	codectest.wrappedServerCodec.On("ReadRequestBody", r).Return(nil).Once()
+	err = codectest.authServerCodec.ReadRequestBody(r)
+	c.Assert(err, IsNil)
+	c.Assert(r.hostID, Equals, "")
+}
+
 func (s *MySuite) TestWriteResponse(c *C) {
 	body := 0
 	resp := &rpc.Response{}
@@ -299,4 +364,9 @@ func (s *MySuite) TestRequiresAdmin(c *C) {
 	c.Assert(result, Equals, false)
 	result = requiresAdmin("RPCTestType.AdminRequiredCall")
 	c.Assert(result, Equals, true)
+	result = requiresAdmin("ControlCenter.RestartService")
+	c.Assert(result, Equals, false)
+	c.Assert(requiredRole("ControlCenter.RestartService"), Equals, auth.RoleOperator)
+	c.Assert(requiredRole("ControlCenter.RemoveService"), Equals, auth.RoleAdmin)
+	c.Assert(requiredRole("RPCTestType.NonAdminRequiredCall"), Equals, auth.RoleNone)
 }
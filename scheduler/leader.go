@@ -53,8 +53,11 @@ func Lead(shutdown <-chan interface{}, conn coordclient.Connection, cpClient dao
 	// creates a listener for services
 	serviceListener := zkservice.NewServiceListener(poolID, &leader)
 
+	// creates a listener that publishes changes to the states of instances
+	instanceListener := zkservice.NewInstanceEventListener(poolID, facade)
+
 	// starts all of the listeners
-	zzk.Start(shutdown, conn, serviceListener, hreg)
+	zzk.Start(shutdown, conn, serviceListener, hreg, instanceListener)
 }
 
 // SelectHost chooses a host from the pool for the specified service. If the
@@ -146,8 +149,7 @@ func (l *leader) SelectHost(sn *zkservice.ServiceNode) (string, error) {
 		return "", errors.New("assigned ip is not available")
 	}
 
-	hp := sn.HostPolicy
-	strat, err := strategy.Get(string(hp))
+	strat, err := strategy.ForService(&StrategyService{sn})
 	if err != nil {
 		return "", err
 	}
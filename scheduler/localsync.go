@@ -82,10 +82,19 @@ func (s *scheduler) doSync(rootConn client.Connection) <-chan time.Time {
 		}
 
 		// Update the services
-		if svcs, err := s.GetServicesByPool(pool.ID); err != nil {
+		svcs, err := s.GetServicesByPool(pool.ID)
+		if err != nil {
 			glog.Errorf("Could not get services: %s", err)
 			return time.After(minWait)
-		} else if err = zkservice.SyncServices(conn, svcs); err != nil {
+		}
+		tenantIDs := make(map[string]string)
+		for _, svc := range svcs {
+			if tenantIDs[svc.ID], err = s.facade.GetTenantID(ctx, svc.ID); err != nil {
+				glog.Errorf("Could not get the tenant of service %s (%s): %s", svc.Name, svc.ID, err)
+				return time.After(minWait)
+			}
+		}
+		if err = zkservice.SyncServices(conn, svcs, tenantIDs); err != nil {
 			glog.Errorf("Could not do a local sync of services: %s", err)
 			return time.After(minWait)
 		} else {
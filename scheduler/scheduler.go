@@ -41,36 +41,38 @@ var (
 type leaderFunc func(<-chan interface{}, coordclient.Connection, dao.ControlPlane, *facade.Facade, string)
 
 type scheduler struct {
-	sync.Mutex                     // only one process can stop and start the scheduler at a time
-	cpDao         dao.ControlPlane // ControlPlane interface
-	poolID        string           // pool where the master resides
-	realm         string           // realm for which the scheduler will run
-	instance_id   string           // unique id for this node instance
-	shutdown      chan interface{} // Shuts down all the pools
-	started       bool             // is the loop running
-	zkleaderFunc  leaderFunc       // multiple implementations of leader function possible
-	snapshotTTL   int
-	facade        *facade.Facade
-	stopped       chan interface{}
-	storageServer *storage.Server
-	pushreg       *imgreg.RegistryListener
+	sync.Mutex                            // only one process can stop and start the scheduler at a time
+	cpDao                dao.ControlPlane // ControlPlane interface
+	poolID               string           // pool where the master resides
+	realm                string           // realm for which the scheduler will run
+	instance_id          string           // unique id for this node instance
+	shutdown             chan interface{} // Shuts down all the pools
+	started              bool             // is the loop running
+	zkleaderFunc         leaderFunc       // multiple implementations of leader function possible
+	snapshotTTL          int
+	snapshotSpacePercent int
+	facade               *facade.Facade
+	stopped              chan interface{}
+	storageServer        *storage.Server
+	pushreg              *imgreg.RegistryListener
 
 	conn coordclient.Connection
 }
 
 // NewScheduler creates a new scheduler master
-func NewScheduler(poolID string, instance_id string, storageServer *storage.Server, cpDao dao.ControlPlane, facade *facade.Facade, pushreg *imgreg.RegistryListener, snapshotTTL int) (*scheduler, error) {
+func NewScheduler(poolID string, instance_id string, storageServer *storage.Server, cpDao dao.ControlPlane, facade *facade.Facade, pushreg *imgreg.RegistryListener, snapshotTTL, snapshotSpacePercent int) (*scheduler, error) {
 	s := &scheduler{
-		cpDao:         cpDao,
-		poolID:        poolID,
-		instance_id:   instance_id,
-		shutdown:      make(chan interface{}),
-		stopped:       make(chan interface{}),
-		zkleaderFunc:  Lead, // random scheduler implementation
-		facade:        facade,
-		snapshotTTL:   snapshotTTL,
-		storageServer: storageServer,
-		pushreg:       pushreg,
+		cpDao:                cpDao,
+		poolID:               poolID,
+		instance_id:          instance_id,
+		shutdown:             make(chan interface{}),
+		stopped:              make(chan interface{}),
+		zkleaderFunc:         Lead, // random scheduler implementation
+		facade:               facade,
+		snapshotTTL:          snapshotTTL,
+		snapshotSpacePercent: snapshotSpacePercent,
+		storageServer:        storageServer,
+		pushreg:              pushreg,
 	}
 	return s, nil
 }
@@ -201,6 +203,14 @@ func (s *scheduler) mainloop(conn coordclient.Connection) {
 		}()
 	}
 
+	// kicks off the scheduled snapshot goroutine
+	wg.Add(1)
+	go func() {
+		defer glog.Infof("Stopping snapshot policies")
+		defer wg.Done()
+		ttl.RunSnapshotPolicies(s.facade, s.cpDao, _shutdown, s.snapshotSpacePercent)
+	}()
+
 	// wait for something to happen
 	for {
 		select {
@@ -26,9 +26,10 @@ import (
 
 // Verify we implement all the interfaces
 var (
-	_ strategy.Host          = &StrategyHost{}
-	_ strategy.ServiceConfig = &StrategyRunningService{}
-	_ strategy.ServiceConfig = &StrategyService{}
+	_ strategy.Host            = &StrategyHost{}
+	_ strategy.ServiceConfig   = &StrategyRunningService{}
+	_ strategy.ServiceConfig   = &StrategyService{}
+	_ strategy.PlacementConfig = &StrategyService{}
 )
 
 type StrategyHost struct {
@@ -94,6 +95,10 @@ func (h *StrategyHost) RunningServices() []strategy.ServiceConfig {
 	return h.services
 }
 
+func (h *StrategyHost) Labels() map[string]string {
+	return h.host.Labels
+}
+
 func (h *StrategyHost) TotalCores() int {
 	return h.host.Cores
 }
@@ -106,6 +111,14 @@ func (s *StrategyService) GetServiceID() string {
 	return s.svc.ID
 }
 
+func (s *StrategyService) GetServiceName() string {
+	return s.svc.Name
+}
+
+func (s *StrategyService) GetDeploymentID() string {
+	return s.svc.DeploymentID
+}
+
 func (s *StrategyService) RequestedCorePercent() int {
 	return s.svc.CPUCommitment
 }
@@ -118,10 +131,30 @@ func (s *StrategyService) HostPolicy() servicedefinition.HostPolicy {
 	return s.svc.HostPolicy
 }
 
+func (s *StrategyService) NodeSelector() map[string]string {
+	return s.svc.NodeSelector
+}
+
+func (s *StrategyService) Affinity() []servicedefinition.AffinityRule {
+	return s.svc.Affinity
+}
+
+func (s *StrategyService) AntiAffinity() []servicedefinition.AffinityRule {
+	return s.svc.AntiAffinity
+}
+
 func (s *StrategyRunningService) GetServiceID() string {
 	return s.svc.ServiceID
 }
 
+func (s *StrategyRunningService) GetServiceName() string {
+	return s.svc.Name
+}
+
+func (s *StrategyRunningService) GetDeploymentID() string {
+	return s.svc.DeploymentID
+}
+
 func (s *StrategyRunningService) RequestedCorePercent() int {
 	return s.svc.CPUCommitment
 }
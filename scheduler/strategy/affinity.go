@@ -0,0 +1,117 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strategy
+
+import (
+	"sort"
+
+	"github.com/zenoss/glog"
+)
+
+// AffinityStrategy filters out the hosts that do not match the node selector
+// or the required affinity rules of a service, and ranks the rest by the
+// number of preferred rules they meet.  The base strategy then chooses from
+// the best ranked hosts that have enough resources to run the service.
+type AffinityStrategy struct {
+	Base Strategy
+}
+
+func (s *AffinityStrategy) Name() string {
+	return s.Base.Name() + "+affinity"
+}
+
+func (s *AffinityStrategy) SelectHost(service ServiceConfig, hosts []Host) (Host, error) {
+	pc, ok := service.(PlacementConfig)
+	if !ok {
+		return s.Base.SelectHost(service, hosts)
+	}
+
+	// Group the eligible hosts by placement score
+	ranked := make(map[int][]Host)
+	eligible := []Host{}
+	for _, host := range hosts {
+		score, ok := PlacementScore(pc, host)
+		if !ok {
+			glog.V(2).Infof("Host %s does not meet the placement rules of service %s", host.HostID(), service.GetServiceID())
+			continue
+		}
+		ranked[score] = append(ranked[score], host)
+		eligible = append(eligible, host)
+	}
+	if len(eligible) == 0 {
+		glog.V(1).Infof("No hosts meet the placement rules of service %s", service.GetServiceID())
+		return nil, nil
+	}
+	scores := make([]int, 0, len(ranked))
+	for score := range ranked {
+		scores = append(scores, score)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(scores)))
+
+	// Use the best ranked hosts that can handle the service
+	for _, score := range scores {
+		if under, _ := ScoreHosts(service, ranked[score]); len(under) > 0 {
+			glog.V(2).Infof("Choosing from %d hosts with placement score %d for service %s", len(ranked[score]), score, service.GetServiceID())
+			return s.Base.SelectHost(service, ranked[score])
+		}
+	}
+
+	// Every eligible host would be oversubscribed
+	return s.Base.SelectHost(service, eligible)
+}
+
+// HasPlacementRules returns true if the service has a node selector or
+// affinity rules.
+func HasPlacementRules(service PlacementConfig) bool {
+	return len(service.NodeSelector()) > 0 || len(service.Affinity()) > 0 || len(service.AntiAffinity()) > 0
+}
+
+// PlacementScore returns false if the host cannot run the service because it
+// does not match the node selector or a required affinity rule.  Otherwise,
+// it returns the number of preferred affinity rules the host meets, less the
+// number of preferred anti-affinity rules it breaks.  Affinity rules refer to
+// the services with the same deployment as the service.
+func PlacementScore(service PlacementConfig, host Host) (int, bool) {
+	labels := host.Labels()
+	for key, value := range service.NodeSelector() {
+		if label, ok := labels[key]; !ok || label != value {
+			return 0, false
+		}
+	}
+
+	running := make(map[string]int)
+	for _, svc := range host.RunningServices() {
+		if svc.GetDeploymentID() == service.GetDeploymentID() {
+			running[svc.GetServiceName()]++
+		}
+	}
+
+	score := 0
+	for _, rule := range service.Affinity() {
+		if running[rule.Service] > 0 {
+			score++
+		} else if rule.Required {
+			return 0, false
+		}
+	}
+	for _, rule := range service.AntiAffinity() {
+		if running[rule.Service] > 0 {
+			if rule.Required {
+				return 0, false
+			}
+			score--
+		}
+	}
+	return score, true
+}
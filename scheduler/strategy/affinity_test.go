@@ -0,0 +1,136 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build unit
+
+package strategy_test
+
+import (
+	"github.com/control-center/serviced/domain/servicedefinition"
+	"github.com/control-center/serviced/scheduler/strategy"
+	"github.com/control-center/serviced/scheduler/strategy/mocks"
+	"github.com/control-center/serviced/utils"
+	. "gopkg.in/check.v1"
+)
+
+func newLabeledHost(cores int, memgigs uint64, labels map[string]string) *mocks.Host {
+	host := newHost(cores, memgigs)
+	host.On("Labels").Return(labels)
+	return host
+}
+
+func newNamedService(name string, cores int, memgigs uint64) *mocks.ServiceConfig {
+	svc := newService(cores, memgigs)
+	svc.On("GetServiceName").Return(name)
+	svc.On("GetDeploymentID").Return("deployment")
+	return svc
+}
+
+func newPlacedService(cores int, memgigs uint64, selector map[string]string, affinity, antiAffinity []servicedefinition.AffinityRule) *mocks.PlacementConfig {
+	id, _ := utils.NewUUID36()
+	svc := &mocks.PlacementConfig{}
+	svc.On("RequestedCorePercent").Return(cores * 100)
+	svc.On("RequestedMemoryBytes").Return(memgigs * Gigabyte)
+	svc.On("GetServiceID").Return(id)
+	svc.On("GetServiceName").Return("app")
+	svc.On("GetDeploymentID").Return("deployment")
+	svc.On("HostPolicy").Return(servicedefinition.HostPolicy(servicedefinition.Balance))
+	svc.On("NodeSelector").Return(selector)
+	svc.On("Affinity").Return(affinity)
+	svc.On("AntiAffinity").Return(antiAffinity)
+	return svc
+}
+
+func (s *StrategySuite) TestAffinity_ForService(c *C) {
+	svc := newPlacedService(1, 1, nil, nil, nil)
+	strat, err := strategy.ForService(svc)
+	c.Assert(err, IsNil)
+	c.Assert(strat, FitsTypeOf, &strategy.BalanceStrategy{})
+
+	svc = newPlacedService(1, 1, map[string]string{"disk": "ssd"}, nil, nil)
+	strat, err = strategy.ForService(svc)
+	c.Assert(err, IsNil)
+	c.Assert(strat, FitsTypeOf, &strategy.AffinityStrategy{})
+	c.Assert(strat.Name(), Equals, "balance+affinity")
+}
+
+func (s *StrategySuite) TestAffinity_NodeSelector(c *C) {
+	hostA := newLabeledHost(5, 5, map[string]string{"disk": "hdd"})
+	hostB := newLabeledHost(5, 5, map[string]string{"disk": "ssd"})
+	hostC := newLabeledHost(5, 5, nil)
+
+	// hostB is the busiest, but is the only host with an ssd
+	hostA.On("RunningServices").Return([]strategy.ServiceConfig{})
+	hostB.On("RunningServices").Return([]strategy.ServiceConfig{newNamedService("other", 3, 3)})
+	hostC.On("RunningServices").Return([]strategy.ServiceConfig{})
+
+	svc := newPlacedService(1, 1, map[string]string{"disk": "ssd"}, nil, nil)
+	strat := &strategy.AffinityStrategy{Base: &strategy.BalanceStrategy{}}
+	host, err := strat.SelectHost(svc, []strategy.Host{hostA, hostB, hostC})
+	c.Assert(err, IsNil)
+	c.Assert(host, Equals, hostB)
+
+	// no host matches
+	svc = newPlacedService(1, 1, map[string]string{"disk": "nvme"}, nil, nil)
+	host, err = strat.SelectHost(svc, []strategy.Host{hostA, hostB, hostC})
+	c.Assert(err, IsNil)
+	c.Assert(host, IsNil)
+}
+
+func (s *StrategySuite) TestAffinity_Preferred(c *C) {
+	hostA := newLabeledHost(5, 5, nil)
+	hostB := newLabeledHost(5, 5, nil)
+
+	hostA.On("RunningServices").Return([]strategy.ServiceConfig{})
+	hostB.On("RunningServices").Return([]strategy.ServiceConfig{newNamedService("cache", 2, 2)})
+
+	// prefer the host running the cache
+	svc := newPlacedService(1, 1, nil, []servicedefinition.AffinityRule{{Service: "cache"}}, nil)
+	strat := &strategy.AffinityStrategy{Base: &strategy.BalanceStrategy{}}
+	host, err := strat.SelectHost(svc, []strategy.Host{hostA, hostB})
+	c.Assert(err, IsNil)
+	c.Assert(host, Equals, hostB)
+
+	// unless it does not have the resources
+	svc = newPlacedService(4, 4, nil, []servicedefinition.AffinityRule{{Service: "cache"}}, nil)
+	host, err = strat.SelectHost(svc, []strategy.Host{hostA, hostB})
+	c.Assert(err, IsNil)
+	c.Assert(host, Equals, hostA)
+
+	// prefer the host not running the cache
+	svc = newPlacedService(1, 1, nil, nil, []servicedefinition.AffinityRule{{Service: "cache"}})
+	host, err = strat.SelectHost(svc, []strategy.Host{hostA, hostB})
+	c.Assert(err, IsNil)
+	c.Assert(host, Equals, hostA)
+}
+
+func (s *StrategySuite) TestAffinity_Required(c *C) {
+	hostA := newLabeledHost(5, 5, nil)
+	hostB := newLabeledHost(5, 5, nil)
+
+	hostA.On("RunningServices").Return([]strategy.ServiceConfig{})
+	hostB.On("RunningServices").Return([]strategy.ServiceConfig{newNamedService("cache", 2, 2)})
+
+	// the host running the cache is the only choice, even if oversubscribed
+	svc := newPlacedService(4, 4, nil, []servicedefinition.AffinityRule{{Service: "cache", Required: true}}, nil)
+	strat := &strategy.AffinityStrategy{Base: &strategy.BalanceStrategy{}}
+	host, err := strat.SelectHost(svc, []strategy.Host{hostA, hostB})
+	c.Assert(err, IsNil)
+	c.Assert(host, Equals, hostB)
+
+	// never run on a host with the cache
+	svc = newPlacedService(1, 1, nil, nil, []servicedefinition.AffinityRule{{Service: "cache", Required: true}})
+	host, err = strat.SelectHost(svc, []strategy.Host{hostB})
+	c.Assert(err, IsNil)
+	c.Assert(host, IsNil)
+}
@@ -0,0 +1,82 @@
+package mocks
+
+import "github.com/stretchr/testify/mock"
+
+import "github.com/control-center/serviced/domain/servicedefinition"
+
+type PlacementConfig struct {
+	mock.Mock
+}
+
+func (m *PlacementConfig) GetServiceID() string {
+	ret := m.Called()
+
+	r0 := ret.Get(0).(string)
+
+	return r0
+}
+func (m *PlacementConfig) GetServiceName() string {
+	ret := m.Called()
+
+	r0 := ret.Get(0).(string)
+
+	return r0
+}
+func (m *PlacementConfig) GetDeploymentID() string {
+	ret := m.Called()
+
+	r0 := ret.Get(0).(string)
+
+	return r0
+}
+func (m *PlacementConfig) RequestedCorePercent() int {
+	ret := m.Called()
+
+	r0 := ret.Get(0).(int)
+
+	return r0
+}
+func (m *PlacementConfig) RequestedMemoryBytes() uint64 {
+	ret := m.Called()
+
+	r0 := ret.Get(0).(uint64)
+
+	return r0
+}
+func (m *PlacementConfig) HostPolicy() servicedefinition.HostPolicy {
+	ret := m.Called()
+
+	r0 := ret.Get(0).(servicedefinition.HostPolicy)
+
+	return r0
+}
+func (m *PlacementConfig) NodeSelector() map[string]string {
+	ret := m.Called()
+
+	var r0 map[string]string
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).(map[string]string)
+	}
+
+	return r0
+}
+func (m *PlacementConfig) Affinity() []servicedefinition.AffinityRule {
+	ret := m.Called()
+
+	var r0 []servicedefinition.AffinityRule
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).([]servicedefinition.AffinityRule)
+	}
+
+	return r0
+}
+func (m *PlacementConfig) AntiAffinity() []servicedefinition.AffinityRule {
+	ret := m.Called()
+
+	var r0 []servicedefinition.AffinityRule
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).([]servicedefinition.AffinityRule)
+	}
+
+	return r0
+}
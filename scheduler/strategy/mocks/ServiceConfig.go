@@ -15,6 +15,20 @@ func (m *ServiceConfig) GetServiceID() string {
 
 	return r0
 }
+func (m *ServiceConfig) GetServiceName() string {
+	ret := m.Called()
+
+	r0 := ret.Get(0).(string)
+
+	return r0
+}
+func (m *ServiceConfig) GetDeploymentID() string {
+	ret := m.Called()
+
+	r0 := ret.Get(0).(string)
+
+	return r0
+}
 func (m *ServiceConfig) RequestedCorePercent() int {
 	ret := m.Called()
 
@@ -23,6 +23,7 @@ import (
 var (
 	strategies        []Strategy
 	ErrNoSuchStrategy = errors.New("no such scheduler strategy")
+	ErrStrategyExists = errors.New("scheduler strategy already exists")
 )
 
 func init() {
@@ -39,15 +40,27 @@ type Host interface {
 	TotalCores() int
 	TotalMemory() uint64
 	RunningServices() []ServiceConfig
+	Labels() map[string]string
 }
 
 type ServiceConfig interface {
 	GetServiceID() string
+	GetServiceName() string
+	GetDeploymentID() string
 	RequestedCorePercent() int
 	RequestedMemoryBytes() uint64
 	HostPolicy() servicedefinition.HostPolicy
 }
 
+// PlacementConfig is implemented by services that constrain the hosts their
+// instances may run on.
+type PlacementConfig interface {
+	ServiceConfig
+	NodeSelector() map[string]string
+	Affinity() []servicedefinition.AffinityRule
+	AntiAffinity() []servicedefinition.AffinityRule
+}
+
 type Strategy interface {
 	// The name of this strategy
 	Name() string
@@ -55,6 +68,15 @@ type Strategy interface {
 	SelectHost(svc ServiceConfig, hosts []Host) (Host, error)
 }
 
+// Register adds a strategy that can be selected by its name
+func Register(strategy Strategy) error {
+	if _, err := Get(strategy.Name()); err == nil {
+		return ErrStrategyExists
+	}
+	strategies = append(strategies, strategy)
+	return nil
+}
+
 func Get(name string) (Strategy, error) {
 	// Default to servicedefinition.Balance
 	if len(name) == 0 {
@@ -67,3 +89,17 @@ func Get(name string) (Strategy, error) {
 	}
 	return nil, ErrNoSuchStrategy
 }
+
+// ForService returns the strategy for the host policy of the service.  If the
+// service has placement rules, hosts are filtered and ranked by those rules
+// before the strategy chooses one.
+func ForService(svc ServiceConfig) (Strategy, error) {
+	strategy, err := Get(string(svc.HostPolicy()))
+	if err != nil {
+		return nil, err
+	}
+	if pc, ok := svc.(PlacementConfig); ok && HasPlacementRules(pc) {
+		return &AffinityStrategy{Base: strategy}, nil
+	}
+	return strategy, nil
+}
@@ -94,15 +94,14 @@ func evalSvcWait(r *runner, n node) error {
 		return fmt.Errorf("no service tenant id specified for %s", SVC_WAIT)
 	}
 
+	svcPaths, state, timeout, recursive, err := parseWaitArgs(n.args)
+	if err != nil {
+		return err
+	}
+
 	var svcIDs []string
-	var stateIdx int
-	for i, arg := range n.args {
-		if arg == "started" || arg == "stopped" || arg == "paused" {
-			stateIdx = i
-			break
-		}
-		svcPath := arg
-		svcID, err := r.svcFromPath(tenantID, arg)
+	for _, svcPath := range svcPaths {
+		svcID, err := r.svcFromPath(tenantID, svcPath)
 		if err != nil {
 			return err
 		}
@@ -112,41 +111,51 @@ func evalSvcWait(r *runner, n node) error {
 		svcIDs = append(svcIDs, svcID)
 	}
 
-	state := ServiceState(n.args[stateIdx])
+	plog.WithFields(log.Fields{
+		"timeout": timeout,
+		"services": strings.Join(svcPaths, ", "),
+		"targetstate": state,
+	}).Info("Waiting for service(s) to reach target state")
+	if err := r.svcWait(svcIDs, state, timeout, recursive); err != nil {
+		return err
+	}
+
+	return nil
+}
 
-	timeout := uint32(0)
-	recursive := false
+// parseWaitArgs returns the service paths, target state, timeout and
+// recursive flag of an SVC_WAIT node.
+// SVC_WAIT <svcs>+ (started|stopped|paused) (<timeout>|recursive)? (recursive)?
+func parseWaitArgs(args []string) (svcPaths []string, state ServiceState, timeout uint32, recursive bool, err error) {
+	var stateIdx int
+	for i, arg := range args {
+		if arg == "started" || arg == "stopped" || arg == "paused" {
+			stateIdx = i
+			break
+		}
+		svcPaths = append(svcPaths, arg)
+	}
 
-	if len(n.args) > stateIdx+1 {
+	state = ServiceState(args[stateIdx])
+
+	if len(args) > stateIdx+1 {
 		// check optional first arg
-		if n.args[stateIdx+1] == "recursive" {
+		if args[stateIdx+1] == "recursive" {
 			recursive = true
 		} else {
 			var timeout64 uint64
-			var err error
-			lastArg := n.args[len(n.args)-1]
-			if timeout64, err = strconv.ParseUint(n.args[stateIdx+1], 10, 32); err != nil {
-				return fmt.Errorf("Unable to parse timeout value %s: %s", lastArg, err)
+			lastArg := args[len(args)-1]
+			if timeout64, err = strconv.ParseUint(args[stateIdx+1], 10, 32); err != nil {
+				return nil, "", 0, false, fmt.Errorf("Unable to parse timeout value %s: %s", lastArg, err)
 			}
 			timeout = uint32(timeout64)
 		}
 		// check optional second arg (data should be sanitized prior to here)
-		if len(n.args) == stateIdx+3 {
+		if len(args) == stateIdx+3 {
 			recursive = true
 		}
-
 	}
-
-	plog.WithFields(log.Fields{
-		"timeout": timeout,
-		"services": strings.Join(n.args[:stateIdx], ", "),
-		"targetstate": state,
-	}).Info("Waiting for service(s) to reach target state")
-	if err := r.svcWait(svcIDs, state, timeout, recursive); err != nil {
-		return err
-	}
-
-	return nil
+	return svcPaths, state, timeout, recursive, nil
 }
 
 func evalSvcStart(r *runner, n node) error {
@@ -374,3 +383,51 @@ func evalRequireSvc(r *runner, n node) error {
 	r.env["TENANT_ID"] = tID
 	return nil
 }
+
+func evalSet(r *runner, n node) error {
+	plog.WithFields(log.Fields{
+		"variable": n.args[0],
+		"value":    n.args[1],
+	}).Debug("setting variable")
+	r.env[n.args[0]] = n.args[1]
+	return nil
+}
+
+// evalIfSvc returns true if the service is in the state given by the IF_SVC
+// node.
+func evalIfSvc(r *runner, n node) (bool, error) {
+	if r.svcFromPath == nil {
+		return false, fmt.Errorf("no service id lookup function for %s", IF_SVC)
+	}
+
+	if r.svcState == nil {
+		return false, fmt.Errorf("no service state function for %s", IF_SVC)
+	}
+
+	svcPath := n.args[0]
+	tenantID, found := r.env["TENANT_ID"]
+	if !found {
+		return false, fmt.Errorf("no service tenant id specified for %s", IF_SVC)
+	}
+	svcID, err := r.svcFromPath(tenantID, svcPath)
+	if err != nil {
+		return false, err
+	}
+	if svcID == "" {
+		return false, fmt.Errorf("no service id found for %s", svcPath)
+	}
+
+	state, err := r.svcState(svcID)
+	if err != nil {
+		return false, err
+	}
+	ok := state == ServiceState(n.args[1])
+	plog.WithFields(log.Fields{
+		"servicepath": svcPath,
+		"serviceid":   svcID,
+		"state":       state,
+		"condition":   n.args[1],
+		"result":      ok,
+	}).Info("Evaluated service state condition")
+	return ok, nil
+}
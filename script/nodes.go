@@ -28,6 +28,11 @@ var (
 	SVC_RESTART = "SVC_RESTART"
 	SVC_WAIT    = "SVC_WAIT"
 	DEPENDENCY  = "DEPENDENCY"
+	SET         = "SET"
+	IF_SVC      = "IF_SVC"
+	ELSE        = "ELSE"
+	END         = "END"
+	ON_ERROR    = "ON_ERROR"
 
 	EMPTY     = "EMPTY"
 	emptyNode = node{cmd: EMPTY}
@@ -52,16 +57,24 @@ func init() {
 		SVC_STOP:    require([]string{REQUIRE_SVC}, parseArgMatch(1, "^recurse$|^auto$", true, parseArgCount(bounds(1, 2), buildNode))),
 		SVC_WAIT:    require([]string{REQUIRE_SVC}, parseWaitCmd(parseArgsUntil("^started$|^stopped$|^paused$", parseArgMatch(0, "^started$|^stopped$|^paused$", false, parseArgCount(max(3), buildNode))))),
 		DEPENDENCY:  validParents([]string{DESCRIPTION, VERSION}, atMost(1, parseArgCount(equals(1), buildNode))),
+		SET:         parseVarName(parseArgCount(equals(2), buildNode)),
+		IF_SVC:      require([]string{REQUIRE_SVC}, openBlock(parseArgMatch(1, "^started$|^stopped$|^paused$", false, parseArgCount(equals(2), buildNode)))),
+		ELSE:        elseBlock(parseArgCount(equals(0), buildNode)),
+		END:         closeBlock(parseArgCount(equals(0), buildNode)),
+		ON_ERROR:    atMost(1, openBlock(parseArgCount(equals(0), buildNode))),
 	}
 }
 
 // node is the struct created from parsing a line; cmd is the command on the line, args are the remainder of the line, line is
-// the original line and lineNum is the line number where the line occurred.
+// the original line and lineNum is the line number where the line occurred.  For block commands (IF_SVC, ON_ERROR), body
+// and elseBody hold the nested nodes once the runner has built the script tree.
 type node struct {
-	cmd     string
-	args    []string
-	line    string
-	lineNum int
+	cmd      string
+	args     []string
+	line     string
+	lineNum  int
+	body     []node
+	elseBody []node
 }
 
 type lineParser func(*parseContext, string, []string) (node, error)
@@ -146,6 +159,10 @@ func parseArgMatch(argN int, pattern string, optional bool, parser lineParser) l
 		n, err := parser(ctx, cmd, args)
 		if err == nil {
 			if argN < len(args) {
+				// args with variable references are validated after expansion
+				if hasVars(args[argN]) {
+					return n, nil
+				}
 				//try to match
 				if matched, err := regexp.MatchString(pattern, args[argN]); !matched {
 					return node{}, fmt.Errorf("line %d: arg %s did not match %s", ctx.lineNum, args[argN], pattern)
@@ -205,12 +222,17 @@ func parseImageID(parser lineParser) lineParser {
 	return func(ctx *parseContext, cmd string, args []string) (node, error) {
 		n, err := parser(ctx, cmd, args)
 		if err == nil {
-			_, err := commons.ParseImageID(args[0])
-			if err != nil {
-				return node{}, err
+			// args with variable references are validated after expansion
+			if !hasVars(args[0]) {
+				if _, err := commons.ParseImageID(args[0]); err != nil {
+					return node{}, err
+				}
 			}
 			if len(args) >= 2 {
 				for _, tgtImg := range args[1:] {
+					if hasVars(tgtImg) {
+						continue
+					}
 					image, err := commons.ParseImageID(tgtImg)
 					if err != nil {
 						return node{}, err
@@ -322,3 +344,73 @@ func atMost(n int, parser lineParser) lineParser {
 	}
 	return f
 }
+
+// parseVarName checks that the first arg is a valid variable name that may be
+// assigned by the script, eg., SET IMAGE zenoss/core:5.2.0
+func parseVarName(parser lineParser) lineParser {
+	return func(ctx *parseContext, cmd string, args []string) (node, error) {
+		n, err := parser(ctx, cmd, args)
+		if err == nil {
+			if !varNameRegex.MatchString(args[0]) {
+				return node{}, fmt.Errorf("line %d: invalid variable name %s", ctx.lineNum, args[0])
+			}
+			if args[0] == "TENANT_ID" {
+				return node{}, fmt.Errorf("line %d: variable %s is reserved", ctx.lineNum, args[0])
+			}
+		}
+		return n, err
+	}
+}
+
+// openBlock starts a new IF_SVC or ON_ERROR block that must be closed by END,
+// eg., IF_SVC Zenoss.core/mariadb started.  ON_ERROR blocks may only be
+// declared at the top level of the script.
+func openBlock(parser lineParser) lineParser {
+	return func(ctx *parseContext, cmd string, args []string) (node, error) {
+		n, err := parser(ctx, cmd, args)
+		if err == nil {
+			if cmd == ON_ERROR && len(ctx.blocks) > 0 {
+				ctx.addErrorf("line %d: %s cannot be nested inside %s", ctx.lineNum, cmd, ctx.blocks[len(ctx.blocks)-1].open.cmd)
+			}
+			ctx.blocks = append(ctx.blocks, &block{open: n})
+		}
+		return n, err
+	}
+}
+
+// elseBlock checks that ELSE belongs to an open IF_SVC block that does not
+// already have an ELSE.
+func elseBlock(parser lineParser) lineParser {
+	return func(ctx *parseContext, cmd string, args []string) (node, error) {
+		n, err := parser(ctx, cmd, args)
+		if err == nil {
+			if len(ctx.blocks) == 0 {
+				ctx.addErrorf("line %d: %s without %s", ctx.lineNum, cmd, IF_SVC)
+				return n, nil
+			}
+			b := ctx.blocks[len(ctx.blocks)-1]
+			if b.open.cmd != IF_SVC {
+				ctx.addErrorf("line %d: %s not allowed in %s", ctx.lineNum, cmd, b.open.cmd)
+			} else if b.hasElse {
+				ctx.addErrorf("line %d: extra %s for %s on line %d", ctx.lineNum, cmd, IF_SVC, b.open.lineNum)
+			}
+			b.hasElse = true
+		}
+		return n, err
+	}
+}
+
+// closeBlock checks that END closes an open block.
+func closeBlock(parser lineParser) lineParser {
+	return func(ctx *parseContext, cmd string, args []string) (node, error) {
+		n, err := parser(ctx, cmd, args)
+		if err == nil {
+			if len(ctx.blocks) == 0 {
+				ctx.addErrorf("line %d: %s without %s or %s", ctx.lineNum, cmd, IF_SVC, ON_ERROR)
+				return n, nil
+			}
+			ctx.blocks = ctx.blocks[:len(ctx.blocks)-1]
+		}
+		return n, err
+	}
+}
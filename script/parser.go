@@ -20,6 +20,13 @@ type parseContext struct {
 	line    string
 	errors  []error
 	nodes   []node
+	blocks  []*block // blocks that have not yet been closed by END
+}
+
+// block tracks an open IF_SVC or ON_ERROR block while parsing
+type block struct {
+	open    node
+	hasElse bool
 }
 
 func newParseContext() *parseContext {
@@ -49,6 +56,9 @@ func parseDescriptor(r io.Reader) (*parseContext, error) {
 	if err := ForEachLine(r, parse); err != nil {
 		return nil, err
 	}
+	for _, b := range ctx.blocks {
+		ctx.addErrorf("line %d: %s is missing %s", b.open.lineNum, b.open.cmd, END)
+	}
 	return ctx, nil
 }
 
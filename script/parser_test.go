@@ -191,3 +191,74 @@ func (vs *ScriptSuite) Test_parseBadLines(t *C) {
 		t.Assert(err, ErrorMatches, "invalid command line string")
 	}
 }
+
+func (vs *ScriptSuite) Test_parseBlocks(t *C) {
+	testDescriptor := `
+DESCRIPTION  conditional upgrade
+REQUIRE_SVC
+SET IMAGE zenoss/core:5.2.0
+IF_SVC Zenoss.core/mariadb started
+SVC_STOP Zenoss.core/mariadb
+ELSE
+SVC_START Zenoss.core/mariadb
+END
+SVC_USE ${IMAGE}
+ON_ERROR
+SVC_START Zenoss.core/mariadb
+END
+`
+	r := strings.NewReader(testDescriptor)
+	ctx, err := parseDescriptor(r)
+	t.Assert(err, IsNil)
+	t.Assert(ctx.errors, HasLen, 0)
+	t.Assert(ctx.blocks, HasLen, 0)
+	t.Assert(ctx.nodes, HasLen, 12)
+
+	steps, _ := nestNodes(ctx.nodes, 0)
+	t.Assert(steps, HasLen, 6)
+	t.Assert(steps[3].cmd, Equals, IF_SVC)
+	t.Assert(steps[3].body, HasLen, 1)
+	t.Assert(steps[3].body[0].cmd, Equals, SVC_STOP)
+	t.Assert(steps[3].elseBody, HasLen, 1)
+	t.Assert(steps[3].elseBody[0].cmd, Equals, SVC_START)
+	t.Assert(steps[4].cmd, Equals, USE)
+	t.Assert(steps[5].cmd, Equals, ON_ERROR)
+	t.Assert(steps[5].body, HasLen, 1)
+}
+
+func (vs *ScriptSuite) Test_parseBlockErrors(t *C) {
+	testDescriptor := `
+REQUIRE_SVC
+ELSE
+END
+IF_SVC Zenoss.core/mariadb started
+ELSE
+ELSE
+ON_ERROR
+END
+END
+ON_ERROR
+IF_SVC Zenoss.core started
+`
+	r := strings.NewReader(testDescriptor)
+	ctx, err := parseDescriptor(r)
+	t.Assert(err, IsNil)
+	t.Assert(len(ctx.errors), Equals, 7)
+	t.Assert(ctx.errors[0], ErrorMatches, "line 3: ELSE without IF_SVC")
+	t.Assert(ctx.errors[1], ErrorMatches, "line 4: END without IF_SVC or ON_ERROR")
+	t.Assert(ctx.errors[2], ErrorMatches, "line 7: extra ELSE for IF_SVC on line 5")
+	t.Assert(ctx.errors[3], ErrorMatches, "line 8: ON_ERROR cannot be nested inside IF_SVC")
+	t.Assert(ctx.errors[4], ErrorMatches, "line 11: extra ON_ERROR: ON_ERROR")
+	t.Assert(ctx.errors[5], ErrorMatches, "line 11: ON_ERROR is missing END")
+	t.Assert(ctx.errors[6], ErrorMatches, "line 12: IF_SVC is missing END")
+}
+
+func (vs *ScriptSuite) Test_parseSet(t *C) {
+	for _, line := range []string{"SET 1ABC foo", "SET A-B foo", "SET TENANT_ID foo", "SET A", "SET A b c"} {
+		_, err := parseDescriptor(strings.NewReader(line))
+		t.Assert(err, NotNil, Commentf("line %s", line))
+	}
+	ctx, err := parseDescriptor(strings.NewReader("SET VERSION_1 \"5.2 beta\""))
+	t.Assert(err, IsNil)
+	t.Assert(ctx.nodes, DeepEquals, []node{{cmd: SET, args: []string{"VERSION_1", "5.2 beta"}, line: "SET VERSION_1 \"5.2 beta\"", lineNum: 1}})
+}
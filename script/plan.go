@@ -0,0 +1,487 @@
+// Copyright 2018, The Serviced Authors. All rights reserved.
+// Use of this source code is governed by a
+// license that can be found in the LICENSE file.
+
+package script
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/control-center/serviced/commons"
+	"github.com/control-center/serviced/commons/docker"
+	"github.com/control-center/serviced/domain/registry"
+	"github.com/control-center/serviced/domain/service"
+	"github.com/docker/go-units"
+)
+
+var (
+	cmdPlan map[string]func(*planner, node, *PlanStep) error
+
+	// ErrNoTenant is returned when a step requires a tenant and REQUIRE_SVC
+	// has not been evaluated.
+	ErrNoTenant = errors.New("no service tenant id specified")
+)
+
+func init() {
+	cmdPlan = map[string]func(*planner, node, *PlanStep) error{
+		DESCRIPTION: planEmpty,
+		VERSION:     planEmpty,
+		DEPENDENCY:  planEmpty,
+		REQUIRE_SVC: planRequireSvc,
+		SNAPSHOT:    planSnapshot,
+		USE:         planUSE,
+		SVC_RUN:     planSvcRun,
+		SVC_EXEC:    planSvcExec,
+		SVC_START:   planSvcControl("start", "started"),
+		SVC_STOP:    planSvcControl("stop", "stopped"),
+		SVC_RESTART: planSvcControl("restart", "started"),
+		SVC_WAIT:    planSvcWait,
+		SET:         planSet,
+	}
+}
+
+// Plan is the report of what a script would do if it was run
+type Plan struct {
+	TenantID string
+	Steps    []PlanStep
+}
+
+// HasErrors returns true if any step of the plan would fail
+func (p *Plan) HasErrors() bool {
+	for _, step := range p.Steps {
+		if step.Error != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// PlanStep describes the expected outcome of a single line of a script
+type PlanStep struct {
+	LineNum  int
+	Line     string
+	Command  string
+	Depth    int           // nesting depth of the line inside IF_SVC and ON_ERROR blocks
+	Skipped  bool          `json:",omitempty"` // the line is in a branch that would not be taken
+	OnError  bool          `json:",omitempty"` // the line only runs if an earlier line fails
+	Summary  string        `json:",omitempty"`
+	Services []string      `json:",omitempty"` // paths of the services affected by the line
+	Images   []PlanImage   `json:",omitempty"`
+	Snapshot *PlanSnapshot `json:",omitempty"`
+	Warnings []string      `json:",omitempty"`
+	Error    string        `json:",omitempty"`
+}
+
+// PlanImage describes an image that would be pulled or a service whose image
+// would be replaced by SVC_USE
+type PlanImage struct {
+	ServicePath string `json:",omitempty"`
+	From        string `json:",omitempty"`
+	To          string
+}
+
+// PlanSnapshot describes a snapshot that would be taken
+type PlanSnapshot struct {
+	TenantID      string
+	Tag           string `json:",omitempty"`
+	EstimatedSize uint64 // bytes; 0 if the size could not be determined
+}
+
+// planState tracks the state of the tenant's services as the steps of the
+// script are applied, so that later steps are evaluated against the changes
+// made by earlier ones.
+type planState struct {
+	services map[string]*planService // by service id
+	paths    map[string]string       // lower case service path to service id
+}
+
+type planService struct {
+	service.ServiceDetails
+	path     string
+	state    ServiceState
+	children planServices
+}
+
+// planServices sorts services by path
+type planServices []*planService
+
+func (s planServices) Len() int           { return len(s) }
+func (s planServices) Less(i, j int) bool { return s[i].path < s[j].path }
+func (s planServices) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+func newPlanState(svcs []service.ServiceDetails) *planState {
+	ps := &planState{
+		services: make(map[string]*planService),
+		paths:    make(map[string]string),
+	}
+	for _, svc := range svcs {
+		state, err := DesiredStateToScriptState(service.DesiredState(svc.DesiredState))
+		if err != nil {
+			// a pending restart leaves the service running
+			state = "started"
+		}
+		ps.services[svc.ID] = &planService{ServiceDetails: svc, state: state}
+	}
+	for _, svc := range ps.services {
+		fullpath := svc.Name
+		for parentID := svc.ParentServiceID; parentID != ""; {
+			parent, ok := ps.services[parentID]
+			if !ok {
+				break
+			}
+			fullpath = path.Join(parent.Name, fullpath)
+			parentID = parent.ParentServiceID
+		}
+		svc.path = fullpath
+		ps.paths[strings.ToLower(fullpath)] = svc.ID
+		if parent, ok := ps.services[svc.ParentServiceID]; ok {
+			parent.children = append(parent.children, svc)
+		}
+	}
+	for _, svc := range ps.services {
+		sort.Sort(svc.children)
+	}
+	return ps
+}
+
+// find returns the service at the given path
+func (ps *planState) find(svcPath string) (*planService, error) {
+	if id, ok := ps.paths[strings.ToLower(svcPath)]; ok {
+		return ps.services[id], nil
+	}
+	return nil, fmt.Errorf("did not find service %s", svcPath)
+}
+
+// walk calls visit on the service and, if recursive, all of its descendants
+func (ps *planState) walk(svc *planService, recursive bool, visit func(*planService)) {
+	visit(svc)
+	if recursive {
+		for _, child := range svc.children {
+			ps.walk(child, recursive, visit)
+		}
+	}
+}
+
+// sorted returns all of the services ordered by path
+func (ps *planState) sorted() planServices {
+	svcs := make(planServices, 0, len(ps.services))
+	for _, svc := range ps.services {
+		svcs = append(svcs, svc)
+	}
+	sort.Sort(svcs)
+	return svcs
+}
+
+// planner builds a Plan by evaluating the nodes of a runner without side
+// effects.
+type planner struct {
+	*runner
+	plan  *Plan
+	state *planState // nil until the tenant is known
+}
+
+// Plan resolves each step of the script against the current state of the
+// system without modifying it and reports what the step would do.
+func (r *runner) Plan() (*Plan, error) {
+	if r.tenantServices == nil {
+		return nil, errors.New("no tenant service list function provided for plan")
+	}
+	p := &planner{runner: r, plan: &Plan{}}
+	p.planSteps(r.nodes, 0, false, false)
+	p.planSteps(r.onError, 0, false, true)
+	p.plan.TenantID = p.env["TENANT_ID"]
+	return p.plan, nil
+}
+
+// planSteps adds a step to the plan for each node.  Steps that are skipped
+// are still reported, but are not applied to the plan state.
+func (p *planner) planSteps(nodes []node, depth int, skipped, onError bool) {
+	for _, n := range nodes {
+		step := PlanStep{
+			LineNum: n.lineNum,
+			Line:    n.line,
+			Command: n.cmd,
+			Depth:   depth,
+			Skipped: skipped,
+			OnError: onError,
+		}
+		if skipped {
+			p.plan.Steps = append(p.plan.Steps, step)
+			if n.cmd == IF_SVC {
+				p.planSteps(n.body, depth+1, true, onError)
+				p.planSteps(n.elseBody, depth+1, true, onError)
+			}
+			continue
+		}
+
+		expanded, err := expandNode(n, p.env)
+		if err != nil {
+			step.Error = err.Error()
+			p.plan.Steps = append(p.plan.Steps, step)
+			continue
+		}
+		step.Line = expanded.line
+
+		if err := p.loadState(); err != nil {
+			step.Error = err.Error()
+			p.plan.Steps = append(p.plan.Steps, step)
+			continue
+		}
+
+		if n.cmd == IF_SVC {
+			ok, err := planIfSvc(p, expanded, &step)
+			if err != nil {
+				step.Error = err.Error()
+			}
+			p.plan.Steps = append(p.plan.Steps, step)
+			p.planSteps(n.body, depth+1, err != nil || !ok, onError)
+			p.planSteps(n.elseBody, depth+1, err != nil || ok, onError)
+			continue
+		}
+
+		if f, found := cmdPlan[n.cmd]; found {
+			if err := f(p, expanded, &step); err != nil {
+				step.Error = err.Error()
+			}
+		} else {
+			step.Summary = "unknown command, would be skipped"
+		}
+		p.plan.Steps = append(p.plan.Steps, step)
+	}
+}
+
+// loadState looks up the tenant's services once the tenant is known
+func (p *planner) loadState() error {
+	if p.state != nil {
+		return nil
+	}
+	tenantID, found := p.env["TENANT_ID"]
+	if !found {
+		return nil
+	}
+	svcs, err := p.tenantServices(tenantID)
+	if err != nil {
+		return err
+	}
+	p.state = newPlanState(svcs)
+	return nil
+}
+
+func planEmpty(p *planner, n node, step *PlanStep) error {
+	return nil
+}
+
+func planRequireSvc(p *planner, n node, step *PlanStep) error {
+	if err := evalRequireSvc(p.runner, n); err != nil {
+		return err
+	}
+	step.Summary = fmt.Sprintf("tenant %s", p.env["TENANT_ID"])
+	return nil
+}
+
+func planSet(p *planner, n node, step *PlanStep) error {
+	if err := evalSet(p.runner, n); err != nil {
+		return err
+	}
+	step.Summary = fmt.Sprintf("%s=%s", n.args[0], n.args[1])
+	return nil
+}
+
+func planSnapshot(p *planner, n node, step *PlanStep) error {
+	tenantID, found := p.env["TENANT_ID"]
+	if !found {
+		return ErrNoTenant
+	}
+	snapshot := &PlanSnapshot{TenantID: tenantID}
+	if len(n.args) == 1 {
+		snapshot.Tag = n.args[0]
+	}
+	step.Snapshot = snapshot
+	step.Summary = fmt.Sprintf("snapshot tenant %s, rolled back if a later step fails", tenantID)
+	if p.snapshotSize == nil {
+		step.Warnings = append(step.Warnings, "snapshot size could not be estimated")
+		return nil
+	}
+	size, err := p.snapshotSize(tenantID)
+	if err != nil {
+		step.Warnings = append(step.Warnings, fmt.Sprintf("snapshot size could not be estimated: %s", err))
+		return nil
+	}
+	snapshot.EstimatedSize = size
+	step.Summary = fmt.Sprintf("%s (up to %s)", step.Summary, units.BytesSize(float64(size)))
+	return nil
+}
+
+// planUSE reports the image that would be pulled into the registry and the
+// services that would be updated to use it, following Facade.ServiceUse.
+func planUSE(p *planner, n node, step *PlanStep) error {
+	if p.state == nil {
+		return ErrNoTenant
+	}
+	tenantID := p.env["TENANT_ID"]
+	imageName := n.args[0]
+	newImg, err := commons.ParseImageID(imageName)
+	if err != nil {
+		return err
+	}
+	rImage := (&registry.Image{
+		Library: tenantID,
+		Repo:    newImg.Repo,
+		Tag:     docker.DockerLatest,
+	}).String()
+	step.Images = append(step.Images, PlanImage{From: imageName, To: path.Join(p.config.DockerRegistry, rImage)})
+	step.Summary = fmt.Sprintf("pull %s and push it to the registry", imageName)
+
+	srchImgs := make(map[string]struct{})
+	for _, replaceImg := range n.args[1:] {
+		img, err := commons.ParseImageID(replaceImg)
+		if err != nil {
+			return err
+		}
+		srchImgs[img.Repo] = struct{}{}
+	}
+	if len(srchImgs) == 0 {
+		return nil
+	}
+
+	for _, svc := range p.state.sorted() {
+		if svc.ImageID == "" {
+			continue
+		}
+		origImg, err := commons.ParseImageID(svc.ImageID)
+		if err != nil {
+			return fmt.Errorf("error parsing image ID %s: %s", svc.ImageID, err)
+		}
+		if _, ok := srchImgs[origImg.Repo]; !ok {
+			continue
+		}
+		origImg.Merge(&commons.ImageID{Repo: newImg.Repo})
+		step.Images = append(step.Images, PlanImage{ServicePath: svc.path, From: svc.ImageID, To: origImg.String()})
+		step.Services = append(step.Services, svc.path)
+		svc.ImageID = origImg.String()
+	}
+	if len(step.Services) == 0 {
+		step.Warnings = append(step.Warnings, fmt.Sprintf("no services use the images %s", strings.Join(n.args[1:], ", ")))
+	} else {
+		step.Summary = fmt.Sprintf("%s; update the image of %d service(s)", step.Summary, len(step.Services))
+	}
+	return nil
+}
+
+// planSvcControl reports the services that would be scheduled by SVC_START,
+// SVC_STOP or SVC_RESTART, following Facade.ScheduleServices.
+func planSvcControl(action string, target ServiceState) func(*planner, node, *PlanStep) error {
+	return func(p *planner, n node, step *PlanStep) error {
+		if p.state == nil {
+			return ErrNoTenant
+		}
+		svc, err := p.state.find(n.args[0])
+		if err != nil {
+			return err
+		}
+		recursive := len(n.args) > 1
+		var blocked []string
+		p.state.walk(svc, recursive, func(s *planService) {
+			explicit := s.ID == svc.ID
+			if s.Launch == commons.MANUAL && !explicit && s.CurrentState == string(service.SVCCSStopped) {
+				return
+			}
+			if target != "stopped" && s.EmergencyShutdown {
+				blocked = append(blocked, s.path)
+				return
+			}
+			step.Services = append(step.Services, s.path)
+			s.state = target
+		})
+		if len(blocked) > 0 {
+			return fmt.Errorf("cannot %s services in emergency shutdown: %s", action, strings.Join(blocked, ", "))
+		}
+		step.Summary = fmt.Sprintf("%s %d service(s)", action, len(step.Services))
+		return nil
+	}
+}
+
+// planSvcWait checks whether the services would reach the target state,
+// given the state they are expected to be in when the step is reached.
+func planSvcWait(p *planner, n node, step *PlanStep) error {
+	if p.state == nil {
+		return ErrNoTenant
+	}
+	svcPaths, state, timeout, recursive, err := parseWaitArgs(n.args)
+	if err != nil {
+		return err
+	}
+	var unreachable []string
+	for _, svcPath := range svcPaths {
+		svc, err := p.state.find(svcPath)
+		if err != nil {
+			return err
+		}
+		p.state.walk(svc, recursive, func(s *planService) {
+			step.Services = append(step.Services, s.path)
+			if s.state != state || (state == "started" && s.EmergencyShutdown) {
+				unreachable = append(unreachable, fmt.Sprintf("%s (%s)", s.path, s.state))
+			}
+		})
+	}
+	if len(unreachable) > 0 {
+		if timeout == 0 {
+			return fmt.Errorf("would wait forever for %s; not %s: %s", state, state, strings.Join(unreachable, ", "))
+		}
+		return fmt.Errorf("would time out after %ds waiting for %s; not %s: %s", timeout, state, state, strings.Join(unreachable, ", "))
+	}
+	if timeout == 0 {
+		step.Warnings = append(step.Warnings, "no timeout given; would wait indefinitely")
+	}
+	step.Summary = fmt.Sprintf("wait for %d service(s) to be %s", len(step.Services), state)
+	return nil
+}
+
+func planSvcRun(p *planner, n node, step *PlanStep) error {
+	if p.state == nil {
+		return ErrNoTenant
+	}
+	svc, err := p.state.find(n.args[0])
+	if err != nil {
+		return err
+	}
+	step.Services = []string{svc.path}
+	step.Summary = fmt.Sprintf("run command %s of %s", strings.Join(n.args[1:], " "), svc.path)
+	return nil
+}
+
+func planSvcExec(p *planner, n node, step *PlanStep) error {
+	if p.state == nil {
+		return ErrNoTenant
+	}
+	svc, err := p.state.find(n.args[1])
+	if err != nil {
+		return err
+	}
+	step.Services = []string{svc.path}
+	step.Summary = fmt.Sprintf("exec %s in a shell of %s", strings.Join(n.args[2:], " "), svc.path)
+	if n.args[0] == "COMMIT" {
+		step.Summary = fmt.Sprintf("%s and commit the container to %s", step.Summary, svc.ImageID)
+	}
+	return nil
+}
+
+// planIfSvc returns true if the service is expected to be in the state given
+// by the IF_SVC node when the step is reached.
+func planIfSvc(p *planner, n node, step *PlanStep) (bool, error) {
+	if p.state == nil {
+		return false, ErrNoTenant
+	}
+	svc, err := p.state.find(n.args[0])
+	if err != nil {
+		return false, err
+	}
+	ok := svc.state == ServiceState(n.args[1])
+	step.Services = []string{svc.path}
+	step.Summary = fmt.Sprintf("%s is %s; condition is %t", svc.path, svc.state, ok)
+	return ok, nil
+}
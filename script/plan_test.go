@@ -0,0 +1,110 @@
+// Copyright 2018, The Serviced Authors. All rights reserved.
+// Use of this source code is governed by a
+// license that can be found in the LICENSE file.
+
+// +build unit
+
+package script
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/control-center/serviced/commons"
+	"github.com/control-center/serviced/domain/service"
+	. "gopkg.in/check.v1"
+)
+
+func planTestConfig() *Config {
+	svcs := []service.ServiceDetails{
+		{ID: "tenant", Name: "Zenoss.core", DesiredState: int(service.SVCRun), ImageID: ""},
+		{ID: "db", Name: "mariadb", ParentServiceID: "tenant", DesiredState: int(service.SVCRun), ImageID: "localhost:5000/tenant/core:latest"},
+		{ID: "zope", Name: "Zope", ParentServiceID: "tenant", DesiredState: int(service.SVCStop), ImageID: "localhost:5000/tenant/core-old:latest"},
+		{ID: "manual", Name: "zenjmx", ParentServiceID: "tenant", DesiredState: int(service.SVCStop), Launch: commons.MANUAL, CurrentState: string(service.SVCCSStopped), ImageID: "localhost:5000/tenant/hbase:latest"},
+	}
+	return &Config{
+		ServiceID:      "tenant",
+		TenantLookup:   func(service string) (string, error) { return "tenant", nil },
+		TenantServices: func(tenantID string) ([]service.ServiceDetails, error) { return svcs, nil },
+		SnapshotSize:   func(tenantID string) (uint64, error) { return 1024 * 1024, nil },
+	}
+}
+
+func (vs *ScriptSuite) Test_Plan(t *C) {
+	descriptor := `
+DESCRIPTION plan test
+REQUIRE_SVC
+SNAPSHOT preupgrade
+SVC_USE zenoss/core:5.2 zenoss/core-old
+IF_SVC Zenoss.core/Zope stopped
+SVC_START Zenoss.core recurse
+ELSE
+SVC_STOP Zenoss.core/Zope
+END
+SVC_WAIT Zenoss.core started recursive
+SVC_STOP Zenoss.core/mariadb
+SVC_WAIT Zenoss.core/mariadb started 30
+ON_ERROR
+SVC_START Zenoss.core/mariadb
+END
+`
+	runner, err := NewRunner(strings.NewReader(descriptor), planTestConfig())
+	t.Assert(err, IsNil)
+	plan, err := runner.Plan()
+	t.Assert(err, IsNil)
+	t.Assert(plan.TenantID, Equals, "tenant")
+	t.Assert(plan.Steps, HasLen, 11)
+
+	snapshot := plan.Steps[2]
+	t.Assert(snapshot.Snapshot, DeepEquals, &PlanSnapshot{TenantID: "tenant", Tag: "preupgrade", EstimatedSize: 1024 * 1024})
+
+	use := plan.Steps[3]
+	t.Assert(use.Images, DeepEquals, []PlanImage{
+		{From: "zenoss/core:5.2", To: "localhost:5000/tenant/core:latest"},
+		{ServicePath: "Zenoss.core/Zope", From: "localhost:5000/tenant/core-old:latest", To: "localhost:5000/tenant/core:latest"},
+	})
+	t.Assert(use.Services, DeepEquals, []string{"Zenoss.core/Zope"})
+
+	cond := plan.Steps[4]
+	t.Assert(cond.Command, Equals, IF_SVC)
+	t.Assert(cond.Error, Equals, "")
+	start := plan.Steps[5]
+	t.Assert(start.Skipped, Equals, false)
+	t.Assert(start.Depth, Equals, 1)
+	t.Assert(start.Services, DeepEquals, []string{"Zenoss.core", "Zenoss.core/Zope", "Zenoss.core/mariadb"})
+	t.Assert(plan.Steps[6].Skipped, Equals, true)
+
+	wait := plan.Steps[7]
+	t.Assert(wait.Error, Matches, "would wait forever for started; not started: Zenoss.core/zenjmx \\(stopped\\)")
+
+	badWait := plan.Steps[9]
+	t.Assert(badWait.Error, Matches, "would time out after 30s .*Zenoss.core/mariadb \\(stopped\\)")
+
+	onError := plan.Steps[10]
+	t.Assert(onError.OnError, Equals, true)
+	t.Assert(onError.Services, DeepEquals, []string{"Zenoss.core/mariadb"})
+	t.Assert(plan.HasErrors(), Equals, true)
+}
+
+func (vs *ScriptSuite) Test_PlanErrors(t *C) {
+	descriptor := `
+REQUIRE_SVC
+SVC_START Zenoss.core/missing
+SNAPSHOT
+`
+	config := planTestConfig()
+	config.SnapshotSize = func(tenantID string) (uint64, error) { return 0, errors.New("no usage data") }
+	runner, err := NewRunner(strings.NewReader(descriptor), config)
+	t.Assert(err, IsNil)
+	plan, err := runner.Plan()
+	t.Assert(err, IsNil)
+	t.Assert(plan.Steps, HasLen, 3)
+	t.Assert(plan.Steps[1].Error, Equals, "did not find service Zenoss.core/missing")
+	t.Assert(plan.Steps[2].Warnings, DeepEquals, []string{"snapshot size could not be estimated: no usage data"})
+
+	config.TenantServices = nil
+	runner, err = NewRunner(strings.NewReader(descriptor), config)
+	t.Assert(err, IsNil)
+	_, err = runner.Plan()
+	t.Assert(err, NotNil)
+}
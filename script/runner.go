@@ -33,6 +33,7 @@ func init() {
 		SVC_RESTART: evalSvcRestart,
 		SVC_EXEC:    evalSvcExec,
 		SVC_WAIT:    evalSvcWait,
+		SET:         evalSet,
 	}
 }
 
@@ -50,14 +51,20 @@ type Config struct {
 	SvcRestart     ServiceControl    // function to restart a service
 	SvcWait        ServiceWait       // function to wait for a service to be in a desired state
 	SvcUse         ServiceUse
+	SvcState       ServiceStateLookup // function to look up the state of a service
+	TenantServices TenantServiceList  // function to list the services of a tenant, used by Plan
+	SnapshotSize   SnapshotEstimate   // function to estimate the size of a tenant snapshot, used by Plan
 }
 
 type Runner interface {
 	Run(<-chan struct{}) error
+	Plan() (*Plan, error)
 }
 
 type runner struct {
 	parseCtx        *parseContext
+	nodes           []node // the steps to run, with blocks nested in their parent node
+	onError         []node // the steps to run if any step fails
 	config          *Config
 	exitFunctions   []func(bool)      // each is called on exit of upgrade, bool denotes if upgrade exited with an error
 	snapshotID      string            // the last snapshot taken
@@ -73,6 +80,9 @@ type runner struct {
 	svcWait         ServiceWait
 	execCommand     execCmd
 	svcUse          ServiceUse
+	svcState        ServiceStateLookup
+	tenantServices  TenantServiceList
+	snapshotSize    SnapshotEstimate
 }
 
 func NewRunnerFromFile(fileName string, config *Config) (Runner, error) {
@@ -119,6 +129,17 @@ func newRunner(config *Config, pctx *parseContext) *runner {
 		svcRestart:      config.SvcRestart,
 		execCommand:     defaultExec,
 		svcUse:          config.SvcUse,
+		svcState:        config.SvcState,
+		tenantServices:  config.TenantServices,
+		snapshotSize:    config.SnapshotSize,
+	}
+	steps, _ := nestNodes(pctx.nodes, 0)
+	for _, n := range steps {
+		if n.cmd == ON_ERROR {
+			r.onError = n.body
+		} else {
+			r.nodes = append(r.nodes, n)
+		}
 	}
 	if config.NoOp {
 		plog.Info("creatng no op runner")
@@ -136,8 +157,34 @@ func newRunner(config *Config, pctx *parseContext) *runner {
 	return r
 }
 
+// nestNodes moves the nodes inside of IF_SVC/ELSE/END and ON_ERROR/END blocks
+// into the body of their block node, starting at index i.  It returns the
+// nodes up to the ELSE or END that terminates the current block and the index
+// of that terminator.  The nodes are expected to have been validated by the
+// parser.
+func nestNodes(nodes []node, i int) ([]node, int) {
+	var result []node
+	for i < len(nodes) {
+		n := nodes[i]
+		switch n.cmd {
+		case ELSE, END:
+			return result, i
+		case IF_SVC, ON_ERROR:
+			n.body, i = nestNodes(nodes, i+1)
+			if i < len(nodes) && nodes[i].cmd == ELSE {
+				n.elseBody, i = nestNodes(nodes, i+1)
+			}
+			i++ // skip END
+		default:
+			i++
+		}
+		result = append(result, n)
+	}
+	return result, i
+}
+
 func (r *runner) Run(stop <-chan struct{}) error {
-	if err := r.evalNodes(r.parseCtx.nodes, stop); err != nil {
+	if err := r.evalNodes(r.nodes, stop); err != nil {
 		return err
 	}
 
@@ -154,13 +201,44 @@ func (r *runner) evalNodes(nodes []node, stop <-chan struct{}) error {
 		}
 	}()
 
+	if err := r.evalSteps(nodes, stop); err != nil {
+		r.evalOnError()
+		return err
+	}
+	failed = false
+
+	return nil
+}
+
+// evalSteps evaluates each node in order, descending into the branch of an
+// IF_SVC block that matches the current state of the service.
+func (r *runner) evalSteps(nodes []node, stop <-chan struct{}) error {
 	for i, n := range nodes {
 		logger := plog.WithFields(log.Fields{
 			"step": i,
 			"line": n.line,
 			"command": n.cmd,
 		})
-		if f, found := cmdEval[n.cmd]; found {
+		n, err := expandNode(n, r.env)
+		if err != nil {
+			logger.WithError(err).Error("Unable to expand variables for step")
+			return err
+		}
+		if n.cmd == IF_SVC {
+			logger.Info("evaluating condition")
+			ok, err := evalIfSvc(r, n)
+			if err != nil {
+				logger.WithError(err).Error("Unable to evaluate condition")
+				return err
+			}
+			branch := n.elseBody
+			if ok {
+				branch = n.body
+			}
+			if err := r.evalSteps(branch, stop); err != nil {
+				return err
+			}
+		} else if f, found := cmdEval[n.cmd]; found {
 			logger.Info("executing step")
 			if err := f(r, n); err != nil {
 				logger.WithError(err).Error("Unable to execute step")
@@ -178,11 +256,23 @@ func (r *runner) evalNodes(nodes []node, stop <-chan struct{}) error {
 		}
 
 	}
-	failed = false
-
 	return nil
 }
 
+// evalOnError runs the ON_ERROR section of the script, if there is one.  It is
+// called before the exit functions so that it runs prior to any snapshot
+// rollback.  The section is not interrupted by the stop signal and a failure
+// within it is logged, but does not replace the error that caused it to run.
+func (r *runner) evalOnError() {
+	if len(r.onError) == 0 {
+		return
+	}
+	plog.Info("Executing ON_ERROR steps")
+	if err := r.evalSteps(r.onError, nil); err != nil {
+		plog.WithError(err).Error("Unable to complete ON_ERROR steps")
+	}
+}
+
 func (r *runner) addExitFunction(ef func(bool)) {
 	r.exitFunctions = append(r.exitFunctions, ef)
 }
@@ -8,6 +8,7 @@ package script
 
 import (
 	"errors"
+	"strings"
 
 	. "gopkg.in/check.v1"
 )
@@ -48,3 +49,65 @@ func (vs *ScriptSuite) Test_Run(t *C) {
 	t.Assert(err, ErrorMatches, "test error id from path")
 
 }
+
+func (vs *ScriptSuite) Test_RunConditional(t *C) {
+	descriptor := `
+REQUIRE_SVC
+SNAPSHOT
+SET DB Zenoss.core/mariadb
+IF_SVC ${DB} started
+SVC_STOP ${DB}
+ELSE
+SVC_START ${DB}
+IF_SVC Zenoss.core/Zope paused
+SVC_RESTART Zenoss.core/Zope
+END
+END
+ON_ERROR
+SVC_START Zenoss.core/rabbitmq
+END
+`
+	var calls []string
+	states := map[string]ServiceState{"Zenoss.core/mariadb": "stopped", "Zenoss.core/Zope": "paused"}
+	record := func(action string) ServiceControl {
+		return func(serviceID string, recursive bool) error {
+			calls = append(calls, action+" "+serviceID)
+			if serviceID == "Zenoss.core/Zope" {
+				return errors.New("restart failed")
+			}
+			return nil
+		}
+	}
+	config := Config{
+		ServiceID:     "TEST_SERVICE_ID_12345",
+		TenantLookup:  func(service string) (string, error) { return service, nil },
+		SvcIDFromPath: func(tenantID string, path string) (string, error) { return path, nil },
+		SvcState:      func(serviceID string) (ServiceState, error) { return states[serviceID], nil },
+		Snapshot:      func(serviceID, description, tag string) (string, error) { return "snapshot", nil },
+		Restore: func(snapshotID string, forceRestart bool) error {
+			calls = append(calls, "restore "+snapshotID)
+			return nil
+		},
+		SvcStart:   record("start"),
+		SvcStop:    record("stop"),
+		SvcRestart: record("restart"),
+	}
+	runner, err := NewRunner(strings.NewReader(descriptor), &config)
+	t.Assert(err, IsNil)
+	err = runner.Run(make(chan struct{}))
+	t.Assert(err, ErrorMatches, "restart failed")
+	t.Assert(calls, DeepEquals, []string{
+		"start Zenoss.core/mariadb",
+		"restart Zenoss.core/Zope",
+		"start Zenoss.core/rabbitmq",
+		"restore snapshot",
+	})
+
+	calls = nil
+	states["Zenoss.core/mariadb"] = "started"
+	runner, err = NewRunner(strings.NewReader(descriptor), &config)
+	t.Assert(err, IsNil)
+	err = runner.Run(make(chan struct{}))
+	t.Assert(err, IsNil)
+	t.Assert(calls, DeepEquals, []string{"stop Zenoss.core/mariadb"})
+}
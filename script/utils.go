@@ -39,6 +39,15 @@ type ServiceState string
 // Wait for a service to be in a particular state
 type ServiceWait func(serviceID []string, serviceState ServiceState, timeout uint32, recursive bool) error
 
+// ServiceStateLookup returns the state (started, stopped or paused) of a service
+type ServiceStateLookup func(serviceID string) (ServiceState, error)
+
+// TenantServiceList returns all of the services of a tenant
+type TenantServiceList func(tenantID string) ([]service.ServiceDetails, error)
+
+// SnapshotEstimate returns the estimated size in bytes of a snapshot of a tenant
+type SnapshotEstimate func(tenantID string) (uint64, error)
+
 type execCmd func(string, ...string) error
 
 type findTenant func(string) (string, error)
@@ -55,6 +64,18 @@ func ScriptStateToDesiredState(state ServiceState) (service.DesiredState, error)
 	return service.DesiredState(-99), fmt.Errorf("service state %s unknown", state)
 }
 
+func DesiredStateToScriptState(state service.DesiredState) (ServiceState, error) {
+	switch state {
+	case service.SVCStop:
+		return "stopped", nil
+	case service.SVCRun:
+		return "started", nil
+	case service.SVCPause:
+		return "paused", nil
+	}
+	return "", fmt.Errorf("desired state %d unknown", state)
+}
+
 func defaultExec(name string, args ...string) error {
 	cmd := exec.Command(name, args...)
 	cmd.Stderr = os.Stderr
@@ -0,0 +1,79 @@
+// Copyright 2018, The Serviced Authors. All rights reserved.
+// Use of this source code is governed by a
+// license that can be found in the LICENSE file.
+
+package script
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	varNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	varRefRegex  = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+)
+
+// hasVars returns true if the string contains a ${VAR} reference
+func hasVars(s string) bool {
+	return varRefRegex.MatchString(s)
+}
+
+// expandVars replaces each ${VAR} reference in s with its value from env.  It
+// is an error to reference a variable that has not been set.
+func expandVars(s string, env map[string]string) (string, error) {
+	var err error
+	result := varRefRegex.ReplaceAllStringFunc(s, func(ref string) string {
+		name := varRefRegex.FindStringSubmatch(ref)[1]
+		value, found := env[name]
+		if !found && err == nil {
+			err = fmt.Errorf("variable %s is not set", name)
+		}
+		return value
+	})
+	return result, err
+}
+
+// expandNode returns a copy of the node with all variable references in its
+// args substituted.  The expanded node is run back through its line parser so
+// that args that could not be checked when the script was parsed are
+// validated before the node is evaluated.
+func expandNode(n node, env map[string]string) (node, error) {
+	found := false
+	for _, arg := range n.args {
+		if hasVars(arg) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return n, nil
+	}
+
+	args := make([]string, len(n.args))
+	for i, arg := range n.args {
+		value, err := expandVars(arg, env)
+		if err != nil {
+			return node{}, fmt.Errorf("line %d: %s", n.lineNum, err)
+		}
+		args[i] = value
+	}
+	line := strings.Join(append([]string{n.cmd}, args...), " ")
+
+	if f, found := nodeFactories[n.cmd]; found {
+		// use a scratch context; only errors returned by the parser are
+		// about the args, the rest were already reported at parse time.
+		ctx := newParseContext()
+		ctx.line = line
+		ctx.lineNum = n.lineNum
+		if _, err := f(ctx, n.cmd, args); err != nil {
+			return node{}, err
+		}
+	}
+
+	expanded := n
+	expanded.args = args
+	expanded.line = line
+	return expanded, nil
+}
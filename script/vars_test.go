@@ -0,0 +1,58 @@
+// Copyright 2018, The Serviced Authors. All rights reserved.
+// Use of this source code is governed by a
+// license that can be found in the LICENSE file.
+
+// +build unit
+
+package script
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+func (vs *ScriptSuite) Test_expandVars(t *C) {
+	env := map[string]string{"A": "alpha", "B_2": "beta"}
+
+	result, err := expandVars("no vars", env)
+	t.Assert(err, IsNil)
+	t.Assert(result, Equals, "no vars")
+
+	result, err = expandVars("${A}/${B_2}-${A}", env)
+	t.Assert(err, IsNil)
+	t.Assert(result, Equals, "alpha/beta-alpha")
+
+	result, err = expandVars("$A ${ A}", env)
+	t.Assert(err, IsNil)
+	t.Assert(result, Equals, "$A ${ A}")
+
+	_, err = expandVars("${A}${C}", env)
+	t.Assert(err, ErrorMatches, "variable C is not set")
+}
+
+func (vs *ScriptSuite) Test_expandNode(t *C) {
+	env := map[string]string{"IMAGE": "zenoss/core:5.2", "MODE": "COMMIT", "BAD": "zenoss/core:5.2:bad"}
+
+	n := node{cmd: USE, args: []string{"${IMAGE}", "zenoss/core"}, line: "SVC_USE ${IMAGE} zenoss/core", lineNum: 4}
+	expanded, err := expandNode(n, env)
+	t.Assert(err, IsNil)
+	t.Assert(expanded.args, DeepEquals, []string{"zenoss/core:5.2", "zenoss/core"})
+	t.Assert(expanded.line, Equals, "SVC_USE zenoss/core:5.2 zenoss/core")
+	t.Assert(n.args[0], Equals, "${IMAGE}")
+
+	n = node{cmd: SVC_EXEC, args: []string{"${MODE}", "Zenoss.core/Zope", "cmd"}, lineNum: 5}
+	expanded, err = expandNode(n, env)
+	t.Assert(err, IsNil)
+	t.Assert(expanded.args[0], Equals, "COMMIT")
+
+	env["MODE"] = "MAYBE_COMMIT"
+	_, err = expandNode(n, env)
+	t.Assert(err, ErrorMatches, "line 5: arg MAYBE_COMMIT did not match .*")
+
+	n = node{cmd: USE, args: []string{"${BAD}"}, lineNum: 6}
+	_, err = expandNode(n, env)
+	t.Assert(err, NotNil)
+
+	n = node{cmd: SVC_START, args: []string{"${MISSING}"}, lineNum: 7}
+	_, err = expandNode(n, env)
+	t.Assert(err, ErrorMatches, "line 7: variable MISSING is not set")
+}
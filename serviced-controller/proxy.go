@@ -21,6 +21,7 @@ import (
 
 	"github.com/codegangsta/cli"
 	"github.com/control-center/serviced/container"
+	_ "github.com/control-center/serviced/coordinator/client/etcd" // registers the etcd coordinator driver
 	coordzk "github.com/control-center/serviced/coordinator/client/zookeeper"
 	"github.com/control-center/serviced/logging"
 	"github.com/control-center/serviced/rpc/rpcutils"
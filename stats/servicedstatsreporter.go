@@ -40,8 +40,13 @@ type ServicedStatsReporter struct {
 	conn                coordclient.Connection
 	containerRegistries map[registryKey]metrics.Registry
 	docker              docker.Docker
+	sampleSources       []SampleSource
 }
 
+// SampleSource returns samples collected outside of the reporter, such as
+// the connection statistics of the TCP mux.
+type SampleSource func(t time.Time) []Sample
+
 type registryKey struct {
 	serviceID  string
 	instanceID int
@@ -77,6 +82,14 @@ func NewServicedStatsReporter(destination string, interval time.Duration, conn c
 	return &ssr, nil
 }
 
+// AddSampleSource adds samples from source to every report.  The samples are
+// tagged with the host id.
+func (sr *ServicedStatsReporter) AddSampleSource(source SampleSource) {
+	sr.Lock()
+	defer sr.Unlock()
+	sr.sampleSources = append(sr.sampleSources, source)
+}
+
 // getOrCreateContainerRegistry returns a registry for a given service id or creates it
 // if it doesn't exist.
 func (sr *ServicedStatsReporter) getOrCreateContainerRegistry(serviceID string, instanceID int) metrics.Registry {
@@ -164,6 +177,20 @@ func (sr *ServicedStatsReporter) gatherStats(t time.Time) []Sample {
 			}
 		})
 	}
+	// Handle the samples from other sources.
+	sr.Lock()
+	sources := sr.sampleSources
+	sr.Unlock()
+	for _, source := range sources {
+		for _, sample := range source(t) {
+			tagmap := map[string]string{"controlplane_host_id": sr.hostID}
+			for k, v := range sample.Tags {
+				tagmap[k] = v
+			}
+			sample.Tags = tagmap
+			stats = append(stats, sample)
+		}
+	}
 	return stats
 }
 
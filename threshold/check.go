@@ -0,0 +1,240 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package threshold
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/control-center/serviced/commons/statistics"
+	"github.com/control-center/serviced/domain"
+	"github.com/control-center/serviced/metrics"
+)
+
+// Threshold types
+const (
+	MinMax      = "MinMax"
+	Duration    = "Duration"
+	HoltWinters = "HoltWinters"
+)
+
+// holtWintersDelta is the number of mean absolute deviations that a value
+// may differ from its forecast before it violates a HoltWinters threshold.
+const holtWintersDelta = 2.0
+
+var (
+	// ErrUnsupportedThreshold is returned for threshold types that cannot be
+	// evaluated
+	ErrUnsupportedThreshold = errors.New("unsupported threshold type")
+
+	// ErrNoData is returned when there are no values to evaluate
+	ErrNoData = errors.New("no data to evaluate")
+)
+
+// Outcome is the result of evaluating a threshold against a series
+type Outcome struct {
+	Violated bool
+	Value    float64 // the value that was evaluated
+	Message  string
+}
+
+// Check evaluates a threshold against the series of one of its data points
+type Check interface {
+	// Window returns how much history the check needs, given the interval
+	// between evaluations
+	Window(interval time.Duration) time.Duration
+	// Evaluate reports whether the series violates the threshold
+	Evaluate(series metrics.MetricSeries) (Outcome, error)
+}
+
+// NewCheck returns the check for a threshold config
+func NewCheck(config domain.ThresholdConfig) (Check, error) {
+	switch config.Type {
+	case MinMax:
+		var t domain.MinMaxThreshold
+		if err := decode(config.Threshold, &t); err != nil {
+			return nil, err
+		}
+		min, err := parseBound(t.Min)
+		if err != nil {
+			return nil, err
+		}
+		max, err := parseBound(t.Max)
+		if err != nil {
+			return nil, err
+		}
+		return &minMaxCheck{min: min, max: max}, nil
+	case Duration:
+		var t domain.DurationThreshold
+		if err := decode(config.Threshold, &t); err != nil {
+			return nil, err
+		}
+		if t.TimePeriod <= 0 {
+			return nil, fmt.Errorf("duration threshold %s has no time period", config.ID)
+		}
+		return &durationCheck{t}, nil
+	case HoltWinters:
+		var t domain.HoltWintersThreshold
+		if err := decode(config.Threshold, &t); err != nil {
+			return nil, err
+		}
+		if t.Season < 1 || t.Rows < t.Season {
+			return nil, fmt.Errorf("holt-winters threshold %s must have 0 < Season <= Rows", config.ID)
+		}
+		return &holtWintersCheck{t}, nil
+	default:
+		return nil, ErrUnsupportedThreshold
+	}
+}
+
+// decode converts the threshold data, which may be a threshold struct or the
+// map it was unmarshalled into, to the threshold struct.
+func decode(data interface{}, t interface{}) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, t)
+}
+
+// parseBound parses a min or max value.  Empty values have no bound.
+func parseBound(value string) (*float64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported threshold value %q", value)
+	}
+	return &f, nil
+}
+
+// outOfRange returns a message if the value is less than min or greater than
+// max.
+func outOfRange(value float64, min, max *float64) string {
+	if min != nil && value < *min {
+		return fmt.Sprintf("%g is less than the minimum %g", value, *min)
+	}
+	if max != nil && value > *max {
+		return fmt.Sprintf("%g is greater than the maximum %g", value, *max)
+	}
+	return ""
+}
+
+// minMaxCheck is violated when the latest value is out of range
+type minMaxCheck struct {
+	min, max *float64
+}
+
+func (c *minMaxCheck) Window(interval time.Duration) time.Duration {
+	return interval
+}
+
+func (c *minMaxCheck) Evaluate(series metrics.MetricSeries) (Outcome, error) {
+	values := series.Y()
+	if len(values) == 0 {
+		return Outcome{}, ErrNoData
+	}
+	value := values[len(values)-1]
+	msg := outOfRange(value, c.min, c.max)
+	return Outcome{Violated: msg != "", Value: value, Message: msg}, nil
+}
+
+// durationCheck is violated when the percentage of values in the time period
+// that are out of range is at least the threshold percentage.
+type durationCheck struct {
+	domain.DurationThreshold
+}
+
+func (c *durationCheck) Window(interval time.Duration) time.Duration {
+	return c.TimePeriod
+}
+
+func (c *durationCheck) Evaluate(series metrics.MetricSeries) (Outcome, error) {
+	values := series.Y()
+	if len(values) == 0 {
+		return Outcome{}, ErrNoData
+	}
+	var min, max *float64
+	if c.Min != nil {
+		f := float64(*c.Min)
+		min = &f
+	}
+	if c.Max != nil {
+		f := float64(*c.Max)
+		max = &f
+	}
+	count := 0
+	for _, value := range values {
+		if outOfRange(value, min, max) != "" {
+			count++
+		}
+	}
+	percent := count * 100 / len(values)
+	result := Outcome{Value: float64(percent)}
+	if count > 0 && percent >= c.Percentage {
+		result.Violated = true
+		result.Message = fmt.Sprintf("%d%% of values in the last %s are out of range", percent, c.TimePeriod)
+	}
+	return result, nil
+}
+
+// holtWintersCheck is violated when the latest value is too far from its
+// forecast.  The threshold has no seasonal smoothing factor, so Alpha
+// smooths both the level and the seasons.
+type holtWintersCheck struct {
+	domain.HoltWintersThreshold
+}
+
+func (c *holtWintersCheck) Window(interval time.Duration) time.Duration {
+	// Initializing the model needs two seasons
+	rows := c.Rows
+	if rows < 2*c.Season {
+		rows = 2 * c.Season
+	}
+	return time.Duration(rows+1) * interval
+}
+
+func (c *holtWintersCheck) Evaluate(series metrics.MetricSeries) (Outcome, error) {
+	values := series.Y()
+	if len(values) == 0 {
+		return Outcome{}, ErrNoData
+	}
+	forecast, err := statistics.HoltWinters(values, int(c.Season), c.Alpha, c.Beta, c.Alpha)
+	if err != nil {
+		return Outcome{}, err
+	}
+
+	// Compare the latest value to the deviation of the values before it,
+	// leaving out the first season that initialized the model.
+	last := len(values) - 1
+	deviation, err := statistics.MeanAbsoluteDeviation(values[c.Season:last], forecast[c.Season:last])
+	if err != nil {
+		return Outcome{}, err
+	}
+	value := values[last]
+	diff := math.Abs(value - forecast[last])
+	result := Outcome{Value: value}
+	if diff > holtWintersDelta*deviation && diff > 0 {
+		result.Violated = true
+		result.Message = fmt.Sprintf("%g differs from the forecast %g by more than %g", value, forecast[last], holtWintersDelta*deviation)
+	}
+	return result, nil
+}
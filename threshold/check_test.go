@@ -0,0 +1,144 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build unit
+
+package threshold_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/control-center/serviced/domain"
+	"github.com/control-center/serviced/metrics"
+	. "github.com/control-center/serviced/threshold"
+	. "gopkg.in/check.v1"
+)
+
+func TestThreshold(t *testing.T) { TestingT(t) }
+
+type CheckSuite struct{}
+
+var _ = Suite(&CheckSuite{})
+
+func newSeries(values ...float64) metrics.MetricSeries {
+	dps := make([]metrics.Datapoint, len(values))
+	for i, v := range values {
+		dps[i] = metrics.Datapoint{Timestamp: int64(i), Value: metrics.Float{Value: v}}
+	}
+	return metrics.DatapointsToSeries(dps)
+}
+
+func (s *CheckSuite) TestMinMax(c *C) {
+	check, err := NewCheck(domain.ThresholdConfig{
+		Type:      MinMax,
+		Threshold: domain.MinMaxThreshold{Min: "10", Max: ""},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(check.Window(time.Minute), Equals, time.Minute)
+
+	result, err := check.Evaluate(newSeries(1, 15))
+	c.Assert(err, IsNil)
+	c.Assert(result.Violated, Equals, false)
+
+	result, err = check.Evaluate(newSeries(15, 5))
+	c.Assert(err, IsNil)
+	c.Assert(result.Violated, Equals, true)
+	c.Assert(result.Value, Equals, float64(5))
+
+	_, err = check.Evaluate(newSeries())
+	c.Assert(err, Equals, ErrNoData)
+
+	// expressions cannot be evaluated
+	_, err = NewCheck(domain.ThresholdConfig{
+		Type:      MinMax,
+		Threshold: domain.MinMaxThreshold{Max: "here.totalBytes * 0.80"},
+	})
+	c.Assert(err, NotNil)
+}
+
+func (s *CheckSuite) TestMinMax_FromJSON(c *C) {
+	// thresholds loaded from templates are maps
+	var config domain.ThresholdConfig
+	err := json.Unmarshal([]byte(`{"Type": "MinMax", "Threshold": {"Max": "100"}}`), &config)
+	c.Assert(err, IsNil)
+	check, err := NewCheck(config)
+	c.Assert(err, IsNil)
+	result, err := check.Evaluate(newSeries(101))
+	c.Assert(err, IsNil)
+	c.Assert(result.Violated, Equals, true)
+}
+
+func (s *CheckSuite) TestDuration(c *C) {
+	max := int64(10)
+	check, err := NewCheck(domain.ThresholdConfig{
+		Type: Duration,
+		Threshold: domain.DurationThreshold{
+			Max:        &max,
+			TimePeriod: 5 * time.Minute,
+			Percentage: 50,
+		},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(check.Window(time.Minute), Equals, 5*time.Minute)
+
+	result, err := check.Evaluate(newSeries(1, 20, 3, 4))
+	c.Assert(err, IsNil)
+	c.Assert(result.Violated, Equals, false)
+	c.Assert(result.Value, Equals, float64(25))
+
+	result, err = check.Evaluate(newSeries(1, 20, 30, 4))
+	c.Assert(err, IsNil)
+	c.Assert(result.Violated, Equals, true)
+
+	// a time period is required
+	_, err = NewCheck(domain.ThresholdConfig{
+		Type:      Duration,
+		Threshold: domain.DurationThreshold{Max: &max},
+	})
+	c.Assert(err, NotNil)
+}
+
+func (s *CheckSuite) TestHoltWinters(c *C) {
+	check, err := NewCheck(domain.ThresholdConfig{
+		Type: HoltWinters,
+		Threshold: domain.HoltWintersThreshold{
+			Alpha:  0.5,
+			Beta:   0.1,
+			Rows:   12,
+			Season: 3,
+		},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(check.Window(time.Minute), Equals, 13*time.Minute)
+
+	// the pattern continues
+	result, err := check.Evaluate(newSeries(1, 5, 3, 1.1, 5, 3, 1, 5.1, 3, 1, 5, 3))
+	c.Assert(err, IsNil)
+	c.Assert(result.Violated, Equals, false)
+
+	// the pattern breaks
+	result, err = check.Evaluate(newSeries(1, 5, 3, 1.1, 5, 3, 1, 5.1, 3, 1, 5, 30))
+	c.Assert(err, IsNil)
+	c.Assert(result.Violated, Equals, true)
+
+	// not enough data
+	_, err = check.Evaluate(newSeries(1, 5, 3))
+	c.Assert(err, NotNil)
+}
+
+func (s *CheckSuite) TestUnsupported(c *C) {
+	_, err := NewCheck(domain.ThresholdConfig{Type: "ValueChange"})
+	c.Assert(err, Equals, ErrUnsupportedThreshold)
+}
@@ -0,0 +1,38 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package threshold
+
+import (
+	"time"
+)
+
+// Event is raised when a data point starts or stops violating a threshold
+type Event struct {
+	Timestamp   time.Time
+	ServiceID   string
+	ServiceName string
+	ThresholdID string
+	Threshold   string // name of the threshold
+	DataPoint   string
+	Value       float64
+	Cleared     bool // true if the data point no longer violates the threshold
+	Message     string
+	Tags        map[string]interface{} // event tags of the threshold
+}
+
+// EventStore receives the events raised by a monitor
+type EventStore interface {
+	// Add stores or publishes an event
+	Add(event Event)
+}
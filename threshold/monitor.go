@@ -0,0 +1,193 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package threshold
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/control-center/serviced/audit"
+	"github.com/control-center/serviced/datastore"
+	"github.com/control-center/serviced/domain"
+	"github.com/control-center/serviced/domain/service"
+	"github.com/control-center/serviced/logging"
+	"github.com/control-center/serviced/metrics"
+)
+
+var plog = logging.PackageLogger()
+
+// AppliedTo values of a threshold config
+const (
+	AppliedToAll             = 0
+	AppliedToServices        = 1
+	AppliedToRunningServices = 2
+)
+
+// aggregator combines the values of the instances of a service
+const aggregator = "avg"
+
+// ServiceLister returns the services whose thresholds are evaluated
+type ServiceLister interface {
+	GetAllServices(ctx datastore.Context) ([]service.Service, error)
+}
+
+// MetricsClient queries the metrics backend
+type MetricsClient interface {
+	GetMetricSeries(window, resolution time.Duration, aggregator string, tags map[string][]string, metrics ...string) (map[string]metrics.MetricSeries, error)
+}
+
+// Monitor periodically evaluates the thresholds in the monitoring profiles
+// of services, and raises an event whenever a data point starts or stops
+// violating a threshold.
+type Monitor struct {
+	services ServiceLister
+	client   MetricsClient
+	store    EventStore
+	audit    audit.Logger
+	interval time.Duration
+	violated map[string]bool // data points currently violating a threshold
+}
+
+// NewMonitor returns a monitor that evaluates thresholds every interval
+func NewMonitor(services ServiceLister, client MetricsClient, store EventStore, auditLogger audit.Logger, interval time.Duration) *Monitor {
+	return &Monitor{
+		services: services,
+		client:   client,
+		store:    store,
+		audit:    auditLogger,
+		interval: interval,
+		violated: make(map[string]bool),
+	}
+}
+
+// Run evaluates the thresholds every interval until shutdown is closed
+func (m *Monitor) Run(ctx datastore.Context, shutdown <-chan interface{}) {
+	logger := plog.WithField("interval", m.interval)
+	logger.Info("Started evaluating thresholds")
+	defer logger.Info("Stopped evaluating thresholds")
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := m.Evaluate(ctx); err != nil {
+				logger.WithError(err).Warn("Unable to evaluate thresholds")
+			}
+		case <-shutdown:
+			return
+		}
+	}
+}
+
+// Evaluate evaluates the thresholds of all services once
+func (m *Monitor) Evaluate(ctx datastore.Context) error {
+	svcs, err := m.services.GetAllServices(ctx)
+	if err != nil {
+		return err
+	}
+	seen := make(map[string]bool)
+	for i := range svcs {
+		svc := &svcs[i]
+		for _, config := range svc.MonitoringProfile.ThresholdConfigs {
+			if config.AppliedTo == AppliedToRunningServices && svc.DesiredState != int(service.SVCRun) {
+				continue
+			}
+			m.evaluateThreshold(ctx, svc, config, seen)
+		}
+	}
+
+	// Forget the data points of thresholds that are gone
+	for key := range m.violated {
+		if !seen[key] {
+			delete(m.violated, key)
+		}
+	}
+	return nil
+}
+
+// evaluateThreshold evaluates a threshold against each of its data points
+func (m *Monitor) evaluateThreshold(ctx datastore.Context, svc *service.Service, config domain.ThresholdConfig, seen map[string]bool) {
+	logger := plog.WithFields(log.Fields{
+		"serviceid":   svc.ID,
+		"servicename": svc.Name,
+		"threshold":   config.ID,
+	})
+
+	check, err := NewCheck(config)
+	if err != nil {
+		logger.WithError(err).Debug("Skipping threshold")
+		return
+	}
+
+	tags := map[string][]string{"controlplane_service_id": {svc.ID}}
+	series, err := m.client.GetMetricSeries(check.Window(m.interval), m.interval, aggregator, tags, config.DataPoints...)
+	if err != nil {
+		logger.WithError(err).Debug("Unable to query metrics for threshold")
+		return
+	}
+
+	for _, dp := range config.DataPoints {
+		key := fmt.Sprintf("%s/%s/%s", svc.ID, config.ID, dp)
+		seen[key] = true
+		s, ok := series[dp]
+		if !ok {
+			continue
+		}
+		result, err := check.Evaluate(s)
+		if err != nil {
+			logger.WithError(err).WithField("datapoint", dp).Debug("Unable to evaluate threshold")
+			continue
+		}
+		if result.Violated == m.violated[key] {
+			continue
+		}
+		m.violated[key] = result.Violated
+		m.raise(ctx, Event{
+			Timestamp:   time.Now(),
+			ServiceID:   svc.ID,
+			ServiceName: svc.Name,
+			ThresholdID: config.ID,
+			Threshold:   config.Name,
+			DataPoint:   dp,
+			Value:       result.Value,
+			Cleared:     !result.Violated,
+			Message:     result.Message,
+			Tags:        config.EventTags,
+		})
+	}
+}
+
+// raise stores the event and writes it to the audit log
+func (m *Monitor) raise(ctx datastore.Context, event Event) {
+	m.store.Add(event)
+
+	fields := log.Fields{}
+	for k, v := range event.Tags {
+		fields[k] = v
+	}
+	fields["threshold"] = event.ThresholdID
+	fields["datapoint"] = event.DataPoint
+	fields["value"] = event.Value
+	if event.Message != "" {
+		fields["reason"] = event.Message
+	}
+
+	message := "Threshold violated"
+	if event.Cleared {
+		message = "Threshold cleared"
+	}
+	m.audit.Message(ctx, message).Action(audit.Threshold).Type(service.GetType()).ID(event.ServiceID).WithFields(fields).SucceededIf(event.Cleared)
+}
@@ -0,0 +1,128 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build unit
+
+package threshold_test
+
+import (
+	"sync"
+	"time"
+
+	auditmocks "github.com/control-center/serviced/audit/mocks"
+	"github.com/control-center/serviced/datastore"
+	"github.com/control-center/serviced/domain"
+	"github.com/control-center/serviced/domain/service"
+	"github.com/control-center/serviced/metrics"
+	. "github.com/control-center/serviced/threshold"
+	"github.com/stretchr/testify/mock"
+	. "gopkg.in/check.v1"
+)
+
+type MonitorSuite struct{}
+
+var _ = Suite(&MonitorSuite{})
+
+type testServices []service.Service
+
+func (t testServices) GetAllServices(ctx datastore.Context) ([]service.Service, error) {
+	return t, nil
+}
+
+type testMetrics map[string]metrics.MetricSeries
+
+func (t testMetrics) GetMetricSeries(window, resolution time.Duration, aggregator string, tags map[string][]string, names ...string) (map[string]metrics.MetricSeries, error) {
+	return t, nil
+}
+
+// testEvents keeps the events raised by a monitor
+type testEvents struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (t *testEvents) Add(event Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = append(t.events, event)
+}
+
+func (t *testEvents) Events() []Event {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Event{}, t.events...)
+}
+
+func (s *MonitorSuite) TestEvaluate(c *C) {
+	svcs := testServices{
+		{
+			ID:           "svc1",
+			Name:         "service1",
+			DesiredState: int(service.SVCRun),
+			MonitoringProfile: domain.MonitorProfile{
+				ThresholdConfigs: []domain.ThresholdConfig{
+					{
+						ID:           "cpu.high",
+						Name:         "CPU high",
+						Type:         MinMax,
+						MetricSource: "cpu",
+						DataPoints:   []string{"cpu.user"},
+						Threshold:    domain.MinMaxThreshold{Max: "90"},
+						EventTags:    map[string]interface{}{"Severity": 3},
+					},
+				},
+			},
+		},
+	}
+	data := testMetrics{"cpu.user": newSeries(50, 95)}
+
+	auditLogger := &auditmocks.Logger{}
+	auditLogger.On("Message", mock.Anything, mock.AnythingOfType("string")).Return(auditLogger)
+	auditLogger.On("Action", "threshold").Return(auditLogger)
+	auditLogger.On("Type", mock.Anything).Return(auditLogger)
+	auditLogger.On("ID", "svc1").Return(auditLogger)
+	auditLogger.On("WithFields", mock.Anything).Return(auditLogger)
+	auditLogger.On("SucceededIf", mock.Anything).Return()
+
+	store := &testEvents{}
+	monitor := NewMonitor(svcs, data, store, auditLogger, time.Minute)
+	ctx := datastore.Get()
+
+	// the threshold is violated once
+	c.Assert(monitor.Evaluate(ctx), IsNil)
+	c.Assert(monitor.Evaluate(ctx), IsNil)
+	events := store.Events()
+	c.Assert(events, HasLen, 1)
+	c.Assert(events[0].ServiceID, Equals, "svc1")
+	c.Assert(events[0].ThresholdID, Equals, "cpu.high")
+	c.Assert(events[0].DataPoint, Equals, "cpu.user")
+	c.Assert(events[0].Value, Equals, float64(95))
+	c.Assert(events[0].Cleared, Equals, false)
+	c.Assert(events[0].Tags["Severity"], Equals, 3)
+
+	// and cleared when the value recovers
+	data["cpu.user"] = newSeries(95, 50)
+	c.Assert(monitor.Evaluate(ctx), IsNil)
+	events = store.Events()
+	c.Assert(events, HasLen, 2)
+	c.Assert(events[1].Cleared, Equals, true)
+	auditLogger.AssertCalled(c, "SucceededIf", false)
+	auditLogger.AssertCalled(c, "SucceededIf", true)
+
+	// thresholds for running services are skipped for stopped services
+	svcs[0].DesiredState = int(service.SVCStop)
+	svcs[0].MonitoringProfile.ThresholdConfigs[0].AppliedTo = AppliedToRunningServices
+	data["cpu.user"] = newSeries(95)
+	c.Assert(monitor.Evaluate(ctx), IsNil)
+	c.Assert(store.Events(), HasLen, 2)
+}
@@ -30,7 +30,7 @@ import (
 type DriverInit struct {
 	Args struct {
 		Path flags.Filename `description:"Path of the driver"`
-		Type string         `description:"Type of driver to initialize (btrfs|devicemapper|rsync)"`
+		Type string         `description:"Type of driver to initialize (btrfs|devicemapper|rsync|zfs)"`
 	} `positional-args:"yes" required:"yes"`
 }
 
@@ -50,7 +50,7 @@ type VolumeRemove struct {
 // DriverSync is the subcommand for syncing two volumes
 type DriverSync struct {
 	Create bool   `description:"Indicates that the destination driver should be created" long:"create" short:"c"`
-	Type   string `description:"Type of the destination driver (btrfs|devicemapper|rsync)" long:"type" short:"t"`
+	Type   string `description:"Type of the destination driver (btrfs|devicemapper|rsync|zfs)" long:"type" short:"t"`
 	Args   struct {
 		SourcePath      flags.Filename `description:"Path of the source driver"`
 		DestinationPath flags.Filename `description:"Path of the destionation"`
@@ -607,8 +607,17 @@ func (v *BtrfsVolume) Export(label, parent string, writer io.Writer, excludes []
 	} else if !exists {
 		return volume.ErrSnapshotDoesNotExist
 	}
+	var parentpath string
+	if parent = strings.TrimSpace(parent); parent != "" {
+		if exists, err := v.snapshotExists(parent); err != nil {
+			return err
+		} else if !exists {
+			return volume.ErrSnapshotDoesNotExist
+		}
+		parentpath = v.snapshotPath(parent)
+	}
 	// TODO: add to tarfile and include metadata
-	if err := runBtrfsSend(writer, v.sudoer, parent, v.snapshotPath(label)); err != nil {
+	if err := runBtrfsSend(writer, v.sudoer, parentpath, v.snapshotPath(label)); err != nil {
 		glog.Errorf("Could not export snapshot %s: %s", label, err)
 		return err
 	}
@@ -639,6 +648,42 @@ func (v *BtrfsVolume) Import(label string, reader io.Reader) error {
 	return nil
 }
 
+// Diff implements volume.Volume.Diff
+func (v *BtrfsVolume) Diff(label, other string) ([]volume.FileChange, error) {
+	from, err := v.existingSnapshotPath(label)
+	if err != nil {
+		return nil, err
+	}
+	to := v.path
+	if other != "" {
+		if to, err = v.existingSnapshotPath(other); err != nil {
+			return nil, err
+		}
+	}
+	return volume.DiffDirectories(from, to)
+}
+
+// RestorePaths implements volume.Volume.RestorePaths
+func (v *BtrfsVolume) RestorePaths(label string, paths []string) error {
+	src, err := v.existingSnapshotPath(label)
+	if err != nil {
+		return err
+	}
+	v.Lock()
+	defer v.Unlock()
+	return volume.RestoreFromDirectory(src, v.path, paths)
+}
+
+// existingSnapshotPath gets the path to the snapshot <label>, if it exists
+func (v *BtrfsVolume) existingSnapshotPath(label string) (string, error) {
+	if exists, err := v.snapshotExists(label); err != nil {
+		return "", err
+	} else if !exists {
+		return "", volume.ErrSnapshotDoesNotExist
+	}
+	return v.snapshotPath(label), nil
+}
+
 // snapshotExists queries the snapshot existence for the given label
 func (v *BtrfsVolume) snapshotExists(label string) (exists bool, err error) {
 	rlabel := v.rawSnapshotLabel(label)
@@ -658,10 +703,11 @@ func (v *BtrfsVolume) snapshotExists(label string) (exists bool, err error) {
 
 // runBtrfsSend writes a btrfs snapshot to a write handle
 func runBtrfsSend(writer io.Writer, sudoer bool, parentpath, path string) error {
-	cmdArgs := []string{"btrfs", "send", path}
+	cmdArgs := []string{"btrfs", "send"}
 	if parentpath = strings.TrimSpace(parentpath); parentpath != "" {
 		cmdArgs = append(cmdArgs, "-p", parentpath)
 	}
+	cmdArgs = append(cmdArgs, path)
 	if sudoer {
 		cmdArgs = append([]string{"sudo", "-n"}, cmdArgs...)
 	}
@@ -62,6 +62,10 @@ func (s *BtrfsSuite) TestBtrfsSnapshots(c *C) {
 	drivertest.DriverTestSnapshots(c, "btrfs", s.root, btrfsArgs)
 }
 
+func (s *BtrfsSuite) TestBtrfsDiffRestorePaths(c *C) {
+	drivertest.DriverTestDiffRestorePaths(c, "btrfs", s.root, btrfsArgs)
+}
+
 func (s *BtrfsSuite) TestBtrfsBadSnapshots(c *C) {
 	badsnapshot := func(label string, vol volume.Volume) error {
 		//create an invalid snapshot by snapshotting and then writing garbage to .SnapshotInfo
@@ -124,3 +128,9 @@ func (s *BtrfsSuite) TestBtrfsExportImport(c *C) {
 	defer volume.CleanupTmpVolume(c, other_root)
 	drivertest.DriverTestExportImport(c, "btrfs", s.root, other_root, btrfsArgs)
 }
+
+func (s *BtrfsSuite) TestBtrfsIncrementalExportImport(c *C) {
+	other_root := volume.CreateBtrfsTmpVolume(c, 32*1024*1024)
+	defer volume.CleanupTmpVolume(c, other_root)
+	drivertest.DriverTestIncrementalExportImport(c, "btrfs", s.root, other_root, btrfsArgs)
+}
@@ -31,8 +31,8 @@ func DetectDriverType(root string) (DriverType, error) {
 		}
 		return "", err
 	}
-	for _, drivertype := range []DriverType{DriverTypeBtrFS, DriverTypeRsync, DriverTypeDeviceMapper} {
-		dirname := filepath.Join(root, fmt.Sprintf(".%s", drivertype))
+	for _, drivertype := range []DriverType{DriverTypeBtrFS, DriverTypeRsync, DriverTypeDeviceMapper, DriverTypeZFS} {
+		dirname := DriverFlagDir(root, drivertype)
 		flagfile := FlagFilePath(dirname)
 		if fi, err := os.Stat(flagfile); !os.IsNotExist(err) && fi != nil {
 			glog.V(2).Infof("Found %s file; returning %s", dirname, drivertype)
@@ -41,3 +41,13 @@ func DetectDriverType(root string) (DriverType, error) {
 	}
 	return "", ErrDriverNotInit
 }
+
+// DriverFlagDir returns the directory under root whose flag file marks root as
+// initialized by the given driver type.
+func DriverFlagDir(root string, drivertype DriverType) string {
+	if drivertype == DriverTypeZFS {
+		// ZFS reserves .zfs at the top of every dataset for snapshot access
+		return filepath.Join(root, ".zfsdriver")
+	}
+	return filepath.Join(root, fmt.Sprintf(".%s", drivertype))
+}
@@ -1271,32 +1271,25 @@ func (v *DeviceMapperVolume) Export(label, parent string, writer io.Writer, excl
 		return volume.ErrSnapshotDoesNotExist
 	}
 	label = v.rawSnapshotLabel(label)
-	mountpoint, err := ioutil.TempDir("", "serviced-export-volume-")
+	mountpoint, unmount, err := v.mountSnapshot(label)
 	if err != nil {
 		return err
 	}
-	defer os.RemoveAll(mountpoint)
-	deviceHash, err := v.Metadata.LookupSnapshotDevice(label)
-	if err != nil {
-		return err
-	}
-	glog.V(2).Infof("Mounting temporary export device %s", deviceHash)
-	if err := v.driver.DeviceSet.MountDevice(deviceHash, mountpoint, label); err != nil {
-		return err
-	}
-	defer func(d *DeviceMapperDriver, deviceHash, mountpoint string) {
-		// We use the provided UnmountDevice func here, rather than our own
-		// unmount(), because we DO care about Docker's internal bookkeeping
-		// here. Without this, DeviceSet.DeleteDevice will fail.
-		if err := d.DeviceSet.UnmountDevice(deviceHash, mountpoint); err != nil {
-			glog.V(2).Infof("Error unmounting %s (device: %s): %s", mountpoint, deviceHash, err)
+	defer unmount()
+
+	// Mount the parent snapshot to export only the changes since then
+	var parentMountpoint string
+	if parent = strings.TrimSpace(parent); parent != "" {
+		if !v.snapshotExists(parent) {
+			return volume.ErrSnapshotDoesNotExist
 		}
-		d.DeviceSet.Lock()
-		if err := d.DeactivateDevice(deviceHash); err != nil {
-			glog.V(2).Infof("Error deactivating device %s: %s", deviceHash, err)
+		parent = v.rawSnapshotLabel(parent)
+		var unmountParent func()
+		if parentMountpoint, unmountParent, err = v.mountSnapshot(parent); err != nil {
+			return err
 		}
-		d.DeviceSet.Unlock()
-	}(v.driver, deviceHash, mountpoint)
+		defer unmountParent()
+	}
 
 	tarOut := tar.NewWriter(writer)
 
@@ -1333,19 +1326,128 @@ func (v *DeviceMapperVolume) Export(label, parent string, writer io.Writer, excl
 		return err
 	}
 
+	// Set the parent snapshot, so that the import can start from a copy of it
+	if parentMountpoint != "" {
+		header = &tar.Header{Name: fmt.Sprintf("%s-parent", label), Size: int64(len(parent))}
+		if err := tarOut.WriteHeader(header); err != nil {
+			glog.Errorf("Could not export parent header for snapshot %s: %s", label, err)
+			return err
+		}
+		if _, err := tarOut.Write([]byte(parent)); err != nil {
+			glog.Errorf("Could not export parent for snapshot %s: %s", label, err)
+			return err
+		}
+	}
+
 	// Write metadata
 	mdpath := filepath.Join(v.driver.MetadataDir(), label)
 
 	if err := exportDirectoryAsTar(mdpath, fmt.Sprintf("%s-metadata", label), tarOut, []string{}); err != nil {
 		return err
 	}
-	if err := exportDirectoryAsTar(mountpoint, fmt.Sprintf("%s-volume", label), tarOut, excludes); err != nil {
-		return err
+	if parentMountpoint == "" {
+		if err := exportDirectoryAsTar(mountpoint, fmt.Sprintf("%s-volume", label), tarOut, excludes); err != nil {
+			return err
+		}
+	} else {
+		deleted, err := volume.ExportChanges(tarOut, parentMountpoint, mountpoint, fmt.Sprintf("%s-volume", label), excludes)
+		if err != nil {
+			return err
+		}
+		if err := volume.ExportDeleted(tarOut, fmt.Sprintf("%s-deleted", label), deleted); err != nil {
+			return err
+		}
 	}
 
 	return tarOut.Close()
 }
 
+// mountSnapshot mounts the device of the snapshot <label> at a temporary
+// mountpoint.  The returned func unmounts and deactivates the device again.
+func (v *DeviceMapperVolume) mountSnapshot(label string) (string, func(), error) {
+	mountpoint, err := ioutil.TempDir("", "serviced-snapshot-volume-")
+	if err != nil {
+		return "", nil, err
+	}
+	deviceHash, err := v.Metadata.LookupSnapshotDevice(label)
+	if err != nil {
+		os.RemoveAll(mountpoint)
+		return "", nil, err
+	}
+	glog.V(2).Infof("Mounting temporary snapshot device %s", deviceHash)
+	if err := v.driver.DeviceSet.MountDevice(deviceHash, mountpoint, label); err != nil {
+		os.RemoveAll(mountpoint)
+		return "", nil, err
+	}
+	unmount := func() {
+		// We use the provided UnmountDevice func here, rather than our own
+		// unmount(), because we DO care about Docker's internal bookkeeping
+		// here. Without this, DeviceSet.DeleteDevice will fail.
+		if err := v.driver.DeviceSet.UnmountDevice(deviceHash, mountpoint); err != nil {
+			glog.V(2).Infof("Error unmounting %s (device: %s): %s", mountpoint, deviceHash, err)
+		}
+		v.driver.DeviceSet.Lock()
+		if err := v.driver.DeactivateDevice(deviceHash); err != nil {
+			glog.V(2).Infof("Error deactivating device %s: %s", deviceHash, err)
+		}
+		v.driver.DeviceSet.Unlock()
+		os.RemoveAll(mountpoint)
+	}
+	return mountpoint, unmount, nil
+}
+
+// copySnapshot copies the contents of the snapshot <rawLabel> to <dest>
+func (v *DeviceMapperVolume) copySnapshot(rawLabel, dest string) error {
+	if !v.snapshotExists(rawLabel) {
+		return volume.ErrParentSnapshotMissing
+	}
+	src, unmount, err := v.mountSnapshot(rawLabel)
+	if err != nil {
+		return err
+	}
+	defer unmount()
+	return volume.CopyDirectory(src, dest)
+}
+
+// Diff implements volume.Volume.Diff
+func (v *DeviceMapperVolume) Diff(label, other string) ([]volume.FileChange, error) {
+	if !v.snapshotExists(label) {
+		return nil, volume.ErrSnapshotDoesNotExist
+	}
+	from, unmount, err := v.mountSnapshot(v.rawSnapshotLabel(label))
+	if err != nil {
+		return nil, err
+	}
+	defer unmount()
+	to := v.path
+	if other != "" {
+		if !v.snapshotExists(other) {
+			return nil, volume.ErrSnapshotDoesNotExist
+		}
+		var unmountOther func()
+		if to, unmountOther, err = v.mountSnapshot(v.rawSnapshotLabel(other)); err != nil {
+			return nil, err
+		}
+		defer unmountOther()
+	}
+	return volume.DiffDirectories(from, to)
+}
+
+// RestorePaths implements volume.Volume.RestorePaths
+func (v *DeviceMapperVolume) RestorePaths(label string, paths []string) error {
+	if !v.snapshotExists(label) {
+		return volume.ErrSnapshotDoesNotExist
+	}
+	v.Lock()
+	defer v.Unlock()
+	src, unmount, err := v.mountSnapshot(v.rawSnapshotLabel(label))
+	if err != nil {
+		return err
+	}
+	defer unmount()
+	return volume.RestoreFromDirectory(src, v.path, paths)
+}
+
 func (d *DeviceMapperDriver) Status() (volume.Status, error) {
 	glog.V(2).Info("devicemapper.Status()")
 	dockerStatus := d.DeviceSet.Status()
@@ -1641,10 +1743,12 @@ func (v *DeviceMapperVolume) loadSnapshotImport(reader io.Reader, label, deviceH
 
 	// Read the volume and metadata from the stream and write to disk
 	var (
-		driverFile = label + "-driver"   // Filesystem type of export volume
-		deviceFile = label + "-device"   // Information about the device (if available)
-		volumeDir  = label + "-volume"   // Volume data
-		metaDir    = label + "-metadata" // Metadata
+		driverFile  = label + "-driver"   // Filesystem type of export volume
+		deviceFile  = label + "-device"   // Information about the device (if available)
+		parentFile  = label + "-parent"   // Parent snapshot of an incremental export
+		deletedFile = label + "-deleted"  // Paths deleted since the parent snapshot
+		volumeDir   = label + "-volume"   // Volume data
+		metaDir     = label + "-metadata" // Metadata
 	)
 	driverType := ""
 	tarfile := tar.NewReader(reader)
@@ -1694,6 +1798,25 @@ func (v *DeviceMapperVolume) loadSnapshotImport(reader io.Reader, label, deviceH
 				}
 				glog.V(2).Infof("Device %s is now %s", deviceHash, units.HumanSize(float64(volInfo.Size)))
 			}
+		} else if header.Name == parentFile {
+
+			// Start from a copy of the parent snapshot, so that the changes
+			// in an incremental export can be applied to it.
+			bfr := &bytes.Buffer{}
+			if _, err := bfr.ReadFrom(tarfile); err != nil {
+				glog.Errorf("Could not read from %s: %s", parentFile, err)
+				return err
+			}
+			if err := v.copySnapshot(bfr.String(), mountpoint); err != nil {
+				glog.Errorf("Could not copy parent snapshot %s for snapshot %s: %s", bfr.String(), label, err)
+				return err
+			}
+		} else if header.Name == deletedFile {
+
+			// Remove the paths deleted since the parent snapshot
+			if err := volume.RemoveDeleted(tarfile, mountpoint); err != nil {
+				return err
+			}
 		} else if strings.HasPrefix(header.Name, volumeDir) {
 
 			// Untar into mountpoint
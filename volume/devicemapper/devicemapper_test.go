@@ -117,6 +117,10 @@ func (s *DeviceMapperSuite) TestDeviceMapperSnapshots(c *C) {
 	drivertest.DriverTestSnapshots(c, "devicemapper", "", devmapArgs)
 }
 
+func (s *DeviceMapperSuite) TestDeviceMapperDiffRestorePaths(c *C) {
+	drivertest.DriverTestDiffRestorePaths(c, "devicemapper", "", devmapArgs)
+}
+
 func (s *DeviceMapperSuite) TestDeviceMapperSnapshotTags(c *C) {
 	drivertest.DriverTestSnapshotTags(c, "devicemapper", "", devmapArgs)
 }
@@ -125,6 +129,10 @@ func (s *DeviceMapperSuite) TestDeviceMapperExportImport(c *C) {
 	drivertest.DriverTestExportImport(c, "devicemapper", "", "", devmapArgs)
 }
 
+func (s *DeviceMapperSuite) TestDeviceMapperIncrementalExportImport(c *C) {
+	drivertest.DriverTestIncrementalExportImport(c, "devicemapper", "", "", devmapArgs)
+}
+
 func (s *DeviceMapperSuite) TestDeviceMapperExcludeDirs(c *C) {
 
 	// Set up import/export volumes
@@ -0,0 +1,181 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package volume
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/zenoss/glog"
+)
+
+// ChangeType describes how a file differs between two versions of a volume
+type ChangeType string
+
+const (
+	FileAdded    ChangeType = "added"
+	FileModified ChangeType = "modified"
+	FileDeleted  ChangeType = "deleted"
+)
+
+// FileChange is a file that differs between two versions of a volume.  When a
+// directory is added or deleted, only the directory itself is listed.
+type FileChange struct {
+	Path    string
+	Change  ChangeType
+	IsDir   bool
+	Size    int64
+	ModTime time.Time
+}
+
+// DiffDirectories compares the tree at <to> against the tree at <from> and
+// returns the changes, sorted by path.  Files are considered modified when
+// their type, permissions, ownership, size or modification time differ.
+func DiffDirectories(from, to string) ([]FileChange, error) {
+	changes := []FileChange{}
+	if err := diffDirectory(from, to, "", &changes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+func diffDirectory(from, to, rel string, changes *[]FileChange) error {
+	fromFiles, err := readDirMap(filepath.Join(from, rel))
+	if err != nil {
+		return err
+	}
+	toFiles, err := readDirMap(filepath.Join(to, rel))
+	if err != nil {
+		return err
+	}
+	names := []string{}
+	for name := range fromFiles {
+		names = append(names, name)
+	}
+	for name := range toFiles {
+		if _, ok := fromFiles[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		path := filepath.Join(rel, name)
+		// snapshot metadata is rewritten with every snapshot
+		if path == ".SNAPSHOTINFO" {
+			continue
+		}
+		fromFile, inFrom := fromFiles[name]
+		toFile, inTo := toFiles[name]
+		switch {
+		case !inTo:
+			*changes = append(*changes, newFileChange(path, FileDeleted, fromFile))
+		case !inFrom:
+			*changes = append(*changes, newFileChange(path, FileAdded, toFile))
+		case fromFile.IsDir() && toFile.IsDir():
+			if fileChanged(filepath.Join(from, path), filepath.Join(to, path), fromFile, toFile) {
+				*changes = append(*changes, newFileChange(path, FileModified, toFile))
+			}
+			if err := diffDirectory(from, to, path, changes); err != nil {
+				return err
+			}
+		case fileChanged(filepath.Join(from, path), filepath.Join(to, path), fromFile, toFile):
+			*changes = append(*changes, newFileChange(path, FileModified, toFile))
+		}
+	}
+	return nil
+}
+
+func readDirMap(dir string) (map[string]os.FileInfo, error) {
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		glog.Errorf("Could not read directory %s: %s", dir, err)
+		return nil, err
+	}
+	files := make(map[string]os.FileInfo)
+	for _, fi := range fis {
+		files[fi.Name()] = fi
+	}
+	return files, nil
+}
+
+func newFileChange(path string, change ChangeType, fi os.FileInfo) FileChange {
+	return FileChange{
+		Path:    path,
+		Change:  change,
+		IsDir:   fi.IsDir(),
+		Size:    fi.Size(),
+		ModTime: fi.ModTime(),
+	}
+}
+
+// fileChanged does the same quick check as rsync, so that directories are
+// compared by their attributes only and never by their modification time.
+func fileChanged(fromPath, toPath string, from, to os.FileInfo) bool {
+	if from.Mode() != to.Mode() {
+		return true
+	}
+	if fromStat, ok := from.Sys().(*syscall.Stat_t); ok {
+		if toStat, ok := to.Sys().(*syscall.Stat_t); ok {
+			if fromStat.Uid != toStat.Uid || fromStat.Gid != toStat.Gid {
+				return true
+			}
+		}
+	}
+	switch {
+	case from.IsDir():
+		return false
+	case from.Mode()&os.ModeSymlink != 0:
+		fromLink, _ := os.Readlink(fromPath)
+		toLink, _ := os.Readlink(toPath)
+		return fromLink != toLink
+	}
+	return from.Size() != to.Size() || !from.ModTime().Equal(to.ModTime())
+}
+
+// RestoreFromDirectory replaces <paths> under <dest> with their copies under
+// <src>.  Paths are relative to both roots and may not name the root itself.
+func RestoreFromDirectory(src, dest string, paths []string) error {
+	if len(paths) == 0 {
+		return ErrInvalidRestorePath
+	}
+	args := []string{"-a", "--del", "--force", "--relative"}
+	for _, path := range paths {
+		rel := strings.TrimPrefix(filepath.Clean("/"+path), "/")
+		if rel == "" {
+			return ErrInvalidRestorePath
+		}
+		if _, err := os.Lstat(filepath.Join(src, rel)); os.IsNotExist(err) {
+			glog.Errorf("Path %s does not exist under %s", rel, src)
+			return ErrPathNotInSnapshot
+		} else if err != nil {
+			return err
+		}
+		// the /./ marks where the relative path starts for rsync
+		args = append(args, src+"/./"+rel)
+	}
+	args = append(args, dest+"/")
+	rsync := exec.Command("rsync", args...)
+	glog.V(1).Infof("About to execute: %s", rsync)
+	if output, err := rsync.CombinedOutput(); err != nil {
+		glog.Errorf("Could not restore paths from %s: %s (%s)", src, output, err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build unit
+
+package volume_test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	. "github.com/control-center/serviced/volume"
+	. "gopkg.in/check.v1"
+)
+
+type DiffSuite struct {
+	from string
+	to   string
+}
+
+var _ = Suite(&DiffSuite{})
+
+func (s *DiffSuite) SetUpTest(c *C) {
+	s.from = c.MkDir()
+	s.to = c.MkDir()
+	mtime := time.Now().Add(-time.Hour)
+	for _, root := range []string{s.from, s.to} {
+		c.Assert(os.MkdirAll(filepath.Join(root, "etc", "conf.d"), 0755), IsNil)
+		for _, name := range []string{"etc/app.conf", "etc/conf.d/a.conf", "data"} {
+			path := filepath.Join(root, name)
+			c.Assert(ioutil.WriteFile(path, []byte(name), 0644), IsNil)
+			c.Assert(os.Chtimes(path, mtime, mtime), IsNil)
+		}
+	}
+}
+
+func (s *DiffSuite) TestDiffDirectoriesUnchanged(c *C) {
+	changes, err := DiffDirectories(s.from, s.to)
+	c.Assert(err, IsNil)
+	c.Assert(changes, HasLen, 0)
+}
+
+func (s *DiffSuite) TestDiffDirectories(c *C) {
+	// modify a file's content
+	c.Assert(ioutil.WriteFile(filepath.Join(s.to, "data"), []byte("new data"), 0644), IsNil)
+	// change a file's permissions
+	c.Assert(os.Chmod(filepath.Join(s.to, "etc", "app.conf"), 0600), IsNil)
+	// delete a whole directory
+	c.Assert(os.RemoveAll(filepath.Join(s.to, "etc", "conf.d")), IsNil)
+	// add a file and a directory
+	c.Assert(ioutil.WriteFile(filepath.Join(s.to, "etc", "new.conf"), []byte("new"), 0644), IsNil)
+	c.Assert(os.MkdirAll(filepath.Join(s.to, "logs", "app"), 0755), IsNil)
+	// snapshot metadata is ignored
+	c.Assert(ioutil.WriteFile(filepath.Join(s.to, ".SNAPSHOTINFO"), []byte("{}"), 0644), IsNil)
+
+	changes, err := DiffDirectories(s.from, s.to)
+	c.Assert(err, IsNil)
+	summary := make([][]interface{}, len(changes))
+	for i, change := range changes {
+		summary[i] = []interface{}{change.Path, change.Change, change.IsDir}
+	}
+	c.Assert(summary, DeepEquals, [][]interface{}{
+		{"data", FileModified, false},
+		{"etc/app.conf", FileModified, false},
+		{"etc/conf.d", FileDeleted, true},
+		{"etc/new.conf", FileAdded, false},
+		{"logs", FileAdded, true},
+	})
+}
+
+func (s *DiffSuite) TestDiffDirectoriesMissingRoot(c *C) {
+	_, err := DiffDirectories(s.from, filepath.Join(s.to, "missing"))
+	c.Assert(err, NotNil)
+}
+
+func (s *DiffSuite) TestRestoreFromDirectoryBadPaths(c *C) {
+	err := RestoreFromDirectory(s.from, s.to, []string{})
+	c.Assert(err, Equals, ErrInvalidRestorePath)
+	err = RestoreFromDirectory(s.from, s.to, []string{"/"})
+	c.Assert(err, Equals, ErrInvalidRestorePath)
+	err = RestoreFromDirectory(s.from, s.to, []string{"../.."})
+	c.Assert(err, Equals, ErrInvalidRestorePath)
+	err = RestoreFromDirectory(s.from, s.to, []string{"data", "etc/missing.conf"})
+	c.Assert(err, Equals, ErrPathNotInSnapshot)
+}
+
+func (s *DiffSuite) TestRestoreFromDirectory(c *C) {
+	if _, err := exec.LookPath("rsync"); err != nil {
+		c.Skip("rsync is not installed")
+	}
+	c.Assert(ioutil.WriteFile(filepath.Join(s.to, "data"), []byte("new data"), 0644), IsNil)
+	c.Assert(os.RemoveAll(filepath.Join(s.to, "etc")), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(s.to, "other"), []byte("other"), 0644), IsNil)
+
+	err := RestoreFromDirectory(s.from, s.to, []string{"/etc/conf.d/", "data"})
+	c.Assert(err, IsNil)
+
+	changes, err := DiffDirectories(s.from, s.to)
+	c.Assert(err, IsNil)
+	summary := []string{}
+	for _, change := range changes {
+		summary = append(summary, string(change.Change)+" "+change.Path)
+	}
+	// etc/app.conf was not restored, and other is left alone
+	c.Assert(summary, DeepEquals, []string{"deleted etc/app.conf", "added other"})
+}
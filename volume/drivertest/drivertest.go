@@ -562,3 +562,96 @@ func DriverTestExportImport(c *C, drivername volume.DriverType, exportfs, import
 	c.Assert(vol2.Rollback("Backup"), IsNil)
 	verifyBaseWithExtra(c, importDriver, vol2)
 }
+
+func DriverTestIncrementalExportImport(c *C, drivername volume.DriverType, exportfs, importfs string, args []string) {
+	exportDriver := newDriver(c, drivername, exportfs, args)
+	defer cleanup(c, exportDriver)
+	importDriver := newDriver(c, drivername, importfs, args)
+	defer cleanup(c, importDriver)
+
+	// Write a large file that does not change between the snapshots
+	vol := createBase(c, exportDriver, "Base")
+	bigdata := bytes.Repeat([]byte("unchanged data "), 1<<16)
+	err := ioutil.WriteFile(path.Join(vol.Path(), "bigfile"), bigdata, 0644)
+	c.Assert(err, IsNil)
+	c.Assert(vol.Snapshot("Full", "", []string{}), IsNil)
+
+	// Change the volume and take another snapshot
+	writeExtra(c, exportDriver, vol, "differentfile")
+	c.Assert(os.Remove(path.Join(vol.Path(), "a file")), IsNil)
+	c.Assert(vol.Snapshot("Incremental", "", []string{}), IsNil)
+
+	full, incremental := new(bytes.Buffer), new(bytes.Buffer)
+	c.Assert(vol.Export("Base_Full", "", full, []string{}), IsNil)
+	c.Assert(vol.Export("Base_Incremental", "Base_Full", incremental, []string{}), IsNil)
+	err = vol.Export("Base_Incremental", "Base_Missing", new(bytes.Buffer), []string{})
+	c.Assert(err, Equals, volume.ErrSnapshotDoesNotExist)
+
+	// The incremental export only holds the changes
+	c.Assert(full.Len() > len(bigdata), Equals, true)
+	c.Assert(incremental.Len() < len(bigdata), Equals, true)
+
+	// Import both snapshots, in order
+	vol2 := createBase(c, importDriver, "Base")
+	c.Assert(vol2.Import("Base_Full", full), IsNil)
+	c.Assert(vol2.Import("Base_Incremental", incremental), IsNil)
+	snapshots, err := vol2.Snapshots()
+	c.Assert(err, IsNil)
+	c.Assert(snapshots, HasLen, 2)
+
+	c.Assert(vol2.Rollback("Incremental"), IsNil)
+	data, err := ioutil.ReadFile(path.Join(vol2.Path(), "bigfile"))
+	c.Assert(err, IsNil)
+	c.Assert(bytes.Equal(data, bigdata), Equals, true)
+	verifyFile(c, path.Join(vol2.Path(), "differentfile"), 0222|os.ModeSetuid, 0, 0)
+	_, err = os.Stat(path.Join(vol2.Path(), "a file"))
+	c.Assert(os.IsNotExist(err), Equals, true)
+}
+
+func DriverTestDiffRestorePaths(c *C, drivername volume.DriverType, root string, args []string) {
+	driver := newDriver(c, drivername, root, args)
+	defer cleanup(c, driver)
+
+	vol := createBase(c, driver, "Base")
+	verifyBase(c, driver, vol)
+	err := vol.Snapshot("Snap", "snapshot-message-0", []string{})
+	c.Assert(err, IsNil)
+
+	// Nothing has changed since the snapshot
+	changes, err := vol.Diff("Snap", "")
+	c.Assert(err, IsNil)
+	c.Assert(changes, HasLen, 0)
+
+	// Delete a file and add another one
+	c.Assert(os.Remove(path.Join(vol.Path(), "a file")), IsNil)
+	writeExtra(c, driver, vol, "differentfile")
+	changes, err = vol.Diff("Snap", "")
+	c.Assert(err, IsNil)
+	c.Assert(changes, HasLen, 2)
+	c.Check(changes[0].Path, Equals, "a file")
+	c.Check(changes[0].Change, Equals, volume.FileDeleted)
+	c.Check(changes[1].Path, Equals, "differentfile")
+	c.Check(changes[1].Change, Equals, volume.FileAdded)
+
+	// Compare two snapshots
+	err = vol.Snapshot("Snap2", "snapshot-message-1", []string{})
+	c.Assert(err, IsNil)
+	changes2, err := vol.Diff("Snap", "Snap2")
+	c.Assert(err, IsNil)
+	c.Assert(changes2, DeepEquals, changes)
+	_, err = vol.Diff("Snap", "Snap3")
+	c.Assert(err, Equals, volume.ErrSnapshotDoesNotExist)
+
+	// Restore the deleted file, and leave the added one in place
+	err = vol.RestorePaths("Snap", []string{"a file"})
+	c.Assert(err, IsNil)
+	verifyBaseWithExtra(c, driver, vol)
+
+	err = vol.RestorePaths("Snap", []string{"not a file"})
+	c.Assert(err, Equals, volume.ErrPathNotInSnapshot)
+	err = vol.RestorePaths("Snap3", []string{"a file"})
+	c.Assert(err, Equals, volume.ErrSnapshotDoesNotExist)
+
+	c.Assert(driver.Remove("Base"), IsNil)
+	c.Assert(driver.Exists("Base"), Equals, false)
+}
@@ -138,9 +138,17 @@ func ImportArchive(tarfile *tar.Reader, path string) error {
 	return nil
 }
 
-// ImportArchiveHeader imports a tarfile header to a particular path
+// ImportArchiveHeader imports a tarfile header to a particular path,
+// replacing any file that is already there
 func ImportArchiveHeader(header *tar.Header, reader io.Reader, path string) error {
 	filename := filepath.Join(path, header.Name)
+	// an incremental import may replace a file with one of a different type
+	if fstat, err := os.Lstat(filename); err == nil && (!fstat.IsDir() || header.Typeflag != tar.TypeDir) {
+		if err := os.RemoveAll(filename); err != nil {
+			glog.Errorf("Could not replace %s: %s", filename, err)
+			return err
+		}
+	}
 	switch header.Typeflag {
 	case tar.TypeDir:
 		if err := os.MkdirAll(filename, 0755); err != nil {
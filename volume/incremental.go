@@ -0,0 +1,155 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package volume
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/zenoss/glog"
+)
+
+// ErrParentSnapshotMissing is returned when an incremental export is imported
+// into a volume that does not have the snapshot it was exported against.
+var ErrParentSnapshotMissing = errors.New("parent snapshot of incremental export does not exist")
+
+// ExportChanges writes the files under <path> that differ from <parentpath>
+// into a tar Writer under <name>, skipping any excluded paths.  Added and
+// modified files are written in full, and directories whose attributes
+// changed are written without their contents.  The paths that were deleted
+// are returned so they can be written with ExportDeleted.
+func ExportChanges(tarfile *tar.Writer, parentpath, path, name string, excludes []string) ([]string, error) {
+	changes, err := DiffDirectories(parentpath, path)
+	if err != nil {
+		return nil, err
+	}
+	if err := exportDirectoryHeader(tarfile, path, name); err != nil {
+		return nil, err
+	}
+	deleted := []string{}
+	for _, change := range changes {
+		if isExcluded(change.Path, excludes) {
+			continue
+		}
+		fullpath, relpath := filepath.Join(path, change.Path), filepath.Join(name, change.Path)
+		switch {
+		case change.Change == FileDeleted:
+			deleted = append(deleted, change.Path)
+		case change.IsDir && change.Change == FileModified && isDirectory(filepath.Join(parentpath, change.Path)):
+			err = exportDirectoryHeader(tarfile, fullpath, relpath)
+		case change.IsDir:
+			err = ExportDirectory(tarfile, fullpath, relpath)
+		default:
+			err = ExportFile(tarfile, fullpath, relpath)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return deleted, nil
+}
+
+// ExportDeleted writes the paths deleted since the parent snapshot into a tar
+// Writer as the file <name>.
+func ExportDeleted(tarfile *tar.Writer, name string, paths []string) error {
+	data, err := json.Marshal(paths)
+	if err != nil {
+		return err
+	}
+	header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(data))}
+	if err := tarfile.WriteHeader(header); err != nil {
+		glog.Errorf("Could not write header for deleted paths: %s", err)
+		return err
+	}
+	_, err = tarfile.Write(data)
+	return err
+}
+
+// RemoveDeleted reads the paths written by ExportDeleted and removes them
+// from <path>.
+func RemoveDeleted(reader io.Reader, path string) error {
+	var paths []string
+	if err := json.NewDecoder(reader).Decode(&paths); err != nil {
+		glog.Errorf("Could not read deleted paths: %s", err)
+		return err
+	}
+	for _, p := range paths {
+		rel := strings.TrimPrefix(filepath.Clean("/"+p), "/")
+		if rel == "" {
+			return ErrInvalidRestorePath
+		}
+		if err := os.RemoveAll(filepath.Join(path, rel)); err != nil {
+			glog.Errorf("Could not remove %s: %s", rel, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// CopyDirectory replaces the contents of <dest> with a copy of <src>, so that
+// an incremental export can be imported on top of its parent snapshot.
+func CopyDirectory(src, dest string) error {
+	rsync := exec.Command("rsync", "-a", "--del", "--force", src+"/", dest+"/")
+	glog.V(1).Infof("About to execute: %s", rsync)
+	if output, err := rsync.CombinedOutput(); err != nil {
+		glog.Errorf("Could not copy %s to %s: %s (%s)", src, dest, output, err)
+		return err
+	}
+	return nil
+}
+
+// exportDirectoryHeader writes the header of a directory, without its
+// contents, into a tar Writer.
+func exportDirectoryHeader(tarfile *tar.Writer, path, name string) error {
+	fstat, err := os.Stat(path)
+	if err != nil {
+		glog.Errorf("Could not stat %s: %s", path, err)
+		return err
+	}
+	header, err := getHeader(name, "", fstat)
+	if err != nil {
+		return err
+	}
+	if err := tarfile.WriteHeader(header); err != nil {
+		glog.Errorf("Could not write header for directory %s: %s", path, err)
+		return err
+	}
+	return nil
+}
+
+// isExcluded returns true if the relative path is one of the excluded
+// directories, is inside of one, or is the marker of one.
+func isExcluded(path string, excludes []string) bool {
+	for _, exclude := range excludes {
+		exclude = strings.Trim(filepath.Clean("/"+exclude), "/")
+		if exclude == "" {
+			continue
+		}
+		if path == exclude || strings.HasPrefix(path, exclude+"/") || path == "."+exclude+".serviced.initialized" {
+			return true
+		}
+	}
+	return false
+}
+
+func isDirectory(path string) bool {
+	fstat, err := os.Lstat(path)
+	return err == nil && fstat.IsDir()
+}
@@ -0,0 +1,176 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build unit
+
+package volume_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/control-center/serviced/volume"
+	. "gopkg.in/check.v1"
+)
+
+type IncrementalSuite struct {
+	parent string
+	child  string
+}
+
+var _ = Suite(&IncrementalSuite{})
+
+func (s *IncrementalSuite) SetUpTest(c *C) {
+	s.parent = c.MkDir()
+	s.child = c.MkDir()
+	mtime := time.Now().Add(-time.Hour)
+	for _, root := range []string{s.parent, s.child} {
+		c.Assert(os.MkdirAll(filepath.Join(root, "etc", "conf.d"), 0755), IsNil)
+		for _, name := range []string{"etc/app.conf", "etc/conf.d/a.conf", "data", "unchanged"} {
+			path := filepath.Join(root, name)
+			c.Assert(ioutil.WriteFile(path, []byte(name), 0644), IsNil)
+			c.Assert(os.Chtimes(path, mtime, mtime), IsNil)
+		}
+	}
+
+	// modify a file, delete a directory, change the permissions of a
+	// directory and add a directory
+	c.Assert(ioutil.WriteFile(filepath.Join(s.child, "data"), []byte("new data"), 0644), IsNil)
+	c.Assert(os.RemoveAll(filepath.Join(s.child, "etc", "conf.d")), IsNil)
+	c.Assert(os.Chmod(filepath.Join(s.child, "etc"), 0700), IsNil)
+	c.Assert(os.MkdirAll(filepath.Join(s.child, "logs", "app"), 0755), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(s.child, "logs", "app", "out.log"), []byte("log"), 0644), IsNil)
+	// and add to an excluded directory
+	c.Assert(os.MkdirAll(filepath.Join(s.child, "cache"), 0755), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(s.child, "cache", "tmp"), []byte("tmp"), 0644), IsNil)
+}
+
+// exportChanges exports the changes from the parent to the child
+func (s *IncrementalSuite) exportChanges(c *C) *bytes.Buffer {
+	buffer := &bytes.Buffer{}
+	tarfile := tar.NewWriter(buffer)
+	deleted, err := ExportChanges(tarfile, s.parent, s.child, "label-volume", []string{"/cache"})
+	c.Assert(err, IsNil)
+	c.Assert(ExportDeleted(tarfile, "label-deleted", deleted), IsNil)
+	c.Assert(tarfile.Close(), IsNil)
+	return buffer
+}
+
+// importTar imports the entries of an archive under <prefix> into <dest>,
+// and removes the deleted paths.
+func importTar(c *C, r io.Reader, prefix, dest string) {
+	tarfile := tar.NewReader(r)
+	for {
+		header, err := tarfile.Next()
+		if err == io.EOF {
+			return
+		}
+		c.Assert(err, IsNil)
+		if header.Name == "label-deleted" {
+			c.Assert(RemoveDeleted(tarfile, dest), IsNil)
+			continue
+		}
+		header.Name = strings.TrimPrefix(header.Name, prefix)
+		c.Assert(ImportArchiveHeader(header, tarfile, dest), IsNil)
+	}
+}
+
+func (s *IncrementalSuite) TestExportChanges(c *C) {
+	buffer := s.exportChanges(c)
+
+	names := []string{}
+	var deleted string
+	tarfile := tar.NewReader(buffer)
+	for {
+		header, err := tarfile.Next()
+		if err == io.EOF {
+			break
+		}
+		c.Assert(err, IsNil)
+		names = append(names, header.Name)
+		if header.Name == "label-deleted" {
+			data, err := ioutil.ReadAll(tarfile)
+			c.Assert(err, IsNil)
+			deleted = string(data)
+		}
+	}
+	// unchanged files and excluded directories are not exported
+	c.Assert(names, DeepEquals, []string{
+		"label-volume",
+		"label-volume/data",
+		"label-volume/etc",
+		"label-volume/logs",
+		"label-volume/logs/app",
+		"label-volume/logs/app/out.log",
+		"label-deleted",
+	})
+	c.Assert(deleted, Equals, `["etc/conf.d"]`)
+}
+
+func (s *IncrementalSuite) TestImportChanges(c *C) {
+	dest := c.MkDir()
+
+	// start from a copy of the parent
+	buffer := &bytes.Buffer{}
+	tarfile := tar.NewWriter(buffer)
+	c.Assert(ExportDirectory(tarfile, s.parent, "label-volume"), IsNil)
+	c.Assert(tarfile.Close(), IsNil)
+	importTar(c, buffer, "label-volume", dest)
+
+	// and apply the changes
+	importTar(c, s.exportChanges(c), "label-volume", dest)
+
+	for _, name := range []string{"etc/app.conf", "data", "unchanged", "logs/app/out.log"} {
+		expected, err := ioutil.ReadFile(filepath.Join(s.child, name))
+		c.Assert(err, IsNil)
+		actual, err := ioutil.ReadFile(filepath.Join(dest, name))
+		c.Assert(err, IsNil)
+		c.Check(string(actual), Equals, string(expected), Commentf("contents of %s", name))
+	}
+	_, err := os.Stat(filepath.Join(dest, "etc", "conf.d"))
+	c.Check(os.IsNotExist(err), Equals, true)
+	_, err = os.Stat(filepath.Join(dest, "cache"))
+	c.Check(os.IsNotExist(err), Equals, true)
+	fstat, err := os.Stat(filepath.Join(dest, "etc"))
+	c.Assert(err, IsNil)
+	c.Check(fstat.Mode().Perm(), Equals, os.FileMode(0700))
+}
+
+func (s *IncrementalSuite) TestImportReplacesFileType(c *C) {
+	dest := c.MkDir()
+	c.Assert(os.Symlink("/etc/passwd", filepath.Join(dest, "data")), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(dest, "logs"), []byte("file"), 0644), IsNil)
+
+	// a regular file replaces a symlink, and a directory replaces a file
+	importTar(c, s.exportChanges(c), "label-volume", dest)
+
+	fstat, err := os.Lstat(filepath.Join(dest, "data"))
+	c.Assert(err, IsNil)
+	c.Check(fstat.Mode().IsRegular(), Equals, true)
+	fstat, err = os.Lstat(filepath.Join(dest, "logs"))
+	c.Assert(err, IsNil)
+	c.Check(fstat.IsDir(), Equals, true)
+}
+
+func (s *IncrementalSuite) TestRemoveDeletedBadPaths(c *C) {
+	err := RemoveDeleted(strings.NewReader(`["/"]`), s.child)
+	c.Assert(err, Equals, ErrInvalidRestorePath)
+	err = RemoveDeleted(strings.NewReader(`not json`), s.child)
+	c.Assert(err, NotNil)
+}
@@ -249,3 +249,36 @@ func (_m *Volume) Tenant() string {
 
 	return r0
 }
+func (_m *Volume) Diff(label string, other string) ([]volume.FileChange, error) {
+	ret := _m.Called(label, other)
+
+	var r0 []volume.FileChange
+	if rf, ok := ret.Get(0).(func(string, string) []volume.FileChange); ok {
+		r0 = rf(label, other)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]volume.FileChange)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(label, other)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+func (_m *Volume) RestorePaths(label string, paths []string) error {
+	ret := _m.Called(label, paths)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, []string) error); ok {
+		r0 = rf(label, paths)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
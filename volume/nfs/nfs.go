@@ -248,6 +248,16 @@ func (v *NFSVolume) Export(label, parent string, writer io.Writer, excludes []st
 	return ErrNotSupported
 }
 
+// Diff implements volume.Volume.Diff
+func (v *NFSVolume) Diff(label, other string) ([]volume.FileChange, error) {
+	return nil, ErrNotSupported
+}
+
+// RestorePaths implements volume.Volume.RestorePaths
+func (v *NFSVolume) RestorePaths(label string, paths []string) error {
+	return ErrNotSupported
+}
+
 // Import implements volume.Volume.Import
 func (v *NFSVolume) Import(label string, reader io.Reader) error {
 	return ErrNotSupported
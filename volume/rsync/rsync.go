@@ -648,6 +648,45 @@ func (v *RsyncVolume) Rollback(label string) (err error) {
 	return nil
 }
 
+// Diff implements volume.Volume.Diff
+func (v *RsyncVolume) Diff(label, other string) ([]volume.FileChange, error) {
+	v.Lock()
+	defer v.Unlock()
+	from, err := v.existingSnapshotPath(label)
+	if err != nil {
+		return nil, err
+	}
+	to := v.Path()
+	if other != "" {
+		if to, err = v.existingSnapshotPath(other); err != nil {
+			return nil, err
+		}
+	}
+	return volume.DiffDirectories(from, to)
+}
+
+// RestorePaths implements volume.Volume.RestorePaths
+func (v *RsyncVolume) RestorePaths(label string, paths []string) error {
+	v.Lock()
+	defer v.Unlock()
+	src, err := v.existingSnapshotPath(label)
+	if err != nil {
+		return err
+	}
+	return volume.RestoreFromDirectory(src, v.Path(), paths)
+}
+
+// existingSnapshotPath gets the path to the snapshot <label>, if it exists
+func (v *RsyncVolume) existingSnapshotPath(label string) (string, error) {
+	path := v.snapshotPath(label)
+	if exists, err := volume.IsDir(path); err != nil {
+		return "", err
+	} else if !exists {
+		return "", volume.ErrSnapshotDoesNotExist
+	}
+	return path, nil
+}
+
 // Export implements volume.Volume.Export
 func (v *RsyncVolume) Export(label, parent string, writer io.Writer, excludes []string) error {
 	if len(excludes) > 0 {
@@ -672,6 +711,25 @@ func (v *RsyncVolume) Export(label, parent string, writer io.Writer, excludes []
 		glog.Errorf("Could not export driver type: %s", err)
 		return err
 	}
+	// set the parent snapshot, so it can be copied before the changes are
+	// imported
+	var parentpath string
+	if parent = strings.TrimSpace(parent); parent != "" {
+		var err error
+		if parentpath, err = v.existingSnapshotPath(parent); err != nil {
+			return err
+		}
+		parent = v.rawSnapshotLabel(parent)
+		header := &tar.Header{Name: fmt.Sprintf("%s-parent", label), Size: int64(len([]byte(parent)))}
+		if err := tarfile.WriteHeader(header); err != nil {
+			glog.Errorf("Could not export parent snapshot header: %s", err)
+			return err
+		}
+		if _, err := fmt.Fprint(tarfile, parent); err != nil {
+			glog.Errorf("Could not export parent snapshot: %s", err)
+			return err
+		}
+	}
 	// write metadata
 	mdpath := filepath.Join(v.driver.MetadataDir(), label)
 	if err := volume.ExportDirectory(tarfile, mdpath, fmt.Sprintf("%s-metadata", label)); err != nil {
@@ -679,10 +737,14 @@ func (v *RsyncVolume) Export(label, parent string, writer io.Writer, excludes []
 	}
 	// write volume
 	volpath := v.snapshotPath(label)
-	if err := volume.ExportDirectory(tarfile, volpath, fmt.Sprintf("%s-volume", label)); err != nil {
+	if parentpath == "" {
+		return volume.ExportDirectory(tarfile, volpath, fmt.Sprintf("%s-volume", label))
+	}
+	deleted, err := volume.ExportChanges(tarfile, parentpath, volpath, fmt.Sprintf("%s-volume", label), nil)
+	if err != nil {
 		return err
 	}
-	return nil
+	return volume.ExportDeleted(tarfile, fmt.Sprintf("%s-deleted", label), deleted)
 }
 
 // Import implements volume.Volume.Import
@@ -696,6 +758,8 @@ func (v *RsyncVolume) Import(label string, reader io.Reader) error {
 		return volume.ErrSnapshotExists
 	}
 	driverfile := fmt.Sprintf("%s-driver", label)
+	parentfile := fmt.Sprintf("%s-parent", label)
+	deletedfile := fmt.Sprintf("%s-deleted", label)
 	volumedir := fmt.Sprintf("%s-volume", label)
 	metadatadir := fmt.Sprintf("%s-metadata", label)
 	var drivertype string
@@ -714,6 +778,27 @@ func (v *RsyncVolume) Import(label string, reader io.Reader) error {
 				return err
 			}
 			drivertype = buf.String()
+		} else if header.Name == parentfile {
+			// an incremental export is imported on top of a copy of its
+			// parent snapshot
+			buf := bytes.NewBufferString("")
+			if _, err := buf.ReadFrom(tarfile); err != nil {
+				return err
+			}
+			parentpath := v.snapshotPath(buf.String())
+			if exists, err := volume.IsDir(parentpath); err != nil {
+				return err
+			} else if !exists {
+				glog.Errorf("Could not import snapshot %s: parent snapshot %s does not exist", label, buf.String())
+				return volume.ErrParentSnapshotMissing
+			}
+			if err := volume.CopyDirectory(parentpath, v.snapshotPath(label)); err != nil {
+				return err
+			}
+		} else if header.Name == deletedfile {
+			if err := volume.RemoveDeleted(tarfile, v.snapshotPath(label)); err != nil {
+				return err
+			}
 		} else if strings.HasPrefix(header.Name, volumedir) {
 			header.Name = strings.Replace(header.Name, volumedir, label, 1)
 			if err := volume.ImportArchiveHeader(header, tarfile, v.driver.Root()); err != nil {
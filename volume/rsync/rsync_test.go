@@ -61,6 +61,10 @@ func (s *RsyncSuite) TestRsyncSnapshots(c *C) {
 	drivertest.DriverTestSnapshots(c, "rsync", "", rsyncArgs)
 }
 
+func (s *RsyncSuite) TestRsyncDiffRestorePaths(c *C) {
+	drivertest.DriverTestDiffRestorePaths(c, "rsync", "", rsyncArgs)
+}
+
 func (s *RsyncSuite) TestRsyncSnapshotTags(c *C) {
 	drivertest.DriverTestSnapshotTags(c, "rsync", "", rsyncArgs)
 }
@@ -69,6 +73,10 @@ func (s *RsyncSuite) TestRsyncExportImport(c *C) {
 	drivertest.DriverTestExportImport(c, "rsync", "", "", rsyncArgs)
 }
 
+func (s *RsyncSuite) TestRsyncIncrementalExportImport(c *C) {
+	drivertest.DriverTestIncrementalExportImport(c, "rsync", "", "", rsyncArgs)
+}
+
 func (s *RsyncSuite) TestRsyncBadSnapshots(c *C) {
 	badsnapshot := func(label string, vol volume.Volume) error {
 		//create an invalid snapshot by snapshotting and then removing .SnapshotInfo
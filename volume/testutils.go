@@ -31,6 +31,7 @@ import (
 var (
 	ramdisks   map[string]string = make(map[string]string)
 	loopdevs   map[string]string = make(map[string]string)
+	zpools     map[string]string = make(map[string]string)
 	volumeLock sync.Mutex
 )
 
@@ -204,3 +205,50 @@ func CleanupTmpVolume(c *C, fsPath string) {
 	delete(ramdisks, fsPath)
 	delete(loopdevs, fsPath)
 }
+
+// CreateZFSTmpPool creates a zpool backed by a sparse file of <size> bytes in a
+// ramdisk. Returns the mountpoint of the pool's root dataset.
+func CreateZFSTmpPool(c *C, size int64) string {
+	ramdiskDir, err := CreateRamdisk(size)
+	c.Assert(err, IsNil)
+	mountPath := filepath.Join(ramdiskDir, "mnt")
+
+	// Create a sparse file of <size> bytes to back the pool
+	poolFile, err := AllocateLoopFile(ramdiskDir, "serviced", size)
+	if err != nil {
+		defer DestroyRamdisk(ramdiskDir)
+		c.Fatal(err)
+	}
+
+	// Create the pool and mount its root dataset
+	pool := filepath.Base(ramdiskDir)
+	if output, err := exec.Command("zpool", "create", "-m", mountPath, pool, poolFile).CombinedOutput(); err != nil {
+		defer DestroyRamdisk(ramdiskDir)
+		c.Fatalf("could not create zpool %s: %s (%s)", pool, output, err)
+	}
+
+	volumeLock.Lock()
+	defer volumeLock.Unlock()
+	ramdisks[mountPath] = ramdiskDir
+	zpools[mountPath] = pool
+	return mountPath
+}
+
+func CleanupZFSTmpPool(c *C, fsPath string) {
+	volumeLock.Lock()
+	defer volumeLock.Unlock()
+
+	ramdisk, ok := ramdisks[fsPath]
+	c.Assert(ok, Equals, true)
+
+	// Destroying the pool also unmounts all of its datasets
+	err := exec.Command("zpool", "destroy", "-f", zpools[fsPath]).Run()
+	c.Check(err, IsNil)
+
+	// Clean up the ramdisk
+	DestroyRamdisk(ramdisk)
+
+	// Remove the reference to the pool from our internal map
+	delete(ramdisks, fsPath)
+	delete(zpools, fsPath)
+}
@@ -85,3 +85,10 @@ func (s *UtilsSuite) TestFileInfoSlice(c *C) {
 
 	c.Assert(labels, DeepEquals, expected)
 }
+
+func (s *UtilsSuite) TestDriverFlagDir(c *C) {
+	c.Assert(DriverFlagDir("/opt/serviced/var/volumes", DriverTypeBtrFS), Equals, "/opt/serviced/var/volumes/.btrfs")
+	c.Assert(DriverFlagDir("/opt/serviced/var/volumes", DriverTypeRsync), Equals, "/opt/serviced/var/volumes/.rsync")
+	// .zfs is reserved for snapshot access at the top of every zfs dataset
+	c.Assert(DriverFlagDir("/opt/serviced/var/volumes", DriverTypeZFS), Equals, "/opt/serviced/var/volumes/.zfsdriver")
+}
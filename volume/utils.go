@@ -33,6 +33,7 @@ import (
 var (
 	ErrNotADirectory = errors.New("not a directory")
 	ErrBtrfsCommand  = errors.New("error running btrfs command")
+	ErrZFSCommand    = errors.New("error running zfs command")
 )
 
 const FlagFileName = ".initialized"
@@ -118,6 +119,21 @@ func IsBtrfsFilesystem(path string) bool {
 	return err == nil
 }
 
+// RunZFSCmd runs a zfs command, optionally using sudo
+func RunZFSCmd(sudoer bool, args ...string) ([]byte, error) {
+	cmd := append([]string{"zfs"}, args...)
+	if sudoer {
+		cmd = append([]string{"sudo", "-n"}, cmd...)
+	}
+	glog.V(4).Infof("Executing: %v", cmd)
+	output, err := exec.Command(cmd[0], cmd[1:]...).CombinedOutput()
+	if err != nil {
+		glog.V(1).Infof("unable to run cmd:%s  output:%s  error:%s", cmd, string(output), err)
+		return output, ErrZFSCommand
+	}
+	return output, err
+}
+
 func FlagFilePath(root string) string {
 	return filepath.Join(root, FlagFileName)
 }
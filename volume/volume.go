@@ -52,6 +52,7 @@ const (
 	DriverTypeRsync        DriverType = "rsync"
 	DriverTypeDeviceMapper DriverType = "devicemapper"
 	DriverTypeNFS          DriverType = "nfs"
+	DriverTypeZFS          DriverType = "zfs"
 )
 
 var (
@@ -78,6 +79,8 @@ var (
 	ErrInsufficientPermissions = errors.New("insufficient permissions to run command")
 	ErrTagAlreadyExists        = errors.New("a snapshot with the given tag already exists")
 	ErrInvalidSnapshot         = errors.New("invalid snapshot")
+	ErrInvalidRestorePath      = errors.New("path must be inside the volume")
+	ErrPathNotInSnapshot       = errors.New("path does not exist in snapshot")
 )
 
 func init() {
@@ -155,10 +158,18 @@ type Volume interface {
 	UntagSnapshot(tagName string) (string, error)
 	// GetSnapshotWithTag returns info about the snapshot with the given tag, or nil if there isn't one
 	GetSnapshotWithTag(tagName string) (*SnapshotInfo, error)
-	// Export exports the snapshot stored as <label> to <filename>
+	// Export exports the snapshot stored as <label> to <filename>.  If
+	// <parent> is set, only the changes since the snapshot <parent> are
+	// exported, and that snapshot must exist when the export is imported.
 	Export(label, parent string, writer io.Writer, excludes []string) error
 	// Import imports the exported snapshot at <filename> as <label>
 	Import(label string, reader io.Reader) error
+	// Diff lists the files that changed between the snapshot <label> and the
+	// snapshot <other>, or the current state of the volume if <other> is empty
+	Diff(label, other string) ([]FileChange, error)
+	// RestorePaths replaces <paths> in the volume with their contents in the
+	// snapshot <label>, leaving the rest of the volume as it is
+	RestorePaths(label string, paths []string) error
 	// Tenant returns the base tenant of this volume
 	Tenant() string
 }
@@ -429,6 +440,8 @@ func StringToDriverType(name string) (DriverType, error) {
 		return DriverTypeRsync, nil
 	case "devicemapper":
 		return DriverTypeDeviceMapper, nil
+	case "zfs":
+		return DriverTypeZFS, nil
 	}
 	return "", ErrDriverNotSupported
 }
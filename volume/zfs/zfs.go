@@ -0,0 +1,746 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zfs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/control-center/serviced/volume"
+	"github.com/zenoss/glog"
+)
+
+var (
+	ErrZFSInvalidFilesystem = errors.New("not the mountpoint of a zfs dataset")
+	ErrZFSCreatingDataset   = errors.New("could not create dataset")
+	ErrZFSInvalidLabel      = errors.New("invalid label")
+	ErrZFSListingSnapshots  = errors.New("couldn't list snapshots")
+	ErrZFSSettingQuota      = errors.New("could not set quota")
+	ErrZFSNotSupported      = errors.New("operation not supported on zfs driver")
+)
+
+func init() {
+	volume.Register(volume.DriverTypeZFS, Init)
+}
+
+// ZFSDriver is a driver for volumes on a zfs dataset.  Each volume is a child
+// dataset of the dataset mounted at the driver root.
+type ZFSDriver struct {
+	sudoer  bool
+	root    string
+	dataset string
+	sync.Mutex
+}
+
+// ZFSVolume is a zfs volume
+type ZFSVolume struct {
+	sudoer  bool
+	name    string
+	path    string
+	dataset string
+	tenant  string
+	driver  volume.Driver
+	sync.Mutex
+}
+
+// ZFSUsage is the space accounting of a dataset, as reported by zfs list
+type ZFSUsage struct {
+	Dataset   string
+	Used      uint64
+	Available uint64
+}
+
+// ZFS driver initialization
+func Init(root string, _ []string) (volume.Driver, error) {
+	sudoer := volume.IsSudoer()
+	dataset, err := getDataset(sudoer, root)
+	if err != nil {
+		return nil, err
+	}
+	driver := &ZFSDriver{
+		sudoer:  sudoer,
+		root:    root,
+		dataset: dataset,
+	}
+	if err := os.MkdirAll(driver.poolDir(), 0755); err != nil && !os.IsExist(err) {
+		return nil, err
+	}
+	if err := volume.TouchFlagFile(driver.poolDir()); err != nil {
+		return nil, err
+	}
+	return driver, nil
+}
+
+// getDataset returns the name of the dataset mounted at <root>
+func getDataset(sudoer bool, root string) (string, error) {
+	raw, err := volume.RunZFSCmd(sudoer, "list", "-H", "-o", "name,mountpoint", root)
+	if err != nil {
+		glog.Errorf("Could not initialize zfs driver for %s: %s (%s)", root, raw, err)
+		return "", ErrZFSInvalidFilesystem
+	}
+	fields := strings.Split(strings.TrimSpace(string(raw)), "\t")
+	if len(fields) != 2 || filepath.Clean(fields[1]) != root {
+		glog.Errorf("Could not initialize zfs driver for %s: path is not a dataset mountpoint", root)
+		return "", ErrZFSInvalidFilesystem
+	}
+	return fields[0], nil
+}
+
+// Root implements volume.Driver.Root
+func (d *ZFSDriver) Root() string {
+	return d.root
+}
+
+// DriverType implements volume.Driver.DriverType
+func (d *ZFSDriver) DriverType() volume.DriverType {
+	return volume.DriverTypeZFS
+}
+
+// Dataset returns the name of the dataset that holds the driver's volumes
+func (d *ZFSDriver) Dataset() string {
+	return d.dataset
+}
+
+func (d *ZFSDriver) poolDir() string {
+	return volume.DriverFlagDir(d.root, volume.DriverTypeZFS)
+}
+
+// volumeDataset returns the name of the dataset backing <volumeName>
+func (d *ZFSDriver) volumeDataset(volumeName string) string {
+	return d.dataset + "/" + volumeName
+}
+
+// datasetExists checks whether a dataset or snapshot called <name> exists
+func (d *ZFSDriver) datasetExists(name string) bool {
+	if _, err := volume.RunZFSCmd(d.sudoer, "list", "-H", "-o", "name", name); err != nil {
+		return false
+	}
+	return true
+}
+
+// Exists implements volume.Driver.Exists
+func (d *ZFSDriver) Exists(volumeName string) bool {
+	return d.datasetExists(d.volumeDataset(volumeName))
+}
+
+// Cleanup implements volume.Driver.Cleanup
+func (d *ZFSDriver) Cleanup() error {
+	// ZFS driver has no hold on system resources
+	return nil
+}
+
+// Release implements volume.Driver.Release
+func (d *ZFSDriver) Release(volumeName string) error {
+	// ZFS datasets stay mounted for as long as they exist; nothing to release
+	return nil
+}
+
+// Create implements volume.Driver.Create
+func (d *ZFSDriver) Create(volumeName string) (volume.Volume, error) {
+	d.Lock()
+	defer d.Unlock()
+	if !d.Exists(volumeName) {
+		mountpoint := fmt.Sprintf("mountpoint=%s", filepath.Join(d.root, volumeName))
+		if output, err := volume.RunZFSCmd(d.sudoer, "create", "-o", mountpoint, d.volumeDataset(volumeName)); err != nil {
+			glog.Errorf("Could not create volume %s: %s (%s)", volumeName, output, err)
+			return nil, ErrZFSCreatingDataset
+		}
+	}
+	return d.Get(volumeName)
+}
+
+// Remove implements volume.Driver.Remove
+func (d *ZFSDriver) Remove(volumeName string) error {
+	d.Lock()
+	defer d.Unlock()
+	if !d.Exists(volumeName) {
+		glog.Warningf("Volume %s does not exist", volumeName)
+		return nil
+	}
+	// Destroying the dataset recursively takes its snapshots with it
+	if output, err := volume.RunZFSCmd(d.sudoer, "destroy", "-r", d.volumeDataset(volumeName)); err != nil {
+		glog.Errorf("Could not remove volume %s: %s (%s)", volumeName, output, err)
+		return volume.ErrRemovingVolume
+	}
+	return nil
+}
+
+// Status implements volume.Driver.Status
+func (d *ZFSDriver) Status() (volume.Status, error) {
+	glog.V(2).Info("zfs.Status()")
+	output, err := volume.RunZFSCmd(d.sudoer, "list", "-H", "-p", "-o", "name,used,avail", "-t", "filesystem", "-d", "1", d.dataset)
+	if err != nil {
+		glog.Errorf("Could not get status of dataset %s: %s (%s)", d.dataset, output, err)
+		return nil, err
+	}
+	glog.V(2).Infof("Output from zfs list %s: %s", d.dataset, output)
+	usage, err := parseZFSList(strings.Split(string(output), "\n"))
+	if err != nil {
+		glog.Errorf("Could not parse zfs list output: %s", err)
+		return nil, err
+	}
+	response := &volume.SimpleStatus{
+		Driver:     volume.DriverTypeZFS,
+		UsageData:  zfsUsageToUsageData(d.dataset, usage),
+		DriverData: map[string]string{"Dataset": d.dataset},
+	}
+	return response, nil
+}
+
+func getTenant(from string) string {
+	parts := strings.Split(from, "_")
+	return parts[0]
+}
+
+// GetTenant implements volume.Driver.GetTenant
+func (d *ZFSDriver) GetTenant(volumeName string) (volume.Volume, error) {
+	tenant := getTenant(volumeName)
+	if !d.Exists(volumeName) && !d.datasetExists(d.volumeDataset(tenant)+"@"+volumeName) {
+		return nil, volume.ErrVolumeNotExists
+	}
+	return d.Get(tenant)
+}
+
+// Resize implements volume.Driver.Resize by setting the quota on the volume's
+// dataset.  ZFS refuses to set a quota below what the dataset already uses.
+func (d *ZFSDriver) Resize(volumeName string, size uint64) error {
+	if !d.Exists(volumeName) {
+		return volume.ErrVolumeNotExists
+	}
+	quota := fmt.Sprintf("quota=%d", size)
+	if output, err := volume.RunZFSCmd(d.sudoer, "set", quota, d.volumeDataset(volumeName)); err != nil {
+		glog.Errorf("Could not resize volume %s: %s (%s)", volumeName, output, err)
+		return ErrZFSSettingQuota
+	}
+	return nil
+}
+
+// Get implements volume.Driver.Get
+func (d *ZFSDriver) Get(volumeName string) (volume.Volume, error) {
+	v := &ZFSVolume{
+		sudoer:  d.sudoer,
+		name:    volumeName,
+		path:    filepath.Join(d.root, volumeName),
+		dataset: d.volumeDataset(volumeName),
+		tenant:  getTenant(volumeName),
+		driver:  d,
+	}
+	return v, nil
+}
+
+// List implements volume.Driver.List
+func (d *ZFSDriver) List() (result []string) {
+	glog.Infof("Checking volumes at %s", d.root)
+	raw, err := volume.RunZFSCmd(d.sudoer, "list", "-H", "-o", "name", "-t", "filesystem", "-d", "1", d.dataset)
+	if err != nil {
+		glog.Warningf("Could not list datasets of %s: %s (%s)", d.dataset, raw, err)
+		return
+	}
+	prefix := d.dataset + "/"
+	for _, line := range strings.Split(string(raw), "\n") {
+		if name := strings.TrimSpace(line); strings.HasPrefix(name, prefix) {
+			result = append(result, strings.TrimPrefix(name, prefix))
+		}
+	}
+	return
+}
+
+// Name implements volume.Volume.Name
+func (v *ZFSVolume) Name() string {
+	return v.name
+}
+
+// Path implements volume.Volume.Path
+func (v *ZFSVolume) Path() string {
+	return v.path
+}
+
+// Driver implements volume.Volume.Driver
+func (v *ZFSVolume) Driver() volume.Driver {
+	return v.driver
+}
+
+// Tenant implements volume.Volume.Tenant
+func (v *ZFSVolume) Tenant() string {
+	return v.tenant
+}
+
+// WriteMetadata writes the metadata info for a snapshot by writing to the base
+// volume.
+func (v *ZFSVolume) WriteMetadata(label, name string) (io.WriteCloser, error) {
+	filePath := filepath.Join(v.Path(), name)
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil && !os.IsExist(err) {
+		glog.Errorf("Could not create path for file %s: %s", name, err)
+		return nil, err
+	}
+
+	return os.Create(filePath)
+}
+
+// ReadMetadata reads the metadata info from a snapshot
+func (v *ZFSVolume) ReadMetadata(label, name string) (io.ReadCloser, error) {
+	filePath := filepath.Join(v.snapshotPath(label), name)
+	return os.Open(filePath)
+}
+
+func (v *ZFSVolume) getSnapshotPrefix() string {
+	return v.Tenant() + "_"
+}
+
+// rawSnapshotLabel ensures that <label> has the tenant prefix for this volume
+func (v *ZFSVolume) rawSnapshotLabel(label string) string {
+	prefix := v.getSnapshotPrefix()
+	if !strings.HasPrefix(label, prefix) {
+		return prefix + label
+	}
+	return label
+}
+
+// prettySnapshotLabel ensures that <label> does not have the tenant prefix for
+// this volume
+func (v *ZFSVolume) prettySnapshotLabel(rawLabel string) string {
+	return strings.TrimPrefix(rawLabel, v.getSnapshotPrefix())
+}
+
+// snapshotName gets the name of the zfs snapshot for <label>
+func (v *ZFSVolume) snapshotName(label string) string {
+	return v.dataset + "@" + v.rawSnapshotLabel(label)
+}
+
+// snapshotPath gets the path to the read-only contents of snapshot <label>,
+// which zfs exposes under the hidden .zfs directory of the dataset.
+func (v *ZFSVolume) snapshotPath(label string) string {
+	return filepath.Join(v.path, ".zfs", "snapshot", v.rawSnapshotLabel(label))
+}
+
+// isInvalidSnapshot checks to see if <rawLabel> describes a snapshot (i.e., begins
+// with the tenant prefix) but does NOT have a valid metadata file
+func (v *ZFSVolume) isInvalidSnapshot(rawLabel string) bool {
+	if strings.HasPrefix(rawLabel, v.getSnapshotPrefix()) {
+		reader, err := v.ReadMetadata(rawLabel, ".SNAPSHOTINFO")
+		if err != nil {
+			return true
+		}
+		reader.Close()
+	}
+	return false
+}
+
+// writeSnapshotInfo writes metadata about a snapshot
+func (v *ZFSVolume) writeSnapshotInfo(label string, info *volume.SnapshotInfo) error {
+	writer, err := v.WriteMetadata(label, ".SNAPSHOTINFO")
+	if err != nil {
+		glog.Errorf("Could not write meta info for snapshot %s: %s", label, err)
+		return err
+	}
+	defer writer.Close()
+	encoder := json.NewEncoder(writer)
+	if err := encoder.Encode(info); err != nil {
+		glog.Errorf("Could not export meta info for snapshot %s: %s", label, err)
+		return err
+	}
+	return nil
+}
+
+// SnapshotInfo returns the meta info for a snapshot
+func (v *ZFSVolume) SnapshotInfo(label string) (*volume.SnapshotInfo, error) {
+	if v.isInvalidSnapshot(label) {
+		return nil, volume.ErrInvalidSnapshot
+	}
+
+	reader, err := v.ReadMetadata(label, ".SNAPSHOTINFO")
+	if err != nil {
+		glog.Errorf("Could not get info for snapshot %s: %s", label, err)
+		return nil, err
+	}
+	defer reader.Close()
+	decoder := json.NewDecoder(reader)
+	var info volume.SnapshotInfo
+	if err := decoder.Decode(&info); err != nil {
+		glog.Errorf("Could not decode snapshot info for %s: %s", label, err)
+		return nil, err
+	}
+	return &info, err
+}
+
+// Snapshot implements volume.Volume.Snapshot
+func (v *ZFSVolume) Snapshot(label, message string, tags []string) error {
+	// make sure the label doesn't already exist
+	if exists, err := v.snapshotExists(label); err != nil {
+		return err
+	} else if exists {
+		return volume.ErrSnapshotExists
+	}
+	// check the tags for duplicates
+	for _, tagName := range tags {
+		if tagInfo, err := v.GetSnapshotWithTag(tagName); err != volume.ErrSnapshotDoesNotExist {
+			if err != nil {
+				glog.Errorf("Could not look up snapshot with tag %s: %s", tagName, err)
+				return err
+			}
+			glog.Errorf("Tag '%s' is already in use by snapshot %s", tagName, tagInfo.Name)
+			return volume.ErrTagAlreadyExists
+		}
+	}
+	v.Lock()
+	defer v.Unlock()
+	info := volume.SnapshotInfo{
+		Name:     v.rawSnapshotLabel(label),
+		TenantID: v.Tenant(),
+		Label:    v.prettySnapshotLabel(label),
+		Tags:     tags,
+		Message:  message,
+		Created:  time.Now(),
+	}
+	if err := v.writeSnapshotInfo(label, &info); err != nil {
+		return err
+	}
+	if output, err := volume.RunZFSCmd(v.sudoer, "snapshot", v.snapshotName(label)); err != nil {
+		glog.Errorf("Could not snapshot volume %s: %s (%s)", v.name, output, err)
+		return err
+	}
+	return nil
+}
+
+// TagSnapshot implements volume.Volume.TagSnapshot
+// This is not implemented with zfs
+func (v *ZFSVolume) TagSnapshot(label string, tagName string) error {
+	return ErrZFSNotSupported
+}
+
+// UntagSnapshot implements volume.Volume.RemoveSnapshotTag
+// This is not implemented with zfs
+func (v *ZFSVolume) UntagSnapshot(tagName string) (string, error) {
+	return "", ErrZFSNotSupported
+}
+
+// GetSnapshotWithTag implements volume.Volume.GetSnapshotWithTag
+func (v *ZFSVolume) GetSnapshotWithTag(tagName string) (*volume.SnapshotInfo, error) {
+	// Get all the snapshots on the volume
+	snapshotLabels, err := v.Snapshots()
+	if err != nil {
+		glog.Errorf("Could not get current snapshot list: %s", err)
+		return nil, err
+	}
+	// Get info for each snapshot and return if a matching tag is found
+	for _, snapshotLabel := range snapshotLabels {
+		if info, err := v.SnapshotInfo(snapshotLabel); err != volume.ErrInvalidSnapshot {
+			if err != nil {
+				glog.Errorf("Could not get info for snapshot %s: %s", snapshotLabel, err)
+				return nil, err
+			}
+			for _, tag := range info.Tags {
+				if tag == tagName {
+					return info, nil
+				}
+			}
+		}
+	}
+	return nil, volume.ErrSnapshotDoesNotExist
+}
+
+// Snapshots implements volume.Volume.Snapshots
+func (v *ZFSVolume) Snapshots() ([]string, error) {
+	v.Lock()
+	defer v.Unlock()
+
+	glog.V(2).Infof("listing snapshots of volume:%v and v.name:%s ", v.path, v.name)
+	output, err := volume.RunZFSCmd(v.sudoer, "list", "-H", "-o", "name", "-t", "snapshot", "-s", "creation", "-d", "1", v.dataset)
+	if err != nil {
+		glog.Errorf("Could not list snapshots of %s: %s (%s)", v.dataset, output, err)
+		return nil, err
+	}
+	return parseSnapshotList(strings.Split(string(output), "\n"), v.dataset, v.getSnapshotPrefix()), nil
+}
+
+// RemoveSnapshot implements volume.Volume.RemoveSnapshot
+func (v *ZFSVolume) RemoveSnapshot(label string) error {
+	if exists, err := v.snapshotExists(label); err != nil {
+		return err
+	} else if !exists {
+		return volume.ErrSnapshotDoesNotExist
+	}
+
+	v.Lock()
+	defer v.Unlock()
+	if output, err := volume.RunZFSCmd(v.sudoer, "destroy", v.snapshotName(label)); err != nil {
+		glog.Errorf("could not remove snapshot %s: %s (%s)", label, output, err)
+		return volume.ErrRemovingSnapshot
+	}
+	return nil
+}
+
+// Rollback implements volume.Volume.Rollback
+func (v *ZFSVolume) Rollback(label string) error {
+	if v.isInvalidSnapshot(label) {
+		return volume.ErrInvalidSnapshot
+	}
+	snapshots, err := v.Snapshots()
+	if err != nil {
+		glog.Errorf("Could not get current snapshot list: %s", err)
+		return ErrZFSListingSnapshots
+	}
+	rawLabel := v.rawSnapshotLabel(label)
+	found := false
+	for _, snapshot := range snapshots {
+		if snapshot == rawLabel {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return volume.ErrSnapshotDoesNotExist
+	}
+
+	v.Lock()
+	defer v.Unlock()
+	glog.Infof("starting rollback of snapshot %s", label)
+	start := time.Now()
+
+	// zfs rollback can only return to the latest snapshot without destroying
+	// the ones taken after it, so older snapshots are copied back instead.
+	if snapshots[len(snapshots)-1] == rawLabel {
+		if output, err := volume.RunZFSCmd(v.sudoer, "rollback", v.snapshotName(label)); err != nil {
+			glog.Errorf("rollback of snapshot %s failed: %s (%s)", label, output, err)
+			return err
+		}
+	} else {
+		rsync := exec.Command("rsync", "-a", "--del", "--force", "--exclude=/.zfs", v.snapshotPath(label)+"/", v.path+"/")
+		glog.V(0).Infof("About to execute: %s", rsync)
+		if output, err := rsync.CombinedOutput(); err != nil {
+			glog.Errorf("rollback of snapshot %s failed: %s (%s)", label, output, err)
+			return err
+		}
+	}
+	glog.Infof("rollback of snapshot %s took %s", label, time.Since(start))
+	return nil
+}
+
+// Export implements volume.Volume.Export.  If parent is set, only the changes
+// since the parent snapshot are sent.
+func (v *ZFSVolume) Export(label, parent string, writer io.Writer, excludes []string) error {
+	if len(excludes) > 0 {
+		glog.Warning("zfs backups do not support excluding directories")
+	}
+	if label = strings.TrimSpace(label); label == "" {
+		glog.Errorf("%s: label cannot be empty", volume.DriverTypeZFS)
+		return ErrZFSInvalidLabel
+	} else if exists, err := v.snapshotExists(label); err != nil {
+		return err
+	} else if !exists {
+		return volume.ErrSnapshotDoesNotExist
+	}
+	args := []string{"send"}
+	if parent = strings.TrimSpace(parent); parent != "" {
+		if exists, err := v.snapshotExists(parent); err != nil {
+			return err
+		} else if !exists {
+			return volume.ErrSnapshotDoesNotExist
+		}
+		args = append(args, "-i", v.snapshotName(parent))
+	}
+	args = append(args, v.snapshotName(label))
+	if err := runZFSSend(writer, v.sudoer, args...); err != nil {
+		glog.Errorf("Could not export snapshot %s: %s", label, err)
+		return err
+	}
+	return nil
+}
+
+// Import implements volume.Volume.Import.  The stream is received into the
+// volume's dataset, discarding any changes made since its latest snapshot.
+func (v *ZFSVolume) Import(label string, reader io.Reader) error {
+	if exists, err := v.snapshotExists(label); err != nil {
+		return err
+	} else if exists {
+		return volume.ErrSnapshotExists
+	}
+	if err := runZFSRecv(reader, v.sudoer, v.snapshotName(label)); err != nil {
+		glog.Errorf("Could not import snapshot %s: %s", label, err)
+		return err
+	}
+	return nil
+}
+
+// Diff implements volume.Volume.Diff
+func (v *ZFSVolume) Diff(label, other string) ([]volume.FileChange, error) {
+	from, err := v.existingSnapshotPath(label)
+	if err != nil {
+		return nil, err
+	}
+	to := v.path
+	if other != "" {
+		if to, err = v.existingSnapshotPath(other); err != nil {
+			return nil, err
+		}
+	}
+	return volume.DiffDirectories(from, to)
+}
+
+// RestorePaths implements volume.Volume.RestorePaths
+func (v *ZFSVolume) RestorePaths(label string, paths []string) error {
+	src, err := v.existingSnapshotPath(label)
+	if err != nil {
+		return err
+	}
+	v.Lock()
+	defer v.Unlock()
+	return volume.RestoreFromDirectory(src, v.path, paths)
+}
+
+// existingSnapshotPath gets the path to the contents of the snapshot <label>,
+// if it exists
+func (v *ZFSVolume) existingSnapshotPath(label string) (string, error) {
+	if exists, err := v.snapshotExists(label); err != nil {
+		return "", err
+	} else if !exists {
+		return "", volume.ErrSnapshotDoesNotExist
+	}
+	return v.snapshotPath(label), nil
+}
+
+// snapshotExists queries the snapshot existence for the given label
+func (v *ZFSVolume) snapshotExists(label string) (exists bool, err error) {
+	rlabel := v.rawSnapshotLabel(label)
+	if snapshots, err := v.Snapshots(); err != nil {
+		glog.Errorf("Could not get current snapshot list: %v", err)
+		return false, ErrZFSListingSnapshots
+	} else {
+		for _, snapLabel := range snapshots {
+			if rlabel == snapLabel {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// runZFSSend writes a zfs send stream to a write handle
+func runZFSSend(writer io.Writer, sudoer bool, args ...string) error {
+	cmdArgs := append([]string{"zfs"}, args...)
+	if sudoer {
+		cmdArgs = append([]string{"sudo", "-n"}, cmdArgs...)
+	}
+	cmd := exec.Command(cmdArgs[0], cmdArgs[1:]...)
+	cmd.Stdout = writer
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		glog.Errorf("Error while running command %+v: %s", cmdArgs, err)
+		return volume.ErrZFSCommand
+	}
+	return nil
+}
+
+// runZFSRecv reads a zfs send stream from a read handle into the snapshot
+// <name>
+func runZFSRecv(reader io.Reader, sudoer bool, name string) error {
+	cmdArgs := []string{"zfs", "recv", "-F", name}
+	if sudoer {
+		cmdArgs = append([]string{"sudo", "-n"}, cmdArgs...)
+	}
+	cmd := exec.Command(cmdArgs[0], cmdArgs[1:]...)
+	cmd.Stdin = reader
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		glog.Errorf("Error while running command %+v: %s", cmdArgs, err)
+		return volume.ErrZFSCommand
+	}
+	return nil
+}
+
+// parseSnapshotList returns the labels of the snapshots of <dataset> that
+// carry the tenant <prefix>, in the order listed by zfs list -o name.
+func parseSnapshotList(lines []string, dataset, prefix string) []string {
+	labels := []string{}
+	for _, line := range lines {
+		parts := strings.SplitN(strings.TrimSpace(line), "@", 2)
+		if len(parts) != 2 || parts[0] != dataset {
+			continue
+		}
+		if strings.HasPrefix(parts[1], prefix) {
+			labels = append(labels, parts[1])
+			glog.V(2).Infof("found snapshot:%s", parts[1])
+		}
+	}
+	return labels
+}
+
+// Parse output of zfs list -H -p -o name,used,avail, which is tab separated
+// with sizes in bytes:
+/*
+	tank/serviced	1409024	1019695104
+	tank/serviced/abc123	237568	1019695104
+*/
+func parseZFSList(lines []string) ([]ZFSUsage, error) {
+	lines = removeBlankLines(lines)
+	if len(lines) == 0 {
+		return []ZFSUsage{}, errors.New("insufficient output from zfs list")
+	}
+	usage := []ZFSUsage{}
+	for _, line := range lines {
+		fields := strings.Split(strings.TrimSpace(line), "\t")
+		if len(fields) != 3 {
+			return []ZFSUsage{}, fmt.Errorf("Wrong number of fields (%d, expected 3) in line %q", len(fields), line)
+		}
+		used, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return []ZFSUsage{}, fmt.Errorf("Could not parse used field in line %q: %s", line, err)
+		}
+		avail, err := strconv.ParseUint(fields[2], 10, 64)
+		if err != nil {
+			return []ZFSUsage{}, fmt.Errorf("Could not parse avail field in line %q: %s", line, err)
+		}
+		usage = append(usage, ZFSUsage{Dataset: fields[0], Used: used, Available: avail})
+	}
+	return usage, nil
+}
+
+// zfsUsageToUsageData reports the usage of each dataset, publishing the
+// totals of the driver's own dataset as storage metrics.
+func zfsUsageToUsageData(dataset string, zfsUsage []ZFSUsage) []volume.Usage {
+	result := []volume.Usage{}
+	for _, u := range zfsUsage {
+		if u.Dataset == dataset {
+			result = append(result,
+				volume.UsageInt{Label: u.Dataset, Type: "Available", Value: u.Available, MetricName: "storage.available"},
+				volume.UsageInt{Label: u.Dataset, Type: "Used", Value: u.Used, MetricName: "storage.used"},
+				volume.UsageInt{Label: u.Dataset, Type: "Total", Value: u.Used + u.Available, MetricName: "storage.total"})
+		} else {
+			result = append(result,
+				volume.UsageInt{Label: u.Dataset, Type: "Used", Value: u.Used},
+				volume.UsageInt{Label: u.Dataset, Type: "Total", Value: u.Used + u.Available})
+		}
+	}
+	return result
+}
+
+func removeBlankLines(in []string) []string {
+	out := []string{}
+	for _, value := range in {
+		if strings.TrimSpace(value) != "" {
+			out = append(out, value)
+		}
+	}
+	return out
+}
@@ -0,0 +1,160 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build root,integration
+
+package zfs_test
+
+import (
+	"fmt"
+	"testing"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/control-center/serviced/volume"
+	"github.com/control-center/serviced/volume/drivertest"
+	// Register the zfs driver
+	. "github.com/control-center/serviced/volume/zfs"
+)
+
+var (
+	_                = Suite(&ZFSSuite{})
+	zfsArgs []string = []string{}
+)
+
+// Wire in gocheck
+func Test(t *testing.T) { TestingT(t) }
+
+type ZFSSuite struct {
+	root string
+}
+
+func (s *ZFSSuite) SetUpSuite(c *C) {
+	// zfs will not create a pool on a vdev smaller than 64MB
+	s.root = volume.CreateZFSTmpPool(c, 256*1024*1024)
+}
+
+func (s *ZFSSuite) TearDownSuite(c *C) {
+	volume.CleanupZFSTmpPool(c, s.root)
+}
+
+func (s *ZFSSuite) TestZFSCreateEmpty(c *C) {
+	drivertest.DriverTestCreateEmpty(c, "zfs", s.root, zfsArgs)
+}
+
+func (s *ZFSSuite) TestZFSCreateBase(c *C) {
+	drivertest.DriverTestCreateBase(c, "zfs", s.root, zfsArgs)
+}
+
+func (s *ZFSSuite) TestZFSSnapshots(c *C) {
+	drivertest.DriverTestSnapshots(c, "zfs", s.root, zfsArgs)
+}
+
+func (s *ZFSSuite) TestZFSDiffRestorePaths(c *C) {
+	drivertest.DriverTestDiffRestorePaths(c, "zfs", s.root, zfsArgs)
+}
+
+func (s *ZFSSuite) TestZFSBadSnapshots(c *C) {
+	badsnapshot := func(label string, vol volume.Volume) error {
+		// create an invalid snapshot by snapshotting without writing .SNAPSHOTINFO
+		d := vol.Driver().(*ZFSDriver)
+		name := fmt.Sprintf("%s/%s@%s_%s", d.Dataset(), vol.Name(), vol.Name(), label)
+		_, err := volume.RunZFSCmd(false, "snapshot", name)
+		return err
+	}
+
+	drivertest.DriverTestBadSnapshot(c, "zfs", s.root, badsnapshot, zfsArgs)
+}
+
+func (s *ZFSSuite) TestZFSSnapshotTags(c *C) {
+	err := volume.InitDriver("zfs", s.root, zfsArgs)
+	c.Assert(err, IsNil)
+	d, err := volume.GetDriver(s.root)
+	c.Assert(err, IsNil)
+	c.Assert(d, NotNil)
+
+	vol, err := d.Create("Base")
+	c.Assert(err, IsNil)
+	c.Assert(vol, NotNil)
+
+	// Take a snapshot with tags
+	err = vol.Snapshot("Snap", "snapshot-message-0", []string{"SnapTag", "tagA"})
+	c.Assert(err, IsNil)
+	info, err := vol.SnapshotInfo("Base_Snap")
+	c.Assert(err, IsNil)
+	c.Check(info.Tags, DeepEquals, []string{"SnapTag", "tagA"})
+
+	// Take another snapshot with an existing tag
+	err = vol.Snapshot("Snap2", "snapshot-message-1", []string{"tagA"})
+	c.Assert(err, Equals, volume.ErrTagAlreadyExists)
+
+	// Snapshots are read-only, so tags cannot be changed
+	err = vol.TagSnapshot("Base_Snap", "tagB")
+	c.Assert(err, Equals, ErrZFSNotSupported)
+	label, err := vol.UntagSnapshot("tagA")
+	c.Assert(err, Equals, ErrZFSNotSupported)
+	c.Assert(label, Equals, "")
+
+	c.Assert(d.Remove("Base"), IsNil)
+	c.Assert(d.Exists("Base"), Equals, false)
+}
+
+func (s *ZFSSuite) TestZFSResize(c *C) {
+	err := volume.InitDriver("zfs", s.root, zfsArgs)
+	c.Assert(err, IsNil)
+	d, err := volume.GetDriver(s.root)
+	c.Assert(err, IsNil)
+
+	vol, err := d.Create("Base")
+	c.Assert(err, IsNil)
+	defer d.Remove("Base")
+
+	// The quota caps the space the volume reports as available
+	c.Assert(d.Resize(vol.Name(), 32*1024*1024), IsNil)
+	c.Assert(volume.FilesystemBytesSize(vol.Path()) <= 32*1024*1024, Equals, true)
+
+	c.Assert(d.Resize("missing", 32*1024*1024), Equals, volume.ErrVolumeNotExists)
+}
+
+func (s *ZFSSuite) TestZFSStatus(c *C) {
+	err := volume.InitDriver("zfs", s.root, zfsArgs)
+	c.Assert(err, IsNil)
+	d, err := volume.GetDriver(s.root)
+	c.Assert(err, IsNil)
+
+	_, err = d.Create("Base")
+	c.Assert(err, IsNil)
+	defer d.Remove("Base")
+
+	status, err := d.Status()
+	c.Assert(err, IsNil)
+	labels := make(map[string]bool)
+	for _, usage := range status.GetUsageData() {
+		labels[usage.GetLabel()] = true
+	}
+	dataset := d.(*ZFSDriver).Dataset()
+	c.Check(labels[dataset], Equals, true)
+	c.Check(labels[dataset+"/Base"], Equals, true)
+}
+
+func (s *ZFSSuite) TestZFSExportImport(c *C) {
+	other_root := volume.CreateZFSTmpPool(c, 256*1024*1024)
+	defer volume.CleanupZFSTmpPool(c, other_root)
+	drivertest.DriverTestExportImport(c, "zfs", s.root, other_root, zfsArgs)
+}
+
+func (s *ZFSSuite) TestZFSIncrementalExportImport(c *C) {
+	other_root := volume.CreateZFSTmpPool(c, 256*1024*1024)
+	defer volume.CleanupZFSTmpPool(c, other_root)
+	drivertest.DriverTestIncrementalExportImport(c, "zfs", s.root, other_root, zfsArgs)
+}
@@ -0,0 +1,110 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build unit
+
+package zfs
+
+import (
+	"testing"
+
+	"github.com/control-center/serviced/volume"
+	"github.com/stretchr/testify/assert"
+)
+
+type ParseZFSListTest struct {
+	label string
+	in    []string
+	out   []ZFSUsage
+	err   bool
+}
+
+var parsezfslisttests = []ParseZFSListTest{
+	{
+		label: "dataset with tenant volumes",
+		in: []string{
+			"tank/serviced\t1409024\t1019695104",
+			"tank/serviced/abc123\t237568\t1019695104",
+			"tank/serviced/def456\t98304\t524288000",
+			"",
+		},
+		out: []ZFSUsage{
+			{Dataset: "tank/serviced", Used: 1409024, Available: 1019695104},
+			{Dataset: "tank/serviced/abc123", Used: 237568, Available: 1019695104},
+			{Dataset: "tank/serviced/def456", Used: 98304, Available: 524288000},
+		},
+	},
+	{
+		label: "dataset without volumes",
+		in:    []string{"tank\t24576\t1019695104"},
+		out:   []ZFSUsage{{Dataset: "tank", Used: 24576, Available: 1019695104}},
+	},
+	{
+		label: "empty output",
+		in:    []string{"", ""},
+		err:   true,
+	},
+	{
+		label: "human readable sizes",
+		in:    []string{"tank\t24K\t972M"},
+		err:   true,
+	},
+	{
+		label: "missing field",
+		in:    []string{"tank\t24576"},
+		err:   true,
+	},
+}
+
+func TestParseZFSList(t *testing.T) {
+	for _, tc := range parsezfslisttests {
+		result, err := parseZFSList(tc.in)
+		if tc.err {
+			assert.NotNil(t, err, tc.label)
+			assert.Empty(t, result, tc.label)
+		} else {
+			assert.Nil(t, err, tc.label)
+			assert.Equal(t, tc.out, result, tc.label)
+		}
+	}
+}
+
+func TestParseSnapshotList(t *testing.T) {
+	lines := []string{
+		"tank/serviced/Base@Base_Snap",
+		"tank/serviced/Base@manual",
+		"tank/serviced/Base@Base_Snap2",
+		"tank/serviced/Other@Base_Snap3",
+		"",
+	}
+	labels := parseSnapshotList(lines, "tank/serviced/Base", "Base_")
+	assert.Equal(t, []string{"Base_Snap", "Base_Snap2"}, labels)
+
+	labels = parseSnapshotList([]string{""}, "tank/serviced/Base", "Base_")
+	assert.Empty(t, labels)
+}
+
+func TestZFSUsageToUsageData(t *testing.T) {
+	usage := []ZFSUsage{
+		{Dataset: "tank/serviced", Used: 300, Available: 700},
+		{Dataset: "tank/serviced/Base", Used: 100, Available: 700},
+	}
+	expected := []volume.Usage{
+		volume.UsageInt{Label: "tank/serviced", Type: "Available", Value: 700, MetricName: "storage.available"},
+		volume.UsageInt{Label: "tank/serviced", Type: "Used", Value: 300, MetricName: "storage.used"},
+		volume.UsageInt{Label: "tank/serviced", Type: "Total", Value: 1000, MetricName: "storage.total"},
+		volume.UsageInt{Label: "tank/serviced/Base", Type: "Used", Value: 100},
+		volume.UsageInt{Label: "tank/serviced/Base", Type: "Total", Value: 800},
+	}
+	assert.Equal(t, expected, zfsUsageToUsageData("tank/serviced", usage))
+}
@@ -32,7 +32,14 @@ func getHosts(w *rest.ResponseWriter, r *rest.Request, ctx *requestContext) {
 		return
 	}
 
-	w.WriteJson(hosts)
+	viewable := hosts[:0]
+	for _, h := range hosts {
+		if ctx.viewsPool(h.PoolID) {
+			viewable = append(viewable, h)
+		}
+	}
+
+	w.WriteJson(viewable)
 }
 
 // getHostsForPool returns the list of hosts for a pool.
@@ -67,7 +74,7 @@ func getHostStatuses(w *rest.ResponseWriter, r *rest.Request, ctx *requestContex
 	values := r.URL.Query()
 
 	var hostIDs []string
-	if _, ok := values["hostId"]; ok {
+	if _, ok := values["hostId"]; ok && ctx.viewsAll() {
 		hostIDs = values["hostId"]
 	} else {
 		hosts, err := facade.GetReadHosts(dataCtx)
@@ -76,9 +83,16 @@ func getHostStatuses(w *rest.ResponseWriter, r *rest.Request, ctx *requestContex
 			return
 		}
 
-		hostIDs = make([]string, len(hosts))
-		for i, host := range hosts {
-			hostIDs[i] = host.ID
+		requested := make(map[string]bool)
+		for _, hostID := range values["hostId"] {
+			requested[hostID] = true
+		}
+		for _, host := range hosts {
+			if ok && !requested[host.ID] {
+				continue
+			} else if ctx.viewsPool(host.PoolID) {
+				hostIDs = append(hostIDs, host.ID)
+			}
 		}
 	}
 
@@ -30,6 +30,10 @@ import (
 
 func getAllInternalServices(w *rest.ResponseWriter, r *rest.Request, ctx *requestContext) {
 	results := []interface{}{}
+	if !ctx.viewsAll() {
+		w.WriteJson(results)
+		return
+	}
 
 	for _, service := range getISVCS() {
 		if service.ID == isvcs.InternalServicesISVC.ID {
@@ -174,6 +178,10 @@ func getZooKeeperInstances(ctx *requestContext) []interface{} {
 }
 
 func getInternalServiceStatuses(w *rest.ResponseWriter, r *rest.Request, ctx *requestContext) {
+	if !ctx.viewsAll() {
+		w.WriteJson([]interface{}{})
+		return
+	}
 	values := r.URL.Query()
 
 	runningMap := make(map[string][]dao.RunningService)
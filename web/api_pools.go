@@ -28,5 +28,12 @@ func getPools(w *rest.ResponseWriter, r *rest.Request, ctx *requestContext) {
 		return
 	}
 
-	w.WriteJson(pools)
+	viewable := pools[:0]
+	for _, p := range pools {
+		if ctx.viewsPool(p.ID) {
+			viewable = append(viewable, p)
+		}
+	}
+
+	w.WriteJson(viewable)
 }
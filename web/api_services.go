@@ -41,7 +41,7 @@ func getAllServiceDetails(w *rest.ResponseWriter, r *rest.Request, c *requestCon
 		return
 	}
 
-	w.WriteJson(details)
+	w.WriteJson(c.viewableServiceDetails(details))
 }
 
 func getServiceDetails(w *rest.ResponseWriter, r *rest.Request, c *requestContext) {
@@ -99,7 +99,7 @@ func getChildServiceDetails(w *rest.ResponseWriter, r *rest.Request, c *requestC
 		return
 	}
 
-	w.WriteJson(details)
+	w.WriteJson(c.viewableServiceDetails(details))
 }
 
 func getServiceContext(w *rest.ResponseWriter, r *rest.Request, c *requestContext) {
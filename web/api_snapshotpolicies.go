@@ -0,0 +1,98 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/control-center/serviced/datastore"
+	"github.com/control-center/serviced/domain/snapshotpolicy"
+	"github.com/control-center/serviced/validation"
+	"github.com/zenoss/go-json-rest"
+)
+
+// getSnapshotPolicies returns the snapshot policies of all tenants
+func getSnapshotPolicies(w *rest.ResponseWriter, r *rest.Request, c *requestContext) {
+	policies, err := c.getFacade().GetSnapshotPolicies(c.getDatastoreContext())
+	if err != nil {
+		restServerError(w, err)
+		return
+	}
+	viewable := policies[:0]
+	for _, policy := range policies {
+		if c.viewsServiceID(policy.TenantID) {
+			viewable = append(viewable, policy)
+		}
+	}
+	w.WriteJson(viewable)
+}
+
+// putSnapshotPolicy sets the snapshot policy of the tenant of a service
+func putSnapshotPolicy(w *rest.ResponseWriter, r *rest.Request, c *requestContext) {
+	serviceID, err := url.QueryUnescape(r.PathParam("serviceId"))
+	if err != nil {
+		writeJSON(w, err, http.StatusBadRequest)
+		return
+	} else if serviceID == "" {
+		writeJSON(w, "serviceId must be specified", http.StatusBadRequest)
+		return
+	}
+
+	var policy snapshotpolicy.SnapshotPolicy
+	if err := r.DecodeJsonPayload(&policy); err != nil {
+		plog.WithError(err).Debug("Could not decode snapshot policy payload")
+		writeJSON(w, fmt.Sprintf("Bad Request: %v", err), http.StatusBadRequest)
+		return
+	}
+	policy.TenantID = serviceID
+
+	err = c.getFacade().SetSnapshotPolicy(c.getDatastoreContext(), policy)
+	if datastore.IsErrNoSuchEntity(err) {
+		writeJSON(w, fmt.Sprintf("Service %v Not Found", serviceID), http.StatusNotFound)
+		return
+	} else if _, ok := err.(*validation.ValidationError); ok {
+		writeJSON(w, fmt.Sprintf("Bad Request: %v", err), http.StatusBadRequest)
+		return
+	} else if err != nil {
+		restServerError(w, err)
+		return
+	}
+	restSuccess(w)
+}
+
+// deleteSnapshotPolicy removes the snapshot policy of the tenant of a service
+func deleteSnapshotPolicy(w *rest.ResponseWriter, r *rest.Request, c *requestContext) {
+	serviceID, err := url.QueryUnescape(r.PathParam("serviceId"))
+	if err != nil {
+		writeJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	facade := c.getFacade()
+	ctx := c.getDatastoreContext()
+	tenantID, err := facade.GetTenantID(ctx, serviceID)
+	if err == nil {
+		err = facade.RemoveSnapshotPolicy(ctx, tenantID)
+	}
+	if datastore.IsErrNoSuchEntity(err) {
+		writeJSON(w, fmt.Sprintf("Snapshot policy for %v Not Found", serviceID), http.StatusNotFound)
+		return
+	} else if err != nil {
+		restServerError(w, err)
+		return
+	}
+	restSuccess(w)
+}
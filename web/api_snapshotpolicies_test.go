@@ -0,0 +1,74 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build unit
+
+package web
+
+import (
+	"net/http"
+
+	"github.com/control-center/serviced/datastore"
+	"github.com/control-center/serviced/domain/snapshotpolicy"
+	"github.com/control-center/serviced/validation"
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestWebSuite) TestGetSnapshotPolicies(c *C) {
+	request := s.buildRequest("GET", "/api/v2/snapshotpolicies", "")
+	policies := []snapshotpolicy.SnapshotPolicy{{TenantID: "tenant1", Schedule: "@daily", KeepDaily: 7}}
+	s.mockFacade.On("GetSnapshotPolicies", s.ctx.getDatastoreContext()).Return(policies, nil)
+
+	getSnapshotPolicies(&(s.writer), &request, s.ctx)
+
+	c.Assert(s.recorder.Code, Equals, http.StatusOK)
+	var result []snapshotpolicy.SnapshotPolicy
+	s.getResult(c, &result)
+	c.Assert(result, HasLen, 1)
+	c.Assert(result[0].TenantID, Equals, "tenant1")
+}
+
+func (s *TestWebSuite) TestPutSnapshotPolicy(c *C) {
+	request := s.buildRequest("PUT", "/api/v2/services/svc1/snapshotpolicy", `{"Schedule": "@hourly", "KeepHourly": 24}`)
+	request.PathParams["serviceId"] = "svc1"
+	expected := snapshotpolicy.SnapshotPolicy{TenantID: "svc1", Schedule: "@hourly", KeepHourly: 24}
+	s.mockFacade.On("SetSnapshotPolicy", s.ctx.getDatastoreContext(), expected).Return(nil)
+
+	putSnapshotPolicy(&(s.writer), &request, s.ctx)
+
+	c.Assert(s.recorder.Code, Equals, http.StatusOK)
+	s.mockFacade.AssertExpectations(c)
+}
+
+func (s *TestWebSuite) TestPutSnapshotPolicy_Invalid(c *C) {
+	request := s.buildRequest("PUT", "/api/v2/services/svc1/snapshotpolicy", `{"Schedule": "sometimes"}`)
+	request.PathParams["serviceId"] = "svc1"
+	s.mockFacade.On("SetSnapshotPolicy", s.ctx.getDatastoreContext(), snapshotpolicy.SnapshotPolicy{TenantID: "svc1", Schedule: "sometimes"}).
+		Return(validation.NewValidationError())
+
+	putSnapshotPolicy(&(s.writer), &request, s.ctx)
+
+	c.Assert(s.recorder.Code, Equals, http.StatusBadRequest)
+}
+
+func (s *TestWebSuite) TestDeleteSnapshotPolicy_NotFound(c *C) {
+	request := s.buildRequest("DELETE", "/api/v2/services/svc1/snapshotpolicy", "")
+	request.PathParams["serviceId"] = "svc1"
+	s.mockFacade.On("GetTenantID", s.ctx.getDatastoreContext(), "svc1").Return("tenant1", nil)
+	s.mockFacade.On("RemoveSnapshotPolicy", s.ctx.getDatastoreContext(), "tenant1").
+		Return(datastore.ErrNoSuchEntity{Key: snapshotpolicy.Key("tenant1")})
+
+	deleteSnapshotPolicy(&(s.writer), &request, s.ctx)
+
+	c.Assert(s.recorder.Code, Equals, http.StatusNotFound)
+}
@@ -18,6 +18,7 @@ package web
 //extern int isGroupMember(const char *username, const char *group);
 import "C"
 import (
+	"github.com/control-center/serviced/auth"
 	"github.com/msteinert/pam"
 	"github.com/zenoss/glog"
 
@@ -52,6 +53,28 @@ func pamValidateLogin(creds *login, group string) bool {
 	return pamValidateLoginOnly(creds, group) && isGroupMember(creds.Username, group)
 }
 
+// pamLoginGrants validates the credentials and returns the roles given to the
+// user's groups.  Members of the admin group are admins.
+func pamLoginGrants(creds *login, adminGroup string, roles auth.RoleMap) (auth.Grants, bool) {
+	if !pamValidateLoginOnly(creds, adminGroup) {
+		return nil, false
+	}
+	var grants auth.Grants
+	if isGroupMember(creds.Username, adminGroup) {
+		grants = append(grants, auth.Grant{Role: auth.RoleAdmin})
+	}
+	for group, groupGrants := range roles {
+		if isGroupMember(creds.Username, group) {
+			grants = append(grants, groupGrants...)
+		}
+	}
+	if len(grants) == 0 {
+		glog.Warningf("User %s is not a member of any group with a role", creds.Username)
+		return nil, false
+	}
+	return grants, true
+}
+
 func makePamConvHandler(creds *login) func(pam.Style, string) (string, error) {
 	return func(s pam.Style, msg string) (string, error) {
 		switch s {
@@ -16,6 +16,7 @@
 package web
 
 import (
+	"github.com/control-center/serviced/auth"
 	"github.com/zenoss/glog"
 )
 
@@ -23,3 +24,8 @@ func pamValidateLogin(_ *login, _ string) bool {
 	glog.Errorf("pamValidateLogin is not supported on this platform")
 	return false
 }
+
+func pamLoginGrants(_ *login, _ string, _ auth.RoleMap) (auth.Grants, bool) {
+	glog.Errorf("pamLoginGrants is not supported on this platform")
+	return nil, false
+}
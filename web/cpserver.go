@@ -26,7 +26,7 @@ import (
 	"strings"
 
 	"github.com/Sirupsen/logrus"
-	"github.com/control-center/serviced/config"
+	"github.com/control-center/serviced/auth"
 	daoclient "github.com/control-center/serviced/dao/client"
 	"github.com/control-center/serviced/datastore"
 	"github.com/control-center/serviced/facade"
@@ -60,14 +60,6 @@ type ServiceConfig struct {
 	vhostmgr    *VHostManager
 }
 
-// Auth0Config contains configuration values pertaining to Auth0
-type Auth0Config struct {
-	Auth0ClientID string
-	Auth0Domain   string
-	Auth0Audience string
-	Auth0Scope    string
-}
-
 var defaultHostAlias string
 var uiConfig UIConfig
 
@@ -198,6 +190,10 @@ func (sc *ServiceConfig) Serve(shutdown <-chan (interface{})) {
 			return
 		}
 		r.URL.Path = cleanPath(r.URL.Path)
+		if r.URL.Path == eventsPath {
+			sc.serveEvents(w, r)
+			return
+		}
 		uiHandler.ServeHTTP(w, r)
 	}
 
@@ -294,10 +290,9 @@ func (sc *ServiceConfig) unAuthorizedClient(realfunc handlerClientFunc) handlerF
 	}
 }
 
-func (sc *ServiceConfig) authorizedClient(realfunc handlerClientFunc) handlerFunc {
+func (sc *ServiceConfig) authorizedClient(role auth.Role, realfunc handlerClientFunc) handlerFunc {
 	return func(w *rest.ResponseWriter, r *rest.Request) {
-		if !loginOK(w, r) {
-			restUnauthorized(w)
+		if _, ok := sc.checkRole(w, r, role); !ok {
 			return
 		}
 		client, err := sc.getClient()
@@ -344,29 +339,43 @@ func (sc *ServiceConfig) getMasterClient() (master.ClientInterface, error) {
 
 func (sc *ServiceConfig) newRequestHandler(check checkFunc, realfunc ctxhandlerFunc) handlerFunc {
 	return func(w *rest.ResponseWriter, r *rest.Request) {
-		if !check(w, r) {
+		grants, ok := check(w, r)
+		if !ok {
 			return
 		}
 		reqCtx := newRequestContextFromRequest(sc, r)
+		reqCtx.grants = grants
 		defer reqCtx.end()
 		realfunc(w, r, reqCtx)
 	}
 }
 
-func (sc *ServiceConfig) checkAuth(realfunc ctxhandlerFunc) handlerFunc {
-	check := func(w *rest.ResponseWriter, r *rest.Request) bool {
-		if !loginOK(w, r) {
-			restUnauthorized(w)
-			return false
-		}
-		return true
+// checkRole verifies that the request is logged in with the role on the
+// resource it names, and writes the error response if it is not
+func (sc *ServiceConfig) checkRole(w *rest.ResponseWriter, r *rest.Request, role auth.Role) (auth.Grants, bool) {
+	grants, ok := loginGrants(w, r)
+	if !ok {
+		restUnauthorized(w)
+		return nil, false
+	}
+	if !sc.authorized(r, grants, role) {
+		plog.WithField("url", r.URL.String()).WithField("role", role).Debug("Request does not have the required role")
+		restForbidden(w)
+		return nil, false
+	}
+	return grants, true
+}
+
+func (sc *ServiceConfig) checkAuth(role auth.Role, realfunc ctxhandlerFunc) handlerFunc {
+	check := func(w *rest.ResponseWriter, r *rest.Request) (auth.Grants, bool) {
+		return sc.checkRole(w, r, role)
 	}
 	return sc.newRequestHandler(check, realfunc)
 }
 
 func (sc *ServiceConfig) noAuth(realfunc ctxhandlerFunc) handlerFunc {
-	check := func(w *rest.ResponseWriter, r *rest.Request) bool {
-		return true
+	check := func(w *rest.ResponseWriter, r *rest.Request) (auth.Grants, bool) {
+		return nil, true
 	}
 	return sc.newRequestHandler(check, realfunc)
 }
@@ -376,6 +385,7 @@ type requestContext struct {
 	master   master.ClientInterface
 	dataCtx  datastore.Context
 	username string
+	grants   auth.Grants
 }
 
 func newRequestContext(sc *ServiceConfig) *requestContext {
@@ -427,7 +437,7 @@ func (ctx *requestContext) end() error {
 }
 
 type ctxhandlerFunc func(w *rest.ResponseWriter, r *rest.Request, ctx *requestContext)
-type checkFunc func(w *rest.ResponseWriter, r *rest.Request) bool
+type checkFunc func(w *rest.ResponseWriter, r *rest.Request) (auth.Grants, bool)
 
 type getRoutes func(sc *ServiceConfig) []rest.Route
 
@@ -515,17 +525,3 @@ func (sc *ServiceConfig) startVHostListener(shutdown <-chan interface{}) {
 		}
 	}()
 }
-
-// Get Auth0 Config info for UI
-func restGetAuth0Config(w *rest.ResponseWriter, r *rest.Request, ctx *requestContext) {
-	opts := config.GetOptions()
-	auth0Config := Auth0Config{
-		Auth0Scope:    opts.Auth0Scope,
-		Auth0ClientID: opts.Auth0ClientID,
-		Auth0Audience: opts.Auth0Audience,
-		Auth0Domain:   opts.Auth0Domain,
-	}
-	w.Write([]byte("var Auth0Config = "))
-	w.WriteJson(auth0Config)
-	w.Write([]byte(";\n"))
-}
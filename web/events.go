@@ -0,0 +1,180 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/control-center/serviced/auth"
+	"github.com/control-center/serviced/events"
+	"github.com/zenoss/go-json-rest"
+	"golang.org/x/net/websocket"
+)
+
+// eventsPath is the url of the event stream.  It is served before the rest
+// handler, because the rest handler cannot stream a response.
+const eventsPath = "/api/v2/events"
+
+// eventsKeepAlive is how often an idle server-sent event stream writes a
+// comment, so that proxies do not close it
+var eventsKeepAlive = 30 * time.Second
+
+// serveEvents streams the events that the user may view.  The stream is sent
+// as JSON messages over a WebSocket if the request asks to upgrade, and as
+// server-sent events otherwise.  The query parameters type (a comma separated
+// list of event types or prefixes), serviceId, hostId, poolId and tenantId
+// filter the events.  A stream starts with new events, unless since, or the
+// Last-Event-ID header of a reconnecting event source, gives the id of the
+// last event seen.
+func (sc *ServiceConfig) serveEvents(w http.ResponseWriter, r *http.Request) {
+	rw := rest.NewResponseWriter(w, false)
+	grants, ok := sc.checkRole(&rw, &rest.Request{Request: r}, auth.RoleViewer)
+	if !ok {
+		return
+	}
+	filter, since, err := parseEventsRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	logger := plog.WithField("filter", filter)
+
+	sub := sc.facade.SubscribeEvents(filter, since)
+	defer sub.Close()
+	allowed := func(event events.Event) bool {
+		return grants.Allows(auth.RoleViewer, event.PoolID, event.TenantID)
+	}
+
+	if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		websocket.Server{Handshake: checkEventsOrigin, Handler: func(ws *websocket.Conn) {
+			logger.Debug("Streaming events over a websocket")
+			go func() {
+				// the client does not send anything; stop when it hangs up
+				io.Copy(ioutil.Discard, ws)
+				sub.Close()
+			}()
+			for event := range sub.Events() {
+				if !allowed(event) {
+					continue
+				}
+				if err := websocket.JSON.Send(ws, event); err != nil {
+					logger.WithError(err).Debug("Could not send event; closing the websocket")
+					return
+				}
+			}
+		}}.ServeHTTP(w, r)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	var closed <-chan bool
+	if notifier, ok := w.(http.CloseNotifier); ok {
+		closed = notifier.CloseNotify()
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	logger.Debug("Streaming server-sent events")
+	keepAlive := time.NewTicker(eventsKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case event, ok := <-sub.Events():
+			if !ok {
+				// the subscription fell behind; the client will reconnect
+				// with the id of the last event it received
+				logger.Debug("Event stream overflowed")
+				return
+			}
+			if !allowed(event) {
+				continue
+			}
+			if err := writeServerSentEvent(w, event); err != nil {
+				logger.WithError(err).Debug("Could not write event")
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// checkEventsOrigin rejects WebSocket handshakes from pages served by another
+// site.  Browsers send cookies with cross-site WebSocket requests, so without
+// this check any page could read the event stream of a logged in user.
+func checkEventsOrigin(config *websocket.Config, r *http.Request) error {
+	origin, err := websocket.Origin(config, r)
+	if err != nil {
+		return err
+	}
+	if origin == nil || !strings.EqualFold(origin.Host, r.Host) {
+		return fmt.Errorf("origin %q does not match host %q", r.Header.Get("Origin"), r.Host)
+	}
+	config.Origin = origin
+	return nil
+}
+
+// parseEventsRequest returns the filter and the starting id of an event
+// stream request
+func parseEventsRequest(r *http.Request) (events.Filter, uint64, error) {
+	query := r.URL.Query()
+	filter := events.Filter{
+		Types:     events.ParseTypes(query.Get("type")),
+		PoolID:    query.Get("poolId"),
+		TenantID:  query.Get("tenantId"),
+		ServiceID: query.Get("serviceId"),
+		HostID:    query.Get("hostId"),
+	}
+	since := uint64(events.Now)
+	for _, value := range []string{r.Header.Get("Last-Event-ID"), query.Get("since")} {
+		if value == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return filter, 0, fmt.Errorf("invalid event id %q", value)
+		}
+		since = id
+		break
+	}
+	return filter, since, nil
+}
+
+// writeServerSentEvent writes an event in the text/event-stream format
+func writeServerSentEvent(w http.ResponseWriter, event events.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
@@ -0,0 +1,78 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build unit
+
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/control-center/serviced/events"
+	"golang.org/x/net/websocket"
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestWebSuite) TestParseEventsRequest(c *C) {
+	r, _ := http.NewRequest("GET", "/api/v2/events?type=service,instance.health&serviceId=svc1&poolId=pool1", nil)
+	filter, since, err := parseEventsRequest(r)
+	c.Assert(err, IsNil)
+	c.Assert(since, Equals, uint64(events.Now))
+	c.Assert(filter, DeepEquals, events.Filter{
+		Types:     []events.Type{"service", events.InstanceHealth},
+		PoolID:    "pool1",
+		ServiceID: "svc1",
+	})
+
+	r, _ = http.NewRequest("GET", "/api/v2/events?since=5", nil)
+	_, since, err = parseEventsRequest(r)
+	c.Assert(err, IsNil)
+	c.Assert(since, Equals, uint64(5))
+
+	// a reconnecting event source resumes after the last event it received
+	r.Header.Set("Last-Event-ID", "12")
+	_, since, err = parseEventsRequest(r)
+	c.Assert(err, IsNil)
+	c.Assert(since, Equals, uint64(12))
+
+	r, _ = http.NewRequest("GET", "/api/v2/events?since=latest", nil)
+	_, _, err = parseEventsRequest(r)
+	c.Assert(err, ErrorMatches, `invalid event id "latest"`)
+}
+
+func (s *TestWebSuite) TestCheckEventsOrigin(c *C) {
+	config := &websocket.Config{Version: websocket.ProtocolVersionHybi13}
+	r, _ := http.NewRequest("GET", "https://cc.example.com/api/v2/events", nil)
+	r.Host = "cc.example.com"
+
+	r.Header.Set("Origin", "https://cc.example.com")
+	c.Assert(checkEventsOrigin(config, r), IsNil)
+	c.Assert(config.Origin.Host, Equals, "cc.example.com")
+
+	r.Header.Set("Origin", "https://evil.example.com")
+	c.Assert(checkEventsOrigin(config, r), ErrorMatches, `origin "https://evil.example.com" does not match host "cc.example.com"`)
+
+	r.Header.Set("Origin", "null")
+	c.Assert(checkEventsOrigin(config, r), NotNil)
+
+	r.Header.Del("Origin")
+	c.Assert(checkEventsOrigin(config, r), NotNil)
+}
+
+func (s *TestWebSuite) TestWriteServerSentEvent(c *C) {
+	w := httptest.NewRecorder()
+	err := writeServerSentEvent(w, events.Event{ID: 3, Type: events.HostRegistered, HostID: "host1", State: "up"})
+	c.Assert(err, IsNil)
+	c.Assert(w.Body.String(), Matches, "id: 3\nevent: host.registered\ndata: \\{.*\"HostID\":\"host1\".*\\}\n\n")
+}
@@ -46,6 +46,9 @@ func restGetHosts(w *rest.ResponseWriter, r *rest.Request, ctx *requestContext)
 	glog.V(2).Infof("Returning %d hosts", len(hosts))
 	response := make(map[string]*host.Host)
 	for i, host := range hosts {
+		if !ctx.viewsPool(host.PoolID) {
+			continue
+		}
 		response[host.ID] = &hosts[i]
 		if err := buildHostMonitoringProfile(&hosts[i]); err != nil {
 			restServerError(w, err)
@@ -428,9 +431,17 @@ func restGetAggregateServices(w *rest.ResponseWriter, r *rest.Request, ctx *requ
 			restServerError(w, err)
 			return
 		}
-		for _, d := range details {
+		for _, d := range ctx.viewableServiceDetails(details) {
 			serviceIDs = append(serviceIDs, d.ID)
 		}
+	} else if !ctx.viewsAll() {
+		var viewable []string
+		for _, serviceID := range serviceIDs {
+			if ctx.viewsServiceID(serviceID) {
+				viewable = append(viewable, serviceID)
+			}
+		}
+		serviceIDs = viewable
 	}
 
 	aggServices, err := facade.GetAggregateServices(dataCtx, time.Now().Add(-tsince), serviceIDs)
@@ -453,6 +464,25 @@ func restGetActiveHostIDs(w *rest.ResponseWriter, r *rest.Request, ctx *requestC
 		return
 	}
 
+	if !ctx.viewsAll() {
+		hosts, err := facade.GetHosts(dataCtx)
+		if err != nil {
+			restServerError(w, err)
+			return
+		}
+		viewable := make(map[string]bool)
+		for _, h := range hosts {
+			viewable[h.ID] = ctx.viewsPool(h.PoolID)
+		}
+		active := []string{}
+		for _, hostID := range hostids {
+			if viewable[hostID] {
+				active = append(active, hostID)
+			}
+		}
+		hostids = active
+	}
+
 	w.WriteJson(&hostids)
 
 }
@@ -0,0 +1,165 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/control-center/serviced/auth"
+	"github.com/zenoss/go-json-rest"
+)
+
+// logins that are not completed within this time are discarded
+const oidcLoginTimeout = 10 * time.Minute
+
+type pendingOIDCLogin struct {
+	auth.OIDCLoginRequest
+	created time.Time
+}
+
+var (
+	oidcLogins     = make(map[string]pendingOIDCLogin)
+	oidcLoginsLock = &sync.Mutex{}
+)
+
+// OIDCConfig tells the UI how to log in
+type OIDCConfig struct {
+	Enabled bool
+}
+
+// Get OIDC Config info for UI
+func restGetOIDCConfig(w *rest.ResponseWriter, r *rest.Request, ctx *requestContext) {
+	oidcConfig := OIDCConfig{Enabled: auth.OIDCIsConfigured()}
+	w.Write([]byte("var OIDCConfig = "))
+	w.WriteJson(oidcConfig)
+	w.Write([]byte(";\n"))
+}
+
+// oidcRedirectURL returns the URL that the provider sends the user back to
+func oidcRedirectURL(provider *auth.OIDCProvider, r *rest.Request) string {
+	if u := provider.Config().RedirectURL; u != "" {
+		return u
+	}
+	return "https://" + r.Host + "/oidc/callback"
+}
+
+func randomURLStr() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+/*
+ * Start a login at the OIDC provider
+ */
+func restOIDCLogin(w *rest.ResponseWriter, r *rest.Request, ctx *requestContext) {
+	provider := auth.GetOIDCProvider()
+	if provider == nil {
+		writeJSON(w, &simpleResponse{"OIDC login is not configured", loginLink()}, http.StatusNotFound)
+		return
+	}
+	var req auth.OIDCLoginRequest
+	var err error
+	for _, v := range []*string{&req.State, &req.Nonce, &req.Verifier} {
+		if *v, err = randomURLStr(); err != nil {
+			restServerError(w, err)
+			return
+		}
+	}
+	loginURL, err := provider.AuthCodeURL(req, oidcRedirectURL(provider, r))
+	if err != nil {
+		plog.WithError(err).Warn("Unable to start OIDC login")
+		restServerError(w, err)
+		return
+	}
+
+	oidcLoginsLock.Lock()
+	now := time.Now()
+	for state, login := range oidcLogins {
+		if now.Sub(login.created) > oidcLoginTimeout {
+			delete(oidcLogins, state)
+		}
+	}
+	oidcLogins[req.State] = pendingOIDCLogin{OIDCLoginRequest: req, created: now}
+	oidcLoginsLock.Unlock()
+
+	http.Redirect(w.ResponseWriter, r.Request, loginURL, http.StatusFound)
+}
+
+/*
+ * Complete a login at the OIDC provider
+ */
+func restOIDCCallback(w *rest.ResponseWriter, r *rest.Request, ctx *requestContext) {
+	provider := auth.GetOIDCProvider()
+	if provider == nil {
+		writeJSON(w, &simpleResponse{"OIDC login is not configured", loginLink()}, http.StatusNotFound)
+		return
+	}
+	query := r.URL.Query()
+	if e := query.Get("error"); e != "" {
+		plog.WithField("error", e).WithField("description", query.Get("error_description")).Warn("OIDC login failed at the provider")
+		writeJSON(w, &simpleResponse{"Login failed", loginLink()}, http.StatusUnauthorized)
+		return
+	}
+
+	oidcLoginsLock.Lock()
+	login, ok := oidcLogins[query.Get("state")]
+	delete(oidcLogins, query.Get("state"))
+	oidcLoginsLock.Unlock()
+	if !ok || time.Since(login.created) > oidcLoginTimeout {
+		plog.Debug("OIDC callback has an unknown or expired state")
+		writeJSON(w, &simpleResponse{"Login expired", loginLink()}, http.StatusUnauthorized)
+		return
+	}
+
+	token, parsed, err := provider.Exchange(login.OIDCLoginRequest, query.Get("code"), oidcRedirectURL(provider, r))
+	if err != nil {
+		plog.WithError(err).Warn("Unable to complete OIDC login")
+		writeJSON(w, &simpleResponse{"Login failed", loginLink()}, http.StatusUnauthorized)
+		return
+	}
+	if len(parsed.Grants()) == 0 {
+		writeJSON(w, &simpleResponse{"Insufficient permissions", loginLink()}, http.StatusForbidden)
+		return
+	}
+	plog.WithField("user", parsed.User()).Info("OIDC login succeeded")
+	setOIDCCookies(w.ResponseWriter, token, parsed)
+	http.Redirect(w.ResponseWriter, r.Request, "/", http.StatusFound)
+}
+
+/*
+ * Log out and end the session at the OIDC provider
+ */
+func restOIDCLogout(w *rest.ResponseWriter, r *rest.Request, ctx *requestContext) {
+	var idToken string
+	if cookie, err := r.Request.Cookie(oidcTokenCookie); err == nil {
+		idToken = cookie.Value
+	}
+	writeBlankCookie(w, r, oidcTokenCookie)
+	writeBlankCookie(w, r, usernameCookie)
+
+	redirect := "/"
+	if provider := auth.GetOIDCProvider(); provider != nil {
+		if u := provider.LogoutURL(idToken, "https://"+r.Host+"/"); u != "" {
+			redirect = u
+		}
+	}
+	http.Redirect(w.ResponseWriter, r.Request, redirect, http.StatusFound)
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/Sirupsen/logrus"
 	"github.com/zenoss/go-json-rest"
 
+	"github.com/control-center/serviced/auth"
 	"github.com/control-center/serviced/dao"
 	daoclient "github.com/control-center/serviced/dao/client"
 	"github.com/control-center/serviced/domain"
@@ -97,7 +98,9 @@ func getServices(ctx *requestContext, tenantID string, since time.Duration) ([]s
 func getISVCS() []service.Service {
 	services := []service.Service{}
 	services = append(services, isvcs.InternalServicesISVC)
-	services = append(services, isvcs.ElasticsearchServicedISVC)
+	if config.GetOptions().DatastoreDriver != config.DatastoreEmbedded {
+		services = append(services, isvcs.ElasticsearchServicedISVC)
+	}
 	services = append(services, isvcs.ElasticsearchLogStashISVC)
 	services = append(services, isvcs.ZookeeperISVC)
 	services = append(services, isvcs.LogstashISVC)
@@ -113,7 +116,9 @@ func getISVCS() []service.Service {
 func getIRS() []dao.RunningService {
 	services := []dao.RunningService{}
 	services = append(services, isvcs.InternalServicesIRS)
-	services = append(services, isvcs.ElasticsearchServicedIRS)
+	if config.GetOptions().DatastoreDriver != config.DatastoreEmbedded {
+		services = append(services, isvcs.ElasticsearchServicedIRS)
+	}
 	services = append(services, isvcs.ElasticsearchLogStashIRS)
 	services = append(services, isvcs.GetZooKeeperRunningInstances()...)
 	services = append(services, isvcs.LogstashIRS)
@@ -165,6 +170,7 @@ func restGetAllServices(w *rest.ResponseWriter, r *rest.Request, ctx *requestCon
 			restServerError(w, err)
 			return
 		}
+		result = ctx.viewableServices(result)
 
 		for ii, svc := range result {
 			if len(svc.Startup) > 2 {
@@ -182,6 +188,7 @@ func restGetAllServices(w *rest.ResponseWriter, r *rest.Request, ctx *requestCon
 			restServerError(w, err)
 			return
 		}
+		result = ctx.viewableServices(result)
 
 		for ii, svc := range result {
 			if len(svc.Startup) > 2 {
@@ -223,6 +230,7 @@ func restGetAllServices(w *rest.ResponseWriter, r *rest.Request, ctx *requestCon
 			}
 		}
 	}
+	result = ctx.viewableServices(result)
 
 	for ii, svc := range result {
 		if strings.HasPrefix(result[ii].ID, "isvc-") {
@@ -344,6 +352,9 @@ func restGetTopServices(w *rest.ResponseWriter, r *rest.Request, ctx *requestCon
 		return
 	}
 	for _, tenant := range allTenants {
+		if !ctx.viewsService(tenant.ID, "", tenant.PoolID) {
+			continue
+		}
 		service, err := ctx.getFacade().GetService(ctx.getDatastoreContext(), tenant.ID)
 		if err != nil {
 			plog.WithField("tenantid", tenant.ID).WithError(err).Error("Could not get service")
@@ -352,7 +363,9 @@ func restGetTopServices(w *rest.ResponseWriter, r *rest.Request, ctx *requestCon
 		}
 		topServices = append(topServices, *service)
 	}
-	topServices = append(topServices, isvcs.InternalServicesISVC)
+	if ctx.viewsAll() {
+		topServices = append(topServices, isvcs.InternalServicesISVC)
+	}
 	plog.WithField("numservices", len(topServices)).Debug("Got top services")
 	w.WriteJson(&topServices)
 }
@@ -440,7 +453,7 @@ func restAddService(w *rest.ResponseWriter, r *rest.Request, client *daoclient.C
 		err := client.FindChildService(dao.FindChildRequest{svcID, childName}, &svc)
 		return svc, err
 	}
-	if err = svc.EvaluateEndpointTemplates(getSvc, findChild, 0); err != nil {
+	if err = svc.EvaluateEndpointTemplates(getSvc, findChild, nil, 0); err != nil {
 		plog.WithError(err).Error("Unable to evaluate service endpoints")
 		restServerError(w, err)
 		return
@@ -612,6 +625,10 @@ func restRestartServices(w *rest.ResponseWriter, r *rest.Request, ctx *requestCo
 		return
 
 	}
+	if !ctx.authorizedServices(auth.RoleOperator, serviceRequest.ServiceIDs) {
+		restForbidden(w)
+		return
+	}
 
 	logger := plog.WithField("serviceids", serviceRequest.ServiceIDs)
 	serviceFacade := ctx.getFacade()
@@ -681,6 +698,10 @@ func restStartServices(w *rest.ResponseWriter, r *rest.Request, ctx *requestCont
 		return
 
 	}
+	if !ctx.authorizedServices(auth.RoleOperator, serviceRequest.ServiceIDs) {
+		restForbidden(w)
+		return
+	}
 
 	logger := plog.WithField("serviceids", serviceRequest.ServiceIDs)
 	serviceFacade := ctx.getFacade()
@@ -741,6 +762,10 @@ func restStopServices(w *rest.ResponseWriter, r *rest.Request, ctx *requestConte
 		return
 
 	}
+	if !ctx.authorizedServices(auth.RoleOperator, serviceRequest.ServiceIDs) {
+		restForbidden(w)
+		return
+	}
 
 	logger := plog.WithField("serviceids", serviceRequest.ServiceIDs)
 
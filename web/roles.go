@@ -0,0 +1,165 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/control-center/serviced/auth"
+	"github.com/control-center/serviced/datastore"
+	"github.com/control-center/serviced/domain/service"
+	"github.com/zenoss/go-json-rest"
+)
+
+// authorized returns true if the grants give the role on the resource named
+// by the request's path.  Requests that do not name a resource, such as
+// lists, can be read by anyone with a role in some pool or tenant, and the
+// list handlers only return what the grants cover.  All other requests without
+// a resource need an unscoped grant.
+func (sc *ServiceConfig) authorized(r *rest.Request, grants auth.Grants, role auth.Role) bool {
+	pool, tenant, ok := sc.requestScope(r)
+	if !ok && role == auth.RoleViewer {
+		return grants.Max().Includes(role)
+	}
+	return grants.Allows(role, pool, tenant)
+}
+
+// requestScope returns the pool and tenant of the service, pool or host named
+// by the request's path.  ok is false if the path does not name a resource.
+func (sc *ServiceConfig) requestScope(r *rest.Request) (pool, tenant string, ok bool) {
+	if id, err := url.QueryUnescape(r.PathParam("serviceId")); err == nil && id != "" {
+		pool, tenant = sc.serviceScope(id)
+		return pool, tenant, true
+	}
+	if id, err := url.QueryUnescape(r.PathParam("poolId")); err == nil && id != "" {
+		return id, "", true
+	}
+	if id, err := url.QueryUnescape(r.PathParam("hostId")); err == nil && id != "" {
+		if sc.facade == nil {
+			return "", "", true
+		}
+		h, err := sc.facade.GetHost(datastore.GetNewInstance(), id)
+		if err != nil || h == nil {
+			plog.WithError(err).WithField("hostid", id).Debug("Could not look up the pool of host")
+			return "", "", true
+		}
+		return h.PoolID, "", true
+	}
+	return "", "", false
+}
+
+// serviceScope returns the pool and tenant of a service.  Both are empty if
+// the service cannot be found, so that only unscoped grants cover it.
+func (sc *ServiceConfig) serviceScope(serviceID string) (pool, tenant string) {
+	if sc.facade == nil {
+		return "", ""
+	}
+	ctx := datastore.GetNewInstance()
+	svc, err := sc.facade.GetService(ctx, serviceID)
+	if err != nil || svc == nil {
+		plog.WithError(err).WithField("serviceid", serviceID).Debug("Could not look up the scope of service")
+		return "", ""
+	}
+	tenant, err = sc.facade.GetTenantID(ctx, serviceID)
+	if err != nil {
+		plog.WithError(err).WithField("serviceid", serviceID).Debug("Could not look up the tenant of service")
+		return "", ""
+	}
+	return svc.PoolID, tenant
+}
+
+// authorizedServices returns true if the user has the role on every service.
+// Handlers that act on a list of services from the request body use this,
+// since the route wrapper can only see the path.
+func (ctx *requestContext) authorizedServices(role auth.Role, serviceIDs []string) bool {
+	for _, serviceID := range serviceIDs {
+		pool, tenant := ctx.sc.serviceScope(serviceID)
+		if !ctx.grants.Allows(role, pool, tenant) {
+			plog.WithField("serviceid", serviceID).WithField("role", role).Debug("User does not have the role on service")
+			return false
+		}
+	}
+	return true
+}
+
+// viewsAll returns true if the user can view every resource, so that lists do
+// not need to be filtered
+func (ctx *requestContext) viewsAll() bool {
+	return ctx.grants.Allows(auth.RoleViewer, "", "")
+}
+
+// viewsPool returns true if the user can view a pool and its hosts
+func (ctx *requestContext) viewsPool(poolID string) bool {
+	return ctx.grants.Allows(auth.RoleViewer, poolID, "")
+}
+
+// viewsService returns true if the user can view a service.  A service
+// without a parent is its own tenant, and internal services belong to no
+// tenant.
+func (ctx *requestContext) viewsService(serviceID, parentID, poolID string) bool {
+	if ctx.viewsAll() {
+		return true
+	} else if strings.HasPrefix(serviceID, "isvc-") {
+		return false
+	}
+	tenant := serviceID
+	if parentID != "" {
+		var err error
+		if tenant, err = ctx.getFacade().GetTenantID(ctx.getDatastoreContext(), serviceID); err != nil {
+			plog.WithError(err).WithField("serviceid", serviceID).Debug("Could not look up the tenant of service")
+			return false
+		}
+	}
+	return ctx.grants.Allows(auth.RoleViewer, poolID, tenant)
+}
+
+// viewsServiceID returns true if the user can view the service with the id
+func (ctx *requestContext) viewsServiceID(serviceID string) bool {
+	if ctx.viewsAll() {
+		return true
+	} else if strings.HasPrefix(serviceID, "isvc-") {
+		return false
+	}
+	pool, tenant := ctx.sc.serviceScope(serviceID)
+	return ctx.grants.Allows(auth.RoleViewer, pool, tenant)
+}
+
+// viewableServices returns the services that the user can view
+func (ctx *requestContext) viewableServices(svcs []service.Service) []service.Service {
+	if ctx.viewsAll() {
+		return svcs
+	}
+	result := []service.Service{}
+	for _, svc := range svcs {
+		if ctx.viewsService(svc.ID, svc.ParentServiceID, svc.PoolID) {
+			result = append(result, svc)
+		}
+	}
+	return result
+}
+
+// viewableServiceDetails returns the service details that the user can view
+func (ctx *requestContext) viewableServiceDetails(details []service.ServiceDetails) []service.ServiceDetails {
+	if ctx.viewsAll() {
+		return details
+	}
+	result := []service.ServiceDetails{}
+	for _, d := range details {
+		if ctx.viewsService(d.ID, d.ParentServiceID, d.PoolID) {
+			result = append(result, d)
+		}
+	}
+	return result
+}
@@ -0,0 +1,134 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build unit
+
+package web
+
+import (
+	"errors"
+
+	"github.com/control-center/serviced/auth"
+	"github.com/control-center/serviced/domain/host"
+	"github.com/control-center/serviced/domain/pool"
+	"github.com/control-center/serviced/domain/service"
+	"github.com/stretchr/testify/mock"
+	. "gopkg.in/check.v1"
+)
+
+func (s *TestWebSuite) setUpScopes() {
+	s.mockFacade.On("GetService", mock.Anything, "svc1").Return(&service.Service{ID: "svc1", PoolID: "pool1"}, nil)
+	s.mockFacade.On("GetTenantID", mock.Anything, "svc1").Return("tenant1", nil)
+	s.mockFacade.On("GetService", mock.Anything, "svc2").Return(&service.Service{ID: "svc2", PoolID: "pool2"}, nil)
+	s.mockFacade.On("GetTenantID", mock.Anything, "svc2").Return("tenant2", nil)
+	s.mockFacade.On("GetService", mock.Anything, "missing").Return(nil, errors.New("not found"))
+	s.mockFacade.On("GetHost", mock.Anything, "host1").Return(&host.Host{ID: "host1", PoolID: "pool1"}, nil)
+}
+
+func (s *TestWebSuite) TestAuthorizedScopedOperator(c *C) {
+	s.setUpScopes()
+	grants := auth.Grants{{Role: auth.RoleOperator, Tenant: "tenant1"}}
+
+	request := s.buildRequest("PUT", "http://www.example.com/services/svc1/restartService", "")
+	request.PathParams["serviceId"] = "svc1"
+	c.Assert(s.ctx.sc.authorized(&request, grants, auth.RoleOperator), Equals, true)
+	c.Assert(s.ctx.sc.authorized(&request, grants, auth.RoleAdmin), Equals, false)
+
+	request.PathParams["serviceId"] = "svc2"
+	c.Assert(s.ctx.sc.authorized(&request, grants, auth.RoleViewer), Equals, false)
+
+	request.PathParams["serviceId"] = "missing"
+	c.Assert(s.ctx.sc.authorized(&request, grants, auth.RoleViewer), Equals, false)
+
+	// lists can be read, but other requests without a resource need an
+	// unscoped role
+	request = s.buildRequest("GET", "http://www.example.com/services", "")
+	c.Assert(s.ctx.sc.authorized(&request, grants, auth.RoleViewer), Equals, true)
+	request = s.buildRequest("POST", "http://www.example.com/pools/add", "")
+	c.Assert(s.ctx.sc.authorized(&request, grants, auth.RoleOperator), Equals, false)
+	c.Assert(s.ctx.sc.authorized(&request, auth.Grants{{Role: auth.RoleAdmin}}, auth.RoleAdmin), Equals, true)
+}
+
+func (s *TestWebSuite) TestAuthorizedScopedPool(c *C) {
+	s.setUpScopes()
+	grants := auth.Grants{{Role: auth.RoleAdmin, Pool: "pool1"}}
+
+	request := s.buildRequest("DELETE", "http://www.example.com/pools/pool1", "")
+	request.PathParams["poolId"] = "pool1"
+	c.Assert(s.ctx.sc.authorized(&request, grants, auth.RoleAdmin), Equals, true)
+	request.PathParams["poolId"] = "pool2"
+	c.Assert(s.ctx.sc.authorized(&request, grants, auth.RoleAdmin), Equals, false)
+
+	request = s.buildRequest("DELETE", "http://www.example.com/hosts/host1/state1", "")
+	request.PathParams["hostId"] = "host1"
+	c.Assert(s.ctx.sc.authorized(&request, grants, auth.RoleOperator), Equals, true)
+}
+
+func (s *TestWebSuite) TestAuthorizedServices(c *C) {
+	s.setUpScopes()
+	s.ctx.grants = auth.Grants{{Role: auth.RoleOperator, Pool: "pool1"}, {Role: auth.RoleViewer}}
+	c.Assert(s.ctx.authorizedServices(auth.RoleOperator, []string{"svc1"}), Equals, true)
+	c.Assert(s.ctx.authorizedServices(auth.RoleOperator, []string{"svc1", "svc2"}), Equals, false)
+	c.Assert(s.ctx.authorizedServices(auth.RoleViewer, []string{"svc1", "svc2"}), Equals, true)
+}
+
+func (s *TestWebSuite) TestRestRestartServicesForbidden(c *C) {
+	s.setUpScopes()
+	s.ctx.grants = auth.Grants{{Role: auth.RoleOperator, Tenant: "tenant1"}}
+	request := s.buildRequest("PUT", "http://www.example.com/services/restartServices", `{"ServiceIDs": ["svc2"]}`)
+
+	restRestartServices(&(s.writer), &request, s.ctx)
+
+	c.Assert(s.recorder.Code, Equals, 403)
+	s.assertSimpleResponse(c, "Insufficient permissions", homeLink())
+}
+
+func (s *TestWebSuite) TestGetAllServiceDetailsScoped(c *C) {
+	s.setUpScopes()
+	s.ctx.grants = auth.Grants{{Role: auth.RoleViewer, Tenant: "tenant1"}}
+	details := []service.ServiceDetails{
+		{ID: "tenant1", PoolID: "pool1"},
+		{ID: "svc1", ParentServiceID: "tenant1", PoolID: "pool1"},
+		{ID: "tenant2", PoolID: "pool2"},
+		{ID: "svc2", ParentServiceID: "tenant2", PoolID: "pool2"},
+	}
+	s.mockFacade.On("QueryServiceDetails", mock.Anything, mock.AnythingOfType("service.Query")).Return(details, nil)
+	request := s.buildRequest("GET", "http://www.example.com/api/v2/services", "")
+
+	getAllServiceDetails(&(s.writer), &request, s.ctx)
+
+	var result []service.ServiceDetails
+	s.getResult(c, &result)
+	c.Assert(result, HasLen, 2)
+	c.Assert(result[0].ID, Equals, "tenant1")
+	c.Assert(result[1].ID, Equals, "svc1")
+}
+
+func (s *TestWebSuite) TestGetPoolsScoped(c *C) {
+	s.ctx.grants = auth.Grants{{Role: auth.RoleViewer, Pool: "pool1"}}
+	pools := []pool.ReadPool{{ID: "pool1"}, {ID: "pool2"}}
+	s.mockFacade.On("GetReadPools", mock.Anything).Return(pools, nil)
+	request := s.buildRequest("GET", "http://www.example.com/api/v2/pools", "")
+
+	getPools(&(s.writer), &request, s.ctx)
+
+	var result []pool.ReadPool
+	s.getResult(c, &result)
+	c.Assert(result, HasLen, 1)
+	c.Assert(result[0].ID, Equals, "pool1")
+
+	// tenant grants do not cover pools
+	s.ctx.grants = auth.Grants{{Role: auth.RoleViewer, Tenant: "tenant1"}}
+	c.Assert(s.ctx.viewsPool("pool1"), Equals, false)
+	c.Assert(s.ctx.viewsService("isvc-zookeeper", "isvc-internalservices", ""), Equals, false)
+}
@@ -13,7 +13,10 @@
 
 package web
 
-import "github.com/zenoss/go-json-rest"
+import (
+	"github.com/control-center/serviced/auth"
+	"github.com/zenoss/go-json-rest"
+)
 
 //getRoutes returns all registered rest routes
 func (sc *ServiceConfig) getRoutes() []rest.Route {
@@ -24,132 +27,140 @@ func (sc *ServiceConfig) getRoutes() []rest.Route {
 		rest.Route{"GET", "/", gz(mainPage)},
 
 		// Backups
-		rest.Route{"GET", "/backup/check", gz(sc.authorizedClient(RestBackupCheck))},
-		rest.Route{"GET", "/backup/create", gz(sc.authorizedClient(RestBackupCreate))},
-		rest.Route{"GET", "/backup/restore", gz(sc.authorizedClient(RestBackupRestore))},
-		rest.Route{"GET", "/backup/list", gz(sc.authorizedClient(RestBackupFileList))},
-		rest.Route{"GET", "/backup/status", gz(sc.authorizedClient(RestBackupStatus))},
-		rest.Route{"GET", "/backup/restore/status", gz(sc.authorizedClient(RestRestoreStatus))},
+		rest.Route{"GET", "/backup/check", gz(sc.authorizedClient(auth.RoleViewer, RestBackupCheck))},
+		rest.Route{"GET", "/backup/create", gz(sc.authorizedClient(auth.RoleAdmin, RestBackupCreate))},
+		rest.Route{"GET", "/backup/restore", gz(sc.authorizedClient(auth.RoleAdmin, RestBackupRestore))},
+		rest.Route{"GET", "/backup/list", gz(sc.authorizedClient(auth.RoleViewer, RestBackupFileList))},
+		rest.Route{"GET", "/backup/status", gz(sc.authorizedClient(auth.RoleViewer, RestBackupStatus))},
+		rest.Route{"GET", "/backup/restore/status", gz(sc.authorizedClient(auth.RoleViewer, RestRestoreStatus))},
 
 		// Hosts
-		rest.Route{"GET", "/hosts", gz(sc.checkAuth(restGetHosts))},
-		rest.Route{"GET", "/hosts/running", gz(sc.checkAuth(restGetActiveHostIDs))},
-		rest.Route{"GET", "/hosts/defaultHostAlias", gz(sc.checkAuth(restGetDefaultHostAlias))},
-		rest.Route{"GET", "/hosts/:hostId", gz(sc.checkAuth(restGetHost))},
-		rest.Route{"POST", "/hosts/add", gz(sc.checkAuth(restAddHost))},
-		rest.Route{"DELETE", "/hosts/:hostId", gz(sc.checkAuth(restRemoveHost))},
-		rest.Route{"PUT", "/hosts/:hostId", gz(sc.checkAuth(restUpdateHost))},
-		rest.Route{"GET", "/hosts/:hostId/running", gz(sc.authorizedClient(restGetRunningForHost))},
-		rest.Route{"DELETE", "/hosts/:hostId/:serviceStateId", gz(sc.authorizedClient(restKillRunning))},
-		rest.Route{"POST", "/hosts/:hostId/key", gz(sc.checkAuth(restResetHostKey))},
+		rest.Route{"GET", "/hosts", gz(sc.checkAuth(auth.RoleViewer, restGetHosts))},
+		rest.Route{"GET", "/hosts/running", gz(sc.checkAuth(auth.RoleViewer, restGetActiveHostIDs))},
+		rest.Route{"GET", "/hosts/defaultHostAlias", gz(sc.checkAuth(auth.RoleViewer, restGetDefaultHostAlias))},
+		rest.Route{"GET", "/hosts/:hostId", gz(sc.checkAuth(auth.RoleViewer, restGetHost))},
+		rest.Route{"POST", "/hosts/add", gz(sc.checkAuth(auth.RoleAdmin, restAddHost))},
+		rest.Route{"DELETE", "/hosts/:hostId", gz(sc.checkAuth(auth.RoleAdmin, restRemoveHost))},
+		rest.Route{"PUT", "/hosts/:hostId", gz(sc.checkAuth(auth.RoleAdmin, restUpdateHost))},
+		rest.Route{"GET", "/hosts/:hostId/running", gz(sc.authorizedClient(auth.RoleViewer, restGetRunningForHost))},
+		rest.Route{"DELETE", "/hosts/:hostId/:serviceStateId", gz(sc.authorizedClient(auth.RoleOperator, restKillRunning))},
+		rest.Route{"POST", "/hosts/:hostId/key", gz(sc.checkAuth(auth.RoleAdmin, restResetHostKey))},
 
 		// Pools
-		rest.Route{"GET", "/pools/:poolId", gz(sc.checkAuth(restGetPool))},
-		rest.Route{"DELETE", "/pools/:poolId", gz(sc.checkAuth(restRemovePool))},
-		rest.Route{"PUT", "/pools/:poolId", gz(sc.checkAuth(restUpdatePool))},
-		rest.Route{"POST", "/pools/add", gz(sc.checkAuth(restAddPool))},
-		rest.Route{"GET", "/pools", gz(sc.checkAuth(restGetPools))},
-		rest.Route{"GET", "/pools/:poolId/hosts", gz(sc.checkAuth(restGetHostsForResourcePool))},
+		rest.Route{"GET", "/pools/:poolId", gz(sc.checkAuth(auth.RoleViewer, restGetPool))},
+		rest.Route{"DELETE", "/pools/:poolId", gz(sc.checkAuth(auth.RoleAdmin, restRemovePool))},
+		rest.Route{"PUT", "/pools/:poolId", gz(sc.checkAuth(auth.RoleAdmin, restUpdatePool))},
+		rest.Route{"POST", "/pools/add", gz(sc.checkAuth(auth.RoleAdmin, restAddPool))},
+		rest.Route{"GET", "/pools", gz(sc.checkAuth(auth.RoleViewer, restGetPools))},
+		rest.Route{"GET", "/pools/:poolId/hosts", gz(sc.checkAuth(auth.RoleViewer, restGetHostsForResourcePool))},
 
 		// Pools (VirtualIP)
-		rest.Route{"PUT", "/pools/:poolId/virtualip", gz(sc.checkAuth(restAddPoolVirtualIP))},
-		rest.Route{"DELETE", "/pools/:poolId/virtualip/*ip", gz(sc.checkAuth(restRemovePoolVirtualIP))},
+		rest.Route{"PUT", "/pools/:poolId/virtualip", gz(sc.checkAuth(auth.RoleAdmin, restAddPoolVirtualIP))},
+		rest.Route{"DELETE", "/pools/:poolId/virtualip/*ip", gz(sc.checkAuth(auth.RoleAdmin, restRemovePoolVirtualIP))},
 
 		// Pools (IPs)
-		rest.Route{"GET", "/pools/:poolId/ips", gz(sc.checkAuth(restGetPoolIps))},
+		rest.Route{"GET", "/pools/:poolId/ips", gz(sc.checkAuth(auth.RoleViewer, restGetPoolIps))},
 
 		// Services (Apps)
-		rest.Route{"GET", "/services", gz(sc.checkAuth(restGetAllServices))},
-		rest.Route{"GET", "/servicehealth", gz(sc.checkAuth(restGetServicesHealth))},
-		rest.Route{"GET", "/services/:serviceId", gz(sc.authorizedClient(restGetService))},
-		rest.Route{"GET", "/services/:serviceId/running", gz(sc.authorizedClient(restGetRunningForService))},
-		rest.Route{"GET", "/services/:serviceId/:serviceStateId/logs", gz(sc.authorizedClient(restGetServiceStateLogs))},
-		rest.Route{"GET", "/services/:serviceId/:serviceStateId/logs/download", gz(sc.authorizedClient(downloadServiceStateLogs))},
-		rest.Route{"POST", "/services/add", gz(sc.authorizedClient(restAddService))},
-		rest.Route{"POST", "/services/deploy", gz(sc.authorizedClient(restDeployService))},
-		rest.Route{"PUT", "/services/restartServices", gz(sc.checkAuth(restRestartServices))},
-		rest.Route{"PUT", "/services/startServices", gz(sc.checkAuth(restStartServices))},
-		rest.Route{"PUT", "/services/stopServices", gz(sc.checkAuth(restStopServices))},
-		rest.Route{"DELETE", "/services/:serviceId", gz(sc.checkAuth(restRemoveService))},
-		rest.Route{"GET", "/services/:serviceId/logs", gz(sc.authorizedClient(restGetServiceLogs))},
-		rest.Route{"PUT", "/services/:serviceId", gz(sc.authorizedClient(restUpdateService))},
-		rest.Route{"GET", "/services/:serviceId/snapshot", gz(sc.authorizedClient(restSnapshotService))},
-		rest.Route{"PUT", "/services/:serviceId/restartService", gz(sc.checkAuth(restRestartService))},
-		rest.Route{"PUT", "/services/:serviceId/startService", gz(sc.checkAuth(restStartService))},
-		rest.Route{"PUT", "/services/:serviceId/stopService", gz(sc.checkAuth(restStopService))},
-		rest.Route{"POST", "/services/:serviceId/migrate", sc.authorizedClient(restPostServicesForMigration)},
+		rest.Route{"GET", "/services", gz(sc.checkAuth(auth.RoleViewer, restGetAllServices))},
+		rest.Route{"GET", "/servicehealth", gz(sc.checkAuth(auth.RoleViewer, restGetServicesHealth))},
+		rest.Route{"GET", "/services/:serviceId", gz(sc.authorizedClient(auth.RoleViewer, restGetService))},
+		rest.Route{"GET", "/services/:serviceId/running", gz(sc.authorizedClient(auth.RoleViewer, restGetRunningForService))},
+		rest.Route{"GET", "/services/:serviceId/:serviceStateId/logs", gz(sc.authorizedClient(auth.RoleViewer, restGetServiceStateLogs))},
+		rest.Route{"GET", "/services/:serviceId/:serviceStateId/logs/download", gz(sc.authorizedClient(auth.RoleViewer, downloadServiceStateLogs))},
+		rest.Route{"POST", "/services/add", gz(sc.authorizedClient(auth.RoleAdmin, restAddService))},
+		rest.Route{"POST", "/services/deploy", gz(sc.authorizedClient(auth.RoleAdmin, restDeployService))},
+		// The handlers check the role on each of the services in the request
+		rest.Route{"PUT", "/services/restartServices", gz(sc.checkAuth(auth.RoleViewer, restRestartServices))},
+		rest.Route{"PUT", "/services/startServices", gz(sc.checkAuth(auth.RoleViewer, restStartServices))},
+		rest.Route{"PUT", "/services/stopServices", gz(sc.checkAuth(auth.RoleViewer, restStopServices))},
+		rest.Route{"DELETE", "/services/:serviceId", gz(sc.checkAuth(auth.RoleAdmin, restRemoveService))},
+		rest.Route{"GET", "/services/:serviceId/logs", gz(sc.authorizedClient(auth.RoleViewer, restGetServiceLogs))},
+		rest.Route{"PUT", "/services/:serviceId", gz(sc.authorizedClient(auth.RoleAdmin, restUpdateService))},
+		rest.Route{"GET", "/services/:serviceId/snapshot", gz(sc.authorizedClient(auth.RoleAdmin, restSnapshotService))},
+		rest.Route{"PUT", "/services/:serviceId/restartService", gz(sc.checkAuth(auth.RoleOperator, restRestartService))},
+		rest.Route{"PUT", "/services/:serviceId/startService", gz(sc.checkAuth(auth.RoleOperator, restStartService))},
+		rest.Route{"PUT", "/services/:serviceId/stopService", gz(sc.checkAuth(auth.RoleOperator, restStopService))},
+		rest.Route{"POST", "/services/:serviceId/migrate", sc.authorizedClient(auth.RoleAdmin, restPostServicesForMigration)},
 
 		// Services (Virtual Host)
-		rest.Route{"PUT", "/services/:serviceId/endpoint/:application/vhosts/*name", gz(sc.checkAuth(restAddVirtualHost))},
-		rest.Route{"DELETE", "/services/:serviceId/endpoint/:application/vhosts/*name", gz(sc.checkAuth(restRemoveVirtualHost))},
-		rest.Route{"POST", "/services/:serviceId/endpoint/:application/vhosts/*name", gz(sc.checkAuth(restVirtualHostEnable))},
+		rest.Route{"PUT", "/services/:serviceId/endpoint/:application/vhosts/*name", gz(sc.checkAuth(auth.RoleAdmin, restAddVirtualHost))},
+		rest.Route{"DELETE", "/services/:serviceId/endpoint/:application/vhosts/*name", gz(sc.checkAuth(auth.RoleAdmin, restRemoveVirtualHost))},
+		rest.Route{"POST", "/services/:serviceId/endpoint/:application/vhosts/*name", gz(sc.checkAuth(auth.RoleAdmin, restVirtualHostEnable))},
 		// Services (Endpoint Ports)
-		rest.Route{"PUT", "/services/:serviceId/endpoint/:application/ports/*portname", gz(sc.checkAuth(restAddPort))},
-		rest.Route{"DELETE", "/services/:serviceId/endpoint/:application/ports/*portname", gz(sc.checkAuth(restRemovePort))},
-		rest.Route{"POST", "/services/:serviceId/endpoint/:application/ports/*portname", gz(sc.checkAuth(restPortEnable))},
+		rest.Route{"PUT", "/services/:serviceId/endpoint/:application/ports/*portname", gz(sc.checkAuth(auth.RoleAdmin, restAddPort))},
+		rest.Route{"DELETE", "/services/:serviceId/endpoint/:application/ports/*portname", gz(sc.checkAuth(auth.RoleAdmin, restRemovePort))},
+		rest.Route{"POST", "/services/:serviceId/endpoint/:application/ports/*portname", gz(sc.checkAuth(auth.RoleAdmin, restPortEnable))},
 
 		// Services (IP)
-		rest.Route{"PUT", "/services/:serviceId/ip", gz(sc.checkAuth(restServiceAutomaticAssignIP))},
-		rest.Route{"PUT", "/services/:serviceId/ip/*ip", gz(sc.checkAuth(restServiceManualAssignIP))},
+		rest.Route{"PUT", "/services/:serviceId/ip", gz(sc.checkAuth(auth.RoleAdmin, restServiceAutomaticAssignIP))},
+		rest.Route{"PUT", "/services/:serviceId/ip/*ip", gz(sc.checkAuth(auth.RoleAdmin, restServiceManualAssignIP))},
 
 		// Service templates (App templates)
-		rest.Route{"GET", "/templates", gz(sc.checkAuth(restGetAppTemplates))},
-		rest.Route{"POST", "/templates/add", gz(sc.checkAuth(restAddAppTemplate))},
-		rest.Route{"DELETE", "/templates/:templateId", gz(sc.checkAuth(restRemoveAppTemplate))},
-		rest.Route{"POST", "/templates/deploy", gz(sc.checkAuth(restDeployAppTemplate))},
-		rest.Route{"POST", "/templates/deploy/status", gz(sc.checkAuth(restDeployAppTemplateStatus))},
-		rest.Route{"GET", "/templates/deploy/active", gz(sc.checkAuth(restDeployAppTemplateActive))},
+		rest.Route{"GET", "/templates", gz(sc.checkAuth(auth.RoleViewer, restGetAppTemplates))},
+		rest.Route{"POST", "/templates/add", gz(sc.checkAuth(auth.RoleAdmin, restAddAppTemplate))},
+		rest.Route{"DELETE", "/templates/:templateId", gz(sc.checkAuth(auth.RoleAdmin, restRemoveAppTemplate))},
+		rest.Route{"POST", "/templates/deploy", gz(sc.checkAuth(auth.RoleAdmin, restDeployAppTemplate))},
+		rest.Route{"POST", "/templates/deploy/status", gz(sc.checkAuth(auth.RoleViewer, restDeployAppTemplateStatus))},
+		rest.Route{"GET", "/templates/deploy/active", gz(sc.checkAuth(auth.RoleViewer, restDeployAppTemplateActive))},
 
 		// Login
 		rest.Route{"POST", "/login", gz(sc.noAuth(restLogin))},
 		rest.Route{"DELETE", "/login", gz(restLogout)},
+		rest.Route{"GET", "/oidc/login", sc.noAuth(restOIDCLogin)},
+		rest.Route{"GET", "/oidc/callback", sc.noAuth(restOIDCCallback)},
+		rest.Route{"GET", "/oidc/logout", sc.noAuth(restOIDCLogout)},
 
 		// "Misc" stuff
-		rest.Route{"GET", "/top/services", gz(sc.checkAuth(restGetTopServices))},
-		rest.Route{"GET", "/config", gz(sc.authorizedClient(restGetUIConfig))},
-		rest.Route{"GET", "/servicestatus", gz(sc.checkAuth(restGetConciseServiceStatus))},
+		rest.Route{"GET", "/top/services", gz(sc.checkAuth(auth.RoleViewer, restGetTopServices))},
+		rest.Route{"GET", "/config", gz(sc.authorizedClient(auth.RoleViewer, restGetUIConfig))},
+		rest.Route{"GET", "/servicestatus", gz(sc.checkAuth(auth.RoleViewer, restGetConciseServiceStatus))},
 
 		// Generic static data
 		rest.Route{"GET", "/favicon.ico", gz(favIcon)},
-		rest.Route{"GET", "/static/globals.js", sc.noAuth(restGetAuth0Config)},
+		rest.Route{"GET", "/static/globals.js", sc.noAuth(restGetOIDCConfig)},
 		rest.Route{"GET", "/static/*resource", gz(staticData)},
 		rest.Route{"GET", "/licenses.html", gz(licenses)},
 
 		// Info about serviced itself
-		rest.Route{"GET", "/dockerIsLoggedIn", gz(sc.authorizedClient(restDockerIsLoggedIn))},
+		rest.Route{"GET", "/dockerIsLoggedIn", gz(sc.authorizedClient(auth.RoleViewer, restDockerIsLoggedIn))},
 		rest.Route{"GET", "/stats", gz(sc.isCollectingStats())},
 		rest.Route{"GET", "/version", gz(restGetServicedVersion)},
-		rest.Route{"GET", "/storage", gz(sc.authorizedClient(restGetStorage))},
+		rest.Route{"GET", "/storage", gz(sc.authorizedClient(auth.RoleViewer, restGetStorage))},
 
 		// V2 API
-		rest.Route{"GET", "/api/v2/pools", gz(sc.checkAuth(getPools))},
-		rest.Route{"GET", "/api/v2/pools/:poolId/hosts", gz(sc.checkAuth(getHostsForPool))},
-		rest.Route{"GET", "/api/v2/hosts", gz(sc.checkAuth(getHosts))},
-		rest.Route{"GET", "/api/v2/hosts/:hostId/instances", gz(sc.checkAuth(restGetHostInstances))},
-		rest.Route{"GET", "/api/v2/internalservices", gz(sc.checkAuth(getAllInternalServices))},
-		rest.Route{"GET", "/api/v2/internalservices/:id", gz(sc.checkAuth(getInternalService))},
-		rest.Route{"GET", "/api/v2/internalservices/:id/instances", gz(sc.checkAuth(getInternalServiceInstances))},
-		rest.Route{"GET", "/api/v2/internalservicestatuses", gz(sc.checkAuth(getInternalServiceStatuses))},
-		rest.Route{"GET", "/api/v2/services", gz(sc.checkAuth(getAllServiceDetails))},
-		rest.Route{"GET", "/api/v2/services/:serviceId", gz(sc.checkAuth(getServiceDetails))},
-		rest.Route{"PUT", "/api/v2/services/:serviceId", gz(sc.checkAuth(putServiceDetails))},
-		rest.Route{"GET", "/api/v2/services/:serviceId/services", gz(sc.checkAuth(getChildServiceDetails))},
-		rest.Route{"GET", "/api/v2/services/:serviceId/instances", gz(sc.checkAuth(restGetServiceInstances))},
-		rest.Route{"GET", "/api/v2/services/:serviceId/monitoringprofile", gz(sc.checkAuth(restGetServiceMonitoringProfile))},
-		rest.Route{"GET", "/api/v2/services/:serviceId/publicendpoints", gz(sc.checkAuth(restGetServicePublicEndpoints))},
-		rest.Route{"GET", "/api/v2/services/:serviceId/ipassignments", gz(sc.checkAuth(restGetServiceIPAssignments))},
-		rest.Route{"GET", "/api/v2/services/:serviceId/exportendpoints", gz(sc.checkAuth(restGetServiceExportedEndpoints))},
-		rest.Route{"GET", "/api/v2/services/:serviceId/descendantstates", gz(sc.checkAuth(restCountDescendantStates))},
-		rest.Route{"GET", "/api/v2/services/:serviceId/context", gz(sc.checkAuth(getServiceContext))},
-		rest.Route{"PUT", "/api/v2/services/:serviceId/context", gz(sc.checkAuth(putServiceContext))},
-		rest.Route{"GET", "/api/v2/statuses", gz(sc.checkAuth(restGetAggregateServices))},
-		rest.Route{"GET", "/api/v2/hoststatuses", gz(sc.checkAuth(getHostStatuses))},
-
-		rest.Route{"GET", "/api/v2/services/:serviceId/serviceconfigs", gz(sc.checkAuth(restGetServiceConfigFiles))},
-		rest.Route{"POST", "/api/v2/services/:serviceId/serviceconfigs", gz(sc.checkAuth(restAddServiceConfigFile))},
-		rest.Route{"GET", "/api/v2/serviceconfigs/:fileId", gz(sc.checkAuth(restGetServiceConfigFile))},
-		rest.Route{"PUT", "/api/v2/serviceconfigs/:fileId", gz(sc.checkAuth(restUpdateServiceConfigFile))},
-		rest.Route{"DELETE", "/api/v2/serviceconfigs/:fileId", gz(sc.checkAuth(restDeleteServiceConfigFile))},
+		rest.Route{"GET", "/api/v2/pools", gz(sc.checkAuth(auth.RoleViewer, getPools))},
+		rest.Route{"GET", "/api/v2/pools/:poolId/hosts", gz(sc.checkAuth(auth.RoleViewer, getHostsForPool))},
+		rest.Route{"GET", "/api/v2/hosts", gz(sc.checkAuth(auth.RoleViewer, getHosts))},
+		rest.Route{"GET", "/api/v2/hosts/:hostId/instances", gz(sc.checkAuth(auth.RoleViewer, restGetHostInstances))},
+		rest.Route{"GET", "/api/v2/internalservices", gz(sc.checkAuth(auth.RoleViewer, getAllInternalServices))},
+		rest.Route{"GET", "/api/v2/internalservices/:id", gz(sc.checkAuth(auth.RoleViewer, getInternalService))},
+		rest.Route{"GET", "/api/v2/internalservices/:id/instances", gz(sc.checkAuth(auth.RoleViewer, getInternalServiceInstances))},
+		rest.Route{"GET", "/api/v2/internalservicestatuses", gz(sc.checkAuth(auth.RoleViewer, getInternalServiceStatuses))},
+		rest.Route{"GET", "/api/v2/services", gz(sc.checkAuth(auth.RoleViewer, getAllServiceDetails))},
+		rest.Route{"GET", "/api/v2/services/:serviceId", gz(sc.checkAuth(auth.RoleViewer, getServiceDetails))},
+		rest.Route{"PUT", "/api/v2/services/:serviceId", gz(sc.checkAuth(auth.RoleAdmin, putServiceDetails))},
+		rest.Route{"GET", "/api/v2/services/:serviceId/services", gz(sc.checkAuth(auth.RoleViewer, getChildServiceDetails))},
+		rest.Route{"GET", "/api/v2/services/:serviceId/instances", gz(sc.checkAuth(auth.RoleViewer, restGetServiceInstances))},
+		rest.Route{"GET", "/api/v2/services/:serviceId/monitoringprofile", gz(sc.checkAuth(auth.RoleViewer, restGetServiceMonitoringProfile))},
+		rest.Route{"GET", "/api/v2/services/:serviceId/publicendpoints", gz(sc.checkAuth(auth.RoleViewer, restGetServicePublicEndpoints))},
+		rest.Route{"GET", "/api/v2/services/:serviceId/ipassignments", gz(sc.checkAuth(auth.RoleViewer, restGetServiceIPAssignments))},
+		rest.Route{"GET", "/api/v2/services/:serviceId/exportendpoints", gz(sc.checkAuth(auth.RoleViewer, restGetServiceExportedEndpoints))},
+		rest.Route{"GET", "/api/v2/services/:serviceId/descendantstates", gz(sc.checkAuth(auth.RoleViewer, restCountDescendantStates))},
+		rest.Route{"GET", "/api/v2/services/:serviceId/context", gz(sc.checkAuth(auth.RoleViewer, getServiceContext))},
+		rest.Route{"PUT", "/api/v2/services/:serviceId/context", gz(sc.checkAuth(auth.RoleAdmin, putServiceContext))},
+		rest.Route{"GET", "/api/v2/statuses", gz(sc.checkAuth(auth.RoleViewer, restGetAggregateServices))},
+		rest.Route{"GET", "/api/v2/hoststatuses", gz(sc.checkAuth(auth.RoleViewer, getHostStatuses))},
+
+		rest.Route{"GET", "/api/v2/services/:serviceId/serviceconfigs", gz(sc.checkAuth(auth.RoleViewer, restGetServiceConfigFiles))},
+		rest.Route{"POST", "/api/v2/services/:serviceId/serviceconfigs", gz(sc.checkAuth(auth.RoleAdmin, restAddServiceConfigFile))},
+		rest.Route{"GET", "/api/v2/serviceconfigs/:fileId", gz(sc.checkAuth(auth.RoleViewer, restGetServiceConfigFile))},
+		rest.Route{"PUT", "/api/v2/serviceconfigs/:fileId", gz(sc.checkAuth(auth.RoleAdmin, restUpdateServiceConfigFile))},
+		rest.Route{"DELETE", "/api/v2/serviceconfigs/:fileId", gz(sc.checkAuth(auth.RoleAdmin, restDeleteServiceConfigFile))},
+
+		rest.Route{"GET", "/api/v2/snapshotpolicies", gz(sc.checkAuth(auth.RoleViewer, getSnapshotPolicies))},
+		rest.Route{"PUT", "/api/v2/services/:serviceId/snapshotpolicy", gz(sc.checkAuth(auth.RoleAdmin, putSnapshotPolicy))},
+		rest.Route{"DELETE", "/api/v2/services/:serviceId/snapshotpolicy", gz(sc.checkAuth(auth.RoleAdmin, deleteSnapshotPolicy))},
 	}
 
 	// Hardcoding these target URLs for now.
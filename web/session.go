@@ -34,13 +34,14 @@ import (
 
 const sessionCookie = "ZCPToken"
 const usernameCookie = "ZUsername"
-const auth0TokenCookie = "auth0AccessToken"
+const oidcTokenCookie = "ZOIDCToken"
 
 var adminGroup = "sudo"
 
 type sessionT struct {
 	ID       string
 	User     string
+	grants   auth.Grants
 	creation time.Time
 	access   time.Time
 }
@@ -103,134 +104,150 @@ func purgeOldsessionTs() {
 /*
  * This function should be called by any secure REST resource
  */
-func loginWithBasicAuthOK(r *rest.Request) bool {
+func loginWithBasicAuthOK(r *rest.Request) (auth.Grants, bool) {
 	cookie, err := r.Request.Cookie(sessionCookie)
 	if err != nil {
 		glog.V(1).Info("Error getting cookie ", err)
-		return false
+		return nil, false
 	}
 	sessionsLock.Lock()
 	defer sessionsLock.Unlock()
 	value, err := url.QueryUnescape(strings.Replace(cookie.Value, "+", url.QueryEscape("+"), -1))
 	if err != nil {
 		glog.Warning("Unable to decode session ", cookie.Value)
-		return false
+		return nil, false
 	}
 	session, err := findsessionT(value)
 	if err != nil {
 		glog.Info("Unable to find session ", value)
-		return false
+		return nil, false
 	}
 	session.access = time.Now()
 	glog.V(2).Infof("sessionT %s used", session.ID)
-	return true
+	return session.grants, true
 }
 
-func loginWithTokenOK(r *rest.Request, token string) bool {
+func loginWithTokenOK(r *rest.Request, token string) (auth.Grants, bool) {
 	restToken, err := auth.ParseRestToken(token)
 	if err != nil {
 		msg := "Unable to parse rest token"
 		plog.WithError(err).WithField("url", r.URL.String()).Debug(msg)
-		return false
+		return nil, false
 	} else {
 		if !restToken.ValidateRequestHash(r.Request) {
 			msg := "Could not login with rest token. Request signature does not match token."
 			plog.WithField("url", r.URL.String()).Debug(msg)
-			return false
-		} else if !restToken.HasAdminAccess() {
+			return nil, false
+		} else if restToken.Role() == auth.RoleNone {
 			msg := "Could not login with rest token. Insufficient permissions."
 			plog.WithField("url", r.URL.String()).Debug(msg)
-			return false
+			return nil, false
 		} else {
-			return true
+			return auth.Grants{{Role: restToken.Role()}}, true
 		}
 	}
 }
 
-func loginWithAuth0TokenOK(r *rest.Request, token string) (auth.Auth0Token, bool) {
-	auth0Token, err := auth.ParseAuth0Token(token)
+func loginWithOIDCTokenOK(r *rest.Request, token string) (auth.OIDCToken, bool) {
+	provider := auth.GetOIDCProvider()
+	if provider == nil {
+		return nil, false
+	}
+	oidcToken, err := provider.ParseToken(token)
 	if err != nil {
-		msg := "Unable to parse auth0 rest token"
+		msg := "Unable to parse OIDC token"
 		plog.WithError(err).WithField("url", r.URL.String()).Debug(msg)
 		return nil, false
 	} else {
-		if !auth0Token.HasAdminAccess() {
-			msg := "Could not login with auth0 rest token. Insufficient permissions."
+		if len(oidcToken.Grants()) == 0 {
+			msg := "Could not login with OIDC token. Insufficient permissions."
 			plog.WithField("url", r.URL.String()).Debug(msg)
 			return nil, false
 		} else {
-			return auth0Token, true
+			return oidcToken, true
 		}
 	}
 }
 
-func loginWithAuth0CookieOk(r *rest.Request) bool {
-	cookie, err := r.Request.Cookie(auth0TokenCookie)
+func loginWithOIDCCookieOk(r *rest.Request) (auth.Grants, bool) {
+	cookie, err := r.Request.Cookie(oidcTokenCookie)
 	if err != nil {
 		glog.V(1).Info("Error getting cookie ", err)
-		return false
+		return nil, false
 	}
 	token := cookie.Value
-	_, result := loginWithAuth0TokenOK(r, token)
-	return result
+	if parsed, ok := loginWithOIDCTokenOK(r, token); ok {
+		return parsed.Grants(), true
+	}
+	return nil, false
 }
 
 func loginOK(w *rest.ResponseWriter, r *rest.Request) bool {
+	_, ok := loginGrants(w, r)
+	return ok
+}
+
+// loginGrants returns the roles of the logged in user or token
+func loginGrants(w *rest.ResponseWriter, r *rest.Request) (auth.Grants, bool) {
 	token, tErr := auth.ExtractRestToken(r.Request)
 	if tErr != nil { // There is a token in the header but we could not extract it
 		msg := "Unable to extract auth token from header"
 		plog.WithError(tErr).WithField("url", r.URL.String()).Debug(msg)
-		return false
+		return nil, false
 	}
-	if auth.Auth0IsConfigured() {
-		if auth0LoginOK(w, r, token) {
-			return true
+	if auth.OIDCIsConfigured() {
+		if grants, ok := oidcLoginOK(w, r, token); ok {
+			return grants, true
 		}
-		// CC-4109: even with auth0 configured, we still need token authentication for REST calls.
+		// CC-4109: even with OIDC configured, we still need token authentication for REST calls.
 		return loginWithTokenOK(r, token)
 	}
 	return basicAuthLoginOK(w, r, token)
 }
 
-func auth0LoginOK(w *rest.ResponseWriter, r *rest.Request, token string) bool {
+func oidcLoginOK(w *rest.ResponseWriter, r *rest.Request, token string) (auth.Grants, bool) {
 	if token != "null" && token != "" {
-		if parsed, ok := loginWithAuth0TokenOK(r, token); ok {
+		if parsed, ok := loginWithOIDCTokenOK(r, token); ok {
 			// Set cookie with token, so api calls can work.
-			// Secure and HttpOnly flags are important to mitigate CSRF/XSRF attack risk.
-			exp := parsed.Expiration()
-			expireTime := time.Unix(exp, 0)
-
-			http.SetCookie(
-				w.ResponseWriter,
-				&http.Cookie{
-					Name:     auth0TokenCookie,
-					Value:    token,
-					Path:     "/",
-					Expires:  expireTime,
-					Secure:   true,
-					HttpOnly: true,
-				})
-
-			// not setting secure, httponly on name cookie - this should be for display only
-			http.SetCookie(
-				w.ResponseWriter,
-				&http.Cookie{
-					Name:     usernameCookie,
-					Value:    parsed.User(),
-					Path:     "/",
-					Expires:  expireTime,
-					Secure:   false,
-					HttpOnly: false,
-				})
-			return true
+			setOIDCCookies(w.ResponseWriter, token, parsed)
+			return parsed.Grants(), true
 		}
-		return false
+		return nil, false
 	} else {
-		return loginWithAuth0CookieOk(r)
+		return loginWithOIDCCookieOk(r)
 	}
 }
 
-func basicAuthLoginOK(w *rest.ResponseWriter, r *rest.Request, token string) bool {
+func setOIDCCookies(w http.ResponseWriter, token string, parsed auth.OIDCToken) {
+	// Secure and HttpOnly flags are important to mitigate CSRF/XSRF attack risk.
+	exp := parsed.Expiration()
+	expireTime := time.Unix(exp, 0)
+
+	http.SetCookie(
+		w,
+		&http.Cookie{
+			Name:     oidcTokenCookie,
+			Value:    token,
+			Path:     "/",
+			Expires:  expireTime,
+			Secure:   true,
+			HttpOnly: true,
+		})
+
+	// not setting secure, httponly on name cookie - this should be for display only
+	http.SetCookie(
+		w,
+		&http.Cookie{
+			Name:     usernameCookie,
+			Value:    parsed.User(),
+			Path:     "/",
+			Expires:  expireTime,
+			Secure:   false,
+			HttpOnly: false,
+		})
+}
+
+func basicAuthLoginOK(w *rest.ResponseWriter, r *rest.Request, token string) (auth.Grants, bool) {
 	if token != "null" && token != "" {
 		return loginWithTokenOK(r, token)
 	} else {
@@ -253,7 +270,7 @@ func restLogout(w *rest.ResponseWriter, r *rest.Request) {
 	}
 
 	// Blank out all login cookies
-	writeBlankCookie(w, r, auth0TokenCookie)
+	writeBlankCookie(w, r, oidcTokenCookie)
 	writeBlankCookie(w, r, sessionCookie)
 	writeBlankCookie(w, r, usernameCookie)
 	w.WriteJson(&simpleResponse{"Logged out", loginLink()})
@@ -290,11 +307,11 @@ func restLoginWithBasicAuth(w *rest.ResponseWriter, r *rest.Request, ctx *reques
 		return
 	}
 
-	if validateLogin(&creds, client) {
+	if grants, ok := validateLogin(&creds, client); ok {
 		sessionsLock.Lock()
 		defer sessionsLock.Unlock()
 
-		session, err := createsessionT(creds.Username)
+		session, err := createsessionT(creds.Username, grants)
 		if err != nil {
 			writeJSON(w, &simpleResponse{"sessionT could not be created", loginLink()}, http.StatusInternalServerError)
 			return
@@ -335,10 +352,10 @@ func restLogin(w *rest.ResponseWriter, r *rest.Request, ctx *requestContext) {
 		plog.WithError(tErr).Warning(msg)
 		writeJSON(w, &simpleResponse{msg, loginLink()}, http.StatusUnauthorized)
 	} else if token != "" {
-		if _, ok := loginWithAuth0TokenOK(r, token); ok {
+		if _, ok := loginWithOIDCTokenOK(r, token); ok {
 			w.WriteJson(&simpleResponse{"Accepted", homeLink()})
 			return
-		} else if loginWithTokenOK(r, token) {
+		} else if _, ok := loginWithTokenOK(r, token); ok {
 			w.WriteJson(&simpleResponse{"Accepted", homeLink()})
 			return
 		}
@@ -348,16 +365,19 @@ func restLogin(w *rest.ResponseWriter, r *rest.Request, ctx *requestContext) {
 	}
 }
 
-func validateLogin(creds *login, client master.ClientInterface) bool {
+// validateLogin checks the credentials and returns the roles of the user.  The
+// system user and members of the admin group are admins; other users get the
+// roles of their groups in the role map.
+func validateLogin(creds *login, client master.ClientInterface) (auth.Grants, bool) {
 	glog.V(1).Info("validateLogin()")
 	systemUser, err := client.GetSystemUser()
 	if err == nil && creds.Username == systemUser.Name {
 		validated := cpValidateLogin(creds, client)
 		if validated {
-			return validated
+			return auth.Grants{{Role: auth.RoleAdmin}}, true
 		}
 	}
-	return pamValidateLogin(creds, adminGroup)
+	return pamLoginGrants(creds, adminGroup, auth.GetRoleMap())
 }
 
 func cpValidateLogin(creds *login, client master.ClientInterface) bool {
@@ -376,12 +396,12 @@ func cpValidateLogin(creds *login, client master.ClientInterface) bool {
 	return result
 }
 
-func createsessionT(user string) (*sessionT, error) {
+func createsessionT(user string, grants auth.Grants) (*sessionT, error) {
 	sid, err := randomsessionTId()
 	if err != nil {
 		return nil, err
 	}
-	return &sessionT{sid, user, time.Now(), time.Now()}, nil
+	return &sessionT{sid, user, grants, time.Now(), time.Now()}, nil
 }
 
 func findsessionT(sid string) (*sessionT, error) {
@@ -199,6 +199,15 @@ func restGetConciseServiceStatus(w *rest.ResponseWriter, r *rest.Request, ctx *r
 			glog.V(2).Infof("Error retrieving service statuses: (%s)", err)
 			return nil, err
 		}
+		if !ctx.viewsAll() {
+			viewable := []*ConciseServiceStatus{}
+			for _, stat := range statuses {
+				if ctx.viewsService(stat.ServiceID, stat.ParentServiceID, stat.PoolID) {
+					viewable = append(viewable, stat)
+				}
+			}
+			statuses = viewable
+		}
 		bytes, err := json.Marshal(statuses)
 		if err != nil {
 			glog.V(2).Infof("Error serializing service statuses: (%s)", err)
@@ -207,7 +216,14 @@ func restGetConciseServiceStatus(w *rest.ResponseWriter, r *rest.Request, ctx *r
 		return bytes, nil
 	}
 	w.Header().Set("content-type", "application/json")
-	bytes, err := getCached(f)
+	var bytes []byte
+	var err error
+	if ctx.viewsAll() {
+		bytes, err = getCached(f)
+	} else {
+		// the cache is shared by all users, so filtered statuses are not cached
+		bytes, err = f()
+	}
 	if err != nil {
 		glog.Errorf("Error retrieving service statuses: %s", err)
 		restServerError(w, err)
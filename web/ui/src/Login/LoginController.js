@@ -11,9 +11,9 @@
             $notification.create("", $translate.instant("compatibility_mode"), $("#loginNotifications")).warning(false);
         }
 
-        if (utils.useAuth0()) {
+        if (utils.useOIDC()) {
             disableLoginButton();
-            authService.auth0login();
+            authService.oidcLogin();
         }
 
         enableLoginButton();
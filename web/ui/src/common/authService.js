@@ -4,9 +4,9 @@
 (function(){
     "use strict";
 
-    angular.module("authService", ["auth0.auth0"])
-    .factory("authService", ["angularAuth0", "$cookies", "$cookieStore", "$location", "$http", "$notification", "miscUtils", "log",
-    function(angularAuth0, $cookies, $cookieStore, $location, $http, $notification, utils, log) {
+    angular.module("authService", [])
+    .factory("authService", ["$cookies", "$cookieStore", "$location", "$http", "$notification", "miscUtils", "log",
+    function($cookies, $cookieStore, $location, $http, $notification, utils, log) {
         var loggedIn = false;
         var userName = null;
 
@@ -24,8 +24,8 @@
              */
             setLoggedIn: setLoggedIn,
 
-            auth0login: function () {
-                angularAuth0.authorize();
+            oidcLogin: function () {
+                window.location = "/oidc/login";
             },
 
             login: function(creds, successCallback, failCallback){
@@ -47,14 +47,10 @@
             logout: function(){
                 $http.delete('/login').
                     success(function(data, status) {
-                        window.sessionStorage.removeItem("auth0AccessToken");
-                        window.sessionStorage.removeItem("auth0IDToken");
                         let redirectloc = '/';
-                        if (utils.useAuth0()) {
-                            let returnloc = encodeURIComponent(window.location.origin + '/');
-                            redirectloc = 'https://' + window.Auth0Config.Auth0Domain + '/v2/logout' +
-                                '?returnTo=' + returnloc +
-                                '&client_id=' + window.Auth0Config.Auth0ClientID;
+                        if (utils.useOIDC()) {
+                            // end the session at the OIDC provider too
+                            redirectloc = '/oidc/logout';
                         }
                         // On successful logout, redirect to /
                         window.location = redirectloc;
@@ -72,13 +68,13 @@
              * @param {object} scope The 'loggedIn' property will be set if true
              */
             checkLogin: function($scope) {
-                if (utils.useAuth0()) {
-                    var at = window.sessionStorage.getItem("auth0AccessToken");
-                    var it = window.sessionStorage.getItem("auth0IDToken");
-                    if (at && it) {
+                if (utils.useOIDC()) {
+                    // the token cookie is not visible to scripts, but the
+                    // user name cookie expires with it
+                    if ($cookies.get("ZUsername")) {
                         $scope.loggedIn = true;
                         $scope.user = {
-                            username: "successful auth0 login"
+                            username: $cookies.get("ZUsername")
                         };
                         return;
                     }
@@ -95,21 +91,5 @@
                 utils.unauthorized($location);
             }
         };
-    }]).config(config);
-
-    config.$inject = [
-        'angularAuth0Provider'
-    ];
-
-    function config(angularAuth0Provider) {
-        // Initialization for the angular-auth0 library
-        angularAuth0Provider.init({
-            domain: window.Auth0Config.Auth0Domain,
-            clientID: window.Auth0Config.Auth0ClientID,
-            redirectUri: window.location.origin + "/static/auth0callback.html",
-            audience: window.Auth0Config.Auth0Audience,
-            responseType: "token id_token",
-            scope: window.Auth0Config.Auth0Scope,
-        });
-    }
+    }]);
 })();
@@ -501,9 +501,6 @@
                 requestObj = {
                     method: method,
                     url: url,
-                    headers: {
-                        'Authorization': "Bearer " + window.sessionStorage.getItem("auth0AccessToken")
-                    },
                     data: payload
                 };
 
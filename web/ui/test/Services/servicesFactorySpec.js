@@ -17,7 +17,6 @@ describe('servicesFactory', function() {
         module(resourcesFactoryMock);
         module(instancesFactoryMock);
         module(translateMock);
-        module(angularAuth0Mock);
     });
 
     var resourcesFactory, scope, serviceHealth, servicesFactory, hcStatus, instancesFactory;
@@ -253,7 +252,6 @@ describe('servicesFactory Service object', function() {
         module(resourcesFactoryMock);
         module(instancesFactoryMock);
         module(translateMock);
-        module(angularAuth0Mock);
     });
 
     var resourcesFactory, scope, serviceHealth, servicesFactory, hcStatus;
@@ -6,7 +6,7 @@ var authServiceMock = function($provide) {
     $provide.factory('authService', function() {
         var mock = jasmine.createSpyObj('authService', [
             'setLoggedIn',
-            'auth0Login',
+            'oidcLogin',
             'login',
             'logout',
             'checkLogin'
@@ -20,7 +20,7 @@ var miscUtilsMock = function($provide) {
             'isIpAddress',
             'needsHostAlias',
             'parseEngineeringNotation',
-            'useAuth0',
+            'useOIDC',
             'validateRAMLimit',
             'validatePortNumber'
         ]);
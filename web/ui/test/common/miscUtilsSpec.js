@@ -8,7 +8,6 @@ describe('miscUtils', function() {
     beforeEach(module('controlplaneTest'));
     beforeEach(module('miscUtils'));
     beforeEach(module(logMock));
-    beforeEach(module(angularAuth0Mock));
 
     beforeEach(inject(function($injector) {
         $scope = $injector.get('$rootScope').$new();
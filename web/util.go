@@ -64,6 +64,14 @@ func restUnauthorized(w *rest.ResponseWriter) {
 	return
 }
 
+/*
+ * Inform the user that they are logged in without the role they need
+ */
+func restForbidden(w *rest.ResponseWriter) {
+	writeJSON(w, &simpleResponse{"Insufficient permissions", homeLink()}, http.StatusForbidden)
+	return
+}
+
 /*
  * Provide a generic response for an oopsie.
  */
@@ -113,6 +113,9 @@ func GetRemoteConnection(useTLS bool, export *registry.ExportDetails) (remote ne
 	return getRemoteConnection(export, dialer)
 }
 
+// muxResultTimeout is how long to wait for the mux to answer a mux header
+const muxResultTimeout = 30 * time.Second
+
 func getRemoteConnection(export *registry.ExportDetails, dialer dialerInterface) (net.Conn, error) {
 	// If the exported endpoint is on this Host, we don't go through the mux.
 	if IsLocalAddress(export.HostIP) {
@@ -149,11 +152,21 @@ func getRemoteConnection(export *registry.ExportDetails, dialer dialerInterface)
 		return nil, err
 	}
 
-	if err := auth.AddSignedMuxHeader(remote, muxAddr, token); err != nil {
+	if err := auth.AddSignedMuxHeader(remote, muxAddr, export.TenantID, token); err != nil {
 		plog.WithError(err).Error("Unable to send authenticated mux header")
 		return nil, err
 	}
 
+	// Wait for the mux to connect to the service before handing over the
+	// connection.
+	remote.SetReadDeadline(time.Now().Add(muxResultTimeout))
+	if err := auth.ReadMuxResult(remote); err != nil {
+		plog.WithError(err).Error("Mux did not establish the connection")
+		remote.Close()
+		return nil, err
+	}
+	remote.SetReadDeadline(time.Time{})
+
 	return remote, nil
 }
 
@@ -24,6 +24,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/control-center/serviced/auth"
 	"github.com/control-center/serviced/datastore"
 	datastoreMocks "github.com/control-center/serviced/datastore/mocks"
 	facadeMocks "github.com/control-center/serviced/facade/mocks"
@@ -52,6 +53,7 @@ func (s *TestWebSuite) SetUpTest(c *C) {
 	s.mockFacade = &facadeMocks.FacadeInterface{}
 	config := ServiceConfig{facade: s.mockFacade}
 	s.ctx = newRequestContext(&config)
+	s.ctx.grants = auth.Grants{{Role: auth.RoleAdmin}}
 
 	s.recorder = httptest.NewRecorder()
 	s.writer = rest.NewResponseWriter(s.recorder, false)
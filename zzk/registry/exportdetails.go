@@ -19,6 +19,7 @@ import (
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/control-center/serviced/coordinator/client"
+	"github.com/control-center/serviced/health"
 	"github.com/control-center/serviced/zzk/service"
 )
 
@@ -26,11 +27,18 @@ import (
 // presented on the coordinator.
 type ExportDetails struct {
 	service.ExportBinding
+	TenantID   string
 	PrivateIP  string
 	HostIP     string
 	MuxPort    uint16
 	InstanceID int
-	version    interface{}
+
+	// HealthStatus is the latest result of each of the instance's health
+	// checks, so that importers can steer traffic away from unhealthy
+	// instances.
+	HealthStatus map[string]health.Status
+
+	version interface{}
 }
 
 // Version implements client.Node
@@ -43,14 +51,16 @@ func (node *ExportDetails) SetVersion(version interface{}) {
 	node.version = version
 }
 
-// RegisterExport exposes an exported endpoint
-func RegisterExport(shutdown <-chan struct{}, conn client.Connection, tenantID string, export ExportDetails) {
+// RegisterExport exposes an exported endpoint and publishes the health status
+// of the instance as it changes.
+func RegisterExport(shutdown <-chan struct{}, conn client.Connection, tenantID string, export ExportDetails, healthStatus <-chan map[string]health.Status) {
 	logger := plog.WithFields(log.Fields{
 		"TenantID":    tenantID,
 		"Application": export.Application,
 		"InstanceID":  export.InstanceID,
 	})
 
+	export.TenantID = tenantID
 	basepth := path.Join("/net/export", tenantID, export.Application, fmt.Sprintf("%s-%s-%d", tenantID, export.Application, export.InstanceID))
 	pth := basepth
 	defer func() {
@@ -88,6 +98,12 @@ func RegisterExport(shutdown <-chan struct{}, conn client.Connection, tenantID s
 
 		select {
 		case <-ev:
+		case export.HealthStatus = <-healthStatus:
+			if err := setExport(conn, pth, &export); err != nil {
+				epLogger.WithError(err).Warn("Could not update the health status of endpoint")
+			} else {
+				epLogger.Debug("Updated the health status of endpoint")
+			}
 		case <-shutdown:
 			epLogger.Debug("Listener shutting down")
 			return
@@ -98,14 +114,24 @@ func RegisterExport(shutdown <-chan struct{}, conn client.Connection, tenantID s
 	}
 }
 
+// setExport updates the data of a registered export
+func setExport(conn client.Connection, pth string, export *ExportDetails) error {
+	current := &ExportDetails{}
+	if err := conn.Get(pth, current); err != nil {
+		return err
+	}
+	export.SetVersion(current.Version())
+	return conn.Set(pth, export)
+}
+
 // TrackExports keeps track of changes to the list of exports for given import
 func TrackExports(shutdown <-chan struct{}, conn client.Connection, tenantID, application string) <-chan []ExportDetails {
 	exportsChan := make(chan []ExportDetails)
 	go func() {
 		defer close(exportsChan)
 
-		// lets keep track of the binds that we have already looked up
-		exportMap := make(map[string]ExportDetails)
+		// lets keep track of the binds that we have already seen
+		exportMap := make(map[string]struct{})
 
 		// get the path to the export
 		pth := path.Join("/net/export", tenantID, application)
@@ -145,29 +171,42 @@ func TrackExports(shutdown <-chan struct{}, conn client.Connection, tenantID, ap
 				}
 			}
 
-			// get the data and make sure it is in sync
+			// get the data and watch it for changes, so that updates to the
+			// health of an export are picked up along with changes to the
+			// list of exports.
 			exports := []ExportDetails{}
-			chMap := make(map[string]ExportDetails)
+			chMap := make(map[string]struct{})
+			changed := make(chan struct{}, 1)
 			for _, name := range ch {
-				export, ok := exportMap[name]
-
-				if !ok {
-					err := conn.Get(path.Join(pth, name), &export)
-					if err == client.ErrNoNode {
-						continue
-					} else if err != nil {
-						logger.WithFields(log.Fields{
-							"zkpth": path.Join(pth, name),
-						}).WithError(err).Error("Could not look up export binding")
-						return
-					}
+				var export ExportDetails
+				dataEv, err := conn.GetW(path.Join(pth, name), &export, done)
+				if err == client.ErrNoNode {
+					continue
+				} else if err != nil {
+					logger.WithFields(log.Fields{
+						"zkpth": path.Join(pth, name),
+					}).WithError(err).Error("Could not look up export binding")
+					return
+				}
+				export.TenantID = tenantID
+				if _, ok := exportMap[name]; !ok {
 					logger.WithFields(log.Fields{
 						"Name": name,
 					}).Debug("New record added")
 				}
+				go func(dataEv <-chan client.Event, done <-chan struct{}) {
+					select {
+					case <-dataEv:
+						select {
+						case changed <- struct{}{}:
+						default:
+						}
+					case <-done:
+					}
+				}(dataEv, done)
 
 				exports = append(exports, export)
-				chMap[name] = export
+				chMap[name] = struct{}{}
 			}
 			exportMap = chMap
 
@@ -177,11 +216,14 @@ func TrackExports(shutdown <-chan struct{}, conn client.Connection, tenantID, ap
 				// exports received, wait for event
 				select {
 				case <-ev:
+				case <-changed:
 				case <-shutdown:
 					return
 				}
 			case <-ev:
 				logger.Debug("Exports updated, getting latest")
+			case <-changed:
+				logger.Debug("Export data updated, getting latest")
 			case <-shutdown:
 				return
 			}
@@ -193,3 +235,43 @@ func TrackExports(shutdown <-chan struct{}, conn client.Connection, tenantID, ap
 
 	return exportsChan
 }
+
+// GetExports returns all of the exports registered for a tenant
+func GetExports(conn client.Connection, tenantID string) ([]ExportDetails, error) {
+	pth := path.Join("/net/export", tenantID)
+	logger := plog.WithFields(log.Fields{
+		"tenantid": tenantID,
+		"zkpath":   pth,
+	})
+
+	apps, err := conn.Children(pth)
+	if err == client.ErrNoNode {
+		return []ExportDetails{}, nil
+	} else if err != nil {
+		logger.WithError(err).Debug("Could not look up applications for tenant")
+		return nil, err
+	}
+
+	exports := []ExportDetails{}
+	for _, app := range apps {
+		ch, err := conn.Children(path.Join(pth, app))
+		if err == client.ErrNoNode {
+			continue
+		} else if err != nil {
+			logger.WithField("application", app).WithError(err).Debug("Could not look up exports for application")
+			return nil, err
+		}
+		for _, name := range ch {
+			var export ExportDetails
+			if err := conn.Get(path.Join(pth, app, name), &export); err == client.ErrNoNode {
+				continue
+			} else if err != nil {
+				logger.WithField("application", app).WithError(err).Debug("Could not look up export")
+				return nil, err
+			}
+			export.TenantID = tenantID
+			exports = append(exports, export)
+		}
+	}
+	return exports, nil
+}
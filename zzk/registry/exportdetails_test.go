@@ -18,6 +18,7 @@ package registry_test
 import (
 	"time"
 
+	"github.com/control-center/serviced/health"
 	"github.com/control-center/serviced/zzk"
 	. "github.com/control-center/serviced/zzk/registry"
 	"github.com/control-center/serviced/zzk/service"
@@ -42,7 +43,7 @@ func (t *ZZKTest) TestRegisterExport(c *C) {
 		RegisterExport(shutdown, conn, "tenantid", ExportDetails{
 			ExportBinding: service.ExportBinding{Application: "app"},
 			InstanceID:    1,
-		})
+		}, nil)
 		close(done)
 	}()
 
@@ -181,3 +182,91 @@ func (t *ZZKTest) TestTrackExports(c *C) {
 		c.Fatalf("Timed out waiting for exports")
 	}
 }
+
+func (t *ZZKTest) TestTrackExportsHealthStatus(c *C) {
+	// pre-requisites
+	conn, err := zzk.GetLocalConnection("/")
+	c.Assert(err, IsNil)
+
+	// register an export
+	shutdown := make(chan struct{})
+	healthStatus := make(chan map[string]health.Status)
+	done := make(chan struct{})
+	go func() {
+		RegisterExport(shutdown, conn, "tenantid", ExportDetails{
+			ExportBinding: service.ExportBinding{Application: "app"},
+			InstanceID:    0,
+		}, healthStatus)
+		close(done)
+	}()
+
+	ev := TrackExports(shutdown, conn, "tenantid", "app")
+
+	timer := time.NewTimer(time.Second)
+	for waiting := true; waiting; {
+		select {
+		case exports := <-ev:
+			waiting = len(exports) == 0
+		case <-timer.C:
+			close(shutdown)
+			c.Fatalf("Timed out waiting for exports")
+		}
+	}
+
+	// publish the health of the export
+	healthStatus <- map[string]health.Status{"running": health.Failed}
+
+	timer.Reset(time.Second)
+	select {
+	case exports := <-ev:
+		c.Assert(exports, HasLen, 1)
+		c.Check(exports[0].HealthStatus, DeepEquals, map[string]health.Status{"running": health.Failed})
+	case <-timer.C:
+		close(shutdown)
+		c.Fatalf("Timed out waiting for exports")
+	}
+
+	// shutdown
+	close(shutdown)
+	timer.Reset(time.Second)
+	select {
+	case <-done:
+	case <-timer.C:
+		c.Fatalf("Listener timed out")
+	}
+}
+
+func (t *ZZKTest) TestGetExports(c *C) {
+	conn, err := zzk.GetLocalConnection("/")
+	c.Assert(err, IsNil)
+
+	// no exports
+	exports, err := GetExports(conn, "tenantid")
+	c.Assert(err, IsNil)
+	c.Check(exports, HasLen, 0)
+
+	// exports of two applications
+	err = conn.Create("/net/export/tenantid/app1/0", &ExportDetails{
+		ExportBinding: service.ExportBinding{Application: "app1", PortNumber: 1000},
+		PrivateIP:     "172.17.0.2",
+	})
+	c.Assert(err, IsNil)
+	err = conn.Create("/net/export/tenantid/app2/0", &ExportDetails{
+		ExportBinding: service.ExportBinding{Application: "app2", PortNumber: 2000},
+		PrivateIP:     "172.17.0.3",
+	})
+	c.Assert(err, IsNil)
+	err = conn.Create("/net/export/othertenant/app3/0", &ExportDetails{
+		ExportBinding: service.ExportBinding{Application: "app3", PortNumber: 3000},
+		PrivateIP:     "172.17.0.4",
+	})
+	c.Assert(err, IsNil)
+
+	exports, err = GetExports(conn, "tenantid")
+	c.Assert(err, IsNil)
+	c.Assert(exports, HasLen, 2)
+	for _, export := range exports {
+		c.Check(export.TenantID, Equals, "tenantid")
+		c.Check(export.Application, Not(Equals), "app3")
+	}
+}
@@ -177,6 +177,7 @@ func (l *PublicPortListener) Spawn(shutdown <-chan interface{}, portAddr string)
 					exLogger.WithField("exportkey", name).WithError(err).Error("Could not look up export")
 					return
 				}
+				export.TenantID = dat.TenantID
 			}
 			chMap[name] = export
 			exports = append(exports, export)
@@ -176,6 +176,7 @@ func (l *VHostListener) Spawn(shutdown <-chan interface{}, subdomain string) {
 					exLogger.WithField("exportkey", name).WithError(err).Error("Could not look up export")
 					return
 				}
+				export.TenantID = dat.TenantID
 			}
 			chMap[name] = export
 			exports = append(exports, export)
@@ -0,0 +1,156 @@
+// Copyright 2018 The Serviced Authors.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"path"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/control-center/serviced/coordinator/client"
+	"github.com/control-center/serviced/domain/service"
+)
+
+// InstanceEventHandler is told when a service instance changes its state
+type InstanceEventHandler interface {
+	// InstanceStateChanged reports the previous and current state of an
+	// instance.  The previous state is empty for a new instance and the
+	// current state is empty for an instance that was removed.
+	InstanceStateChanged(poolID, serviceID, hostID string, instanceID int, previous, current service.InstanceCurrentState)
+}
+
+// InstanceEventListener is the listener for /services that reports changes
+// to the states of service instances
+type InstanceEventListener struct {
+	conn    client.Connection
+	handler InstanceEventHandler
+	poolid  string
+}
+
+// NewInstanceEventListener instantiates a new InstanceEventListener
+func NewInstanceEventListener(poolid string, handler InstanceEventHandler) *InstanceEventListener {
+	return &InstanceEventListener{poolid: poolid, handler: handler}
+}
+
+// SetConnection implements zzk.Listener
+func (l *InstanceEventListener) SetConnection(conn client.Connection) { l.conn = conn }
+
+// GetPath implements zzk.Listener
+func (l *InstanceEventListener) GetPath(nodes ...string) string {
+	parts := append([]string{"/services"}, nodes...)
+	if l.poolid != "" {
+		parts = append([]string{"/pools", l.poolid}, parts...)
+	}
+	return path.Join(parts...)
+}
+
+// Ready implements zzk.Listener
+func (l *InstanceEventListener) Ready() (err error) { return }
+
+// Done implements zzk.Listener
+func (l *InstanceEventListener) Done() { return }
+
+// PostProcess implements zzk.Listener
+func (l *InstanceEventListener) PostProcess(p map[string]struct{}) {}
+
+// Spawn watches the instances of a service and reports changes to their
+// states.  The states found when the listener starts are not reported.
+func (l *InstanceEventListener) Spawn(shutdown <-chan interface{}, serviceID string) {
+	logger := plog.WithField("serviceid", serviceID)
+
+	// set up cancellable on zookeeper watches
+	done := make(chan struct{})
+	defer func() { close(done) }()
+
+	var states map[string]service.InstanceCurrentState
+	for {
+		stateIDs, ssEvt, err := l.conn.ChildrenW(l.GetPath(serviceID), done)
+		if err == client.ErrNoNode {
+
+			logger.Debug("Service deleted, instance event listener shutting down")
+			return
+		} else if err != nil {
+
+			logger.WithError(err).Error("Could not look up states for service")
+			return
+		}
+
+		// watch the current state of each instance
+		csEvt := make(chan struct{}, 1)
+		current := make(map[string]service.InstanceCurrentState)
+		for _, stateID := range stateIDs {
+			cstate := &CurrentStateContainer{}
+			ev, err := l.conn.GetW(l.GetPath(serviceID, stateID, "current"), cstate, done)
+			if err == client.ErrNoNode {
+				// the instance is being created or removed
+				continue
+			} else if err != nil {
+
+				logger.WithField("stateid", stateID).WithError(err).Error("Could not watch instance status")
+				return
+			}
+			current[stateID] = cstate.Status
+			go func(ev <-chan client.Event, done <-chan struct{}) {
+				select {
+				case <-ev:
+					select {
+					case csEvt <- struct{}{}:
+					default:
+					}
+				case <-done:
+				}
+			}(ev, done)
+		}
+
+		if states != nil {
+			l.report(logger, states, current)
+		}
+		states = current
+
+		select {
+		case <-ssEvt:
+		case <-csEvt:
+		case <-shutdown:
+
+			logger.Debug("Instance event listener received signal to shut down")
+			return
+		}
+
+		close(done)
+		done = make(chan struct{})
+	}
+}
+
+// report tells the handler about each instance whose state differs
+func (l *InstanceEventListener) report(logger *log.Entry, previous, current map[string]service.InstanceCurrentState) {
+	changed := func(stateID string, before, after service.InstanceCurrentState) {
+		hostID, serviceID, instanceID, err := ParseStateID(stateID)
+		if err != nil {
+
+			// This shouldn't happen
+			logger.WithField("stateid", stateID).WithError(err).Warn("Unexpected error trying to parse state id")
+			return
+		}
+		l.handler.InstanceStateChanged(l.poolid, serviceID, hostID, instanceID, before, after)
+	}
+	for stateID, after := range current {
+		if before, ok := previous[stateID]; !ok || before != after {
+			changed(stateID, previous[stateID], after)
+		}
+	}
+	for stateID, before := range previous {
+		if _, ok := current[stateID]; !ok {
+			changed(stateID, before, "")
+		}
+	}
+}
@@ -39,9 +39,14 @@ func (err ServiceError) Error() string {
 // ServiceNode is the storage object for service data
 type ServiceNode struct {
 	ID                          string
+	TenantID                    string
 	Name                        string
 	DesiredState                int
 	HostPolicy                  servicedefinition.HostPolicy
+	NodeSelector                map[string]string
+	Affinity                    []servicedefinition.AffinityRule
+	AntiAffinity                []servicedefinition.AffinityRule
+	DeploymentID                string
 	Instances                   int
 	RAMCommitment               utils.EngNotation
 	CPUCommitment               int
@@ -63,6 +68,10 @@ func NewServiceNodeFromService(s *service.Service) (*ServiceNode, error) {
 		RAMCommitment: s.RAMCommitment,
 		ChangeOptions: s.ChangeOptions,
 		HostPolicy:    s.HostPolicy,
+		NodeSelector:  s.NodeSelector,
+		Affinity:      s.Affinity,
+		AntiAffinity:  s.AntiAffinity,
+		DeploymentID:  s.DeploymentID,
 	}
 
 	// Copy address assignment if it exists. Note whether assignment is expected, so the scheduler can verify it later.
@@ -131,13 +140,14 @@ func GetServiceNodes(conn client.Connection) ([]ServiceNode, error) {
 
 // UpdateService creates the service if it doesn't exist or updates it if it
 // does exist. (uses a pool-based connection)
-func UpdateService(conn client.Connection, svc *service.Service, setLockOnCreate, setLockOnUpdate bool) error {
-	return UpdateServices(conn, []*service.Service{svc}, setLockOnCreate, setLockOnUpdate)
+func UpdateService(conn client.Connection, tenantID string, svc *service.Service, setLockOnCreate, setLockOnUpdate bool) error {
+	return UpdateServices(conn, tenantID, []*service.Service{svc}, setLockOnCreate, setLockOnUpdate)
 }
 
 // UpdateServices creates the services if they doesn't exist or updates it if it
 // does exist. (uses a pool-based connection). All svcs MUST be in the same pool
-func UpdateServices(conn client.Connection, svcs []*service.Service, setLockOnCreate, setLockOnUpdate bool) error {
+// and tenant
+func UpdateServices(conn client.Connection, tenantID string, svcs []*service.Service, setLockOnCreate, setLockOnUpdate bool) error {
 	poolLogger := plog.WithFields(log.Fields{
 		"poolid":       svcs[0].PoolID,
 		"servicecount": len(svcs),
@@ -170,6 +180,7 @@ func UpdateServices(conn client.Connection, svcs []*service.Service, setLockOnCr
 			}
 		}
 
+		sn.TenantID = tenantID
 		sn.Locked = setLockOnCreate
 		if err := conn.CreateIfExists(pth, sn); err == client.ErrNodeExists {
 
@@ -215,6 +226,38 @@ func UpdateServices(conn client.Connection, svcs []*service.Service, setLockOnCr
 	return nil
 }
 
+// PoolHasTenant returns true if the pool has any service of the tenant (uses
+// a root-based connection)
+func PoolHasTenant(conn client.Connection, poolID, tenantID string) (bool, error) {
+	pth := path.Join("/pools", poolID, "services")
+	serviceIDs, err := conn.Children(pth)
+	if err == client.ErrNoNode {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	// the tenant is the id of its top-level service, which is also how
+	// services that were synced without a tenant are found
+	for _, serviceID := range serviceIDs {
+		if serviceID == tenantID {
+			return true, nil
+		}
+	}
+	for _, serviceID := range serviceIDs {
+		node := &ServiceNode{}
+		if err := conn.Get(path.Join(pth, serviceID), node); err == client.ErrNoNode {
+			continue
+		} else if err != nil && err != client.ErrEmptyNode {
+			return false, err
+		}
+		if node.TenantID == tenantID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // RemoveService deletes a service if the service has no running states
 func RemoveService(conn client.Connection, poolID, serviceID string) error {
 	basepth := ""
@@ -267,8 +310,8 @@ func RemoveService(conn client.Connection, poolID, serviceID string) error {
 }
 
 // SyncServices synchronizes the services to the provided list (uses a pool-
-// based connection)
-func SyncServices(conn client.Connection, svcs []service.Service) error {
+// based connection).  tenantIDs maps each service id to its tenant id.
+func SyncServices(conn client.Connection, svcs []service.Service, tenantIDs map[string]string) error {
 	pth := path.Join("/services")
 
 	logger := plog.WithField("zkpath", pth)
@@ -290,7 +333,7 @@ func SyncServices(conn client.Connection, svcs []service.Service) error {
 
 	// set the services
 	for _, s := range svcs {
-		if err := UpdateService(conn, &s, false, false); err != nil {
+		if err := UpdateService(conn, tenantIDs[s.ID], &s, false, false); err != nil {
 			return err
 		}
 
@@ -164,3 +164,35 @@ func (t *ZZKTest) TestWaitInstance(c *C) {
 		c.Fatalf("Timed out waiting for listener")
 	}
 }
+
+func (t *ZZKTest) TestPoolHasTenant(c *C) {
+	conn, err := zzk.GetLocalConnection("/")
+	c.Assert(err, IsNil)
+	poolconn, err := zzk.GetLocalConnection("/pools/poolid")
+	c.Assert(err, IsNil)
+
+	// a child service of the tenant
+	svc := &service.Service{ID: "serviceid", PoolID: "poolid"}
+	err = UpdateService(poolconn, "tenantid", svc, false, false)
+	c.Assert(err, IsNil)
+
+	ok, err := PoolHasTenant(conn, "poolid", "tenantid")
+	c.Assert(err, IsNil)
+	c.Check(ok, Equals, true)
+
+	ok, err = PoolHasTenant(conn, "poolid", "othertenantid")
+	c.Assert(err, IsNil)
+	c.Check(ok, Equals, false)
+
+	ok, err = PoolHasTenant(conn, "otherpoolid", "tenantid")
+	c.Assert(err, IsNil)
+	c.Check(ok, Equals, false)
+
+	// a tenant that was synced without a tenant id
+	err = conn.CreateDir("/pools/poolid/services/othertenantid")
+	c.Assert(err, IsNil)
+
+	ok, err = PoolHasTenant(conn, "poolid", "othertenantid")
+	c.Assert(err, IsNil)
+	c.Check(ok, Equals, true)
+}